@@ -180,7 +180,7 @@ export const createAgent = (
     { label: 'Knowledge', tools: ['knowledge_read', 'knowledge_write'], desc: 'read & write bot knowledge base' },
     { label: 'Message', tools: ['send', 'react', 'lookup_channel_user'], desc: 'send messages, reactions & user lookup' },
     { label: 'Image', tools: ['generate_image'], desc: 'generate image from text prompt (async, auto-delivered)' },
-    { label: 'Schedule', tools: ['create_schedule', 'list_schedule', 'get_schedule', 'update_schedule', 'delete_schedule'], desc: 'manage cron-based recurring tasks' },
+    { label: 'Schedule', tools: ['create_schedule', 'list_schedule', 'get_schedule', 'update_schedule', 'delete_schedule', 'list_schedule_runs'], desc: 'manage recurring, interval & one-shot tasks & inspect past runs' },
     { label: 'Skills', tools: ['use_skill', 'discover_skills', 'fork_skill'], desc: 'activate, search & import skills' },
     { label: 'Team', tools: ['call_agent'], desc: 'delegate tasks to team member bots' },
     { label: 'Subagent', tools: ['list_subagents', 'create_subagent', 'delete_subagent', 'query_subagent', 'spawn_subagent', 'check_subagent_run', 'kill_subagent_run', 'steer_subagent', 'list_subagent_runs'], desc: 'create & manage sub-agents. ONLY use spawn_subagent when 2+ independent long-running tasks need parallel execution. For simple questions, single-step tasks, or sequential work — do it yourself, never spawn.' },
@@ -461,17 +461,20 @@ export const createAgent = (
   // Normalize AI SDK v6 usage fields to the legacy names expected by the
   // Go backend (gatewayUsage) and the web frontend (promptTokens, etc.).
   const normalizeUsage = (usage: LanguageModelUsage | null) => {
-    if (!usage) return { promptTokens: 0, completionTokens: 0, totalTokens: 0 }
+    if (!usage) return { promptTokens: 0, completionTokens: 0, totalTokens: 0, cachedTokens: 0 }
     const input = (usage as Record<string, unknown>).inputTokens as number | undefined
     const output = (usage as Record<string, unknown>).outputTokens as number | undefined
     const prompt = (usage as Record<string, unknown>).promptTokens as number | undefined
     const completion = (usage as Record<string, unknown>).completionTokens as number | undefined
     const p = prompt ?? input ?? 0
     const c = completion ?? output ?? 0
+    const details = (usage as Record<string, unknown>).inputTokenDetails as { cacheReadTokens?: number } | undefined
+    const cached = details?.cacheReadTokens ?? ((usage as Record<string, unknown>).cachedInputTokens as number | undefined) ?? 0
     return {
       promptTokens: p,
       completionTokens: c,
       totalTokens: usage.totalTokens ?? (p + c),
+      cachedTokens: cached,
     }
   }
 
@@ -67,6 +67,8 @@ export interface NormalizedUsage {
   promptTokens: number
   completionTokens: number
   totalTokens: number
+  // Prompt tokens read from the provider's prompt cache.
+  cachedTokens: number
 }
 
 export interface AgentEndAction extends BaseAction {
@@ -16,5 +16,8 @@ export const buildIdentityHeaders = (identity: IdentityContext, auth: AgentAuthC
   if (identity.replyTarget) {
     headers['X-Memoh-Reply-Target'] = identity.replyTarget
   }
+  if (identity.conversationType) {
+    headers['X-Memoh-Conversation-Type'] = identity.conversationType
+  }
   return headers
 }
\ No newline at end of file
@@ -19,15 +19,18 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/apikeys"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/bind"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/boot"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/budget"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/discord"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/feishu"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/local"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/telegram"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/webhook"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/wechat"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/identities"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/inbound"
@@ -64,6 +67,7 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/message"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/models"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/netguard"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/policy"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/preauth"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/processlog"
@@ -73,12 +77,19 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/searchproviders"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/server"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/sso"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/subagent"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/templates"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/transcription"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/version"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/webhooks"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		os.Exit(runSecretsCommand(os.Args[2:]))
+	}
 	fx.New(
 		fx.Provide(
 			provideConfig,
@@ -95,9 +106,10 @@ func main() {
 			// memory pipeline
 			provideMemoryLLM,
 			provideEmbeddingsResolver,
+			provideTranscriptionResolver,
 			provideEmbeddingSetup,
 			provideTextEmbedderForMemory,
-			provideQdrantStore,
+			provideVectorStore,
 			memory.NewBM25Indexer,
 			provideMemoryService,
 
@@ -110,6 +122,9 @@ func main() {
 			searchproviders.NewService,
 			policy.NewService,
 			preauth.NewService,
+			apikeys.NewService,
+			provideNetGuard,
+			webhooks.NewService,
 			mcp.NewConnectionService,
 			provideBuiltinToolConfigService,
 			subagent.NewService,
@@ -117,6 +132,8 @@ func main() {
 			identities.NewService,
 			bind.NewService,
 			event.NewHub,
+			budget.NewStore,
+			budget.NewService,
 
 			// global settings (timezone, etc.)
 			provideGlobalSettings,
@@ -127,6 +144,8 @@ func main() {
 
 			// channel infrastructure
 			local.NewRouteHub,
+			telegram.NewTelegramAdapter,
+			webhook.NewWebhookAdapter,
 			provideChannelRegistry,
 			channel.NewService,
 			provideChannelRouter,
@@ -151,6 +170,7 @@ func main() {
 
 			// http handlers (group:"server_handlers")
 			provideServerHandler(handlers.NewPingHandler),
+			provideServerHandler(handlers.NewMetricsHandler),
 			provideServerHandler(provideAuthHandler),
 			provideServerHandler(provideMemoryHandler),
 			provideServerHandler(handlers.NewEmbeddingsHandler),
@@ -163,13 +183,16 @@ func main() {
 			provideServerHandler(handlers.NewSettingsHandler),
 			provideServerHandler(providePromptsHandler),
 			provideServerHandler(handlers.NewPreauthHandler),
+			provideServerHandler(provideOpenAIHandler),
 			provideServerHandler(handlers.NewBindHandler),
 			provideServerHandler(handlers.NewScheduleHandler),
+			provideServerHandler(handlers.NewWebhooksHandler),
 			provideServerHandler(handlers.NewHeartbeatHandler),
 			provideServerHandler(handlers.NewSubagentHandler),
 			provideSubagentRunsHandler,
 			provideServerHandler(func(h *handlers.SubagentRunsHandler) *handlers.SubagentRunsHandler { return h }),
 			provideServerHandler(handlers.NewTokenUsageHandler),
+			provideServerHandler(handlers.NewTokenBudgetHandler),
 			provideServerHandler(handlers.NewDiagnosticsHandler),
 			provideServerHandler(handlers.NewGlobalSettingsHandler),
 			provideServerHandler(handlers.NewChannelHandler),
@@ -182,21 +205,29 @@ func main() {
 			provideServerHandler(provideWebHandler),
 			provideServerHandler(provideAgentCallHandler),
 			provideServerHandler(provideWeChatWebhookHandler),
+			provideServerHandler(handlers.NewTelegramWebhookHandler),
+			provideServerHandler(handlers.NewWebhookChannelHandler),
 			provideServerHandler(provideTeamsHandler),
 			provideServerHandler(provideUnifiedToolsHandler),
 
 			provideServer,
 		),
 		fx.Invoke(
+			startSecrets,
+			startTracing,
 			startMemoryWarmup,
 			startCronPool,
 			startScheduleService,
 			startHeartbeatEngine,
 			startChannelManager,
+			startWebhookDispatcher,
 			startContainerReconciliation,
 			startStaleRunReaper,
+			startEgressRefresher,
 			startServer,
 			wireTriggerSender,
+			wireBudgetNotifier,
+			wireScheduleNotifier,
 			wireBroadcaster,
 		),
 		fx.WithLogger(func(logger *slog.Logger) fxevent.Logger {
@@ -230,6 +261,10 @@ func provideConfig() (config.Config, error) {
 	return cfg, nil
 }
 
+func provideNetGuard(cfg config.Config) (*netguard.Guard, error) {
+	return netguard.New(cfg.Webhooks.AllowedNetworks)
+}
+
 func provideLogger(cfg config.Config) *slog.Logger {
 	logger.Init(cfg.Log.Level, cfg.Log.Format)
 	return logger.L
@@ -332,6 +367,10 @@ func provideMemoryLLM(modelsService *models.Service, queries *dbsqlc.Queries, lo
 	}
 }
 
+func provideTranscriptionResolver(log *slog.Logger, modelsService *models.Service, queries *dbsqlc.Queries, botService *bots.Service) *transcription.Resolver {
+	return transcription.NewResolver(log, modelsService, queries, botService, 60*time.Second)
+}
+
 func provideEmbeddingsResolver(log *slog.Logger, modelsService *models.Service, queries *dbsqlc.Queries) *embeddings.Resolver {
 	return embeddings.NewResolver(log, modelsService, queries, 10*time.Second)
 }
@@ -370,7 +409,24 @@ func provideTextEmbedderForMemory(resolver *embeddings.Resolver, setup embedding
 	return embeddings.NewCachedEmbedder(base, setup.TextModel.ModelID, 24*time.Hour, 10000)
 }
 
-func provideQdrantStore(log *slog.Logger, cfg config.Config, setup embeddingSetup) (*memory.QdrantStore, error) {
+func provideVectorStore(log *slog.Logger, cfg config.Config, setup embeddingSetup, pool *pgxpool.Pool) (memory.VectorStore, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Memory.VectorStore)) {
+	case "", memory.VectorBackendQdrant:
+		return provideQdrantStore(log, cfg, setup)
+	case memory.VectorBackendPgVector:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		store, err := memory.NewPgVectorStore(ctx, log, pool, cfg.Qdrant.Collection)
+		if err != nil {
+			return nil, fmt.Errorf("pgvector init: %w", err)
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported memory vector_store: %q", cfg.Memory.VectorStore)
+	}
+}
+
+func provideQdrantStore(log *slog.Logger, cfg config.Config, setup embeddingSetup) (memory.VectorStore, error) {
 	qcfg := cfg.Qdrant
 	timeout := time.Duration(qcfg.TimeoutSeconds) * time.Second
 	if setup.HasEmbeddingModels && len(setup.Vectors) > 0 {
@@ -387,7 +443,7 @@ func provideQdrantStore(log *slog.Logger, cfg config.Config, setup embeddingSetu
 	return store, nil
 }
 
-func provideMemoryService(lc fx.Lifecycle, log *slog.Logger, llm memory.LLM, embedder embeddings.Embedder, store *memory.QdrantStore, resolver *embeddings.Resolver, bm25 *memory.BM25Indexer, setup embeddingSetup, pool *pgxpool.Pool) *memory.Service {
+func provideMemoryService(lc fx.Lifecycle, log *slog.Logger, llm memory.LLM, embedder embeddings.Embedder, store memory.VectorStore, resolver *embeddings.Resolver, bm25 *memory.BM25Indexer, setup embeddingSetup, pool *pgxpool.Pool, processLogSvc *processlog.Service, hub *event.Hub) *memory.Service {
 	svc := memory.NewService(log, llm, embedder, store, resolver, bm25, setup.TextModel.ModelID, setup.MultimodalModel.ModelID)
 	if setup.HasEmbeddingModels && setup.TextModel.ModelID != "" {
 		providerKey := setup.TextModel.LlmProviderID
@@ -398,10 +454,18 @@ func provideMemoryService(lc fx.Lifecycle, log *slog.Logger, llm memory.LLM, emb
 		svc.SetEmbeddingCache(cache)
 		log.Info("embedding cache enabled", slog.String("model", setup.TextModel.ModelID))
 	}
+	svc.SetRelationStore(memory.NewRelationStore(pool, log))
+	svc.SetReembedStore(memory.NewReembedStore(pool, log))
+	svc.SetHistoryStore(memory.NewHistoryStore(pool, log))
+	svc.SetProcessLog(processLogSvc)
+	svc.SetEventPublisher(hub)
 	// Wire BM25 stats persistence: load from DB on start, flush on stop.
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			bm25.SetPool(ctx, pool)
+			if err := svc.LoadReembedState(ctx); err != nil {
+				log.Warn("load embedding migration state failed", slog.Any("error", err))
+			}
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
@@ -444,9 +508,11 @@ func provideSubagentRunsHandler(pool *pgxpool.Pool, log *slog.Logger) *handlers.
 	return handlers.NewSubagentRunsHandler(pool, log)
 }
 
-func provideChatResolver(log *slog.Logger, cfg config.Config, gs *globalsettings.Service, modelsService *models.Service, queries *dbsqlc.Queries, memoryService *memory.Service, chatService *conversation.Service, msgService *message.DBService, settingsService *settings.Service, processLogSvc *processlog.Service, containerdHandler *handlers.ContainerdHandler, manager *mcp.Manager) *flow.Resolver {
+func provideChatResolver(log *slog.Logger, cfg config.Config, gs *globalsettings.Service, modelsService *models.Service, queries *dbsqlc.Queries, memoryService *memory.Service, chatService *conversation.Service, msgService *message.DBService, settingsService *settings.Service, processLogSvc *processlog.Service, containerdHandler *handlers.ContainerdHandler, manager *mcp.Manager, budgetService *budget.Service, hub *event.Hub) *flow.Resolver {
 	resolver := flow.NewResolver(log, modelsService, queries, memoryService, chatService, msgService, settingsService, processLogSvc, cfg.AgentGateway.BaseURL(), 120*time.Second)
 	resolver.SetSkillLoader(&skillLoaderAdapter{handler: containerdHandler})
+	resolver.SetBudgetChecker(budgetService)
+	resolver.SetEventPublisher(hub)
 	tz, _ := gs.GetTimezone()
 	resolver.SetTimezone(tz)
 	gs.OnTimezoneChange(func(tz string, _ *time.Location) {
@@ -463,25 +529,28 @@ func provideChatResolver(log *slog.Logger, cfg config.Config, gs *globalsettings
 // channel providers
 // ---------------------------------------------------------------------------
 
-func provideChannelRegistry(log *slog.Logger, hub *local.RouteHub) *channel.Registry {
+func provideChannelRegistry(log *slog.Logger, hub *local.RouteHub, telegramAdapter *telegram.TelegramAdapter, webhookAdapter *webhook.WebhookAdapter) *channel.Registry {
 	registry := channel.NewRegistry()
-	registry.MustRegister(telegram.NewTelegramAdapter(log))
+	registry.MustRegister(telegramAdapter)
 	registry.MustRegister(feishu.NewFeishuAdapter(log))
 	registry.MustRegister(discord.NewDiscordAdapter(log))
 	registry.MustRegister(local.NewCLIAdapter(hub))
 	registry.MustRegister(local.NewWebAdapter(hub))
 	registry.MustRegister(wechat.NewWeChatAdapter(log))
+	registry.MustRegister(webhookAdapter)
 	return registry
 }
 
-func provideChannelRouter(log *slog.Logger, registry *channel.Registry, routeService *route.DBService, msgService *message.DBService, resolver *flow.Resolver, identityService *identities.Service, botService *bots.Service, policyService *policy.Service, preauthService *preauth.Service, bindService *bind.Service, rc *boot.RuntimeConfig) *inbound.ChannelInboundProcessor {
+func provideChannelRouter(log *slog.Logger, registry *channel.Registry, routeService *route.DBService, msgService *message.DBService, resolver *flow.Resolver, identityService *identities.Service, botService *bots.Service, policyService *policy.Service, preauthService *preauth.Service, bindService *bind.Service, transcriptionResolver *transcription.Resolver, rc *boot.RuntimeConfig) *inbound.ChannelInboundProcessor {
 	proc := inbound.NewChannelInboundProcessor(log, registry, routeService, msgService, resolver, identityService, botService, policyService, preauthService, bindService, rc.JwtSecret, 5*time.Minute)
 	proc.SetGroupDebouncer(message.NewGroupDebouncer(3 * time.Second))
+	proc.SetTranscriber(transcriptionResolver)
 	return proc
 }
 
-func provideChannelManager(log *slog.Logger, registry *channel.Registry, channelService *channel.Service, channelRouter *inbound.ChannelInboundProcessor) *channel.Manager {
+func provideChannelManager(log *slog.Logger, registry *channel.Registry, channelService *channel.Service, channelRouter *inbound.ChannelInboundProcessor, hub *event.Hub) *channel.Manager {
 	mgr := channel.NewManager(log, registry, channelService, channelRouter)
+	mgr.SetEventPublisher(hub)
 	if mw := channelRouter.IdentityMiddleware(); mw != nil {
 		mgr.Use(mw)
 	}
@@ -503,6 +572,53 @@ func wireBroadcaster(channelRouter *inbound.ChannelInboundProcessor, channelMana
 	channelRouter.SetBroadcaster(channelManager, routeService)
 }
 
+// wireBudgetNotifier lets token budget warnings reach bot owners through
+// their linked channels. Like wireTriggerSender it runs post-construction
+// because channel.Manager depends on the resolver.
+func wireBudgetNotifier(budgetService *budget.Service, channelManager *channel.Manager, queries *dbsqlc.Queries) {
+	budgetService.SetOwnerNotifier(&channelOwnerNotifier{manager: channelManager, queries: queries})
+}
+
+// wireScheduleNotifier lets schedule runs that failed for good reach the bot
+// owner the same way budget warnings do.
+func wireScheduleNotifier(scheduleService *schedule.Service, channelManager *channel.Manager, queries *dbsqlc.Queries) {
+	scheduleService.SetOwnerNotifier(&channelOwnerNotifier{manager: channelManager, queries: queries})
+}
+
+// channelOwnerNotifier implements budget.OwnerNotifier and
+// schedule.OwnerNotifier by messaging the owner's linked channel identities
+// through the bot, stopping at the first channel that delivers.
+type channelOwnerNotifier struct {
+	manager *channel.Manager
+	queries *dbsqlc.Queries
+}
+
+func (n *channelOwnerNotifier) NotifyOwner(ctx context.Context, botID, ownerUserID, text string) error {
+	pgUserID, err := db.ParseUUID(ownerUserID)
+	if err != nil {
+		return err
+	}
+	identities, err := n.queries.ListChannelIdentitiesByUserID(ctx, pgUserID)
+	if err != nil {
+		return fmt.Errorf("list owner channel identities: %w", err)
+	}
+	var errs []error
+	for _, identity := range identities {
+		err := n.manager.Send(ctx, botID, channel.ChannelType(identity.ChannelType), channel.SendRequest{
+			ChannelIdentityID: identity.ID.String(),
+			Message:           channel.Message{Text: text},
+		})
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return errors.New("owner has no linked channel identity")
+	}
+	return errors.Join(errs...)
+}
+
 // channelTriggerSender implements flow.TriggerMessageSender using channel.Manager.
 type channelTriggerSender struct {
 	manager *channel.Manager
@@ -520,8 +636,13 @@ func (s *channelTriggerSender) SendText(ctx context.Context, botID, platform, ta
 // containerd handler & tool gateway
 // ---------------------------------------------------------------------------
 
-func provideContainerdHandler(log *slog.Logger, service ctr.Service, cfg config.Config, botService *bots.Service, accountService *accounts.Service, policyService *policy.Service, queries *dbsqlc.Queries) *handlers.ContainerdHandler {
-	return handlers.NewContainerdHandler(log, service, cfg.MCP, cfg.Containerd.Namespace, botService, accountService, policyService, queries)
+func provideContainerdHandler(log *slog.Logger, service ctr.Service, cfg config.Config, botService *bots.Service, accountService *accounts.Service, policyService *policy.Service, queries *dbsqlc.Queries, pool *pgxpool.Pool, manager *mcp.Manager, hub *event.Hub) *handlers.ContainerdHandler {
+	h := handlers.NewContainerdHandler(log, service, cfg.MCP, cfg.Containerd.Namespace, botService, accountService, policyService, queries)
+	h.SetResourceLimitsStore(settings.NewResourceLimitsStore(log, pool))
+	h.SetEgressPolicyStore(settings.NewEgressPolicyStore(log, pool))
+	h.SetEventPublisher(hub)
+	manager.SetEgressPolicyFunc(h.ContainerEgressPolicy)
+	return h
 }
 
 func provideToolGatewayService(lc fx.Lifecycle, log *slog.Logger, cfg config.Config, channelManager *channel.Manager, registry *channel.Registry, channelService *channel.Service, scheduleService *schedule.Service, memoryService *memory.Service, chatService *conversation.Service, accountService *accounts.Service, settingsService *settings.Service, searchProviderService *searchproviders.Service, manager *mcp.Manager, containerdHandler *handlers.ContainerdHandler, mcpConnService *mcp.ConnectionService, botService *bots.Service, modelService *models.Service, providerService *providers.Service, msgService *message.DBService, queries *dbsqlc.Queries, routeService *route.DBService, pool *pgxpool.Pool) *mcp.ToolGatewayService {
@@ -600,20 +721,26 @@ func provideProcessLogHandler(log *slog.Logger, botService *bots.Service, proces
 	return handlers.NewProcessLogHandler(botService, processLogSvc, log)
 }
 
-func provideAuthHandler(log *slog.Logger, accountService *accounts.Service, rc *boot.RuntimeConfig) *handlers.AuthHandler {
-	return handlers.NewAuthHandler(log, accountService, rc.JwtSecret, rc.JwtExpiresIn)
+func provideAuthHandler(log *slog.Logger, accountService *accounts.Service, cfg config.Config, rc *boot.RuntimeConfig) *handlers.AuthHandler {
+	return handlers.NewAuthHandler(log, accountService, sso.NewProvider(log, cfg.Auth.OIDC), rc.JwtSecret, rc.JwtExpiresIn)
 }
 
-func provideMessageHandler(log *slog.Logger, resolver *flow.Resolver, chatService *conversation.Service, msgService *message.DBService, botService *bots.Service, accountService *accounts.Service, identityService *identities.Service, cfg config.Config, hub *event.Hub) *handlers.MessageHandler {
+func provideMessageHandler(log *slog.Logger, resolver *flow.Resolver, chatService *conversation.Service, msgService *message.DBService, botService *bots.Service, accountService *accounts.Service, identityService *identities.Service, cfg config.Config, rc *boot.RuntimeConfig, hub *event.Hub) *handlers.MessageHandler {
 	dataRoot := cfg.MCP.DataRoot
 	if dataRoot == "" {
 		dataRoot = config.DefaultDataRoot
 	}
-	return handlers.NewMessageHandler(log, resolver, chatService, msgService, botService, accountService, identityService, dataRoot, hub)
+	h := handlers.NewMessageHandler(log, resolver, chatService, msgService, botService, accountService, identityService, dataRoot, hub)
+	h.SetTokenSecret(rc.JwtSecret)
+	return h
 }
 
-func provideUsersHandler(log *slog.Logger, accountService *accounts.Service, identityService *identities.Service, botService *bots.Service, routeService *route.DBService, channelService *channel.Service, channelManager *channel.Manager, registry *channel.Registry, heartbeatEngine *heartbeat.Engine) *handlers.UsersHandler {
-	return handlers.NewUsersHandler(log, accountService, identityService, botService, routeService, channelService, channelManager, registry, heartbeatEngine)
+func provideOpenAIHandler(log *slog.Logger, resolver *flow.Resolver, keys *apikeys.Service, botService *bots.Service, rc *boot.RuntimeConfig) *handlers.OpenAIHandler {
+	return handlers.NewOpenAIHandler(log, resolver, keys, botService, rc.JwtSecret)
+}
+
+func provideUsersHandler(log *slog.Logger, accountService *accounts.Service, identityService *identities.Service, botService *bots.Service, routeService *route.DBService, channelService *channel.Service, channelManager *channel.Manager, registry *channel.Registry, heartbeatEngine *heartbeat.Engine, apiKeys *apikeys.Service) *handlers.UsersHandler {
+	return handlers.NewUsersHandler(log, accountService, identityService, botService, routeService, channelService, channelManager, registry, heartbeatEngine, apiKeys)
 }
 
 func provideSharedFilesHandler(cfg config.Config) *handlers.SharedFilesHandler {
@@ -664,13 +791,14 @@ type serverParams struct {
 	Config            config.Config
 	ServerHandlers    []server.Handler `group:"server_handlers"`
 	ContainerdHandler *handlers.ContainerdHandler
+	APIKeys           *apikeys.Service
 }
 
 func provideServer(params serverParams) *server.Server {
 	allHandlers := make([]server.Handler, 0, len(params.ServerHandlers)+1)
 	allHandlers = append(allHandlers, params.ServerHandlers...)
 	allHandlers = append(allHandlers, params.ContainerdHandler)
-	return server.NewServer(params.Logger, params.RuntimeConfig.ServerAddr, params.Config.Auth.JWTSecret, allHandlers...)
+	return server.NewServer(params.Logger, params.RuntimeConfig.ServerAddr, params.Config.Auth.JWTSecret, params.APIKeys, allHandlers...)
 }
 
 // ---------------------------------------------------------------------------
@@ -707,6 +835,25 @@ func provideCronPool(log *slog.Logger, gs *globalsettings.Service) *automation.C
 	return pool
 }
 
+// startTracing installs the OpenTelemetry tracer provider before the other
+// components start, and flushes pending spans after they stop.
+func startTracing(lc fx.Lifecycle, logger *slog.Logger, cfg config.Config) {
+	var shutdown func(context.Context) error
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			var err error
+			shutdown, err = tracing.Setup(ctx, cfg.Tracing, logger)
+			return err
+		},
+		OnStop: func(ctx context.Context) error {
+			if shutdown == nil {
+				return nil
+			}
+			return shutdown(ctx)
+		},
+	})
+}
+
 func startCronPool(lc fx.Lifecycle, pool *automation.CronPool) {
 	lc.Append(fx.Hook{
 		OnStart: func(_ context.Context) error {
@@ -725,6 +872,10 @@ func startScheduleService(lc fx.Lifecycle, scheduleService *schedule.Service) {
 		OnStart: func(ctx context.Context) error {
 			return scheduleService.Bootstrap(ctx)
 		},
+		OnStop: func(_ context.Context) error {
+			scheduleService.Stop()
+			return nil
+		},
 	})
 }
 
@@ -761,6 +912,21 @@ func startChannelManager(lc fx.Lifecycle, channelManager *channel.Manager) {
 	})
 }
 
+// startWebhookDispatcher delivers hub events to bot webhooks until shutdown.
+func startWebhookDispatcher(lc fx.Lifecycle, webhookService *webhooks.Service, hub *event.Hub) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			webhookService.Start(ctx, hub)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
 func startContainerReconciliation(lc fx.Lifecycle, containerdHandler *handlers.ContainerdHandler, _ *mcp.ToolGatewayService) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -784,6 +950,20 @@ func startStaleRunReaper(lc fx.Lifecycle, h *handlers.SubagentRunsHandler) {
 	})
 }
 
+func startEgressRefresher(lc fx.Lifecycle, h *handlers.ContainerdHandler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go h.StartEgressRefresher(ctx, 5*time.Minute)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
 func startServer(lc fx.Lifecycle, logger *slog.Logger, srv *server.Server, shutdowner fx.Shutdowner, cfg config.Config, queries *dbsqlc.Queries, botService *bots.Service, containerdHandler *handlers.ContainerdHandler, mcpConnService *mcp.ConnectionService, toolGateway *mcp.ToolGatewayService, heartbeatEngine *heartbeat.Engine, memoryService *memory.Service) {
 	fmt.Printf("Starting Memoh Agent %s\n", version.GetInfo())
 
@@ -796,6 +976,7 @@ func startServer(lc fx.Lifecycle, logger *slog.Logger, srv *server.Server, shutd
 			botService.SetHeartbeatSeeder(heartbeatEngine)
 			heartbeatEngine.SetMemoryCompactor(memoryService)
 			botService.AddRuntimeChecker(mcp.NewConnectionChecker(logger, mcpConnService, toolGateway))
+			botService.AddRuntimeChecker(containerdHandler.ResourceChecker())
 
 			go func() {
 				if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -928,6 +1109,14 @@ func (c *lazyLLMClient) Compact(ctx context.Context, req memory.CompactRequest)
 	return client.Compact(ctx, req)
 }
 
+func (c *lazyLLMClient) ExtractRelations(ctx context.Context, req memory.ExtractRelationsRequest) (memory.ExtractRelationsResponse, error) {
+	client, err := c.resolve(ctx)
+	if err != nil {
+		return memory.ExtractRelationsResponse{}, err
+	}
+	return client.ExtractRelations(ctx, req)
+}
+
 func (c *lazyLLMClient) DetectLanguage(ctx context.Context, text string) (string, error) {
 	client, err := c.resolve(ctx)
 	if err != nil {
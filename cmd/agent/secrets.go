@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/mcp"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
+)
+
+// secretColumns lists every column that holds credentials.
+var secretColumns = []secrets.Column{
+	{Table: "llm_providers", Column: "api_key", Kind: secrets.KindText},
+	{Table: "bot_channel_configs", Column: "credentials", Kind: secrets.KindJSON},
+	{Table: "search_providers", Column: "config", Kind: secrets.KindJSON},
+	{Table: "mcp_connections", Column: "config", Kind: secrets.KindJSONFields, Fields: mcp.SecretConfigFields},
+	{Table: "bot_webhooks", Column: "secret", Kind: secrets.KindText},
+}
+
+// startSecrets installs the credential keyring before any service reads the
+// database, then encrypts rows still stored in plaintext on startup.
+func startSecrets(lc fx.Lifecycle, cfg config.Config, logger *slog.Logger, pool *pgxpool.Pool) error {
+	keyring, err := secrets.LoadKeyring(cfg.Secrets)
+	if err != nil {
+		return fmt.Errorf("load secrets master key: %w", err)
+	}
+	secrets.SetDefault(keyring)
+	if !keyring.Enabled() {
+		logger.Warn("no secrets master key configured; provider API keys and channel credentials are stored in plaintext")
+		return nil
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, time.Minute)
+			defer cancel()
+			results, err := secrets.Reencrypt(ctx, pool, keyring, secretColumns, secrets.ModeEncrypt, logger)
+			if err != nil {
+				logger.Warn("encrypting stored credentials failed", slog.Any("error", err))
+				return nil
+			}
+			logSecretsResults(logger, "encrypted stored credentials", results)
+			return nil
+		},
+	})
+	return nil
+}
+
+// runSecretsCommand implements `memoh-server secrets <command>`.
+func runSecretsCommand(args []string) int {
+	if len(args) == 0 {
+		printSecretsUsage()
+		return 2
+	}
+	switch args[0] {
+	case "generate-key":
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "generate key:", err)
+			return 1
+		}
+		fmt.Println(key)
+		return 0
+	case "encrypt", "rotate":
+		mode := secrets.ModeEncrypt
+		if args[0] == "rotate" {
+			mode = secrets.ModeRotate
+		}
+		if err := reencryptSecrets(mode); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	default:
+		printSecretsUsage()
+		return 2
+	}
+}
+
+func printSecretsUsage() {
+	fmt.Fprint(os.Stderr, `usage: memoh-server secrets <command>
+
+commands:
+  generate-key  print a new random master key
+  encrypt       encrypt credentials still stored in plaintext
+  rotate        re-encrypt all credentials under secrets.master_key;
+                keys listed in secrets.previous_keys are used to read old values
+`)
+}
+
+func reencryptSecrets(mode secrets.Mode) error {
+	cfg, err := provideConfig()
+	if err != nil {
+		return err
+	}
+	logger := provideLogger(cfg)
+	keyring, err := secrets.LoadKeyring(cfg.Secrets)
+	if err != nil {
+		return fmt.Errorf("load secrets master key: %w", err)
+	}
+	if !keyring.Enabled() {
+		return fmt.Errorf("secrets.master_key or secrets.master_key_file must be set")
+	}
+	ctx := context.Background()
+	pool, err := db.Open(ctx, cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("db connect: %w", err)
+	}
+	defer pool.Close()
+	results, err := secrets.Reencrypt(ctx, pool, keyring, secretColumns, mode, logger)
+	logSecretsResults(logger, "re-encrypted credentials", results)
+	if err != nil {
+		return err
+	}
+	logger.Info("credentials are now under the current master key", slog.String("key_id", keyring.PrimaryID()))
+	return nil
+}
+
+func logSecretsResults(logger *slog.Logger, msg string, results []secrets.Result) {
+	for _, r := range results {
+		logger.Info(msg,
+			slog.String("table", r.Table),
+			slog.String("column", r.Column),
+			slog.Int("scanned", r.Scanned),
+			slog.Int("updated", r.Updated),
+		)
+	}
+}
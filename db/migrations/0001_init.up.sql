@@ -27,6 +27,19 @@ CREATE TABLE IF NOT EXISTS users (
   CONSTRAINT users_username_unique UNIQUE (username)
 );
 
+-- user_sso_identities: OpenID Connect subjects linked to users
+CREATE TABLE IF NOT EXISTS user_sso_identities (
+  id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  issuer     TEXT        NOT NULL,
+  subject    TEXT        NOT NULL,
+  email      TEXT        NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT user_sso_identities_subject_unique UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sso_identities_user_id ON user_sso_identities(user_id);
+
 -- channel_identities: unified inbound identity subject
 CREATE TABLE IF NOT EXISTS channel_identities (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -92,10 +105,13 @@ CREATE TABLE IF NOT EXISTS models (
   fallback_model_id UUID REFERENCES models(id) ON DELETE SET NULL,
   reasoning BOOLEAN NOT NULL DEFAULT false,
   max_tokens INTEGER NOT NULL DEFAULT 0,
+  input_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  output_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+  cached_input_price DOUBLE PRECISION NOT NULL DEFAULT 0,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   CONSTRAINT models_model_id_unique UNIQUE (model_id),
-  CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding')),
+  CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'transcription')),
   CONSTRAINT models_dimensions_check CHECK (type != 'embedding' OR dimensions IS NOT NULL)
 );
 
@@ -210,6 +226,75 @@ CREATE TABLE IF NOT EXISTS bot_preauth_keys (
 CREATE INDEX IF NOT EXISTS idx_bot_preauth_keys_bot_id ON bot_preauth_keys(bot_id);
 CREATE INDEX IF NOT EXISTS idx_bot_preauth_keys_expires ON bot_preauth_keys(expires_at);
 
+-- bot_api_keys: hashed per-bot keys for the OpenAI-compatible /v1 endpoints
+CREATE TABLE IF NOT EXISTS bot_api_keys (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id             UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name               TEXT        NOT NULL DEFAULT '',
+  key_prefix         TEXT        NOT NULL,
+  key_hash           TEXT        NOT NULL,
+  created_by_user_id UUID        REFERENCES users(id) ON DELETE SET NULL,
+  last_used_at       TIMESTAMPTZ,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  scopes             TEXT[]      NOT NULL DEFAULT '{chat}',
+  CONSTRAINT bot_api_keys_hash_unique UNIQUE (key_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_api_keys_bot_id ON bot_api_keys(bot_id);
+
+-- personal_access_tokens: hashed long-lived user tokens with scopes
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name         TEXT        NOT NULL DEFAULT '',
+  token_prefix TEXT        NOT NULL,
+  token_hash   TEXT        NOT NULL,
+  scopes       TEXT[]      NOT NULL DEFAULT '{}',
+  expires_at   TIMESTAMPTZ,
+  last_used_at TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT personal_access_tokens_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+
+-- bot_webhooks: outgoing webhook subscriptions with sealed HMAC secrets
+CREATE TABLE IF NOT EXISTS bot_webhooks (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id             UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name               TEXT        NOT NULL DEFAULT '',
+  url                TEXT        NOT NULL,
+  secret             TEXT        NOT NULL,
+  events             TEXT[]      NOT NULL DEFAULT '{}',
+  enabled            BOOLEAN     NOT NULL DEFAULT true,
+  created_by_user_id UUID        REFERENCES users(id) ON DELETE SET NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_webhooks_bot_id ON bot_webhooks(bot_id);
+
+-- webhook_deliveries: persisted webhook deliveries and their retry state
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  webhook_id       UUID        NOT NULL REFERENCES bot_webhooks(id) ON DELETE CASCADE,
+  bot_id           UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  event_type       TEXT        NOT NULL,
+  payload          JSONB       NOT NULL,
+  status           TEXT        NOT NULL DEFAULT 'pending',
+  attempts         INTEGER     NOT NULL DEFAULT 0,
+  next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_status_code INTEGER     NOT NULL DEFAULT 0,
+  last_error       TEXT        NOT NULL DEFAULT '',
+  delivered_at     TIMESTAMPTZ,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+
 -- channel_identity_bind_codes: one-time codes for channel identity->user linking
 CREATE TABLE IF NOT EXISTS channel_identity_bind_codes (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -336,12 +421,51 @@ CREATE TABLE IF NOT EXISTS schedule (
   command TEXT NOT NULL,
   bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
   platform TEXT NOT NULL DEFAULT '',
-  reply_target TEXT NOT NULL DEFAULT ''
+  reply_target TEXT NOT NULL DEFAULT '',
+  misfire_policy TEXT NOT NULL DEFAULT 'skip',
+  retry_max_attempts INTEGER NOT NULL DEFAULT 1,
+  retry_backoff_seconds INTEGER NOT NULL DEFAULT 60,
+  kind TEXT NOT NULL DEFAULT 'cron',
+  run_at TIMESTAMPTZ,
+  interval_seconds INTEGER,
+  start_at TIMESTAMPTZ,
+  timezone TEXT NOT NULL DEFAULT '',
+  archived_at TIMESTAMPTZ,
+  CONSTRAINT schedule_misfire_policy_check CHECK (misfire_policy IN ('skip', 'run_once', 'run_all')),
+  CONSTRAINT schedule_kind_check CHECK (kind IN ('cron', 'once', 'interval'))
 );
 
 CREATE INDEX IF NOT EXISTS idx_schedule_bot_id ON schedule(bot_id);
 CREATE INDEX IF NOT EXISTS idx_schedule_enabled ON schedule(enabled);
 
+-- schedule_runs: outcome of each schedule execution
+CREATE TABLE IF NOT EXISTS schedule_runs (
+  id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  schedule_id       UUID        NOT NULL REFERENCES schedule(id) ON DELETE CASCADE,
+  bot_id            UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  trigger_type      TEXT        NOT NULL DEFAULT 'cron',
+  status            TEXT        NOT NULL DEFAULT 'running',
+  error             TEXT        NOT NULL DEFAULT '',
+  trace_id          TEXT        NOT NULL DEFAULT '',
+  model             TEXT        NOT NULL DEFAULT '',
+  prompt_tokens     INTEGER     NOT NULL DEFAULT 0,
+  completion_tokens INTEGER     NOT NULL DEFAULT 0,
+  total_tokens      INTEGER     NOT NULL DEFAULT 0,
+  reply_preview     TEXT        NOT NULL DEFAULT '',
+  platform          TEXT        NOT NULL DEFAULT '',
+  reply_target      TEXT        NOT NULL DEFAULT '',
+  started_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at       TIMESTAMPTZ,
+  duration_ms       INTEGER,
+  attempt           INTEGER     NOT NULL DEFAULT 1,
+  scheduled_for     TIMESTAMPTZ,
+  CONSTRAINT schedule_runs_trigger_type_check CHECK (trigger_type IN ('cron', 'manual', 'catchup')),
+  CONSTRAINT schedule_runs_status_check CHECK (status IN ('running', 'success', 'error'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_schedule_started ON schedule_runs(schedule_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_bot_started ON schedule_runs(bot_id, started_at DESC);
+
 CREATE TABLE IF NOT EXISTS subagents (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
   name TEXT NOT NULL,
@@ -398,13 +522,17 @@ CREATE TABLE IF NOT EXISTS token_usage (
   total_tokens INTEGER NOT NULL DEFAULT 0,
   model TEXT NOT NULL DEFAULT '',
   source TEXT NOT NULL DEFAULT 'chat',
-  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  channel_identity_id UUID REFERENCES channel_identities(id) ON DELETE SET NULL,
+  cached_tokens INTEGER NOT NULL DEFAULT 0,
+  cost NUMERIC(20,10) NOT NULL DEFAULT 0
 );
 
 CREATE INDEX IF NOT EXISTS idx_token_usage_bot_id ON token_usage(bot_id);
 CREATE INDEX IF NOT EXISTS idx_token_usage_created_at ON token_usage(created_at);
 CREATE INDEX IF NOT EXISTS idx_token_usage_bot_created ON token_usage(bot_id, created_at);
 CREATE INDEX IF NOT EXISTS idx_token_usage_model ON token_usage(model);
+CREATE INDEX IF NOT EXISTS idx_token_usage_channel_identity_created ON token_usage(channel_identity_id, created_at) WHERE channel_identity_id IS NOT NULL;
 
 CREATE TABLE IF NOT EXISTS evolution_logs (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -478,3 +606,171 @@ CREATE TABLE IF NOT EXISTS bm25_stats (
     updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     PRIMARY KEY (lang)
 );
+
+-- memory_entities / memory_relations: entity relation graph extracted from conversations
+CREATE TABLE IF NOT EXISTS memory_entities (
+  id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id          TEXT        NOT NULL,
+  name            TEXT        NOT NULL,
+  normalized_name TEXT        NOT NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_entities_unique UNIQUE (bot_id, normalized_name)
+);
+
+CREATE TABLE IF NOT EXISTS memory_relations (
+  id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id            TEXT        NOT NULL,
+  subject_id        UUID        NOT NULL REFERENCES memory_entities(id) ON DELETE CASCADE,
+  predicate         TEXT        NOT NULL,
+  object_id         UUID        NOT NULL REFERENCES memory_entities(id) ON DELETE CASCADE,
+  mentions          INT         NOT NULL DEFAULT 1,
+  -- sensitivity / audience_*: inherited from the source conversation, same meaning as on memories
+  sensitivity       TEXT        NOT NULL DEFAULT '',
+  audience_group    TEXT        NOT NULL DEFAULT '',
+  audience_identity TEXT        NOT NULL DEFAULT '',
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_relations_unique UNIQUE (bot_id, subject_id, predicate, object_id, sensitivity, audience_group, audience_identity)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_entities_bot     ON memory_entities (bot_id);
+CREATE INDEX IF NOT EXISTS idx_memory_relations_subject ON memory_relations (subject_id);
+CREATE INDEX IF NOT EXISTS idx_memory_relations_object  ON memory_relations (object_id);
+
+-- memory_points / memory_sparse_terms / memory_point_vectors: Postgres memory backend (memory.vector_store = "pgvector")
+CREATE TABLE IF NOT EXISTS memory_points (
+  id             UUID        NOT NULL,
+  collection     TEXT        NOT NULL,
+  payload        JSONB       NOT NULL DEFAULT '{}'::jsonb,
+  sparse_indices BIGINT[],
+  sparse_values  REAL[],
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_points_pkey PRIMARY KEY (collection, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_points_collection ON memory_points (collection);
+CREATE INDEX IF NOT EXISTS idx_memory_points_bot ON memory_points (collection, (payload ->> 'bot_id'));
+
+CREATE TABLE IF NOT EXISTS memory_sparse_terms (
+  collection TEXT   NOT NULL,
+  point_id   UUID   NOT NULL,
+  term       BIGINT NOT NULL,
+  value      REAL   NOT NULL,
+  CONSTRAINT memory_sparse_terms_pkey PRIMARY KEY (collection, point_id, term),
+  CONSTRAINT memory_sparse_terms_point_fkey FOREIGN KEY (collection, point_id)
+    REFERENCES memory_points(collection, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_sparse_terms_term ON memory_sparse_terms (term);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+    CREATE EXTENSION IF NOT EXISTS vector;
+    -- Dimension-less column: each vector_name (embedding model) may use its own size.
+    -- HNSW indexes are partial per dimension and created by the store on first use.
+    CREATE TABLE IF NOT EXISTS memory_point_vectors (
+      collection  TEXT   NOT NULL,
+      point_id    UUID   NOT NULL,
+      vector_name TEXT   NOT NULL,
+      embedding   vector NOT NULL,
+      CONSTRAINT memory_point_vectors_pkey PRIMARY KEY (collection, point_id, vector_name),
+      CONSTRAINT memory_point_vectors_point_fkey FOREIGN KEY (collection, point_id)
+        REFERENCES memory_points(collection, id) ON DELETE CASCADE
+    );
+  END IF;
+END $$;
+
+-- memory_reembed_jobs / memory_vector_bindings: embedding model migrations for memories
+CREATE TABLE IF NOT EXISTS memory_reembed_jobs (
+  id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id      TEXT        NOT NULL,
+  from_model  TEXT        NOT NULL DEFAULT '',
+  to_model    TEXT        NOT NULL,
+  status      TEXT        NOT NULL DEFAULT 'pending',
+  total       INT         NOT NULL DEFAULT 0,
+  processed   INT         NOT NULL DEFAULT 0,
+  failed      INT         NOT NULL DEFAULT 0,
+  cursor      TEXT        NOT NULL DEFAULT '',
+  error       TEXT        NOT NULL DEFAULT '',
+  started_at  TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_reembed_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_reembed_jobs_bot ON memory_reembed_jobs (bot_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_memory_reembed_jobs_active
+  ON memory_reembed_jobs (bot_id) WHERE status IN ('pending', 'running');
+
+CREATE TABLE IF NOT EXISTS memory_vector_bindings (
+  bot_id      TEXT        PRIMARY KEY,
+  vector_name TEXT        NOT NULL,
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- memory_history: edit log and provenance of memories
+CREATE TABLE IF NOT EXISTS memory_history (
+  id                  UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  memory_id           TEXT        NOT NULL,
+  bot_id              TEXT        NOT NULL DEFAULT '',
+  event               TEXT        NOT NULL,
+  source              TEXT        NOT NULL DEFAULT 'conversation',
+  old_memory          TEXT        NOT NULL DEFAULT '',
+  new_memory          TEXT        NOT NULL DEFAULT '',
+  message_ids         JSONB       NOT NULL DEFAULT '[]'::jsonb,
+  external_message_id TEXT        NOT NULL DEFAULT '',
+  chat_id             TEXT        NOT NULL DEFAULT '',
+  trace_id            TEXT        NOT NULL DEFAULT '',
+  channel             TEXT        NOT NULL DEFAULT '',
+  extractor_model     TEXT        NOT NULL DEFAULT '',
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_history_event_check CHECK (event IN ('ADD', 'UPDATE', 'DELETE'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_history_memory ON memory_history (bot_id, memory_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_memory_history_trace  ON memory_history (trace_id) WHERE trace_id <> '';
+
+-- bot_resource_limits: per-bot container cgroup limits (0 = unlimited)
+CREATE TABLE IF NOT EXISTS bot_resource_limits (
+  bot_id         UUID        PRIMARY KEY REFERENCES bots(id) ON DELETE CASCADE,
+  cpu_shares     INTEGER     NOT NULL DEFAULT 0,
+  cpu_millicores INTEGER     NOT NULL DEFAULT 0,
+  memory_mb      INTEGER     NOT NULL DEFAULT 0,
+  pids_limit     INTEGER     NOT NULL DEFAULT 0,
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_resource_limits_non_negative CHECK (
+    cpu_shares >= 0 AND cpu_millicores >= 0 AND memory_mb >= 0 AND pids_limit >= 0
+  )
+);
+
+-- bot_egress_policies: where a bot container may connect to
+CREATE TABLE IF NOT EXISTS bot_egress_policies (
+  bot_id          UUID        PRIMARY KEY REFERENCES bots(id) ON DELETE CASCADE,
+  mode            TEXT        NOT NULL DEFAULT 'unrestricted',
+  allowed_domains JSONB       NOT NULL DEFAULT '[]'::jsonb,
+  allowed_cidrs   JSONB       NOT NULL DEFAULT '[]'::jsonb,
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_egress_policies_mode_check CHECK (mode IN ('unrestricted', 'allowlist', 'none'))
+);
+
+-- token_budgets: daily/monthly token limits per bot, owner user or channel identity (0 = unlimited)
+CREATE TABLE IF NOT EXISTS token_budgets (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  scope              TEXT        NOT NULL,
+  scope_id           UUID        NOT NULL,
+  task_class         TEXT        NOT NULL DEFAULT 'interactive',
+  daily_limit        BIGINT      NOT NULL DEFAULT 0,
+  monthly_limit      BIGINT      NOT NULL DEFAULT 0,
+  soft_limit_percent INTEGER     NOT NULL DEFAULT 80,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT token_budgets_scope_check CHECK (scope IN ('bot', 'user', 'channel_identity')),
+  CONSTRAINT token_budgets_task_class_check CHECK (task_class IN ('interactive', 'background')),
+  CONSTRAINT token_budgets_limits_check CHECK (daily_limit >= 0 AND monthly_limit >= 0),
+  CONSTRAINT token_budgets_soft_limit_check CHECK (soft_limit_percent BETWEEN 0 AND 100),
+  CONSTRAINT token_budgets_unique UNIQUE (scope, scope_id, task_class)
+);
@@ -0,0 +1,3 @@
+-- 0043_memory_relations (down)
+DROP TABLE IF EXISTS memory_relations;
+DROP TABLE IF EXISTS memory_entities;
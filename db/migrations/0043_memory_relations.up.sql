@@ -0,0 +1,30 @@
+-- 0043_memory_relations
+-- Entity relation graph extracted from conversations. Entities are
+-- deduplicated per bot by normalized name; relations are (subject, predicate,
+-- object) edges with a mention counter.
+
+CREATE TABLE IF NOT EXISTS memory_entities (
+  id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id          TEXT        NOT NULL,
+  name            TEXT        NOT NULL,
+  normalized_name TEXT        NOT NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_entities_unique UNIQUE (bot_id, normalized_name)
+);
+
+CREATE TABLE IF NOT EXISTS memory_relations (
+  id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id     TEXT        NOT NULL,
+  subject_id UUID        NOT NULL REFERENCES memory_entities(id) ON DELETE CASCADE,
+  predicate  TEXT        NOT NULL,
+  object_id  UUID        NOT NULL REFERENCES memory_entities(id) ON DELETE CASCADE,
+  mentions   INT         NOT NULL DEFAULT 1,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_relations_unique UNIQUE (bot_id, subject_id, predicate, object_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_entities_bot     ON memory_entities (bot_id);
+CREATE INDEX IF NOT EXISTS idx_memory_relations_subject ON memory_relations (subject_id);
+CREATE INDEX IF NOT EXISTS idx_memory_relations_object  ON memory_relations (object_id);
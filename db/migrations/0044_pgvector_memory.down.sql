@@ -0,0 +1,4 @@
+-- 0044_pgvector_memory (down)
+DROP TABLE IF EXISTS memory_point_vectors;
+DROP TABLE IF EXISTS memory_sparse_terms;
+DROP TABLE IF EXISTS memory_points;
@@ -0,0 +1,41 @@
+-- 0044_pgvector_memory
+-- Postgres storage for memory points when memory.vector_store = "pgvector".
+-- Payloads and BM25 sparse terms use plain tables; dense vectors need the
+-- pgvector extension and are only created where it is available so that
+-- Qdrant-only deployments keep migrating cleanly.
+
+CREATE TABLE IF NOT EXISTS memory_points (
+  id             UUID        PRIMARY KEY,
+  collection     TEXT        NOT NULL,
+  payload        JSONB       NOT NULL DEFAULT '{}'::jsonb,
+  sparse_indices BIGINT[],
+  sparse_values  REAL[],
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_points_collection ON memory_points (collection);
+CREATE INDEX IF NOT EXISTS idx_memory_points_bot ON memory_points (collection, (payload ->> 'bot_id'));
+
+CREATE TABLE IF NOT EXISTS memory_sparse_terms (
+  point_id UUID   NOT NULL REFERENCES memory_points(id) ON DELETE CASCADE,
+  term     BIGINT NOT NULL,
+  value    REAL   NOT NULL,
+  PRIMARY KEY (point_id, term)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_sparse_terms_term ON memory_sparse_terms (term);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+    CREATE EXTENSION IF NOT EXISTS vector;
+    -- Dimension-less column: each vector_name (embedding model) may use its own size.
+    CREATE TABLE IF NOT EXISTS memory_point_vectors (
+      point_id    UUID   NOT NULL REFERENCES memory_points(id) ON DELETE CASCADE,
+      vector_name TEXT   NOT NULL,
+      embedding   vector NOT NULL,
+      PRIMARY KEY (point_id, vector_name)
+    );
+  END IF;
+END $$;
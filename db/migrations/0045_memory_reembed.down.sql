@@ -0,0 +1,4 @@
+-- 0045_memory_reembed (down)
+-- The process_log_step enum values are left in place (PostgreSQL cannot drop them).
+DROP TABLE IF EXISTS memory_vector_bindings;
+DROP TABLE IF EXISTS memory_reembed_jobs;
@@ -0,0 +1,42 @@
+-- 0045_memory_reembed
+-- Background re-embedding of a bot's memories into a new embedding model's
+-- named vector, plus the per-bot active vector binding swapped in when a job
+-- completes.
+
+CREATE TABLE IF NOT EXISTS memory_reembed_jobs (
+  id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id      TEXT        NOT NULL,
+  from_model  TEXT        NOT NULL DEFAULT '',
+  to_model    TEXT        NOT NULL,
+  status      TEXT        NOT NULL DEFAULT 'pending',
+  total       INT         NOT NULL DEFAULT 0,
+  processed   INT         NOT NULL DEFAULT 0,
+  failed      INT         NOT NULL DEFAULT 0,
+  cursor      TEXT        NOT NULL DEFAULT '',
+  error       TEXT        NOT NULL DEFAULT '',
+  started_at  TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_reembed_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_reembed_jobs_bot ON memory_reembed_jobs (bot_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_memory_reembed_jobs_active
+  ON memory_reembed_jobs (bot_id) WHERE status IN ('pending', 'running');
+
+CREATE TABLE IF NOT EXISTS memory_vector_bindings (
+  bot_id      TEXT        PRIMARY KEY,
+  vector_name TEXT        NOT NULL,
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DO $$ BEGIN
+  ALTER TYPE process_log_step ADD VALUE IF NOT EXISTS 'memory_reembed_started';
+EXCEPTION WHEN duplicate_object THEN null; END $$;
+DO $$ BEGIN
+  ALTER TYPE process_log_step ADD VALUE IF NOT EXISTS 'memory_reembed_completed';
+EXCEPTION WHEN duplicate_object THEN null; END $$;
+DO $$ BEGIN
+  ALTER TYPE process_log_step ADD VALUE IF NOT EXISTS 'memory_reembed_failed';
+EXCEPTION WHEN duplicate_object THEN null; END $$;
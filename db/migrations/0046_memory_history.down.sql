@@ -0,0 +1,2 @@
+-- 0046_memory_history (down)
+DROP TABLE IF EXISTS memory_history;
@@ -0,0 +1,25 @@
+-- 0046_memory_history
+-- Append-only edit log of memories. Each row records the decision event that
+-- changed a memory and the provenance of the round that caused it (persisted
+-- message IDs, trace, channel and extractor model).
+
+CREATE TABLE IF NOT EXISTS memory_history (
+  id                  UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  memory_id           TEXT        NOT NULL,
+  bot_id              TEXT        NOT NULL DEFAULT '',
+  event               TEXT        NOT NULL,
+  source              TEXT        NOT NULL DEFAULT 'conversation',
+  old_memory          TEXT        NOT NULL DEFAULT '',
+  new_memory          TEXT        NOT NULL DEFAULT '',
+  message_ids         JSONB       NOT NULL DEFAULT '[]'::jsonb,
+  external_message_id TEXT        NOT NULL DEFAULT '',
+  chat_id             TEXT        NOT NULL DEFAULT '',
+  trace_id            TEXT        NOT NULL DEFAULT '',
+  channel             TEXT        NOT NULL DEFAULT '',
+  extractor_model     TEXT        NOT NULL DEFAULT '',
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_history_event_check CHECK (event IN ('ADD', 'UPDATE', 'DELETE'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_history_memory ON memory_history (bot_id, memory_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_memory_history_trace  ON memory_history (trace_id) WHERE trace_id <> '';
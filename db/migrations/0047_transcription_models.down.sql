@@ -0,0 +1,4 @@
+-- 0047_transcription_models (down)
+DELETE FROM models WHERE type = 'transcription';
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_type_check;
+ALTER TABLE models ADD CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding'));
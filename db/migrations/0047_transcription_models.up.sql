@@ -0,0 +1,5 @@
+-- 0047_transcription_models
+-- Allow speech-to-text models, used to transcribe inbound voice and audio
+-- attachments before they reach the chat model.
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_type_check;
+ALTER TABLE models ADD CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'transcription'));
@@ -0,0 +1,2 @@
+-- 0048_bot_resource_limits (down)
+DROP TABLE IF EXISTS bot_resource_limits;
@@ -0,0 +1,16 @@
+-- 0048_bot_resource_limits
+-- Per-bot container resource profile: CPU shares and quota, memory and pids
+-- limits, and a writable-layer size cap. Zero means unlimited.
+
+CREATE TABLE IF NOT EXISTS bot_resource_limits (
+  bot_id         UUID        PRIMARY KEY REFERENCES bots(id) ON DELETE CASCADE,
+  cpu_shares     INTEGER     NOT NULL DEFAULT 0,
+  cpu_millicores INTEGER     NOT NULL DEFAULT 0,
+  memory_mb      INTEGER     NOT NULL DEFAULT 0,
+  pids_limit     INTEGER     NOT NULL DEFAULT 0,
+  disk_mb        INTEGER     NOT NULL DEFAULT 0,
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_resource_limits_non_negative CHECK (
+    cpu_shares >= 0 AND cpu_millicores >= 0 AND memory_mb >= 0 AND pids_limit >= 0 AND disk_mb >= 0
+  )
+);
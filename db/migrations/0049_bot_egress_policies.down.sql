@@ -0,0 +1,2 @@
+-- 0049_bot_egress_policies (down)
+DROP TABLE IF EXISTS bot_egress_policies;
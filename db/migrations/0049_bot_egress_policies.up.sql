@@ -0,0 +1,12 @@
+-- 0049_bot_egress_policies
+-- Per-bot container egress policy: no network, allowlisted domains/CIDRs, or
+-- unrestricted. Bots without a row are unrestricted.
+
+CREATE TABLE IF NOT EXISTS bot_egress_policies (
+  bot_id          UUID        PRIMARY KEY REFERENCES bots(id) ON DELETE CASCADE,
+  mode            TEXT        NOT NULL DEFAULT 'unrestricted',
+  allowed_domains JSONB       NOT NULL DEFAULT '[]'::jsonb,
+  allowed_cidrs   JSONB       NOT NULL DEFAULT '[]'::jsonb,
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_egress_policies_mode_check CHECK (mode IN ('unrestricted', 'allowlist', 'none'))
+);
@@ -0,0 +1,4 @@
+-- 0050_token_budgets (down)
+DROP TABLE IF EXISTS token_budgets;
+DROP INDEX IF EXISTS idx_token_usage_channel_identity_created;
+ALTER TABLE token_usage DROP COLUMN IF EXISTS channel_identity_id;
@@ -0,0 +1,24 @@
+-- 0050_token_budgets
+-- Daily and monthly token budgets per bot, owner user or channel identity.
+-- Interactive chat and background runs (heartbeat, schedule, subagent) are
+-- budgeted separately. A limit of 0 means unlimited.
+
+ALTER TABLE token_usage ADD COLUMN IF NOT EXISTS channel_identity_id UUID REFERENCES channel_identities(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_token_usage_channel_identity_created ON token_usage(channel_identity_id, created_at) WHERE channel_identity_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS token_budgets (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  scope              TEXT        NOT NULL,
+  scope_id           UUID        NOT NULL,
+  task_class         TEXT        NOT NULL DEFAULT 'interactive',
+  daily_limit        BIGINT      NOT NULL DEFAULT 0,
+  monthly_limit      BIGINT      NOT NULL DEFAULT 0,
+  soft_limit_percent INTEGER     NOT NULL DEFAULT 80,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT token_budgets_scope_check CHECK (scope IN ('bot', 'user', 'channel_identity')),
+  CONSTRAINT token_budgets_task_class_check CHECK (task_class IN ('interactive', 'background')),
+  CONSTRAINT token_budgets_limits_check CHECK (daily_limit >= 0 AND monthly_limit >= 0),
+  CONSTRAINT token_budgets_soft_limit_check CHECK (soft_limit_percent BETWEEN 0 AND 100),
+  CONSTRAINT token_budgets_unique UNIQUE (scope, scope_id, task_class)
+);
@@ -0,0 +1,6 @@
+-- 0051_model_pricing (down)
+ALTER TABLE token_usage DROP COLUMN IF EXISTS cost;
+ALTER TABLE token_usage DROP COLUMN IF EXISTS cached_tokens;
+ALTER TABLE models DROP COLUMN IF EXISTS cached_input_price;
+ALTER TABLE models DROP COLUMN IF EXISTS output_price;
+ALTER TABLE models DROP COLUMN IF EXISTS input_price;
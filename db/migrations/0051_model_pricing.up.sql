@@ -0,0 +1,10 @@
+-- 0051_model_pricing
+-- Per-model token prices (USD per 1M tokens) and the cost of each recorded
+-- call, computed from those prices when the usage is recorded.
+
+ALTER TABLE models ADD COLUMN IF NOT EXISTS input_price DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE models ADD COLUMN IF NOT EXISTS output_price DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE models ADD COLUMN IF NOT EXISTS cached_input_price DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE token_usage ADD COLUMN IF NOT EXISTS cached_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE token_usage ADD COLUMN IF NOT EXISTS cost DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- 0052_schedule_runs (down)
+DROP TABLE IF EXISTS schedule_runs;
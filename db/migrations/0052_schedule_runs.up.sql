@@ -0,0 +1,28 @@
+-- 0052_schedule_runs
+-- One row per schedule execution (cron or manual trigger) with its outcome,
+-- trace ID, token usage and a preview of the reply.
+
+CREATE TABLE IF NOT EXISTS schedule_runs (
+  id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  schedule_id       UUID        NOT NULL REFERENCES schedule(id) ON DELETE CASCADE,
+  bot_id            UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  trigger_type      TEXT        NOT NULL DEFAULT 'cron',
+  status            TEXT        NOT NULL DEFAULT 'running',
+  error             TEXT        NOT NULL DEFAULT '',
+  trace_id          TEXT        NOT NULL DEFAULT '',
+  model             TEXT        NOT NULL DEFAULT '',
+  prompt_tokens     INTEGER     NOT NULL DEFAULT 0,
+  completion_tokens INTEGER     NOT NULL DEFAULT 0,
+  total_tokens      INTEGER     NOT NULL DEFAULT 0,
+  reply_preview     TEXT        NOT NULL DEFAULT '',
+  platform          TEXT        NOT NULL DEFAULT '',
+  reply_target      TEXT        NOT NULL DEFAULT '',
+  started_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at       TIMESTAMPTZ,
+  duration_ms       INTEGER,
+  CONSTRAINT schedule_runs_trigger_type_check CHECK (trigger_type IN ('cron', 'manual')),
+  CONSTRAINT schedule_runs_status_check CHECK (status IN ('running', 'success', 'error'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_schedule_started ON schedule_runs(schedule_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_bot_started ON schedule_runs(bot_id, started_at DESC);
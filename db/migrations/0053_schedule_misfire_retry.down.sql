@@ -0,0 +1,11 @@
+-- 0053_schedule_misfire_retry (down)
+DELETE FROM schedule_runs WHERE trigger_type = 'catchup';
+ALTER TABLE schedule_runs DROP CONSTRAINT IF EXISTS schedule_runs_trigger_type_check;
+ALTER TABLE schedule_runs ADD CONSTRAINT schedule_runs_trigger_type_check CHECK (trigger_type IN ('cron', 'manual'));
+ALTER TABLE schedule_runs DROP COLUMN IF EXISTS scheduled_for;
+ALTER TABLE schedule_runs DROP COLUMN IF EXISTS attempt;
+
+ALTER TABLE schedule DROP CONSTRAINT IF EXISTS schedule_misfire_policy_check;
+ALTER TABLE schedule DROP COLUMN IF EXISTS retry_backoff_seconds;
+ALTER TABLE schedule DROP COLUMN IF EXISTS retry_max_attempts;
+ALTER TABLE schedule DROP COLUMN IF EXISTS misfire_policy;
@@ -0,0 +1,15 @@
+-- 0053_schedule_misfire_retry
+-- Per-schedule misfire policy (what to do with fire times missed while the
+-- server was down) and retry policy for failed triggers. Runs record their
+-- attempt number and, for catch-up runs, the fire time they stand in for.
+
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS misfire_policy TEXT NOT NULL DEFAULT 'skip';
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS retry_max_attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS retry_backoff_seconds INTEGER NOT NULL DEFAULT 60;
+ALTER TABLE schedule DROP CONSTRAINT IF EXISTS schedule_misfire_policy_check;
+ALTER TABLE schedule ADD CONSTRAINT schedule_misfire_policy_check CHECK (misfire_policy IN ('skip', 'run_once', 'run_all'));
+
+ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schedule_runs ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
+ALTER TABLE schedule_runs DROP CONSTRAINT IF EXISTS schedule_runs_trigger_type_check;
+ALTER TABLE schedule_runs ADD CONSTRAINT schedule_runs_trigger_type_check CHECK (trigger_type IN ('cron', 'manual', 'catchup'));
@@ -0,0 +1,9 @@
+-- 0054_schedule_kinds (down)
+DELETE FROM schedule WHERE kind <> 'cron';
+ALTER TABLE schedule DROP CONSTRAINT IF EXISTS schedule_kind_check;
+ALTER TABLE schedule DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE schedule DROP COLUMN IF EXISTS timezone;
+ALTER TABLE schedule DROP COLUMN IF EXISTS start_at;
+ALTER TABLE schedule DROP COLUMN IF EXISTS interval_seconds;
+ALTER TABLE schedule DROP COLUMN IF EXISTS run_at;
+ALTER TABLE schedule DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,13 @@
+-- 0054_schedule_kinds
+-- Schedules can fire once at run_at or every interval_seconds from start_at
+-- besides the cron pattern, and may set their own IANA timezone. One-shot
+-- schedules are archived once they have run.
+
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'cron';
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS run_at TIMESTAMPTZ;
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS interval_seconds INTEGER;
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ;
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '';
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE schedule DROP CONSTRAINT IF EXISTS schedule_kind_check;
+ALTER TABLE schedule ADD CONSTRAINT schedule_kind_check CHECK (kind IN ('cron', 'once', 'interval'));
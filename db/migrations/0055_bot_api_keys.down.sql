@@ -0,0 +1,2 @@
+-- 0055_bot_api_keys (down)
+DROP TABLE IF EXISTS bot_api_keys;
@@ -0,0 +1,17 @@
+-- 0055_bot_api_keys
+-- Per-bot API keys for the OpenAI-compatible /v1 endpoints. Only the SHA-256
+-- hash of a key is stored; the prefix is kept so owners can tell keys apart.
+
+CREATE TABLE IF NOT EXISTS bot_api_keys (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id             UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name               TEXT        NOT NULL DEFAULT '',
+  key_prefix         TEXT        NOT NULL,
+  key_hash           TEXT        NOT NULL,
+  created_by_user_id UUID        REFERENCES users(id) ON DELETE SET NULL,
+  last_used_at       TIMESTAMPTZ,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_api_keys_hash_unique UNIQUE (key_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_api_keys_bot_id ON bot_api_keys(bot_id);
@@ -0,0 +1,3 @@
+-- 0056_access_tokens (down)
+DROP TABLE IF EXISTS personal_access_tokens;
+ALTER TABLE bot_api_keys DROP COLUMN IF EXISTS scopes;
@@ -0,0 +1,20 @@
+-- 0056_access_tokens
+-- Scopes for bot API keys and long-lived personal access tokens. Like bot
+-- keys, tokens are stored as SHA-256 hashes only.
+
+ALTER TABLE bot_api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{chat}';
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name         TEXT        NOT NULL DEFAULT '',
+  token_prefix TEXT        NOT NULL,
+  token_hash   TEXT        NOT NULL,
+  scopes       TEXT[]      NOT NULL DEFAULT '{}',
+  expires_at   TIMESTAMPTZ,
+  last_used_at TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT personal_access_tokens_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
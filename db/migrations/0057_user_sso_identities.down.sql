@@ -0,0 +1,2 @@
+-- 0057_user_sso_identities (down)
+DROP TABLE IF EXISTS user_sso_identities;
@@ -0,0 +1,14 @@
+-- 0057_user_sso_identities
+-- Links users to the OpenID Connect subjects they sign in with.
+
+CREATE TABLE IF NOT EXISTS user_sso_identities (
+  id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  issuer     TEXT        NOT NULL,
+  subject    TEXT        NOT NULL,
+  email      TEXT        NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT user_sso_identities_subject_unique UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sso_identities_user_id ON user_sso_identities(user_id);
@@ -0,0 +1,3 @@
+-- 0058_webhooks (down)
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS bot_webhooks;
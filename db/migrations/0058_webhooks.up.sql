@@ -0,0 +1,38 @@
+-- 0058_webhooks
+-- Outgoing webhook subscriptions per bot and their delivery log. Secrets are
+-- sealed like other credentials; deliveries are retried with backoff.
+
+CREATE TABLE IF NOT EXISTS bot_webhooks (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id             UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name               TEXT        NOT NULL DEFAULT '',
+  url                TEXT        NOT NULL,
+  secret             TEXT        NOT NULL,
+  events             TEXT[]      NOT NULL DEFAULT '{}',
+  enabled            BOOLEAN     NOT NULL DEFAULT true,
+  created_by_user_id UUID        REFERENCES users(id) ON DELETE SET NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_webhooks_bot_id ON bot_webhooks(bot_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  webhook_id       UUID        NOT NULL REFERENCES bot_webhooks(id) ON DELETE CASCADE,
+  bot_id           UUID        NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  event_type       TEXT        NOT NULL,
+  payload          JSONB       NOT NULL,
+  status           TEXT        NOT NULL DEFAULT 'pending',
+  attempts         INTEGER     NOT NULL DEFAULT 0,
+  next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_status_code INTEGER     NOT NULL DEFAULT 0,
+  last_error       TEXT        NOT NULL DEFAULT '',
+  delivered_at     TIMESTAMPTZ,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
@@ -0,0 +1,44 @@
+-- 0059_memory_points_collection_key (down)
+DO $$
+DECLARE
+  idx TEXT;
+BEGIN
+  FOR idx IN SELECT indexname FROM pg_indexes WHERE tablename = 'memory_point_vectors' AND indexname LIKE 'idx_memory_point_vectors_hnsw_%' LOOP
+    EXECUTE format('DROP INDEX IF EXISTS %I', idx);
+  END LOOP;
+
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'memory_sparse_terms' AND column_name = 'collection'
+  ) THEN
+    ALTER TABLE memory_sparse_terms DROP CONSTRAINT IF EXISTS memory_sparse_terms_point_fkey;
+    ALTER TABLE memory_sparse_terms DROP CONSTRAINT IF EXISTS memory_sparse_terms_pkey;
+    IF to_regclass('memory_point_vectors') IS NOT NULL THEN
+      ALTER TABLE memory_point_vectors DROP CONSTRAINT IF EXISTS memory_point_vectors_point_fkey;
+      ALTER TABLE memory_point_vectors DROP CONSTRAINT IF EXISTS memory_point_vectors_pkey;
+    END IF;
+
+    -- Points duplicated across collections cannot share a single-column key;
+    -- keep the most recently updated copy.
+    DELETE FROM memory_points p USING memory_points q
+      WHERE p.id = q.id AND (p.updated_at, p.collection) < (q.updated_at, q.collection);
+    DELETE FROM memory_sparse_terms t WHERE NOT EXISTS (
+      SELECT 1 FROM memory_points p WHERE p.collection = t.collection AND p.id = t.point_id);
+    ALTER TABLE memory_points DROP CONSTRAINT IF EXISTS memory_points_pkey;
+    ALTER TABLE memory_points ADD CONSTRAINT memory_points_pkey PRIMARY KEY (id);
+
+    ALTER TABLE memory_sparse_terms DROP COLUMN collection;
+    ALTER TABLE memory_sparse_terms ADD CONSTRAINT memory_sparse_terms_pkey PRIMARY KEY (point_id, term);
+    ALTER TABLE memory_sparse_terms ADD CONSTRAINT memory_sparse_terms_point_id_fkey
+      FOREIGN KEY (point_id) REFERENCES memory_points(id) ON DELETE CASCADE;
+
+    IF to_regclass('memory_point_vectors') IS NOT NULL THEN
+      DELETE FROM memory_point_vectors v WHERE NOT EXISTS (
+        SELECT 1 FROM memory_points p WHERE p.collection = v.collection AND p.id = v.point_id);
+      ALTER TABLE memory_point_vectors DROP COLUMN collection;
+      ALTER TABLE memory_point_vectors ADD CONSTRAINT memory_point_vectors_pkey PRIMARY KEY (point_id, vector_name);
+      ALTER TABLE memory_point_vectors ADD CONSTRAINT memory_point_vectors_point_id_fkey
+        FOREIGN KEY (point_id) REFERENCES memory_points(id) ON DELETE CASCADE;
+    END IF;
+  END IF;
+END $$;
@@ -0,0 +1,61 @@
+-- 0059_memory_points_collection_key
+-- Key pgvector memory points by (collection, id) so that sibling collections
+-- (e.g. re-embedding targets) can hold the same point ID without an upsert in
+-- one moving the point out of another, and add HNSW indexes for dense search.
+-- memory_point_vectors has no fixed dimension, so each dimension in use gets
+-- its own partial expression index; the store creates indexes for dimensions
+-- that first appear after this migration.
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'memory_sparse_terms' AND column_name = 'collection'
+  ) THEN
+    ALTER TABLE memory_sparse_terms ADD COLUMN collection TEXT;
+    UPDATE memory_sparse_terms t SET collection = p.collection FROM memory_points p WHERE p.id = t.point_id;
+    ALTER TABLE memory_sparse_terms ALTER COLUMN collection SET NOT NULL;
+    ALTER TABLE memory_sparse_terms DROP CONSTRAINT IF EXISTS memory_sparse_terms_point_id_fkey;
+    ALTER TABLE memory_sparse_terms DROP CONSTRAINT IF EXISTS memory_sparse_terms_pkey;
+
+    IF to_regclass('memory_point_vectors') IS NOT NULL THEN
+      ALTER TABLE memory_point_vectors ADD COLUMN collection TEXT;
+      UPDATE memory_point_vectors v SET collection = p.collection FROM memory_points p WHERE p.id = v.point_id;
+      ALTER TABLE memory_point_vectors ALTER COLUMN collection SET NOT NULL;
+      ALTER TABLE memory_point_vectors DROP CONSTRAINT IF EXISTS memory_point_vectors_point_id_fkey;
+      ALTER TABLE memory_point_vectors DROP CONSTRAINT IF EXISTS memory_point_vectors_pkey;
+    END IF;
+
+    ALTER TABLE memory_points DROP CONSTRAINT IF EXISTS memory_points_pkey;
+    ALTER TABLE memory_points ADD CONSTRAINT memory_points_pkey PRIMARY KEY (collection, id);
+
+    ALTER TABLE memory_sparse_terms ADD CONSTRAINT memory_sparse_terms_pkey PRIMARY KEY (collection, point_id, term);
+    ALTER TABLE memory_sparse_terms ADD CONSTRAINT memory_sparse_terms_point_fkey
+      FOREIGN KEY (collection, point_id) REFERENCES memory_points(collection, id) ON DELETE CASCADE;
+
+    IF to_regclass('memory_point_vectors') IS NOT NULL THEN
+      ALTER TABLE memory_point_vectors ADD CONSTRAINT memory_point_vectors_pkey PRIMARY KEY (collection, point_id, vector_name);
+      ALTER TABLE memory_point_vectors ADD CONSTRAINT memory_point_vectors_point_fkey
+        FOREIGN KEY (collection, point_id) REFERENCES memory_points(collection, id) ON DELETE CASCADE;
+    END IF;
+  END IF;
+END $$;
+
+-- HNSW indexes for the dimensions already stored. pgvector indexes at most
+-- 2000 dimensions; larger vectors keep using exact search.
+DO $$
+DECLARE
+  dim INT;
+BEGIN
+  IF to_regclass('memory_point_vectors') IS NOT NULL
+     AND EXISTS (SELECT 1 FROM pg_am WHERE amname = 'hnsw') THEN
+    FOR dim IN SELECT DISTINCT vector_dims(embedding) FROM memory_point_vectors LOOP
+      IF dim <= 2000 THEN
+        EXECUTE format(
+          'CREATE INDEX IF NOT EXISTS idx_memory_point_vectors_hnsw_%s ON memory_point_vectors '
+          'USING hnsw ((embedding::vector(%s)) vector_cosine_ops) WHERE vector_dims(embedding) = %s',
+          dim, dim, dim);
+      END IF;
+    END LOOP;
+  END IF;
+END $$;
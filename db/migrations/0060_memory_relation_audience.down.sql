@@ -0,0 +1,11 @@
+-- 0060_memory_relation_audience (down)
+-- Keep the most mentioned copy of triples stored for several audiences.
+DELETE FROM memory_relations r USING memory_relations k
+  WHERE r.bot_id = k.bot_id AND r.subject_id = k.subject_id AND r.predicate = k.predicate AND r.object_id = k.object_id
+    AND (r.mentions, r.id) < (k.mentions, k.id);
+
+ALTER TABLE memory_relations DROP CONSTRAINT IF EXISTS memory_relations_unique;
+ALTER TABLE memory_relations DROP COLUMN IF EXISTS audience_identity;
+ALTER TABLE memory_relations DROP COLUMN IF EXISTS audience_group;
+ALTER TABLE memory_relations DROP COLUMN IF EXISTS sensitivity;
+ALTER TABLE memory_relations ADD CONSTRAINT memory_relations_unique UNIQUE (bot_id, subject_id, predicate, object_id);
@@ -0,0 +1,24 @@
+-- 0060_memory_relation_audience
+-- Relations inherit the sensitivity of the conversation they were extracted
+-- from and are filtered by the same audience rule as memories. The same
+-- triple learned in different audiences is stored once per audience.
+-- Relations stored before this migration have unknown origin (they may come
+-- from direct messages), so they are marked private without an identity:
+-- hidden from every conversation, still visible to owner reads.
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'memory_relations' AND column_name = 'sensitivity'
+  ) THEN
+    ALTER TABLE memory_relations ADD COLUMN sensitivity TEXT NOT NULL DEFAULT '';
+    ALTER TABLE memory_relations ADD COLUMN audience_group TEXT NOT NULL DEFAULT '';
+    ALTER TABLE memory_relations ADD COLUMN audience_identity TEXT NOT NULL DEFAULT '';
+    UPDATE memory_relations SET sensitivity = 'private';
+
+    ALTER TABLE memory_relations DROP CONSTRAINT IF EXISTS memory_relations_unique;
+    ALTER TABLE memory_relations ADD CONSTRAINT memory_relations_unique
+      UNIQUE (bot_id, subject_id, predicate, object_id, sensitivity, audience_group, audience_identity);
+  END IF;
+END $$;
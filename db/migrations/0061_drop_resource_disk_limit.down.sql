@@ -0,0 +1,15 @@
+-- 0061_drop_resource_disk_limit (down)
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'bot_resource_limits' AND column_name = 'disk_mb'
+  ) THEN
+    ALTER TABLE bot_resource_limits ADD COLUMN disk_mb INTEGER NOT NULL DEFAULT 0;
+    ALTER TABLE bot_resource_limits DROP CONSTRAINT IF EXISTS bot_resource_limits_non_negative;
+    ALTER TABLE bot_resource_limits ADD CONSTRAINT bot_resource_limits_non_negative CHECK (
+      cpu_shares >= 0 AND cpu_millicores >= 0 AND memory_mb >= 0 AND pids_limit >= 0 AND disk_mb >= 0
+    );
+  END IF;
+END $$;
@@ -0,0 +1,18 @@
+-- 0061_drop_resource_disk_limit
+-- The writable-layer size cap was never enforced (the overlayfs snapshotter
+-- has no per-snapshot quota), so the column is dropped instead of suggesting
+-- a limit that does not hold.
+
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'bot_resource_limits' AND column_name = 'disk_mb'
+  ) THEN
+    ALTER TABLE bot_resource_limits DROP CONSTRAINT IF EXISTS bot_resource_limits_non_negative;
+    ALTER TABLE bot_resource_limits DROP COLUMN disk_mb;
+    ALTER TABLE bot_resource_limits ADD CONSTRAINT bot_resource_limits_non_negative CHECK (
+      cpu_shares >= 0 AND cpu_millicores >= 0 AND memory_mb >= 0 AND pids_limit >= 0
+    );
+  END IF;
+END $$;
@@ -0,0 +1,3 @@
+-- 0062_token_usage_cost_numeric (down)
+
+ALTER TABLE token_usage ALTER COLUMN cost TYPE DOUBLE PRECISION USING cost::double precision;
@@ -0,0 +1,13 @@
+-- 0062_token_usage_cost_numeric
+-- Store per-call cost as an exact decimal so sums over many small calls do
+-- not drift. Ten decimal places keep sub-cent prices per token exact.
+
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'token_usage' AND column_name = 'cost' AND data_type = 'double precision'
+  ) THEN
+    ALTER TABLE token_usage ALTER COLUMN cost TYPE NUMERIC(20,10) USING round(cost::numeric, 10);
+  END IF;
+END $$;
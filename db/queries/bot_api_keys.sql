@@ -0,0 +1,22 @@
+-- name: CreateBotApiKey :one
+INSERT INTO bot_api_keys (bot_id, name, key_prefix, key_hash, created_by_user_id, scopes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetBotApiKeyByHash :one
+SELECT * FROM bot_api_keys
+WHERE key_hash = $1;
+
+-- name: ListBotApiKeysByBot :many
+SELECT * FROM bot_api_keys
+WHERE bot_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeleteBotApiKey :execrows
+DELETE FROM bot_api_keys
+WHERE id = $1 AND bot_id = $2;
+
+-- name: TouchBotApiKey :exec
+UPDATE bot_api_keys
+SET last_used_at = now()
+WHERE id = $1;
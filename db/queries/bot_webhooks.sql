@@ -0,0 +1,33 @@
+-- name: CreateBotWebhook :one
+INSERT INTO bot_webhooks (bot_id, name, url, secret, events, enabled, created_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetBotWebhook :one
+SELECT * FROM bot_webhooks
+WHERE id = $1;
+
+-- name: ListBotWebhooksByBot :many
+SELECT * FROM bot_webhooks
+WHERE bot_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListEnabledBotWebhooksByBot :many
+SELECT * FROM bot_webhooks
+WHERE bot_id = $1 AND enabled = true;
+
+-- name: UpdateBotWebhook :one
+UPDATE bot_webhooks
+SET name = $2, url = $3, events = $4, enabled = $5, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateBotWebhookSecret :one
+UPDATE bot_webhooks
+SET secret = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteBotWebhook :execrows
+DELETE FROM bot_webhooks
+WHERE id = $1 AND bot_id = $2;
@@ -1,21 +1,27 @@
 -- name: CreateSchedule :one
-INSERT INTO schedule (name, description, pattern, max_calls, enabled, command, bot_id, platform, reply_target)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target;
+INSERT INTO schedule (name, description, pattern, max_calls, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at;
 
 -- name: GetScheduleByID :one
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 FROM schedule
 WHERE id = $1;
 
 -- name: ListSchedulesByBot :many
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 FROM schedule
-WHERE bot_id = $1
+WHERE bot_id = $1 AND archived_at IS NULL
 ORDER BY created_at DESC;
 
+-- name: ListArchivedSchedulesByBot :many
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
+FROM schedule
+WHERE bot_id = $1 AND archived_at IS NOT NULL
+ORDER BY archived_at DESC;
+
 -- name: ListEnabledSchedules :many
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 FROM schedule
 WHERE enabled = true
 ORDER BY created_at DESC;
@@ -28,9 +34,18 @@ SET name = $2,
     max_calls = $5,
     enabled = $6,
     command = $7,
+    misfire_policy = $8,
+    retry_max_attempts = $9,
+    retry_backoff_seconds = $10,
+    kind = $11,
+    run_at = $12,
+    interval_seconds = $13,
+    start_at = $14,
+    timezone = $15,
+    archived_at = CASE WHEN $6::boolean THEN NULL ELSE archived_at END,
     updated_at = now()
 WHERE id = $1
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target;
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at;
 
 -- name: DeleteSchedule :exec
 DELETE FROM schedule
@@ -45,4 +60,12 @@ SET current_calls = current_calls + 1,
     END,
     updated_at = now()
 WHERE id = $1
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target;
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at;
+
+-- name: ArchiveSchedule :one
+UPDATE schedule
+SET enabled = false,
+    archived_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at;
@@ -0,0 +1,49 @@
+-- name: CreateScheduleRun :one
+INSERT INTO schedule_runs (schedule_id, bot_id, trigger_type, platform, reply_target, attempt, scheduled_for)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: CompleteScheduleRun :one
+UPDATE schedule_runs
+SET status = $2,
+    error = $3,
+    trace_id = $4,
+    model = $5,
+    prompt_tokens = $6,
+    completion_tokens = $7,
+    total_tokens = $8,
+    reply_preview = $9,
+    finished_at = now(),
+    duration_ms = (EXTRACT(EPOCH FROM (now() - started_at)) * 1000)::integer
+WHERE id = $1
+RETURNING *;
+
+-- name: GetScheduleRunByID :one
+SELECT * FROM schedule_runs
+WHERE id = $1;
+
+-- name: GetLastScheduledRun :one
+SELECT * FROM schedule_runs
+WHERE schedule_id = $1 AND trigger_type <> 'manual'
+ORDER BY COALESCE(scheduled_for, started_at) DESC
+LIMIT 1;
+
+-- name: ListScheduleRunsBySchedule :many
+SELECT * FROM schedule_runs
+WHERE schedule_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: ListScheduleRunsByBot :many
+SELECT * FROM schedule_runs
+WHERE bot_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: FailRunningScheduleRuns :execrows
+UPDATE schedule_runs
+SET status = 'error',
+    error = sqlc.arg(reason)::text,
+    finished_at = now(),
+    duration_ms = (EXTRACT(EPOCH FROM (now() - started_at)) * 1000)::integer
+WHERE status = 'running';
@@ -1,6 +1,6 @@
 -- name: RecordTokenUsage :one
-INSERT INTO token_usage (bot_id, prompt_tokens, completion_tokens, total_tokens, model, source)
-VALUES (@bot_id, @prompt_tokens, @completion_tokens, @total_tokens, @model, @source)
+INSERT INTO token_usage (bot_id, prompt_tokens, completion_tokens, total_tokens, model, source, channel_identity_id, cached_tokens, cost)
+VALUES (@bot_id, @prompt_tokens, @completion_tokens, @total_tokens, @model, @source, sqlc.narg(channel_identity_id), @cached_tokens, @cost)
 RETURNING *;
 
 -- name: GetBotTokenTotal :one
@@ -55,3 +55,78 @@ SELECT
   COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens
 FROM token_usage
 GROUP BY bot_id;
+
+-- name: GetCostByBot :many
+SELECT
+  bot_id,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+  COALESCE(SUM(cached_tokens), 0)::bigint AS cached_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= @since
+  AND created_at < @until
+GROUP BY bot_id
+ORDER BY cost DESC;
+
+-- name: GetCostByModel :many
+SELECT
+  model,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+  COALESCE(SUM(cached_tokens), 0)::bigint AS cached_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= @since
+  AND created_at < @until
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id))
+GROUP BY model
+ORDER BY cost DESC;
+
+-- name: GetCostBySource :many
+SELECT
+  source,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+  COALESCE(SUM(cached_tokens), 0)::bigint AS cached_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= @since
+  AND created_at < @until
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id))
+GROUP BY source
+ORDER BY cost DESC;
+
+-- name: GetCostDailySeries :many
+SELECT
+  date_trunc('day', created_at)::date AS day,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= @since
+  AND created_at < @until
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id))
+GROUP BY date_trunc('day', created_at)
+ORDER BY day;
+
+-- name: ListTokenUsageForExport :many
+SELECT
+  tu.created_at,
+  tu.bot_id,
+  COALESCE(b.display_name, '')::text AS bot_name,
+  tu.model,
+  tu.source,
+  tu.prompt_tokens,
+  tu.completion_tokens,
+  tu.cached_tokens,
+  tu.total_tokens,
+  tu.cost
+FROM token_usage tu
+LEFT JOIN bots b ON b.id = tu.bot_id
+WHERE tu.created_at >= @since
+  AND tu.created_at < @until
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR tu.bot_id = sqlc.narg(bot_id))
+ORDER BY tu.created_at;
@@ -0,0 +1,8 @@
+-- name: CreateUserSSOIdentity :one
+INSERT INTO user_sso_identities (user_id, issuer, subject, email)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserSSOIdentity :one
+SELECT * FROM user_sso_identities
+WHERE issuer = $1 AND subject = $2;
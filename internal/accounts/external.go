@@ -0,0 +1,253 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+)
+
+// maxUsernameAttempts bounds the suffixes tried when a provisioned username
+// is taken.
+const maxUsernameAttempts = 50
+
+// ExternalLogin is a user authenticated by an identity provider.
+type ExternalLogin struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// Username is the preferred name for a new user; a suffix is added when
+	// it is taken.
+	Username    string
+	DisplayName string
+	AvatarURL   string
+	// Role is checked with the same rules as for local accounts.
+	Role string
+	// SyncRole applies Role on every login instead of only on the first.
+	SyncRole bool
+	// LinkByEmail signs a new subject into the account with the same
+	// verified email instead of creating one.
+	LinkByEmail bool
+}
+
+// LoginExternal signs in the user linked to the provider subject, creating
+// the user on first login. Provisioned users have no password.
+func (s *Service) LoginExternal(ctx context.Context, in ExternalLogin) (Account, error) {
+	if s.queries == nil {
+		return Account{}, fmt.Errorf("account queries not configured")
+	}
+	in.Issuer = strings.TrimSpace(in.Issuer)
+	in.Subject = strings.TrimSpace(in.Subject)
+	in.Email = strings.TrimSpace(in.Email)
+	if in.Issuer == "" || in.Subject == "" {
+		return Account{}, fmt.Errorf("issuer and subject are required")
+	}
+	role, err := normalizeRole(in.Role)
+	if err != nil {
+		return Account{}, err
+	}
+
+	row, err := s.linkedUser(ctx, in)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		row, err = s.provisionUser(ctx, in, role)
+		if err != nil {
+			return Account{}, err
+		}
+	case err != nil:
+		return Account{}, err
+	default:
+		if !row.IsActive {
+			return Account{}, ErrInactiveAccount
+		}
+		if in.SyncRole && row.Role != role {
+			row, err = s.queries.UpdateAccountAdmin(ctx, sqlc.UpdateAccountAdminParams{
+				Role:        role,
+				DisplayName: row.DisplayName,
+				AvatarUrl:   row.AvatarUrl,
+				IsActive:    row.IsActive,
+				UserID:      row.ID,
+			})
+			if err != nil {
+				return Account{}, err
+			}
+		}
+	}
+	if _, err := s.queries.UpdateAccountLastLogin(ctx, row.ID); err != nil {
+		s.logger.Warn("touch last login failed", slog.Any("error", err))
+	}
+	return toAccount(row), nil
+}
+
+// linkedUser finds the user of the subject, linking it by email first when
+// allowed. It returns pgx.ErrNoRows when a new user is needed.
+func (s *Service) linkedUser(ctx context.Context, in ExternalLogin) (sqlc.User, error) {
+	link, err := s.queries.GetUserSSOIdentity(ctx, sqlc.GetUserSSOIdentityParams{Issuer: in.Issuer, Subject: in.Subject})
+	if err == nil {
+		return s.queries.GetAccountByUserID(ctx, link.UserID)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return sqlc.User{}, err
+	}
+	if !in.LinkByEmail || !in.EmailVerified || in.Email == "" {
+		return sqlc.User{}, pgx.ErrNoRows
+	}
+	row, err := s.queries.GetAccountByIdentity(ctx, pgtype.Text{String: in.Email, Valid: true})
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	if !strings.EqualFold(row.Email.String, in.Email) {
+		return sqlc.User{}, pgx.ErrNoRows
+	}
+	if err := linkIdentity(ctx, s.queries, row.ID, in); err != nil {
+		return sqlc.User{}, err
+	}
+	s.logger.Info("linked sso identity by email", slog.String("user_id", row.ID.String()), slog.String("issuer", in.Issuer))
+	return row, nil
+}
+
+// provisionUser creates the user, its account and the SSO link in one
+// transaction. If a concurrent first login of the same subject commits
+// first, the transaction fails on a unique constraint and the user created
+// by that login is returned instead.
+func (s *Service) provisionUser(ctx context.Context, in ExternalLogin, role string) (sqlc.User, error) {
+	row, err := s.createExternalUser(ctx, in, role)
+	if err == nil || !db.IsUniqueViolation(err) {
+		return row, err
+	}
+	link, lookupErr := s.queries.GetUserSSOIdentity(ctx, sqlc.GetUserSSOIdentityParams{Issuer: in.Issuer, Subject: in.Subject})
+	if lookupErr == nil {
+		return s.queries.GetAccountByUserID(ctx, link.UserID)
+	}
+	if !errors.Is(lookupErr, pgx.ErrNoRows) {
+		return sqlc.User{}, lookupErr
+	}
+	// Another login took the username or email meanwhile; pick again.
+	return s.createExternalUser(ctx, in, role)
+}
+
+func (s *Service) createExternalUser(ctx context.Context, in ExternalLogin, role string) (sqlc.User, error) {
+	if s.pool == nil {
+		return sqlc.User{}, fmt.Errorf("account database not configured")
+	}
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("begin provision tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	qtx := s.queries.WithTx(tx)
+
+	username, err := availableUsername(ctx, qtx, usernameBase(in))
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	// The email column is unique; leave it empty rather than fail when
+	// another account already uses it.
+	email := pgtype.Text{}
+	if in.Email != "" {
+		if _, err := qtx.GetAccountByIdentity(ctx, pgtype.Text{String: in.Email, Valid: true}); errors.Is(err, pgx.ErrNoRows) {
+			email = pgtype.Text{String: in.Email, Valid: true}
+		}
+	}
+	displayName := strings.TrimSpace(in.DisplayName)
+	if displayName == "" {
+		displayName = username
+	}
+	avatar := pgtype.Text{}
+	if v := strings.TrimSpace(in.AvatarURL); v != "" {
+		avatar = pgtype.Text{String: v, Valid: true}
+	}
+
+	userRow, err := qtx.CreateUser(ctx, sqlc.CreateUserParams{
+		IsActive: true,
+		Metadata: []byte("{}"),
+	})
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	row, err := qtx.CreateAccount(ctx, sqlc.CreateAccountParams{
+		UserID:      userRow.ID,
+		Username:    pgtype.Text{String: username, Valid: true},
+		Email:       email,
+		Role:        role,
+		DisplayName: pgtype.Text{String: displayName, Valid: true},
+		AvatarUrl:   avatar,
+		IsActive:    true,
+	})
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	if err := linkIdentity(ctx, qtx, row.ID, in); err != nil {
+		return sqlc.User{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return sqlc.User{}, fmt.Errorf("commit provision tx: %w", err)
+	}
+	s.logger.Info("provisioned sso user", slog.String("user_id", row.ID.String()), slog.String("username", username), slog.String("role", role))
+	return row, nil
+}
+
+func linkIdentity(ctx context.Context, queries *sqlc.Queries, userID pgtype.UUID, in ExternalLogin) error {
+	_, err := queries.CreateUserSSOIdentity(ctx, sqlc.CreateUserSSOIdentityParams{
+		UserID:  userID,
+		Issuer:  in.Issuer,
+		Subject: in.Subject,
+		Email:   in.Email,
+	})
+	return err
+}
+
+// availableUsername returns base, or base with the first free numeric
+// suffix.
+func availableUsername(ctx context.Context, queries *sqlc.Queries, base string) (string, error) {
+	for i := 1; i <= maxUsernameAttempts; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s%d", base, i)
+		}
+		_, err := queries.GetAccountByIdentity(ctx, pgtype.Text{String: candidate, Valid: true})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free username for %q", base)
+}
+
+// usernameBase picks a username from the login: the preferred username, the
+// local part of the email, or "user".
+func usernameBase(in ExternalLogin) string {
+	candidates := []string{in.Username}
+	if at := strings.Index(in.Email, "@"); at > 0 {
+		candidates = append(candidates, in.Email[:at])
+	}
+	for _, c := range candidates {
+		if name := sanitizeUsername(c); name != "" {
+			return name
+		}
+	}
+	return "user"
+}
+
+func sanitizeUsername(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(raw)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			b.WriteRune(r)
+		case r == '@':
+			return strings.Trim(b.String(), ".-_")
+		}
+	}
+	return strings.Trim(b.String(), ".-_")
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
@@ -18,6 +19,7 @@ import (
 
 // Service provides account (credential) management for users.
 type Service struct {
+	pool    *pgxpool.Pool
 	queries *sqlc.Queries
 	logger  *slog.Logger
 }
@@ -29,11 +31,12 @@ var (
 )
 
 // NewService creates a new accounts service.
-func NewService(log *slog.Logger, queries *sqlc.Queries) *Service {
+func NewService(log *slog.Logger, pool *pgxpool.Pool, queries *sqlc.Queries) *Service {
 	if log == nil {
 		log = slog.Default()
 	}
 	return &Service{
+		pool:    pool,
 		queries: queries,
 		logger:  log.With(slog.String("service", "accounts")),
 	}
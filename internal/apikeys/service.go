@@ -0,0 +1,206 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+)
+
+var (
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrTokenNotFound = errors.New("access token not found")
+	// ErrInvalidRequest marks errors caused by the caller's input.
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+const (
+	// SecretPrefix starts every bot key so it can be told apart from a JWT.
+	SecretPrefix = "mk-"
+	// TokenPrefix starts every personal access token.
+	TokenPrefix = "mp-"
+	// displayPrefixLen is how much of the secret is kept for display.
+	displayPrefixLen = 11
+	// touchInterval throttles last_used_at writes for busy keys.
+	touchInterval = time.Minute
+)
+
+type Service struct {
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+func NewService(log *slog.Logger, queries *sqlc.Queries) *Service {
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "apikeys")),
+	}
+}
+
+// IsKey reports whether token looks like a bot API key rather than a JWT.
+func IsKey(token string) bool {
+	return strings.HasPrefix(strings.TrimSpace(token), SecretPrefix)
+}
+
+// Create issues a new key for the bot. The returned secret cannot be
+// recovered later.
+func (s *Service) Create(ctx context.Context, botID, createdByUserID string, req CreateRequest) (CreatedKey, error) {
+	if s.queries == nil {
+		return CreatedKey{}, fmt.Errorf("api key queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return CreatedKey{}, err
+	}
+	pgCreatedBy := pgtype.UUID{}
+	if strings.TrimSpace(createdByUserID) != "" {
+		pgCreatedBy, err = db.ParseUUID(createdByUserID)
+		if err != nil {
+			return CreatedKey{}, err
+		}
+	}
+	scopes := []string{auth.ScopeChat}
+	if len(req.Scopes) > 0 {
+		scopes, err = auth.NormalizeScopes(req.Scopes)
+		if err != nil {
+			return CreatedKey{}, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+		}
+	}
+	for _, scope := range scopes {
+		if scope == auth.ScopeAdmin {
+			return CreatedKey{}, fmt.Errorf("%w: bot api keys cannot have the admin scope", ErrInvalidRequest)
+		}
+	}
+	secret, err := generateSecret(SecretPrefix)
+	if err != nil {
+		return CreatedKey{}, err
+	}
+	row, err := s.queries.CreateBotApiKey(ctx, sqlc.CreateBotApiKeyParams{
+		BotID:           pgBotID,
+		Name:            strings.TrimSpace(req.Name),
+		KeyPrefix:       secret[:displayPrefixLen],
+		KeyHash:         hashSecret(secret),
+		CreatedByUserID: pgCreatedBy,
+		Scopes:          scopes,
+	})
+	if err != nil {
+		return CreatedKey{}, err
+	}
+	return CreatedKey{Key: toKey(row), Secret: secret}, nil
+}
+
+func (s *Service) List(ctx context.Context, botID string) ([]Key, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("api key queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListBotApiKeysByBot(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Key, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toKey(row))
+	}
+	return items, nil
+}
+
+// Delete revokes a key of the bot.
+func (s *Service) Delete(ctx context.Context, botID, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("api key queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	n, err := s.queries.DeleteBotApiKey(ctx, sqlc.DeleteBotApiKeyParams{ID: pgID, BotID: pgBotID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a secret to its key and records the use.
+func (s *Service) Authenticate(ctx context.Context, secret string) (Key, error) {
+	if s.queries == nil {
+		return Key{}, fmt.Errorf("api key queries not configured")
+	}
+	secret = strings.TrimSpace(secret)
+	if !IsKey(secret) {
+		return Key{}, ErrKeyNotFound
+	}
+	row, err := s.queries.GetBotApiKeyByHash(ctx, hashSecret(secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Key{}, ErrKeyNotFound
+		}
+		return Key{}, err
+	}
+	key := toKey(row)
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > touchInterval {
+		if err := s.queries.TouchBotApiKey(ctx, row.ID); err != nil {
+			s.logger.Warn("failed to record api key use", slog.String("key_id", key.ID), slog.Any("error", err))
+		}
+	}
+	return key, nil
+}
+
+func generateSecret(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashSecret is unsalted on purpose: keys are random 256-bit values, and the
+// hash must be usable as a lookup key.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func toKey(row sqlc.BotApiKey) Key {
+	key := Key{
+		ID:     row.ID.String(),
+		BotID:  row.BotID.String(),
+		Name:   row.Name,
+		Prefix: row.KeyPrefix,
+		Scopes: row.Scopes,
+	}
+	if row.CreatedByUserID.Valid {
+		key.CreatedByUserID = row.CreatedByUserID.String()
+	}
+	if row.LastUsedAt.Valid {
+		lastUsed := row.LastUsedAt.Time
+		key.LastUsedAt = &lastUsed
+	}
+	if row.CreatedAt.Valid {
+		key.CreatedAt = row.CreatedAt.Time
+	}
+	return key
+}
@@ -0,0 +1,53 @@
+package apikeys
+
+import "testing"
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := generateSecret(SecretPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := generateSecret(SecretPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("secrets should be random")
+	}
+	if !IsKey(a) || len(a) != len(SecretPrefix)+43 {
+		t.Errorf("unexpected secret %q", a)
+	}
+	if len(a) < displayPrefixLen {
+		t.Errorf("secret shorter than its display prefix")
+	}
+}
+
+func TestHashSecret(t *testing.T) {
+	if hashSecret("mk-abc") != hashSecret("mk-abc") {
+		t.Error("hash should be deterministic")
+	}
+	if hashSecret("mk-abc") == hashSecret("mk-abd") {
+		t.Error("different secrets should hash differently")
+	}
+}
+
+func TestIsKey(t *testing.T) {
+	if IsKey("eyJhbGciOiJIUzI1NiJ9.e30.x") {
+		t.Error("a JWT is not an API key")
+	}
+	if !IsKey(" mk-abc") {
+		t.Error("prefixed token should be an API key")
+	}
+}
+
+func TestIsAPIToken(t *testing.T) {
+	s := &Service{}
+	for _, token := range []string{"mk-abc", "mp-abc"} {
+		if !s.IsAPIToken(token) {
+			t.Errorf("%q should be an API token", token)
+		}
+	}
+	if s.IsAPIToken("eyJhbGciOiJIUzI1NiJ9.e30.x") {
+		t.Error("a JWT is not an API token")
+	}
+}
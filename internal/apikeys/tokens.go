@@ -0,0 +1,210 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+)
+
+// CreateToken issues a personal access token for the user. The returned
+// secret cannot be recovered later.
+func (s *Service) CreateToken(ctx context.Context, userID string, req CreateTokenRequest) (CreatedToken, error) {
+	if s.queries == nil {
+		return CreatedToken{}, fmt.Errorf("access token queries not configured")
+	}
+	pgUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return CreatedToken{}, err
+	}
+	scopes, err := auth.NormalizeScopes(req.Scopes)
+	if err != nil {
+		return CreatedToken{}, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+	expiresAt := pgtype.Timestamptz{}
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return CreatedToken{}, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidRequest)
+		}
+		expiresAt = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+	secret, err := generateSecret(TokenPrefix)
+	if err != nil {
+		return CreatedToken{}, err
+	}
+	row, err := s.queries.CreatePersonalAccessToken(ctx, sqlc.CreatePersonalAccessTokenParams{
+		UserID:      pgUserID,
+		Name:        strings.TrimSpace(req.Name),
+		TokenPrefix: secret[:displayPrefixLen],
+		TokenHash:   hashSecret(secret),
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		return CreatedToken{}, err
+	}
+	return CreatedToken{Token: toToken(row), Secret: secret}, nil
+}
+
+func (s *Service) ListTokens(ctx context.Context, userID string) ([]Token, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("access token queries not configured")
+	}
+	pgUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListPersonalAccessTokensByUser(ctx, pgUserID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Token, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toToken(row))
+	}
+	return items, nil
+}
+
+// DeleteToken revokes a token of the user.
+func (s *Service) DeleteToken(ctx context.Context, userID, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("access token queries not configured")
+	}
+	pgUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	n, err := s.queries.DeletePersonalAccessToken(ctx, sqlc.DeletePersonalAccessTokenParams{ID: pgID, UserID: pgUserID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// IsAPIToken reports whether a bearer token is a personal access token or a
+// bot key. It implements auth.TokenAuthenticator.
+func (s *Service) IsAPIToken(token string) bool {
+	token = strings.TrimSpace(token)
+	return strings.HasPrefix(token, TokenPrefix) || strings.HasPrefix(token, SecretPrefix)
+}
+
+// AuthenticateToken resolves a personal access token or a bot key for the
+// auth middleware. Bot keys act as the bot owner. Tokens of inactive users
+// are rejected.
+func (s *Service) AuthenticateToken(ctx context.Context, token string) (auth.TokenPrincipal, error) {
+	if s.queries == nil {
+		return auth.TokenPrincipal{}, fmt.Errorf("access token queries not configured")
+	}
+	token = strings.TrimSpace(token)
+	var principal auth.TokenPrincipal
+	switch {
+	case strings.HasPrefix(token, TokenPrefix):
+		t, err := s.authenticatePersonal(ctx, token)
+		if err != nil {
+			return auth.TokenPrincipal{}, err
+		}
+		principal = auth.TokenPrincipal{UserID: t.UserID, Scopes: t.Scopes}
+	case strings.HasPrefix(token, SecretPrefix):
+		key, err := s.Authenticate(ctx, token)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				return auth.TokenPrincipal{}, auth.ErrInvalidAPIToken
+			}
+			return auth.TokenPrincipal{}, err
+		}
+		pgBotID, err := db.ParseUUID(key.BotID)
+		if err != nil {
+			return auth.TokenPrincipal{}, err
+		}
+		bot, err := s.queries.GetBotByID(ctx, pgBotID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return auth.TokenPrincipal{}, auth.ErrInvalidAPIToken
+			}
+			return auth.TokenPrincipal{}, err
+		}
+		principal = auth.TokenPrincipal{UserID: bot.OwnerUserID.String(), BotID: key.BotID, Scopes: key.Scopes}
+	default:
+		return auth.TokenPrincipal{}, auth.ErrInvalidAPIToken
+	}
+	if err := s.requireActiveUser(ctx, principal.UserID); err != nil {
+		return auth.TokenPrincipal{}, err
+	}
+	return principal, nil
+}
+
+func (s *Service) authenticatePersonal(ctx context.Context, secret string) (Token, error) {
+	row, err := s.queries.GetPersonalAccessTokenByHash(ctx, hashSecret(secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Token{}, auth.ErrInvalidAPIToken
+		}
+		return Token{}, err
+	}
+	t := toToken(row)
+	if t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now()) {
+		return Token{}, auth.ErrInvalidAPIToken
+	}
+	if t.LastUsedAt == nil || time.Since(*t.LastUsedAt) > touchInterval {
+		if err := s.queries.TouchPersonalAccessToken(ctx, row.ID); err != nil {
+			s.logger.Warn("failed to record access token use", slog.String("token_id", t.ID), slog.Any("error", err))
+		}
+	}
+	return t, nil
+}
+
+func (s *Service) requireActiveUser(ctx context.Context, userID string) error {
+	pgUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return auth.ErrInvalidAPIToken
+	}
+	user, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.ErrInvalidAPIToken
+		}
+		return err
+	}
+	if !user.IsActive {
+		return auth.ErrInvalidAPIToken
+	}
+	return nil
+}
+
+func toToken(row sqlc.PersonalAccessToken) Token {
+	t := Token{
+		ID:     row.ID.String(),
+		UserID: row.UserID.String(),
+		Name:   row.Name,
+		Prefix: row.TokenPrefix,
+		Scopes: row.Scopes,
+	}
+	if row.ExpiresAt.Valid {
+		expiresAt := row.ExpiresAt.Time
+		t.ExpiresAt = &expiresAt
+	}
+	if row.LastUsedAt.Valid {
+		lastUsed := row.LastUsedAt.Time
+		t.LastUsedAt = &lastUsed
+	}
+	if row.CreatedAt.Valid {
+		t.CreatedAt = row.CreatedAt.Time
+	}
+	return t
+}
@@ -0,0 +1,63 @@
+package apikeys
+
+import "time"
+
+// Key is a bot API key as shown to its owner. The secret itself is never
+// stored and only returned once, on creation.
+type Key struct {
+	ID              string     `json:"id"`
+	BotID           string     `json:"bot_id"`
+	Name            string     `json:"name"`
+	Prefix          string     `json:"prefix"`
+	Scopes          []string   `json:"scopes"`
+	CreatedByUserID string     `json:"created_by_user_id,omitempty"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// CreatedKey is a freshly created key together with its secret.
+type CreatedKey struct {
+	Key
+	Secret string `json:"key"`
+}
+
+// CreateRequest creates a bot key. Scopes default to chat; a bot key cannot
+// carry the admin scope.
+type CreateRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+type ListResponse struct {
+	Items []Key `json:"items"`
+}
+
+// Token is a personal access token as shown to its user.
+type Token struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedToken is a freshly created token together with its secret.
+type CreatedToken struct {
+	Token
+	Secret string `json:"token"`
+}
+
+// CreateTokenRequest creates a personal access token. Without expires_at the
+// token lives until it is revoked.
+type CreateTokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type TokenListResponse struct {
+	Items []Token `json:"items"`
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	apiTokenType        = "api_token"
+	claimScopes         = "scopes"
+	contextKeyPrincipal = "api_token_principal"
+)
+
+// ErrInvalidAPIToken is returned for unknown, revoked or expired API tokens.
+var ErrInvalidAPIToken = errors.New("invalid api token")
+
+// TokenPrincipal is who an API token acts as.
+type TokenPrincipal struct {
+	UserID string
+	// BotID limits the token to one bot. It is empty for personal access
+	// tokens.
+	BotID  string
+	Scopes []string
+}
+
+// TokenAuthenticator resolves API tokens: personal access tokens and bot API
+// keys.
+type TokenAuthenticator interface {
+	// IsAPIToken reports whether a bearer token is an API token rather than
+	// a JWT.
+	IsAPIToken(token string) bool
+	AuthenticateToken(ctx context.Context, token string) (TokenPrincipal, error)
+}
+
+// APITokenMiddleware accepts API tokens next to JWTs. It must run before
+// JWTMiddleware: requests carrying an API token are authenticated and checked
+// against the token's scopes here, and JWTMiddleware skips them. Other
+// requests pass through untouched.
+func APITokenMiddleware(authn TokenAuthenticator, skipper middleware.Skipper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if authn == nil || (skipper != nil && skipper(c)) {
+				return next(c)
+			}
+			token := bearerToken(c)
+			if token == "" || !authn.IsAPIToken(token) {
+				return next(c)
+			}
+			principal, err := authn.AuthenticateToken(c.Request().Context(), token)
+			if err != nil {
+				if errors.Is(err, ErrInvalidAPIToken) {
+					return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify api token")
+			}
+			if principal.BotID != "" && pathBotID(c.Request().URL.Path) != principal.BotID {
+				return echo.NewHTTPError(http.StatusForbidden, "bot api key can only access its own bot")
+			}
+			if required := RequiredScope(c.Request().Method, c.Path()); !HasScope(principal.Scopes, required) {
+				return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("api token lacks the %s scope", required))
+			}
+			c.Set(contextKeyPrincipal, principal)
+			c.Set("user", principalToken(principal))
+			return next(c)
+		}
+	}
+}
+
+// IsAPITokenRequest reports whether the request was authenticated with an API
+// token.
+func IsAPITokenRequest(c echo.Context) bool {
+	_, ok := APITokenFromContext(c)
+	return ok
+}
+
+// APITokenFromContext returns the API token principal of the request, if any.
+func APITokenFromContext(c echo.Context) (TokenPrincipal, bool) {
+	principal, ok := c.Get(contextKeyPrincipal).(TokenPrincipal)
+	return principal, ok
+}
+
+// principalToken stands in for a parsed JWT so that UserIDFromContext and
+// everything built on it work unchanged.
+func principalToken(p TokenPrincipal) *jwt.Token {
+	scopes := make([]any, 0, len(p.Scopes))
+	for _, scope := range p.Scopes {
+		scopes = append(scopes, scope)
+	}
+	claims := jwt.MapClaims{
+		claimType:    apiTokenType,
+		claimSubject: p.UserID,
+		claimUserID:  p.UserID,
+		claimScopes:  scopes,
+	}
+	if p.BotID != "" {
+		claims[claimBotID] = p.BotID
+	}
+	return &jwt.Token{Valid: true, Claims: claims, Method: jwt.SigningMethodNone}
+}
+
+// bearerToken reads the token from the places JWTMiddleware looks at.
+func bearerToken(c echo.Context) string {
+	if header := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	return strings.TrimSpace(c.QueryParam("token"))
+}
+
+// pathBotID returns the bot ID of a /bots/{id}/... request path.
+func pathBotID(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "bots" {
+		return ""
+	}
+	return parts[1]
+}
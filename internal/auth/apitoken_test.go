@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type fakeAuthenticator struct {
+	tokens map[string]TokenPrincipal
+}
+
+func (f fakeAuthenticator) IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, "mk-") || strings.HasPrefix(token, "mp-")
+}
+
+func (f fakeAuthenticator) AuthenticateToken(_ context.Context, token string) (TokenPrincipal, error) {
+	p, ok := f.tokens[token]
+	if !ok {
+		return TokenPrincipal{}, ErrInvalidAPIToken
+	}
+	return p, nil
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	got, err := NormalizeScopes([]string{" Admin", "chat", "chat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "chat,admin" {
+		t.Errorf("scopes = %v", got)
+	}
+	if _, err := NormalizeScopes([]string{"root"}); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+	if _, err := NormalizeScopes(nil); err == nil {
+		t.Error("expected error for no scopes")
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	cases := []struct {
+		method, path, want string
+	}{
+		{http.MethodPost, "/bots/:bot_id/messages", ScopeChat},
+		{http.MethodPost, "/bots/:bot_id/messages/stream", ScopeChat},
+		{http.MethodPost, "/bots/:bot_id/web/messages", ScopeChat},
+		{http.MethodGet, "/bots/:bot_id/messages", ScopeReadMessages},
+		{http.MethodGet, "/bots/:bot_id/messages/events", ScopeReadMessages},
+		{http.MethodGet, "/bots/:bot_id/web/stream", ScopeReadMessages},
+		{http.MethodDelete, "/bots/:bot_id/messages", ScopeManageBot},
+		{http.MethodPut, "/bots/:bot_id/settings", ScopeManageBot},
+		{http.MethodPut, "/bots/:id", ScopeManageBot},
+		{http.MethodGet, "/bots/:id", ""},
+		{http.MethodGet, "/users/me", ""},
+		{http.MethodGet, "/users", ScopeAdmin},
+		{http.MethodPost, "/users/me/tokens", ScopeAdmin},
+		{http.MethodPut, "/providers/:id", ScopeAdmin},
+	}
+	for _, tc := range cases {
+		if got := RequiredScope(tc.method, tc.path); got != tc.want {
+			t.Errorf("RequiredScope(%s %s) = %q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestAPITokenMiddleware(t *testing.T) {
+	authn := fakeAuthenticator{tokens: map[string]TokenPrincipal{
+		"mp-chat":  {UserID: "user-1", Scopes: []string{ScopeChat}},
+		"mp-admin": {UserID: "user-1", Scopes: []string{ScopeAdmin}},
+		"mk-bot":   {UserID: "owner-1", BotID: "bot-1", Scopes: []string{ScopeChat}},
+	}}
+	e := echo.New()
+	e.Use(APITokenMiddleware(authn, nil))
+	e.Use(JWTMiddleware("secret", IsAPITokenRequest))
+	ok := func(c echo.Context) error {
+		userID, err := UserIDFromContext(c)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, userID)
+	}
+	e.POST("/bots/:bot_id/messages", ok)
+	e.PUT("/bots/:bot_id/settings", ok)
+	e.GET("/users", ok)
+
+	cases := []struct {
+		name, method, path, token string
+		want                      int
+	}{
+		{"chat token sends", http.MethodPost, "/bots/bot-1/messages", "mp-chat", http.StatusOK},
+		{"chat token cannot manage", http.MethodPut, "/bots/bot-1/settings", "mp-chat", http.StatusForbidden},
+		{"admin token manages", http.MethodPut, "/bots/bot-1/settings", "mp-admin", http.StatusOK},
+		{"admin token lists users", http.MethodGet, "/users", "mp-admin", http.StatusOK},
+		{"bot key on its bot", http.MethodPost, "/bots/bot-1/messages", "mk-bot", http.StatusOK},
+		{"bot key on another bot", http.MethodPost, "/bots/bot-2/messages", "mk-bot", http.StatusForbidden},
+		{"unknown token", http.MethodPost, "/bots/bot-1/messages", "mp-revoked", http.StatusUnauthorized},
+		{"malformed jwt still rejected", http.MethodPost, "/bots/bot-1/messages", "not-a-jwt", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tc.token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/bots/bot-1/messages", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer mk-bot")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Body.String() != "owner-1" {
+		t.Errorf("bot key should act as the owner, got %q", rec.Body.String())
+	}
+}
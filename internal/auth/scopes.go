@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Scopes limit what an API token may do. JWTs from login are not scoped.
+const (
+	// ScopeChat allows sending messages to bots.
+	ScopeChat = "chat"
+	// ScopeReadMessages allows reading bot conversations.
+	ScopeReadMessages = "read-messages"
+	// ScopeManageBot allows changing bots and their settings.
+	ScopeManageBot = "manage-bot"
+	// ScopeAdmin allows everything the user can do.
+	ScopeAdmin = "admin"
+)
+
+// AllScopes lists every scope.
+var AllScopes = []string{ScopeChat, ScopeReadMessages, ScopeManageBot, ScopeAdmin}
+
+// NormalizeScopes trims, validates and deduplicates scopes, returning them
+// in the order of AllScopes.
+func NormalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	for _, raw := range scopes {
+		scope := strings.ToLower(strings.TrimSpace(raw))
+		if scope == "" {
+			continue
+		}
+		if !validScope(scope) {
+			return nil, fmt.Errorf("invalid scope %q: must be one of %s", raw, strings.Join(AllScopes, ", "))
+		}
+		seen[scope] = true
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	out := make([]string, 0, len(seen))
+	for _, scope := range AllScopes {
+		if seen[scope] {
+			out = append(out, scope)
+		}
+	}
+	return out, nil
+}
+
+// HasScope reports whether the granted scopes cover required. The admin scope
+// covers everything and an empty requirement is met by any token.
+func HasScope(granted []string, required string) bool {
+	if required == "" {
+		return true
+	}
+	for _, scope := range granted {
+		if scope == required || scope == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredScope returns the scope an API token needs for a route, given as
+// the registered path (for example /bots/:bot_id/messages).
+func RequiredScope(method, path string) string {
+	switch {
+	case method == http.MethodGet && (path == "/users/me" || path == "/bots" || path == "/bots/:id"):
+		return ""
+	case strings.HasPrefix(path, "/v1/"):
+		return ScopeChat
+	case strings.HasPrefix(path, "/bots/:bot_id/"):
+		if scope := messageScope(method, strings.TrimPrefix(path, "/bots/:bot_id/")); scope != "" {
+			return scope
+		}
+		return ScopeManageBot
+	case path == "/bots" || strings.HasPrefix(path, "/bots/"):
+		return ScopeManageBot
+	}
+	return ScopeAdmin
+}
+
+// messageScope classifies the message routes of a bot: sending needs chat,
+// reading and subscribing need read-messages. Deleting history is managing
+// the bot.
+func messageScope(method, rest string) string {
+	switch method {
+	case http.MethodPost:
+		if rest == "messages" || rest == "messages/stream" || strings.HasSuffix(rest, "/messages") {
+			return ScopeChat
+		}
+	case http.MethodGet:
+		if rest == "messages" || strings.HasPrefix(rest, "messages/") || strings.HasSuffix(rest, "/stream") {
+			return ScopeReadMessages
+		}
+	}
+	return ""
+}
+
+func validScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
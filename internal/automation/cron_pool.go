@@ -2,11 +2,22 @@ package automation
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+)
+
+var (
+	cronJobRuns = metrics.NewCounter("memoh_cron_job_runs_total",
+		"Cron job triggers by result (ok, error, skipped). Skipped triggers overlapped a running job.", "result")
+	cronJobDuration = metrics.NewHistogram("memoh_cron_job_duration_seconds",
+		"Cron job run time.", nil)
 )
 
 // CronPool wraps robfig/cron as a shared, process-wide scheduler.
@@ -18,11 +29,11 @@ type CronPool struct {
 	logger *slog.Logger
 	loc    *time.Location
 
-	mu       sync.Mutex
-	jobs     map[string]cron.EntryID
-	locks    map[string]*sync.Mutex
-	patterns map[string]string
-	funcs    map[string]func()
+	mu        sync.Mutex
+	jobs      map[string]cron.EntryID
+	locks     map[string]*sync.Mutex
+	schedules map[string]cron.Schedule
+	funcs     map[string]func() error
 }
 
 // NewCronPool creates an idle CronPool. Call Start() to begin scheduling.
@@ -38,14 +49,14 @@ func NewCronPool(log *slog.Logger, loc *time.Location) *CronPool {
 	)
 	c := cron.New(cron.WithParser(parser), cron.WithLocation(loc))
 	return &CronPool{
-		cron:     c,
-		parser:   parser,
-		logger:   log.With(slog.String("component", "cron_pool")),
-		loc:      loc,
-		jobs:     make(map[string]cron.EntryID),
-		locks:    make(map[string]*sync.Mutex),
-		patterns: make(map[string]string),
-		funcs:    make(map[string]func()),
+		cron:      c,
+		parser:    parser,
+		logger:    log.With(slog.String("component", "cron_pool")),
+		loc:       loc,
+		jobs:      make(map[string]cron.EntryID),
+		locks:     make(map[string]*sync.Mutex),
+		schedules: make(map[string]cron.Schedule),
+		funcs:     make(map[string]func() error),
 	}
 }
 
@@ -77,7 +88,7 @@ func (p *CronPool) SetLocation(loc *time.Location) {
 
 	var failed int
 	for id := range oldJobs {
-		pattern, ok1 := p.patterns[id]
+		schedule, ok1 := p.schedules[id]
 		fn, ok2 := p.funcs[id]
 		if !ok1 || !ok2 {
 			failed++
@@ -88,21 +99,9 @@ func (p *CronPool) SetLocation(loc *time.Location) {
 			jobMu = &sync.Mutex{}
 			p.locks[id] = jobMu
 		}
-		wrapped := func() {
-			if !jobMu.TryLock() {
-				return
-			}
-			defer jobMu.Unlock()
-			fn()
-		}
-		entryID, err := newCron.AddFunc(pattern, wrapped)
-		if err != nil {
-			p.logger.Warn("failed to re-register job after timezone change",
-				slog.String("job_id", id), slog.Any("error", err))
-			failed++
-			continue
-		}
-		p.jobs[id] = entryID
+		// Patterns without CRON_TZ follow the scheduler's location, so the
+		// parsed schedules can be reused as they are.
+		p.jobs[id] = newCron.Schedule(schedule, cron.FuncJob(p.wrapJob(id, jobMu, fn)))
 	}
 
 	p.cron = newCron
@@ -121,11 +120,98 @@ func (p *CronPool) ValidatePattern(pattern string) error {
 	return err
 }
 
+// Location returns the timezone cron patterns are interpreted in unless
+// they carry their own.
+func (p *CronPool) Location() *time.Location {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.loc
+}
+
+// Parse parses a cron pattern. A non-empty timezone (IANA name) overrides
+// the pool's location for this pattern.
+func (p *CronPool) Parse(pattern, timezone string) (cron.Schedule, error) {
+	pattern = strings.TrimSpace(pattern)
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		pattern = "CRON_TZ=" + tz + " " + pattern
+	}
+	return p.parser.Parse(pattern)
+}
+
+// OnceSchedule fires a single time at the given instant.
+type OnceSchedule struct {
+	At time.Time
+}
+
+// Next implements cron.Schedule. It returns the zero time once At has
+// passed, which the scheduler treats as "never again".
+func (s OnceSchedule) Next(t time.Time) time.Time {
+	if s.At.After(t) {
+		return s.At
+	}
+	return time.Time{}
+}
+
+// IntervalSchedule fires at Start and then every Every after it. Unlike
+// "@every", fire times are anchored to Start rather than to when the job
+// was registered, so they survive restarts.
+type IntervalSchedule struct {
+	Start time.Time
+	Every time.Duration
+}
+
+// Next implements cron.Schedule.
+func (s IntervalSchedule) Next(t time.Time) time.Time {
+	if s.Every <= 0 {
+		return time.Time{}
+	}
+	if t.Before(s.Start) {
+		return s.Start
+	}
+	n := t.Sub(s.Start)/s.Every + 1
+	return s.Start.Add(n * s.Every)
+}
+
+// FireTimesBetween returns the times schedule fires after since and up to and
+// including until, in the pool's timezone, oldest first. When there are more
+// than limit, only the most recent limit times are returned and truncated
+// reports how many were dropped.
+func (p *CronPool) FireTimesBetween(schedule cron.Schedule, since, until time.Time, limit int) (times []time.Time, truncated int) {
+	if limit <= 0 {
+		return nil, 0
+	}
+	loc := p.Location()
+	for next := schedule.Next(since.In(loc)); !next.IsZero() && !next.After(until); next = schedule.Next(next) {
+		if len(times) == limit {
+			times = append(times[1:], next)
+			truncated++
+			continue
+		}
+		times = append(times, next)
+	}
+	return times, truncated
+}
+
 // Add registers a job under the given id with the given cron pattern.
 // The callback fn is wrapped with a per-job mutex so that overlapping
 // triggers are skipped (not queued), preventing concurrent execution
-// of the same job when a previous run hasn't finished.
-func (p *CronPool) Add(id, pattern string, fn func()) error {
+// of the same job when a previous run hasn't finished. Errors returned by
+// fn are counted as failed runs.
+func (p *CronPool) Add(id, pattern string, fn func() error) error {
+	schedule, err := p.parser.Parse(pattern)
+	if err != nil {
+		return err
+	}
+	p.AddSchedule(id, schedule, fn)
+	return nil
+}
+
+// AddSchedule is like Add for an already parsed or custom schedule, such as
+// OnceSchedule or IntervalSchedule.
+func (p *CronPool) AddSchedule(id string, schedule cron.Schedule, fn func() error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -138,26 +224,31 @@ func (p *CronPool) Add(id, pattern string, fn func()) error {
 	jobMu := &sync.Mutex{}
 	p.locks[id] = jobMu
 
-	wrapped := func() {
+	p.jobs[id] = p.cron.Schedule(schedule, cron.FuncJob(p.wrapJob(id, jobMu, fn)))
+	p.schedules[id] = schedule
+	p.funcs[id] = fn
+}
+
+// wrapJob guards fn with the job's mutex and records run metrics.
+func (p *CronPool) wrapJob(id string, jobMu *sync.Mutex, fn func() error) func() {
+	return func() {
 		if !jobMu.TryLock() {
 			p.logger.Debug("skipping overlapping trigger",
 				slog.String("job_id", id),
 			)
+			cronJobRuns.Inc("skipped")
 			return
 		}
 		defer jobMu.Unlock()
-		fn()
-	}
-
-	entryID, err := p.cron.AddFunc(pattern, wrapped)
-	if err != nil {
-		delete(p.locks, id)
-		return err
+		start := time.Now()
+		err := fn()
+		cronJobDuration.ObserveSince(start)
+		if err != nil {
+			cronJobRuns.Inc("error")
+			return
+		}
+		cronJobRuns.Inc("ok")
 	}
-	p.jobs[id] = entryID
-	p.patterns[id] = pattern
-	p.funcs[id] = fn
-	return nil
 }
 
 // Remove unregisters a job by id. Safe to call with unknown ids.
@@ -168,14 +259,14 @@ func (p *CronPool) Remove(id string) {
 		p.cron.Remove(entryID)
 		delete(p.jobs, id)
 		delete(p.locks, id)
-		delete(p.patterns, id)
+		delete(p.schedules, id)
 		delete(p.funcs, id)
 	}
 }
 
 // Replace is a convenience method: Remove + Add. If the new pattern is
 // invalid, the old job is already removed (caller should handle the error).
-func (p *CronPool) Replace(id, pattern string, fn func()) error {
+func (p *CronPool) Replace(id, pattern string, fn func() error) error {
 	p.Remove(id)
 	return p.Add(id, pattern, fn)
 }
@@ -0,0 +1,94 @@
+package automation
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestFireTimesBetween(t *testing.T) {
+	pool := NewCronPool(slog.Default(), time.UTC)
+	since := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	daily, err := pool.Parse("0 9 * * *", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	times, truncated := pool.FireTimesBetween(daily, since, until, 10)
+	if truncated != 0 || len(times) != 4 {
+		t.Fatalf("expected 4 fire times, got %d (truncated %d)", len(times), truncated)
+	}
+	if !times[0].Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) || !times[3].Equal(until) {
+		t.Errorf("unexpected range %v .. %v", times[0], times[3])
+	}
+
+	times, truncated = pool.FireTimesBetween(daily, since, until, 2)
+	if truncated != 2 || len(times) != 2 || !times[1].Equal(until) {
+		t.Errorf("expected the 2 most recent times with 2 dropped, got %v (truncated %d)", times, truncated)
+	}
+}
+
+func TestFireTimesBetween_Location(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	pool := NewCronPool(slog.Default(), loc)
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	daily, err := pool.Parse("0 9 * * *", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	times, _ := pool.FireTimesBetween(daily, since, until, 10)
+	if len(times) != 1 || !times[0].Equal(time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 09:00 UTC+8 on March 1, got %v", times)
+	}
+}
+
+func TestParse_Timezone(t *testing.T) {
+	pool := NewCronPool(slog.Default(), time.UTC)
+	tokyo, err := pool.Parse("0 9 * * *", "Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := tokyo.Next(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if !next.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 09:00 Tokyo (00:00 UTC) on March 2, got %v", next.UTC())
+	}
+	if _, err := pool.Parse("0 9 * * *", "Mars/Olympus"); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+	if _, err := pool.Parse("not a pattern", ""); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestOnceSchedule(t *testing.T) {
+	at := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	s := OnceSchedule{At: at}
+	if next := s.Next(at.Add(-time.Minute)); !next.Equal(at) {
+		t.Errorf("expected %v, got %v", at, next)
+	}
+	if next := s.Next(at); !next.IsZero() {
+		t.Errorf("expected no further fire time, got %v", next)
+	}
+}
+
+func TestIntervalSchedule(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	s := IntervalSchedule{Start: start, Every: 90 * time.Minute}
+	cases := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{start.Add(-time.Hour), start},
+		{start, start.Add(90 * time.Minute)},
+		{start.Add(100 * time.Minute), start.Add(180 * time.Minute)},
+		{start.Add(180 * time.Minute), start.Add(270 * time.Minute)},
+	}
+	for _, tc := range cases {
+		if got := s.Next(tc.now); !got.Equal(tc.want) {
+			t.Errorf("Next(%v) = %v, want %v", tc.now, got, tc.want)
+		}
+	}
+}
@@ -161,6 +161,10 @@ const (
 	BotCheckKeyContainerTask   = "container.task"
 	BotCheckKeyContainerData   = "container.data_path"
 	BotCheckKeyDelete          = "bot.delete"
+
+	BotCheckKeyContainerMemory = "container.memory"
+	BotCheckKeyContainerCPU    = "container.cpu"
+	BotCheckKeyContainerPids   = "container.pids"
 )
 
 const (
@@ -0,0 +1,220 @@
+// Package budget enforces daily and monthly token budgets per bot, per bot
+// owner and per channel identity, based on the token_usage table.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope is what a budget applies to.
+type Scope string
+
+const (
+	// ScopeBot limits the usage of a single bot.
+	ScopeBot Scope = "bot"
+	// ScopeUser limits the combined usage of all bots owned by a user.
+	ScopeUser Scope = "user"
+	// ScopeChannelIdentity limits what a single sender may spend on any bot.
+	ScopeChannelIdentity Scope = "channel_identity"
+)
+
+// Class separates interactive chat from background runs so that a runaway
+// heartbeat cannot eat the budget meant for users, and vice versa.
+type Class string
+
+const (
+	ClassInteractive Class = "interactive"
+	ClassBackground  Class = "background"
+)
+
+// Period is the window a limit is counted over. Windows start at midnight
+// UTC and on the first of the month UTC.
+type Period string
+
+const (
+	PeriodDaily   Period = "daily"
+	PeriodMonthly Period = "monthly"
+)
+
+var (
+	// ErrExceeded is returned for background runs refused by a token budget.
+	ErrExceeded = errors.New("token budget exceeded")
+	// ErrCheckFailed is returned for background runs refused because their
+	// budgets could not be checked.
+	ErrCheckFailed = errors.New("token budget check failed")
+)
+
+// DefaultSoftLimitPercent is the share of a limit at which the owner is warned.
+const DefaultSoftLimitPercent = 80
+
+// backgroundTaskTypes are the task types (and token_usage sources) counted
+// against background budgets.
+var backgroundTaskTypes = []string{"heartbeat", "schedule", "subagent"}
+
+// ClassOf returns the budget class of a task type or token usage source.
+func ClassOf(taskType string) Class {
+	taskType = strings.ToLower(strings.TrimSpace(taskType))
+	for _, t := range backgroundTaskTypes {
+		if taskType == t {
+			return ClassBackground
+		}
+	}
+	return ClassInteractive
+}
+
+// ParseScope validates a scope name.
+func ParseScope(raw string) (Scope, error) {
+	switch s := Scope(strings.ToLower(strings.TrimSpace(raw))); s {
+	case ScopeBot, ScopeUser, ScopeChannelIdentity:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid budget scope %q", raw)
+	}
+}
+
+// ParseClass validates a class name. Empty means interactive.
+func ParseClass(raw string) (Class, error) {
+	switch c := Class(strings.ToLower(strings.TrimSpace(raw))); c {
+	case "":
+		return ClassInteractive, nil
+	case ClassInteractive, ClassBackground:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid task class %q", raw)
+	}
+}
+
+// Budget is a stored token limit. A zero limit leaves that period unlimited.
+type Budget struct {
+	Scope            Scope      `json:"scope"`
+	ScopeID          string     `json:"scope_id"`
+	Class            Class      `json:"task_class"`
+	DailyLimit       int64      `json:"daily_limit"`
+	MonthlyLimit     int64      `json:"monthly_limit"`
+	SoftLimitPercent int        `json:"soft_limit_percent"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks the limits.
+func (b Budget) Validate() error {
+	switch {
+	case b.DailyLimit < 0 || b.MonthlyLimit < 0:
+		return errors.New("token limits must not be negative")
+	case b.SoftLimitPercent < 0 || b.SoftLimitPercent > 100:
+		return errors.New("soft_limit_percent must be between 0 and 100")
+	case b.DailyLimit > 0 && b.MonthlyLimit > 0 && b.DailyLimit > b.MonthlyLimit:
+		return errors.New("daily_limit must not exceed monthly_limit")
+	}
+	return nil
+}
+
+// Usage is the token usage counted against a budget in the current windows.
+type Usage struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// Breach describes a limit that has been reached (hard) or approached (soft).
+type Breach struct {
+	Scope   Scope
+	ScopeID string
+	Class   Class
+	Period  Period
+	Used    int64
+	Limit   int64
+	// Hard is set when the limit is reached and requests are refused.
+	Hard bool
+}
+
+// Decision is the outcome of a budget check.
+type Decision struct {
+	// Blocked is the first hard limit reached, if any.
+	Blocked *Breach
+	// Warnings are soft limits crossed by budgets that are not blocked.
+	Warnings []Breach
+}
+
+// Allowed reports whether the request may proceed.
+func (d Decision) Allowed() bool {
+	return d.Blocked == nil
+}
+
+// Evaluate compares usage with a budget. It returns hard breaches before
+// soft ones and the daily window before the monthly one.
+func Evaluate(b Budget, usage Usage) []Breach {
+	soft := b.SoftLimitPercent
+	if soft <= 0 {
+		soft = DefaultSoftLimitPercent
+	}
+	var hard, warn []Breach
+	check := func(period Period, used, limit int64) {
+		if limit <= 0 {
+			return
+		}
+		breach := Breach{Scope: b.Scope, ScopeID: b.ScopeID, Class: b.Class, Period: period, Used: used, Limit: limit}
+		switch {
+		case used >= limit:
+			breach.Hard = true
+			hard = append(hard, breach)
+		case soft < 100 && used*100 >= limit*int64(soft):
+			warn = append(warn, breach)
+		}
+	}
+	check(PeriodDaily, usage.Daily, b.DailyLimit)
+	check(PeriodMonthly, usage.Monthly, b.MonthlyLimit)
+	return append(hard, warn...)
+}
+
+// PeriodStart returns the start of the window containing now.
+func PeriodStart(period Period, now time.Time) time.Time {
+	now = now.UTC()
+	if period == PeriodMonthly {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RefusalMessage is the reply sent instead of an answer when a hard limit
+// is reached.
+func RefusalMessage(b Breach) string {
+	var who string
+	switch b.Scope {
+	case ScopeChannelIdentity:
+		who = "You have used up your"
+	case ScopeUser:
+		who = "The owner of this bot has used up their"
+	default:
+		who = "This bot has used up its"
+	}
+	when := "tomorrow"
+	if b.Period == PeriodMonthly {
+		when = "next month"
+	}
+	return fmt.Sprintf("%s %s token budget, so I can't answer right now. Please try again %s.", who, b.Period, when)
+}
+
+// WarningMessage is the notice sent to the bot owner when a budget crosses
+// its soft limit or is exhausted.
+func WarningMessage(botName string, b Breach) string {
+	target := string(b.Scope)
+	switch b.Scope {
+	case ScopeBot:
+		target = "bot"
+	case ScopeUser:
+		target = "account-wide"
+	case ScopeChannelIdentity:
+		target = "per-sender"
+	}
+	if strings.TrimSpace(botName) == "" {
+		botName = "your bot"
+	}
+	if b.Hard {
+		return fmt.Sprintf("Token budget reached for %s: the %s %s %s limit of %d tokens is used up (%d used). Further %s requests are refused until the period resets.",
+			botName, target, b.Period, b.Class, b.Limit, b.Used, b.Class)
+	}
+	return fmt.Sprintf("Token budget warning for %s: %d of %d tokens (%d%%) of the %s %s %s limit are used.",
+		botName, b.Used, b.Limit, b.Used*100/b.Limit, target, b.Period, b.Class)
+}
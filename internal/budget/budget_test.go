@@ -0,0 +1,120 @@
+package budget
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassOf(t *testing.T) {
+	cases := map[string]Class{
+		"":           ClassInteractive,
+		"chat":       ClassInteractive,
+		"summarize":  ClassInteractive,
+		"agent_call": ClassInteractive,
+		"heartbeat":  ClassBackground,
+		"Schedule":   ClassBackground,
+		"subagent":   ClassBackground,
+	}
+	for taskType, want := range cases {
+		if got := ClassOf(taskType); got != want {
+			t.Errorf("ClassOf(%q) = %q, want %q", taskType, got, want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	b := Budget{Scope: ScopeBot, ScopeID: "bot-1", Class: ClassBackground, DailyLimit: 1000, MonthlyLimit: 10000, SoftLimitPercent: 80}
+
+	if got := Evaluate(b, Usage{Daily: 500, Monthly: 500}); len(got) != 0 {
+		t.Fatalf("expected no breach under the soft limit, got %+v", got)
+	}
+
+	got := Evaluate(b, Usage{Daily: 800, Monthly: 800})
+	if len(got) != 1 || got[0].Hard || got[0].Period != PeriodDaily {
+		t.Fatalf("expected a daily soft breach, got %+v", got)
+	}
+
+	got = Evaluate(b, Usage{Daily: 900, Monthly: 10000})
+	if len(got) != 2 {
+		t.Fatalf("expected two breaches, got %+v", got)
+	}
+	if !got[0].Hard || got[0].Period != PeriodMonthly {
+		t.Fatalf("expected the hard monthly breach first, got %+v", got[0])
+	}
+	if got[1].Hard || got[1].Period != PeriodDaily {
+		t.Fatalf("expected the soft daily breach second, got %+v", got[1])
+	}
+}
+
+func TestEvaluateUnlimitedAndDefaultSoftLimit(t *testing.T) {
+	b := Budget{Scope: ScopeUser, ScopeID: "user-1", Class: ClassInteractive, MonthlyLimit: 100}
+	got := Evaluate(b, Usage{Daily: 1 << 40, Monthly: 80})
+	if len(got) != 1 || got[0].Period != PeriodMonthly || got[0].Hard {
+		t.Fatalf("expected only a monthly soft breach at the default 80%%, got %+v", got)
+	}
+
+	b.SoftLimitPercent = 100
+	if got := Evaluate(b, Usage{Monthly: 99}); len(got) != 0 {
+		t.Fatalf("expected no warning when the soft limit is 100%%, got %+v", got)
+	}
+}
+
+func TestBudgetValidate(t *testing.T) {
+	valid := Budget{DailyLimit: 10, MonthlyLimit: 100, SoftLimitPercent: 80}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	invalid := []Budget{
+		{DailyLimit: -1},
+		{SoftLimitPercent: 101},
+		{DailyLimit: 200, MonthlyLimit: 100},
+	}
+	for _, b := range invalid {
+		if err := b.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", b)
+		}
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2026, 3, 15, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))
+	if got, want := PeriodStart(PeriodDaily, now), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("daily start = %v, want %v", got, want)
+	}
+	if got, want := PeriodStart(PeriodMonthly, now), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("monthly start = %v, want %v", got, want)
+	}
+}
+
+func TestRefusalMessage(t *testing.T) {
+	msg := RefusalMessage(Breach{Scope: ScopeChannelIdentity, Period: PeriodMonthly})
+	if !strings.Contains(msg, "You have used up your monthly token budget") || !strings.Contains(msg, "next month") {
+		t.Fatalf("unexpected refusal: %q", msg)
+	}
+	msg = RefusalMessage(Breach{Scope: ScopeBot, Period: PeriodDaily})
+	if !strings.Contains(msg, "This bot") || !strings.Contains(msg, "tomorrow") {
+		t.Fatalf("unexpected refusal: %q", msg)
+	}
+}
+
+func TestMarkNotifiedOncePerWindow(t *testing.T) {
+	s := NewService(nil, nil)
+	b := Breach{Scope: ScopeBot, ScopeID: "bot-1", Class: ClassBackground, Period: PeriodDaily}
+	day := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	if !s.markNotified(b, day) {
+		t.Fatal("first breach should notify")
+	}
+	if s.markNotified(b, day.Add(time.Hour)) {
+		t.Fatal("same breach in the same day should not notify again")
+	}
+	hard := b
+	hard.Hard = true
+	if !s.markNotified(hard, day.Add(time.Hour)) {
+		t.Fatal("reaching the hard limit should notify even after a soft warning")
+	}
+	if !s.markNotified(b, day.Add(24*time.Hour)) {
+		t.Fatal("a new day should notify again")
+	}
+}
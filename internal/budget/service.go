@@ -0,0 +1,174 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+)
+
+var (
+	budgetRefusals = metrics.NewCounter("memoh_token_budget_refusals_total",
+		"Requests refused because a token budget was exhausted.", "scope", "class")
+	budgetWarnings = metrics.NewCounter("memoh_token_budget_warnings_total",
+		"Owner notifications sent for token budgets crossing their soft limit.", "scope", "class")
+)
+
+// OwnerNotifier delivers budget notices to a bot's owner.
+type OwnerNotifier interface {
+	NotifyOwner(ctx context.Context, botID, ownerUserID, text string) error
+}
+
+// Subject identifies who a request is billed to.
+type Subject struct {
+	BotID string
+	// ChannelIdentityID is the sender; empty for background runs.
+	ChannelIdentityID string
+	Class             Class
+}
+
+// Service checks requests against the budgets of their bot, the bot's owner
+// and the sender.
+type Service struct {
+	store    *Store
+	notifier OwnerNotifier
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu sync.Mutex
+	// notified maps a breach key to the window it was last reported in, so
+	// the owner hears about each limit once per day or month.
+	notified map[string]time.Time
+}
+
+// NewService creates a budget service.
+func NewService(log *slog.Logger, store *Store) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		store:    store,
+		logger:   log.With(slog.String("service", "token_budget")),
+		now:      time.Now,
+		notified: map[string]time.Time{},
+	}
+}
+
+// SetOwnerNotifier sets how soft-limit warnings reach the bot owner.
+func (s *Service) SetOwnerNotifier(n OwnerNotifier) {
+	s.notifier = n
+}
+
+// Check evaluates every budget that applies to the subject. Budgets without
+// a stored row are unlimited. When an error is returned, callers should let
+// interactive requests through, so a database hiccup does not take chat
+// down, but refuse background runs, which nobody watches while they spend.
+func (s *Service) Check(ctx context.Context, subject Subject) (Decision, error) {
+	if s == nil || s.store == nil {
+		return Decision{}, nil
+	}
+	botID := strings.TrimSpace(subject.BotID)
+	if botID == "" {
+		return Decision{}, fmt.Errorf("bot id is required")
+	}
+	class := subject.Class
+	if class == "" {
+		class = ClassInteractive
+	}
+	ownerUserID, botName, err := s.store.BotInfo(ctx, botID)
+	if err != nil {
+		return Decision{}, err
+	}
+	scopes := []struct {
+		scope Scope
+		id    string
+	}{
+		{ScopeBot, botID},
+		{ScopeUser, ownerUserID},
+		{ScopeChannelIdentity, strings.TrimSpace(subject.ChannelIdentityID)},
+	}
+	now := s.now()
+	var decision Decision
+	for _, sc := range scopes {
+		if sc.id == "" {
+			continue
+		}
+		b, ok, err := s.store.Get(ctx, sc.scope, sc.id, class)
+		if err != nil {
+			return Decision{}, err
+		}
+		if !ok || (b.DailyLimit == 0 && b.MonthlyLimit == 0) {
+			continue
+		}
+		usage, err := s.store.Usage(ctx, sc.scope, sc.id, class, now)
+		if err != nil {
+			return Decision{}, err
+		}
+		for _, breach := range Evaluate(b, usage) {
+			if breach.Hard {
+				if decision.Blocked == nil {
+					blocked := breach
+					decision.Blocked = &blocked
+				}
+				continue
+			}
+			decision.Warnings = append(decision.Warnings, breach)
+		}
+	}
+	if decision.Blocked != nil {
+		budgetRefusals.Inc(string(decision.Blocked.Scope), string(class))
+		s.logger.Warn("token budget exhausted",
+			slog.String("bot_id", botID),
+			slog.String("scope", string(decision.Blocked.Scope)),
+			slog.String("class", string(class)),
+			slog.String("period", string(decision.Blocked.Period)),
+			slog.Int64("used", decision.Blocked.Used),
+			slog.Int64("limit", decision.Blocked.Limit),
+		)
+		s.notify(ctx, botID, ownerUserID, botName, *decision.Blocked, now)
+	}
+	for _, warning := range decision.Warnings {
+		s.notify(ctx, botID, ownerUserID, botName, warning, now)
+	}
+	return decision, nil
+}
+
+// notify sends the owner a notice unless the same breach was already
+// reported in the current window. Delivery runs in the background.
+func (s *Service) notify(ctx context.Context, botID, ownerUserID, botName string, b Breach, now time.Time) {
+	if s.notifier == nil || ownerUserID == "" || !s.markNotified(b, now) {
+		return
+	}
+	if !b.Hard {
+		budgetWarnings.Inc(string(b.Scope), string(b.Class))
+	}
+	text := WarningMessage(botName, b)
+	go func() {
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+		defer cancel()
+		if err := s.notifier.NotifyOwner(notifyCtx, botID, ownerUserID, text); err != nil {
+			s.logger.Warn("token budget owner notification failed",
+				slog.String("bot_id", botID),
+				slog.String("owner_user_id", ownerUserID),
+				slog.Any("error", err),
+			)
+		}
+	}()
+}
+
+// markNotified records a breach and reports whether it is new in its window.
+func (s *Service) markNotified(b Breach, now time.Time) bool {
+	key := strings.Join([]string{string(b.Scope), b.ScopeID, string(b.Class), string(b.Period), fmt.Sprint(b.Hard)}, "|")
+	start := PeriodStart(b.Period, now)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.notified[key]; ok && !last.Before(start) {
+		return false
+	}
+	s.notified[key] = start
+	return true
+}
@@ -0,0 +1,176 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store persists budgets and sums token usage against them.
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewStore creates a store backed by the token_budgets and token_usage tables.
+func NewStore(log *slog.Logger, pool *pgxpool.Pool) *Store {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Store{
+		pool:   pool,
+		logger: log.With(slog.String("service", "token_budget_store")),
+	}
+}
+
+// Get returns the budget for a scope and class. The bool is false when none
+// is stored.
+func (s *Store) Get(ctx context.Context, scope Scope, scopeID string, class Class) (Budget, bool, error) {
+	scopeID = strings.TrimSpace(scopeID)
+	if scopeID == "" {
+		return Budget{}, false, errors.New("scope_id is required")
+	}
+	b := Budget{Scope: scope, ScopeID: scopeID, Class: class}
+	var updatedAt time.Time
+	err := s.pool.QueryRow(ctx, `
+		SELECT daily_limit, monthly_limit, soft_limit_percent, updated_at
+		FROM token_budgets WHERE scope = $1 AND scope_id = $2 AND task_class = $3`,
+		string(scope), scopeID, string(class),
+	).Scan(&b.DailyLimit, &b.MonthlyLimit, &b.SoftLimitPercent, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Budget{}, false, nil
+		}
+		return Budget{}, false, fmt.Errorf("query token budget: %w", err)
+	}
+	b.UpdatedAt = &updatedAt
+	return b, true, nil
+}
+
+// List returns all budgets of a scope.
+func (s *Store) List(ctx context.Context, scope Scope, scopeID string) ([]Budget, error) {
+	scopeID = strings.TrimSpace(scopeID)
+	if scopeID == "" {
+		return nil, errors.New("scope_id is required")
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT task_class, daily_limit, monthly_limit, soft_limit_percent, updated_at
+		FROM token_budgets WHERE scope = $1 AND scope_id = $2
+		ORDER BY task_class`, string(scope), scopeID)
+	if err != nil {
+		return nil, fmt.Errorf("list token budgets: %w", err)
+	}
+	defer rows.Close()
+	var out []Budget
+	for rows.Next() {
+		b := Budget{Scope: scope, ScopeID: scopeID}
+		var class string
+		var updatedAt time.Time
+		if err := rows.Scan(&class, &b.DailyLimit, &b.MonthlyLimit, &b.SoftLimitPercent, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan token budget: %w", err)
+		}
+		b.Class = Class(class)
+		b.UpdatedAt = &updatedAt
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list token budgets: %w", err)
+	}
+	return out, nil
+}
+
+// Upsert validates and stores a budget.
+func (s *Store) Upsert(ctx context.Context, b Budget) (Budget, error) {
+	b.ScopeID = strings.TrimSpace(b.ScopeID)
+	if b.ScopeID == "" {
+		return Budget{}, errors.New("scope_id is required")
+	}
+	if b.SoftLimitPercent == 0 {
+		b.SoftLimitPercent = DefaultSoftLimitPercent
+	}
+	if err := b.Validate(); err != nil {
+		return Budget{}, err
+	}
+	var updatedAt time.Time
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO token_budgets (scope, scope_id, task_class, daily_limit, monthly_limit, soft_limit_percent, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, now())
+		ON CONFLICT (scope, scope_id, task_class) DO UPDATE SET
+			daily_limit = EXCLUDED.daily_limit,
+			monthly_limit = EXCLUDED.monthly_limit,
+			soft_limit_percent = EXCLUDED.soft_limit_percent,
+			updated_at = now()
+		RETURNING updated_at`,
+		string(b.Scope), b.ScopeID, string(b.Class), b.DailyLimit, b.MonthlyLimit, b.SoftLimitPercent,
+	).Scan(&updatedAt)
+	if err != nil {
+		return Budget{}, fmt.Errorf("upsert token budget: %w", err)
+	}
+	b.UpdatedAt = &updatedAt
+	return b, nil
+}
+
+// Delete removes a budget. Deleting a missing budget is not an error.
+func (s *Store) Delete(ctx context.Context, scope Scope, scopeID string, class Class) error {
+	if _, err := s.pool.Exec(ctx, `
+		DELETE FROM token_budgets WHERE scope = $1 AND scope_id = $2 AND task_class = $3`,
+		string(scope), strings.TrimSpace(scopeID), string(class),
+	); err != nil {
+		return fmt.Errorf("delete token budget: %w", err)
+	}
+	return nil
+}
+
+// Usage sums the tokens counted against a scope and class in the current
+// day and month.
+func (s *Store) Usage(ctx context.Context, scope Scope, scopeID string, class Class, now time.Time) (Usage, error) {
+	var from, filter string
+	switch scope {
+	case ScopeBot:
+		from, filter = "token_usage u", "u.bot_id = $1"
+	case ScopeUser:
+		from, filter = "token_usage u JOIN bots b ON b.id = u.bot_id", "b.owner_user_id = $1"
+	case ScopeChannelIdentity:
+		from, filter = "token_usage u", "u.channel_identity_id = $1"
+	default:
+		return Usage{}, fmt.Errorf("invalid budget scope %q", scope)
+	}
+	query := `
+		SELECT
+			COALESCE(SUM(u.total_tokens) FILTER (WHERE u.created_at >= $2), 0)::bigint,
+			COALESCE(SUM(u.total_tokens), 0)::bigint
+		FROM ` + from + `
+		WHERE ` + filter + `
+			AND u.created_at >= $3
+			AND (u.source = ANY($4::text[])) = $5`
+	var usage Usage
+	err := s.pool.QueryRow(ctx, query,
+		strings.TrimSpace(scopeID),
+		PeriodStart(PeriodDaily, now),
+		PeriodStart(PeriodMonthly, now),
+		backgroundTaskTypes,
+		class == ClassBackground,
+	).Scan(&usage.Daily, &usage.Monthly)
+	if err != nil {
+		return Usage{}, fmt.Errorf("sum token usage: %w", err)
+	}
+	return usage, nil
+}
+
+// BotInfo returns the owner user ID and display name of a bot.
+func (s *Store) BotInfo(ctx context.Context, botID string) (ownerUserID, name string, err error) {
+	err = s.pool.QueryRow(ctx, `
+		SELECT owner_user_id::text, COALESCE(display_name, '')
+		FROM bots WHERE id = $1`, strings.TrimSpace(botID),
+	).Scan(&ownerUserID, &name)
+	if err != nil {
+		return "", "", fmt.Errorf("query bot: %w", err)
+	}
+	return ownerUserID, name, nil
+}
@@ -129,6 +129,12 @@ type Connection interface {
 	Running() bool
 }
 
+// LossReporter is implemented by connections that can tell when their link to
+// the platform dropped. LostErr returns nil while the connection is healthy.
+type LossReporter interface {
+	LostErr() error
+}
+
 // BaseConnection is a default Connection implementation backed by a stop function.
 type BaseConnection struct {
 	configID    string
@@ -136,6 +142,7 @@ type BaseConnection struct {
 	channelType ChannelType
 	stop        func(ctx context.Context) error
 	running     atomic.Bool
+	lost        atomic.Pointer[error]
 }
 
 // NewConnection creates a BaseConnection for the given config and stop function.
@@ -178,3 +185,25 @@ func (c *BaseConnection) Stop(ctx context.Context) error {
 func (c *BaseConnection) Running() bool {
 	return c.running.Load()
 }
+
+// MarkLost records that the link to the platform dropped. Adapters that
+// reconnect on their own call MarkRecovered once the link is back.
+func (c *BaseConnection) MarkLost(err error) {
+	if err == nil {
+		err = errors.New("connection lost")
+	}
+	c.lost.Store(&err)
+}
+
+// MarkRecovered clears a loss recorded by MarkLost.
+func (c *BaseConnection) MarkRecovered() {
+	c.lost.Store(nil)
+}
+
+// LostErr returns the error passed to MarkLost, or nil while connected.
+func (c *BaseConnection) LostErr() error {
+	if p := c.lost.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+const (
+	// Discord allows 5 buttons per action row and 5 rows per message, and
+	// custom IDs of up to 100 characters.
+	discordButtonsPerRow     = 5
+	discordMaxButtonRows     = 5
+	discordCustomIDMaxLength = 100
+)
+
+// buildDiscordComponents renders actions as rows of message buttons.
+func buildDiscordComponents(actions []channel.Action) ([]discordgo.MessageComponent, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	if len(actions) > discordButtonsPerRow*discordMaxButtonRows {
+		return nil, fmt.Errorf("discord messages support at most %d buttons", discordButtonsPerRow*discordMaxButtonRows)
+	}
+	var rows []discordgo.MessageComponent
+	var row []discordgo.MessageComponent
+	for _, action := range actions {
+		if err := action.Validate(); err != nil {
+			return nil, err
+		}
+		button := discordgo.Button{Label: strings.TrimSpace(action.Label)}
+		if action.IsLink() {
+			button.Style = discordgo.LinkButton
+			button.URL = strings.TrimSpace(action.URL)
+		} else {
+			value := strings.TrimSpace(action.Value)
+			if len(value) > discordCustomIDMaxLength {
+				return nil, fmt.Errorf("discord button value %q exceeds %d characters", button.Label, discordCustomIDMaxLength)
+			}
+			button.Style = discordgo.PrimaryButton
+			button.CustomID = value
+		}
+		row = append(row, button)
+		if len(row) == discordButtonsPerRow {
+			rows = append(rows, discordgo.ActionsRow{Components: row})
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, discordgo.ActionsRow{Components: row})
+	}
+	return rows, nil
+}
+
+// buildDiscordActionInbound converts a button interaction into an action
+// message. Interactions other than button presses are ignored.
+func buildDiscordActionInbound(i *discordgo.InteractionCreate) (channel.InboundMessage, bool) {
+	if i == nil || i.Interaction == nil || i.Type != discordgo.InteractionMessageComponent {
+		return channel.InboundMessage{}, false
+	}
+	data := i.MessageComponentData()
+	value := strings.TrimSpace(data.CustomID)
+	if value == "" {
+		return channel.InboundMessage{}, false
+	}
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		return channel.InboundMessage{}, false
+	}
+	isDM := i.GuildID == ""
+	chatType := "channel"
+	if isDM {
+		chatType = "dm"
+	}
+	var reply *channel.ReplyRef
+	label := ""
+	if i.Message != nil {
+		reply = &channel.ReplyRef{MessageID: i.Message.ID, Target: i.ChannelID}
+		label = discordButtonLabel(i.Message.Components, data.CustomID)
+	}
+	return channel.InboundMessage{
+		Channel: Type,
+		Kind:    channel.InboundKindAction,
+		Action: &channel.Action{
+			Type:  channel.ActionTypeButton,
+			Label: label,
+			Value: value,
+		},
+		Message: channel.Message{
+			Format: channel.MessageFormatMarkdown,
+			Reply:  reply,
+		},
+		ReplyTarget: i.ChannelID,
+		Sender: channel.Identity{
+			SubjectID:   user.ID,
+			DisplayName: user.Username,
+			Attributes: map[string]string{
+				"user_id":    user.ID,
+				"username":   user.Username,
+				"channel_id": i.ChannelID,
+			},
+		},
+		Conversation: channel.Conversation{
+			ID:   i.ChannelID,
+			Type: chatType,
+		},
+		ReceivedAt: time.Now().UTC(),
+		Source:     "discord",
+		Metadata: map[string]any{
+			// Pressing the bot's own button is addressed to the bot.
+			"is_mentioned":    false,
+			"is_reply_to_bot": true,
+			"is_dm":           isDM,
+		},
+	}, true
+}
+
+func discordButtonLabel(components []discordgo.MessageComponent, customID string) string {
+	for _, component := range components {
+		var children []discordgo.MessageComponent
+		switch row := component.(type) {
+		case *discordgo.ActionsRow:
+			children = row.Components
+		case discordgo.ActionsRow:
+			children = row.Components
+		}
+		for _, child := range children {
+			switch button := child.(type) {
+			case *discordgo.Button:
+				if button.CustomID == customID {
+					return button.Label
+				}
+			case discordgo.Button:
+				if button.CustomID == customID {
+					return button.Label
+				}
+			}
+		}
+	}
+	return ""
+}
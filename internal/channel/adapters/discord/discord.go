@@ -2,6 +2,7 @@ package discord
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -71,6 +72,7 @@ func (a *DiscordAdapter) Descriptor() channel.Descriptor {
 			Reply:          true,
 			Attachments:    true,
 			Media:          true,
+			Buttons:        true,
 			Streaming:      true,
 			BlockStreaming: true,
 		},
@@ -315,18 +317,61 @@ func (a *DiscordAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 		}()
 	})
 
-	if err := s.Open(); err != nil {
-		cancel()
-		a.logger.Error("open session failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
-		return nil, err
-	}
+	s.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if discordCfg.GuildID != "" && i.GuildID != "" && i.GuildID != discordCfg.GuildID {
+			return
+		}
+		msg, ok := buildDiscordActionInbound(i)
+		if !ok {
+			return
+		}
+		// Acknowledge within Discord's 3s window; the reply arrives as a new message.
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		}); err != nil {
+			a.logger.Warn("acknowledge interaction failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		msg.BotID = cfg.BotID
+		a.logger.Info("inbound action received",
+			slog.String("config_id", cfg.ID),
+			slog.String("chat_type", msg.Conversation.Type),
+			slog.String("channel_id", msg.Conversation.ID),
+			slog.String("user_id", msg.Sender.SubjectID),
+			slog.String("value", msg.Action.Value),
+		)
+		go func() {
+			if err := handler(connCtx, cfg, msg); err != nil {
+				a.logger.Error("handle inbound action failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+		}()
+	})
 
 	stop := func(_ context.Context) error {
 		a.logger.Info("stop", slog.String("config_id", cfg.ID))
 		cancel()
 		return s.Close()
 	}
-	return channel.NewConnection(cfg, stop), nil
+	conn := channel.NewConnection(cfg, stop)
+	// discordgo reconnects on its own; track the gateway link so the manager
+	// can report outages.
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) {
+		if connCtx.Err() == nil {
+			conn.MarkLost(errors.New("discord gateway disconnected"))
+		}
+	})
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Resumed) {
+		conn.MarkRecovered()
+	})
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Ready) {
+		conn.MarkRecovered()
+	})
+
+	if err := s.Open(); err != nil {
+		cancel()
+		a.logger.Error("open session failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		return nil, err
+	}
+	return conn, nil
 }
 
 // resolveChannelID resolves a target to a Discord channel ID.
@@ -364,8 +409,12 @@ func (a *DiscordAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, ms
 	if err != nil {
 		return err
 	}
+	components, err := buildDiscordComponents(msg.Message.Actions)
+	if err != nil {
+		return err
+	}
 	text := formatDiscordOutput(msg.Message.PlainText())
-	msgSend := &discordgo.MessageSend{Content: text}
+	msgSend := &discordgo.MessageSend{Content: text, Components: components}
 	if msg.Message.Reply != nil && msg.Message.Reply.MessageID != "" {
 		msgSend.Reference = &discordgo.MessageReference{MessageID: msg.Message.Reply.MessageID}
 	}
@@ -0,0 +1,142 @@
+package feishu
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+// Keys stored in a card button's value. Feishu echoes the value back in the
+// card.action.trigger callback, which carries no chat type, so the send
+// target is kept alongside the action to route the reply.
+const (
+	feishuActionValueKey  = "memoh_value"
+	feishuActionLabelKey  = "memoh_label"
+	feishuActionTargetKey = "memoh_target"
+)
+
+// buildFeishuActionCardContent renders text and actions as an interactive
+// card with one button per action.
+func buildFeishuActionCardContent(text string, actions []channel.Action, target string) (string, error) {
+	buttons := make([]map[string]any, 0, len(actions))
+	for _, action := range actions {
+		if err := action.Validate(); err != nil {
+			return "", err
+		}
+		label := strings.TrimSpace(action.Label)
+		button := map[string]any{
+			"tag":  "button",
+			"text": map[string]any{"tag": "plain_text", "content": label},
+			"type": "default",
+		}
+		if action.IsLink() {
+			button["url"] = strings.TrimSpace(action.URL)
+		} else {
+			button["type"] = "primary"
+			button["value"] = map[string]any{
+				feishuActionValueKey:  strings.TrimSpace(action.Value),
+				feishuActionLabelKey:  label,
+				feishuActionTargetKey: target,
+			}
+		}
+		buttons = append(buttons, button)
+	}
+	elements := make([]map[string]any, 0, 2)
+	if text = strings.TrimSpace(text); text != "" {
+		elements = append(elements, map[string]any{
+			"tag":  "div",
+			"text": map[string]any{"tag": "lark_md", "content": processFeishuCardMarkdown(text)},
+		})
+	}
+	elements = append(elements, map[string]any{"tag": "action", "actions": buttons})
+	card := map[string]any{
+		"config":   map[string]any{"wide_screen_mode": true},
+		"elements": elements,
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// extractFeishuActionInbound converts a card button callback into an action
+// message. Callbacks from cards this adapter did not build are ignored.
+func extractFeishuActionInbound(event *callback.CardActionTriggerEvent) (channel.InboundMessage, bool) {
+	if event == nil || event.Event == nil || event.Event.Action == nil {
+		return channel.InboundMessage{}, false
+	}
+	values := event.Event.Action.Value
+	value, _ := values[feishuActionValueKey].(string)
+	if value = strings.TrimSpace(value); value == "" {
+		return channel.InboundMessage{}, false
+	}
+	label, _ := values[feishuActionLabelKey].(string)
+	target, _ := values[feishuActionTargetKey].(string)
+	target = strings.TrimSpace(target)
+
+	senderID, senderOpenID := "", ""
+	if operator := event.Event.Operator; operator != nil {
+		senderOpenID = strings.TrimSpace(operator.OpenID)
+		if operator.UserID != nil {
+			senderID = strings.TrimSpace(*operator.UserID)
+		}
+	}
+	chatID, messageID := "", ""
+	if ctx := event.Event.Context; ctx != nil {
+		chatID = strings.TrimSpace(ctx.OpenChatID)
+		messageID = strings.TrimSpace(ctx.OpenMessageID)
+	}
+	chatType := "p2p"
+	if strings.HasPrefix(target, "chat_id:") {
+		chatType = "group"
+	}
+	if target == "" {
+		target = senderOpenID
+	}
+	attrs := map[string]string{}
+	if senderID != "" {
+		attrs["user_id"] = senderID
+	}
+	if senderOpenID != "" {
+		attrs["open_id"] = senderOpenID
+	}
+	subjectID := senderOpenID
+	if subjectID == "" {
+		subjectID = senderID
+	}
+	msg := channel.Message{}
+	if messageID != "" {
+		msg.Reply = &channel.ReplyRef{MessageID: messageID}
+	}
+	return channel.InboundMessage{
+		Channel: Type,
+		Kind:    channel.InboundKindAction,
+		Action: &channel.Action{
+			Type:  channel.ActionTypeButton,
+			Label: strings.TrimSpace(label),
+			Value: value,
+		},
+		Message:     msg,
+		ReplyTarget: target,
+		Sender: channel.Identity{
+			SubjectID:  subjectID,
+			Attributes: attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   chatID,
+			Type: chatType,
+		},
+		ReceivedAt: time.Now().UTC(),
+		Source:     "feishu",
+		Metadata: map[string]any{
+			// Pressing the bot's own button is addressed to the bot.
+			"is_mentioned":    false,
+			"is_reply_to_bot": true,
+		},
+	}, true
+}
@@ -0,0 +1,79 @@
+package feishu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+func TestBuildFeishuActionCardContent(t *testing.T) {
+	content, err := buildFeishuActionCardContent("Approve expense #42?", []channel.Action{
+		{Label: "Approve", Value: "expense:42:approve"},
+		{Label: "Open", URL: "https://example.com"},
+	}, "chat_id:oc_1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var card struct {
+		Elements []struct {
+			Tag     string           `json:"tag"`
+			Actions []map[string]any `json:"actions"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(content), &card); err != nil {
+		t.Fatalf("invalid card json: %v", err)
+	}
+	if len(card.Elements) != 2 || card.Elements[1].Tag != "action" || len(card.Elements[1].Actions) != 2 {
+		t.Fatalf("unexpected card: %s", content)
+	}
+	value, _ := card.Elements[1].Actions[0]["value"].(map[string]any)
+	if value[feishuActionValueKey] != "expense:42:approve" || value[feishuActionTargetKey] != "chat_id:oc_1" {
+		t.Fatalf("unexpected button value: %#v", value)
+	}
+	if card.Elements[1].Actions[1]["url"] != "https://example.com" {
+		t.Fatalf("unexpected link button: %#v", card.Elements[1].Actions[1])
+	}
+
+	if _, err := buildFeishuActionCardContent("hi", []channel.Action{{Label: "Go"}}, "ou_1"); err == nil {
+		t.Fatal("expected error for button without value")
+	}
+}
+
+func TestExtractFeishuActionInbound(t *testing.T) {
+	userID := "u_1"
+	event := &callback.CardActionTriggerEvent{
+		Event: &callback.CardActionTriggerRequest{
+			Operator: &callback.Operator{OpenID: "ou_1", UserID: &userID},
+			Action: &callback.CallBackAction{
+				Tag: "button",
+				Value: map[string]any{
+					feishuActionValueKey:  "expense:42:approve",
+					feishuActionLabelKey:  "Approve",
+					feishuActionTargetKey: "chat_id:oc_1",
+				},
+			},
+			Context: &callback.Context{OpenChatID: "oc_1", OpenMessageID: "om_1"},
+		},
+	}
+	msg, ok := extractFeishuActionInbound(event)
+	if !ok {
+		t.Fatal("expected action message")
+	}
+	if !msg.IsAction() || msg.Action.Value != "expense:42:approve" || msg.Action.Label != "Approve" {
+		t.Fatalf("unexpected action: %#v", msg.Action)
+	}
+	if msg.ReplyTarget != "chat_id:oc_1" || msg.Conversation.Type != "group" || msg.Conversation.ID != "oc_1" {
+		t.Fatalf("unexpected routing: %#v", msg)
+	}
+	if msg.Sender.SubjectID != "ou_1" || msg.Sender.Attribute("user_id") != "u_1" {
+		t.Fatalf("unexpected sender: %#v", msg.Sender)
+	}
+
+	event.Event.Action.Value = map[string]any{"other": "x"}
+	if _, ok := extractFeishuActionInbound(event); ok {
+		t.Fatal("expected foreign card callback to be ignored")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 	larkws "github.com/larksuite/oapi-sdk-go/v3/ws"
 
@@ -121,6 +123,7 @@ func (a *FeishuAdapter) Descriptor() channel.Descriptor {
 			Attachments:    true,
 			Media:          true,
 			Reactions:      true,
+			Buttons:        true,
 			Reply:          true,
 			Streaming:      true,
 			BlockStreaming: true,
@@ -421,6 +424,31 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 			}()
 			return nil
 		})
+		eventDispatcher.OnP2CardActionTrigger(func(_ context.Context, event *callback.CardActionTriggerEvent) (*callback.CardActionTriggerResponse, error) {
+			if connCtx.Err() != nil {
+				return nil, nil
+			}
+			msg, ok := extractFeishuActionInbound(event)
+			if !ok {
+				return &callback.CardActionTriggerResponse{}, nil
+			}
+			msg.BotID = cfg.BotID
+			if a.logger != nil {
+				a.logger.Info(
+					"inbound action received",
+					slog.String("config_id", cfg.ID),
+					slog.String("route_key", msg.RoutingKey()),
+					slog.String("chat_type", msg.Conversation.Type),
+					slog.String("value", msg.Action.Value),
+				)
+			}
+			go func() {
+				if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
+					a.logger.Error("handle inbound action failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+				}
+			}()
+			return &callback.CardActionTriggerResponse{}, nil
+		})
 		eventDispatcher.OnP2MessageReadV1(func(_ context.Context, _ *larkim.P2MessageReadV1) error {
 			return nil
 		})
@@ -441,6 +469,12 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 		)
 	}
 
+	stop := func(context.Context) error {
+		cancel()
+		return nil
+	}
+	conn := channel.NewConnection(cfg, stop)
+
 	go func() {
 		const reconnectDelay = 3 * time.Second
 		for {
@@ -459,6 +493,10 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 					a.logger.Warn("client exited without error; reconnecting", slog.String("config_id", cfg.ID))
 				}
 			}
+			if err == nil {
+				err = errors.New("feishu websocket client exited")
+			}
+			conn.MarkLost(err)
 			timer := time.NewTimer(reconnectDelay)
 			select {
 			case <-connCtx.Done():
@@ -469,11 +507,7 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 		}
 	}()
 
-	stop := func(context.Context) error {
-		cancel()
-		return nil
-	}
-	return channel.NewConnection(cfg, stop), nil
+	return conn, nil
 }
 
 // Send delivers an outbound message to Feishu, handling attachments, rich text, and replies.
@@ -505,7 +539,14 @@ func (a *FeishuAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg
 	var msgType string
 	var content string
 
-	if len(msg.Message.Parts) > 1 {
+	if len(msg.Message.Actions) > 0 {
+		msgType = larkim.MsgTypeInteractive
+		cardContent, cardErr := buildFeishuActionCardContent(msg.Message.PlainText(), msg.Message.Actions, strings.TrimSpace(msg.Target))
+		if cardErr != nil {
+			return cardErr
+		}
+		content = cardContent
+	} else if len(msg.Message.Parts) > 1 {
 		msgType = larkim.MsgTypePost
 		postContent, postErr := a.buildPostContent(msg.Message)
 		if postErr != nil {
@@ -0,0 +1,142 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+const (
+	// telegramCallbackDataMaxBytes is the Bot API limit for callback_data.
+	telegramCallbackDataMaxBytes = 64
+	telegramButtonsPerRow        = 3
+)
+
+// buildTelegramInlineKeyboard renders actions as an inline keyboard. It
+// returns nil when there are no actions.
+func buildTelegramInlineKeyboard(actions []channel.Action) (*tgbotapi.InlineKeyboardMarkup, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, (len(actions)+telegramButtonsPerRow-1)/telegramButtonsPerRow)
+	row := make([]tgbotapi.InlineKeyboardButton, 0, telegramButtonsPerRow)
+	for _, action := range actions {
+		if err := action.Validate(); err != nil {
+			return nil, err
+		}
+		label := strings.TrimSpace(action.Label)
+		if action.IsLink() {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonURL(label, strings.TrimSpace(action.URL)))
+		} else {
+			value := strings.TrimSpace(action.Value)
+			if len(value) > telegramCallbackDataMaxBytes {
+				return nil, fmt.Errorf("telegram button value %q exceeds %d bytes", label, telegramCallbackDataMaxBytes)
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, value))
+		}
+		if len(row) == telegramButtonsPerRow {
+			rows = append(rows, row)
+			row = make([]tgbotapi.InlineKeyboardButton, 0, telegramButtonsPerRow)
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &markup, nil
+}
+
+// handleCallbackQuery forwards an inline keyboard press as an action message.
+func (a *TelegramAdapter) handleCallbackQuery(ctx context.Context, bot *tgbotapi.BotAPI, cfg channel.ChannelConfig, handler channel.InboundHandler, query *tgbotapi.CallbackQuery) {
+	// Answer right away so the client stops showing a spinner on the button.
+	if _, err := bot.Request(tgbotapi.NewCallback(query.ID, "")); err != nil && a.logger != nil {
+		a.logger.Warn("answer callback query failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+	}
+	msg, ok := buildTelegramActionInbound(query)
+	if !ok {
+		return
+	}
+	msg.BotID = cfg.BotID
+	if a.logger != nil {
+		a.logger.Info(
+			"inbound action received",
+			slog.String("config_id", cfg.ID),
+			slog.String("chat_type", msg.Conversation.Type),
+			slog.String("chat_id", msg.Conversation.ID),
+			slog.String("user_id", msg.Sender.Attribute("user_id")),
+			slog.String("value", msg.Action.Value),
+		)
+	}
+	go func() {
+		if err := handler(ctx, cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound action failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}()
+}
+
+// buildTelegramActionInbound converts a callback query into an action
+// message. Presses on messages too old to carry their chat are dropped.
+func buildTelegramActionInbound(query *tgbotapi.CallbackQuery) (channel.InboundMessage, bool) {
+	if query == nil || query.Message == nil || query.Message.Chat == nil {
+		return channel.InboundMessage{}, false
+	}
+	value := strings.TrimSpace(query.Data)
+	if value == "" {
+		return channel.InboundMessage{}, false
+	}
+	source := query.Message
+	chatID := strconv.FormatInt(source.Chat.ID, 10)
+	subjectID, displayName, attrs := resolveTelegramSender(&tgbotapi.Message{From: query.From, Chat: source.Chat})
+	return channel.InboundMessage{
+		Channel: Type,
+		Kind:    channel.InboundKindAction,
+		Action: &channel.Action{
+			Type:  channel.ActionTypeButton,
+			Label: telegramButtonLabel(source.ReplyMarkup, query.Data),
+			Value: value,
+		},
+		Message: channel.Message{
+			Format: channel.MessageFormatPlain,
+			Reply:  &channel.ReplyRef{Target: chatID, MessageID: strconv.Itoa(source.MessageID)},
+		},
+		ReplyTarget: chatID,
+		Sender: channel.Identity{
+			SubjectID:   subjectID,
+			DisplayName: displayName,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   chatID,
+			Type: strings.TrimSpace(source.Chat.Type),
+			Name: strings.TrimSpace(source.Chat.Title),
+		},
+		ReceivedAt: time.Now().UTC(),
+		Source:     "telegram",
+		Metadata: map[string]any{
+			// A press on the bot's own buttons is addressed to the bot, so
+			// it triggers a reply in groups like a reply to the bot would.
+			"is_reply_to_bot": true,
+		},
+	}, true
+}
+
+func telegramButtonLabel(markup *tgbotapi.InlineKeyboardMarkup, data string) string {
+	if markup == nil {
+		return ""
+	}
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != nil && *button.CallbackData == data {
+				return button.Text
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+func TestBuildTelegramInlineKeyboard(t *testing.T) {
+	t.Parallel()
+
+	keyboard, err := buildTelegramInlineKeyboard([]channel.Action{
+		{Type: channel.ActionTypeButton, Label: "Approve", Value: "approve"},
+		{Type: channel.ActionTypeButton, Label: "Reject", Value: "reject"},
+		{Type: channel.ActionTypeButton, Label: "Later", Value: "later"},
+		{Type: channel.ActionTypeLink, Label: "Open", URL: "https://example.com"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(keyboard.InlineKeyboard) != 2 || len(keyboard.InlineKeyboard[0]) != 3 {
+		t.Fatalf("unexpected layout: %#v", keyboard.InlineKeyboard)
+	}
+	if data := keyboard.InlineKeyboard[0][0].CallbackData; data == nil || *data != "approve" {
+		t.Fatalf("unexpected callback data: %#v", data)
+	}
+	if url := keyboard.InlineKeyboard[1][0].URL; url == nil || *url != "https://example.com" {
+		t.Fatalf("unexpected url: %#v", url)
+	}
+
+	if keyboard, err := buildTelegramInlineKeyboard(nil); err != nil || keyboard != nil {
+		t.Fatalf("expected nil keyboard, got %#v, %v", keyboard, err)
+	}
+	if _, err := buildTelegramInlineKeyboard([]channel.Action{{Label: "Big", Value: strings.Repeat("x", 65)}}); err == nil {
+		t.Fatal("expected error for oversized callback data")
+	}
+}
+
+func TestBuildTelegramActionInbound(t *testing.T) {
+	t.Parallel()
+
+	keyboard, _ := buildTelegramInlineKeyboard([]channel.Action{{Label: "Approve", Value: "expense:42:approve"}})
+	query := &tgbotapi.CallbackQuery{
+		ID:   "cb-1",
+		From: &tgbotapi.User{ID: 5, UserName: "alice"},
+		Data: "expense:42:approve",
+		Message: &tgbotapi.Message{
+			MessageID:   77,
+			Chat:        &tgbotapi.Chat{ID: -100, Type: "supergroup", Title: "Finance"},
+			ReplyMarkup: keyboard,
+		},
+	}
+	msg, ok := buildTelegramActionInbound(query)
+	if !ok {
+		t.Fatal("expected action message")
+	}
+	if !msg.IsAction() || msg.Action.Value != "expense:42:approve" || msg.Action.Label != "Approve" {
+		t.Fatalf("unexpected action: %#v", msg.Action)
+	}
+	if msg.ReplyTarget != "-100" || msg.Conversation.Type != "supergroup" || msg.Sender.SubjectID != "5" {
+		t.Fatalf("unexpected routing: %#v", msg)
+	}
+	if msg.Message.Reply == nil || msg.Message.Reply.MessageID != "77" {
+		t.Fatalf("unexpected reply ref: %#v", msg.Message.Reply)
+	}
+	if v, _ := msg.Metadata["is_reply_to_bot"].(bool); !v {
+		t.Fatal("expected is_reply_to_bot")
+	}
+
+	query.Message = nil
+	if _, ok := buildTelegramActionInbound(query); ok {
+		t.Fatal("expected callback without message to be dropped")
+	}
+}
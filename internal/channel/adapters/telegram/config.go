@@ -2,14 +2,30 @@ package telegram
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
 	"strings"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
 )
 
+// Update delivery modes.
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+// webhookSecretPattern is the character set Telegram accepts for secret_token.
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
 // Config holds the Telegram bot credentials extracted from a channel configuration.
+// In webhook mode WebhookBaseURL is the public base URL of this server and
+// WebhookSecret is echoed back by Telegram in X-Telegram-Bot-Api-Secret-Token.
 type Config struct {
-	BotToken string
+	BotToken       string
+	Mode           string
+	WebhookBaseURL string
+	WebhookSecret  string
 }
 
 // UserConfig holds the identifiers used to target a Telegram user or group.
@@ -24,9 +40,15 @@ func normalizeConfig(raw map[string]any) (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
-	return map[string]any{
+	result := map[string]any{
 		"botToken": cfg.BotToken,
-	}, nil
+		"mode":     cfg.Mode,
+	}
+	if cfg.Mode == ModeWebhook {
+		result["webhookBaseUrl"] = cfg.WebhookBaseURL
+		result["webhookSecret"] = cfg.WebhookSecret
+	}
+	return result, nil
 }
 
 func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
@@ -109,7 +131,32 @@ func parseConfig(raw map[string]any) (Config, error) {
 	if token == "" {
 		return Config{}, fmt.Errorf("telegram botToken is required")
 	}
-	return Config{BotToken: token}, nil
+	cfg := Config{BotToken: token, Mode: ModePolling}
+	switch mode := strings.ToLower(strings.TrimSpace(channel.ReadString(raw, "mode"))); mode {
+	case "", ModePolling:
+	case ModeWebhook:
+		cfg.Mode = ModeWebhook
+	default:
+		return Config{}, fmt.Errorf("telegram mode must be %q or %q", ModePolling, ModeWebhook)
+	}
+	if cfg.Mode != ModeWebhook {
+		return cfg, nil
+	}
+	cfg.WebhookBaseURL = strings.TrimRight(strings.TrimSpace(channel.ReadString(raw, "webhookBaseUrl", "webhook_base_url")), "/")
+	cfg.WebhookSecret = strings.TrimSpace(channel.ReadString(raw, "webhookSecret", "webhook_secret"))
+	parsed, err := url.Parse(cfg.WebhookBaseURL)
+	if cfg.WebhookBaseURL == "" || err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return Config{}, fmt.Errorf("telegram webhookBaseUrl must be a public https URL in webhook mode")
+	}
+	if !webhookSecretPattern.MatchString(cfg.WebhookSecret) {
+		return Config{}, fmt.Errorf("telegram webhookSecret is required in webhook mode (1-256 characters: A-Z, a-z, 0-9, _ and -)")
+	}
+	return cfg, nil
+}
+
+// webhookURL is the endpoint Telegram posts updates of configID to.
+func (c Config) webhookURL(configID string) string {
+	return c.WebhookBaseURL + webhookPathPrefix + url.PathEscape(configID)
 }
 
 func parseUserConfig(raw map[string]any) (UserConfig, error) {
@@ -25,6 +25,62 @@ func TestNormalizeConfigRequiresToken(t *testing.T) {
 	}
 }
 
+func TestNormalizeConfigDefaultsToPolling(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{"botToken": "token-123"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["mode"] != ModePolling {
+		t.Fatalf("unexpected mode: %#v", got["mode"])
+	}
+	if _, ok := got["webhookSecret"]; ok {
+		t.Fatalf("polling config should not carry webhook fields: %#v", got)
+	}
+}
+
+func TestNormalizeConfigWebhookMode(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"botToken":       "token-123",
+		"mode":           "Webhook",
+		"webhookBaseUrl": "https://memoh.example.com/",
+		"webhookSecret":  "s3cret_token-1",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["mode"] != ModeWebhook {
+		t.Fatalf("unexpected mode: %#v", got["mode"])
+	}
+	if got["webhookBaseUrl"] != "https://memoh.example.com" {
+		t.Fatalf("unexpected webhookBaseUrl: %#v", got["webhookBaseUrl"])
+	}
+	cfg, _ := parseConfig(got)
+	if url := cfg.webhookURL("cfg-1"); url != "https://memoh.example.com/channels/telegram/webhook/cfg-1" {
+		t.Fatalf("unexpected webhook url: %s", url)
+	}
+}
+
+func TestNormalizeConfigWebhookModeValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]map[string]any{
+		"unknown mode":   {"botToken": "t", "mode": "push"},
+		"missing url":    {"botToken": "t", "mode": "webhook", "webhookSecret": "abc"},
+		"plain http url": {"botToken": "t", "mode": "webhook", "webhookBaseUrl": "http://memoh.example.com", "webhookSecret": "abc"},
+		"missing secret": {"botToken": "t", "mode": "webhook", "webhookBaseUrl": "https://memoh.example.com"},
+		"invalid secret": {"botToken": "t", "mode": "webhook", "webhookBaseUrl": "https://memoh.example.com", "webhookSecret": "has space"},
+	}
+	for name, raw := range cases {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Fatalf("%s: expected error, got nil", name)
+		}
+	}
+}
+
 func TestNormalizeUserConfig(t *testing.T) {
 	t.Parallel()
 
@@ -24,6 +24,9 @@ type TelegramAdapter struct {
 	logger *slog.Logger
 	mu     sync.RWMutex
 	bots   map[string]*tgbotapi.BotAPI // keyed by bot token
+
+	webhookMu sync.RWMutex
+	webhooks  map[string]*webhookReceiver // keyed by channel config ID
 }
 
 // NewTelegramAdapter creates a TelegramAdapter with the given logger.
@@ -32,8 +35,9 @@ func NewTelegramAdapter(log *slog.Logger) *TelegramAdapter {
 		log = slog.Default()
 	}
 	adapter := &TelegramAdapter{
-		logger: log.With(slog.String("adapter", "telegram")),
-		bots:   make(map[string]*tgbotapi.BotAPI),
+		logger:   log.With(slog.String("adapter", "telegram")),
+		bots:     make(map[string]*tgbotapi.BotAPI),
+		webhooks: make(map[string]*webhookReceiver),
 	}
 	_ = tgbotapi.SetLogger(&slogBotLogger{log: adapter.logger})
 	return adapter
@@ -83,6 +87,7 @@ func (a *TelegramAdapter) Descriptor() channel.Descriptor {
 			Reply:          true,
 			Attachments:    true,
 			Media:          true,
+			Buttons:        true,
 			Streaming:      true,
 			BlockStreaming: true,
 		},
@@ -94,6 +99,24 @@ func (a *TelegramAdapter) Descriptor() channel.Descriptor {
 					Required: true,
 					Title:    "Bot API",
 				},
+				"mode": {
+					Type:        channel.FieldEnum,
+					Title:       "Update Mode",
+					Description: "polling (default) or webhook",
+					Enum:        []string{ModePolling, ModeWebhook},
+					Example:     ModePolling,
+				},
+				"webhookBaseUrl": {
+					Type:        channel.FieldString,
+					Title:       "Webhook Base URL",
+					Description: "Public https URL of this server; required in webhook mode",
+					Example:     "https://memoh.example.com",
+				},
+				"webhookSecret": {
+					Type:        channel.FieldSecret,
+					Title:       "Webhook Secret",
+					Description: "Secret token Telegram sends with each update (A-Z, a-z, 0-9, _ and -); required in webhook mode",
+				},
 			},
 		},
 		UserConfigSchema: channel.ConfigSchema{
@@ -144,7 +167,9 @@ func (a *TelegramAdapter) BuildUserConfig(identity channel.Identity) map[string]
 	return buildUserConfig(identity)
 }
 
-// Connect starts long-polling for Telegram updates and forwards messages to the handler.
+// Connect starts receiving Telegram updates and forwards messages to the handler.
+// In polling mode it long-polls getUpdates; in webhook mode it registers the
+// webhook with Telegram and waits for HandleWebhook to deliver updates.
 func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
 	if a.logger != nil {
 		a.logger.Info("start", slog.String("config_id", cfg.ID))
@@ -163,11 +188,36 @@ func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig
 		}
 		return nil, err
 	}
+	if telegramCfg.Mode == ModeWebhook {
+		return a.connectWebhook(ctx, bot, cfg, telegramCfg, handler)
+	}
+	// A webhook left over from webhook mode makes getUpdates fail with a
+	// conflict, so clear it before polling.
+	if err := deleteTelegramWebhook(bot); err != nil && a.logger != nil {
+		a.logger.Warn("delete webhook failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+	}
 	updateConfig := tgbotapi.NewUpdate(0)
 	updateConfig.Timeout = 30
 	updates := bot.GetUpdatesChan(updateConfig)
 	connCtx, cancel := context.WithCancel(ctx)
 
+	stop := func(_ context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		bot.StopReceivingUpdates()
+		cancel()
+		// Drain remaining updates so the library's polling goroutine can
+		// finish writing and exit. Without this, the in-flight long-poll
+		// HTTP request keeps the old getUpdates session alive, causing
+		// "Conflict: terminated by other getUpdates request" when a new
+		// connection starts with the same bot token.
+		for range updates {
+		}
+		return nil
+	}
+	conn := channel.NewConnection(cfg, stop)
+
 	go func() {
 		for {
 			select {
@@ -178,100 +228,98 @@ func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig
 					if a.logger != nil {
 						a.logger.Info("updates channel closed", slog.String("config_id", cfg.ID))
 					}
+					if connCtx.Err() == nil {
+						conn.MarkLost(errors.New("telegram updates channel closed"))
+					}
 					return
 				}
-				if update.Message == nil {
-					continue
-				}
-				text := strings.TrimSpace(update.Message.Text)
-				caption := strings.TrimSpace(update.Message.Caption)
-				if text == "" && caption != "" {
-					text = caption
-				}
-				attachments := a.collectTelegramAttachments(bot, update.Message)
-				if text == "" && len(attachments) == 0 {
-					continue
-				}
-				subjectID, displayName, attrs := resolveTelegramSender(update.Message)
-				chatID := ""
-				chatType := ""
-				chatName := ""
-				if update.Message.Chat != nil {
-					chatID = strconv.FormatInt(update.Message.Chat.ID, 10)
-					chatType = strings.TrimSpace(update.Message.Chat.Type)
-					chatName = strings.TrimSpace(update.Message.Chat.Title)
-				}
-				replyRef := buildTelegramReplyRef(update.Message, chatID)
-				isReplyToBot := update.Message.ReplyToMessage != nil &&
-					update.Message.ReplyToMessage.From != nil &&
-					update.Message.ReplyToMessage.From.ID == bot.Self.ID
-				isMentioned := isTelegramBotMentioned(update.Message, bot.Self.UserName)
-				isFromBot := update.Message.From != nil && update.Message.From.IsBot
-				msg := channel.InboundMessage{
-					Channel: Type,
-					Message: channel.Message{
-						ID:          strconv.Itoa(update.Message.MessageID),
-						Format:      channel.MessageFormatPlain,
-						Text:        text,
-						Attachments: attachments,
-						Reply:       replyRef,
-					},
-					BotID:       cfg.BotID,
-					ReplyTarget: chatID,
-					Sender: channel.Identity{
-						SubjectID:   subjectID,
-						DisplayName: displayName,
-						Attributes:  attrs,
-					},
-					Conversation: channel.Conversation{
-						ID:   chatID,
-						Type: chatType,
-						Name: chatName,
-					},
-					ReceivedAt: time.Unix(int64(update.Message.Date), 0).UTC(),
-					Source:     "telegram",
-					Metadata: map[string]any{
-						"is_mentioned":    isMentioned,
-						"is_reply_to_bot": isReplyToBot,
-						"is_from_bot":     isFromBot,
-					},
-				}
-				if a.logger != nil {
-					a.logger.Info(
-						"inbound received",
-						slog.String("config_id", cfg.ID),
-						slog.String("chat_type", msg.Conversation.Type),
-						slog.String("chat_id", msg.Conversation.ID),
-						slog.String("user_id", attrs["user_id"]),
-						slog.String("username", attrs["username"]),
-						slog.String("text", common.SummarizeText(text)),
-					)
-				}
-				go func() {
-					if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
-						a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
-					}
-				}()
+				a.handleUpdate(connCtx, bot, cfg, handler, update)
 			}
 		}
 	}()
 
-	stop := func(_ context.Context) error {
-		if a.logger != nil {
-			a.logger.Info("stop", slog.String("config_id", cfg.ID))
-		}
-		bot.StopReceivingUpdates()
-		cancel()
-		// Drain remaining updates so the library's polling goroutine can
-		// finish writing and exit. Without this, the in-flight long-poll
-		// HTTP request keeps the old getUpdates session alive, causing
-		// "Conflict: terminated by other getUpdates request" when a new
-		// connection starts with the same bot token.
-		for range updates {
-		}
-		return nil
+	return conn, nil
+}
+
+// handleUpdate converts one Telegram update into an inbound message and
+// dispatches it to handler. It is shared by polling and webhook delivery.
+func (a *TelegramAdapter) handleUpdate(ctx context.Context, bot *tgbotapi.BotAPI, cfg channel.ChannelConfig, handler channel.InboundHandler, update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		a.handleCallbackQuery(ctx, bot, cfg, handler, update.CallbackQuery)
+		return
+	}
+	if update.Message == nil {
+		return
+	}
+	text := strings.TrimSpace(update.Message.Text)
+	caption := strings.TrimSpace(update.Message.Caption)
+	if text == "" && caption != "" {
+		text = caption
+	}
+	attachments := a.collectTelegramAttachments(bot, update.Message)
+	if text == "" && len(attachments) == 0 {
+		return
+	}
+	subjectID, displayName, attrs := resolveTelegramSender(update.Message)
+	chatID := ""
+	chatType := ""
+	chatName := ""
+	if update.Message.Chat != nil {
+		chatID = strconv.FormatInt(update.Message.Chat.ID, 10)
+		chatType = strings.TrimSpace(update.Message.Chat.Type)
+		chatName = strings.TrimSpace(update.Message.Chat.Title)
+	}
+	replyRef := buildTelegramReplyRef(update.Message, chatID)
+	isReplyToBot := update.Message.ReplyToMessage != nil &&
+		update.Message.ReplyToMessage.From != nil &&
+		update.Message.ReplyToMessage.From.ID == bot.Self.ID
+	isMentioned := isTelegramBotMentioned(update.Message, bot.Self.UserName)
+	isFromBot := update.Message.From != nil && update.Message.From.IsBot
+	msg := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:          strconv.Itoa(update.Message.MessageID),
+			Format:      channel.MessageFormatPlain,
+			Text:        text,
+			Attachments: attachments,
+			Reply:       replyRef,
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: chatID,
+		Sender: channel.Identity{
+			SubjectID:   subjectID,
+			DisplayName: displayName,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   chatID,
+			Type: chatType,
+			Name: chatName,
+		},
+		ReceivedAt: time.Unix(int64(update.Message.Date), 0).UTC(),
+		Source:     "telegram",
+		Metadata: map[string]any{
+			"is_mentioned":    isMentioned,
+			"is_reply_to_bot": isReplyToBot,
+			"is_from_bot":     isFromBot,
+		},
+	}
+	if a.logger != nil {
+		a.logger.Info(
+			"inbound received",
+			slog.String("config_id", cfg.ID),
+			slog.String("chat_type", msg.Conversation.Type),
+			slog.String("chat_id", msg.Conversation.ID),
+			slog.String("user_id", attrs["user_id"]),
+			slog.String("username", attrs["username"]),
+			slog.String("text", common.SummarizeText(text)),
+		)
 	}
-	return channel.NewConnection(cfg, stop), nil
+	go func() {
+		if err := handler(ctx, cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}()
 }
 
 // Send delivers an outbound message to Telegram, handling text, attachments, and replies.
@@ -296,6 +344,13 @@ func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, m
 	}
 	text := strings.TrimSpace(msg.Message.PlainText())
 	text, parseMode := formatTelegramOutput(text, msg.Message.Format)
+	keyboard, err := buildTelegramInlineKeyboard(msg.Message.Actions)
+	if err != nil {
+		return err
+	}
+	if keyboard != nil && text == "" {
+		return fmt.Errorf("telegram buttons require message text")
+	}
 	// Distinguish between Bot auto-reply (Reply is nil) and explicit send tool reply
 	var replyTo int
 	if msg.Message.Reply != nil {
@@ -309,7 +364,8 @@ func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, m
 		usedCaption := false
 		for i, att := range msg.Message.Attachments {
 			caption := ""
-			if !usedCaption && text != "" {
+			// Buttons go on the text message, so keep the text out of captions.
+			if !usedCaption && text != "" && keyboard == nil {
 				caption = text
 				usedCaption = true
 			}
@@ -325,11 +381,11 @@ func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, m
 			}
 		}
 		if text != "" && !usedCaption {
-			return sendTelegramText(bot, to, text, replyTo, parseMode)
+			return sendTelegramText(bot, to, text, replyTo, parseMode, keyboard)
 		}
 		return nil
 	}
-	return sendTelegramText(bot, to, text, replyTo, parseMode)
+	return sendTelegramText(bot, to, text, replyTo, parseMode, keyboard)
 }
 
 // OpenStream opens a Telegram streaming session.
@@ -423,13 +479,17 @@ func parseReplyToMessageID(reply *channel.ReplyRef) int {
 	return value
 }
 
-func sendTelegramText(bot *tgbotapi.BotAPI, target string, text string, replyTo int, parseMode string) error {
-	_, _, err := sendTelegramTextReturnMessage(bot, target, text, replyTo, parseMode)
+func sendTelegramText(bot *tgbotapi.BotAPI, target string, text string, replyTo int, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	_, _, err := sendTelegramTextWithKeyboard(bot, target, text, replyTo, parseMode, keyboard)
 	return err
 }
 
 // sendTelegramTextReturnMessage sends a text message and returns the chat ID and message ID for later editing.
 func sendTelegramTextReturnMessage(bot *tgbotapi.BotAPI, target string, text string, replyTo int, parseMode string) (chatID int64, messageID int, err error) {
+	return sendTelegramTextWithKeyboard(bot, target, text, replyTo, parseMode, nil)
+}
+
+func sendTelegramTextWithKeyboard(bot *tgbotapi.BotAPI, target string, text string, replyTo int, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) (chatID int64, messageID int, err error) {
 	text = truncateTelegramText(sanitizeTelegramText(text))
 	var sent tgbotapi.Message
 	if strings.HasPrefix(target, "@") {
@@ -438,6 +498,9 @@ func sendTelegramTextReturnMessage(bot *tgbotapi.BotAPI, target string, text str
 		if replyTo > 0 {
 			message.ReplyToMessageID = replyTo
 		}
+		if keyboard != nil {
+			message.ReplyMarkup = keyboard
+		}
 		sent, err = bot.Send(message)
 		if err != nil {
 			return 0, 0, err
@@ -452,6 +515,9 @@ func sendTelegramTextReturnMessage(bot *tgbotapi.BotAPI, target string, text str
 		if replyTo > 0 {
 			message.ReplyToMessageID = replyTo
 		}
+		if keyboard != nil {
+			message.ReplyMarkup = keyboard
+		}
 		sent, err = bot.Send(message)
 		if err != nil {
 			return 0, 0, err
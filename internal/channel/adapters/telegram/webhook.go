@@ -0,0 +1,111 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+// webhookPathPrefix is the server route Telegram posts updates to; the channel
+// config ID is appended so each bot token has its own endpoint.
+const webhookPathPrefix = "/channels/telegram/webhook/"
+
+var (
+	// ErrWebhookNotFound is returned when no webhook-mode connection is
+	// running for the config ID in the request path.
+	ErrWebhookNotFound = errors.New("telegram webhook not registered")
+	// ErrWebhookUnauthorized is returned when the secret token header does not
+	// match the configured webhook secret.
+	ErrWebhookUnauthorized = errors.New("telegram webhook secret mismatch")
+)
+
+// webhookReceiver is a running webhook-mode connection.
+type webhookReceiver struct {
+	ctx     context.Context
+	bot     *tgbotapi.BotAPI
+	cfg     channel.ChannelConfig
+	secret  string
+	handler channel.InboundHandler
+}
+
+// connectWebhook registers the webhook with Telegram and routes updates
+// delivered to HandleWebhook for cfg.ID into handler until stopped.
+func (a *TelegramAdapter) connectWebhook(ctx context.Context, bot *tgbotapi.BotAPI, cfg channel.ChannelConfig, telegramCfg Config, handler channel.InboundHandler) (channel.Connection, error) {
+	// tgbotapi's WebhookConfig predates secret_token, so call setWebhook directly.
+	params := tgbotapi.Params{}
+	params["url"] = telegramCfg.webhookURL(cfg.ID)
+	params.AddNonEmpty("secret_token", telegramCfg.WebhookSecret)
+	if _, err := bot.MakeRequest("setWebhook", params); err != nil {
+		if a.logger != nil {
+			a.logger.Error("set webhook failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, fmt.Errorf("telegram setWebhook: %w", err)
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	receiver := &webhookReceiver{
+		ctx:     connCtx,
+		bot:     bot,
+		cfg:     cfg,
+		secret:  telegramCfg.WebhookSecret,
+		handler: handler,
+	}
+	a.webhookMu.Lock()
+	a.webhooks[cfg.ID] = receiver
+	a.webhookMu.Unlock()
+	if a.logger != nil {
+		a.logger.Info("webhook registered", slog.String("config_id", cfg.ID))
+	}
+
+	stop := func(_ context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		a.webhookMu.Lock()
+		if a.webhooks[cfg.ID] == receiver {
+			delete(a.webhooks, cfg.ID)
+		}
+		a.webhookMu.Unlock()
+		cancel()
+		// Deregister so Telegram stops posting to a config that is disabled;
+		// a restarted webhook connection registers again right after.
+		if err := deleteTelegramWebhook(bot); err != nil && a.logger != nil {
+			a.logger.Warn("delete webhook failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// HandleWebhook verifies and dispatches one update Telegram posted for the
+// given channel config. secretToken is the X-Telegram-Bot-Api-Secret-Token
+// header. The inbound handler runs asynchronously so the caller can answer
+// Telegram immediately.
+func (a *TelegramAdapter) HandleWebhook(configID, secretToken string, body []byte) error {
+	a.webhookMu.RLock()
+	receiver := a.webhooks[configID]
+	a.webhookMu.RUnlock()
+	if receiver == nil {
+		return ErrWebhookNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(secretToken), []byte(receiver.secret)) != 1 {
+		return ErrWebhookUnauthorized
+	}
+	var update tgbotapi.Update
+	if err := json.Unmarshal(body, &update); err != nil {
+		return fmt.Errorf("decode telegram update: %w", err)
+	}
+	a.handleUpdate(receiver.ctx, receiver.bot, receiver.cfg, receiver.handler, update)
+	return nil
+}
+
+func deleteTelegramWebhook(bot *tgbotapi.BotAPI) error {
+	_, err := bot.Request(tgbotapi.DeleteWebhookConfig{})
+	return err
+}
@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+func TestHandleWebhook(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewTelegramAdapter(nil)
+	received := make(chan channel.InboundMessage, 1)
+	adapter.webhooks["cfg-1"] = &webhookReceiver{
+		ctx:    context.Background(),
+		bot:    &tgbotapi.BotAPI{Self: tgbotapi.User{ID: 99, UserName: "memoh_bot"}},
+		cfg:    channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1"},
+		secret: "s3cret",
+		handler: func(_ context.Context, _ channel.ChannelConfig, msg channel.InboundMessage) error {
+			received <- msg
+			return nil
+		},
+	}
+	body := []byte(`{"update_id":1,"message":{"message_id":7,"date":1700000000,"text":"hello","chat":{"id":42,"type":"private"},"from":{"id":5,"first_name":"Alice","username":"alice"}}}`)
+
+	if err := adapter.HandleWebhook("cfg-2", "s3cret", body); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("expected ErrWebhookNotFound, got %v", err)
+	}
+	if err := adapter.HandleWebhook("cfg-1", "wrong", body); !errors.Is(err, ErrWebhookUnauthorized) {
+		t.Fatalf("expected ErrWebhookUnauthorized, got %v", err)
+	}
+	if err := adapter.HandleWebhook("cfg-1", "s3cret", []byte("{")); err == nil {
+		t.Fatalf("expected decode error, got nil")
+	}
+	if err := adapter.HandleWebhook("cfg-1", "s3cret", body); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg.Message.Text != "hello" || msg.ReplyTarget != "42" || msg.BotID != "bot-1" {
+			t.Fatalf("unexpected inbound message: %#v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler was not called")
+	}
+}
@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+)
+
+// Request authentication modes.
+const (
+	// AuthSignature expects X-Memoh-Timestamp and X-Memoh-Signature headers
+	// signed with the shared secret, like outgoing bot webhooks.
+	AuthSignature = "signature"
+	// AuthToken expects the secret itself as the token query parameter or
+	// X-Memoh-Token header, for senders that cannot sign requests.
+	AuthToken = "token"
+)
+
+// Reply delivery modes.
+const (
+	// ReplySync returns the replies in the HTTP response.
+	ReplySync = "sync"
+	// ReplyCallback answers 202 right away and POSTs replies to CallbackURL.
+	ReplyCallback = "callback"
+)
+
+// minSecretLength keeps shared secrets out of brute-force range.
+const minSecretLength = 16
+
+// Default JSON paths of the inbound fields.
+const (
+	defaultTextPath             = "text"
+	defaultMessageIDPath        = "id"
+	defaultSenderIDPath         = "sender.id"
+	defaultSenderNamePath       = "sender.name"
+	defaultConversationIDPath   = "conversation.id"
+	defaultConversationTypePath = "conversation.type"
+)
+
+// Config holds a webhook channel configuration. The *Path fields locate
+// inbound message fields in the posted JSON; see lookupPath for the syntax.
+type Config struct {
+	Secret      string
+	AuthMode    string
+	ReplyMode   string
+	CallbackURL string
+
+	TextPath             string
+	MessageIDPath        string
+	SenderIDPath         string
+	SenderNamePath       string
+	ConversationIDPath   string
+	ConversationTypePath string
+}
+
+// UserConfig holds the identifiers used to target a webhook sender or
+// conversation.
+type UserConfig struct {
+	SenderID       string
+	ConversationID string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{
+		"secret":               cfg.Secret,
+		"authMode":             cfg.AuthMode,
+		"replyMode":            cfg.ReplyMode,
+		"textPath":             cfg.TextPath,
+		"messageIdPath":        cfg.MessageIDPath,
+		"senderIdPath":         cfg.SenderIDPath,
+		"senderNamePath":       cfg.SenderNamePath,
+		"conversationIdPath":   cfg.ConversationIDPath,
+		"conversationTypePath": cfg.ConversationTypePath,
+	}
+	if cfg.CallbackURL != "" {
+		result["callbackUrl"] = cfg.CallbackURL
+	}
+	return result, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	if cfg.SenderID != "" {
+		result["sender_id"] = cfg.SenderID
+	}
+	if cfg.ConversationID != "" {
+		result["conversation_id"] = cfg.ConversationID
+	}
+	return result, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ConversationID != "" {
+		return cfg.ConversationID, nil
+	}
+	return cfg.SenderID, nil
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := strings.TrimSpace(criteria.Attribute("sender_id")); value != "" && value == cfg.SenderID {
+		return true
+	}
+	if value := strings.TrimSpace(criteria.Attribute("conversation_id")); value != "" && value == cfg.ConversationID {
+		return true
+	}
+	return criteria.SubjectID != "" && criteria.SubjectID == cfg.SenderID
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	result := map[string]any{}
+	if value := strings.TrimSpace(identity.Attribute("sender_id")); value != "" {
+		result["sender_id"] = value
+	} else if value := strings.TrimSpace(identity.SubjectID); value != "" {
+		result["sender_id"] = value
+	}
+	if value := strings.TrimSpace(identity.Attribute("conversation_id")); value != "" {
+		result["conversation_id"] = value
+	}
+	return result
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	cfg := Config{
+		Secret:      strings.TrimSpace(channel.ReadString(raw, "secret")),
+		AuthMode:    AuthSignature,
+		ReplyMode:   ReplySync,
+		CallbackURL: strings.TrimSpace(channel.ReadString(raw, "callbackUrl", "callback_url")),
+
+		TextPath:             readPath(raw, defaultTextPath, "textPath", "text_path"),
+		MessageIDPath:        readPath(raw, defaultMessageIDPath, "messageIdPath", "message_id_path"),
+		SenderIDPath:         readPath(raw, defaultSenderIDPath, "senderIdPath", "sender_id_path"),
+		SenderNamePath:       readPath(raw, defaultSenderNamePath, "senderNamePath", "sender_name_path"),
+		ConversationIDPath:   readPath(raw, defaultConversationIDPath, "conversationIdPath", "conversation_id_path"),
+		ConversationTypePath: readPath(raw, defaultConversationTypePath, "conversationTypePath", "conversation_type_path"),
+	}
+	if len(cfg.Secret) < minSecretLength {
+		return Config{}, fmt.Errorf("webhook secret must be at least %d characters", minSecretLength)
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(channel.ReadString(raw, "authMode", "auth_mode"))); mode {
+	case "", AuthSignature:
+	case AuthToken:
+		cfg.AuthMode = AuthToken
+	default:
+		return Config{}, fmt.Errorf("webhook authMode must be %q or %q", AuthSignature, AuthToken)
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(channel.ReadString(raw, "replyMode", "reply_mode"))); mode {
+	case "", ReplySync:
+	case ReplyCallback:
+		cfg.ReplyMode = ReplyCallback
+	default:
+		return Config{}, fmt.Errorf("webhook replyMode must be %q or %q", ReplySync, ReplyCallback)
+	}
+	if cfg.CallbackURL != "" {
+		parsed, err := url.Parse(cfg.CallbackURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return Config{}, fmt.Errorf("webhook callbackUrl must be an http(s) URL")
+		}
+	} else if cfg.ReplyMode == ReplyCallback {
+		return Config{}, fmt.Errorf("webhook callbackUrl is required in callback reply mode")
+	}
+	return cfg, nil
+}
+
+// readPath returns the configured JSON path or fallback when it is unset.
+func readPath(raw map[string]any, fallback string, keys ...string) string {
+	if value := strings.TrimSpace(channel.ReadString(raw, keys...)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	senderID := strings.TrimSpace(channel.ReadString(raw, "senderId", "sender_id"))
+	conversationID := strings.TrimSpace(channel.ReadString(raw, "conversationId", "conversation_id"))
+	if senderID == "" && conversationID == "" {
+		return UserConfig{}, fmt.Errorf("webhook user config requires sender_id or conversation_id")
+	}
+	return UserConfig{
+		SenderID:       senderID,
+		ConversationID: conversationID,
+	}, nil
+}
+
+func normalizeTarget(raw string) string {
+	return strings.TrimPrefix(strings.TrimSpace(raw), "webhook:")
+}
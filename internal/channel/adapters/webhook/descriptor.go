@@ -0,0 +1,9 @@
+// Package webhook implements a generic HTTP channel adapter: external systems
+// POST JSON to a per-config URL and get the bot's reply either in the HTTP
+// response or on a callback URL.
+package webhook
+
+import "github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+
+// Type is the registered ChannelType identifier for generic webhooks.
+const Type channel.ChannelType = "webhook"
@@ -0,0 +1,595 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/netguard"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/webhooks"
+)
+
+const (
+	// TokenHeader carries the secret in token auth mode for senders that
+	// cannot put it in the URL.
+	TokenHeader = "X-Memoh-Token"
+
+	// signatureTolerance bounds the clock skew accepted in signature mode.
+	signatureTolerance = 5 * time.Minute
+	// syncReplyTimeout bounds how long a sync request waits for the bot.
+	// Replies produced later go to the callback URL when one is configured.
+	syncReplyTimeout = 2 * time.Minute
+	callbackTimeout  = 10 * time.Second
+	// maxErrorBody caps how much of a failed callback response is reported.
+	maxErrorBody = 512
+	userAgent    = "Memoh-Webhook/1.0"
+	// replyEvent is the X-Memoh-Event value of callback requests.
+	replyEvent = "channel.reply"
+
+	// anonymousSender is the subject of payloads without sender or
+	// conversation ID, so they share one identity and conversation.
+	anonymousSender         = "anonymous"
+	defaultConversationType = "private"
+)
+
+var (
+	// ErrWebhookNotFound is returned when no connection is running for the
+	// config ID in the request path.
+	ErrWebhookNotFound = errors.New("webhook channel not registered")
+	// ErrWebhookUnauthorized is returned when the request signature or token
+	// does not match the configured secret.
+	ErrWebhookUnauthorized = errors.New("webhook channel authentication failed")
+	// ErrInvalidPayload is returned when the body is not JSON or has no text
+	// at the configured path.
+	ErrInvalidPayload = errors.New("invalid webhook payload")
+	// ErrMessageInFlight is returned when a sync request reuses the message ID
+	// of a request that is still waiting for its reply.
+	ErrMessageInFlight = errors.New("webhook message is already being processed")
+)
+
+// Request is one inbound HTTP call. Timestamp and Signature are the
+// X-Memoh-Timestamp and X-Memoh-Signature headers; Token is the token query
+// parameter or TokenHeader.
+type Request struct {
+	Body      []byte
+	Timestamp string
+	Signature string
+	Token     string
+}
+
+// Response is the answer to an inbound call. In callback mode Async is set
+// and Replies stays empty.
+type Response struct {
+	MessageID string            `json:"message_id"`
+	Replies   []channel.Message `json:"replies"`
+	// TimedOut reports that the bot was still working when the sync wait
+	// ended; later replies go to the callback URL if one is configured.
+	TimedOut bool `json:"timed_out,omitempty"`
+	Async    bool `json:"-"`
+}
+
+// callbackPayload is the JSON body POSTed to the callback URL.
+type callbackPayload struct {
+	BotID    string            `json:"bot_id"`
+	ConfigID string            `json:"config_id"`
+	Target   string            `json:"target"`
+	ReplyTo  string            `json:"reply_to,omitempty"`
+	Messages []channel.Message `json:"messages"`
+}
+
+// receiver is a running webhook connection.
+type receiver struct {
+	ctx        context.Context
+	cfg        channel.ChannelConfig
+	webhookCfg Config
+	handler    channel.InboundHandler
+}
+
+// pendingReply collects the replies of a sync request.
+type pendingReply struct {
+	messages []channel.Message
+}
+
+// WebhookAdapter implements channel.Adapter, channel.Sender,
+// channel.StreamSender and channel.Receiver for generic HTTP webhooks.
+type WebhookAdapter struct {
+	logger *slog.Logger
+	client *http.Client
+
+	mu        sync.RWMutex
+	receivers map[string]*receiver // keyed by channel config ID
+
+	pendingMu sync.Mutex
+	pending   map[string]*pendingReply // keyed by pendingKey
+}
+
+// NewWebhookAdapter creates a WebhookAdapter. Callbacks dial through guard,
+// so callback URLs on internal addresses are refused unless it allows them.
+func NewWebhookAdapter(log *slog.Logger, guard *netguard.Guard) *WebhookAdapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &WebhookAdapter{
+		logger:    log.With(slog.String("adapter", "webhook")),
+		client:    &http.Client{Timeout: callbackTimeout, Transport: guard.Transport()},
+		receivers: make(map[string]*receiver),
+		pending:   make(map[string]*pendingReply),
+	}
+}
+
+// Type returns the webhook channel type.
+func (a *WebhookAdapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the webhook channel metadata.
+func (a *WebhookAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Webhook",
+		Capabilities: channel.ChannelCapabilities{
+			Text:      true,
+			Markdown:  true,
+			Reply:     true,
+			Streaming: true,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"secret": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Title:       "Secret",
+					Description: fmt.Sprintf("Shared secret that signs or authorizes requests and signs callbacks (at least %d characters)", minSecretLength),
+				},
+				"authMode": {
+					Type:        channel.FieldEnum,
+					Title:       "Authentication",
+					Description: "signature (default): HMAC headers; token: the secret as ?token= or X-Memoh-Token",
+					Enum:        []string{AuthSignature, AuthToken},
+					Example:     AuthSignature,
+				},
+				"replyMode": {
+					Type:        channel.FieldEnum,
+					Title:       "Reply Mode",
+					Description: "sync (default): replies in the HTTP response; callback: replies POSTed to the callback URL",
+					Enum:        []string{ReplySync, ReplyCallback},
+					Example:     ReplySync,
+				},
+				"callbackUrl": {
+					Type:        channel.FieldString,
+					Title:       "Callback URL",
+					Description: "Receives replies in callback mode and replies sent outside a request; required in callback mode",
+					Example:     "https://example.com/memoh/replies",
+				},
+				"textPath": {
+					Type:        channel.FieldString,
+					Title:       "Text Path",
+					Description: "JSON path of the message text; $ sends the whole body",
+					Example:     defaultTextPath,
+				},
+				"messageIdPath": {
+					Type:    channel.FieldString,
+					Title:   "Message ID Path",
+					Example: defaultMessageIDPath,
+				},
+				"senderIdPath": {
+					Type:    channel.FieldString,
+					Title:   "Sender ID Path",
+					Example: defaultSenderIDPath,
+				},
+				"senderNamePath": {
+					Type:    channel.FieldString,
+					Title:   "Sender Name Path",
+					Example: defaultSenderNamePath,
+				},
+				"conversationIdPath": {
+					Type:    channel.FieldString,
+					Title:   "Conversation ID Path",
+					Example: defaultConversationIDPath,
+				},
+				"conversationTypePath": {
+					Type:        channel.FieldString,
+					Title:       "Conversation Type Path",
+					Description: "private (default) or group",
+					Example:     defaultConversationTypePath,
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"sender_id":       {Type: channel.FieldString},
+				"conversation_id": {Type: channel.FieldString},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "conversation_id",
+			Hints: []channel.TargetHint{
+				{Label: "Conversation ID", Example: "ticket-4711"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a webhook channel configuration map.
+func (a *WebhookAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a webhook user-binding configuration map.
+func (a *WebhookAdapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget normalizes a webhook delivery target string.
+func (a *WebhookAdapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives a delivery target from a webhook user-binding configuration.
+func (a *WebhookAdapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a webhook user binding matches the given criteria.
+func (a *WebhookAdapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs a webhook user-binding config from an Identity.
+func (a *WebhookAdapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Connect routes requests delivered to HandleWebhook for cfg.ID into handler
+// until the connection is stopped.
+func (a *WebhookAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	webhookCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	rcv := &receiver{
+		ctx:        connCtx,
+		cfg:        cfg,
+		webhookCfg: webhookCfg,
+		handler:    handler,
+	}
+	a.mu.Lock()
+	a.receivers[cfg.ID] = rcv
+	a.mu.Unlock()
+	a.logger.Info("webhook registered", slog.String("config_id", cfg.ID), slog.String("reply_mode", webhookCfg.ReplyMode))
+
+	stop := func(_ context.Context) error {
+		a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		a.mu.Lock()
+		if a.receivers[cfg.ID] == rcv {
+			delete(a.receivers, cfg.ID)
+		}
+		a.mu.Unlock()
+		cancel()
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// HandleWebhook authenticates one request for the given channel config and
+// hands the mapped message to the inbound handler. In sync mode it waits for
+// the replies; in callback mode it returns right away.
+func (a *WebhookAdapter) HandleWebhook(ctx context.Context, configID string, req Request) (Response, error) {
+	a.mu.RLock()
+	rcv := a.receivers[configID]
+	a.mu.RUnlock()
+	if rcv == nil {
+		return Response{}, ErrWebhookNotFound
+	}
+	now := time.Now()
+	if err := authenticate(rcv.webhookCfg, req, now); err != nil {
+		return Response{}, err
+	}
+	doc, err := decodePayload(req.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	msg, err := buildInbound(rcv.cfg, rcv.webhookCfg, doc, now)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if rcv.webhookCfg.ReplyMode == ReplyCallback {
+		go a.dispatch(rcv, msg)
+		return Response{MessageID: msg.Message.ID, Replies: []channel.Message{}, Async: true}, nil
+	}
+
+	key := pendingKey(configID, msg.Message.ID)
+	a.pendingMu.Lock()
+	if _, exists := a.pending[key]; exists {
+		a.pendingMu.Unlock()
+		return Response{}, ErrMessageInFlight
+	}
+	a.pending[key] = &pendingReply{}
+	a.pendingMu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- rcv.handler(rcv.ctx, rcv.cfg, msg)
+	}()
+	timer := time.NewTimer(syncReplyTimeout)
+	defer timer.Stop()
+	var handleErr error
+	timedOut := false
+	select {
+	case handleErr = <-done:
+	case <-timer.C:
+		timedOut = true
+	case <-ctx.Done():
+		handleErr = ctx.Err()
+	}
+
+	a.pendingMu.Lock()
+	pending := a.pending[key]
+	delete(a.pending, key)
+	a.pendingMu.Unlock()
+	if handleErr != nil {
+		return Response{}, handleErr
+	}
+	resp := Response{MessageID: msg.Message.ID, Replies: []channel.Message{}, TimedOut: timedOut}
+	if pending != nil {
+		resp.Replies = append(resp.Replies, pending.messages...)
+	}
+	return resp, nil
+}
+
+// dispatch runs the inbound handler of a callback-mode request.
+func (a *WebhookAdapter) dispatch(rcv *receiver, msg channel.InboundMessage) {
+	if err := rcv.handler(rcv.ctx, rcv.cfg, msg); err != nil {
+		a.logger.Error("handle inbound message failed",
+			slog.String("config_id", rcv.cfg.ID),
+			slog.String("message_id", msg.Message.ID),
+			slog.Any("error", err))
+	}
+}
+
+// Send delivers an outbound message to the request it replies to, or to the
+// callback URL.
+func (a *WebhookAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) error {
+	webhookCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if msg.Message.IsEmpty() {
+		return fmt.Errorf("message is required")
+	}
+	replyTo := ""
+	if msg.Message.Reply != nil {
+		replyTo = strings.TrimSpace(msg.Message.Reply.MessageID)
+	}
+	return a.deliver(ctx, cfg, webhookCfg, strings.TrimSpace(msg.Target), replyTo, []channel.Message{msg.Message})
+}
+
+// OpenStream opens a stream that collects the final messages of a reply and
+// delivers them together on Close.
+func (a *WebhookAdapter) OpenStream(ctx context.Context, cfg channel.ChannelConfig, target string, opts channel.StreamOptions) (channel.OutboundStream, error) {
+	webhookCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("webhook target is required")
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	return &outboundStream{
+		adapter:    a,
+		cfg:        cfg,
+		webhookCfg: webhookCfg,
+		target:     target,
+		replyTo:    strings.TrimSpace(opts.SourceMessageID),
+	}, nil
+}
+
+// deliver hands messages to the sync request waiting for replyTo or, when no
+// such request is pending, POSTs them to the callback URL.
+func (a *WebhookAdapter) deliver(ctx context.Context, cfg channel.ChannelConfig, webhookCfg Config, target, replyTo string, messages []channel.Message) error {
+	if replyTo != "" {
+		a.pendingMu.Lock()
+		pending := a.pending[pendingKey(cfg.ID, replyTo)]
+		if pending != nil {
+			pending.messages = append(pending.messages, messages...)
+		}
+		a.pendingMu.Unlock()
+		if pending != nil {
+			return nil
+		}
+	}
+	if webhookCfg.CallbackURL == "" {
+		return fmt.Errorf("webhook channel has no callbackUrl for replies outside a request")
+	}
+	body, err := json.Marshal(callbackPayload{
+		BotID:    cfg.BotID,
+		ConfigID: cfg.ID,
+		Target:   target,
+		ReplyTo:  replyTo,
+		Messages: messages,
+	})
+	if err != nil {
+		return err
+	}
+	return a.postCallback(ctx, webhookCfg, body)
+}
+
+// postCallback POSTs body to the callback URL, signed like outgoing bot
+// webhooks. Any 2xx response counts as delivered.
+func (a *WebhookAdapter) postCallback(ctx context.Context, webhookCfg Config, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookCfg.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(webhooks.HeaderEvent, replyEvent)
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(webhookCfg.Secret, timestamp, body))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook callback: %w", err)
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(snippet))
+		if msg == "" {
+			return fmt.Errorf("webhook callback: unexpected status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("webhook callback: unexpected status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// authenticate checks a request against the configured secret.
+func authenticate(cfg Config, req Request, now time.Time) error {
+	if cfg.AuthMode == AuthToken {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(req.Token)), []byte(cfg.Secret)) != 1 {
+			return ErrWebhookUnauthorized
+		}
+		return nil
+	}
+	if err := webhooks.Verify(cfg.Secret, req.Timestamp, req.Signature, req.Body, signatureTolerance, now); err != nil {
+		return ErrWebhookUnauthorized
+	}
+	return nil
+}
+
+// buildInbound maps a decoded payload onto an inbound message using the
+// configured JSON paths. Missing IDs fall back to each other, then to
+// anonymousSender; a missing message ID is generated.
+func buildInbound(cfg channel.ChannelConfig, webhookCfg Config, doc any, now time.Time) (channel.InboundMessage, error) {
+	text := lookupString(doc, webhookCfg.TextPath)
+	if text == "" {
+		return channel.InboundMessage{}, fmt.Errorf("%w: no text at %q", ErrInvalidPayload, webhookCfg.TextPath)
+	}
+	messageID := lookupString(doc, webhookCfg.MessageIDPath)
+	if messageID == "" {
+		messageID = uuid.NewString()
+	}
+	senderID := lookupString(doc, webhookCfg.SenderIDPath)
+	conversationID := lookupString(doc, webhookCfg.ConversationIDPath)
+	if senderID == "" {
+		senderID = conversationID
+	}
+	if senderID == "" {
+		senderID = anonymousSender
+	}
+	if conversationID == "" {
+		conversationID = senderID
+	}
+	conversationType := strings.ToLower(lookupString(doc, webhookCfg.ConversationTypePath))
+	if conversationType == "" {
+		conversationType = defaultConversationType
+	}
+	return channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:     messageID,
+			Format: channel.MessageFormatPlain,
+			Text:   text,
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: conversationID,
+		Sender: channel.Identity{
+			SubjectID:   senderID,
+			DisplayName: lookupString(doc, webhookCfg.SenderNamePath),
+			Attributes: map[string]string{
+				"sender_id":       senderID,
+				"conversation_id": conversationID,
+			},
+		},
+		Conversation: channel.Conversation{
+			ID:   conversationID,
+			Type: conversationType,
+		},
+		ReceivedAt: now.UTC(),
+		Source:     "webhook",
+		Metadata: map[string]any{
+			// Every request is addressed to the bot, including group ones.
+			"is_mentioned": true,
+		},
+	}, nil
+}
+
+func pendingKey(configID, messageID string) string {
+	return configID + "\x00" + messageID
+}
+
+// outboundStream buffers the final messages of a streamed reply; deltas are
+// dropped because neither sync responses nor callbacks can be updated.
+type outboundStream struct {
+	adapter    *WebhookAdapter
+	cfg        channel.ChannelConfig
+	webhookCfg Config
+	target     string
+	replyTo    string
+
+	mu       sync.Mutex
+	messages []channel.Message
+	closed   bool
+}
+
+func (s *outboundStream) Push(ctx context.Context, event channel.StreamEvent) error {
+	if event.Type != channel.StreamEventFinal || event.Final == nil || event.Final.Message.IsEmpty() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("webhook stream is closed")
+	}
+	s.messages = append(s.messages, event.Final.Message)
+	return nil
+}
+
+func (s *outboundStream) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	messages := s.messages
+	s.mu.Unlock()
+	if len(messages) == 0 {
+		return nil
+	}
+	if err := s.adapter.deliver(ctx, s.cfg, s.webhookCfg, s.target, s.replyTo, messages); err != nil {
+		// The inbound processor ignores Close errors, so report them here.
+		s.adapter.logger.Warn("deliver reply failed",
+			slog.String("config_id", s.cfg.ID),
+			slog.String("target", s.target),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/netguard"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/webhooks"
+)
+
+const testSecret = "0123456789abcdef"
+
+func signedRequest(body string, now time.Time) Request {
+	return Request{
+		Body:      []byte(body),
+		Timestamp: strconv.FormatInt(now.Unix(), 10),
+		Signature: webhooks.Sign(testSecret, now.Unix(), []byte(body)),
+	}
+}
+
+// replyingHandler answers every inbound message through the adapter's stream,
+// the way the inbound processor does.
+func replyingHandler(a *WebhookAdapter, received chan<- channel.InboundMessage) channel.InboundHandler {
+	return func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		stream, err := a.OpenStream(ctx, cfg, msg.ReplyTarget, channel.StreamOptions{SourceMessageID: msg.Message.ID})
+		if err != nil {
+			return err
+		}
+		defer func() { _ = stream.Close(context.WithoutCancel(ctx)) }()
+		_ = stream.Push(ctx, channel.StreamEvent{Type: channel.StreamEventDelta, Delta: "ec"})
+		return stream.Push(ctx, channel.StreamEvent{
+			Type:  channel.StreamEventFinal,
+			Final: &channel.StreamFinalizePayload{Message: channel.Message{Text: "echo: " + msg.Message.Text}},
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := parseConfig(map[string]any{"secret": testSecret})
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if cfg.AuthMode != AuthSignature || cfg.ReplyMode != ReplySync || cfg.TextPath != defaultTextPath || cfg.SenderIDPath != defaultSenderIDPath {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+	cases := []map[string]any{
+		{"secret": "short"},
+		{"secret": testSecret, "authMode": "basic"},
+		{"secret": testSecret, "replyMode": "callback"},
+		{"secret": testSecret, "callbackUrl": "ftp://example.com"},
+	}
+	for _, raw := range cases {
+		if _, err := parseConfig(raw); err == nil {
+			t.Fatalf("expected error for %v", raw)
+		}
+	}
+	normalized, err := normalizeConfig(map[string]any{
+		"secret":       testSecret,
+		"reply_mode":   "Callback",
+		"callback_url": "https://example.com/hook",
+		"text_path":    "data.body",
+	})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if normalized["replyMode"] != ReplyCallback || normalized["callbackUrl"] != "https://example.com/hook" || normalized["textPath"] != "data.body" {
+		t.Fatalf("unexpected normalized config: %v", normalized)
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	t.Parallel()
+
+	doc, err := decodePayload([]byte(`{"data":{"items":[{"text":"first"},{"id":12345678901234567890}]},"ok":true}`))
+	if err != nil {
+		t.Fatalf("decodePayload: %v", err)
+	}
+	cases := map[string]string{
+		"data.items[0].text":  "first",
+		"$.data.items.0.text": "first",
+		"data.items[1].id":    "12345678901234567890",
+		"ok":                  "true",
+		"data.items[2].text":  "",
+		"data.missing":        "",
+		"data.items[1]":       `{"id":12345678901234567890}`,
+	}
+	for path, want := range cases {
+		if got := lookupString(doc, path); got != want {
+			t.Errorf("lookupString(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestHandleWebhookSync(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewWebhookAdapter(nil, nil)
+	received := make(chan channel.InboundMessage, 1)
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", Credentials: map[string]any{
+		"secret":             testSecret,
+		"textPath":           "message.body",
+		"senderIdPath":       "user.id",
+		"senderNamePath":     "user.name",
+		"conversationIdPath": "ticket",
+	}}
+	if _, err := adapter.Connect(context.Background(), cfg, replyingHandler(adapter, received)); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	body := `{"id":"m-1","message":{"body":"hello"},"user":{"id":42,"name":"Alice"},"ticket":"T-7"}`
+	now := time.Now()
+
+	if _, err := adapter.HandleWebhook(context.Background(), "cfg-2", signedRequest(body, now)); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("expected ErrWebhookNotFound, got %v", err)
+	}
+	stale := signedRequest(body, now.Add(-time.Hour))
+	if _, err := adapter.HandleWebhook(context.Background(), "cfg-1", stale); !errors.Is(err, ErrWebhookUnauthorized) {
+		t.Fatalf("expected ErrWebhookUnauthorized for a stale signature, got %v", err)
+	}
+	if _, err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest(`{"text":"x"}`, now)); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected ErrInvalidPayload, got %v", err)
+	}
+
+	resp, err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest(body, now))
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	msg := <-received
+	if msg.Message.Text != "hello" || msg.Sender.SubjectID != "42" || msg.Sender.DisplayName != "Alice" || msg.ReplyTarget != "T-7" || msg.BotID != "bot-1" {
+		t.Fatalf("unexpected inbound message: %#v", msg)
+	}
+	if resp.MessageID != "m-1" || resp.Async || resp.TimedOut || len(resp.Replies) != 1 || resp.Replies[0].Text != "echo: hello" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if len(adapter.pending) != 0 {
+		t.Fatalf("pending replies not cleaned up: %v", adapter.pending)
+	}
+}
+
+func TestHandleWebhookCallback(t *testing.T) {
+	t.Parallel()
+
+	callbacks := make(chan callbackPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhooks.Verify(testSecret, r.Header.Get(webhooks.HeaderTimestamp), r.Header.Get(webhooks.HeaderSignature), body, time.Minute, time.Now())
+		if err != nil {
+			t.Errorf("callback signature: %v", err)
+		}
+		var payload callbackPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("decode callback: %v", err)
+		}
+		callbacks <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	blocked := NewWebhookAdapter(nil, nil)
+	if err := blocked.postCallback(context.Background(), Config{Secret: testSecret, CallbackURL: server.URL}, []byte(`{}`)); !errors.Is(err, netguard.ErrBlockedAddress) {
+		t.Fatalf("expected loopback callback to be refused, got %v", err)
+	}
+
+	guard, err := netguard.New([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adapter := NewWebhookAdapter(nil, guard)
+	received := make(chan channel.InboundMessage, 1)
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", Credentials: map[string]any{
+		"secret":      testSecret,
+		"authMode":    AuthToken,
+		"replyMode":   ReplyCallback,
+		"callbackUrl": server.URL,
+	}}
+	conn, err := adapter.Connect(context.Background(), cfg, replyingHandler(adapter, received))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	body := []byte(`{"text":"ping"}`)
+	if _, err := adapter.HandleWebhook(context.Background(), "cfg-1", Request{Body: body, Token: "wrong"}); !errors.Is(err, ErrWebhookUnauthorized) {
+		t.Fatalf("expected ErrWebhookUnauthorized, got %v", err)
+	}
+	resp, err := adapter.HandleWebhook(context.Background(), "cfg-1", Request{Body: body, Token: testSecret})
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if !resp.Async || resp.MessageID == "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	select {
+	case payload := <-callbacks:
+		if payload.BotID != "bot-1" || payload.Target != anonymousSender || payload.ReplyTo != resp.MessageID ||
+			len(payload.Messages) != 1 || payload.Messages[0].Text != "echo: ping" {
+			t.Fatalf("unexpected callback: %+v", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback was not delivered")
+	}
+
+	if err := conn.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if _, err := adapter.HandleWebhook(context.Background(), "cfg-1", Request{Body: body, Token: testSecret}); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("expected ErrWebhookNotFound after stop, got %v", err)
+	}
+}
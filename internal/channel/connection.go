@@ -6,11 +6,21 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+)
+
+// Reasons reported in channel_connection_lost events.
+const (
+	lossReasonConnectFailed = "connect_failed"
+	lossReasonDisconnected  = "disconnected"
 )
 
 type connectionEntry struct {
 	config     ChannelConfig
 	connection Connection
+	// lostReported is set once the current loss of connection was published.
+	lostReported bool
 }
 
 func (m *Manager) refresh(ctx context.Context) {
@@ -49,15 +59,31 @@ func (m *Manager) reconcile(ctx context.Context, configs []ChannelConfig) {
 			continue
 		}
 		active[cfg.ID] = cfg
-		if err := m.ensureConnection(ctx, cfg); err != nil {
-			if m.logger != nil {
-				m.logger.Error("adapter start failed", slog.String("channel", cfg.ChannelType.String()), slog.String("config_id", cfg.ID), slog.Any("error", err))
-			}
+		err := m.ensureConnection(ctx, cfg)
+		if err != nil && m.logger != nil {
+			m.logger.Error("adapter start failed", slog.String("channel", cfg.ChannelType.String()), slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		m.mu.Lock()
+		firstFailure := err != nil && !m.connectFailed[cfg.ID]
+		if err != nil {
+			m.connectFailed[cfg.ID] = true
+		} else {
+			delete(m.connectFailed, cfg.ID)
+		}
+		m.mu.Unlock()
+		if firstFailure {
+			m.publishLoss(cfg, lossReasonConnectFailed, err)
 		}
 	}
+	m.reportLostConnections()
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	for id := range m.connectFailed {
+		if _, ok := active[id]; !ok {
+			delete(m.connectFailed, id)
+		}
+	}
 	for id, entry := range m.connections {
 		if _, ok := active[id]; ok {
 			continue
@@ -74,6 +100,57 @@ func (m *Manager) reconcile(ctx context.Context, configs []ChannelConfig) {
 	}
 }
 
+// reportLostConnections publishes connections that reported a dropped link
+// since the last refresh. Each loss is published once; a recovery re-arms it.
+func (m *Manager) reportLostConnections() {
+	type loss struct {
+		cfg ChannelConfig
+		err error
+	}
+	var lost []loss
+	m.mu.Lock()
+	for _, entry := range m.connections {
+		if entry == nil || entry.connection == nil {
+			continue
+		}
+		reporter, ok := entry.connection.(LossReporter)
+		if !ok {
+			continue
+		}
+		err := reporter.LostErr()
+		if err == nil {
+			entry.lostReported = false
+			continue
+		}
+		if !entry.lostReported {
+			entry.lostReported = true
+			lost = append(lost, loss{cfg: entry.config, err: err})
+		}
+	}
+	m.mu.Unlock()
+	for _, l := range lost {
+		if m.logger != nil {
+			m.logger.Warn("adapter connection lost", slog.String("channel", l.cfg.ChannelType.String()), slog.String("config_id", l.cfg.ID), slog.Any("error", l.err))
+		}
+		m.publishLoss(l.cfg, lossReasonDisconnected, l.err)
+	}
+}
+
+func (m *Manager) publishLoss(cfg ChannelConfig, reason string, err error) {
+	if m.events == nil || cfg.BotID == "" {
+		return
+	}
+	data := map[string]any{
+		"config_id":    cfg.ID,
+		"channel_type": cfg.ChannelType.String(),
+		"reason":       reason,
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	m.events.Publish(event.NewEvent(event.EventTypeChannelConnectionLost, cfg.BotID, data))
+}
+
 func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error {
 	_, ok := m.registry.GetReceiver(cfg.ChannelType)
 	if !ok {
@@ -4,6 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
 )
 
 type inboundTask struct {
@@ -33,6 +38,7 @@ func (m *Manager) HandleInbound(ctx context.Context, cfg ChannelConfig, msg Inbo
 	case m.inboundQueue <- task:
 		return nil
 	default:
+		inboundMessages.Inc(msg.Channel.String(), "queue_full")
 		return fmt.Errorf("inbound queue full")
 	}
 }
@@ -42,7 +48,16 @@ func (m *Manager) handleInbound(ctx context.Context, cfg ChannelConfig, msg Inbo
 		return fmt.Errorf("inbound processor not configured")
 	}
 	sender := m.newReplySender(cfg, msg.Channel)
-	if err := m.processor.HandleInbound(ctx, cfg, msg, sender); err != nil {
+	ctx, span := tracing.Start(ctx, "channel.inbound",
+		attribute.String("channel.type", msg.Channel.String()),
+		attribute.String("bot.id", cfg.BotID),
+	)
+	start := time.Now()
+	err := m.processor.HandleInbound(ctx, cfg, msg, sender)
+	inboundDuration.ObserveSince(start, msg.Channel.String())
+	tracing.End(span, err)
+	inboundMessages.Inc(msg.Channel.String(), resultLabel(err))
+	if err != nil {
 		if m.logger != nil {
 			m.logger.Error("inbound processing failed", slog.String("channel", msg.Channel.String()), slog.Any("error", err))
 		}
@@ -18,12 +18,17 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation/flow"
 	messagepkg "github.com/Kxiandaoyan/Memoh-v2/internal/message"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/transcription"
 )
 
 const (
 	silentReplyToken        = "NO_REPLY"
 	minDuplicateTextLength  = 10
 	processingStatusTimeout = 60 * time.Second
+
+	// Attachment metadata keys set by the transcription stage.
+	metadataTranscript         = "transcript"
+	metadataTranscriptLanguage = "transcript_language"
 )
 
 var (
@@ -45,6 +50,11 @@ type RouteLister interface {
 	List(ctx context.Context, conversationID string) ([]route.Route, error)
 }
 
+// Transcriber converts voice and audio attachments to text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, botID string, audio transcription.Audio) (transcription.Result, error)
+}
+
 // ChannelInboundProcessor routes channel inbound messages to the chat gateway.
 type ChannelInboundProcessor struct {
 	runner         flow.Runner
@@ -59,6 +69,7 @@ type ChannelInboundProcessor struct {
 	groupDebouncer *messagepkg.GroupDebouncer
 	broadcaster    Broadcaster
 	routeLister    RouteLister
+	transcriber    Transcriber
 }
 
 // NewChannelInboundProcessor creates a processor with channel identity-based resolution.
@@ -110,6 +121,12 @@ func (p *ChannelInboundProcessor) SetBroadcaster(b Broadcaster, rl RouteLister)
 	p.routeLister = rl
 }
 
+// SetTranscriber enables transcription of inbound voice and audio
+// attachments. Pass nil to disable.
+func (p *ChannelInboundProcessor) SetTranscriber(t Transcriber) {
+	p.transcriber = t
+}
+
 func (p *ChannelInboundProcessor) IdentityMiddleware() channel.Middleware {
 	if p == nil || p.identity == nil {
 		return nil
@@ -126,6 +143,9 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		return fmt.Errorf("reply sender not configured")
 	}
 	text := buildInboundQuery(msg.Message)
+	if msg.IsAction() {
+		text = buildActionQuery(msg)
+	}
 	if strings.TrimSpace(text) == "" {
 		return nil
 	}
@@ -199,6 +219,11 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		p.persistInboundUser(ctx, resolved.RouteID, identity, msg, text, "passive_sync")
 		return nil
 	}
+	// Transcribe only messages the bot answers, so untriggered group chatter
+	// does not reach the speech-to-text provider.
+	if !msg.IsAction() && p.transcribeAttachments(ctx, identity.BotID, &msg) {
+		text = buildInboundQuery(msg.Message)
+	}
 	userMessagePersisted := p.persistInboundUser(ctx, resolved.RouteID, identity, msg, text, "active_chat")
 
 	// For group conversations with the debouncer enabled, buffer the message text
@@ -623,6 +648,12 @@ func (p *ChannelInboundProcessor) persistInboundUser(ctx context.Context, routeI
 		"platform":     msg.Channel.String(),
 		"trigger_mode": strings.TrimSpace(triggerMode),
 	}
+	if transcript, language := collectTranscripts(msg.Message.Attachments); transcript != "" {
+		meta[metadataTranscript] = transcript
+		if language != "" {
+			meta[metadataTranscriptLanguage] = language
+		}
+	}
 	if _, err := p.message.Persist(ctx, messagepkg.PersistInput{
 		BotID:                   botID,
 		RouteID:                 strings.TrimSpace(routeID),
@@ -837,10 +868,111 @@ func buildInboundQuery(message channel.Message) string {
 			label = "unknown"
 		}
 		lines = append(lines, fmt.Sprintf("[attachment:%s] %s", att.Type, label))
+		if transcript := attachmentTranscript(att); transcript != "" {
+			lines = append(lines, fmt.Sprintf("[%s transcript] %s", att.Type, transcript))
+		}
 	}
 	return strings.Join(lines, "\n")
 }
 
+// transcribeAttachments transcribes the voice and audio attachments of msg in
+// place and reports whether any transcript was added. Failures are logged and
+// leave the attachment as is, so the agent still sees the reference.
+func (p *ChannelInboundProcessor) transcribeAttachments(ctx context.Context, botID string, msg *channel.InboundMessage) bool {
+	if p.transcriber == nil || len(msg.Message.Attachments) == 0 {
+		return false
+	}
+	var attachments []channel.Attachment
+	for i, att := range msg.Message.Attachments {
+		if att.Type != channel.AttachmentVoice && att.Type != channel.AttachmentAudio {
+			continue
+		}
+		if attachmentTranscript(att) != "" {
+			continue
+		}
+		if len(att.Data) == 0 && strings.TrimSpace(att.URL) == "" {
+			continue
+		}
+		result, err := p.transcriber.Transcribe(ctx, botID, transcription.Audio{
+			Data: att.Data,
+			URL:  att.URL,
+			Name: att.Name,
+			Mime: att.Mime,
+		})
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn(
+					"transcribe attachment failed",
+					slog.String("bot_id", strings.TrimSpace(botID)),
+					slog.String("channel", msg.Channel.String()),
+					slog.String("type", string(att.Type)),
+					slog.Any("error", err),
+				)
+			}
+			continue
+		}
+		if strings.TrimSpace(result.Text) == "" {
+			continue
+		}
+		if attachments == nil {
+			// Copy so the caller's message is left untouched.
+			attachments = append([]channel.Attachment(nil), msg.Message.Attachments...)
+		}
+		metadata := make(map[string]any, len(att.Metadata)+2)
+		for k, v := range att.Metadata {
+			metadata[k] = v
+		}
+		metadata[metadataTranscript] = strings.TrimSpace(result.Text)
+		if language := strings.TrimSpace(result.Language); language != "" {
+			metadata[metadataTranscriptLanguage] = language
+		}
+		attachments[i].Metadata = metadata
+	}
+	if attachments == nil {
+		return false
+	}
+	msg.Message.Attachments = attachments
+	return true
+}
+
+func attachmentTranscript(att channel.Attachment) string {
+	transcript, _ := att.Metadata[metadataTranscript].(string)
+	return strings.TrimSpace(transcript)
+}
+
+// collectTranscripts joins the transcripts of all attachments and returns the
+// first detected language.
+func collectTranscripts(attachments []channel.Attachment) (string, string) {
+	var transcripts []string
+	language := ""
+	for _, att := range attachments {
+		transcript := attachmentTranscript(att)
+		if transcript == "" {
+			continue
+		}
+		transcripts = append(transcripts, transcript)
+		if language == "" {
+			language, _ = att.Metadata[metadataTranscriptLanguage].(string)
+		}
+	}
+	return strings.Join(transcripts, "\n"), strings.TrimSpace(language)
+}
+
+// buildActionQuery renders a button press as the user turn the agent sees,
+// keeping the value verbatim so the agent can match it to the buttons it sent.
+func buildActionQuery(msg channel.InboundMessage) string {
+	action := msg.Action
+	value := strings.TrimSpace(action.Value)
+	if value == "" {
+		return ""
+	}
+	label := strings.TrimSpace(action.Label)
+	if label == "" || label == value {
+		return fmt.Sprintf("[button pressed] %s", value)
+	}
+	return fmt.Sprintf("[button pressed] %s (value: %s)", label, value)
+}
+
 func normalizeContentPartType(raw string) channel.MessagePartType {
 	switch strings.TrimSpace(strings.ToLower(raw)) {
 	case "link":
@@ -14,6 +14,7 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
 	messagepkg "github.com/Kxiandaoyan/Memoh-v2/internal/message"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/schedule"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/transcription"
 )
 
 type fakeChatGateway struct {
@@ -57,8 +58,8 @@ func (f *fakeChatGateway) StreamChat(ctx context.Context, req conversation.ChatR
 	return chunks, errs
 }
 
-func (f *fakeChatGateway) TriggerSchedule(ctx context.Context, botID string, payload schedule.TriggerPayload, token string) error {
-	return nil
+func (f *fakeChatGateway) TriggerSchedule(ctx context.Context, botID string, payload schedule.TriggerPayload, token string) (schedule.TriggerResult, error) {
+	return schedule.TriggerResult{}, nil
 }
 
 type fakeReplySender struct {
@@ -773,3 +774,108 @@ func TestBroadcastSkipsSamePlatform(t *testing.T) {
 		t.Error("expected slack route to receive broadcast")
 	}
 }
+
+func TestBuildActionQuery(t *testing.T) {
+	t.Parallel()
+
+	msg := channel.InboundMessage{
+		Kind:   channel.InboundKindAction,
+		Action: &channel.Action{Type: channel.ActionTypeButton, Label: "Approve", Value: "expense:42:approve"},
+	}
+	if got := buildActionQuery(msg); got != "[button pressed] Approve (value: expense:42:approve)" {
+		t.Fatalf("unexpected query: %q", got)
+	}
+	msg.Action.Label = ""
+	if got := buildActionQuery(msg); got != "[button pressed] expense:42:approve" {
+		t.Fatalf("unexpected query without label: %q", got)
+	}
+}
+
+type fakeTranscriber struct {
+	result transcription.Result
+	err    error
+	got    []transcription.Audio
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, botID string, audio transcription.Audio) (transcription.Result, error) {
+	f.got = append(f.got, audio)
+	return f.result, f.err
+}
+
+func TestTranscribeAttachments(t *testing.T) {
+	t.Parallel()
+
+	transcriber := &fakeTranscriber{result: transcription.Result{Text: "see you at noon", Language: "english"}}
+	processor := &ChannelInboundProcessor{transcriber: transcriber, logger: slog.Default()}
+	original := []channel.Attachment{
+		{Type: channel.AttachmentImage, URL: "https://example.com/a.png"},
+		{Type: channel.AttachmentVoice, URL: "https://example.com/v.ogg", Mime: "audio/ogg", DurationMs: 2000},
+	}
+	msg := channel.InboundMessage{Message: channel.Message{Attachments: original}}
+	if !processor.transcribeAttachments(context.Background(), "bot-1", &msg) {
+		t.Fatal("expected a transcript to be added")
+	}
+	if len(transcriber.got) != 1 || transcriber.got[0].URL != "https://example.com/v.ogg" {
+		t.Fatalf("expected only the voice attachment to be transcribed, got %+v", transcriber.got)
+	}
+	if original[1].Metadata != nil {
+		t.Fatal("expected the caller's attachments to be left untouched")
+	}
+	want := "[attachment:image] https://example.com/a.png\n" +
+		"[attachment:voice] https://example.com/v.ogg\n" +
+		"[voice transcript] see you at noon"
+	if got := buildInboundQuery(msg.Message); got != want {
+		t.Fatalf("unexpected query: %q", got)
+	}
+	transcript, language := collectTranscripts(msg.Message.Attachments)
+	if transcript != "see you at noon" || language != "english" {
+		t.Fatalf("unexpected transcript metadata: %q %q", transcript, language)
+	}
+}
+
+func TestTranscribeAttachmentsFailureKeepsAttachment(t *testing.T) {
+	t.Parallel()
+
+	processor := &ChannelInboundProcessor{transcriber: &fakeTranscriber{err: errors.New("boom")}, logger: slog.Default()}
+	msg := channel.InboundMessage{Message: channel.Message{Attachments: []channel.Attachment{
+		{Type: channel.AttachmentAudio, URL: "https://example.com/a.mp3"},
+	}}}
+	if processor.transcribeAttachments(context.Background(), "bot-1", &msg) {
+		t.Fatal("expected no transcript on failure")
+	}
+	if got := buildInboundQuery(msg.Message); got != "[attachment:audio] https://example.com/a.mp3" {
+		t.Fatalf("unexpected query: %q", got)
+	}
+}
+
+func TestChannelInboundProcessorGroupPassiveSyncSkipsTranscription(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-5"}}
+	memberSvc := &fakeMemberService{isMember: true}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{ChatID: "chat-5", RouteID: "route-5"}}
+	gateway := &fakeChatGateway{}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, memberSvc, nil, nil, nil, "", 0)
+	transcriber := &fakeTranscriber{result: transcription.Result{Text: "see you at noon"}}
+	processor.SetTranscriber(transcriber)
+
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1"}
+	msg := channel.InboundMessage{
+		BotID:   "bot-1",
+		Channel: channel.ChannelType("feishu"),
+		Message: channel.Message{ID: "msg-1", Attachments: []channel.Attachment{
+			{Type: channel.AttachmentVoice, URL: "https://example.com/v.ogg"},
+		}},
+		ReplyTarget:  "chat_id:oc_123",
+		Sender:       channel.Identity{SubjectID: "user-1"},
+		Conversation: channel.Conversation{ID: "oc_123", Type: "group"},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, &fakeReplySender{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transcriber.got) != 0 {
+		t.Fatalf("untriggered group message should not be transcribed, got %+v", transcriber.got)
+	}
+	if len(chatSvc.persisted) != 1 {
+		t.Fatalf("expected 1 passive persisted message, got: %d", len(chatSvc.persisted))
+	}
+}
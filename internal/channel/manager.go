@@ -8,6 +8,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 )
 
 // ConfigLister lists channel configs for periodic refresh. Used by connection lifecycle.
@@ -63,6 +65,10 @@ type Manager struct {
 	mu             sync.Mutex
 	refreshMu      sync.Mutex
 	connections    map[string]*connectionEntry
+	// connectFailed holds the configs whose last connect attempt failed, so
+	// a failure streak is published only once.
+	connectFailed map[string]bool
+	events        event.Publisher
 }
 
 // NewManager creates a Manager with the given logger, registry, config store, and inbound processor.
@@ -79,6 +85,7 @@ func NewManager(log *slog.Logger, registry *Registry, service ManagerStore, proc
 		processor:       processor,
 		refreshInterval: 30 * time.Second,
 		connections:     map[string]*connectionEntry{},
+		connectFailed:   map[string]bool{},
 		logger:          log.With(slog.String("component", "channel")),
 		middlewares:     []Middleware{},
 		inboundQueue:    make(chan inboundTask, 256),
@@ -91,6 +98,11 @@ func (m *Manager) Registry() *Registry {
 	return m.registry
 }
 
+// SetEventPublisher sets the hub that receives channel_connection_lost events.
+func (m *Manager) SetEventPublisher(p event.Publisher) {
+	m.events = p
+}
+
 // Use appends middleware to the inbound processing chain.
 func (m *Manager) Use(mw ...Middleware) {
 	m.middlewares = append(m.middlewares, mw...)
@@ -9,6 +9,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 )
 
 type fakeConfigStore struct {
@@ -239,3 +241,61 @@ func TestManagerReconcileStartsAndStops(t *testing.T) {
 		t.Fatalf("expected 1 stop, got %d", adapter.stops)
 	}
 }
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(evt event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, evt)
+}
+
+func (p *recordingPublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.events)
+}
+
+func TestManagerReportsLostConnectionOnce(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	reg := NewRegistry()
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	manager := NewManager(log, reg, &fakeConfigStore{}, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	events := &recordingPublisher{}
+	manager.SetEventPublisher(events)
+
+	cfg := ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		UpdatedAt:   time.Now(),
+	}
+	ctx := context.Background()
+	manager.reconcile(ctx, []ChannelConfig{cfg})
+	conn := manager.connections["cfg-1"].connection.(*BaseConnection)
+
+	conn.MarkLost(fmt.Errorf("gateway closed"))
+	manager.reconcile(ctx, []ChannelConfig{cfg})
+	manager.reconcile(ctx, []ChannelConfig{cfg})
+	if events.count() != 1 {
+		t.Fatalf("expected 1 event, got %d", events.count())
+	}
+	evt := events.events[0]
+	if evt.Type != event.EventTypeChannelConnectionLost || evt.BotID != "bot-1" || !strings.Contains(string(evt.Data), "gateway closed") {
+		t.Fatalf("unexpected event: %+v %s", evt, evt.Data)
+	}
+
+	conn.MarkRecovered()
+	manager.reconcile(ctx, []ChannelConfig{cfg})
+	conn.MarkLost(nil)
+	manager.reconcile(ctx, []ChannelConfig{cfg})
+	if events.count() != 2 {
+		t.Fatalf("expected a second event after recovery, got %d", events.count())
+	}
+}
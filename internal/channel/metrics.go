@@ -0,0 +1,19 @@
+package channel
+
+import "github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+
+var (
+	inboundMessages = metrics.NewCounter("memoh_channel_inbound_messages_total",
+		"Inbound channel messages by adapter and result (ok, error, queue_full).", "channel", "result")
+	inboundDuration = metrics.NewHistogram("memoh_channel_inbound_duration_seconds",
+		"Time spent processing an inbound channel message.", nil, "channel")
+	outboundMessages = metrics.NewCounter("memoh_channel_outbound_messages_total",
+		"Outbound channel deliveries by adapter and result (ok, error).", "channel", "result")
+)
+
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -6,6 +6,10 @@ import (
 	"log/slog"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
 )
 
 // ChunkerMode selects the text chunking strategy.
@@ -298,6 +302,11 @@ func validateMessageCapabilities(registry *Registry, channelType ChannelType, ms
 	if len(msg.Actions) > 0 && !caps.Buttons {
 		return fmt.Errorf("channel does not support actions")
 	}
+	for _, action := range msg.Actions {
+		if err := action.Validate(); err != nil {
+			return err
+		}
+	}
 	if msg.Thread != nil && !caps.Threads {
 		return fmt.Errorf("channel does not support threads")
 	}
@@ -310,7 +319,15 @@ func validateMessageCapabilities(registry *Registry, channelType ChannelType, ms
 	return nil
 }
 
-func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg ChannelConfig, msg OutboundMessage, policy OutboundPolicy) error {
+func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg ChannelConfig, msg OutboundMessage, policy OutboundPolicy) (err error) {
+	ctx, span := tracing.Start(ctx, "channel.outbound",
+		attribute.String("channel.type", cfg.ChannelType.String()),
+		attribute.String("bot.id", cfg.BotID),
+	)
+	defer func() {
+		outboundMessages.Inc(cfg.ChannelType.String(), resultLabel(err))
+		tracing.End(span, err)
+	}()
 	if sender == nil {
 		return fmt.Errorf("unsupported channel type: %s", cfg.ChannelType)
 	}
@@ -0,0 +1,30 @@
+package channel
+
+import "testing"
+
+func TestValidateMessageCapabilitiesActions(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.MustRegister(&buttonAdapter{})
+	msg := Message{Text: "approve?", Actions: []Action{{Type: ActionTypeButton, Label: "Approve", Value: "approve"}}}
+	if err := validateMessageCapabilities(registry, "buttons-test", msg); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	msg.Actions = append(msg.Actions, Action{Label: "Broken"})
+	if err := validateMessageCapabilities(registry, "buttons-test", msg); err == nil {
+		t.Fatal("expected error for action without value or url")
+	}
+}
+
+type buttonAdapter struct{}
+
+func (a *buttonAdapter) Type() ChannelType { return "buttons-test" }
+
+func (a *buttonAdapter) Descriptor() Descriptor {
+	return Descriptor{
+		Type:         "buttons-test",
+		DisplayName:  "Buttons",
+		Capabilities: ChannelCapabilities{Text: true, Buttons: true},
+	}
+}
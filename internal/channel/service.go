@@ -13,6 +13,7 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
 )
 
 // Service provides CRUD operations for channel configurations, user bindings, and sessions.
@@ -45,6 +46,10 @@ func (s *Service) UpsertConfig(ctx context.Context, botID string, channelType Ch
 	if err != nil {
 		return ChannelConfig{}, err
 	}
+	credentialsPayload, err = secrets.SealJSON(credentialsPayload)
+	if err != nil {
+		return ChannelConfig{}, fmt.Errorf("encrypt channel credentials: %w", err)
+	}
 	botUUID, err := db.ParseUUID(botID)
 	if err != nil {
 		return ChannelConfig{}, err
@@ -282,7 +287,11 @@ func (s *Service) ResolveChannelIdentityBinding(ctx context.Context, channelType
 }
 
 func normalizeChannelConfig(row sqlc.BotChannelConfig) (ChannelConfig, error) {
-	credentials, err := DecodeConfigMap(row.Credentials)
+	credentialsPayload, err := secrets.OpenJSON(row.Credentials)
+	if err != nil {
+		return ChannelConfig{}, fmt.Errorf("decrypt channel credentials: %w", err)
+	}
+	credentials, err := DecodeConfigMap(credentialsPayload)
 	if err != nil {
 		return ChannelConfig{}, err
 	}
@@ -3,6 +3,7 @@
 package channel
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -39,10 +40,23 @@ type Conversation struct {
 	Metadata map[string]any
 }
 
+// InboundKind classifies what an inbound message carries.
+type InboundKind string
+
+const (
+	// InboundKindMessage is a regular user message. An empty kind means the same.
+	InboundKindMessage InboundKind = "message"
+	// InboundKindAction is a press on a button the bot sent earlier; Action
+	// carries the pressed button and Message.Reply the message it was on.
+	InboundKindAction InboundKind = "action"
+)
+
 // InboundMessage is a message received from an external channel.
 type InboundMessage struct {
 	Channel      ChannelType
+	Kind         InboundKind
 	Message      Message
+	Action       *Action
 	BotID        string
 	ReplyTarget  string
 	RouteKey     string
@@ -53,6 +67,11 @@ type InboundMessage struct {
 	Metadata     map[string]any
 }
 
+// IsAction reports whether the message is a button press.
+func (m InboundMessage) IsAction() bool {
+	return m.Kind == InboundKindAction && m.Action != nil
+}
+
 // RoutingKey returns a stable identifier used for reply routing.
 // Format: platform:bot_id:conversation_id[:sender_id].
 func (m InboundMessage) RoutingKey() string {
@@ -213,7 +232,14 @@ func (a Attachment) HasReference() bool {
 	return a.Reference() != ""
 }
 
-// Action describes an interactive button or link in a message.
+// Action types. A button with a URL opens the link instead of calling back.
+const (
+	ActionTypeButton = "button"
+	ActionTypeLink   = "link"
+)
+
+// Action describes an interactive button or link in a message. Value is sent
+// back in an InboundKindAction message when a button is pressed.
 type Action struct {
 	Type  string `json:"type"`
 	Label string `json:"label,omitempty"`
@@ -221,6 +247,30 @@ type Action struct {
 	URL   string `json:"url,omitempty"`
 }
 
+// IsLink reports whether pressing the action opens its URL rather than
+// calling back into the bot.
+func (a Action) IsLink() bool {
+	return a.Type == ActionTypeLink || (strings.TrimSpace(a.URL) != "" && strings.TrimSpace(a.Value) == "")
+}
+
+// Validate checks that the action can be rendered: it needs a label and
+// either a callback value or a URL.
+func (a Action) Validate() error {
+	if strings.TrimSpace(a.Label) == "" {
+		return fmt.Errorf("action label is required")
+	}
+	if a.IsLink() {
+		if strings.TrimSpace(a.URL) == "" {
+			return fmt.Errorf("link action %q requires url", a.Label)
+		}
+		return nil
+	}
+	if strings.TrimSpace(a.Value) == "" {
+		return fmt.Errorf("button action %q requires value", a.Label)
+	}
+	return nil
+}
+
 // ThreadRef references a conversation thread by ID.
 type ThreadRef struct {
 	ID string `json:"id"`
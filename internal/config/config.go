@@ -9,21 +9,22 @@ import (
 )
 
 const (
-	DefaultConfigPath       = "config.toml"
-	DefaultHTTPAddr         = ":8080"
-	DefaultNamespace        = "default"
-	DefaultSocketPath       = "/run/containerd/containerd.sock"
-	DefaultMCPImage         = "docker.io/library/memoh-mcp:latest"
-	DefaultDataRoot         = "data"
-	DefaultDataMount        = "/data"
-	DefaultJWTExpiresIn     = "24h"
-	DefaultPGHost           = "127.0.0.1"
-	DefaultPGPort           = 5432
-	DefaultPGUser           = "postgres"
-	DefaultPGDatabase       = "memoh"
-	DefaultPGSSLMode        = "disable"
-	DefaultQdrantURL        = "http://127.0.0.1:6334"
-	DefaultQdrantCollection = "memory"
+	DefaultConfigPath        = "config.toml"
+	DefaultHTTPAddr          = ":8080"
+	DefaultNamespace         = "default"
+	DefaultSocketPath        = "/run/containerd/containerd.sock"
+	DefaultMCPImage          = "docker.io/library/memoh-mcp:latest"
+	DefaultDataRoot          = "data"
+	DefaultDataMount         = "/data"
+	DefaultJWTExpiresIn      = "24h"
+	DefaultPGHost            = "127.0.0.1"
+	DefaultPGPort            = 5432
+	DefaultPGUser            = "postgres"
+	DefaultPGDatabase        = "memoh"
+	DefaultPGSSLMode         = "disable"
+	DefaultQdrantURL         = "http://127.0.0.1:6334"
+	DefaultQdrantCollection  = "memory"
+	DefaultMemoryVectorStore = "qdrant"
 )
 
 type Config struct {
@@ -35,8 +36,12 @@ type Config struct {
 	MCP          MCPConfig          `toml:"mcp"`
 	Postgres     PostgresConfig     `toml:"postgres"`
 	Qdrant       QdrantConfig       `toml:"qdrant"`
+	Memory       MemoryConfig       `toml:"memory"`
 	AgentGateway AgentGatewayConfig `toml:"agent_gateway"`
 	Smithery     SmitheryConfig     `toml:"smithery"`
+	Tracing      TracingConfig      `toml:"tracing"`
+	Secrets      SecretsConfig      `toml:"secrets"`
+	Webhooks     WebhooksConfig     `toml:"webhooks"`
 }
 
 type LogConfig struct {
@@ -47,6 +52,42 @@ type LogConfig struct {
 type ServerConfig struct {
 	Addr     string `toml:"addr"`
 	Timezone string `toml:"timezone"`
+	// MetricsToken, when set, is required as a bearer token on /metrics.
+	// Without it, /metrics needs a user login or API token.
+	MetricsToken string `toml:"metrics_token"`
+}
+
+// TracingConfig controls OpenTelemetry trace export over OTLP/HTTP.
+type TracingConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Endpoint is host:port or a full URL of the OTLP/HTTP collector. Empty
+	// uses the OTEL_EXPORTER_OTLP_* environment variables.
+	Endpoint    string  `toml:"endpoint"`
+	Insecure    bool    `toml:"insecure"`
+	ServiceName string  `toml:"service_name"`
+	SampleRatio float64 `toml:"sample_ratio"`
+}
+
+// WebhooksConfig controls outbound HTTP to user-supplied URLs: webhook
+// deliveries and webhook channel callbacks. Loopback, private and
+// link-local destinations are refused unless listed in AllowedNetworks.
+type WebhooksConfig struct {
+	// AllowedNetworks lists internal CIDRs or addresses that may be reached
+	// anyway, for example a receiver on the same private network.
+	AllowedNetworks []string `toml:"allowed_networks"`
+}
+
+// SecretsConfig holds the master key used to encrypt provider API keys and
+// channel, MCP and search provider credentials in the database. Keys are 32
+// random bytes, base64 encoded. Without a master key credentials are stored
+// in plaintext.
+type SecretsConfig struct {
+	MasterKey     string `toml:"master_key"`
+	MasterKeyFile string `toml:"master_key_file"`
+	// PreviousKeys and PreviousKeyFiles still decrypt values written before a
+	// key rotation. Drop them once the rotation command has run.
+	PreviousKeys     []string `toml:"previous_keys"`
+	PreviousKeyFiles []string `toml:"previous_key_files"`
 }
 
 type AdminConfig struct {
@@ -56,8 +97,42 @@ type AdminConfig struct {
 }
 
 type AuthConfig struct {
-	JWTSecret    string `toml:"jwt_secret"`
-	JWTExpiresIn string `toml:"jwt_expires_in"`
+	JWTSecret    string     `toml:"jwt_secret"`
+	JWTExpiresIn string     `toml:"jwt_expires_in"`
+	OIDC         OIDCConfig `toml:"oidc"`
+}
+
+// OIDCConfig enables single sign-on with an OpenID Connect provider, using
+// the authorization code flow with PKCE.
+type OIDCConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Name is shown on the login button.
+	Name     string `toml:"name"`
+	Issuer   string `toml:"issuer"`
+	ClientID string `toml:"client_id"`
+	// ClientSecret may be empty for public clients.
+	ClientSecret string `toml:"client_secret"`
+	// RedirectURL is the externally reachable /auth/oidc/callback URL
+	// registered with the provider.
+	RedirectURL string   `toml:"redirect_url"`
+	Scopes      []string `toml:"scopes"`
+	// UsernameClaim names new users. Defaults to preferred_username, then the
+	// email.
+	UsernameClaim string `toml:"username_claim"`
+	// RoleClaim is a string or list claim such as groups. Users whose claim
+	// contains one of AdminValues are admins, everyone else gets DefaultRole,
+	// on every login. Without RoleClaim the role is only set on first login.
+	RoleClaim   string   `toml:"role_claim"`
+	AdminValues []string `toml:"admin_values"`
+	DefaultRole string   `toml:"default_role"`
+	// LinkByEmail signs a first-time SSO user into the existing account with
+	// the same verified email instead of creating a new one.
+	LinkByEmail bool `toml:"link_by_email"`
+	// DisablePasswordLogin makes SSO the only way to log in.
+	DisablePasswordLogin bool `toml:"disable_password_login"`
+	// PostLoginRedirect is where the browser lands after login, with the
+	// token in the URL fragment. Defaults to /login.
+	PostLoginRedirect string `toml:"post_login_redirect"`
 }
 
 type ContainerdConfig struct {
@@ -89,6 +164,13 @@ type QdrantConfig struct {
 	TimeoutSeconds int    `toml:"timeout_seconds"`
 }
 
+type MemoryConfig struct {
+	// VectorStore selects the memory vector backend: "qdrant" (default) or
+	// "pgvector". The pgvector backend stores points in the main Postgres
+	// database and reuses qdrant.collection as its collection name.
+	VectorStore string `toml:"vector_store"`
+}
+
 type AgentGatewayConfig struct {
 	Host string `toml:"host"`
 	Port int    `toml:"port"`
@@ -166,6 +248,9 @@ func Load(path string) (Config, error) {
 			BaseURL:    DefaultQdrantURL,
 			Collection: DefaultQdrantCollection,
 		},
+		Memory: MemoryConfig{
+			VectorStore: DefaultMemoryVectorStore,
+		},
 		AgentGateway: AgentGatewayConfig{
 			Host: "127.0.0.1",
 			Port: 8081,
@@ -0,0 +1,398 @@
+package containerd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/containerd/v2/client"
+)
+
+// EgressMode selects how much of the network a bot container may reach.
+type EgressMode string
+
+const (
+	// EgressUnrestricted attaches the default CNI network without filtering.
+	EgressUnrestricted EgressMode = "unrestricted"
+	// EgressAllowlist only lets the container connect to the allowed domains
+	// and CIDRs (plus its DNS servers). Known gap: the DNS servers are not
+	// filtered, so a container can still leak data through DNS queries for
+	// names it chooses.
+	EgressAllowlist EgressMode = "allowlist"
+	// EgressNone leaves the container with loopback only.
+	EgressNone EgressMode = "none"
+)
+
+// egressRootChain is jumped to from FORWARD and INPUT and dispatches each
+// restricted container by source address to its own chain.
+const egressRootChain = "MEMOH-EGRESS"
+
+// EgressPolicy describes where a container may connect to.
+type EgressPolicy struct {
+	Mode           EgressMode
+	AllowedDomains []string
+	AllowedCIDRs   []string
+	// ResolvConf is the resolv.conf mounted into the container; its
+	// nameservers stay reachable in allowlist mode.
+	ResolvConf string
+}
+
+// EgressPolicyFunc returns the egress policy of a container.
+type EgressPolicyFunc func(ctx context.Context, containerID string) (EgressPolicy, error)
+
+// iptablesFunc runs one iptables or ip6tables command.
+type iptablesFunc func(ctx context.Context, args ...string) ([]byte, error)
+
+// iptablesRunner and ip6tablesRunner run IPv4 and IPv6 rule commands. They
+// are variables so tests can record rules without touching the host.
+var (
+	iptablesRunner  = commandRunner("iptables")
+	ip6tablesRunner = commandRunner("ip6tables")
+)
+
+func commandRunner(name string) iptablesFunc {
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, name, args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil && stderr.Len() > 0 {
+			return out, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+		}
+		return out, err
+	}
+}
+
+// SetupBotNetwork attaches networking to a task according to the egress
+// policy. Call it before the task starts (see StartTaskOptions.Network) so
+// the container never runs with an unfiltered network. Allowlist mode fails
+// closed: if the rules cannot be installed, the network is detached again
+// and an error is returned.
+func SetupBotNetwork(ctx context.Context, task client.Task, containerID string, policy EgressPolicy) error {
+	if task == nil {
+		return ErrInvalidArgument
+	}
+	if containerID == "" {
+		containerID = task.ID()
+	}
+	switch policy.Mode {
+	case EgressNone:
+		// The task may still hold an interface from an earlier policy.
+		if err := RemoveNetwork(ctx, task, containerID); err != nil && !isNetworkAbsentError(err) {
+			return fmt.Errorf("detach network: %w", err)
+		}
+		return nil
+	case EgressAllowlist:
+		addrs, err := setupNetwork(ctx, task, containerID)
+		if err != nil {
+			return err
+		}
+		applyErr := applyEgressAllowlist(ctx, containerID, addrs, policy)
+		if applyErr == nil {
+			return nil
+		}
+		if rmErr := RemoveNetwork(ctx, task, containerID); rmErr != nil {
+			return fmt.Errorf("apply egress allowlist: %w (detach network: %v)", applyErr, rmErr)
+		}
+		return fmt.Errorf("apply egress allowlist: %w", applyErr)
+	default:
+		if err := SetupNetwork(ctx, task, containerID); err != nil {
+			return err
+		}
+		if runtime.GOOS == "darwin" {
+			return nil
+		}
+		if err := removeEgressRules(ctx, iptablesRunner, containerID); err != nil {
+			return err
+		}
+		return removeEgressRules(ctx, ip6tablesRunner, containerID)
+	}
+}
+
+// applyEgressAllowlist installs a filtering chain for every address the
+// container holds: iptables for IPv4 and ip6tables for IPv6.
+func applyEgressAllowlist(ctx context.Context, containerID string, addrs []net.IP, policy EgressPolicy) error {
+	if runtime.GOOS == "darwin" || len(addrs) == 0 {
+		return errors.New("container address unknown; egress allowlist is not supported on this platform")
+	}
+	dest4, dest6, err := resolveEgressDestinations(ctx, policy)
+	if err != nil {
+		return err
+	}
+	var ip4, ip6 net.IP
+	for _, addr := range addrs {
+		if v4 := addr.To4(); v4 != nil {
+			ip4 = v4
+		} else {
+			ip6 = addr
+		}
+	}
+	if ip4 != nil {
+		if err := applyEgressChain(ctx, iptablesRunner, containerID, ip4.String()+"/32", dest4); err != nil {
+			return err
+		}
+	} else if err := removeEgressRules(ctx, iptablesRunner, containerID); err != nil {
+		return err
+	}
+	if ip6 != nil {
+		if err := applyEgressChain(ctx, ip6tablesRunner, containerID, ip6.String()+"/128", dest6); err != nil {
+			return fmt.Errorf("ipv6: %w", err)
+		}
+		return nil
+	}
+	return removeEgressRules(ctx, ip6tablesRunner, containerID)
+}
+
+// applyEgressChain fills the container's chain and points the root chain at
+// it for the container's source address.
+func applyEgressChain(ctx context.Context, run iptablesFunc, containerID, source string, destinations []string) error {
+	if err := ensureEgressRootChain(ctx, run); err != nil {
+		return err
+	}
+	chain := egressChainName(containerID)
+	if _, err := run(ctx, "-N", chain); err != nil {
+		if _, flushErr := run(ctx, "-F", chain); flushErr != nil {
+			return flushErr
+		}
+	}
+	for _, rule := range egressChainRules(chain, destinations) {
+		if _, err := run(ctx, rule...); err != nil {
+			return err
+		}
+	}
+	// Drop jumps left from an earlier address before adding the current one.
+	if err := deleteEgressJumps(ctx, run, chain); err != nil {
+		return err
+	}
+	_, err := run(ctx, "-A", egressRootChain, "-s", source, "-j", chain)
+	return err
+}
+
+// egressChainRules returns the iptables commands that fill a container
+// chain: replies and allowed destinations return to the normal path, and
+// everything else is rejected.
+func egressChainRules(chain string, destinations []string) [][]string {
+	rules := [][]string{
+		{"-A", chain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "RETURN"},
+	}
+	for _, dest := range destinations {
+		rules = append(rules, []string{"-A", chain, "-d", dest, "-j", "RETURN"})
+	}
+	rules = append(rules, []string{"-A", chain, "-j", "REJECT"})
+	return rules
+}
+
+// RefreshEgressAllowlist re-resolves the allowed domains of a restricted
+// container and rewrites its chains in place, keeping its address and the
+// jumps into them. The new rules are inserted ahead of the old ones before
+// those are deleted, so the container is never left unfiltered. A failed
+// lookup keeps the current rules.
+func RefreshEgressAllowlist(ctx context.Context, containerID string, policy EgressPolicy) error {
+	if policy.Mode != EgressAllowlist || runtime.GOOS == "darwin" {
+		return nil
+	}
+	dest4, dest6, err := resolveEgressDestinations(ctx, policy)
+	if err != nil {
+		return err
+	}
+	if err := refreshEgressChain(ctx, iptablesRunner, containerID, dest4); err != nil {
+		return err
+	}
+	if err := refreshEgressChain(ctx, ip6tablesRunner, containerID, dest6); err != nil {
+		return fmt.Errorf("ipv6: %w", err)
+	}
+	return nil
+}
+
+// refreshEgressChain replaces the rules of an existing container chain. It is
+// a no-op when the chain does not exist for this address family.
+func refreshEgressChain(ctx context.Context, run iptablesFunc, containerID string, destinations []string) error {
+	chain := egressChainName(containerID)
+	out, err := run(ctx, "-S", chain)
+	if err != nil {
+		return nil
+	}
+	old := 0
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "-A ") {
+			old++
+		}
+	}
+	rules := egressChainRules(chain, destinations)
+	for i, rule := range rules {
+		insert := append([]string{"-I", chain, strconv.Itoa(i + 1)}, rule[2:]...)
+		if _, err := run(ctx, insert...); err != nil {
+			return err
+		}
+	}
+	// The old rules now follow the new REJECT and are unreachable.
+	for range old {
+		if _, err := run(ctx, "-D", chain, strconv.Itoa(len(rules)+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveEgressDestinations turns the allowlist into IPv4 and IPv6 CIDRs.
+// Domains are resolved (A and AAAA) when the rules are applied, on task
+// start, reconcile and policy updates, and again by RefreshEgressAllowlist;
+// addresses a domain moves to stay blocked until the next refresh.
+func resolveEgressDestinations(ctx context.Context, policy EgressPolicy) ([]string, []string, error) {
+	seen := map[string]struct{}{}
+	var out4, out6 []string
+	add := func(network *net.IPNet) {
+		cidr := network.String()
+		if _, ok := seen[cidr]; ok {
+			return
+		}
+		seen[cidr] = struct{}{}
+		if network.IP.To4() != nil {
+			out4 = append(out4, cidr)
+		} else {
+			out6 = append(out6, cidr)
+		}
+	}
+	addIP := func(ip net.IP) {
+		if v4 := ip.To4(); v4 != nil {
+			add(&net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)})
+			return
+		}
+		add(&net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+	}
+	for _, ns := range resolvConfNameservers(policy.ResolvConf) {
+		addIP(ns)
+	}
+	for _, raw := range policy.AllowedCIDRs {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "/") {
+			ip := net.ParseIP(raw)
+			if ip == nil {
+				return nil, nil, fmt.Errorf("invalid CIDR %q", raw)
+			}
+			addIP(ip)
+			continue
+		}
+		_, network, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CIDR %q", raw)
+		}
+		add(network)
+	}
+	var resolver net.Resolver
+	for _, domain := range policy.AllowedDomains {
+		domain = strings.TrimSpace(domain)
+		if domain == "" {
+			continue
+		}
+		addrs, err := resolver.LookupIP(ctx, "ip", domain)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve %s: %w", domain, err)
+		}
+		for _, addr := range addrs {
+			addIP(addr)
+		}
+	}
+	return out4, out6, nil
+}
+
+func resolvConfNameservers(path string) []net.IP {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	var out []net.IP
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		if ip := net.ParseIP(fields[1]); ip != nil {
+			out = append(out, ip)
+		}
+	}
+	return out
+}
+
+func ensureEgressRootChain(ctx context.Context, run iptablesFunc) error {
+	if _, err := run(ctx, "-N", egressRootChain); err != nil {
+		// Already exists; -L tells it apart from a broken iptables.
+		if _, listErr := run(ctx, "-L", egressRootChain, "-n"); listErr != nil {
+			return err
+		}
+	}
+	for _, hook := range []string{"FORWARD", "INPUT"} {
+		if _, err := run(ctx, "-C", hook, "-j", egressRootChain); err == nil {
+			continue
+		}
+		if _, err := run(ctx, "-I", hook, "1", "-j", egressRootChain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEgressRules deletes a container's chain and the jumps into it. It is
+// a no-op when the container was never restricted.
+func removeEgressRules(ctx context.Context, run iptablesFunc, containerID string) error {
+	chain := egressChainName(containerID)
+	if _, err := run(ctx, "-L", chain, "-n"); err != nil {
+		return nil
+	}
+	if err := deleteEgressJumps(ctx, run, chain); err != nil {
+		return err
+	}
+	if _, err := run(ctx, "-F", chain); err != nil {
+		return err
+	}
+	_, err := run(ctx, "-X", chain)
+	return err
+}
+
+func deleteEgressJumps(ctx context.Context, run iptablesFunc, chain string) error {
+	out, err := run(ctx, "-S", egressRootChain)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "-A" || !strings.HasSuffix(line, "-j "+chain) {
+			continue
+		}
+		args := append([]string{"-D"}, fields[1:]...)
+		if _, err := run(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// egressChainName derives a per-container chain name within iptables'
+// 28-character limit.
+func egressChainName(containerID string) string {
+	sum := sha256.Sum256([]byte(containerID))
+	return "MEMOH-EG-" + hex.EncodeToString(sum[:])[:16]
+}
+
+func isNetworkAbsentError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "netns not found") || strings.Contains(msg, "not found")
+}
@@ -0,0 +1,168 @@
+package containerd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEgressChainName(t *testing.T) {
+	a := egressChainName("mcp-bot-a")
+	b := egressChainName("mcp-bot-b")
+	if a == b {
+		t.Fatalf("expected distinct chains, got %q", a)
+	}
+	if len(a) > 28 {
+		t.Fatalf("chain name %q exceeds iptables limit", a)
+	}
+	if a != egressChainName("mcp-bot-a") {
+		t.Fatal("chain name is not stable")
+	}
+}
+
+func TestResolveEgressDestinations(t *testing.T) {
+	resolv := filepath.Join(t.TempDir(), "resolv.conf")
+	if err := os.WriteFile(resolv, []byte("# comment\nnameserver 1.1.1.1\nnameserver ::1\nsearch local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got4, got6, err := resolveEgressDestinations(context.Background(), EgressPolicy{
+		Mode:         EgressAllowlist,
+		AllowedCIDRs: []string{"10.0.0.0/8", "192.168.1.7", "1.1.1.1", "fd00::/8", "2001:db8::1"},
+		ResolvConf:   resolv,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want4 := []string{"1.1.1.1/32", "10.0.0.0/8", "192.168.1.7/32"}
+	want6 := []string{"::1/128", "fd00::/8", "2001:db8::1/128"}
+	if !reflect.DeepEqual(got4, want4) || !reflect.DeepEqual(got6, want6) {
+		t.Fatalf("got %v %v, want %v %v", got4, got6, want4, want6)
+	}
+
+	if _, _, err := resolveEgressDestinations(context.Background(), EgressPolicy{AllowedCIDRs: []string{"not-an-ip"}}); err == nil {
+		t.Fatal("expected invalid CIDR to be rejected")
+	}
+}
+
+func TestApplyEgressAllowlistRules(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("allowlist is not supported on darwin")
+	}
+	chain := egressChainName("mcp-bot")
+	var calls []string
+	record := func(prefix string) iptablesFunc {
+		return func(_ context.Context, args ...string) ([]byte, error) {
+			call := strings.Join(args, " ")
+			calls = append(calls, prefix+call)
+			switch call {
+			case "-C FORWARD -j " + egressRootChain, "-C INPUT -j " + egressRootChain:
+				return nil, errors.New("no such rule")
+			case "-S " + egressRootChain:
+				if prefix == "" {
+					return []byte("-N " + egressRootChain + "\n-A " + egressRootChain + " -s 10.88.0.9/32 -j " + chain + "\n"), nil
+				}
+				return []byte("-N " + egressRootChain + "\n"), nil
+			}
+			return nil, nil
+		}
+	}
+	orig4, orig6 := iptablesRunner, ip6tablesRunner
+	t.Cleanup(func() { iptablesRunner, ip6tablesRunner = orig4, orig6 })
+	iptablesRunner, ip6tablesRunner = record(""), record("6: ")
+
+	err := applyEgressAllowlist(context.Background(), "mcp-bot", []net.IP{net.ParseIP("10.88.0.5").To4(), net.ParseIP("fd00::5")}, EgressPolicy{
+		Mode:         EgressAllowlist,
+		AllowedCIDRs: []string{"203.0.113.0/24", "2001:db8::/32"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"-N " + egressRootChain,
+		"-C FORWARD -j " + egressRootChain,
+		"-I FORWARD 1 -j " + egressRootChain,
+		"-C INPUT -j " + egressRootChain,
+		"-I INPUT 1 -j " + egressRootChain,
+		"-N " + chain,
+		"-A " + chain + " -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"-A " + chain + " -d 203.0.113.0/24 -j RETURN",
+		"-A " + chain + " -j REJECT",
+		"-S " + egressRootChain,
+		"-D " + egressRootChain + " -s 10.88.0.9/32 -j " + chain,
+		"-A " + egressRootChain + " -s 10.88.0.5/32 -j " + chain,
+		"6: -N " + egressRootChain,
+		"6: -C FORWARD -j " + egressRootChain,
+		"6: -I FORWARD 1 -j " + egressRootChain,
+		"6: -C INPUT -j " + egressRootChain,
+		"6: -I INPUT 1 -j " + egressRootChain,
+		"6: -N " + chain,
+		"6: -A " + chain + " -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"6: -A " + chain + " -d 2001:db8::/32 -j RETURN",
+		"6: -A " + chain + " -j REJECT",
+		"6: -S " + egressRootChain,
+		"6: -A " + egressRootChain + " -s fd00::5/128 -j " + chain,
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("iptables calls:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Without an IPv6 address, a chain left from an earlier apply is removed.
+	calls = nil
+	err = applyEgressAllowlist(context.Background(), "mcp-bot", []net.IP{net.ParseIP("10.88.0.5")}, EgressPolicy{
+		Mode:         EgressAllowlist,
+		AllowedCIDRs: []string{"203.0.113.0/24"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := calls[len(calls)-1]; last != "6: -X "+chain {
+		t.Fatalf("expected the IPv6 chain to be removed, last call %q", last)
+	}
+}
+
+func TestRefreshEgressChain(t *testing.T) {
+	chain := egressChainName("mcp-bot")
+	var calls []string
+	run := func(_ context.Context, args ...string) ([]byte, error) {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		if call == "-S "+chain {
+			return []byte("-N " + chain + "\n" +
+				"-A " + chain + " -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN\n" +
+				"-A " + chain + " -d 203.0.113.7/32 -j RETURN\n" +
+				"-A " + chain + " -j REJECT\n"), nil
+		}
+		return nil, nil
+	}
+	if err := refreshEgressChain(context.Background(), run, "mcp-bot", []string{"203.0.113.8/32"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"-S " + chain,
+		"-I " + chain + " 1 -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"-I " + chain + " 2 -d 203.0.113.8/32 -j RETURN",
+		"-I " + chain + " 3 -j REJECT",
+		"-D " + chain + " 4",
+		"-D " + chain + " 4",
+		"-D " + chain + " 4",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("iptables calls:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A container without a chain for this family is left alone.
+	calls = nil
+	missing := func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		return nil, errors.New("no chain")
+	}
+	if err := refreshEgressChain(context.Background(), missing, "mcp-bot", nil); err != nil || len(calls) != 1 {
+		t.Fatalf("expected a single lookup, got %v (%v)", calls, err)
+	}
+}
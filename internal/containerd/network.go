@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -21,33 +22,41 @@ const (
 
 // SetupNetwork attaches CNI networking to a running task.
 func SetupNetwork(ctx context.Context, task client.Task, containerID string) error {
+	_, err := setupNetwork(ctx, task, containerID)
+	return err
+}
+
+// setupNetwork attaches CNI networking and returns the container's
+// addresses, at most one per IP family. No addresses are returned when
+// networking is set up through the lima CLI, which does not report them.
+func setupNetwork(ctx context.Context, task client.Task, containerID string) ([]net.IP, error) {
 	if task == nil {
-		return ErrInvalidArgument
+		return nil, ErrInvalidArgument
 	}
 	if containerID == "" {
 		containerID = task.ID()
 	}
 	if containerID == "" {
-		return ErrInvalidArgument
+		return nil, ErrInvalidArgument
 	}
 
 	pid := task.Pid()
 	if pid == 0 {
-		return fmt.Errorf("task pid not available for %s", containerID)
+		return nil, fmt.Errorf("task pid not available for %s", containerID)
 	}
 	if runtime.GOOS == "darwin" {
-		return setupNetworkWithCLI(ctx, containerID, pid)
+		return nil, setupNetworkWithCLI(ctx, containerID, pid)
 	}
 
 	if _, err := os.Stat(defaultCNIConfDir); err != nil {
-		return fmt.Errorf("cni config dir missing: %s: %w", defaultCNIConfDir, err)
+		return nil, fmt.Errorf("cni config dir missing: %s: %w", defaultCNIConfDir, err)
 	}
 	if _, err := os.Stat(defaultCNIBinDir); err != nil {
-		return fmt.Errorf("cni bin dir missing: %s: %w", defaultCNIBinDir, err)
+		return nil, fmt.Errorf("cni bin dir missing: %s: %w", defaultCNIBinDir, err)
 	}
 	netnsPath := filepath.Join("/proc", fmt.Sprint(pid), "ns", "net")
 	if _, err := os.Stat(netnsPath); err != nil {
-		return fmt.Errorf("netns not found: %s: %w", netnsPath, err)
+		return nil, fmt.Errorf("netns not found: %s: %w", netnsPath, err)
 	}
 
 	cni, err := gocni.New(
@@ -55,23 +64,59 @@ func SetupNetwork(ctx context.Context, task client.Task, containerID string) err
 		gocni.WithPluginConfDir(defaultCNIConfDir),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := cni.Load(gocni.WithLoNetwork, gocni.WithDefaultConf); err != nil {
-		return err
+		return nil, err
 	}
-	_, err = cni.Setup(ctx, containerID, netnsPath)
+	result, err := cni.Setup(ctx, containerID, netnsPath)
 	if err == nil {
-		return nil
+		return resultIPs(result), nil
 	}
 	if !isDuplicateAllocationError(err) {
-		return err
+		return nil, err
 	}
 	if rmErr := cni.Remove(ctx, containerID, netnsPath); rmErr != nil {
-		return rmErr
+		return nil, rmErr
 	}
-	_, err = cni.Setup(ctx, containerID, netnsPath)
-	return err
+	result, err = cni.Setup(ctx, containerID, netnsPath)
+	if err != nil {
+		return nil, err
+	}
+	return resultIPs(result), nil
+}
+
+// resultIPs returns the first IPv4 and the first IPv6 address assigned
+// outside loopback and link-local ranges.
+func resultIPs(result *gocni.Result) []net.IP {
+	if result == nil {
+		return nil
+	}
+	var ip4, ip6 net.IP
+	for name, iface := range result.Interfaces {
+		if iface == nil || name == "lo" {
+			continue
+		}
+		for _, cfg := range iface.IPConfigs {
+			if cfg == nil || cfg.IP == nil || cfg.IP.IsLoopback() || cfg.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if v4 := cfg.IP.To4(); v4 != nil {
+				if ip4 == nil {
+					ip4 = v4
+				}
+			} else if ip6 == nil {
+				ip6 = cfg.IP
+			}
+		}
+	}
+	var out []net.IP
+	for _, ip := range []net.IP{ip4, ip6} {
+		if ip != nil {
+			out = append(out, ip)
+		}
+	}
+	return out
 }
 
 func setupNetworkWithCLI(ctx context.Context, containerID string, pid uint32) error {
@@ -159,7 +204,13 @@ func RemoveNetwork(ctx context.Context, task client.Task, containerID string) er
 	if err := cni.Load(gocni.WithLoNetwork, gocni.WithDefaultConf); err != nil {
 		return err
 	}
-	return cni.Remove(ctx, containerID, netnsPath)
+	if err := cni.Remove(ctx, containerID, netnsPath); err != nil {
+		return err
+	}
+	if err := removeEgressRules(ctx, iptablesRunner, containerID); err != nil {
+		return err
+	}
+	return removeEgressRules(ctx, ip6tablesRunner, containerID)
 }
 
 func removeNetworkWithCLI(ctx context.Context, containerID string, pid uint32) error {
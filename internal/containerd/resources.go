@@ -0,0 +1,215 @@
+package containerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cgroup1stats "github.com/containerd/cgroups/v3/cgroup1/stats"
+	cgroup2stats "github.com/containerd/cgroups/v3/cgroup2/stats"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/typeurl/v2"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// DefaultCPUPeriod is the CFS period used when a CPU quota is set.
+const DefaultCPUPeriod uint64 = 100000
+
+// ResourceLimits are the cgroup limits applied to a container. Zero values
+// leave the corresponding resource unlimited.
+type ResourceLimits struct {
+	CPUShares   uint64
+	CPUQuota    int64
+	CPUPeriod   uint64
+	MemoryBytes int64
+	PidsLimit   int64
+}
+
+// IsZero reports whether no limit is set.
+func (l ResourceLimits) IsZero() bool {
+	return l.CPUShares == 0 && l.CPUQuota == 0 && l.MemoryBytes == 0 && l.PidsLimit == 0
+}
+
+// linuxResources returns the OCI resources for l. Unset limits are spelled
+// out as "unlimited" so that a live update also lifts a previously set limit.
+func (l ResourceLimits) linuxResources() *specs.LinuxResources {
+	unlimited := int64(-1)
+	period := l.CPUPeriod
+	if period == 0 {
+		period = DefaultCPUPeriod
+	}
+	cpu := &specs.LinuxCPU{Period: &period}
+	if l.CPUShares > 0 {
+		shares := l.CPUShares
+		cpu.Shares = &shares
+	}
+	if l.CPUQuota > 0 {
+		quota := l.CPUQuota
+		cpu.Quota = &quota
+	} else {
+		cpu.Quota = &unlimited
+	}
+	memory := &specs.LinuxMemory{Limit: &unlimited}
+	if l.MemoryBytes > 0 {
+		limit := l.MemoryBytes
+		memory.Limit = &limit
+	}
+	pids := &specs.LinuxPids{}
+	if l.PidsLimit > 0 {
+		limit := l.PidsLimit
+		pids.Limit = &limit
+	} else {
+		pids.Limit = &unlimited
+	}
+	return &specs.LinuxResources{CPU: cpu, Memory: memory, Pids: pids}
+}
+
+// WithResourceLimits sets the CPU, memory and pids limits of the spec. It
+// keeps any other resource settings (devices, block IO) from earlier opts.
+func WithResourceLimits(limits ResourceLimits) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			return nil
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		resources := limits.linuxResources()
+		s.Linux.Resources.CPU = resources.CPU
+		s.Linux.Resources.Memory = resources.Memory
+		s.Linux.Resources.Pids = resources.Pids
+		return nil
+	}
+}
+
+// ResourceStats is a snapshot of a task's cgroup counters. Counters are
+// cumulative since the task started.
+type ResourceStats struct {
+	MemoryUsage      uint64
+	MemoryLimit      uint64
+	OOMEvents        uint64
+	OOMKills         uint64
+	CPUPeriods       uint64
+	CPUThrottled     uint64
+	CPUThrottledUsec uint64
+	PidsCurrent      uint64
+	PidsLimit        uint64
+}
+
+// UpdateContainerResources stores limits in the container spec, so they
+// survive task restarts, and applies them to the running task if any.
+func (s *DefaultService) UpdateContainerResources(ctx context.Context, containerID string, limits ResourceLimits) error {
+	if containerID == "" {
+		return ErrInvalidArgument
+	}
+	ctx = s.withNamespace(ctx)
+	container, err := s.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	if err := WithResourceLimits(limits)(ctx, s.client, nil, spec); err != nil {
+		return err
+	}
+	if err := container.Update(ctx, containerd.UpdateContainerOpts(containerd.WithSpec(spec))); err != nil {
+		return fmt.Errorf("update container spec: %w", err)
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := task.Update(ctx, containerd.WithResources(limits.linuxResources())); err != nil {
+		return fmt.Errorf("update task resources: %w", err)
+	}
+	return nil
+}
+
+// TaskResourceStats reads the cgroup counters of a container's task. Both
+// cgroup v1 and v2 hosts are supported.
+func (s *DefaultService) TaskResourceStats(ctx context.Context, containerID string) (ResourceStats, error) {
+	if containerID == "" {
+		return ResourceStats{}, ErrInvalidArgument
+	}
+	ctx = s.withNamespace(ctx)
+	container, err := s.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	switch {
+	case typeurl.Is(metric.Data, (*cgroup2stats.Metrics)(nil)):
+		data := &cgroup2stats.Metrics{}
+		if err := typeurl.UnmarshalTo(metric.Data, data); err != nil {
+			return ResourceStats{}, err
+		}
+		return resourceStatsFromCgroup2(data), nil
+	case typeurl.Is(metric.Data, (*cgroup1stats.Metrics)(nil)):
+		data := &cgroup1stats.Metrics{}
+		if err := typeurl.UnmarshalTo(metric.Data, data); err != nil {
+			return ResourceStats{}, err
+		}
+		return resourceStatsFromCgroup1(data), nil
+	default:
+		return ResourceStats{}, errors.New("unsupported task metrics format")
+	}
+}
+
+func resourceStatsFromCgroup2(data *cgroup2stats.Metrics) ResourceStats {
+	var stats ResourceStats
+	if data.Memory != nil {
+		stats.MemoryUsage = data.Memory.Usage
+		stats.MemoryLimit = data.Memory.UsageLimit
+	}
+	if data.MemoryEvents != nil {
+		stats.OOMEvents = data.MemoryEvents.Oom
+		stats.OOMKills = data.MemoryEvents.OomKill
+	}
+	if data.CPU != nil {
+		stats.CPUPeriods = data.CPU.NrPeriods
+		stats.CPUThrottled = data.CPU.NrThrottled
+		stats.CPUThrottledUsec = data.CPU.ThrottledUsec
+	}
+	if data.Pids != nil {
+		stats.PidsCurrent = data.Pids.Current
+		stats.PidsLimit = data.Pids.Limit
+	}
+	return stats
+}
+
+func resourceStatsFromCgroup1(data *cgroup1stats.Metrics) ResourceStats {
+	var stats ResourceStats
+	if data.Memory != nil && data.Memory.Usage != nil {
+		stats.MemoryUsage = data.Memory.Usage.Usage
+		stats.MemoryLimit = data.Memory.Usage.Limit
+	}
+	if data.MemoryOomControl != nil {
+		stats.OOMKills = data.MemoryOomControl.OomKill
+		stats.OOMEvents = data.MemoryOomControl.OomKill
+	}
+	if data.CPU != nil && data.CPU.Throttling != nil {
+		stats.CPUPeriods = data.CPU.Throttling.Periods
+		stats.CPUThrottled = data.CPU.Throttling.ThrottledPeriods
+		stats.CPUThrottledUsec = data.CPU.Throttling.ThrottledTime / 1000
+	}
+	if data.Pids != nil {
+		stats.PidsCurrent = data.Pids.Current
+		stats.PidsLimit = data.Pids.Limit
+	}
+	return stats
+}
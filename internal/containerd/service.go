@@ -63,6 +63,10 @@ type StartTaskOptions struct {
 	UseStdio bool
 	Terminal bool
 	FIFODir  string
+	// Network, when set, runs after the task is created and before it is
+	// started, so networking and egress rules are in place before the
+	// container runs anything. An error deletes the task.
+	Network func(ctx context.Context, task containerd.Task) error
 }
 
 type StopTaskOptions struct {
@@ -141,6 +145,8 @@ type Service interface {
 	PrepareSnapshot(ctx context.Context, snapshotter, key, parent string) error
 	CreateContainerFromSnapshot(ctx context.Context, req CreateContainerRequest) (containerd.Container, error)
 	SnapshotMounts(ctx context.Context, snapshotter, key string) ([]mount.Mount, error)
+	UpdateContainerResources(ctx context.Context, containerID string, limits ResourceLimits) error
+	TaskResourceStats(ctx context.Context, containerID string) (ResourceStats, error)
 }
 
 type DefaultService struct {
@@ -464,6 +470,14 @@ func (s *DefaultService) StartTask(ctx context.Context, containerID string, opts
 	if err != nil {
 		return nil, err
 	}
+	if opts != nil && opts.Network != nil {
+		if err := opts.Network(ctx, task); err != nil {
+			if _, delErr := task.Delete(ctx, containerd.WithProcessKill); delErr != nil {
+				return nil, fmt.Errorf("setup task network: %w (delete task: %v)", err, delErr)
+			}
+			return nil, fmt.Errorf("setup task network: %w", err)
+		}
+	}
 	if err := task.Start(ctx); err != nil {
 		return nil, err
 	}
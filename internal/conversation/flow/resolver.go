@@ -20,17 +20,21 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/Kxiandaoyan/Memoh-v2/internal/budget"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/heartbeat"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/memory"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 	messagepkg "github.com/Kxiandaoyan/Memoh-v2/internal/message"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/models"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/processlog"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/schedule"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
 )
 
 const (
@@ -38,6 +42,8 @@ const (
 	memoryContextLimitPerScope = 4
 	memoryContextMaxItems      = 8
 	memoryContextItemMaxChars  = 220
+	// The score thresholds are cosine similarities; default hybrid recall
+	// fuses by absolute score and keeps that scale.
 	memoryMinScoreThreshold    = 0.1
 	memoryDecayHalfLifeDays    = 30.0
 	sharedMemoryNamespace      = "bot"
@@ -212,6 +218,11 @@ type TriggerMessageSender interface {
 	SendText(ctx context.Context, botID, platform, target, text string) error
 }
 
+// BudgetChecker checks token budgets before a request reaches the gateway.
+type BudgetChecker interface {
+	Check(ctx context.Context, subject budget.Subject) (budget.Decision, error)
+}
+
 // Resolver orchestrates chat with the agent gateway.
 type Resolver struct {
 	modelsService    *models.Service
@@ -225,6 +236,8 @@ type Resolver struct {
 	ovSessionExtractor OVSessionExtractor
 	ovContextLoader    OVContextLoader
 	triggerSender    TriggerMessageSender
+	budgetChecker    BudgetChecker
+	eventPublisher   event.Publisher
 	gatewayBaseURL   string
 	timezone         string
 	timeout          time.Duration
@@ -265,12 +278,12 @@ func NewResolver(
 		gatewayBaseURL:    gatewayBaseURL,
 		timeout:           timeout,
 		logger:            log.With(slog.String("service", "conversation_resolver")),
-		httpClient:      &http.Client{Timeout: timeout},
+		httpClient:      &http.Client{Timeout: timeout, Transport: tracing.Transport(nil)},
 		streamingClient: &http.Client{
-			Transport: &http.Transport{
+			Transport: tracing.Transport(&http.Transport{
 				ResponseHeaderTimeout: 120 * time.Second, // max wait for first response byte
 				IdleConnTimeout:       180 * time.Second, // increased for long-running tasks
-			},
+			}),
 		},
 	}
 }
@@ -303,6 +316,17 @@ func (r *Resolver) SetTriggerSender(s TriggerMessageSender) {
 	r.triggerSender = s
 }
 
+// SetBudgetChecker sets the token budget check run before every chat and
+// trigger request.
+func (r *Resolver) SetBudgetChecker(c BudgetChecker) {
+	r.budgetChecker = c
+}
+
+// SetEventPublisher sets the hub that receives evolution_completed events.
+func (r *Resolver) SetEventPublisher(p event.Publisher) {
+	r.eventPublisher = p
+}
+
 // --- Process Logging Helpers ---
 
 // logProcessStep records a process log entry for debugging and monitoring
@@ -402,6 +426,8 @@ type gatewayUsage struct {
 	PromptTokens     int `json:"promptTokens"`
 	CompletionTokens int `json:"completionTokens"`
 	TotalTokens      int `json:"totalTokens"`
+	// CachedTokens is the part of PromptTokens read from the provider's prompt cache.
+	CachedTokens int `json:"cachedTokens,omitempty"`
 }
 
 // gatewaySchedule matches the agent gateway ScheduleModel for /chat/trigger-schedule.
@@ -430,9 +456,17 @@ type resolvedContext struct {
 	traceID  string
 }
 
-func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (resolvedContext, error) {
+func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (_ resolvedContext, err error) {
 	// Generate trace ID for this request
 	traceID := generateTraceID()
+	// The process log trace ID is recorded on the span so Postgres step rows
+	// and exported traces can be joined.
+	ctx, span := tracing.Start(ctx, "conversation.resolve",
+		attribute.String("bot.id", req.BotID),
+		attribute.String("chat.id", req.ChatID),
+		attribute.String("memoh.trace_id", traceID),
+	)
+	defer func() { tracing.End(span, err) }()
 
 	// Log user message received
 	r.logProcessStep(ctx, req.BotID, req.ChatID, traceID, req.UserID, req.CurrentChannel,
@@ -532,7 +566,10 @@ func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (r
 
 	var messages []conversation.ModelMessage
 	if !skipHistory && r.conversationSvc != nil {
-		msgs, err := r.loadMessages(ctx, req.ChatID, maxCtx)
+		historyCtx, historySpan := tracing.Start(ctx, "conversation.load_history", attribute.String("chat.id", req.ChatID))
+		msgs, err := r.loadMessages(historyCtx, req.ChatID, maxCtx)
+		historySpan.SetAttributes(attribute.Int("history.messages", len(msgs)))
+		tracing.End(historySpan, err)
 		if err != nil {
 			r.logProcessStep(ctx, req.BotID, req.ChatID, traceID, req.UserID, req.CurrentChannel,
 				processlog.StepHistoryLoaded, processlog.LevelError, "Message load failed",
@@ -908,6 +945,17 @@ func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (r
 
 // Chat sends a synchronous chat request to the agent gateway and stores the result.
 func (r *Resolver) Chat(ctx context.Context, req conversation.ChatRequest) (conversation.ChatResponse, error) {
+	breach, err := r.checkBudget(ctx, req.BotID, req.ChatID, req.UserID, req.CurrentChannel, req.SourceChannelIdentityID, req.TaskType)
+	if err != nil {
+		return conversation.ChatResponse{}, err
+	}
+	if breach != nil {
+		if budget.ClassOf(req.TaskType) == budget.ClassBackground {
+			return conversation.ChatResponse{}, budgetError(*breach)
+		}
+		return conversation.ChatResponse{Messages: budgetRefusalMessages(*breach)}, nil
+	}
+
 	rc, err := r.resolve(ctx, req)
 	if err != nil {
 		return conversation.ChatResponse{}, err
@@ -924,7 +972,7 @@ func (r *Resolver) Chat(ctx context.Context, req conversation.ChatRequest) (conv
 
 	syncStart := time.Now()
 	var resp gatewayResponse
-	err = withGatewayRetry(ctx, func() error {
+	err = withGatewayRetry(ctx, "chat", func() error {
 		var callErr error
 		resp, callErr = r.postChat(ctx, rc.payload, req.Token)
 		return callErr
@@ -946,7 +994,7 @@ func (r *Resolver) Chat(ctx context.Context, req conversation.ChatRequest) (conv
 					"fallback_model":   fbCtx.model.ModelID,
 					"fallback_provider": fbCtx.provider.ClientType,
 				}, 0)
-			err = withGatewayRetry(ctx, func() error {
+			err = withGatewayRetry(ctx, "chat", func() error {
 				var callErr error
 				resp, callErr = r.postChat(ctx, fbCtx.payload, req.Token)
 				return callErr
@@ -1011,7 +1059,7 @@ func (r *Resolver) Chat(ctx context.Context, req conversation.ChatRequest) (conv
 	if err := r.storeRoundWithTrace(ctx, req, rc.traceID, resp.Messages, resp.Usage); err != nil {
 		return conversation.ChatResponse{}, err
 	}
-	r.recordTokenUsage(ctx, req.BotID, resp.Usage, rc.model.ModelID, "chat")
+	r.recordTokenUsage(ctx, req.BotID, req.SourceChannelIdentityID, resp.Usage, rc.model.ModelID, usageSource(req.TaskType))
 
 	// Log response sent
 	r.logProcessStep(ctx, req.BotID, req.ChatID, rc.traceID, req.UserID, req.CurrentChannel,
@@ -1049,12 +1097,12 @@ type triggerParams struct {
 // executeTrigger is the shared execution path for both schedule and heartbeat triggers.
 // It resolves the conversation context, posts to the agent gateway, records token usage,
 // and stores the conversation round.
-func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token string) error {
+func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token string) (schedule.TriggerResult, error) {
 	if strings.TrimSpace(p.schedule.ID) == "" {
-		return fmt.Errorf("trigger pre-validation: schedule id is required")
+		return schedule.TriggerResult{}, fmt.Errorf("trigger pre-validation: schedule id is required")
 	}
 	if strings.TrimSpace(p.schedule.Command) == "" {
-		return fmt.Errorf("trigger pre-validation: schedule command is required")
+		return schedule.TriggerResult{}, fmt.Errorf("trigger pre-validation: schedule command is required")
 	}
 
 	r.logger.Info("executeTrigger: channel routing",
@@ -1094,11 +1142,20 @@ func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token st
 			"task_type":    taskType,
 		}, 0)
 
+	breach, err := r.checkBudget(ctx, p.botID, p.botID, p.ownerUserID, p.platform, "", p.usageType)
+	if err == nil && breach != nil {
+		err = budgetError(*breach)
+	}
+	if err != nil {
+		r.completeEvolutionLogOnError(ctx, p.evolutionLogID, err)
+		return schedule.TriggerResult{}, err
+	}
+
 	rc, err := r.resolve(ctx, req)
 	if err != nil {
 		r.logger.Warn("executeTrigger: resolve failed", slog.String("bot_id", p.botID), slog.Any("error", err))
 		r.completeEvolutionLogOnError(ctx, p.evolutionLogID, err)
-		return err
+		return schedule.TriggerResult{}, err
 	}
 
 	gwPayload := rc.payload
@@ -1133,7 +1190,7 @@ func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token st
 
 	triggerStart := time.Now()
 	var resp gatewayResponse
-	err = withGatewayRetry(ctx, func() error {
+	err = withGatewayRetry(ctx, "trigger_schedule", func() error {
 		var callErr error
 		resp, callErr = r.postTriggerSchedule(ctx, triggerReq, token)
 		return callErr
@@ -1145,10 +1202,20 @@ func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token st
 			processlog.StepLLMResponseReceived, processlog.LevelError, "Trigger request failed: "+err.Error(),
 			map[string]any{"error": err.Error()}, triggerDur)
 		r.completeEvolutionLogOnError(ctx, p.evolutionLogID, err)
-		return err
+		return schedule.TriggerResult{TraceID: rc.traceID, Model: rc.model.ModelID}, err
 	}
 
 	responsePreview := extractAssistantPreview(resp.Messages, 300)
+	result := schedule.TriggerResult{
+		TraceID:      rc.traceID,
+		Model:        rc.model.ModelID,
+		ReplyPreview: responsePreview,
+	}
+	if resp.Usage != nil {
+		result.PromptTokens = resp.Usage.PromptTokens
+		result.CompletionTokens = resp.Usage.CompletionTokens
+		result.TotalTokens = resp.Usage.TotalTokens
+	}
 	r.logProcessStep(ctx, p.botID, p.botID, rc.traceID, p.ownerUserID, p.platform,
 		processlog.StepLLMResponseReceived, processlog.LevelInfo, fmt.Sprintf("Trigger response received (%s)", p.usageType),
 		map[string]any{
@@ -1158,7 +1225,7 @@ func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token st
 			"usage":            resp.Usage,
 		}, triggerDur)
 
-	r.recordTokenUsage(ctx, p.botID, resp.Usage, rc.model.ModelID, p.usageType)
+	r.recordTokenUsage(ctx, p.botID, "", resp.Usage, rc.model.ModelID, p.usageType)
 	r.completeEvolutionLogFromResponse(ctx, p.evolutionLogID, resp)
 
 	// Fallback delivery: if the LLM never called the send MCP tool, push the
@@ -1210,7 +1277,7 @@ func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token st
 
 	if err := r.storeRound(ctx, req, resp.Messages, resp.Usage); err != nil {
 		r.logger.Warn("executeTrigger: storeRound failed", slog.String("bot_id", p.botID), slog.Any("error", err))
-		return err
+		return result, err
 	}
 
 	triggerTotalDur := int(time.Since(triggerTotalStart).Milliseconds())
@@ -1221,7 +1288,7 @@ func (r *Resolver) executeTrigger(ctx context.Context, p triggerParams, token st
 			"task_type":   taskType,
 		}, triggerTotalDur)
 
-	return nil
+	return result, nil
 }
 
 // hasSendToolCallInMessages returns true if any assistant message in the slice
@@ -1338,7 +1405,7 @@ func extractLastToolResultSummary(messages []conversation.ModelMessage) string {
 }
 
 // TriggerSchedule executes a scheduled command through the agent gateway trigger-schedule endpoint.
-func (r *Resolver) TriggerSchedule(ctx context.Context, botID string, payload schedule.TriggerPayload, token string) error {
+func (r *Resolver) TriggerSchedule(ctx context.Context, botID string, payload schedule.TriggerPayload, token string) (schedule.TriggerResult, error) {
 	r.logger.Info("TriggerSchedule: starting",
 		slog.String("bot_id", botID),
 		slog.String("schedule_id", payload.ID),
@@ -1346,12 +1413,12 @@ func (r *Resolver) TriggerSchedule(ctx context.Context, botID string, payload sc
 		slog.String("reply_target", payload.ReplyTarget),
 	)
 	if strings.TrimSpace(botID) == "" {
-		return fmt.Errorf("bot id is required")
+		return schedule.TriggerResult{}, fmt.Errorf("bot id is required")
 	}
 	if strings.TrimSpace(payload.Command) == "" {
-		return fmt.Errorf("schedule command is required")
+		return schedule.TriggerResult{}, fmt.Errorf("schedule command is required")
 	}
-	err := r.executeTrigger(ctx, triggerParams{
+	result, err := r.executeTrigger(ctx, triggerParams{
 		botID:       botID,
 		query:       payload.Command,
 		ownerUserID: payload.OwnerUserID,
@@ -1371,9 +1438,9 @@ func (r *Resolver) TriggerSchedule(ctx context.Context, botID string, payload sc
 	}, token)
 	if err != nil {
 		r.logger.Warn("TriggerSchedule: failed", slog.String("bot_id", botID), slog.Any("error", err))
-		return err
+		return result, err
 	}
-	return nil
+	return result, nil
 }
 
 // TriggerHeartbeat executes a heartbeat command through the agent gateway trigger-schedule endpoint.
@@ -1386,7 +1453,7 @@ func (r *Resolver) TriggerHeartbeat(ctx context.Context, botID string, payload h
 	if strings.TrimSpace(payload.Prompt) == "" {
 		return fmt.Errorf("heartbeat prompt is required")
 	}
-	err := r.executeTrigger(ctx, triggerParams{
+	_, err := r.executeTrigger(ctx, triggerParams{
 		botID:       botID,
 		query:       payload.Prompt,
 		ownerUserID: payload.OwnerUserID,
@@ -1446,7 +1513,7 @@ func (r *Resolver) completeEvolutionLogFromResponse(ctx context.Context, logID s
 		summary = summary[:500] + "..."
 	}
 
-	_, completeErr := r.queries.CompleteEvolutionLog(ctx, sqlc.CompleteEvolutionLogParams{
+	row, completeErr := r.queries.CompleteEvolutionLog(ctx, sqlc.CompleteEvolutionLogParams{
 		ID:             pgLogID,
 		Status:         status,
 		ChangesSummary: pgtype.Text{String: summary, Valid: summary != ""},
@@ -1456,7 +1523,9 @@ func (r *Resolver) completeEvolutionLogFromResponse(ctx context.Context, logID s
 	if completeErr != nil {
 		r.logger.Warn("failed to complete evolution log",
 			slog.String("log_id", logID), slog.Any("error", completeErr))
+		return
 	}
+	r.publishEvolutionCompleted(row)
 }
 
 // completeEvolutionLogOnError marks an evolution log as failed.
@@ -1470,7 +1539,7 @@ func (r *Resolver) completeEvolutionLogOnError(ctx context.Context, logID string
 		return
 	}
 	errMsg := triggerErr.Error()
-	_, dbErr := r.queries.CompleteEvolutionLog(ctx, sqlc.CompleteEvolutionLogParams{
+	row, dbErr := r.queries.CompleteEvolutionLog(ctx, sqlc.CompleteEvolutionLogParams{
 		ID:             pgLogID,
 		Status:         "failed",
 		ChangesSummary: pgtype.Text{String: "Error: " + errMsg, Valid: true},
@@ -1479,7 +1548,16 @@ func (r *Resolver) completeEvolutionLogOnError(ctx context.Context, logID string
 	})
 	if dbErr != nil {
 		r.logger.Warn("completeEvolutionLogOnError: DB update failed", slog.String("log_id", logID), slog.Any("error", dbErr))
+		return
+	}
+	r.publishEvolutionCompleted(row)
+}
+
+func (r *Resolver) publishEvolutionCompleted(row sqlc.EvolutionLog) {
+	if r.eventPublisher == nil {
+		return
 	}
+	r.eventPublisher.Publish(heartbeat.EvolutionCompletedEvent(row))
 }
 
 // --- StreamChat ---
@@ -1498,6 +1576,20 @@ func (r *Resolver) StreamChat(ctx context.Context, req conversation.ChatRequest)
 		defer close(errCh)
 
 		streamReq := req
+		breach, err := r.checkBudget(ctx, req.BotID, req.ChatID, req.UserID, req.CurrentChannel, req.SourceChannelIdentityID, req.TaskType)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		if breach != nil {
+			if budget.ClassOf(req.TaskType) == budget.ClassBackground {
+				errCh <- budgetError(*breach)
+				return
+			}
+			r.streamBudgetRefusal(ctx, chunkCh, *breach)
+			return
+		}
+
 		rc, err := r.resolve(ctx, streamReq)
 		if err != nil {
 			r.logger.Error("gateway stream resolve failed",
@@ -2110,7 +2202,7 @@ func (r *Resolver) logToolCallEvent(ctx context.Context, req conversation.ChatRe
 // tryStoreStream attempts to extract final messages from a stream event and persist them.
 func (r *Resolver) tryStoreStream(ctx context.Context, req conversation.ChatRequest, eventType, data, modelID, traceID string) (bool, error) {
 	record := func(usage *gatewayUsage) {
-		r.recordTokenUsage(ctx, req.BotID, usage, modelID, "chat")
+		r.recordTokenUsage(ctx, req.BotID, req.SourceChannelIdentityID, usage, modelID, usageSource(req.TaskType))
 	}
 
 	// event: done + data: {messages: [...]}
@@ -2341,13 +2433,17 @@ func (r *Resolver) loadMemoryContextMessage(ctx context.Context, req conversatio
 		"scopeId":   req.BotID,
 		"bot_id":    req.BotID,
 	}
+	// Only recall memories this conversation may see: private facts stay in
+	// the DM they were told in, group facts in their group.
+	audience := memoryAudience(req)
 
 	resp, err := r.memoryService.Search(ctx, memory.SearchRequest{
-		Query:   req.Query,
-		BotID:   req.BotID,
-		Limit:   memoryContextLimitPerScope,
-		Filters: filters,
-		NoStats: true,
+		Query:    req.Query,
+		BotID:    req.BotID,
+		Limit:    memoryContextLimitPerScope,
+		Filters:  filters,
+		NoStats:  true,
+		Audience: audience,
 	})
 	if err != nil {
 		r.logger.Warn("memory search for context failed",
@@ -2366,11 +2462,12 @@ func (r *Resolver) loadMemoryContextMessage(ctx context.Context, req conversatio
 	if kw := extractKeywords(req.Query); kw != "" && kw != strings.ToLower(req.Query) {
 		expandedKeywords = kw
 		kwResp, kwErr := r.memoryService.Search(ctx, memory.SearchRequest{
-			Query:   kw,
-			BotID:   req.BotID,
-			Limit:   memoryContextLimitPerScope,
-			Filters: filters,
-			NoStats: true,
+			Query:    kw,
+			BotID:    req.BotID,
+			Limit:    memoryContextLimitPerScope,
+			Filters:  filters,
+			NoStats:  true,
+			Audience: audience,
 		})
 		if kwErr == nil && len(kwResp.Results) > 0 {
 			kwCount = len(kwResp.Results)
@@ -2579,11 +2676,22 @@ func (r *Resolver) persistUserMessage(ctx context.Context, req conversation.Chat
 }
 
 // memoryTraceCtx carries process-log context into the storeMemory goroutine.
+// messageIDs and externalMessageID identify the persisted round and become
+// the provenance of every memory extracted from it; audience decides the
+// sensitivity label of those memories.
 type memoryTraceCtx struct {
-	traceID string
-	chatID  string
-	userID  string
-	channel string
+	traceID           string
+	chatID            string
+	userID            string
+	channel           string
+	messageIDs        []string
+	externalMessageID string
+	audience          *memory.Audience
+}
+
+// memoryAudience describes the conversation of req for memory sensitivity.
+func memoryAudience(req conversation.ChatRequest) *memory.Audience {
+	return memory.NewAudience(req.ConversationType, req.CurrentChannel, req.ReplyTarget, req.SourceChannelIdentityID)
 }
 
 func (r *Resolver) storeRound(ctx context.Context, req conversation.ChatRequest, messages []conversation.ModelMessage, usage ...*gatewayUsage) error {
@@ -2671,7 +2779,7 @@ func (r *Resolver) storeRoundWithTrace(ctx context.Context, req conversation.Cha
 		}
 	}
 
-	r.storeMessages(ctx, req, fullRound, usagePtr)
+	messageIDs := r.storeMessages(ctx, req, fullRound, usagePtr)
 
 	// For memory extraction, always include the user's query so the LLM
 	// can extract facts from what the user said. fullRound may have
@@ -2688,10 +2796,13 @@ func (r *Resolver) storeRoundWithTrace(ctx context.Context, req conversation.Cha
 	}
 
 	mtc := memoryTraceCtx{
-		traceID: traceID,
-		chatID:  req.ChatID,
-		userID:  req.UserID,
-		channel: req.CurrentChannel,
+		traceID:           traceID,
+		chatID:            req.ChatID,
+		userID:            req.UserID,
+		channel:           req.CurrentChannel,
+		messageIDs:        messageIDs,
+		externalMessageID: req.ExternalMessageID,
+		audience:          memoryAudience(req),
 	}
 	go func() {
 		defer func() {
@@ -2748,12 +2859,13 @@ func (r *Resolver) storeRoundWithTrace(ctx context.Context, req conversation.Cha
 	return nil
 }
 
-func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatRequest, messages []conversation.ModelMessage, usage *gatewayUsage) {
+// storeMessages persists the round and returns the IDs of the stored messages.
+func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatRequest, messages []conversation.ModelMessage, usage *gatewayUsage) []string {
 	if r.messageService == nil {
-		return
+		return nil
 	}
 	if strings.TrimSpace(req.BotID) == "" {
-		return
+		return nil
 	}
 	meta := buildRouteMetadata(req)
 
@@ -2771,6 +2883,7 @@ func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatReque
 	}
 
 	senderChannelIdentityID, senderUserID := r.resolvePersistSenderIDs(ctx, req)
+	messageIDs := make([]string, 0, len(messages))
 	for i, msg := range messages {
 		content, err := json.Marshal(msg)
 		if err != nil {
@@ -2810,7 +2923,7 @@ func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatReque
 			}
 		}
 
-		if _, err := r.messageService.Persist(ctx, messagepkg.PersistInput{
+		persisted, err := r.messageService.Persist(ctx, messagepkg.PersistInput{
 			BotID:                   req.BotID,
 			RouteID:                 req.RouteID,
 			SenderChannelIdentityID: messageSenderChannelIdentityID,
@@ -2821,10 +2934,16 @@ func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatReque
 			Role:                    msg.Role,
 			Content:                 content,
 			Metadata:                msgMeta,
-		}); err != nil {
+		})
+		if err != nil {
 			r.logger.Warn("persist message failed", slog.Any("error", err))
+			continue
+		}
+		if persisted.ID != "" {
+			messageIDs = append(messageIDs, persisted.ID)
 		}
 	}
+	return messageIDs
 }
 
 // copyMap returns a shallow copy of the input map (nil-safe).
@@ -3058,6 +3177,14 @@ func (r *Resolver) addMemory(ctx context.Context, botID string, msgs []memory.Me
 		Messages: msgs,
 		BotID:    botID,
 		Filters:  filters,
+		Provenance: &memory.Provenance{
+			MessageIDs:        mtc.messageIDs,
+			ExternalMessageID: mtc.externalMessageID,
+			ChatID:            mtc.chatID,
+			TraceID:           mtc.traceID,
+			Channel:           mtc.channel,
+		},
+		Audience: mtc.audience,
 	})
 	durationMs := int(time.Since(start).Milliseconds())
 	if err != nil {
@@ -3990,7 +4117,7 @@ func (r *Resolver) asyncSummarize(
 			r.logger.Warn("summarize request failed", slog.String("bot_id", botID), slog.Any("error", err))
 			return
 		}
-		r.recordTokenUsage(ctx, botID, usage, chatModel.ModelID, "summarize")
+		r.recordTokenUsage(ctx, botID, "", usage, chatModel.ModelID, "summarize")
 		if strings.TrimSpace(summary) == "" {
 			return
 		}
@@ -4061,8 +4188,13 @@ func (r *Resolver) postSummarize(ctx context.Context, model gatewayModelConfig,
 
 // --- Token Usage ---
 
+// tokenCostPlaces is the scale of token_usage.cost (NUMERIC(20,10)).
+const tokenCostPlaces = 10
+
 // recordTokenUsage persists token usage asynchronously so it never blocks the response.
-func (r *Resolver) recordTokenUsage(ctx context.Context, botID string, usage *gatewayUsage, model, source string) {
+// channelIdentityID is the sender the usage is billed to, if any. The cost is
+// priced from the model's configured rates at the time of the call.
+func (r *Resolver) recordTokenUsage(ctx context.Context, botID, channelIdentityID string, usage *gatewayUsage, model, source string) {
 	if usage == nil || usage.TotalTokens == 0 {
 		return
 	}
@@ -4073,19 +4205,105 @@ func (r *Resolver) recordTokenUsage(ctx context.Context, botID string, usage *ga
 		if err != nil {
 			return
 		}
+		var channelIdentityUUID pgtype.UUID
+		if strings.TrimSpace(channelIdentityID) != "" {
+			if parsed, err := db.ParseUUID(channelIdentityID); err == nil {
+				channelIdentityUUID = parsed
+			}
+		}
+		cost := db.NumericFromFloat(0, tokenCostPlaces)
+		if r.modelsService != nil && strings.TrimSpace(model) != "" {
+			if priced, err := r.modelsService.GetByModelID(bgCtx, model); err == nil {
+				cost = db.NumericFromFloat(priced.Cost(usage.PromptTokens, usage.CompletionTokens, usage.CachedTokens), tokenCostPlaces)
+			}
+		}
 		if _, err := r.queries.RecordTokenUsage(bgCtx, sqlc.RecordTokenUsageParams{
-			BotID:            botUUID,
-			PromptTokens:     int32(usage.PromptTokens),
-			CompletionTokens: int32(usage.CompletionTokens),
-			TotalTokens:      int32(usage.TotalTokens),
-			Model:            model,
-			Source:           source,
+			BotID:             botUUID,
+			PromptTokens:      int32(usage.PromptTokens),
+			CompletionTokens:  int32(usage.CompletionTokens),
+			TotalTokens:       int32(usage.TotalTokens),
+			Model:             model,
+			Source:            source,
+			ChannelIdentityID: channelIdentityUUID,
+			CachedTokens:      int32(usage.CachedTokens),
+			Cost:              cost,
 		}); err != nil {
 			r.logger.Warn("record token usage failed", slog.String("bot_id", botID), slog.Any("error", err))
 		}
 	}()
 }
 
+// usageSource returns the token_usage source of a chat request. Background
+// task types are recorded as-is so they count against background budgets.
+func usageSource(taskType string) string {
+	if budget.ClassOf(taskType) == budget.ClassBackground {
+		return strings.ToLower(strings.TrimSpace(taskType))
+	}
+	return "chat"
+}
+
+// checkBudget returns the exhausted token budget that refuses a request, or
+// nil when it may proceed. When the check itself fails, interactive requests
+// go through, while background runs (schedules, heartbeats, subagents) fail
+// closed with an error so a broken check cannot lift their hard limits.
+func (r *Resolver) checkBudget(ctx context.Context, botID, chatID, userID, channel, channelIdentityID, taskType string) (*budget.Breach, error) {
+	if r.budgetChecker == nil {
+		return nil, nil
+	}
+	decision, err := r.budgetChecker.Check(ctx, budget.Subject{
+		BotID:             botID,
+		ChannelIdentityID: channelIdentityID,
+		Class:             budget.ClassOf(taskType),
+	})
+	if err != nil {
+		r.logger.Warn("token budget check failed", slog.String("bot_id", botID), slog.Any("error", err))
+		if budget.ClassOf(taskType) == budget.ClassBackground {
+			return nil, fmt.Errorf("%w: %v", budget.ErrCheckFailed, err)
+		}
+		return nil, nil
+	}
+	if decision.Allowed() {
+		return nil, nil
+	}
+	breach := decision.Blocked
+	r.logProcessStep(ctx, botID, chatID, "", userID, channel,
+		processlog.StepQuotaChecked, processlog.LevelWarn, "Token budget exhausted",
+		map[string]any{
+			"scope":      string(breach.Scope),
+			"task_class": string(breach.Class),
+			"period":     string(breach.Period),
+			"used":       breach.Used,
+			"limit":      breach.Limit,
+		}, 0)
+	return breach, nil
+}
+
+// budgetError is returned to background runs refused by a token budget.
+func budgetError(b budget.Breach) error {
+	return fmt.Errorf("%w: %s %s limit of %d tokens reached (%s)", budget.ErrExceeded, b.Period, b.Scope, b.Limit, b.Class)
+}
+
+// budgetRefusalMessages is the reply given instead of calling the gateway
+// when an interactive request is refused by a token budget.
+func budgetRefusalMessages(b budget.Breach) []conversation.ModelMessage {
+	content, _ := json.Marshal(budget.RefusalMessage(b))
+	return []conversation.ModelMessage{{Role: "assistant", Content: content}}
+}
+
+// streamBudgetRefusal emits the refusal as gateway stream events so stream
+// consumers render it like a normal reply.
+func (r *Resolver) streamBudgetRefusal(ctx context.Context, chunkCh chan<- conversation.StreamChunk, b budget.Breach) {
+	delta, _ := json.Marshal(map[string]any{"type": "text_delta", "delta": budget.RefusalMessage(b)})
+	end, _ := json.Marshal(map[string]any{"type": "agent_end", "messages": budgetRefusalMessages(b)})
+	for _, chunk := range []conversation.StreamChunk{delta, end} {
+		select {
+		case chunkCh <- chunk:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // toTokenUsage converts internal usage to the public type.
 func toTokenUsage(u *gatewayUsage) *conversation.TokenUsage {
 	if u == nil {
@@ -6,6 +6,15 @@ import (
 	"net"
 	"strings"
 	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+)
+
+var (
+	gatewayCallDuration = metrics.NewHistogram("memoh_gateway_call_duration_seconds",
+		"Agent gateway call latency including retries.", nil, "op", "outcome")
+	gatewayRetries = metrics.NewCounter("memoh_gateway_retries_total",
+		"Agent gateway call retries after a transient error.", "op")
 )
 
 // gatewayHTTPError is a typed error returned by postChat and postTriggerSchedule
@@ -22,14 +31,25 @@ func (e *gatewayHTTPError) Error() string {
 
 // withGatewayRetry retries fn up to 3 total attempts (2 retries) with exponential
 // backoff (300 ms, 600 ms). Only transient errors are retried; context cancellation
-// and non-retryable HTTP errors are returned immediately.
-func withGatewayRetry(ctx context.Context, fn func() error) error {
+// and non-retryable HTTP errors are returned immediately. op labels the call in
+// metrics.
+func withGatewayRetry(ctx context.Context, op string, fn func() error) (err error) {
+	start := time.Now()
+	defer func() {
+		outcome := "ok"
+		if err != nil {
+			outcome = "error"
+		}
+		gatewayCallDuration.ObserveSince(start, op, outcome)
+	}()
+
 	const maxAttempts = 3
 	baseDelay := 300 * time.Millisecond
 
 	var lastErr error
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		if attempt > 0 {
+			gatewayRetries.Inc(op)
 			delay := baseDelay * time.Duration(1<<(attempt-1)) // 300 ms, 600 ms
 			select {
 			case <-time.After(delay):
@@ -38,7 +58,7 @@ func withGatewayRetry(ctx context.Context, fn func() error) error {
 			}
 		}
 
-		err := fn()
+		err = fn()
 		if err == nil {
 			return nil
 		}
@@ -45,6 +45,18 @@ type Bot struct {
 	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
 }
 
+type BotApiKey struct {
+	ID              pgtype.UUID        `json:"id"`
+	BotID           pgtype.UUID        `json:"bot_id"`
+	Name            string             `json:"name"`
+	KeyPrefix       string             `json:"key_prefix"`
+	KeyHash         string             `json:"key_hash"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	LastUsedAt      pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	Scopes          []string           `json:"scopes"`
+}
+
 type BotCallLog struct {
 	ID          pgtype.UUID        `json:"id"`
 	CallerBotID pgtype.UUID        `json:"caller_bot_id"`
@@ -136,6 +148,19 @@ type BotTeamMember struct {
 	CreatedAt       pgtype.Timestamptz `json:"created_at"`
 }
 
+type BotWebhook struct {
+	ID              pgtype.UUID        `json:"id"`
+	BotID           pgtype.UUID        `json:"bot_id"`
+	Name            string             `json:"name"`
+	Url             string             `json:"url"`
+	Secret          string             `json:"secret"`
+	Events          []string           `json:"events"`
+	Enabled         bool               `json:"enabled"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
 type BuiltinToolConfig struct {
 	ID        pgtype.UUID        `json:"id"`
 	BotID     pgtype.UUID        `json:"bot_id"`
@@ -286,19 +311,22 @@ type McpConnection struct {
 }
 
 type Model struct {
-	ID              pgtype.UUID        `json:"id"`
-	ModelID         string             `json:"model_id"`
-	Name            pgtype.Text        `json:"name"`
-	LlmProviderID   pgtype.UUID        `json:"llm_provider_id"`
-	Dimensions      pgtype.Int4        `json:"dimensions"`
-	IsMultimodal    bool               `json:"is_multimodal"`
-	Type            string             `json:"type"`
-	ContextWindow   int32              `json:"context_window"`
-	FallbackModelID pgtype.UUID        `json:"fallback_model_id"`
-	Reasoning       bool               `json:"reasoning"`
-	MaxTokens       int32              `json:"max_tokens"`
-	CreatedAt       pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	ID               pgtype.UUID        `json:"id"`
+	ModelID          string             `json:"model_id"`
+	Name             pgtype.Text        `json:"name"`
+	LlmProviderID    pgtype.UUID        `json:"llm_provider_id"`
+	Dimensions       pgtype.Int4        `json:"dimensions"`
+	IsMultimodal     bool               `json:"is_multimodal"`
+	Type             string             `json:"type"`
+	ContextWindow    int32              `json:"context_window"`
+	FallbackModelID  pgtype.UUID        `json:"fallback_model_id"`
+	Reasoning        bool               `json:"reasoning"`
+	MaxTokens        int32              `json:"max_tokens"`
+	InputPrice       float64            `json:"input_price"`
+	OutputPrice      float64            `json:"output_price"`
+	CachedInputPrice float64            `json:"cached_input_price"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
 
 type ModelVariant struct {
@@ -311,6 +339,18 @@ type ModelVariant struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type PersonalAccessToken struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	Name        string             `json:"name"`
+	TokenPrefix string             `json:"token_prefix"`
+	TokenHash   string             `json:"token_hash"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type ProcessLog struct {
 	ID         pgtype.UUID        `json:"id"`
 	BotID      pgtype.UUID        `json:"bot_id"`
@@ -327,19 +367,50 @@ type ProcessLog struct {
 }
 
 type Schedule struct {
-	ID           pgtype.UUID        `json:"id"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	Pattern      string             `json:"pattern"`
-	MaxCalls     pgtype.Int4        `json:"max_calls"`
-	CurrentCalls int32              `json:"current_calls"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
-	Enabled      bool               `json:"enabled"`
-	Command      string             `json:"command"`
-	BotID        pgtype.UUID        `json:"bot_id"`
-	Platform     string             `json:"platform"`
-	ReplyTarget  string             `json:"reply_target"`
+	ID                  pgtype.UUID        `json:"id"`
+	Name                string             `json:"name"`
+	Description         string             `json:"description"`
+	Pattern             string             `json:"pattern"`
+	MaxCalls            pgtype.Int4        `json:"max_calls"`
+	CurrentCalls        int32              `json:"current_calls"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	Enabled             bool               `json:"enabled"`
+	Command             string             `json:"command"`
+	BotID               pgtype.UUID        `json:"bot_id"`
+	Platform            string             `json:"platform"`
+	ReplyTarget         string             `json:"reply_target"`
+	MisfirePolicy       string             `json:"misfire_policy"`
+	RetryMaxAttempts    int32              `json:"retry_max_attempts"`
+	RetryBackoffSeconds int32              `json:"retry_backoff_seconds"`
+	Kind                string             `json:"kind"`
+	RunAt               pgtype.Timestamptz `json:"run_at"`
+	IntervalSeconds     pgtype.Int4        `json:"interval_seconds"`
+	StartAt             pgtype.Timestamptz `json:"start_at"`
+	Timezone            string             `json:"timezone"`
+	ArchivedAt          pgtype.Timestamptz `json:"archived_at"`
+}
+
+type ScheduleRun struct {
+	ID               pgtype.UUID        `json:"id"`
+	ScheduleID       pgtype.UUID        `json:"schedule_id"`
+	BotID            pgtype.UUID        `json:"bot_id"`
+	TriggerType      string             `json:"trigger_type"`
+	Status           string             `json:"status"`
+	Error            string             `json:"error"`
+	TraceID          string             `json:"trace_id"`
+	Model            string             `json:"model"`
+	PromptTokens     int32              `json:"prompt_tokens"`
+	CompletionTokens int32              `json:"completion_tokens"`
+	TotalTokens      int32              `json:"total_tokens"`
+	ReplyPreview     string             `json:"reply_preview"`
+	Platform         string             `json:"platform"`
+	ReplyTarget      string             `json:"reply_target"`
+	StartedAt        pgtype.Timestamptz `json:"started_at"`
+	FinishedAt       pgtype.Timestamptz `json:"finished_at"`
+	DurationMs       pgtype.Int4        `json:"duration_ms"`
+	Attempt          int32              `json:"attempt"`
+	ScheduledFor     pgtype.Timestamptz `json:"scheduled_for"`
 }
 
 type SearchProvider struct {
@@ -391,14 +462,17 @@ type SubagentRun struct {
 }
 
 type TokenUsage struct {
-	ID               pgtype.UUID        `json:"id"`
-	BotID            pgtype.UUID        `json:"bot_id"`
-	PromptTokens     int32              `json:"prompt_tokens"`
-	CompletionTokens int32              `json:"completion_tokens"`
-	TotalTokens      int32              `json:"total_tokens"`
-	Model            string             `json:"model"`
-	Source           string             `json:"source"`
-	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	ID                pgtype.UUID        `json:"id"`
+	BotID             pgtype.UUID        `json:"bot_id"`
+	PromptTokens      int32              `json:"prompt_tokens"`
+	CompletionTokens  int32              `json:"completion_tokens"`
+	TotalTokens       int32              `json:"total_tokens"`
+	Model             string             `json:"model"`
+	Source            string             `json:"source"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	ChannelIdentityID pgtype.UUID        `json:"channel_identity_id"`
+	CachedTokens      int32              `json:"cached_tokens"`
+	Cost              pgtype.Numeric     `json:"cost"`
 }
 
 type User struct {
@@ -425,3 +499,28 @@ type UserChannelBinding struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
+
+type UserSsoIdentity struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Issuer    string             `json:"issuer"`
+	Subject   string             `json:"subject"`
+	Email     string             `json:"email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             pgtype.UUID        `json:"id"`
+	WebhookID      pgtype.UUID        `json:"webhook_id"`
+	BotID          pgtype.UUID        `json:"bot_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	LastStatusCode int32              `json:"last_status_code"`
+	LastError      string             `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
@@ -98,7 +98,7 @@ func (q *Queries) CreateLlmProvider(ctx context.Context, arg CreateLlmProviderPa
 }
 
 const createModel = `-- name: CreateModel :one
-INSERT INTO models (model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens)
+INSERT INTO models (model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price)
 VALUES (
   $1,
   $2,
@@ -109,22 +109,28 @@ VALUES (
   $7,
   $8,
   $9,
-  $10
+  $10,
+  $11,
+  $12,
+  $13
 )
-RETURNING id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at
+RETURNING id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at
 `
 
 type CreateModelParams struct {
-	ModelID         string      `json:"model_id"`
-	Name            pgtype.Text `json:"name"`
-	LlmProviderID   pgtype.UUID `json:"llm_provider_id"`
-	Dimensions      pgtype.Int4 `json:"dimensions"`
-	IsMultimodal    bool        `json:"is_multimodal"`
-	Type            string      `json:"type"`
-	ContextWindow   int32       `json:"context_window"`
-	FallbackModelID pgtype.UUID `json:"fallback_model_id"`
-	Reasoning       bool        `json:"reasoning"`
-	MaxTokens       int32       `json:"max_tokens"`
+	ModelID          string      `json:"model_id"`
+	Name             pgtype.Text `json:"name"`
+	LlmProviderID    pgtype.UUID `json:"llm_provider_id"`
+	Dimensions       pgtype.Int4 `json:"dimensions"`
+	IsMultimodal     bool        `json:"is_multimodal"`
+	Type             string      `json:"type"`
+	ContextWindow    int32       `json:"context_window"`
+	FallbackModelID  pgtype.UUID `json:"fallback_model_id"`
+	Reasoning        bool        `json:"reasoning"`
+	MaxTokens        int32       `json:"max_tokens"`
+	InputPrice       float64     `json:"input_price"`
+	OutputPrice      float64     `json:"output_price"`
+	CachedInputPrice float64     `json:"cached_input_price"`
 }
 
 func (q *Queries) CreateModel(ctx context.Context, arg CreateModelParams) (Model, error) {
@@ -139,6 +145,9 @@ func (q *Queries) CreateModel(ctx context.Context, arg CreateModelParams) (Model
 		arg.FallbackModelID,
 		arg.Reasoning,
 		arg.MaxTokens,
+		arg.InputPrice,
+		arg.OutputPrice,
+		arg.CachedInputPrice,
 	)
 	var i Model
 	err := row.Scan(
@@ -153,6 +162,9 @@ func (q *Queries) CreateModel(ctx context.Context, arg CreateModelParams) (Model
 		&i.FallbackModelID,
 		&i.Reasoning,
 		&i.MaxTokens,
+		&i.InputPrice,
+		&i.OutputPrice,
+		&i.CachedInputPrice,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -265,7 +277,7 @@ func (q *Queries) GetLlmProviderByName(ctx context.Context, name string) (LlmPro
 }
 
 const getModelByID = `-- name: GetModelByID :one
-SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at FROM models WHERE id = $1
+SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at FROM models WHERE id = $1
 `
 
 func (q *Queries) GetModelByID(ctx context.Context, id pgtype.UUID) (Model, error) {
@@ -283,6 +295,9 @@ func (q *Queries) GetModelByID(ctx context.Context, id pgtype.UUID) (Model, erro
 		&i.FallbackModelID,
 		&i.Reasoning,
 		&i.MaxTokens,
+		&i.InputPrice,
+		&i.OutputPrice,
+		&i.CachedInputPrice,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -290,7 +305,7 @@ func (q *Queries) GetModelByID(ctx context.Context, id pgtype.UUID) (Model, erro
 }
 
 const getModelByModelID = `-- name: GetModelByModelID :one
-SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at FROM models WHERE model_id = $1
+SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at FROM models WHERE model_id = $1
 `
 
 func (q *Queries) GetModelByModelID(ctx context.Context, modelID string) (Model, error) {
@@ -308,6 +323,9 @@ func (q *Queries) GetModelByModelID(ctx context.Context, modelID string) (Model,
 		&i.FallbackModelID,
 		&i.Reasoning,
 		&i.MaxTokens,
+		&i.InputPrice,
+		&i.OutputPrice,
+		&i.CachedInputPrice,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -315,7 +333,7 @@ func (q *Queries) GetModelByModelID(ctx context.Context, modelID string) (Model,
 }
 
 const getModelFallback = `-- name: GetModelFallback :one
-SELECT fallback.id, fallback.model_id, fallback.name, fallback.llm_provider_id, fallback.dimensions, fallback.is_multimodal, fallback.type, fallback.context_window, fallback.fallback_model_id, fallback.reasoning, fallback.max_tokens, fallback.created_at, fallback.updated_at FROM models AS fallback
+SELECT fallback.id, fallback.model_id, fallback.name, fallback.llm_provider_id, fallback.dimensions, fallback.is_multimodal, fallback.type, fallback.context_window, fallback.fallback_model_id, fallback.reasoning, fallback.max_tokens, fallback.input_price, fallback.output_price, fallback.cached_input_price, fallback.created_at, fallback.updated_at FROM models AS fallback
 JOIN models AS primary_model ON primary_model.fallback_model_id = fallback.id
 WHERE primary_model.id = $1
 `
@@ -335,6 +353,9 @@ func (q *Queries) GetModelFallback(ctx context.Context, primaryModelID pgtype.UU
 		&i.FallbackModelID,
 		&i.Reasoning,
 		&i.MaxTokens,
+		&i.InputPrice,
+		&i.OutputPrice,
+		&i.CachedInputPrice,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -445,7 +466,7 @@ func (q *Queries) ListModelVariantsByModelUUID(ctx context.Context, modelUuid pg
 }
 
 const listModels = `-- name: ListModels :many
-SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at FROM models
+SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at FROM models
 ORDER BY created_at DESC
 `
 
@@ -470,6 +491,9 @@ func (q *Queries) ListModels(ctx context.Context) ([]Model, error) {
 			&i.FallbackModelID,
 			&i.Reasoning,
 			&i.MaxTokens,
+			&i.InputPrice,
+			&i.OutputPrice,
+			&i.CachedInputPrice,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -484,7 +508,7 @@ func (q *Queries) ListModels(ctx context.Context) ([]Model, error) {
 }
 
 const listModelsByClientType = `-- name: ListModelsByClientType :many
-SELECT m.id, m.model_id, m.name, m.llm_provider_id, m.dimensions, m.is_multimodal, m.type, m.context_window, m.fallback_model_id, m.reasoning, m.max_tokens, m.created_at, m.updated_at FROM models AS m
+SELECT m.id, m.model_id, m.name, m.llm_provider_id, m.dimensions, m.is_multimodal, m.type, m.context_window, m.fallback_model_id, m.reasoning, m.max_tokens, m.input_price, m.output_price, m.cached_input_price, m.created_at, m.updated_at FROM models AS m
 JOIN llm_providers AS p ON p.id = m.llm_provider_id
 WHERE p.client_type = $1
 ORDER BY m.created_at DESC
@@ -511,6 +535,9 @@ func (q *Queries) ListModelsByClientType(ctx context.Context, clientType string)
 			&i.FallbackModelID,
 			&i.Reasoning,
 			&i.MaxTokens,
+			&i.InputPrice,
+			&i.OutputPrice,
+			&i.CachedInputPrice,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -525,7 +552,7 @@ func (q *Queries) ListModelsByClientType(ctx context.Context, clientType string)
 }
 
 const listModelsByProviderID = `-- name: ListModelsByProviderID :many
-SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at FROM models
+SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at FROM models
 WHERE llm_provider_id = $1
 ORDER BY created_at DESC
 `
@@ -551,6 +578,9 @@ func (q *Queries) ListModelsByProviderID(ctx context.Context, llmProviderID pgty
 			&i.FallbackModelID,
 			&i.Reasoning,
 			&i.MaxTokens,
+			&i.InputPrice,
+			&i.OutputPrice,
+			&i.CachedInputPrice,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -565,7 +595,7 @@ func (q *Queries) ListModelsByProviderID(ctx context.Context, llmProviderID pgty
 }
 
 const listModelsByProviderIDAndType = `-- name: ListModelsByProviderIDAndType :many
-SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at FROM models
+SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at FROM models
 WHERE llm_provider_id = $1
   AND type = $2
 ORDER BY created_at DESC
@@ -597,6 +627,9 @@ func (q *Queries) ListModelsByProviderIDAndType(ctx context.Context, arg ListMod
 			&i.FallbackModelID,
 			&i.Reasoning,
 			&i.MaxTokens,
+			&i.InputPrice,
+			&i.OutputPrice,
+			&i.CachedInputPrice,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -611,7 +644,7 @@ func (q *Queries) ListModelsByProviderIDAndType(ctx context.Context, arg ListMod
 }
 
 const listModelsByType = `-- name: ListModelsByType :many
-SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at FROM models
+SELECT id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at FROM models
 WHERE type = $1
 ORDER BY created_at DESC
 `
@@ -637,6 +670,9 @@ func (q *Queries) ListModelsByType(ctx context.Context, type_ string) ([]Model,
 			&i.FallbackModelID,
 			&i.Reasoning,
 			&i.MaxTokens,
+			&i.InputPrice,
+			&i.OutputPrice,
+			&i.CachedInputPrice,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -707,22 +743,28 @@ SET
   fallback_model_id = $7,
   reasoning = $8,
   max_tokens = $9,
+  input_price = $10,
+  output_price = $11,
+  cached_input_price = $12,
   updated_at = now()
-WHERE id = $10
-RETURNING id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at
+WHERE id = $13
+RETURNING id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at
 `
 
 type UpdateModelParams struct {
-	Name            pgtype.Text `json:"name"`
-	LlmProviderID   pgtype.UUID `json:"llm_provider_id"`
-	Dimensions      pgtype.Int4 `json:"dimensions"`
-	IsMultimodal    bool        `json:"is_multimodal"`
-	Type            string      `json:"type"`
-	ContextWindow   int32       `json:"context_window"`
-	FallbackModelID pgtype.UUID `json:"fallback_model_id"`
-	Reasoning       bool        `json:"reasoning"`
-	MaxTokens       int32       `json:"max_tokens"`
-	ID              pgtype.UUID `json:"id"`
+	Name             pgtype.Text `json:"name"`
+	LlmProviderID    pgtype.UUID `json:"llm_provider_id"`
+	Dimensions       pgtype.Int4 `json:"dimensions"`
+	IsMultimodal     bool        `json:"is_multimodal"`
+	Type             string      `json:"type"`
+	ContextWindow    int32       `json:"context_window"`
+	FallbackModelID  pgtype.UUID `json:"fallback_model_id"`
+	Reasoning        bool        `json:"reasoning"`
+	MaxTokens        int32       `json:"max_tokens"`
+	InputPrice       float64     `json:"input_price"`
+	OutputPrice      float64     `json:"output_price"`
+	CachedInputPrice float64     `json:"cached_input_price"`
+	ID               pgtype.UUID `json:"id"`
 }
 
 func (q *Queries) UpdateModel(ctx context.Context, arg UpdateModelParams) (Model, error) {
@@ -736,6 +778,9 @@ func (q *Queries) UpdateModel(ctx context.Context, arg UpdateModelParams) (Model
 		arg.FallbackModelID,
 		arg.Reasoning,
 		arg.MaxTokens,
+		arg.InputPrice,
+		arg.OutputPrice,
+		arg.CachedInputPrice,
 		arg.ID,
 	)
 	var i Model
@@ -751,6 +796,9 @@ func (q *Queries) UpdateModel(ctx context.Context, arg UpdateModelParams) (Model
 		&i.FallbackModelID,
 		&i.Reasoning,
 		&i.MaxTokens,
+		&i.InputPrice,
+		&i.OutputPrice,
+		&i.CachedInputPrice,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -770,23 +818,29 @@ SET
   fallback_model_id = $8,
   reasoning = $9,
   max_tokens = $10,
+  input_price = $11,
+  output_price = $12,
+  cached_input_price = $13,
   updated_at = now()
-WHERE model_id = $11
-RETURNING id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, created_at, updated_at
+WHERE model_id = $14
+RETURNING id, model_id, name, llm_provider_id, dimensions, is_multimodal, type, context_window, fallback_model_id, reasoning, max_tokens, input_price, output_price, cached_input_price, created_at, updated_at
 `
 
 type UpdateModelByModelIDParams struct {
-	NewModelID      string      `json:"new_model_id"`
-	Name            pgtype.Text `json:"name"`
-	LlmProviderID   pgtype.UUID `json:"llm_provider_id"`
-	Dimensions      pgtype.Int4 `json:"dimensions"`
-	IsMultimodal    bool        `json:"is_multimodal"`
-	Type            string      `json:"type"`
-	ContextWindow   int32       `json:"context_window"`
-	FallbackModelID pgtype.UUID `json:"fallback_model_id"`
-	Reasoning       bool        `json:"reasoning"`
-	MaxTokens       int32       `json:"max_tokens"`
-	ModelID         string      `json:"model_id"`
+	NewModelID       string      `json:"new_model_id"`
+	Name             pgtype.Text `json:"name"`
+	LlmProviderID    pgtype.UUID `json:"llm_provider_id"`
+	Dimensions       pgtype.Int4 `json:"dimensions"`
+	IsMultimodal     bool        `json:"is_multimodal"`
+	Type             string      `json:"type"`
+	ContextWindow    int32       `json:"context_window"`
+	FallbackModelID  pgtype.UUID `json:"fallback_model_id"`
+	Reasoning        bool        `json:"reasoning"`
+	MaxTokens        int32       `json:"max_tokens"`
+	InputPrice       float64     `json:"input_price"`
+	OutputPrice      float64     `json:"output_price"`
+	CachedInputPrice float64     `json:"cached_input_price"`
+	ModelID          string      `json:"model_id"`
 }
 
 func (q *Queries) UpdateModelByModelID(ctx context.Context, arg UpdateModelByModelIDParams) (Model, error) {
@@ -801,6 +855,9 @@ func (q *Queries) UpdateModelByModelID(ctx context.Context, arg UpdateModelByMod
 		arg.FallbackModelID,
 		arg.Reasoning,
 		arg.MaxTokens,
+		arg.InputPrice,
+		arg.OutputPrice,
+		arg.CachedInputPrice,
 		arg.ModelID,
 	)
 	var i Model
@@ -816,6 +873,9 @@ func (q *Queries) UpdateModelByModelID(ctx context.Context, arg UpdateModelByMod
 		&i.FallbackModelID,
 		&i.Reasoning,
 		&i.MaxTokens,
+		&i.InputPrice,
+		&i.OutputPrice,
+		&i.CachedInputPrice,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -11,22 +11,69 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveSchedule = `-- name: ArchiveSchedule :one
+UPDATE schedule
+SET enabled = false,
+    archived_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
+`
+
+func (q *Queries) ArchiveSchedule(ctx context.Context, id pgtype.UUID) (Schedule, error) {
+	row := q.db.QueryRow(ctx, archiveSchedule, id)
+	var i Schedule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Pattern,
+		&i.MaxCalls,
+		&i.CurrentCalls,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Enabled,
+		&i.Command,
+		&i.BotID,
+		&i.Platform,
+		&i.ReplyTarget,
+		&i.MisfirePolicy,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffSeconds,
+		&i.Kind,
+		&i.RunAt,
+		&i.IntervalSeconds,
+		&i.StartAt,
+		&i.Timezone,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
 const createSchedule = `-- name: CreateSchedule :one
-INSERT INTO schedule (name, description, pattern, max_calls, enabled, command, bot_id, platform, reply_target)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+INSERT INTO schedule (name, description, pattern, max_calls, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 `
 
 type CreateScheduleParams struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Pattern     string      `json:"pattern"`
-	MaxCalls    pgtype.Int4 `json:"max_calls"`
-	Enabled     bool        `json:"enabled"`
-	Command     string      `json:"command"`
-	BotID       pgtype.UUID `json:"bot_id"`
-	Platform    string      `json:"platform"`
-	ReplyTarget string      `json:"reply_target"`
+	Name                string             `json:"name"`
+	Description         string             `json:"description"`
+	Pattern             string             `json:"pattern"`
+	MaxCalls            pgtype.Int4        `json:"max_calls"`
+	Enabled             bool               `json:"enabled"`
+	Command             string             `json:"command"`
+	BotID               pgtype.UUID        `json:"bot_id"`
+	Platform            string             `json:"platform"`
+	ReplyTarget         string             `json:"reply_target"`
+	MisfirePolicy       string             `json:"misfire_policy"`
+	RetryMaxAttempts    int32              `json:"retry_max_attempts"`
+	RetryBackoffSeconds int32              `json:"retry_backoff_seconds"`
+	Kind                string             `json:"kind"`
+	RunAt               pgtype.Timestamptz `json:"run_at"`
+	IntervalSeconds     pgtype.Int4        `json:"interval_seconds"`
+	StartAt             pgtype.Timestamptz `json:"start_at"`
+	Timezone            string             `json:"timezone"`
 }
 
 func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error) {
@@ -40,6 +87,14 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		arg.BotID,
 		arg.Platform,
 		arg.ReplyTarget,
+		arg.MisfirePolicy,
+		arg.RetryMaxAttempts,
+		arg.RetryBackoffSeconds,
+		arg.Kind,
+		arg.RunAt,
+		arg.IntervalSeconds,
+		arg.StartAt,
+		arg.Timezone,
 	)
 	var i Schedule
 	err := row.Scan(
@@ -56,6 +111,15 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		&i.BotID,
 		&i.Platform,
 		&i.ReplyTarget,
+		&i.MisfirePolicy,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffSeconds,
+		&i.Kind,
+		&i.RunAt,
+		&i.IntervalSeconds,
+		&i.StartAt,
+		&i.Timezone,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
@@ -71,7 +135,7 @@ func (q *Queries) DeleteSchedule(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getScheduleByID = `-- name: GetScheduleByID :one
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 FROM schedule
 WHERE id = $1
 `
@@ -93,6 +157,15 @@ func (q *Queries) GetScheduleByID(ctx context.Context, id pgtype.UUID) (Schedule
 		&i.BotID,
 		&i.Platform,
 		&i.ReplyTarget,
+		&i.MisfirePolicy,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffSeconds,
+		&i.Kind,
+		&i.RunAt,
+		&i.IntervalSeconds,
+		&i.StartAt,
+		&i.Timezone,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
@@ -106,7 +179,7 @@ SET current_calls = current_calls + 1,
     END,
     updated_at = now()
 WHERE id = $1
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 `
 
 func (q *Queries) IncrementScheduleCalls(ctx context.Context, id pgtype.UUID) (Schedule, error) {
@@ -126,12 +199,71 @@ func (q *Queries) IncrementScheduleCalls(ctx context.Context, id pgtype.UUID) (S
 		&i.BotID,
 		&i.Platform,
 		&i.ReplyTarget,
+		&i.MisfirePolicy,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffSeconds,
+		&i.Kind,
+		&i.RunAt,
+		&i.IntervalSeconds,
+		&i.StartAt,
+		&i.Timezone,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
+const listArchivedSchedulesByBot = `-- name: ListArchivedSchedulesByBot :many
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
+FROM schedule
+WHERE bot_id = $1 AND archived_at IS NOT NULL
+ORDER BY archived_at DESC
+`
+
+func (q *Queries) ListArchivedSchedulesByBot(ctx context.Context, botID pgtype.UUID) ([]Schedule, error) {
+	rows, err := q.db.Query(ctx, listArchivedSchedulesByBot, botID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Schedule
+	for rows.Next() {
+		var i Schedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Pattern,
+			&i.MaxCalls,
+			&i.CurrentCalls,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Enabled,
+			&i.Command,
+			&i.BotID,
+			&i.Platform,
+			&i.ReplyTarget,
+			&i.MisfirePolicy,
+			&i.RetryMaxAttempts,
+			&i.RetryBackoffSeconds,
+			&i.Kind,
+			&i.RunAt,
+			&i.IntervalSeconds,
+			&i.StartAt,
+			&i.Timezone,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEnabledSchedules = `-- name: ListEnabledSchedules :many
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 FROM schedule
 WHERE enabled = true
 ORDER BY created_at DESC
@@ -160,6 +292,15 @@ func (q *Queries) ListEnabledSchedules(ctx context.Context) ([]Schedule, error)
 			&i.BotID,
 			&i.Platform,
 			&i.ReplyTarget,
+			&i.MisfirePolicy,
+			&i.RetryMaxAttempts,
+			&i.RetryBackoffSeconds,
+			&i.Kind,
+			&i.RunAt,
+			&i.IntervalSeconds,
+			&i.StartAt,
+			&i.Timezone,
+			&i.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -172,9 +313,9 @@ func (q *Queries) ListEnabledSchedules(ctx context.Context) ([]Schedule, error)
 }
 
 const listSchedulesByBot = `-- name: ListSchedulesByBot :many
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 FROM schedule
-WHERE bot_id = $1
+WHERE bot_id = $1 AND archived_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -201,6 +342,15 @@ func (q *Queries) ListSchedulesByBot(ctx context.Context, botID pgtype.UUID) ([]
 			&i.BotID,
 			&i.Platform,
 			&i.ReplyTarget,
+			&i.MisfirePolicy,
+			&i.RetryMaxAttempts,
+			&i.RetryBackoffSeconds,
+			&i.Kind,
+			&i.RunAt,
+			&i.IntervalSeconds,
+			&i.StartAt,
+			&i.Timezone,
+			&i.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -220,19 +370,36 @@ SET name = $2,
     max_calls = $5,
     enabled = $6,
     command = $7,
+    misfire_policy = $8,
+    retry_max_attempts = $9,
+    retry_backoff_seconds = $10,
+    kind = $11,
+    run_at = $12,
+    interval_seconds = $13,
+    start_at = $14,
+    timezone = $15,
+    archived_at = CASE WHEN $6::boolean THEN NULL ELSE archived_at END,
     updated_at = now()
 WHERE id = $1
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, platform, reply_target, misfire_policy, retry_max_attempts, retry_backoff_seconds, kind, run_at, interval_seconds, start_at, timezone, archived_at
 `
 
 type UpdateScheduleParams struct {
-	ID          pgtype.UUID `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Pattern     string      `json:"pattern"`
-	MaxCalls    pgtype.Int4 `json:"max_calls"`
-	Enabled     bool        `json:"enabled"`
-	Command     string      `json:"command"`
+	ID                  pgtype.UUID        `json:"id"`
+	Name                string             `json:"name"`
+	Description         string             `json:"description"`
+	Pattern             string             `json:"pattern"`
+	MaxCalls            pgtype.Int4        `json:"max_calls"`
+	Enabled             bool               `json:"enabled"`
+	Command             string             `json:"command"`
+	MisfirePolicy       string             `json:"misfire_policy"`
+	RetryMaxAttempts    int32              `json:"retry_max_attempts"`
+	RetryBackoffSeconds int32              `json:"retry_backoff_seconds"`
+	Kind                string             `json:"kind"`
+	RunAt               pgtype.Timestamptz `json:"run_at"`
+	IntervalSeconds     pgtype.Int4        `json:"interval_seconds"`
+	StartAt             pgtype.Timestamptz `json:"start_at"`
+	Timezone            string             `json:"timezone"`
 }
 
 func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (Schedule, error) {
@@ -244,6 +411,14 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		arg.MaxCalls,
 		arg.Enabled,
 		arg.Command,
+		arg.MisfirePolicy,
+		arg.RetryMaxAttempts,
+		arg.RetryBackoffSeconds,
+		arg.Kind,
+		arg.RunAt,
+		arg.IntervalSeconds,
+		arg.StartAt,
+		arg.Timezone,
 	)
 	var i Schedule
 	err := row.Scan(
@@ -260,6 +435,15 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		&i.BotID,
 		&i.Platform,
 		&i.ReplyTarget,
+		&i.MisfirePolicy,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffSeconds,
+		&i.Kind,
+		&i.RunAt,
+		&i.IntervalSeconds,
+		&i.StartAt,
+		&i.Timezone,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
@@ -180,6 +180,223 @@ func (q *Queries) GetBotTokenTotal(ctx context.Context, botID pgtype.UUID) (GetB
 	return i, err
 }
 
+const getCostByBot = `-- name: GetCostByBot :many
+SELECT
+  bot_id,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+  COALESCE(SUM(cached_tokens), 0)::bigint AS cached_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= $1
+  AND created_at < $2
+GROUP BY bot_id
+ORDER BY cost DESC
+`
+
+type GetCostByBotParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+}
+
+type GetCostByBotRow struct {
+	BotID            pgtype.UUID    `json:"bot_id"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	CachedTokens     int64          `json:"cached_tokens"`
+	TotalTokens      int64          `json:"total_tokens"`
+	Cost             pgtype.Numeric `json:"cost"`
+}
+
+func (q *Queries) GetCostByBot(ctx context.Context, arg GetCostByBotParams) ([]GetCostByBotRow, error) {
+	rows, err := q.db.Query(ctx, getCostByBot, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCostByBotRow
+	for rows.Next() {
+		var i GetCostByBotRow
+		if err := rows.Scan(
+			&i.BotID,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.CachedTokens,
+			&i.TotalTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCostByModel = `-- name: GetCostByModel :many
+SELECT
+  model,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+  COALESCE(SUM(cached_tokens), 0)::bigint AS cached_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= $1
+  AND created_at < $2
+  AND ($3::uuid IS NULL OR bot_id = $3)
+GROUP BY model
+ORDER BY cost DESC
+`
+
+type GetCostByModelParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+	BotID pgtype.UUID        `json:"bot_id"`
+}
+
+type GetCostByModelRow struct {
+	Model            string         `json:"model"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	CachedTokens     int64          `json:"cached_tokens"`
+	TotalTokens      int64          `json:"total_tokens"`
+	Cost             pgtype.Numeric `json:"cost"`
+}
+
+func (q *Queries) GetCostByModel(ctx context.Context, arg GetCostByModelParams) ([]GetCostByModelRow, error) {
+	rows, err := q.db.Query(ctx, getCostByModel, arg.Since, arg.Until, arg.BotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCostByModelRow
+	for rows.Next() {
+		var i GetCostByModelRow
+		if err := rows.Scan(
+			&i.Model,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.CachedTokens,
+			&i.TotalTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCostBySource = `-- name: GetCostBySource :many
+SELECT
+  source,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens,
+  COALESCE(SUM(cached_tokens), 0)::bigint AS cached_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= $1
+  AND created_at < $2
+  AND ($3::uuid IS NULL OR bot_id = $3)
+GROUP BY source
+ORDER BY cost DESC
+`
+
+type GetCostBySourceParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+	BotID pgtype.UUID        `json:"bot_id"`
+}
+
+type GetCostBySourceRow struct {
+	Source           string         `json:"source"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	CachedTokens     int64          `json:"cached_tokens"`
+	TotalTokens      int64          `json:"total_tokens"`
+	Cost             pgtype.Numeric `json:"cost"`
+}
+
+func (q *Queries) GetCostBySource(ctx context.Context, arg GetCostBySourceParams) ([]GetCostBySourceRow, error) {
+	rows, err := q.db.Query(ctx, getCostBySource, arg.Since, arg.Until, arg.BotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCostBySourceRow
+	for rows.Next() {
+		var i GetCostBySourceRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.CachedTokens,
+			&i.TotalTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCostDailySeries = `-- name: GetCostDailySeries :many
+SELECT
+  date_trunc('day', created_at)::date AS day,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost), 0)::numeric AS cost
+FROM token_usage
+WHERE created_at >= $1
+  AND created_at < $2
+  AND ($3::uuid IS NULL OR bot_id = $3)
+GROUP BY date_trunc('day', created_at)
+ORDER BY day
+`
+
+type GetCostDailySeriesParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+	BotID pgtype.UUID        `json:"bot_id"`
+}
+
+type GetCostDailySeriesRow struct {
+	Day         pgtype.Date    `json:"day"`
+	TotalTokens int64          `json:"total_tokens"`
+	Cost        pgtype.Numeric `json:"cost"`
+}
+
+func (q *Queries) GetCostDailySeries(ctx context.Context, arg GetCostDailySeriesParams) ([]GetCostDailySeriesRow, error) {
+	rows, err := q.db.Query(ctx, getCostDailySeries, arg.Since, arg.Until, arg.BotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCostDailySeriesRow
+	for rows.Next() {
+		var i GetCostDailySeriesRow
+		if err := rows.Scan(&i.Day, &i.TotalTokens, &i.Cost); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTokenTotalsByModel = `-- name: GetTokenTotalsByModel :many
 SELECT
   model,
@@ -223,19 +440,92 @@ func (q *Queries) GetTokenTotalsByModel(ctx context.Context) ([]GetTokenTotalsBy
 	return items, nil
 }
 
+const listTokenUsageForExport = `-- name: ListTokenUsageForExport :many
+SELECT
+  tu.created_at,
+  tu.bot_id,
+  COALESCE(b.display_name, '')::text AS bot_name,
+  tu.model,
+  tu.source,
+  tu.prompt_tokens,
+  tu.completion_tokens,
+  tu.cached_tokens,
+  tu.total_tokens,
+  tu.cost
+FROM token_usage tu
+LEFT JOIN bots b ON b.id = tu.bot_id
+WHERE tu.created_at >= $1
+  AND tu.created_at < $2
+  AND ($3::uuid IS NULL OR tu.bot_id = $3)
+ORDER BY tu.created_at
+`
+
+type ListTokenUsageForExportParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+	BotID pgtype.UUID        `json:"bot_id"`
+}
+
+type ListTokenUsageForExportRow struct {
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	BotID            pgtype.UUID        `json:"bot_id"`
+	BotName          string             `json:"bot_name"`
+	Model            string             `json:"model"`
+	Source           string             `json:"source"`
+	PromptTokens     int32              `json:"prompt_tokens"`
+	CompletionTokens int32              `json:"completion_tokens"`
+	CachedTokens     int32              `json:"cached_tokens"`
+	TotalTokens      int32              `json:"total_tokens"`
+	Cost             pgtype.Numeric     `json:"cost"`
+}
+
+func (q *Queries) ListTokenUsageForExport(ctx context.Context, arg ListTokenUsageForExportParams) ([]ListTokenUsageForExportRow, error) {
+	rows, err := q.db.Query(ctx, listTokenUsageForExport, arg.Since, arg.Until, arg.BotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTokenUsageForExportRow
+	for rows.Next() {
+		var i ListTokenUsageForExportRow
+		if err := rows.Scan(
+			&i.CreatedAt,
+			&i.BotID,
+			&i.BotName,
+			&i.Model,
+			&i.Source,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.CachedTokens,
+			&i.TotalTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const recordTokenUsage = `-- name: RecordTokenUsage :one
-INSERT INTO token_usage (bot_id, prompt_tokens, completion_tokens, total_tokens, model, source)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, bot_id, prompt_tokens, completion_tokens, total_tokens, model, source, created_at
+INSERT INTO token_usage (bot_id, prompt_tokens, completion_tokens, total_tokens, model, source, channel_identity_id, cached_tokens, cost)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, bot_id, prompt_tokens, completion_tokens, total_tokens, model, source, created_at, channel_identity_id, cached_tokens, cost
 `
 
 type RecordTokenUsageParams struct {
-	BotID            pgtype.UUID `json:"bot_id"`
-	PromptTokens     int32       `json:"prompt_tokens"`
-	CompletionTokens int32       `json:"completion_tokens"`
-	TotalTokens      int32       `json:"total_tokens"`
-	Model            string      `json:"model"`
-	Source           string      `json:"source"`
+	BotID             pgtype.UUID    `json:"bot_id"`
+	PromptTokens      int32          `json:"prompt_tokens"`
+	CompletionTokens  int32          `json:"completion_tokens"`
+	TotalTokens       int32          `json:"total_tokens"`
+	Model             string         `json:"model"`
+	Source            string         `json:"source"`
+	ChannelIdentityID pgtype.UUID    `json:"channel_identity_id"`
+	CachedTokens      int32          `json:"cached_tokens"`
+	Cost              pgtype.Numeric `json:"cost"`
 }
 
 func (q *Queries) RecordTokenUsage(ctx context.Context, arg RecordTokenUsageParams) (TokenUsage, error) {
@@ -246,6 +536,9 @@ func (q *Queries) RecordTokenUsage(ctx context.Context, arg RecordTokenUsagePara
 		arg.TotalTokens,
 		arg.Model,
 		arg.Source,
+		arg.ChannelIdentityID,
+		arg.CachedTokens,
+		arg.Cost,
 	)
 	var i TokenUsage
 	err := row.Scan(
@@ -257,6 +550,9 @@ func (q *Queries) RecordTokenUsage(ctx context.Context, arg RecordTokenUsagePara
 		&i.Model,
 		&i.Source,
 		&i.CreatedAt,
+		&i.ChannelIdentityID,
+		&i.CachedTokens,
+		&i.Cost,
 	)
 	return i, err
 }
@@ -3,6 +3,7 @@ package db
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -51,6 +52,29 @@ func TextToString(value pgtype.Text) string {
 	return value.String
 }
 
+// NumericFromFloat converts v to a pgtype.Numeric rounded to places decimal
+// digits, so amounts computed in float64 are stored exactly as rounded.
+func NumericFromFloat(v float64, places int) pgtype.Numeric {
+	var n pgtype.Numeric
+	if err := n.Scan(strconv.FormatFloat(v, 'f', places, 64)); err != nil {
+		return pgtype.Numeric{}
+	}
+	return n
+}
+
+// NumericToString renders a pgtype.Numeric as a plain decimal string, or "0"
+// when invalid.
+func NumericToString(value pgtype.Numeric) string {
+	if !value.Valid {
+		return "0"
+	}
+	out, err := value.MarshalJSON()
+	if err != nil {
+		return "0"
+	}
+	return strings.Trim(string(out), `"`)
+}
+
 // IsUniqueViolation reports whether err is a PostgreSQL unique constraint violation (SQLSTATE 23505).
 func IsUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
@@ -100,6 +100,30 @@ func TestTextToString(t *testing.T) {
 	}
 }
 
+func TestNumericFromFloat(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		places int
+		want   string
+	}{
+		{"rounds", 0.1 + 0.2, 10, "0.3000000000"},
+		{"small", 3.5e-6, 10, "0.0000035000"},
+		{"zero", 0, 10, "0.0000000000"},
+		{"whole", 12, 0, "12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NumericToString(NumericFromFloat(tt.value, tt.places)); got != tt.want {
+				t.Errorf("NumericFromFloat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := NumericToString(pgtype.Numeric{}); got != "0" {
+		t.Errorf("NumericToString(invalid) = %q, want 0", got)
+	}
+}
+
 func TestIsUniqueViolation(t *testing.T) {
 	tests := []struct {
 		name string
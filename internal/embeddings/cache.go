@@ -6,8 +6,23 @@ import (
 	"encoding/hex"
 	"sync"
 	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
 )
 
+var cacheLookups = metrics.NewCounter("memoh_embedding_cache_lookups_total",
+	"Embedding cache lookups by cache layer (memory, postgres) and result (hit, miss).", "cache", "result")
+
+// ObserveCacheLookup records an embedding cache lookup for the hit ratio
+// metric. cache names the cache layer.
+func ObserveCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.Inc(cache, result)
+}
+
 type cacheEntry struct {
 	vector    []float32
 	expiresAt time.Time
@@ -44,11 +59,13 @@ func (c *CachedEmbedder) Embed(ctx context.Context, input string) ([]float32, er
 	c.mu.RLock()
 	if entry, ok := c.cache[key]; ok && time.Now().Before(entry.expiresAt) {
 		c.mu.RUnlock()
+		ObserveCacheLookup("memory", true)
 		dst := make([]float32, len(entry.vector))
 		copy(dst, entry.vector)
 		return dst, nil
 	}
 	c.mu.RUnlock()
+	ObserveCacheLookup("memory", false)
 
 	vector, err := c.inner.Embed(ctx, input)
 	if err != nil {
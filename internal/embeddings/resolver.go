@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/models"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
 )
 
 const (
@@ -304,5 +306,12 @@ func (r *Resolver) fetchProvider(ctx context.Context, providerID string) (sqlc.L
 	}
 	pgID := pgtype.UUID{Valid: true}
 	copy(pgID.Bytes[:], parsed[:])
-	return r.queries.GetLlmProviderByID(ctx, pgID)
+	provider, err := r.queries.GetLlmProviderByID(ctx, pgID)
+	if err != nil {
+		return sqlc.LlmProvider{}, err
+	}
+	if provider.ApiKey, err = secrets.Open(provider.ApiKey); err != nil {
+		return sqlc.LlmProvider{}, fmt.Errorf("decrypt provider api key: %w", err)
+	}
+	return provider, nil
 }
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -11,10 +13,12 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/sso"
 )
 
 type AuthHandler struct {
 	accountService *accounts.Service
+	sso            *sso.Provider
 	jwtSecret      string
 	expiresIn      time.Duration
 	logger         *slog.Logger
@@ -35,9 +39,22 @@ type LoginResponse struct {
 	Username    string `json:"username"`
 }
 
-func NewAuthHandler(log *slog.Logger, accountService *accounts.Service, jwtSecret string, expiresIn time.Duration) *AuthHandler {
+// AuthConfigResponse tells the login page which login methods are offered.
+type AuthConfigResponse struct {
+	PasswordLogin bool          `json:"password_login"`
+	SSO           *SSOLoginInfo `json:"sso,omitempty"`
+}
+
+type SSOLoginInfo struct {
+	Name string `json:"name"`
+	// LoginURL starts the login; the browser must navigate to it.
+	LoginURL string `json:"login_url"`
+}
+
+func NewAuthHandler(log *slog.Logger, accountService *accounts.Service, ssoProvider *sso.Provider, jwtSecret string, expiresIn time.Duration) *AuthHandler {
 	return &AuthHandler{
 		accountService: accountService,
+		sso:            ssoProvider,
 		jwtSecret:      jwtSecret,
 		expiresIn:      expiresIn,
 		logger:         log.With(slog.String("handler", "auth")),
@@ -46,6 +63,23 @@ func NewAuthHandler(log *slog.Logger, accountService *accounts.Service, jwtSecre
 
 func (h *AuthHandler) Register(e *echo.Echo) {
 	e.POST("/auth/login", h.Login)
+	e.GET("/auth/config", h.Config)
+	e.GET("/auth/oidc/login", h.SSOLogin)
+	e.GET("/auth/oidc/callback", h.SSOCallback)
+}
+
+// Config godoc
+// @Summary Login methods
+// @Description List the login methods offered to the login page
+// @Tags auth
+// @Success 200 {object} AuthConfigResponse
+// @Router /auth/config [get]
+func (h *AuthHandler) Config(c echo.Context) error {
+	resp := AuthConfigResponse{PasswordLogin: h.sso.PasswordLoginEnabled()}
+	if h.sso.Enabled() {
+		resp.SSO = &SSOLoginInfo{Name: h.sso.Name(), LoginURL: "/auth/oidc/login"}
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // Login godoc
@@ -56,9 +90,13 @@ func (h *AuthHandler) Register(e *echo.Echo) {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c echo.Context) error {
+	if !h.sso.PasswordLoginEnabled() {
+		return echo.NewHTTPError(http.StatusForbidden, "password login is disabled, sign in with SSO")
+	}
 	if h.accountService == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "user service not configured")
 	}
@@ -105,3 +143,112 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		DisplayName: account.DisplayName,
 	})
 }
+
+// SSOLogin godoc
+// @Summary Start SSO login
+// @Description Redirect the browser to the OpenID Connect provider
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /auth/oidc/login [get]
+func (h *AuthHandler) SSOLogin(c echo.Context) error {
+	if !h.sso.Enabled() {
+		return echo.NewHTTPError(http.StatusNotFound, "single sign-on is not enabled")
+	}
+	st, err := sso.NewLoginState()
+	if err != nil {
+		h.logger.Error("create sso state failed", slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start login")
+	}
+	now := time.Now()
+	value, err := sso.EncodeState(st, h.jwtSecret, now)
+	if err != nil {
+		h.logger.Error("sign sso state failed", slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start login")
+	}
+	target, err := h.sso.AuthURL(c.Request().Context(), st)
+	if err != nil {
+		h.logger.Error("sso provider unavailable", slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusBadGateway, "identity provider unavailable")
+	}
+	h.setStateCookie(c, value, now.Add(sso.StateTTL))
+	return c.Redirect(http.StatusFound, target)
+}
+
+// SSOCallback godoc
+// @Summary Finish SSO login
+// @Description Handle the OpenID Connect provider redirect and send the browser to the web UI with an access token in the URL fragment
+// @Tags auth
+// @Param code query string false "Authorization code"
+// @Param state query string false "Login state"
+// @Success 302
+// @Router /auth/oidc/callback [get]
+func (h *AuthHandler) SSOCallback(c echo.Context) error {
+	if !h.sso.Enabled() {
+		return echo.NewHTTPError(http.StatusNotFound, "single sign-on is not enabled")
+	}
+	cookie, cookieErr := c.Cookie(sso.StateCookie)
+	h.setStateCookie(c, "", time.Unix(0, 0))
+
+	if providerErr := strings.TrimSpace(c.QueryParam("error")); providerErr != "" {
+		h.logger.Warn("sso provider returned error", slog.String("error", providerErr), slog.String("description", c.QueryParam("error_description")))
+		return h.ssoFailed(c, "the identity provider rejected the login")
+	}
+	if cookieErr != nil || cookie.Value == "" {
+		return h.ssoFailed(c, "login session expired, please try again")
+	}
+	st, err := sso.DecodeState(cookie.Value, h.jwtSecret)
+	if err != nil {
+		return h.ssoFailed(c, "login session expired, please try again")
+	}
+	if subtle.ConstantTimeCompare([]byte(st.State), []byte(c.QueryParam("state"))) != 1 {
+		return h.ssoFailed(c, "login state mismatch, please try again")
+	}
+	code := strings.TrimSpace(c.QueryParam("code"))
+	if code == "" {
+		return h.ssoFailed(c, "missing authorization code")
+	}
+
+	ctx := c.Request().Context()
+	login, err := h.sso.Exchange(ctx, code, st)
+	if err != nil {
+		h.logger.Error("sso exchange failed", slog.Any("error", err))
+		return h.ssoFailed(c, "failed to verify the login with the identity provider")
+	}
+	account, err := h.accountService.LoginExternal(ctx, login)
+	if err != nil {
+		if errors.Is(err, accounts.ErrInactiveAccount) {
+			return h.ssoFailed(c, "user is inactive")
+		}
+		h.logger.Error("sso login failed", slog.Any("error", err))
+		return h.ssoFailed(c, "login failed")
+	}
+	token, expiresAt, err := auth.GenerateToken(account.ID, h.jwtSecret, h.expiresIn)
+	if err != nil {
+		h.logger.Error("generate token failed", slog.Any("error", err))
+		return h.ssoFailed(c, "failed to generate token")
+	}
+	// The token travels in the fragment so it never reaches server logs.
+	return c.Redirect(http.StatusFound, h.sso.PostLoginRedirect()+"#"+url.Values{
+		"access_token": {token},
+		"token_type":   {"Bearer"},
+		"expires_at":   {expiresAt.Format(time.RFC3339)},
+	}.Encode())
+}
+
+func (h *AuthHandler) ssoFailed(c echo.Context, message string) error {
+	return c.Redirect(http.StatusFound, h.sso.PostLoginRedirect()+"#"+url.Values{"error": {message}}.Encode())
+}
+
+func (h *AuthHandler) setStateCookie(c echo.Context, value string, expires time.Time) {
+	c.SetCookie(&http.Cookie{
+		Name:     sso.StateCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.sso.SecureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -33,7 +33,9 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	dbsqlc "github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/mcp"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/policy"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
 )
 
 type ContainerdHandler struct {
@@ -51,6 +53,9 @@ type ContainerdHandler struct {
 	accountService *accounts.Service
 	policyService  *policy.Service
 	queries        *dbsqlc.Queries
+	resourceLimits *settings.ResourceLimitsStore
+	egressPolicies *settings.EgressPolicyStore
+	events         event.Publisher
 }
 
 type CreateContainerRequest struct {
@@ -132,6 +137,8 @@ func (h *ContainerdHandler) Register(e *echo.Echo) {
 	group.DELETE("", h.DeleteContainer)
 	group.POST("/start", h.StartContainer)
 	group.POST("/stop", h.StopContainer)
+	group.GET("/resources", h.GetResourceLimits)
+	group.PUT("/resources", h.UpdateResourceLimits)
 	group.POST("/snapshots", h.CreateSnapshot)
 	group.GET("/snapshots", h.ListSnapshots)
 	group.DELETE("/snapshots/:snapshot_name", h.DeleteSnapshot)
@@ -148,6 +155,8 @@ func (h *ContainerdHandler) Register(e *echo.Echo) {
 	root.POST("/mcp-stdio", h.CreateMCPStdio)
 	root.POST("/mcp-stdio/:connection_id", h.HandleMCPStdio)
 	root.POST("/tools", h.HandleMCPTools)
+	root.GET("/settings/egress", h.GetEgressPolicy)
+	root.PUT("/settings/egress", h.UpdateEgressPolicy)
 
 	filesGroup := e.Group("/bots/:bot_id/files")
 	filesGroup.GET("", h.ListBotFiles)
@@ -221,6 +230,10 @@ func (h *ContainerdHandler) CreateContainer(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	limits, err := h.botResourceLimits(ctx, botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
 	h.ensureBotSharedOutputDir(ctx, sharedDir, botID)
 
@@ -246,6 +259,7 @@ func (h *ContainerdHandler) CreateContainer(c echo.Context) error {
 			},
 		}),
 		oci.WithProcessArgs("/bin/sh", "-lc", fmt.Sprintf("bootstrap(){ [ -e /app/mcp ] || { mkdir -p /app; [ -f /opt/mcp ] && cp -a /opt/mcp /app/mcp 2>/dev/null || true; }; [ -d /tmp ] || mkdir -p /tmp; chmod 1777 /tmp 2>/dev/null || true; if [ -d /opt/mcp-template ]; then mkdir -p %q; for f in /opt/mcp-template/*; do name=$(basename \"$f\"); [ -e %q/\"$name\" ] || cp -a \"$f\" %q/\"$name\" 2>/dev/null || true; done; fi; }; bootstrap; exec /app/mcp", dataMount, dataMount, dataMount)),
+		ctr.WithResourceLimits(containerResourceLimits(limits)),
 	}
 
 	createReq := ctr.CreateContainerRequest{
@@ -298,16 +312,8 @@ func (h *ContainerdHandler) CreateContainer(c echo.Context) error {
 	}
 
 	started := false
-	if task, err := h.service.StartTask(ctx, containerID, &ctr.StartTaskOptions{
-		UseStdio: false,
-	}); err == nil {
+	if _, err := h.service.StartTask(ctx, containerID, h.startTaskOptions(containerID)); err == nil {
 		started = true
-		if netErr := ctr.SetupNetwork(ctx, task, containerID); netErr != nil {
-			h.logger.Warn("mcp container network setup failed, task kept running",
-				slog.String("container_id", containerID),
-				slog.Any("error", netErr),
-			)
-		}
 		if h.queries != nil {
 			if pgBotID, parseErr := db.ParseUUID(botID); parseErr == nil {
 				if dbErr := h.queries.UpdateContainerStarted(c.Request().Context(), pgBotID); dbErr != nil {
@@ -316,11 +322,13 @@ func (h *ContainerdHandler) CreateContainer(c echo.Context) error {
 				}
 			}
 		}
+		h.publishContainerState(botID, containerID, containerStateRunning, nil)
 	} else {
 		h.logger.Error("mcp container start failed",
 			slog.String("container_id", containerID),
 			slog.Any("error", err),
 		)
+		h.publishContainerState(botID, containerID, containerStateError, err)
 	}
 
 	return c.JSON(http.StatusOK, CreateContainerResponse{
@@ -361,7 +369,7 @@ func (h *ContainerdHandler) ensureContainerAndTask(ctx context.Context, containe
 			// Task is running but CNI state may be stale (e.g. server container restarted).
 			// Re-apply network to ensure connectivity.
 			if task, taskErr := h.service.GetTask(ctx, containerID); taskErr == nil {
-				if netErr := ctr.SetupNetwork(ctx, task, containerID); netErr != nil {
+				if netErr := h.setupBotNetwork(ctx, task, containerID); netErr != nil {
 					h.logger.Warn("network re-setup failed for running task",
 						slog.String("container_id", containerID), slog.Any("error", netErr))
 				}
@@ -373,9 +381,7 @@ func (h *ContainerdHandler) ensureContainerAndTask(ctx context.Context, containe
 		}
 	}
 
-	task, err := h.service.StartTask(ctx, containerID, &ctr.StartTaskOptions{
-		UseStdio: false,
-	})
+	_, err = h.service.StartTask(ctx, containerID, h.startTaskOptions(containerID))
 	if err != nil {
 		// The container metadata exists in containerd but the snapshot may be gone
 		// (e.g. after an upgrade that rebuilt the containerd data volume). In this
@@ -392,10 +398,6 @@ func (h *ContainerdHandler) ensureContainerAndTask(ctx context.Context, containe
 		}
 		return err
 	}
-	if netErr := ctr.SetupNetwork(ctx, task, containerID); netErr != nil {
-		h.logger.Warn("network setup failed, task kept running",
-			slog.String("container_id", containerID), slog.Any("error", netErr))
-	}
 	return nil
 }
 
@@ -567,6 +569,7 @@ func (h *ContainerdHandler) StartContainer(c echo.Context) error {
 			}
 		}
 	}
+	h.publishContainerState(botID, containerID, containerStateRunning, nil)
 	return c.JSON(http.StatusOK, map[string]bool{"started": true})
 }
 
@@ -605,6 +608,7 @@ func (h *ContainerdHandler) StopContainer(c echo.Context) error {
 			}
 		}
 	}
+	h.publishContainerState(botID, containerID, containerStateStopped, nil)
 	return c.JSON(http.StatusOK, map[string]bool{"stopped": true})
 }
 
@@ -891,12 +895,18 @@ func (h *ContainerdHandler) RestoreSnapshot(c echo.Context) error {
 		cleanupSnapshot()
 		return echo.NewHTTPError(http.StatusInternalServerError, "shared dir: "+err.Error())
 	}
+	limits, err := h.botResourceLimits(ctx, botID)
+	if err != nil {
+		cleanupSnapshot()
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 	specOpts := []oci.SpecOpts{
 		oci.WithMounts([]specs.Mount{
 			{Destination: dataMount, Type: "bind", Source: dataDir, Options: []string{"rbind", "rw"}},
 			{Destination: "/shared", Type: "bind", Source: sharedDir, Options: []string{"rbind", "rw"}},
 			{Destination: "/etc/resolv.conf", Type: "bind", Source: resolvPath, Options: []string{"rbind", "ro"}},
 		}),
+		ctr.WithResourceLimits(containerResourceLimits(limits)),
 	}
 
 	_, err = h.service.CreateContainerFromSnapshot(ctx, ctr.CreateContainerRequest{
@@ -1008,6 +1018,10 @@ func (h *ContainerdHandler) SetupBotContainer(ctx context.Context, botID string)
 	if err != nil {
 		return err
 	}
+	limits, err := h.botResourceLimits(ctx, botID)
+	if err != nil {
+		return err
+	}
 	h.ensureBotSharedOutputDir(ctx, sharedDir, botID)
 
 	specOpts := []oci.SpecOpts{
@@ -1032,6 +1046,7 @@ func (h *ContainerdHandler) SetupBotContainer(ctx context.Context, botID string)
 			},
 		}),
 		oci.WithProcessArgs("/bin/sh", "-lc", fmt.Sprintf("bootstrap(){ [ -e /app/mcp ] || { mkdir -p /app; [ -f /opt/mcp ] && cp -a /opt/mcp /app/mcp 2>/dev/null || true; }; [ -d /tmp ] || mkdir -p /tmp; chmod 1777 /tmp 2>/dev/null || true; if [ -d /opt/mcp-template ]; then mkdir -p %q; for f in /opt/mcp-template/*; do name=$(basename \"$f\"); [ -e %q/\"$name\" ] || cp -a \"$f\" %q/\"$name\" 2>/dev/null || true; done; fi; }; bootstrap; exec /app/mcp", dataMount, dataMount, dataMount)),
+		ctr.WithResourceLimits(containerResourceLimits(limits)),
 	}
 
 	setupReq := ctr.CreateContainerRequest{
@@ -1082,16 +1097,7 @@ func (h *ContainerdHandler) SetupBotContainer(ctx context.Context, botID string)
 		}
 	}
 
-	if task, err := h.service.StartTask(ctx, containerID, &ctr.StartTaskOptions{
-		UseStdio: false,
-	}); err == nil {
-		if netErr := ctr.SetupNetwork(ctx, task, containerID); netErr != nil {
-			h.logger.Warn("setup bot container: network setup failed, task kept running",
-				slog.String("bot_id", botID),
-				slog.String("container_id", containerID),
-				slog.Any("error", netErr),
-			)
-		}
+	if _, err := h.service.StartTask(ctx, containerID, h.startTaskOptions(containerID)); err == nil {
 		if h.queries != nil {
 			if pgBotID, parseErr := db.ParseUUID(botID); parseErr == nil {
 				if dbErr := h.queries.UpdateContainerStarted(ctx, pgBotID); dbErr != nil {
@@ -1100,12 +1106,14 @@ func (h *ContainerdHandler) SetupBotContainer(ctx context.Context, botID string)
 				}
 			}
 		}
+		h.publishContainerState(botID, containerID, containerStateRunning, nil)
 	} else {
 		h.logger.Error("setup bot container: task start failed",
 			slog.String("bot_id", botID),
 			slog.String("container_id", containerID),
 			slog.Any("error", err),
 		)
+		h.publishContainerState(botID, containerID, containerStateError, err)
 	}
 	return nil
 }
@@ -1175,6 +1183,7 @@ func (h *ContainerdHandler) CleanupBotContainer(ctx context.Context, botID strin
 			}
 		}
 	}
+	h.publishContainerState(botID, containerID, containerStateDeleted, nil)
 	h.logger.Info("CleanupBotContainer finished", slog.String("bot_id", botID))
 	return nil
 }
@@ -1249,6 +1258,7 @@ func (h *ContainerdHandler) ReconcileContainers(ctx context.Context) {
 			if setupErr := h.SetupBotContainer(ctx, botID); setupErr != nil {
 				h.logger.Error("reconcile: rebuild failed",
 					slog.String("bot_id", botID), slog.Any("error", setupErr))
+				h.publishContainerState(botID, containerID, containerStateError, setupErr)
 				if dbErr := h.queries.UpdateContainerStatus(ctx, dbsqlc.UpdateContainerStatusParams{
 					Status: "error",
 					BotID:  row.BotID,
@@ -1260,6 +1270,13 @@ func (h *ContainerdHandler) ReconcileContainers(ctx context.Context) {
 			continue
 		}
 
+		// Container exists — re-apply resource limits, which may have changed
+		// while the server was down, before making sure the task is running.
+		if limitErr := h.applyResourceLimits(ctx, botID, containerID); limitErr != nil {
+			h.logger.Warn("reconcile: failed to apply resource limits",
+				slog.String("bot_id", botID), slog.Any("error", limitErr))
+		}
+
 		// Container exists — ensure the task is running.
 		running := h.isTaskRunning(ctx, containerID)
 		if running {
@@ -1268,12 +1285,13 @@ func (h *ContainerdHandler) ReconcileContainers(ctx context.Context) {
 					h.logger.Error("reconcile: failed to update DB status to running",
 						slog.String("bot_id", botID), slog.Any("error", dbErr))
 				}
+				h.publishContainerState(botID, containerID, containerStateRunning, nil)
 			}
 			// Re-apply CNI networking: server container restart drops cni0 bridge,
 			// veth endpoints and iptables masquerade rules while the MCP task keeps
 			// running inside containerd.
 			if task, taskErr := h.service.GetTask(ctx, containerID); taskErr == nil {
-				if netErr := ctr.SetupNetwork(ctx, task, containerID); netErr != nil {
+				if netErr := h.setupBotNetwork(ctx, task, containerID); netErr != nil {
 					h.logger.Warn("reconcile: network re-setup failed for running task",
 						slog.String("bot_id", botID),
 						slog.String("container_id", containerID),
@@ -1298,11 +1316,13 @@ func (h *ContainerdHandler) ReconcileContainers(ctx context.Context) {
 				h.logger.Error("reconcile: failed to mark container as stopped",
 					slog.String("bot_id", botID), slog.Any("error", dbErr))
 			}
+			h.publishContainerState(botID, containerID, containerStateStopped, err)
 		} else {
 			if dbErr := h.queries.UpdateContainerStarted(ctx, row.BotID); dbErr != nil {
 				h.logger.Error("reconcile: failed to update DB status to running",
 					slog.String("bot_id", botID), slog.Any("error", dbErr))
 			}
+			h.publishContainerState(botID, containerID, containerStateRunning, nil)
 		}
 	}
 	h.logger.Info("reconcile: completed")
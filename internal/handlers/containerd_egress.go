@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	tasktypes "github.com/containerd/containerd/api/types/task"
+	"github.com/containerd/containerd/v2/client"
+	"github.com/labstack/echo/v4"
+
+	ctr "github.com/Kxiandaoyan/Memoh-v2/internal/containerd"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/mcp"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
+)
+
+// SetEgressPolicyStore enables per-bot container egress policies.
+func (h *ContainerdHandler) SetEgressPolicyStore(store *settings.EgressPolicyStore) {
+	h.egressPolicies = store
+}
+
+// GetEgressPolicy godoc
+// @Summary Get container egress policy for bot
+// @Tags settings
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} settings.EgressPolicy
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/settings/egress [get]
+func (h *ContainerdHandler) GetEgressPolicy(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.egressPolicies == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "egress policies not configured")
+	}
+	policy, err := h.egressPolicies.Get(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// UpdateEgressPolicy godoc
+// @Summary Update container egress policy for bot
+// @Description Stores the bot's egress policy and re-applies networking to the running container. Modes are unrestricted, allowlist (domains and IPv4 or IPv6 CIDRs; domains are resolved whenever the policy is applied and re-resolved every few minutes) and none. In allowlist mode the container's DNS servers stay reachable and are not filtered, so data can still leave through DNS queries. Only admins may change the policy.
+// @Tags settings
+// @Param bot_id path string true "Bot ID"
+// @Param payload body settings.EgressPolicy true "Egress policy"
+// @Success 200 {object} settings.EgressPolicy
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/settings/egress [put]
+func (h *ContainerdHandler) UpdateEgressPolicy(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	isAdmin, err := h.accountService.IsAdmin(c.Request().Context(), channelIdentityID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !isAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "only admins can change egress policies")
+	}
+	if h.egressPolicies == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "egress policies not configured")
+	}
+	var req settings.EgressPolicy
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	req = req.Normalize()
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	ctx := c.Request().Context()
+	policy, err := h.egressPolicies.Upsert(ctx, botID, req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if containerID, err := h.botContainerID(ctx, botID); err == nil {
+		if task, taskErr := h.service.GetTask(ctx, containerID); taskErr == nil {
+			if err := h.setupBotNetwork(ctx, task, containerID); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("policy saved but not applied: %v", err))
+			}
+		}
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// ContainerEgressPolicy returns the egress policy of a bot container. It
+// satisfies ctr.EgressPolicyFunc so the MCP manager can apply it on task
+// restarts. Bots without a stored policy are unrestricted; a lookup failure
+// is returned so the task does not start with a policy it may not have.
+func (h *ContainerdHandler) ContainerEgressPolicy(ctx context.Context, containerID string) (ctr.EgressPolicy, error) {
+	unrestricted := ctr.EgressPolicy{Mode: ctr.EgressUnrestricted}
+	botID := strings.TrimPrefix(containerID, mcp.ContainerPrefix)
+	if h.egressPolicies == nil || botID == "" || botID == containerID {
+		return unrestricted, nil
+	}
+	stored, err := h.egressPolicies.Get(ctx, botID)
+	if err != nil {
+		return ctr.EgressPolicy{}, fmt.Errorf("load egress policy: %w", err)
+	}
+	policy := ctr.EgressPolicy{
+		Mode:           ctr.EgressMode(stored.Mode),
+		AllowedDomains: stored.AllowedDomains,
+		AllowedCIDRs:   stored.AllowedCIDRs,
+	}
+	if policy.Mode == ctr.EgressAllowlist {
+		if dataDir, err := h.ensureBotDataRoot(botID); err == nil {
+			if resolvPath, err := ctr.ResolveConfSource(dataDir); err == nil {
+				policy.ResolvConf = resolvPath
+			}
+		}
+	}
+	return policy, nil
+}
+
+// startTaskOptions attaches the bot's network before its task starts. A
+// policy lookup failure aborts the start, and so does a failed setup under
+// a restricted policy; an unrestricted setup failure is logged and the task
+// runs without networking.
+func (h *ContainerdHandler) startTaskOptions(containerID string) *ctr.StartTaskOptions {
+	return &ctr.StartTaskOptions{
+		UseStdio: false,
+		Network: func(ctx context.Context, task client.Task) error {
+			policy, err := h.ContainerEgressPolicy(ctx, containerID)
+			if err != nil {
+				return err
+			}
+			if err := ctr.SetupBotNetwork(ctx, task, containerID, policy); err != nil {
+				if policy.Mode != ctr.EgressUnrestricted {
+					return err
+				}
+				h.logger.Warn("container network setup failed, task kept running",
+					slog.String("container_id", containerID), slog.Any("error", err))
+			}
+			return nil
+		},
+	}
+}
+
+// setupBotNetwork attaches networking to a bot task according to its egress
+// policy.
+func (h *ContainerdHandler) setupBotNetwork(ctx context.Context, task client.Task, containerID string) error {
+	policy, err := h.ContainerEgressPolicy(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	return ctr.SetupBotNetwork(ctx, task, containerID, policy)
+}
+
+// StartEgressRefresher periodically re-resolves the allowed domains of
+// running allowlist containers, so addresses a domain moves to are reachable
+// without a restart. It blocks until ctx is cancelled.
+func (h *ContainerdHandler) StartEgressRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	h.logger.Info("egress refresher started", slog.Duration("interval", interval))
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.Info("egress refresher stopped")
+			return
+		case <-ticker.C:
+			h.refreshEgressAllowlists(ctx)
+		}
+	}
+}
+
+func (h *ContainerdHandler) refreshEgressAllowlists(ctx context.Context) {
+	if h.egressPolicies == nil {
+		return
+	}
+	tasks, err := h.service.ListTasks(ctx, nil)
+	if err != nil {
+		h.logger.Warn("egress refresh: list tasks failed", slog.Any("error", err))
+		return
+	}
+	for _, task := range tasks {
+		if task.Status != tasktypes.Status_RUNNING || !strings.HasPrefix(task.ContainerID, mcp.ContainerPrefix) {
+			continue
+		}
+		policy, err := h.ContainerEgressPolicy(ctx, task.ContainerID)
+		if err != nil {
+			h.logger.Warn("egress refresh: load policy failed",
+				slog.String("container_id", task.ContainerID), slog.Any("error", err))
+			continue
+		}
+		if err := ctr.RefreshEgressAllowlist(ctx, task.ContainerID, policy); err != nil {
+			h.logger.Warn("egress refresh failed, keeping current rules",
+				slog.String("container_id", task.ContainerID), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+)
+
+// Container states reported in container_state_changed events.
+const (
+	containerStateRunning = "running"
+	containerStateStopped = "stopped"
+	containerStateError   = "error"
+	containerStateDeleted = "deleted"
+)
+
+// SetEventPublisher enables container_state_changed events.
+func (h *ContainerdHandler) SetEventPublisher(p event.Publisher) {
+	h.events = p
+}
+
+// publishContainerState reports a container state change. err explains an
+// error or unexpected stop and may be nil.
+func (h *ContainerdHandler) publishContainerState(botID, containerID, state string, err error) {
+	if h.events == nil {
+		return
+	}
+	data := map[string]any{
+		"container_id": containerID,
+		"state":        state,
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	h.events.Publish(event.NewEvent(event.EventTypeContainerStateChanged, botID, data))
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	ctr "github.com/Kxiandaoyan/Memoh-v2/internal/containerd"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
+)
+
+const (
+	// Usage at or above this share of a limit is reported as a warning.
+	resourceWarnRatio = 0.9
+	// A task throttled in at least this share of CFS periods is reported as
+	// CPU-starved.
+	cpuThrottleWarnRatio = 0.25
+)
+
+// SetResourceLimitsStore enables per-bot container resource limits.
+func (h *ContainerdHandler) SetResourceLimitsStore(store *settings.ResourceLimitsStore) {
+	h.resourceLimits = store
+}
+
+// GetResourceLimits godoc
+// @Summary Get container resource limits for bot
+// @Tags containerd
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} settings.ResourceLimits
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/container/resources [get]
+func (h *ContainerdHandler) GetResourceLimits(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.resourceLimits == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "resource limits not configured")
+	}
+	limits, err := h.resourceLimits.Get(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, limits)
+}
+
+// UpdateResourceLimits godoc
+// @Summary Update container resource limits for bot
+// @Description Stores the bot's resource profile and applies it to the running container. Only admins may change limits. Zero fields are unlimited.
+// @Tags containerd
+// @Param bot_id path string true "Bot ID"
+// @Param payload body settings.ResourceLimits true "Resource limits"
+// @Success 200 {object} settings.ResourceLimits
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/container/resources [put]
+func (h *ContainerdHandler) UpdateResourceLimits(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	// Limits protect the host from every bot, so owners may not lift their own.
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	isAdmin, err := h.accountService.IsAdmin(c.Request().Context(), channelIdentityID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !isAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "only admins can change resource limits")
+	}
+	if h.resourceLimits == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "resource limits not configured")
+	}
+	var req settings.ResourceLimits
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	ctx := c.Request().Context()
+	limits, err := h.resourceLimits.Upsert(ctx, botID, req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if containerID, err := h.botContainerID(ctx, botID); err == nil {
+		if err := h.applyResourceLimits(ctx, botID, containerID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("limits saved but not applied: %v", err))
+		}
+	}
+	return c.JSON(http.StatusOK, limits)
+}
+
+// botResourceLimits returns the stored limits of a bot. A lookup failure is
+// returned rather than treated as unlimited, so containers are never created
+// without the limits an admin set.
+func (h *ContainerdHandler) botResourceLimits(ctx context.Context, botID string) (settings.ResourceLimits, error) {
+	if h.resourceLimits == nil {
+		return settings.ResourceLimits{}, nil
+	}
+	limits, err := h.resourceLimits.Get(ctx, botID)
+	if err != nil {
+		h.logger.Warn("load resource limits failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return settings.ResourceLimits{}, fmt.Errorf("load resource limits: %w", err)
+	}
+	return limits, nil
+}
+
+// containerResourceLimits converts a stored profile to cgroup limits.
+func containerResourceLimits(limits settings.ResourceLimits) ctr.ResourceLimits {
+	out := ctr.ResourceLimits{
+		CPUShares:   uint64(limits.CPUShares),
+		CPUPeriod:   ctr.DefaultCPUPeriod,
+		MemoryBytes: int64(limits.MemoryMB) << 20,
+		PidsLimit:   int64(limits.PidsLimit),
+	}
+	if limits.CPUMillicores > 0 {
+		out.CPUQuota = int64(limits.CPUMillicores) * int64(ctr.DefaultCPUPeriod) / 1000
+	}
+	return out
+}
+
+// applyResourceLimits re-applies the bot's stored limits to its container
+// and running task.
+func (h *ContainerdHandler) applyResourceLimits(ctx context.Context, botID, containerID string) error {
+	if h.resourceLimits == nil {
+		return nil
+	}
+	limits, err := h.resourceLimits.Get(ctx, botID)
+	if err != nil {
+		return err
+	}
+	return h.service.UpdateContainerResources(ctx, containerID, containerResourceLimits(limits))
+}
+
+// ResourceChecker returns a runtime checker that reports OOM kills, CPU
+// throttling and pids pressure of a bot's container.
+func (h *ContainerdHandler) ResourceChecker() bots.RuntimeChecker {
+	return &containerResourceChecker{handler: h}
+}
+
+type containerResourceChecker struct {
+	handler *ContainerdHandler
+}
+
+// CheckKeys returns the resource check keys.
+func (c *containerResourceChecker) CheckKeys(_ context.Context, _ string) []string {
+	return []string{
+		bots.BotCheckKeyContainerMemory,
+		bots.BotCheckKeyContainerCPU,
+		bots.BotCheckKeyContainerPids,
+	}
+}
+
+// RunCheck evaluates one resource check for the bot's container.
+func (c *containerResourceChecker) RunCheck(ctx context.Context, botID, key string) bots.BotCheck {
+	h := c.handler
+	check := bots.BotCheck{CheckKey: key, Status: bots.BotCheckStatusUnknown}
+	limits, err := h.botResourceLimits(ctx, botID)
+	if err != nil {
+		check.Summary = "Container resource limits are unknown."
+		check.Detail = err.Error()
+		return check
+	}
+	containerID, err := h.botContainerID(ctx, botID)
+	if err != nil {
+		check.Summary = "Container resource usage is unknown."
+		check.Detail = "no container is attached to this bot"
+		return check
+	}
+	stats, err := h.service.TaskResourceStats(ctx, containerID)
+	if err != nil {
+		check.Summary = "Container resource usage is unknown."
+		check.Detail = err.Error()
+		return check
+	}
+	switch key {
+	case bots.BotCheckKeyContainerMemory:
+		return checkMemoryUsage(check, stats, limits)
+	case bots.BotCheckKeyContainerCPU:
+		return checkCPUThrottling(check, stats)
+	case bots.BotCheckKeyContainerPids:
+		return checkPidsUsage(check, stats, limits)
+	}
+	check.Summary = "Check key not found."
+	return check
+}
+
+func checkMemoryUsage(check bots.BotCheck, stats ctr.ResourceStats, limits settings.ResourceLimits) bots.BotCheck {
+	limitBytes := uint64(limits.MemoryMB) << 20
+	check.Metadata = map[string]any{
+		"usage_bytes": stats.MemoryUsage,
+		"limit_bytes": limitBytes,
+		"oom_events":  stats.OOMEvents,
+		"oom_kills":   stats.OOMKills,
+	}
+	check.Detail = fmt.Sprintf("usage=%dMB limit=%s", stats.MemoryUsage>>20, formatLimitMB(limits.MemoryMB))
+	switch {
+	case stats.OOMKills > 0:
+		check.Status = bots.BotCheckStatusError
+		check.Summary = fmt.Sprintf("Processes were OOM-killed %d time(s).", stats.OOMKills)
+	case limitBytes > 0 && float64(stats.MemoryUsage) >= resourceWarnRatio*float64(limitBytes):
+		check.Status = bots.BotCheckStatusWarn
+		check.Summary = "Memory usage is close to the limit."
+	default:
+		check.Status = bots.BotCheckStatusOK
+		check.Summary = "Memory usage is within limits."
+	}
+	return check
+}
+
+func checkCPUThrottling(check bots.BotCheck, stats ctr.ResourceStats) bots.BotCheck {
+	check.Metadata = map[string]any{
+		"periods":        stats.CPUPeriods,
+		"throttled":      stats.CPUThrottled,
+		"throttled_usec": stats.CPUThrottledUsec,
+	}
+	if stats.CPUPeriods == 0 {
+		check.Status = bots.BotCheckStatusOK
+		check.Summary = "CPU is not throttled."
+		return check
+	}
+	ratio := float64(stats.CPUThrottled) / float64(stats.CPUPeriods)
+	check.Detail = fmt.Sprintf("throttled in %d of %d periods (%.0f%%)", stats.CPUThrottled, stats.CPUPeriods, ratio*100)
+	if ratio >= cpuThrottleWarnRatio {
+		check.Status = bots.BotCheckStatusWarn
+		check.Summary = "CPU is frequently throttled by the quota."
+		return check
+	}
+	check.Status = bots.BotCheckStatusOK
+	check.Summary = "CPU throttling is low."
+	return check
+}
+
+func checkPidsUsage(check bots.BotCheck, stats ctr.ResourceStats, limits settings.ResourceLimits) bots.BotCheck {
+	check.Metadata = map[string]any{
+		"current": stats.PidsCurrent,
+		"limit":   limits.PidsLimit,
+	}
+	check.Detail = fmt.Sprintf("current=%d limit=%s", stats.PidsCurrent, formatLimit(limits.PidsLimit))
+	if limits.PidsLimit > 0 && float64(stats.PidsCurrent) >= resourceWarnRatio*float64(limits.PidsLimit) {
+		check.Status = bots.BotCheckStatusWarn
+		check.Summary = "Process count is close to the limit."
+		return check
+	}
+	check.Status = bots.BotCheckStatusOK
+	check.Summary = "Process count is within limits."
+	return check
+}
+
+func formatLimit(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", limit)
+}
+
+func formatLimitMB(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%dMB", limit)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	ctr "github.com/Kxiandaoyan/Memoh-v2/internal/containerd"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
+)
+
+func TestContainerResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	got := containerResourceLimits(settings.ResourceLimits{CPUShares: 512, CPUMillicores: 1500, MemoryMB: 512, PidsLimit: 256})
+	want := ctr.ResourceLimits{
+		CPUShares:   512,
+		CPUQuota:    150000,
+		CPUPeriod:   ctr.DefaultCPUPeriod,
+		MemoryBytes: 512 << 20,
+		PidsLimit:   256,
+	}
+	if got != want {
+		t.Fatalf("unexpected limits: %+v", got)
+	}
+	if unlimited := containerResourceLimits(settings.ResourceLimits{}); !unlimited.IsZero() {
+		t.Fatalf("expected zero limits, got %+v", unlimited)
+	}
+}
+
+func TestResourceChecks(t *testing.T) {
+	t.Parallel()
+
+	limits := settings.ResourceLimits{MemoryMB: 100, PidsLimit: 100}
+	cases := []struct {
+		name  string
+		check bots.BotCheck
+		want  string
+	}{
+		{"oom kill", checkMemoryUsage(bots.BotCheck{}, ctr.ResourceStats{OOMKills: 2}, limits), bots.BotCheckStatusError},
+		{"memory pressure", checkMemoryUsage(bots.BotCheck{}, ctr.ResourceStats{MemoryUsage: 95 << 20}, limits), bots.BotCheckStatusWarn},
+		{"memory ok", checkMemoryUsage(bots.BotCheck{}, ctr.ResourceStats{MemoryUsage: 10 << 20}, limits), bots.BotCheckStatusOK},
+		{"throttled", checkCPUThrottling(bots.BotCheck{}, ctr.ResourceStats{CPUPeriods: 100, CPUThrottled: 40}), bots.BotCheckStatusWarn},
+		{"not throttled", checkCPUThrottling(bots.BotCheck{}, ctr.ResourceStats{CPUPeriods: 100, CPUThrottled: 1}), bots.BotCheckStatusOK},
+		{"pids pressure", checkPidsUsage(bots.BotCheck{}, ctr.ResourceStats{PidsCurrent: 99}, limits), bots.BotCheckStatusWarn},
+		{"pids unlimited", checkPidsUsage(bots.BotCheck{}, ctr.ResourceStats{PidsCurrent: 9999}, settings.ResourceLimits{}), bots.BotCheckStatusOK},
+	}
+	for _, tc := range cases {
+		if tc.check.Status != tc.want {
+			t.Errorf("%s: status = %q, want %q (%s)", tc.name, tc.check.Status, tc.want, tc.check.Summary)
+		}
+	}
+}
+
+func TestResourceLimitsValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (settings.ResourceLimits{MemoryMB: 1 << 30}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, limits := range []settings.ResourceLimits{
+		{MemoryMB: -1},
+		{MemoryMB: 8},
+		{CPUMillicores: 1},
+		{PidsLimit: 2},
+	} {
+		if err := limits.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", limits)
+		}
+	}
+}
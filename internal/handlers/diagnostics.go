@@ -15,20 +15,23 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/memory"
 )
 
 type DiagnosticsHandler struct {
 	queries        *sqlc.Queries
 	cfg            config.Config
 	accountService *accounts.Service
+	vectorStore    memory.VectorStore
 	logger         *slog.Logger
 }
 
-func NewDiagnosticsHandler(log *slog.Logger, queries *sqlc.Queries, cfg config.Config, accountService *accounts.Service) *DiagnosticsHandler {
+func NewDiagnosticsHandler(log *slog.Logger, queries *sqlc.Queries, cfg config.Config, accountService *accounts.Service, vectorStore memory.VectorStore) *DiagnosticsHandler {
 	return &DiagnosticsHandler{
 		queries:        queries,
 		cfg:            cfg,
 		accountService: accountService,
+		vectorStore:    vectorStore,
 		logger:         log.With(slog.String("handler", "diagnostics")),
 	}
 }
@@ -58,7 +61,7 @@ func (h *DiagnosticsHandler) RunDiagnostics(c echo.Context) error {
 	ctx := c.Request().Context()
 	checks := []DiagnosticCheck{
 		h.checkPostgreSQL(ctx),
-		h.checkQdrant(ctx),
+		h.checkVectorStore(ctx),
 		h.checkAgentGateway(ctx),
 		h.checkContainerd(),
 		h.checkDiskSpace(),
@@ -102,6 +105,29 @@ func (h *DiagnosticsHandler) checkPostgreSQL(ctx context.Context) DiagnosticChec
 	}
 }
 
+func (h *DiagnosticsHandler) checkVectorStore(ctx context.Context) DiagnosticCheck {
+	if h.vectorStore == nil || h.vectorStore.Backend() == memory.VectorBackendQdrant {
+		return h.checkQdrant(ctx)
+	}
+	start := time.Now()
+	err := h.vectorStore.Ping(ctx)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return DiagnosticCheck{
+			Name:    "Vector Store",
+			Status:  "error",
+			Message: fmt.Sprintf("%s unavailable: %v", h.vectorStore.Backend(), err),
+			Latency: latency,
+		}
+	}
+	return DiagnosticCheck{
+		Name:    "Vector Store",
+		Status:  "ok",
+		Message: fmt.Sprintf("Healthy (%s)", h.vectorStore.Backend()),
+		Latency: latency,
+	}
+}
+
 func (h *DiagnosticsHandler) checkQdrant(ctx context.Context) DiagnosticCheck {
 	start := time.Now()
 	baseURL := h.cfg.Qdrant.BaseURL
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	mcpgw "github.com/Kxiandaoyan/Memoh-v2/internal/mcp"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -28,7 +29,8 @@ func NewMCPFederationGateway(log *slog.Logger, handler *ContainerdHandler) *MCPF
 		handler: handler,
 		logger:  log.With(slog.String("gateway", "mcp_federation")),
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: tracing.Transport(nil),
 		},
 	}
 }
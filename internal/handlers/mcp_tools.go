@@ -20,6 +20,7 @@ const (
 	headerSessionToken      = "X-Memoh-Session-Token"
 	headerCurrentPlatform   = "X-Memoh-Current-Platform"
 	headerReplyTarget       = "X-Memoh-Reply-Target"
+	headerConversationType  = "X-Memoh-Conversation-Type"
 	headerIncludeTools      = "X-Memoh-Include-Tools"
 )
 
@@ -261,6 +262,7 @@ func (h *ContainerdHandler) buildToolSessionContext(c echo.Context, botID string
 		ChannelIdentityID: channelIdentityID,
 		SessionToken:      strings.TrimSpace(c.Request().Header.Get(headerSessionToken)),
 		CurrentPlatform:   strings.TrimSpace(c.Request().Header.Get(headerCurrentPlatform)),
+		ConversationType:  strings.TrimSpace(c.Request().Header.Get(headerConversationType)),
 		ReplyTarget:       strings.TrimSpace(c.Request().Header.Get(headerReplyTarget)),
 		IncludeTools:      includeTools,
 	}
@@ -33,6 +33,9 @@ type memoryAddPayload struct {
 	Filters          map[string]any   `json:"filters,omitempty"`
 	Infer            *bool            `json:"infer,omitempty"`
 	EmbeddingEnabled *bool            `json:"embedding_enabled,omitempty"`
+	// Sensitivity labels the added memories: public, group or private (to
+	// the caller). Empty means public unless the text contains PII.
+	Sensitivity string `json:"sensitivity,omitempty"`
 }
 
 type memorySearchPayload struct {
@@ -43,6 +46,10 @@ type memorySearchPayload struct {
 	Sources          []string       `json:"sources,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 	NoStats          bool           `json:"no_stats,omitempty"`
+	Hybrid           bool           `json:"hybrid,omitempty"`
+	FusionMethod     string         `json:"fusion_method,omitempty"`
+	DenseWeight      float64        `json:"dense_weight,omitempty"`
+	SparseWeight     float64        `json:"sparse_weight,omitempty"`
 }
 
 type memoryDeletePayload struct {
@@ -88,6 +95,13 @@ func (h *MemoryHandler) Register(e *echo.Echo) {
 	chatGroup.GET("/usage", h.ChatUsage)
 	chatGroup.DELETE("", h.ChatDelete)
 	chatGroup.DELETE("/:memory_id", h.ChatDeleteOne)
+	chatGroup.GET("/:memory_id/history", h.ChatHistory)
+	chatGroup.GET("/export", h.ChatExport)
+	chatGroup.POST("/import", h.ChatImport)
+	chatGroup.POST("/reembed", h.StartReembed)
+	chatGroup.GET("/reembed", h.ListReembedJobs)
+	chatGroup.GET("/reembed/:job_id", h.GetReembedJob)
+	chatGroup.POST("/reembed/:job_id/cancel", h.CancelReembed)
 }
 
 func (h *MemoryHandler) checkService() error {
@@ -138,6 +152,9 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	if _, ok := memory.NormalizeSensitivity(payload.Sensitivity); !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "sensitivity must be public, group or private")
+	}
 
 	// Resolve bot scope for shared memory.
 	scopeID, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
@@ -155,6 +172,8 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 		Filters:          filters,
 		Infer:            payload.Infer,
 		EmbeddingEnabled: payload.EmbeddingEnabled,
+		Audience:         &memory.Audience{ChannelIdentityID: channelIdentityID},
+		Sensitivity:      payload.Sensitivity,
 	}
 	resp, err := h.service.Add(c.Request().Context(), req)
 	if err != nil {
@@ -238,6 +257,10 @@ func (h *MemoryHandler) ChatSearch(c echo.Context) error {
 			Sources:          payload.Sources,
 			EmbeddingEnabled: payload.EmbeddingEnabled,
 			NoStats:          payload.NoStats,
+			Hybrid:           payload.Hybrid,
+			FusionMethod:     payload.FusionMethod,
+			DenseWeight:      payload.DenseWeight,
+			SparseWeight:     payload.SparseWeight,
 		}
 		resp, err := h.service.Search(c.Request().Context(), req)
 		if err != nil {
@@ -374,6 +397,9 @@ func (h *MemoryHandler) ChatDelete(c echo.Context) error {
 			h.logger.Warn("deleteall namespace failed", slog.String("namespace", scope.Namespace), slog.Any("error", err))
 		}
 	}
+	if err := h.service.DeleteRelations(c.Request().Context(), containerID); err != nil {
+		h.logger.Warn("deleteall relations failed", slog.Any("error", err))
+	}
 	// Sync remove all from filesystem.
 	if h.memoryFS != nil {
 		if err := h.memoryFS.RemoveAllMemories(c.Request().Context(), containerID); err != nil {
@@ -623,18 +649,25 @@ func (h *MemoryHandler) ChatRebuild(c echo.Context) error {
 		if _, exists := existingIDs[fsItem.ID]; exists {
 			continue
 		}
-		// Resolve filters from manifest, fallback to first scope.
+		// Resolve filters and labels from manifest, fallback to first scope
+		// and the file's frontmatter.
 		var filters map[string]any
+		sensitivity, audienceGroup := fsItem.Sensitivity, fsItem.AudienceGroup
 		if manifest != nil {
-			if entry, ok := manifest.Entries[fsItem.ID]; ok && len(entry.Filters) > 0 {
-				filters = entry.Filters
+			if entry, ok := manifest.Entries[fsItem.ID]; ok {
+				if len(entry.Filters) > 0 {
+					filters = entry.Filters
+				}
+				if entry.Sensitivity != "" {
+					sensitivity, audienceGroup = entry.Sensitivity, entry.AudienceGroup
+				}
 			}
 		}
 		if len(filters) == 0 && len(scopes) > 0 {
 			filters = buildNamespaceFilters(scopes[0].Namespace, scopes[0].ScopeID, nil)
 		}
 
-		if _, err := h.service.RebuildAdd(c.Request().Context(), fsItem.ID, fsItem.Memory, filters); err != nil {
+		if _, err := h.service.RebuildAdd(c.Request().Context(), fsItem.ID, fsItem.Memory, filters, sensitivity, audienceGroup); err != nil {
 			h.logger.Warn("rebuild add failed", slog.String("id", fsItem.ID), slog.Any("error", err))
 			continue
 		}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/memory"
+)
+
+// maxImportLineBytes bounds a single JSONL record; exports with vectors of
+// large embedding models stay well below this.
+const maxImportLineBytes = 4 << 20
+
+// maxImportBodyBytes bounds a whole import request, since the records are
+// held in memory until the import finishes.
+const maxImportBodyBytes = 256 << 20
+
+// ChatExport godoc
+// @Summary Export memories
+// @Description Stream every memory of the bot as JSONL (one memory.ExportRecord per line). Pass vectors=true to include the dense vectors of the active embedding model.
+// @Tags memory
+// @Produce application/x-ndjson
+// @Param bot_id path string true "Bot ID"
+// @Param vectors query bool false "Include raw vectors"
+// @Success 200 {object} memory.ExportRecord
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/export [get]
+func (h *MemoryHandler) ChatExport(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return err
+	}
+	scopes, err := h.resolveEnabledScopes(c.Request().Context(), containerID)
+	if err != nil {
+		return err
+	}
+	withVectors, _ := strconv.ParseBool(c.QueryParam("vectors"))
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	resp.Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="memory-%s-%s.jsonl"`, containerID, time.Now().UTC().Format("20060102-150405")))
+	resp.WriteHeader(http.StatusOK)
+
+	writer := bufio.NewWriter(resp)
+	encoder := json.NewEncoder(writer)
+	count := 0
+	for _, scope := range scopes {
+		filters := buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil)
+		err := h.service.Export(c.Request().Context(), scope.ScopeID, filters, withVectors, func(record memory.ExportRecord) error {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			count++
+			if count%256 == 0 {
+				if err := writer.Flush(); err != nil {
+					return err
+				}
+				resp.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			// Headers are already sent; log and truncate the stream.
+			h.logger.Warn("memory export failed", slog.String("bot_id", containerID), slog.Int("exported", count), slog.Any("error", err))
+			break
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return nil
+	}
+	resp.Flush()
+	return nil
+}
+
+// ChatImport godoc
+// @Summary Import memories
+// @Description Import a JSONL memory export into the bot. Records are deduplicated by content hash, timestamps are preserved, records from the shared bot namespace are moved into this bot's scope, other exported namespaces/scopes are preserved, and vectors from a different embedding model are re-computed. Pass override_scope=true to place every record in this bot's shared scope instead. Bodies over 256 MiB are rejected.
+// @Tags memory
+// @Accept application/x-ndjson
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param embedding_enabled query bool false "Write dense vectors (default true)"
+// @Param override_scope query bool false "Replace the exported namespace and scope with this bot's (default false)"
+// @Success 200 {object} memory.ImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/import [post]
+func (h *MemoryHandler) ChatImport(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return err
+	}
+	scopeID, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	if err != nil {
+		return err
+	}
+	embeddingEnabled := true
+	if raw := strings.TrimSpace(c.QueryParam("embedding_enabled")); raw != "" {
+		if embeddingEnabled, err = strconv.ParseBool(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid embedding_enabled")
+		}
+	}
+	overrideScope := false
+	if raw := strings.TrimSpace(c.QueryParam("override_scope")); raw != "" {
+		if overrideScope, err = strconv.ParseBool(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid override_scope")
+		}
+	}
+
+	records, err := decodeExportRecords(http.MaxBytesReader(c.Response(), c.Request().Body, maxImportBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "import body too large")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	filters := buildNamespaceFilters(sharedMemoryNamespace, scopeID, nil)
+	result, err := h.service.Import(c.Request().Context(), memory.ImportRequest{
+		BotID:            botID,
+		Filters:          filters,
+		OverrideScope:    overrideScope,
+		Records:          records,
+		EmbeddingEnabled: embeddingEnabled,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if h.memoryFS != nil && len(result.Scopes) > 0 {
+		scopes := result.Scopes
+		go func() {
+			bgCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			for _, scope := range scopes {
+				if err := h.memoryFS.PersistMemories(bgCtx, botID, scope.Items, scope.Filters); err != nil {
+					h.logger.Warn("async memory import persist failed", slog.Any("error", err))
+				}
+			}
+		}()
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func decodeExportRecords(body io.Reader) ([]memory.ExportRecord, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+	records := make([]memory.ExportRecord, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var record memory.ExportRecord
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read import body: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ChatHistory godoc
+// @Summary Memory edit history
+// @Description Return every recorded edit of one memory, oldest first, with the provenance of each edit (source message IDs, chat, trace, extractor model and decision event).
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param memory_id path string true "Memory ID"
+// @Param limit query int false "Max entries (default 50)"
+// @Success 200 {array} memory.HistoryEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/{memory_id}/history [get]
+func (h *MemoryHandler) ChatHistory(c echo.Context) error {
+	botID, err := h.authorizeMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	memoryID := strings.TrimSpace(c.Param("memory_id"))
+	if memoryID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "memory_id is required")
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	entries, err := h.service.History(c.Request().Context(), botID, memoryID, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/memory"
+)
+
+type memoryReembedPayload struct {
+	ModelID string `json:"model_id"`
+}
+
+// StartReembed godoc
+// @Summary Start embedding migration
+// @Description Re-embed every memory of the bot with another embedding model in the background. Search switches to the new model when the job completes.
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param payload body memoryReembedPayload true "Target embedding model"
+// @Success 202 {object} memory.ReembedJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed [post]
+func (h *MemoryHandler) StartReembed(c echo.Context) error {
+	botID, err := h.authorizeMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	var payload memoryReembedPayload
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(payload.ModelID) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "model_id is required")
+	}
+	job, err := h.service.StartReembed(c.Request().Context(), botID, payload.ModelID)
+	if err != nil {
+		if errors.Is(err, memory.ErrReembedInProgress) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// ListReembedJobs godoc
+// @Summary List embedding migrations
+// @Description List recent embedding migration jobs of the bot, newest first
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param limit query int false "Max jobs (default 20)"
+// @Success 200 {array} memory.ReembedJob
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed [get]
+func (h *MemoryHandler) ListReembedJobs(c echo.Context) error {
+	botID, err := h.authorizeMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	jobs, err := h.service.ListReembedJobs(c.Request().Context(), botID, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// GetReembedJob godoc
+// @Summary Get embedding migration
+// @Description Get progress of an embedding migration job
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} memory.ReembedJob
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed/{job_id} [get]
+func (h *MemoryHandler) GetReembedJob(c echo.Context) error {
+	botID, err := h.authorizeMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	job, err := h.loadReembedJob(c, botID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// CancelReembed godoc
+// @Summary Cancel embedding migration
+// @Description Stop a running embedding migration; the bot keeps its current embedding model
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} memory.ReembedJob
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed/{job_id}/cancel [post]
+func (h *MemoryHandler) CancelReembed(c echo.Context) error {
+	botID, err := h.authorizeMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	job, err := h.loadReembedJob(c, botID)
+	if err != nil {
+		return err
+	}
+	job, err = h.service.CancelReembed(c.Request().Context(), job.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+func (h *MemoryHandler) authorizeMemoryBot(c echo.Context) (string, error) {
+	if err := h.checkService(); err != nil {
+		return "", err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return "", err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return "", err
+	}
+	_, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	return botID, err
+}
+
+func (h *MemoryHandler) loadReembedJob(c echo.Context, botID string) (memory.ReembedJob, error) {
+	jobID := strings.TrimSpace(c.Param("job_id"))
+	if jobID == "" {
+		return memory.ReembedJob{}, echo.NewHTTPError(http.StatusBadRequest, "job_id is required")
+	}
+	job, err := h.service.GetReembedJob(c.Request().Context(), jobID)
+	if errors.Is(err, memory.ErrReembedNotFound) || (err == nil && job.BotID != botID) {
+		return memory.ReembedJob{}, echo.NewHTTPError(http.StatusNotFound, "reembed job not found")
+	}
+	if err != nil {
+		return memory.ReembedJob{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return job, nil
+}
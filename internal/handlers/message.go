@@ -16,6 +16,8 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/identities"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
@@ -36,6 +38,7 @@ type MessageHandler struct {
 	channelIdentitySvc  *identities.Service
 	logger              *slog.Logger
 	dataRoot            string // root directory for shared file storage
+	jwtSecret           string // signs bot tokens for requests made with API tokens
 }
 
 // NewMessageHandler creates a MessageHandler.
@@ -57,6 +60,12 @@ func NewMessageHandler(log *slog.Logger, runner flow.Runner, conversationService
 	}
 }
 
+// SetTokenSecret sets the JWT secret used to sign the bot's token when a
+// message arrives with an API token.
+func (h *MessageHandler) SetTokenSecret(secret string) {
+	h.jwtSecret = secret
+}
+
 // Register registers all conversation routes.
 func (h *MessageHandler) Register(e *echo.Echo) {
 	// Bot-scoped message container (single shared history per bot).
@@ -97,6 +106,15 @@ func (h *MessageHandler) bindChatRequest(c echo.Context) (conversation.ChatReque
 	req.BotID = botID
 	req.ChatID = botID
 	req.Token = c.Request().Header.Get("Authorization")
+	if auth.IsAPITokenRequest(c) {
+		// API tokens are scoped, while the bot calls back with the user's
+		// full permissions, as it does with a login JWT.
+		token, err := automation.GenerateTriggerToken(channelIdentityID, h.jwtSecret, automation.DefaultTriggerTokenTTL)
+		if err != nil {
+			return conversation.ChatRequest{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to issue bot token")
+		}
+		req.Token = token
+	}
 	req.UserID = channelIdentityID
 	req.SourceChannelIdentityID = channelIdentityID
 	if strings.TrimSpace(req.CurrentChannel) == "" {
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
+	ctr "github.com/Kxiandaoyan/Memoh-v2/internal/containerd"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+)
+
+var (
+	containerTasks = metrics.NewGauge("memoh_container_tasks",
+		"Bot container tasks by state.", "state")
+	containerTaskListErrors = metrics.NewCounter("memoh_container_task_list_errors_total",
+		"Failed attempts to list container tasks during a scrape.")
+)
+
+// containerTaskListTimeout bounds the containerd call made on each scrape.
+const containerTaskListTimeout = 5 * time.Second
+
+type MetricsHandler struct {
+	token          string
+	jwtSecret      string
+	accountService *accounts.Service
+	serve          http.Handler
+	logger         *slog.Logger
+}
+
+func NewMetricsHandler(log *slog.Logger, service ctr.Service, accountService *accounts.Service, cfg config.Config) *MetricsHandler {
+	h := &MetricsHandler{
+		token:          strings.TrimSpace(cfg.Server.MetricsToken),
+		jwtSecret:      cfg.Auth.JWTSecret,
+		accountService: accountService,
+		serve:          metrics.Default.Handler(),
+		logger:         log.With(slog.String("handler", "metrics")),
+	}
+	if service != nil {
+		metrics.Default.OnScrape(func(ctx context.Context) {
+			h.collectContainerTasks(ctx, service)
+		})
+	}
+	return h
+}
+
+// Register registers /metrics. The server-wide JWT middleware skips the
+// route so scrapers can use the metrics token; without one, the route
+// requires an admin login or an API token with the admin scope.
+func (h *MetricsHandler) Register(e *echo.Echo) {
+	if h.token != "" {
+		e.GET("/metrics", h.Metrics)
+		return
+	}
+	e.GET("/metrics", h.Metrics, auth.JWTMiddleware(h.jwtSecret, auth.IsAPITokenRequest))
+}
+
+// Metrics godoc
+// @Summary Prometheus metrics
+// @Description Serves server metrics in the Prometheus exposition format. When server.metrics_token is set, scrapers must send it as a bearer token; otherwise an admin login or an API token with the admin scope is required.
+// @Tags metrics
+// @Produce plain
+// @Success 200 {string} string
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /metrics [get]
+func (h *MetricsHandler) Metrics(c echo.Context) error {
+	if h.token != "" {
+		got := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid metrics token")
+		}
+	} else if err := RequireAdmin(c, h.accountService); err != nil {
+		// API tokens already passed the admin scope check in the token
+		// middleware; this also holds their owner to the admin role.
+		return err
+	}
+	h.serve.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+func (h *MetricsHandler) collectContainerTasks(ctx context.Context, service ctr.Service) {
+	ctx, cancel := context.WithTimeout(ctx, containerTaskListTimeout)
+	defer cancel()
+	tasks, err := service.ListTasks(ctx, nil)
+	if err != nil {
+		h.logger.Warn("list container tasks failed", slog.Any("error", err))
+		containerTaskListErrors.Inc()
+		return
+	}
+	containerTasks.Reset()
+	for _, task := range tasks {
+		containerTasks.Add(1, strings.ToLower(task.Status.String()))
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/apikeys"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation/flow"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/openaicompat"
+)
+
+// openAIChannel is the channel name API conversations are recorded under.
+const openAIChannel = "api"
+
+// OpenAIHandler serves an OpenAI-compatible API in front of the bots. Every
+// request authenticates with a bot API key and talks to that bot, which acts
+// with its owner's permissions as it does for schedules.
+type OpenAIHandler struct {
+	runner     flow.Runner
+	keys       *apikeys.Service
+	botService *bots.Service
+	jwtSecret  string
+	logger     *slog.Logger
+}
+
+// NewOpenAIHandler creates an OpenAIHandler.
+func NewOpenAIHandler(log *slog.Logger, runner flow.Runner, keys *apikeys.Service, botService *bots.Service, jwtSecret string) *OpenAIHandler {
+	return &OpenAIHandler{
+		runner:     runner,
+		keys:       keys,
+		botService: botService,
+		jwtSecret:  jwtSecret,
+		logger:     log.With(slog.String("handler", "openai")),
+	}
+}
+
+// Register registers the /v1 routes. They skip JWT auth and check bot API
+// keys instead.
+func (h *OpenAIHandler) Register(e *echo.Echo) {
+	e.GET("/v1/models", h.ListModels)
+	e.POST("/v1/chat/completions", h.ChatCompletions)
+}
+
+// openAIError is an error answered in the OpenAI error format.
+type openAIError struct {
+	status int
+	body   openaicompat.ErrorResponse
+}
+
+func (e *openAIError) Error() string {
+	return e.body.Error.Message
+}
+
+func newOpenAIError(status int, errType, code, message string) *openAIError {
+	return &openAIError{status: status, body: openaicompat.NewError(errType, code, message)}
+}
+
+func (h *OpenAIHandler) writeError(c echo.Context, err error) error {
+	var apiErr *openAIError
+	if !errors.As(err, &apiErr) {
+		apiErr = newOpenAIError(http.StatusInternalServerError, "server_error", "", "internal error")
+	}
+	return c.JSON(apiErr.status, apiErr.body)
+}
+
+// ListModels godoc
+// @Summary List models (OpenAI-compatible)
+// @Description Lists the bot the API key belongs to as the only model. Authenticate with "Authorization: Bearer <bot API key>".
+// @Tags openai
+// @Produce json
+// @Success 200 {object} openaicompat.ModelList
+// @Failure 401 {object} openaicompat.ErrorResponse
+// @Failure 403 {object} openaicompat.ErrorResponse
+// @Router /v1/models [get]
+func (h *OpenAIHandler) ListModels(c echo.Context) error {
+	bot, err := h.authenticate(c)
+	if err != nil {
+		return h.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, openaicompat.ModelList{
+		Object: openaicompat.ObjectList,
+		Data: []openaicompat.Model{{
+			ID:      bot.ID,
+			Object:  openaicompat.ObjectModel,
+			Created: bot.CreatedAt.Unix(),
+			OwnedBy: "memoh",
+		}},
+	})
+}
+
+// ChatCompletions godoc
+// @Summary Chat completions (OpenAI-compatible)
+// @Description Sends the conversation to the bot selected by model (the bot ID). The last message must come from the user. When earlier turns are included the client owns the context and the bot's stored history is not loaded; with a single user message the bot continues its own history. Streams server-sent events when stream is true.
+// @Tags openai
+// @Accept json
+// @Produce json
+// @Param payload body openaicompat.ChatCompletionRequest true "Chat completion request"
+// @Success 200 {object} openaicompat.ChatCompletion
+// @Failure 400 {object} openaicompat.ErrorResponse
+// @Failure 401 {object} openaicompat.ErrorResponse
+// @Failure 403 {object} openaicompat.ErrorResponse
+// @Failure 404 {object} openaicompat.ErrorResponse
+// @Failure 500 {object} openaicompat.ErrorResponse
+// @Router /v1/chat/completions [post]
+func (h *OpenAIHandler) ChatCompletions(c echo.Context) error {
+	bot, err := h.authenticate(c)
+	if err != nil {
+		return h.writeError(c, err)
+	}
+	var body openaicompat.ChatCompletionRequest
+	if err := c.Bind(&body); err != nil {
+		return h.writeError(c, newOpenAIError(http.StatusBadRequest, "invalid_request_error", "", "invalid request body"))
+	}
+	if strings.TrimSpace(body.Model) != bot.ID {
+		return h.writeError(c, newOpenAIError(http.StatusNotFound, "invalid_request_error", "model_not_found",
+			fmt.Sprintf("model %q does not exist or this API key cannot use it", body.Model)))
+	}
+	req, err := h.buildChatRequest(c, bot, body)
+	if err != nil {
+		return h.writeError(c, err)
+	}
+	if h.runner == nil {
+		return h.writeError(c, newOpenAIError(http.StatusInternalServerError, "server_error", "", "conversation runner not configured"))
+	}
+	id := "chatcmpl-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	created := time.Now().Unix()
+	if body.Stream {
+		includeUsage := body.StreamOptions != nil && body.StreamOptions.IncludeUsage
+		return h.streamCompletion(c, req, openaicompat.NewStreamTranslator(id, bot.ID, created, includeUsage))
+	}
+	resp, err := h.runner.Chat(c.Request().Context(), req)
+	if err != nil {
+		h.logger.Error("chat completion failed", slog.String("bot_id", bot.ID), slog.Any("error", err))
+		return h.writeError(c, newOpenAIError(http.StatusInternalServerError, "server_error", "", "conversation failed"))
+	}
+	return c.JSON(http.StatusOK, openaicompat.NewCompletion(id, bot.ID, created, resp))
+}
+
+func (h *OpenAIHandler) streamCompletion(c echo.Context, req conversation.ChatRequest, translator *openaicompat.StreamTranslator) error {
+	flusher, ok := c.Response().Writer.(http.Flusher)
+	if !ok {
+		return h.writeError(c, newOpenAIError(http.StatusInternalServerError, "server_error", "", "streaming not supported"))
+	}
+	c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+	c.Response().Header().Set("X-Accel-Buffering", "no")
+	c.Response().WriteHeader(http.StatusOK)
+	w := c.Response()
+
+	sendError := func(message string) {
+		writeSSEJSON(w, flusher, openaicompat.NewError("server_error", "", message))
+		writeSSEData(w, flusher, "[DONE]")
+	}
+
+	ctx := c.Request().Context()
+	chunkChan, errChan := h.runner.StreamChat(ctx, req)
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	for chunkChan != nil || errChan != nil {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			// SSE comment: keeps proxies from closing the connection and is
+			// ignored by OpenAI clients.
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case chunk, ok := <-chunkChan:
+			if !ok {
+				chunkChan = nil
+				continue
+			}
+			out, streamErr := translator.Translate(chunk)
+			if streamErr != "" {
+				h.logger.Error("chat completion stream failed", slog.String("bot_id", req.BotID), slog.String("error", streamErr))
+				sendError(streamErr)
+				return nil
+			}
+			for _, item := range out {
+				if err := writeSSEJSON(w, flusher, item); err != nil {
+					return nil
+				}
+			}
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				h.logger.Error("chat completion stream failed", slog.String("bot_id", req.BotID), slog.Any("error", err))
+				sendError(classifyStreamError(err))
+				return nil
+			}
+		}
+	}
+	for _, item := range translator.Finish() {
+		if err := writeSSEJSON(w, flusher, item); err != nil {
+			return nil
+		}
+	}
+	writeSSEData(w, flusher, "[DONE]")
+	return nil
+}
+
+// buildChatRequest maps the OpenAI messages onto a bot chat request.
+func (h *OpenAIHandler) buildChatRequest(c echo.Context, bot bots.Bot, body openaicompat.ChatCompletionRequest) (conversation.ChatRequest, error) {
+	query, history, err := openaicompat.SplitMessages(body.Messages)
+	if err != nil {
+		return conversation.ChatRequest{}, newOpenAIError(http.StatusBadRequest, "invalid_request_error", "", err.Error())
+	}
+	token, err := automation.GenerateTriggerToken(bot.OwnerUserID, h.jwtSecret, automation.DefaultTriggerTokenTTL)
+	if err != nil {
+		h.logger.Error("issue token for api chat failed", slog.String("bot_id", bot.ID), slog.Any("error", err))
+		return conversation.ChatRequest{}, newOpenAIError(http.StatusInternalServerError, "server_error", "", "failed to authorize bot")
+	}
+	displayName := strings.TrimSpace(body.User)
+	if displayName == "" {
+		displayName = "API"
+	}
+	req := conversation.ChatRequest{
+		BotID:            bot.ID,
+		ChatID:           bot.ID,
+		Token:            token,
+		UserID:           bot.OwnerUserID,
+		DisplayName:      displayName,
+		ConversationType: "direct",
+		Query:            query,
+		Messages:         history,
+		CurrentChannel:   openAIChannel,
+		Channels:         []string{openAIChannel},
+	}
+	if openaicompat.HasConversation(history) {
+		// A negative load time skips the stored history.
+		req.MaxContextLoadTime = -1
+	}
+	return req, nil
+}
+
+// authenticate resolves the bot API key from the Authorization header. Keys
+// go through the same check as on the rest of the API, so keys of a
+// deactivated owner are refused.
+func (h *OpenAIHandler) authenticate(c echo.Context) (bots.Bot, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(echo.HeaderAuthorization))
+	secret := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if secret == "" {
+		return bots.Bot{}, newOpenAIError(http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "missing API key; send Authorization: Bearer <key>")
+	}
+	if h.keys == nil || h.botService == nil {
+		return bots.Bot{}, newOpenAIError(http.StatusInternalServerError, "server_error", "", "api keys not configured")
+	}
+	if !apikeys.IsKey(secret) {
+		// Personal access tokens are not bound to a bot.
+		return bots.Bot{}, newOpenAIError(http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "invalid API key")
+	}
+	ctx := c.Request().Context()
+	principal, err := h.keys.AuthenticateToken(ctx, secret)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidAPIToken) {
+			return bots.Bot{}, newOpenAIError(http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "invalid API key")
+		}
+		h.logger.Error("api key lookup failed", slog.Any("error", err))
+		return bots.Bot{}, newOpenAIError(http.StatusInternalServerError, "server_error", "", "failed to verify API key")
+	}
+	if !auth.HasScope(principal.Scopes, auth.ScopeChat) {
+		return bots.Bot{}, newOpenAIError(http.StatusForbidden, "permission_error", "insufficient_scope", "API key lacks the chat scope")
+	}
+	bot, err := h.botService.Get(ctx, principal.BotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return bots.Bot{}, newOpenAIError(http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "invalid API key")
+		}
+		h.logger.Error("load bot for api key failed", slog.String("bot_id", principal.BotID), slog.Any("error", err))
+		return bots.Bot{}, newOpenAIError(http.StatusInternalServerError, "server_error", "", "failed to load bot")
+	}
+	return bot, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -33,7 +34,10 @@ func (h *ScheduleHandler) Register(e *echo.Echo) {
 	group := e.Group("/bots/:bot_id/schedule")
 	group.POST("", h.Create)
 	group.GET("", h.List)
+	group.GET("/runs", h.ListBotRuns)
+	group.GET("/runs/:run_id", h.GetRun)
 	group.GET("/:id", h.Get)
+	group.GET("/:id/runs", h.ListRuns)
 	group.PUT("/:id", h.Update)
 	group.DELETE("/:id", h.Delete)
 }
@@ -72,8 +76,9 @@ func (h *ScheduleHandler) Create(c echo.Context) error {
 
 // List godoc
 // @Summary List schedules
-// @Description List schedules for current user
+// @Description List schedules for current user. Archived one-shot schedules are only returned with archived=true.
 // @Tags schedule
+// @Param archived query bool false "List archived one-shot schedules instead of active ones"
 // @Success 200 {object} schedule.ListResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -90,7 +95,11 @@ func (h *ScheduleHandler) List(c echo.Context) error {
 	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
 		return err
 	}
-	items, err := h.service.List(c.Request().Context(), botID)
+	list := h.service.List
+	if archived, _ := strconv.ParseBool(c.QueryParam("archived")); archived {
+		list = h.service.ListArchived
+	}
+	items, err := list(c.Request().Context(), botID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -215,6 +224,123 @@ func (h *ScheduleHandler) Delete(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// ListBotRuns godoc
+// @Summary List schedule runs for a bot
+// @Description List the most recent runs across all schedules of a bot, newest first
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Param limit query int false "Max items to return" default(20)
+// @Success 200 {object} schedule.ListRunsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedule/runs [get]
+func (h *ScheduleHandler) ListBotRuns(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+	items, err := h.service.ListRunsByBot(c.Request().Context(), botID, parseRunLimit(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, schedule.ListRunsResponse{Items: items})
+}
+
+// ListRuns godoc
+// @Summary List schedule runs
+// @Description List the most recent runs of a schedule, newest first
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Schedule ID"
+// @Param limit query int false "Max items to return" default(20)
+// @Success 200 {object} schedule.ListRunsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedule/{id}/runs [get]
+func (h *ScheduleHandler) ListRuns(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	id := c.Param("id")
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "id is required")
+	}
+	item, err := h.service.Get(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if item.BotID != botID {
+		return echo.NewHTTPError(http.StatusForbidden, "bot mismatch")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+	items, err := h.service.ListRuns(c.Request().Context(), id, parseRunLimit(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, schedule.ListRunsResponse{Items: items})
+}
+
+// GetRun godoc
+// @Summary Get schedule run
+// @Description Get a single schedule run by ID
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Param run_id path string true "Run ID"
+// @Success 200 {object} schedule.Run
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedule/runs/{run_id} [get]
+func (h *ScheduleHandler) GetRun(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	runID := c.Param("run_id")
+	if runID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "run id is required")
+	}
+	run, err := h.service.GetRun(c.Request().Context(), runID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if run.BotID != botID {
+		return echo.NewHTTPError(http.StatusForbidden, "bot mismatch")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, run)
+}
+
+func parseRunLimit(c echo.Context) int {
+	limit := schedule.DefaultRunLimit
+	if v := c.QueryParam("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	return limit
+}
+
 func (h *ScheduleHandler) requireUserID(c echo.Context) (string, error) {
 	return RequireChannelIdentityID(c)
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	dbsqlc "github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/memory"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/models"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/settings"
 )
@@ -23,17 +24,19 @@ type SettingsHandler struct {
 	botService     *bots.Service
 	accountService *accounts.Service
 	modelsService  *models.Service
+	memoryService  *memory.Service
 	queries        *dbsqlc.Queries
 	mcpCfg         config.MCPConfig
 	logger         *slog.Logger
 }
 
-func NewSettingsHandler(log *slog.Logger, service *settings.Service, botService *bots.Service, accountService *accounts.Service, modelsService *models.Service, queries *dbsqlc.Queries, cfg config.Config) *SettingsHandler {
+func NewSettingsHandler(log *slog.Logger, service *settings.Service, botService *bots.Service, accountService *accounts.Service, modelsService *models.Service, memoryService *memory.Service, queries *dbsqlc.Queries, cfg config.Config) *SettingsHandler {
 	return &SettingsHandler{
 		service:        service,
 		botService:     botService,
 		accountService: accountService,
 		modelsService:  modelsService,
+		memoryService:  memoryService,
 		queries:        queries,
 		mcpCfg:         cfg.MCP,
 		logger:         log.With(slog.String("handler", "settings")),
@@ -102,6 +105,7 @@ func (h *SettingsHandler) Upsert(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	previous, _ := h.service.GetBot(c.Request().Context(), botID)
 	resp, err := h.service.UpsertBot(c.Request().Context(), botID, req)
 	if err != nil {
 		if errors.Is(err, settings.ErrPersonalBotGuestAccessUnsupported) {
@@ -113,6 +117,7 @@ func (h *SettingsHandler) Upsert(c echo.Context) error {
 
 	// Regenerate ov.conf when OpenViking is enabled and models may have changed.
 	h.syncOVConfIfNeeded(c.Request().Context(), botID)
+	h.startReembedIfNeeded(c.Request().Context(), botID, previous.EmbeddingModelID, resp.EmbeddingModelID)
 
 	return c.JSON(http.StatusOK, resp)
 }
@@ -171,6 +176,23 @@ func (h *SettingsHandler) syncOVConfIfNeeded(ctx context.Context, botID string)
 	syncer.ensureOVConf(ctx, botID)
 }
 
+// startReembedIfNeeded migrates the bot's memories when its embedding model
+// changes, so recall does not silently degrade until memory is wiped.
+func (h *SettingsHandler) startReembedIfNeeded(ctx context.Context, botID, previousModelID, modelID string) {
+	if h.memoryService == nil || modelID == "" || modelID == previousModelID {
+		return
+	}
+	job, err := h.memoryService.StartReembed(ctx, botID, modelID)
+	if errors.Is(err, memory.ErrReembedNotNeeded) {
+		return
+	}
+	if err != nil {
+		h.logger.Warn("start embedding migration failed", slog.String("botID", botID), slog.String("model", modelID), slog.Any("error", err))
+		return
+	}
+	h.logger.Info("embedding migration started", slog.String("botID", botID), slog.String("jobID", job.ID), slog.String("model", modelID))
+}
+
 func (h *SettingsHandler) requireChannelIdentityID(c echo.Context) (string, error) {
 	return RequireChannelIdentityID(c)
 }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/telegram"
+)
+
+// maxTelegramUpdateBytes bounds a webhook body; updates are small JSON
+// documents and attachments are fetched separately by file ID.
+const maxTelegramUpdateBytes = 1 << 20
+
+// TelegramWebhookHandler receives updates for Telegram channel configs that
+// run in webhook mode.
+type TelegramWebhookHandler struct {
+	adapter *telegram.TelegramAdapter
+	logger  *slog.Logger
+}
+
+// NewTelegramWebhookHandler creates a Telegram webhook handler.
+func NewTelegramWebhookHandler(log *slog.Logger, adapter *telegram.TelegramAdapter) *TelegramWebhookHandler {
+	return &TelegramWebhookHandler{
+		adapter: adapter,
+		logger:  log.With(slog.String("handler", "telegram_webhook")),
+	}
+}
+
+// Register registers the Telegram webhook route.
+func (h *TelegramWebhookHandler) Register(e *echo.Echo) {
+	e.POST("/channels/telegram/webhook/:config_id", h.HandleWebhook)
+}
+
+// HandleWebhook accepts one Telegram update.
+//
+// @Summary Telegram Webhook Endpoint
+// @Description Receives updates from Telegram for a channel config in webhook mode. The request must carry the configured secret in X-Telegram-Bot-Api-Secret-Token.
+// @Tags webhook
+// @Accept json
+// @Param config_id path string true "Channel config ID"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /channels/telegram/webhook/{config_id} [post]
+func (h *TelegramWebhookHandler) HandleWebhook(c echo.Context) error {
+	configID := strings.TrimSpace(c.Param("config_id"))
+	if configID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "config_id is required")
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxTelegramUpdateBytes))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "read body failed")
+	}
+	secret := c.Request().Header.Get("X-Telegram-Bot-Api-Secret-Token")
+	if err := h.adapter.HandleWebhook(configID, secret, body); err != nil {
+		switch {
+		case errors.Is(err, telegram.ErrWebhookNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, telegram.ErrWebhookUnauthorized):
+			h.logger.Warn("telegram webhook secret mismatch", slog.String("config_id", configID), slog.String("remote_ip", c.RealIP()))
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid secret token")
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	return c.NoContent(http.StatusOK)
+}
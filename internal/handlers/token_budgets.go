@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/budget"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+)
+
+// TokenBudgetHandler manages daily and monthly token budgets.
+type TokenBudgetHandler struct {
+	store          *budget.Store
+	botService     *bots.Service
+	accountService *accounts.Service
+}
+
+// TokenBudgetItem is a stored budget with the usage counted against it.
+type TokenBudgetItem struct {
+	budget.Budget
+	Usage budget.Usage `json:"usage"`
+}
+
+// NewTokenBudgetHandler creates a new TokenBudgetHandler.
+func NewTokenBudgetHandler(store *budget.Store, botService *bots.Service, accountService *accounts.Service) *TokenBudgetHandler {
+	return &TokenBudgetHandler{
+		store:          store,
+		botService:     botService,
+		accountService: accountService,
+	}
+}
+
+// Register registers token budget routes.
+func (h *TokenBudgetHandler) Register(e *echo.Echo) {
+	e.GET("/token-budgets", h.List)
+	e.PUT("/token-budgets", h.Upsert)
+	e.DELETE("/token-budgets", h.Delete)
+}
+
+// List godoc
+// @Summary List token budgets of a bot, user or channel identity
+// @Description Returns the interactive and background budgets with their usage in the current UTC day and month. Bot members may read bot budgets and users their own; everything else is admin-only.
+// @Tags token-usage
+// @Param scope query string true "bot, user or channel_identity"
+// @Param scope_id query string true "Bot, user or channel identity ID"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /token-budgets [get]
+func (h *TokenBudgetHandler) List(c echo.Context) error {
+	scope, scopeID, err := parseBudgetScope(c.QueryParam("scope"), c.QueryParam("scope_id"))
+	if err != nil {
+		return err
+	}
+	if err := h.authorizeRead(c, scope, scopeID); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	budgets, err := h.store.List(ctx, scope, scopeID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	now := time.Now()
+	items := make([]TokenBudgetItem, 0, len(budgets))
+	for _, b := range budgets {
+		usage, err := h.store.Usage(ctx, scope, scopeID, b.Class, now)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		items = append(items, TokenBudgetItem{Budget: b, Usage: usage})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// Upsert godoc
+// @Summary Create or update a token budget
+// @Description Sets the daily and monthly token limits (0 = unlimited) for a scope and task class. Interactive covers chat; background covers heartbeat, schedule and subagent runs. Owners are warned at soft_limit_percent (default 80). Admin only.
+// @Tags token-usage
+// @Param payload body budget.Budget true "Token budget"
+// @Success 200 {object} budget.Budget
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /token-budgets [put]
+func (h *TokenBudgetHandler) Upsert(c echo.Context) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	var req budget.Budget
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	scope, scopeID, err := parseBudgetScope(string(req.Scope), req.ScopeID)
+	if err != nil {
+		return err
+	}
+	class, err := budget.ParseClass(string(req.Class))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	req.Scope, req.ScopeID, req.Class = scope, scopeID, class
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	saved, err := h.store.Upsert(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, saved)
+}
+
+// Delete godoc
+// @Summary Delete a token budget
+// @Description Removes the budget, leaving the scope unlimited for that task class. Admin only.
+// @Tags token-usage
+// @Param scope query string true "bot, user or channel_identity"
+// @Param scope_id query string true "Bot, user or channel identity ID"
+// @Param task_class query string false "interactive (default) or background"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /token-budgets [delete]
+func (h *TokenBudgetHandler) Delete(c echo.Context) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	scope, scopeID, err := parseBudgetScope(c.QueryParam("scope"), c.QueryParam("scope_id"))
+	if err != nil {
+		return err
+	}
+	class, err := budget.ParseClass(c.QueryParam("task_class"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.store.Delete(c.Request().Context(), scope, scopeID, class); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *TokenBudgetHandler) authorizeRead(c echo.Context, scope budget.Scope, scopeID string) error {
+	channelIdentityID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	switch scope {
+	case budget.ScopeBot:
+		_, err := AuthorizeBotAccess(ctx, h.botService, h.accountService, channelIdentityID, scopeID, bots.AccessPolicy{AllowPublicMember: false})
+		return err
+	case budget.ScopeUser:
+		if scopeID == channelIdentityID {
+			return nil
+		}
+	}
+	return h.requireAdmin(c)
+}
+
+func (h *TokenBudgetHandler) requireAdmin(c echo.Context) error {
+	channelIdentityID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	isAdmin, err := h.accountService.IsAdmin(c.Request().Context(), channelIdentityID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !isAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "only admins can manage token budgets")
+	}
+	return nil
+}
+
+func parseBudgetScope(rawScope, rawID string) (budget.Scope, string, error) {
+	scope, err := budget.ParseScope(rawScope)
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	scopeID := strings.TrimSpace(rawID)
+	if scopeID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "scope_id is required")
+	}
+	if _, err := db.ParseUUID(scopeID); err != nil {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "invalid scope_id")
+	}
+	return scope, scopeID, nil
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -37,6 +39,11 @@ func (h *TokenUsageHandler) Register(e *echo.Echo) {
 	e.GET("/token-usage/all", h.GetAllBotsTotals)
 	e.GET("/token-usage/daily", h.GetAllBotsDaily)
 	e.GET("/token-usage/by-model", h.GetByModel)
+	e.GET("/token-usage/cost/by-bot", h.GetCostByBot)
+	e.GET("/token-usage/cost/by-model", h.GetCostByModel)
+	e.GET("/token-usage/cost/by-source", h.GetCostBySource)
+	e.GET("/token-usage/cost/daily", h.GetCostDaily)
+	e.GET("/token-usage/export.csv", h.ExportCSV)
 }
 
 // GetBotTotal godoc
@@ -208,6 +215,230 @@ func (h *TokenUsageHandler) GetByModel(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]any{"items": items})
 }
 
+// CostTotal is the token usage and cost of one group (bot, model or source).
+// Cost is in USD, computed from the model prices at the time of each call.
+type CostTotal struct {
+	Key              string         `json:"key"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	CachedTokens     int64          `json:"cached_tokens"`
+	TotalTokens      int64          `json:"total_tokens"`
+	Cost             pgtype.Numeric `json:"cost" swaggertype:"number"`
+}
+
+// GetCostByBot godoc
+// @Summary Get token cost grouped by bot
+// @Tags token-usage
+// @Param days query int false "Number of days (default 30)"
+// @Success 200 {object} map[string]any
+// @Router /token-usage/cost/by-bot [get]
+func (h *TokenUsageHandler) GetCostByBot(c echo.Context) error {
+	if _, err := h.requireAccess(c); err != nil {
+		return err
+	}
+	since, until := parseDateRange(c)
+	rows, err := h.queries.GetCostByBot(c.Request().Context(), dbsqlc.GetCostByBotParams{
+		Since: toPgTimestamptz(since),
+		Until: toPgTimestamptz(until),
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	items := make([]CostTotal, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, CostTotal{
+			Key:              db.UUIDToString(r.BotID),
+			PromptTokens:     r.PromptTokens,
+			CompletionTokens: r.CompletionTokens,
+			CachedTokens:     r.CachedTokens,
+			TotalTokens:      r.TotalTokens,
+			Cost:             r.Cost,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// GetCostByModel godoc
+// @Summary Get token cost grouped by model
+// @Tags token-usage
+// @Param days query int false "Number of days (default 30)"
+// @Param bot_id query string false "Only count this bot"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} ErrorResponse
+// @Router /token-usage/cost/by-model [get]
+func (h *TokenUsageHandler) GetCostByModel(c echo.Context) error {
+	if _, err := h.requireAccess(c); err != nil {
+		return err
+	}
+	botUUID, err := parseOptionalBotID(c)
+	if err != nil {
+		return err
+	}
+	since, until := parseDateRange(c)
+	rows, err := h.queries.GetCostByModel(c.Request().Context(), dbsqlc.GetCostByModelParams{
+		Since: toPgTimestamptz(since),
+		Until: toPgTimestamptz(until),
+		BotID: botUUID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	items := make([]CostTotal, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, CostTotal{
+			Key:              r.Model,
+			PromptTokens:     r.PromptTokens,
+			CompletionTokens: r.CompletionTokens,
+			CachedTokens:     r.CachedTokens,
+			TotalTokens:      r.TotalTokens,
+			Cost:             r.Cost,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// GetCostBySource godoc
+// @Summary Get token cost grouped by source
+// @Description Sources are chat, heartbeat, schedule, subagent and summarize.
+// @Tags token-usage
+// @Param days query int false "Number of days (default 30)"
+// @Param bot_id query string false "Only count this bot"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} ErrorResponse
+// @Router /token-usage/cost/by-source [get]
+func (h *TokenUsageHandler) GetCostBySource(c echo.Context) error {
+	if _, err := h.requireAccess(c); err != nil {
+		return err
+	}
+	botUUID, err := parseOptionalBotID(c)
+	if err != nil {
+		return err
+	}
+	since, until := parseDateRange(c)
+	rows, err := h.queries.GetCostBySource(c.Request().Context(), dbsqlc.GetCostBySourceParams{
+		Since: toPgTimestamptz(since),
+		Until: toPgTimestamptz(until),
+		BotID: botUUID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	items := make([]CostTotal, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, CostTotal{
+			Key:              r.Source,
+			PromptTokens:     r.PromptTokens,
+			CompletionTokens: r.CompletionTokens,
+			CachedTokens:     r.CachedTokens,
+			TotalTokens:      r.TotalTokens,
+			Cost:             r.Cost,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// GetCostDaily godoc
+// @Summary Get daily token cost series
+// @Tags token-usage
+// @Param days query int false "Number of days (default 30)"
+// @Param bot_id query string false "Only count this bot"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} ErrorResponse
+// @Router /token-usage/cost/daily [get]
+func (h *TokenUsageHandler) GetCostDaily(c echo.Context) error {
+	if _, err := h.requireAccess(c); err != nil {
+		return err
+	}
+	botUUID, err := parseOptionalBotID(c)
+	if err != nil {
+		return err
+	}
+	since, until := parseDateRange(c)
+	rows, err := h.queries.GetCostDailySeries(c.Request().Context(), dbsqlc.GetCostDailySeriesParams{
+		Since: toPgTimestamptz(since),
+		Until: toPgTimestamptz(until),
+		BotID: botUUID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	type dailyEntry struct {
+		Day         string         `json:"day"`
+		TotalTokens int64          `json:"total_tokens"`
+		Cost        pgtype.Numeric `json:"cost"`
+	}
+	items := make([]dailyEntry, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dailyEntry{
+			Day:         r.Day.Time.Format("2006-01-02"),
+			TotalTokens: r.TotalTokens,
+			Cost:        r.Cost,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// ExportCSV godoc
+// @Summary Export token usage and cost as CSV
+// @Description One row per model call, oldest first. Cost is in USD.
+// @Tags token-usage
+// @Produce text/csv
+// @Param days query int false "Number of days (default 30)"
+// @Param bot_id query string false "Only export this bot"
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Router /token-usage/export.csv [get]
+func (h *TokenUsageHandler) ExportCSV(c echo.Context) error {
+	if _, err := h.requireAccess(c); err != nil {
+		return err
+	}
+	botUUID, err := parseOptionalBotID(c)
+	if err != nil {
+		return err
+	}
+	since, until := parseDateRange(c)
+	rows, err := h.queries.ListTokenUsageForExport(c.Request().Context(), dbsqlc.ListTokenUsageForExportParams{
+		Since: toPgTimestamptz(since),
+		Until: toPgTimestamptz(until),
+		BotID: botUUID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	filename := "token-usage-" + since.Format("20060102") + "-" + until.Add(-time.Second).Format("20060102") + ".csv"
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	resp.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	resp.WriteHeader(http.StatusOK)
+	w := csv.NewWriter(resp)
+	_ = w.Write([]string{"created_at", "bot_id", "bot_name", "model", "source", "prompt_tokens", "completion_tokens", "cached_tokens", "total_tokens", "cost_usd"})
+	for _, r := range rows {
+		_ = w.Write([]string{
+			r.CreatedAt.Time.UTC().Format(time.RFC3339),
+			db.UUIDToString(r.BotID),
+			csvSafe(r.BotName),
+			csvSafe(r.Model),
+			csvSafe(r.Source),
+			strconv.Itoa(int(r.PromptTokens)),
+			strconv.Itoa(int(r.CompletionTokens)),
+			strconv.Itoa(int(r.CachedTokens)),
+			strconv.Itoa(int(r.TotalTokens)),
+			db.NumericToString(r.Cost),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvSafe neutralises values a spreadsheet would evaluate as a formula by
+// prefixing them with a quote.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
 func (h *TokenUsageHandler) requireAccess(c echo.Context) (string, error) {
 	return RequireChannelIdentityID(c)
 }
@@ -240,6 +471,18 @@ func parseInt(s string) int {
 	return n
 }
 
+func parseOptionalBotID(c echo.Context) (pgtype.UUID, error) {
+	botID := strings.TrimSpace(c.QueryParam("bot_id"))
+	if botID == "" {
+		return pgtype.UUID{}, nil
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return pgtype.UUID{}, echo.NewHTTPError(http.StatusBadRequest, "invalid bot id")
+	}
+	return botUUID, nil
+}
+
 func toPgTimestamptz(t time.Time) pgtype.Timestamptz {
 	return pgtype.Timestamptz{Time: t, Valid: true}
 }
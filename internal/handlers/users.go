@@ -11,6 +11,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/apikeys"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/channel"
@@ -31,6 +32,7 @@ type UsersHandler struct {
 	channelManager         *channel.Manager
 	registry               *channel.Registry
 	heartbeatEngine        *heartbeat.Engine
+	apiKeys                *apikeys.Service
 	logger                 *slog.Logger
 }
 
@@ -40,7 +42,7 @@ type listMyIdentitiesResponse struct {
 }
 
 // NewUsersHandler creates a UsersHandler with channel identity support.
-func NewUsersHandler(log *slog.Logger, service *accounts.Service, channelIdentityService *identities.Service, botService *bots.Service, routeService route.Service, channelService *channel.Service, channelManager *channel.Manager, registry *channel.Registry, heartbeatEngine *heartbeat.Engine) *UsersHandler {
+func NewUsersHandler(log *slog.Logger, service *accounts.Service, channelIdentityService *identities.Service, botService *bots.Service, routeService route.Service, channelService *channel.Service, channelManager *channel.Manager, registry *channel.Registry, heartbeatEngine *heartbeat.Engine, apiKeys *apikeys.Service) *UsersHandler {
 	if log == nil {
 		log = slog.Default()
 	}
@@ -53,6 +55,7 @@ func NewUsersHandler(log *slog.Logger, service *accounts.Service, channelIdentit
 		channelManager:         channelManager,
 		heartbeatEngine:        heartbeatEngine,
 		registry:               registry,
+		apiKeys:                apiKeys,
 		logger:                 log.With(slog.String("handler", "users")),
 	}
 }
@@ -63,6 +66,9 @@ func (h *UsersHandler) Register(e *echo.Echo) {
 	userGroup.GET("/me/identities", h.ListMyIdentities)
 	userGroup.PUT("/me", h.UpdateMe)
 	userGroup.PUT("/me/password", h.UpdateMyPassword)
+	userGroup.GET("/me/tokens", h.ListMyTokens)
+	userGroup.POST("/me/tokens", h.CreateMyToken)
+	userGroup.DELETE("/me/tokens/:id", h.DeleteMyToken)
 	userGroup.GET("", h.ListUsers)
 	userGroup.GET("/:id", h.GetUser)
 	userGroup.PUT("/:id", h.UpdateUser)
@@ -82,6 +88,9 @@ func (h *UsersHandler) Register(e *echo.Echo) {
 	botGroup.GET("/:id/members", h.ListBotMembers)
 	botGroup.PUT("/:id/members", h.UpsertBotMember)
 	botGroup.DELETE("/:id/members/:user_id", h.DeleteBotMember)
+	botGroup.GET("/:id/api-keys", h.ListBotAPIKeys)
+	botGroup.POST("/:id/api-keys", h.CreateBotAPIKey)
+	botGroup.DELETE("/:id/api-keys/:key_id", h.DeleteBotAPIKey)
 	botGroup.GET("/:id/channel/:platform", h.GetBotChannelConfig)
 	botGroup.PUT("/:id/channel/:platform", h.UpsertBotChannelConfig)
 	botGroup.POST("/:id/channel/:platform/send", h.SendBotMessage)
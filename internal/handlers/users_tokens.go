@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/apikeys"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/auth"
+)
+
+// ListMyTokens godoc
+// @Summary List personal access tokens
+// @Description List the current user's personal access tokens. Secrets are never returned, only their prefix.
+// @Tags users
+// @Success 200 {object} apikeys.TokenListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me/tokens [get]
+func (h *UsersHandler) ListMyTokens(c echo.Context) error {
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.apiKeys.ListTokens(c.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, apikeys.TokenListResponse{Items: items})
+}
+
+// CreateMyToken godoc
+// @Summary Create a personal access token
+// @Description Create a long-lived token that authenticates like a login JWT, limited to its scopes (chat, read-messages, manage-bot, admin). The secret is only returned in this response.
+// @Tags users
+// @Param payload body apikeys.CreateTokenRequest true "Token payload"
+// @Success 201 {object} apikeys.CreatedToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me/tokens [post]
+func (h *UsersHandler) CreateMyToken(c echo.Context) error {
+	if err := rejectAPITokenIssuer(c); err != nil {
+		return err
+	}
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	var req apikeys.CreateTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	token, err := h.apiKeys.CreateToken(c.Request().Context(), userID, req)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(http.StatusCreated, token)
+}
+
+// DeleteMyToken godoc
+// @Summary Revoke a personal access token
+// @Tags users
+// @Param id path string true "Token ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me/tokens/{id} [delete]
+func (h *UsersHandler) DeleteMyToken(c echo.Context) error {
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "id is required")
+	}
+	if err := h.apiKeys.DeleteToken(c.Request().Context(), userID, id); err != nil {
+		return apiKeyError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListBotAPIKeys godoc
+// @Summary List bot API keys
+// @Description List the API keys of a bot. Secrets are never returned, only their prefix.
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Success 200 {object} apikeys.ListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/api-keys [get]
+func (h *UsersHandler) ListBotAPIKeys(c echo.Context) error {
+	_, botID, err := h.authorizeBotAPIKeys(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.apiKeys.List(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, apikeys.ListResponse{Items: items})
+}
+
+// CreateBotAPIKey godoc
+// @Summary Create a bot API key
+// @Description Create a key limited to this bot, acting as its owner. Scopes default to chat, which covers the OpenAI-compatible /v1 endpoints; read-messages and manage-bot are also allowed. Requires a login session; API tokens cannot create keys. The secret is only returned in this response.
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param payload body apikeys.CreateRequest true "Key payload"
+// @Success 201 {object} apikeys.CreatedKey
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/api-keys [post]
+func (h *UsersHandler) CreateBotAPIKey(c echo.Context) error {
+	if err := rejectAPITokenIssuer(c); err != nil {
+		return err
+	}
+	userID, botID, err := h.authorizeBotAPIKeys(c)
+	if err != nil {
+		return err
+	}
+	var req apikeys.CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	key, err := h.apiKeys.Create(c.Request().Context(), botID, userID, req)
+	if err != nil {
+		return apiKeyError(err)
+	}
+	return c.JSON(http.StatusCreated, key)
+}
+
+// DeleteBotAPIKey godoc
+// @Summary Revoke a bot API key
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param key_id path string true "Key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/api-keys/{key_id} [delete]
+func (h *UsersHandler) DeleteBotAPIKey(c echo.Context) error {
+	_, botID, err := h.authorizeBotAPIKeys(c)
+	if err != nil {
+		return err
+	}
+	keyID := strings.TrimSpace(c.Param("key_id"))
+	if keyID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "key id is required")
+	}
+	if err := h.apiKeys.Delete(c.Request().Context(), botID, keyID); err != nil {
+		return apiKeyError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// authorizeBotAPIKeys returns the caller and the bot, which the caller must
+// be able to manage: a key acts with the owner's permissions.
+func (h *UsersHandler) authorizeBotAPIKeys(c echo.Context) (string, string, error) {
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", "", err
+	}
+	botID := strings.TrimSpace(c.Param("id"))
+	if botID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", "", err
+	}
+	return userID, botID, nil
+}
+
+// rejectAPITokenIssuer refuses to mint tokens or keys for a request that is
+// itself authenticated with one: a manage-bot key could otherwise issue keys
+// with scopes it does not hold, and a leaked token could outlive its revocation.
+func rejectAPITokenIssuer(c echo.Context) error {
+	if auth.IsAPITokenRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "api tokens cannot create tokens or keys; sign in instead")
+	}
+	return nil
+}
+
+func apiKeyError(err error) error {
+	switch {
+	case errors.Is(err, apikeys.ErrInvalidRequest):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, apikeys.ErrKeyNotFound), errors.Is(err, apikeys.ErrTokenNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/webhook"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/webhooks"
+)
+
+// maxWebhookChannelBodyBytes bounds an inbound webhook channel request.
+const maxWebhookChannelBodyBytes = 1 << 20
+
+// WebhookChannelHandler receives messages for generic webhook channel configs.
+type WebhookChannelHandler struct {
+	adapter *webhook.WebhookAdapter
+	logger  *slog.Logger
+}
+
+// NewWebhookChannelHandler creates a webhook channel handler.
+func NewWebhookChannelHandler(log *slog.Logger, adapter *webhook.WebhookAdapter) *WebhookChannelHandler {
+	return &WebhookChannelHandler{
+		adapter: adapter,
+		logger:  log.With(slog.String("handler", "webhook_channel")),
+	}
+}
+
+// Register registers the webhook channel route.
+func (h *WebhookChannelHandler) Register(e *echo.Echo) {
+	e.POST("/channels/webhook/inbound/:config_id", h.HandleWebhook)
+}
+
+// HandleWebhook accepts one message for a webhook channel config.
+//
+// @Summary Generic Webhook Channel Endpoint
+// @Description Receives a JSON message for a webhook channel config. Requests are authenticated with X-Memoh-Timestamp and X-Memoh-Signature headers, or with the secret as the token query parameter or X-Memoh-Token header in token mode. In sync reply mode the bot's replies are returned in the response; in callback mode the request is answered with 202 and replies are POSTed to the callback URL.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param config_id path string true "Channel config ID"
+// @Param token query string false "Secret in token auth mode"
+// @Success 200 {object} webhook.Response
+// @Success 202 {object} webhook.Response
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/webhook/inbound/{config_id} [post]
+func (h *WebhookChannelHandler) HandleWebhook(c echo.Context) error {
+	configID := strings.TrimSpace(c.Param("config_id"))
+	if configID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "config_id is required")
+	}
+	// Read one byte past the limit so an oversized body is rejected rather
+	// than silently truncated.
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookChannelBodyBytes+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "read body failed")
+	}
+	if len(body) > maxWebhookChannelBodyBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "request body exceeds 1 MiB")
+	}
+	headers := c.Request().Header
+	token := c.QueryParam("token")
+	if token == "" {
+		token = headers.Get(webhook.TokenHeader)
+	}
+	resp, err := h.adapter.HandleWebhook(c.Request().Context(), configID, webhook.Request{
+		Body:      body,
+		Timestamp: headers.Get(webhooks.HeaderTimestamp),
+		Signature: headers.Get(webhooks.HeaderSignature),
+		Token:     token,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, webhook.ErrWebhookNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, webhook.ErrWebhookUnauthorized):
+			h.logger.Warn("webhook channel authentication failed", slog.String("config_id", configID), slog.String("remote_ip", c.RealIP()))
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature or token")
+		case errors.Is(err, webhook.ErrInvalidPayload):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case errors.Is(err, webhook.ErrMessageInFlight):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			h.logger.Error("webhook channel message failed", slog.String("config_id", configID), slog.Any("error", err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "message processing failed")
+		}
+	}
+	if resp.Async {
+		return c.JSON(http.StatusAccepted, resp)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/channel/adapters/webhook"
+)
+
+func TestHandleWebhookRejectsOversizedBody(t *testing.T) {
+	e := echo.New()
+	handler := NewWebhookChannelHandler(slog.Default(), webhook.NewWebhookAdapter(nil, nil))
+
+	send := func(size int) error {
+		req := httptest.NewRequest(http.MethodPost, "/channels/webhook/inbound/cfg-1", bytes.NewReader(bytes.Repeat([]byte("a"), size)))
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath("/channels/webhook/inbound/:config_id")
+		c.SetParamNames("config_id")
+		c.SetParamValues("cfg-1")
+		return handler.HandleWebhook(c)
+	}
+
+	httpErr, ok := send(maxWebhookChannelBodyBytes + 1).(*echo.HTTPError)
+	if !ok || httpErr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for an oversized body, got %v", httpErr)
+	}
+	// A body at the limit reaches the adapter, which does not know the config.
+	httpErr, ok = send(maxWebhookChannelBodyBytes).(*echo.HTTPError)
+	if !ok || httpErr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a body at the limit, got %v", httpErr)
+	}
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/webhooks"
+)
+
+type WebhooksHandler struct {
+	service        *webhooks.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+// EventTypesResponse lists the event types a webhook can subscribe to.
+type EventTypesResponse struct {
+	Items []string `json:"items"`
+}
+
+func NewWebhooksHandler(log *slog.Logger, service *webhooks.Service, botService *bots.Service, accountService *accounts.Service) *WebhooksHandler {
+	return &WebhooksHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "webhooks")),
+	}
+}
+
+func (h *WebhooksHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/webhooks")
+	group.POST("", h.Create)
+	group.GET("", h.List)
+	group.GET("/events", h.ListEventTypes)
+	group.GET("/:id", h.Get)
+	group.PUT("/:id", h.Update)
+	group.DELETE("/:id", h.Delete)
+	group.POST("/:id/rotate-secret", h.RotateSecret)
+	group.POST("/:id/ping", h.Ping)
+	group.GET("/:id/deliveries", h.ListDeliveries)
+	group.POST("/:id/deliveries/:delivery_id/redeliver", h.Redeliver)
+}
+
+// Create godoc
+// @Summary Create webhook
+// @Description Subscribe an external URL to bot events. The signing secret is only returned here and on rotation.
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param payload body webhooks.CreateRequest true "Webhook payload"
+// @Success 201 {object} webhooks.CreatedWebhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks [post]
+func (h *WebhooksHandler) Create(c echo.Context) error {
+	userID, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	var req webhooks.CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	created, err := h.service.Create(c.Request().Context(), botID, userID, req)
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// List godoc
+// @Summary List webhooks
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} webhooks.ListResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks [get]
+func (h *WebhooksHandler) List(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.List(c.Request().Context(), botID)
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, webhooks.ListResponse{Items: items})
+}
+
+// ListEventTypes godoc
+// @Summary List webhook event types
+// @Description List the event types a webhook can filter on
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} EventTypesResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/events [get]
+func (h *WebhooksHandler) ListEventTypes(c echo.Context) error {
+	if _, _, err := h.requireBot(c); err != nil {
+		return err
+	}
+	items := make([]string, 0, len(event.AllEventTypes))
+	for _, t := range event.AllEventTypes {
+		items = append(items, string(t))
+	}
+	return c.JSON(http.StatusOK, EventTypesResponse{Items: items})
+}
+
+// Get godoc
+// @Summary Get webhook
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} webhooks.Webhook
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id} [get]
+func (h *WebhooksHandler) Get(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	item, err := h.service.Get(c.Request().Context(), botID, c.Param("id"))
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// Update godoc
+// @Summary Update webhook
+// @Description Change the name, URL, event filter or enabled state of a webhook
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Param payload body webhooks.UpdateRequest true "Webhook payload"
+// @Success 200 {object} webhooks.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id} [put]
+func (h *WebhooksHandler) Update(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	var req webhooks.UpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	item, err := h.service.Update(c.Request().Context(), botID, c.Param("id"), req)
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// Delete godoc
+// @Summary Delete webhook
+// @Description Delete a webhook and its delivery log
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id} [delete]
+func (h *WebhooksHandler) Delete(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	if err := h.service.Delete(c.Request().Context(), botID, c.Param("id")); err != nil {
+		return webhookError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RotateSecret godoc
+// @Summary Rotate webhook secret
+// @Description Replace the signing secret. The new secret is only returned in this response.
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} webhooks.CreatedWebhook
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id}/rotate-secret [post]
+func (h *WebhooksHandler) RotateSecret(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	item, err := h.service.RotateSecret(c.Request().Context(), botID, c.Param("id"))
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// Ping godoc
+// @Summary Ping webhook
+// @Description Queue a ping event for the webhook, ignoring its event filter
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Success 202 {object} webhooks.Delivery
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id}/ping [post]
+func (h *WebhooksHandler) Ping(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := h.service.Ping(c.Request().Context(), botID, c.Param("id"))
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries of a webhook, newest first
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 200)"
+// @Success 200 {object} webhooks.DeliveryListResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id}/deliveries [get]
+func (h *WebhooksHandler) ListDeliveries(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+	items, err := h.service.ListDeliveries(c.Request().Context(), botID, c.Param("id"), limit)
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, webhooks.DeliveryListResponse{Items: items})
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook delivery
+// @Description Queue a delivery again with a fresh retry budget
+// @Tags webhooks
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 202 {object} webhooks.Delivery
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhooksHandler) Redeliver(c echo.Context) error {
+	_, botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := h.service.Redeliver(c.Request().Context(), botID, c.Param("id"), c.Param("delivery_id"))
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+// requireBot resolves the caller and checks they may manage the bot.
+func (h *WebhooksHandler) requireBot(c echo.Context) (string, string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", "", err
+	}
+	return userID, botID, nil
+}
+
+func (h *WebhooksHandler) authorizeBotAccess(ctx context.Context, channelIdentityID, botID string) (bots.Bot, error) {
+	return AuthorizeBotAccess(ctx, h.botService, h.accountService, channelIdentityID, botID, bots.AccessPolicy{AllowPublicMember: false})
+}
+
+func webhookError(err error) error {
+	switch {
+	case errors.Is(err, webhooks.ErrInvalidRequest):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, webhooks.ErrWebhookNotFound), errors.Is(err, webhooks.ErrDeliveryNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -21,6 +21,14 @@ import (
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
 	msgEvent "github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+)
+
+var (
+	heartbeatFires = metrics.NewCounter("memoh_heartbeat_fires_total",
+		"Heartbeats fired by trigger (periodic, an event trigger, manual) and result (ok, error).", "trigger", "result")
+	heartbeatSkips = metrics.NewCounter("memoh_heartbeat_skips_total",
+		"Heartbeats skipped by reason (inactive_hours, shutdown).", "reason")
 )
 
 // Engine manages periodic and event-driven heartbeats for all bots.
@@ -360,8 +368,8 @@ func (e *Engine) startConfig(cfg Config) {
 	if cfg.IntervalSeconds > 0 && e.pool != nil {
 		pattern := "@every " + strconv.Itoa(cfg.IntervalSeconds) + "s"
 		cfgCopy := cfg
-		if err := e.pool.Add(cfg.ID, pattern, func() {
-			e.onPeriodicTick(cfgCopy)
+		if err := e.pool.Add(cfg.ID, pattern, func() error {
+			return e.onPeriodicTick(cfgCopy)
 		}); err != nil {
 			e.logger.Error("failed to register heartbeat cron job",
 				slog.String("config_id", cfg.ID),
@@ -403,10 +411,11 @@ func (e *Engine) restartConfig(cfg Config) {
 }
 
 // onPeriodicTick fires when the cron scheduler triggers the heartbeat interval.
-func (e *Engine) onPeriodicTick(cfg Config) {
+func (e *Engine) onPeriodicTick(cfg Config) error {
 	select {
 	case <-e.ctx.Done():
-		return
+		heartbeatSkips.Inc("shutdown")
+		return nil
 	default:
 	}
 
@@ -415,13 +424,15 @@ func (e *Engine) onPeriodicTick(cfg Config) {
 		slog.String("bot_id", cfg.BotID),
 	)
 
-	if err := e.fire(context.Background(), cfg, "periodic"); err != nil {
+	err := e.fire(context.Background(), cfg, "periodic")
+	if err != nil {
 		e.logger.Error("heartbeat periodic trigger failed",
 			slog.String("config_id", cfg.ID),
 			slog.Any("error", err),
 		)
 	}
 	// No manual reschedule needed — CronPool repeats automatically.
+	return err
 }
 
 // eventLoop listens for events from the message hub and fires heartbeats.
@@ -459,15 +470,29 @@ func (e *Engine) eventLoop(cfg Config, ch <-chan msgEvent.Event) {
 }
 
 // fire executes the heartbeat by calling the triggerer.
-func (e *Engine) fire(ctx context.Context, cfg Config, reason string) error {
+func (e *Engine) fire(ctx context.Context, cfg Config, reason string) (err error) {
 	// Skip firing if outside the configured active hours window.
 	if !e.isWithinActiveHours(ctx, cfg) {
 		e.logger.Debug("heartbeat skipped: outside active hours",
 			slog.String("config_id", cfg.ID),
 			slog.String("bot_id", cfg.BotID),
 		)
+		heartbeatSkips.Inc("inactive_hours")
 		return nil
 	}
+	defer func() {
+		result := "ok"
+		data := map[string]any{"heartbeat_id": cfg.ID, "reason": reason}
+		if err != nil {
+			result = "error"
+			data["error"] = err.Error()
+		}
+		data["status"] = result
+		heartbeatFires.Inc(triggerLabel(reason), result)
+		if e.hub != nil {
+			e.hub.Publish(msgEvent.NewEvent(msgEvent.EventTypeHeartbeatFired, cfg.BotID, data))
+		}
+	}()
 
 	// Memory compaction heartbeats are handled directly, bypassing the conversation flow.
 	if strings.Contains(cfg.Prompt, MemoryCompactPromptMarker) {
@@ -528,6 +553,20 @@ func (e *Engine) fire(ctx context.Context, cfg Config, reason string) error {
 	return nil
 }
 
+// triggerLabel maps a fire reason to a bounded metric label. Reasons given
+// to Fire by API callers are free-form and are reported as manual.
+func triggerLabel(reason string) string {
+	if reason == "periodic" {
+		return reason
+	}
+	for _, t := range AllTriggers {
+		if reason == string(t) {
+			return reason
+		}
+	}
+	return "manual"
+}
+
 // fireMemoryCompact directly invokes the memory compactor without going through the bot conversation flow.
 func (e *Engine) fireMemoryCompact(ctx context.Context, cfg Config, reason string) error {
 	if e.compactor == nil {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	msgEvent "github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 )
 
 // EvolutionLog represents a single evolution execution record.
@@ -112,9 +113,24 @@ func (e *Engine) CompleteEvolutionLog(ctx context.Context, logID string, req Com
 		e.logger.Warn("complete evolution log failed", slog.String("log_id", logID), slog.Any("error", err))
 		return EvolutionLog{}, err
 	}
+	if e.hub != nil {
+		e.hub.Publish(EvolutionCompletedEvent(row))
+	}
 	return toEvolutionLog(row), nil
 }
 
+// EvolutionCompletedEvent builds the event published when an evolution log
+// reaches a final status.
+func EvolutionCompletedEvent(row sqlc.EvolutionLog) msgEvent.Event {
+	return msgEvent.NewEvent(msgEvent.EventTypeEvolutionCompleted, row.BotID.String(), map[string]any{
+		"log_id":          row.ID.String(),
+		"heartbeat_id":    row.HeartbeatConfigID.String(),
+		"trigger_reason":  row.TriggerReason,
+		"status":          row.Status,
+		"changes_summary": row.ChangesSummary.String,
+	})
+}
+
 // enrichFilesSnapshot fetches files_snapshot for each item in-place via a
 // direct DB query. Items with no snapshot are left unchanged.
 func (e *Engine) enrichFilesSnapshot(ctx context.Context, items []EvolutionLog) {
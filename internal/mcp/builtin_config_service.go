@@ -112,7 +112,7 @@ func (s *BuiltinToolConfigService) InitializeDefaults(ctx context.Context, botID
 		{"send", "message"}, {"react", "message"},
 		{"search_memory", "memory"},
 		{"web_search", "web"},
-		{"list_schedule", "schedule"}, {"get_schedule", "schedule"}, {"create_schedule", "schedule"}, {"update_schedule", "schedule"}, {"delete_schedule", "schedule"},
+		{"list_schedule", "schedule"}, {"get_schedule", "schedule"}, {"create_schedule", "schedule"}, {"update_schedule", "schedule"}, {"delete_schedule", "schedule"}, {"list_schedule_runs", "schedule"},
 		{"lookup_channel_user", "directory"},
 		{"knowledge_read", "knowledge"},
 		{"knowledge_write", "knowledge"},
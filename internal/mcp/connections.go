@@ -10,6 +10,7 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
 )
 
 // Connection represents a stored MCP connection for a bot.
@@ -160,7 +161,7 @@ func (s *ConnectionService) Create(ctx context.Context, botID string, req Upsert
 	if err != nil {
 		return Connection{}, err
 	}
-	configPayload, err := json.Marshal(config)
+	configPayload, err := encodeMCPConfig(config)
 	if err != nil {
 		return Connection{}, err
 	}
@@ -206,7 +207,7 @@ func (s *ConnectionService) Update(ctx context.Context, botID, id string, req Up
 	if req.Active != nil {
 		active = *req.Active
 	}
-	configPayload, err := json.Marshal(config)
+	configPayload, err := encodeMCPConfig(config)
 	if err != nil {
 		return Connection{}, err
 	}
@@ -250,7 +251,7 @@ func (s *ConnectionService) Import(ctx context.Context, botID string, req Import
 		if err != nil {
 			return nil, fmt.Errorf("server %q: %w", name, err)
 		}
-		configPayload, err := json.Marshal(config)
+		configPayload, err := encodeMCPConfig(config)
 		if err != nil {
 			return nil, err
 		}
@@ -342,6 +343,21 @@ func normalizeMCPConnection(row sqlc.McpConnection) (Connection, error) {
 	}, nil
 }
 
+// SecretConfigFields are the config fields encrypted at rest. They carry
+// auth headers for remote servers and tokens passed to stdio servers.
+var SecretConfigFields = []string{"headers", "env"}
+
+func encodeMCPConfig(config map[string]any) ([]byte, error) {
+	sealed := make(map[string]any, len(config))
+	for k, v := range config {
+		sealed[k] = v
+	}
+	if err := secrets.SealFields(sealed, SecretConfigFields...); err != nil {
+		return nil, fmt.Errorf("encrypt mcp config: %w", err)
+	}
+	return json.Marshal(sealed)
+}
+
 func decodeMCPConfig(raw []byte) (map[string]any, error) {
 	if len(raw) == 0 {
 		return map[string]any{}, nil
@@ -353,6 +369,9 @@ func decodeMCPConfig(raw []byte) (map[string]any, error) {
 	if payload == nil {
 		payload = map[string]any{}
 	}
+	if err := secrets.OpenFields(payload, SecretConfigFields...); err != nil {
+		return nil, fmt.Errorf("decrypt mcp config: %w", err)
+	}
 	return payload, nil
 }
 
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/oci"
 	"github.com/containerd/errdefs"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -57,6 +58,7 @@ type Manager struct {
 	db          *pgxpool.Pool
 	queries     *dbsqlc.Queries
 	logger      *slog.Logger
+	egress      ctr.EgressPolicyFunc
 }
 
 func NewManager(log *slog.Logger, service ctr.Service, cfg config.MCPConfig, namespace string, conn *pgxpool.Pool) *Manager {
@@ -168,19 +170,8 @@ func (m *Manager) Start(ctx context.Context, botID string) error {
 		return err
 	}
 
-	task, err := m.service.StartTask(ctx, m.containerID(botID), &ctr.StartTaskOptions{
-		UseStdio: false,
-	})
-	if err != nil {
-		return err
-	}
-	if err := ctr.SetupNetwork(ctx, task, m.containerID(botID)); err != nil {
-		if stopErr := m.service.StopTask(ctx, m.containerID(botID), &ctr.StopTaskOptions{Force: true}); stopErr != nil {
-			m.logger.Warn("cleanup: stop task failed", slog.String("container_id", m.containerID(botID)), slog.Any("error", stopErr))
-		}
-		return err
-	}
-	return nil
+	_, err := m.service.StartTask(ctx, m.containerID(botID), m.startTaskOptions(m.containerID(botID)))
+	return err
 }
 
 func (m *Manager) Stop(ctx context.Context, botID string, timeout time.Duration) error {
@@ -375,11 +366,36 @@ func (m *Manager) restartTask(ctx context.Context, containerID string) error {
 		m.logger.Warn("restartTask: delete failed (may already be gone)",
 			slog.String("container_id", containerID), slog.Any("error", err))
 	}
-	task, err := m.service.StartTask(ctx, containerID, &ctr.StartTaskOptions{UseStdio: false})
+	_, err := m.service.StartTask(ctx, containerID, m.startTaskOptions(containerID))
+	return err
+}
+
+// SetEgressPolicyFunc makes task starts apply the bot's egress policy
+// instead of attaching an unrestricted network.
+func (m *Manager) SetEgressPolicyFunc(fn ctr.EgressPolicyFunc) {
+	m.egress = fn
+}
+
+// startTaskOptions attaches the container's network before its task starts;
+// a failure aborts the start.
+func (m *Manager) startTaskOptions(containerID string) *ctr.StartTaskOptions {
+	return &ctr.StartTaskOptions{
+		UseStdio: false,
+		Network: func(ctx context.Context, task client.Task) error {
+			return m.setupNetwork(ctx, task, containerID)
+		},
+	}
+}
+
+func (m *Manager) setupNetwork(ctx context.Context, task client.Task, containerID string) error {
+	if m.egress == nil {
+		return ctr.SetupNetwork(ctx, task, containerID)
+	}
+	policy, err := m.egress(ctx, containerID)
 	if err != nil {
 		return err
 	}
-	return ctr.SetupNetwork(ctx, task, containerID)
+	return ctr.SetupBotNetwork(ctx, task, containerID, policy)
 }
 
 // isTaskNotRunning checks whether the error indicates the container's task is dead.
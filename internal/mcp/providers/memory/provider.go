@@ -13,6 +13,7 @@ import (
 
 const (
 	toolSearchMemory       = "search_memory"
+	toolMemoryRelations    = "memory_relations"
 	defaultMemoryToolLimit = 8
 	maxMemoryToolLimit     = 50
 	sharedMemoryNamespace  = "bot"
@@ -22,6 +23,15 @@ type MemorySearcher interface {
 	Search(ctx context.Context, req mem.SearchRequest) (mem.SearchResponse, error)
 }
 
+// RelationLookup is implemented by memory searchers that keep an entity
+// relation graph. When the searcher supports it, memory_relations is exposed.
+// Relations the audience may not see are left out.
+type RelationLookup interface {
+	Relations(ctx context.Context, botID, entity, predicate string, limit int, audience *mem.Audience) ([]mem.Relation, error)
+}
+
+var _ RelationLookup = (*mem.Service)(nil)
+
 type AdminChecker interface {
 	IsAdmin(ctx context.Context, channelIdentityID string) (bool, error)
 }
@@ -49,7 +59,7 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 	if p.searcher == nil || p.chatAccessor == nil {
 		return []mcpgw.ToolDescriptor{}, nil
 	}
-	return []mcpgw.ToolDescriptor{
+	tools := []mcpgw.ToolDescriptor{
 		{
 			Name:        toolSearchMemory,
 			Description: "Search for memories relevant to the current chat",
@@ -68,13 +78,46 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				"required": []string{"query"},
 			},
 		},
-	}, nil
+	}
+	if _, ok := p.searcher.(RelationLookup); ok {
+		tools = append(tools, mcpgw.ToolDescriptor{
+			Name:        toolMemoryRelations,
+			Description: "Follow remembered relations between people, organizations and projects, e.g. who is someone's manager or which team a person belongs to",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"entity": map[string]any{
+						"type":        "string",
+						"description": "Entity name to look up, e.g. \"Alice\". Use \"user\" for the current user",
+					},
+					"predicate": map[string]any{
+						"type":        "string",
+						"description": "Optional relation filter, e.g. \"manager_of\" or \"member\"",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of relations",
+					},
+				},
+				"required": []string{"entity"},
+			},
+		})
+	}
+	return tools, nil
 }
 
 func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
-	if toolName != toolSearchMemory {
+	switch toolName {
+	case toolSearchMemory:
+		return p.callSearchMemory(ctx, session, arguments)
+	case toolMemoryRelations:
+		return p.callMemoryRelations(ctx, session, arguments)
+	default:
 		return nil, mcpgw.ErrToolNotFound
 	}
+}
+
+func (p *Executor) callSearchMemory(ctx context.Context, session mcpgw.ToolSessionContext, arguments map[string]any) (map[string]any, error) {
 	if p.searcher == nil || p.chatAccessor == nil {
 		return mcpgw.BuildToolErrorResult("memory service not available"), nil
 	}
@@ -106,25 +149,8 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 		limit = maxMemoryToolLimit
 	}
 
-	// When ChatID equals BotID (e.g. tools called without conversation context), search by bot scope only.
-	// Otherwise require the conversation to exist and the caller to be a participant.
-	if chatID != botID {
-		chatObj, err := p.chatAccessor.Get(ctx, chatID)
-		if err != nil {
-			return mcpgw.BuildToolErrorResult("chat not found"), nil
-		}
-		if strings.TrimSpace(chatObj.BotID) != botID {
-			return mcpgw.BuildToolErrorResult("bot mismatch"), nil
-		}
-		if channelIdentityID != "" {
-			allowed, err := p.canAccessChat(ctx, chatID, channelIdentityID)
-			if err != nil {
-				return mcpgw.BuildToolErrorResult(err.Error()), nil
-			}
-			if !allowed {
-				return mcpgw.BuildToolErrorResult("not a chat participant"), nil
-			}
-		}
+	if errResult := p.checkChatAccess(ctx, botID, chatID, channelIdentityID); errResult != nil {
+		return errResult, nil
 	}
 
 	resp, err := p.searcher.Search(ctx, mem.SearchRequest{
@@ -136,7 +162,8 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 			"scopeId":   botID,
 			"bot_id":    botID,
 		},
-		NoStats: true,
+		NoStats:  true,
+		Audience: mem.NewAudience(session.ConversationType, session.CurrentPlatform, session.ReplyTarget, channelIdentityID),
 	})
 	if err != nil {
 		p.logger.Warn("memory search namespace failed", slog.String("namespace", sharedMemoryNamespace), slog.Any("error", err))
@@ -162,13 +189,102 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 		})
 	}
 
-	return mcpgw.BuildToolSuccessResult(map[string]any{
+	payload := map[string]any{
 		"query":   query,
 		"total":   len(results),
 		"results": results,
+	}
+	if len(resp.Relations) > 0 {
+		payload["relations"] = formatRelations(resp.Relations)
+	}
+	return mcpgw.BuildToolSuccessResult(payload), nil
+}
+
+func (p *Executor) callMemoryRelations(ctx context.Context, session mcpgw.ToolSessionContext, arguments map[string]any) (map[string]any, error) {
+	lookup, ok := p.searcher.(RelationLookup)
+	if !ok || p.chatAccessor == nil {
+		return mcpgw.BuildToolErrorResult("memory relations not available"), nil
+	}
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return mcpgw.BuildToolErrorResult("bot_id is required"), nil
+	}
+	chatID := strings.TrimSpace(session.ChatID)
+	if chatID == "" {
+		chatID = botID
+	}
+	channelIdentityID := strings.TrimSpace(session.ChannelIdentityID)
+	if errResult := p.checkChatAccess(ctx, botID, chatID, channelIdentityID); errResult != nil {
+		return errResult, nil
+	}
+	entity := mcpgw.StringArg(arguments, "entity")
+	if entity == "" {
+		return mcpgw.BuildToolErrorResult("entity is required"), nil
+	}
+	predicate := mcpgw.StringArg(arguments, "predicate")
+	limit := defaultMemoryToolLimit
+	if value, ok, err := mcpgw.IntArg(arguments, "limit"); err != nil {
+		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	} else if ok && value > 0 {
+		limit = value
+	}
+	if limit > maxMemoryToolLimit {
+		limit = maxMemoryToolLimit
+	}
+
+	audience := mem.NewAudience(session.ConversationType, session.CurrentPlatform, session.ReplyTarget, channelIdentityID)
+	relations, err := lookup.Relations(ctx, botID, entity, predicate, limit, audience)
+	if err != nil {
+		p.logger.Warn("memory relations lookup failed", slog.String("entity", entity), slog.Any("error", err))
+		return mcpgw.BuildToolErrorResult("memory relations lookup failed"), nil
+	}
+	return mcpgw.BuildToolSuccessResult(map[string]any{
+		"entity":    entity,
+		"total":     len(relations),
+		"relations": formatRelations(relations),
 	}), nil
 }
 
+func formatRelations(relations []mem.Relation) []map[string]any {
+	out := make([]map[string]any, 0, len(relations))
+	for _, rel := range relations {
+		out = append(out, map[string]any{
+			"subject":   rel.Subject,
+			"predicate": rel.Predicate,
+			"object":    rel.Object,
+			"mentions":  rel.Mentions,
+		})
+	}
+	return out
+}
+
+// checkChatAccess returns a tool error result when the session may not read
+// the conversation's memories. When chatID equals botID (tools called without
+// conversation context) only the bot scope applies; otherwise the conversation
+// must belong to the bot and the caller must be a participant.
+func (p *Executor) checkChatAccess(ctx context.Context, botID, chatID, channelIdentityID string) map[string]any {
+	if chatID == botID {
+		return nil
+	}
+	chatObj, err := p.chatAccessor.Get(ctx, chatID)
+	if err != nil {
+		return mcpgw.BuildToolErrorResult("chat not found")
+	}
+	if strings.TrimSpace(chatObj.BotID) != botID {
+		return mcpgw.BuildToolErrorResult("bot mismatch")
+	}
+	if channelIdentityID != "" {
+		allowed, err := p.canAccessChat(ctx, chatID, channelIdentityID)
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error())
+		}
+		if !allowed {
+			return mcpgw.BuildToolErrorResult("not a chat participant")
+		}
+	}
+	return nil
+}
+
 func (p *Executor) canAccessChat(ctx context.Context, chatID, channelIdentityID string) (bool, error) {
 	if p.adminChecker != nil {
 		isAdmin, err := p.adminChecker.IsAdmin(ctx, channelIdentityID)
@@ -282,3 +282,106 @@ func TestDeduplicateMemoryItems(t *testing.T) {
 		})
 	}
 }
+
+type fakeRelationSearcher struct {
+	fakeSearcher
+	relations    []memory.Relation
+	gotEntity    string
+	gotBotID     string
+	gotPredicate string
+	gotAudience  *memory.Audience
+}
+
+func (f *fakeRelationSearcher) Relations(ctx context.Context, botID, entity, predicate string, limit int, audience *memory.Audience) ([]memory.Relation, error) {
+	f.gotBotID = botID
+	f.gotEntity = entity
+	f.gotPredicate = predicate
+	f.gotAudience = audience
+	return f.relations, nil
+}
+
+func TestExecutor_MemoryRelations(t *testing.T) {
+	searcher := &fakeRelationSearcher{
+		relations: []memory.Relation{{Subject: "Bob", Predicate: "manager_of", Object: "Alice", Mentions: 2}},
+	}
+	exec := NewExecutor(nil, searcher, &fakeChatAccessor{}, nil)
+
+	tools, err := exec.ListTools(context.Background(), mcpgw.ToolSessionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 2 || tools[1].Name != toolMemoryRelations {
+		t.Fatalf("expected memory_relations tool to be listed, got %+v", tools)
+	}
+
+	result, err := exec.CallTool(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"}, toolMemoryRelations, map[string]any{
+		"entity":    "Alice",
+		"predicate": "manager",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); isErr {
+		t.Fatalf("unexpected error result: %v", result)
+	}
+	if searcher.gotBotID != "bot1" || searcher.gotEntity != "Alice" || searcher.gotPredicate != "manager" {
+		t.Errorf("unexpected lookup args: bot=%q entity=%q predicate=%q", searcher.gotBotID, searcher.gotEntity, searcher.gotPredicate)
+	}
+
+	groupSession := mcpgw.ToolSessionContext{BotID: "bot1", ConversationType: "group", CurrentPlatform: "telegram", ReplyTarget: "-100"}
+	if _, err := exec.CallTool(context.Background(), groupSession, toolMemoryRelations, map[string]any{"entity": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if !searcher.gotAudience.IsGroup() || searcher.gotAudience.GroupID != "telegram:-100" {
+		t.Errorf("expected the group session audience, got %+v", searcher.gotAudience)
+	}
+
+	result, err = exec.CallTool(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"}, toolMemoryRelations, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Error("expected error when entity is empty")
+	}
+}
+
+func TestExecutor_MemoryRelations_ChecksChatAccess(t *testing.T) {
+	searcher := &fakeRelationSearcher{
+		relations: []memory.Relation{{Subject: "Bob", Predicate: "manager_of", Object: "Alice"}},
+	}
+	session := mcpgw.ToolSessionContext{BotID: "bot1", ChatID: "c1", ChannelIdentityID: "user1"}
+	args := map[string]any{"entity": "Alice"}
+
+	notParticipant := &fakeChatAccessor{chat: conversation.Conversation{BotID: "bot1", ID: "c1"}}
+	exec := NewExecutor(nil, searcher, notParticipant, &fakeAdminChecker{})
+	result, err := exec.CallTool(context.Background(), session, toolMemoryRelations, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Error("expected error when not a participant")
+	}
+
+	otherBot := &fakeChatAccessor{chat: conversation.Conversation{BotID: "bot2", ID: "c1"}, participant: true}
+	exec = NewExecutor(nil, searcher, otherBot, nil)
+	result, err = exec.CallTool(context.Background(), session, toolMemoryRelations, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Error("expected error for a chat of another bot")
+	}
+	if searcher.gotEntity != "" {
+		t.Errorf("relations looked up despite denied access: %q", searcher.gotEntity)
+	}
+
+	participant := &fakeChatAccessor{chat: conversation.Conversation{BotID: "bot1", ID: "c1"}, participant: true}
+	exec = NewExecutor(nil, searcher, participant, nil)
+	result, err = exec.CallTool(context.Background(), session, toolMemoryRelations, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); isErr {
+		t.Fatalf("unexpected error result: %v", result)
+	}
+}
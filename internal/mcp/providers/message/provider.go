@@ -67,7 +67,7 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 	if p.sender != nil && p.resolver != nil {
 		tools = append(tools, mcpgw.ToolDescriptor{
 			Name:        toolSend,
-			Description: "Send a message to a channel or session. Supports text, structured messages, attachments, and replies.",
+			Description: "Send a message to a channel or session. Supports text, structured messages, attachments, replies, and buttons.",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -108,6 +108,19 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 						"description": "File attachments: container paths (/data/...), HTTP URLs, or objects {path, url, type, name}",
 						"items":       map[string]any{},
 					},
+					"buttons": map[string]any{
+						"type":        "array",
+						"description": "Clickable buttons shown under the message text. A press arrives as a user message \"[button pressed] <label> (value: <value>)\". Use url instead of value for a link button.",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"label": map[string]any{"type": "string", "description": "Button text"},
+								"value": map[string]any{"type": "string", "description": "Value reported back when pressed (keep under 64 bytes)"},
+								"url":   map[string]any{"type": "string", "description": "Link to open instead of calling back"},
+							},
+							"required": []string{"label"},
+						},
+					},
 				},
 				"required": []string{},
 			},
@@ -180,6 +193,10 @@ func (p *Executor) callSend(ctx context.Context, session mcpgw.ToolSessionContex
 	}
 
 	messageText := mcpgw.FirstStringArg(arguments, "text")
+	buttons, err := parseButtons(arguments["buttons"])
+	if err != nil {
+		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	}
 	outboundMessage, parseErr := parseOutboundMessage(arguments, messageText)
 	if parseErr != nil {
 		if rawAtt, ok := arguments["attachments"]; !ok || rawAtt == nil {
@@ -199,6 +216,12 @@ func (p *Executor) callSend(ctx context.Context, session mcpgw.ToolSessionContex
 	if outboundMessage.IsEmpty() && len(outboundMessage.Attachments) == 0 {
 		return mcpgw.BuildToolErrorResult("message or attachments required"), nil
 	}
+	if len(buttons) > 0 {
+		if strings.TrimSpace(outboundMessage.PlainText()) == "" {
+			return mcpgw.BuildToolErrorResult("buttons require message text"), nil
+		}
+		outboundMessage.Actions = append(outboundMessage.Actions, buttons...)
+	}
 
 	// Attach reply reference if reply_to is provided.
 	if replyTo := mcpgw.FirstStringArg(arguments, "reply_to"); replyTo != "" {
@@ -348,6 +371,38 @@ func parseOutboundMessage(arguments map[string]any, fallbackText string) (channe
 	return msg, nil
 }
 
+// parseButtons reads the buttons argument into message actions.
+func parseButtons(raw any) ([]channel.Action, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("buttons must be an array")
+	}
+	actions := make([]channel.Action, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("each button must be an object with label and value or url")
+		}
+		action := channel.Action{
+			Type:  channel.ActionTypeButton,
+			Label: strings.TrimSpace(stringVal(obj, "label", "text")),
+			Value: strings.TrimSpace(stringVal(obj, "value")),
+			URL:   strings.TrimSpace(stringVal(obj, "url")),
+		}
+		if action.Value == "" && action.URL != "" {
+			action.Type = channel.ActionTypeLink
+		}
+		if err := action.Validate(); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
 // --- attachment resolution ---
 
 func (p *Executor) resolveAttachments(ctx context.Context, botID string, arr []any) []channel.Attachment {
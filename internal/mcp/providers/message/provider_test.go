@@ -292,6 +292,58 @@ func TestExecutor_CallTool_NoReplyTo(t *testing.T) {
 	}
 }
 
+func TestExecutor_CallTool_Buttons(t *testing.T) {
+	sender := &fakeSender{}
+	resolver := &fakeResolver{ct: channel.ChannelType("telegram")}
+	exec := NewExecutor(nil, sender, nil, resolver, nil)
+	session := mcpgw.ToolSessionContext{BotID: "bot1", CurrentPlatform: "telegram", ReplyTarget: "123"}
+	result, err := exec.CallTool(context.Background(), session, toolSend, map[string]any{
+		"text": "Approve expense #42?",
+		"buttons": []any{
+			map[string]any{"label": "Approve", "value": "expense:42:approve"},
+			map[string]any{"label": "Reject", "value": "expense:42:reject"},
+			map[string]any{"label": "Details", "url": "https://example.com/expenses/42"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mcpgw.PayloadError(result); err != nil {
+		t.Fatal(err)
+	}
+	actions := sender.lastReq.Message.Actions
+	if len(actions) != 3 {
+		t.Fatalf("expected 3 actions, got %d", len(actions))
+	}
+	if actions[0].Value != "expense:42:approve" || actions[0].Type != channel.ActionTypeButton {
+		t.Errorf("unexpected first action: %+v", actions[0])
+	}
+	if actions[2].Type != channel.ActionTypeLink || !actions[2].IsLink() {
+		t.Errorf("expected link action, got %+v", actions[2])
+	}
+}
+
+func TestExecutor_CallTool_InvalidButtons(t *testing.T) {
+	resolver := &fakeResolver{ct: channel.ChannelType("telegram")}
+	session := mcpgw.ToolSessionContext{BotID: "bot1", CurrentPlatform: "telegram", ReplyTarget: "123"}
+	cases := map[string]map[string]any{
+		"missing value": {"text": "hi", "buttons": []any{map[string]any{"label": "Go"}}},
+		"not an array":  {"text": "hi", "buttons": "Go"},
+		"no text":       {"attachments": []any{"https://example.com/a.png"}, "buttons": []any{map[string]any{"label": "Go", "value": "go"}}},
+	}
+	for name, args := range cases {
+		sender := &fakeSender{}
+		exec := NewExecutor(nil, sender, nil, resolver, nil)
+		result, err := exec.CallTool(context.Background(), session, toolSend, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isErr, _ := result["isError"].(bool); !isErr {
+			t.Errorf("%s: expected tool error", name)
+		}
+	}
+}
+
 // --- react tests ---
 
 func TestExecutor_React_NilReactor(t *testing.T) {
@@ -2,8 +2,10 @@ package schedule
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	mcpgw "github.com/Kxiandaoyan/Memoh-v2/internal/mcp"
 	sched "github.com/Kxiandaoyan/Memoh-v2/internal/schedule"
@@ -15,6 +17,7 @@ const (
 	toolScheduleCreate = "create_schedule"
 	toolScheduleUpdate = "update_schedule"
 	toolScheduleDelete = "delete_schedule"
+	toolScheduleRuns   = "list_schedule_runs"
 )
 
 type Scheduler interface {
@@ -23,6 +26,8 @@ type Scheduler interface {
 	Create(ctx context.Context, botID string, req sched.CreateRequest) (sched.Schedule, error)
 	Update(ctx context.Context, id string, req sched.UpdateRequest) (sched.Schedule, error)
 	Delete(ctx context.Context, id string) error
+	ListRuns(ctx context.Context, scheduleID string, limit int) ([]sched.Run, error)
+	ListRunsByBot(ctx context.Context, botID string, limit int) ([]sched.Run, error)
 }
 
 type Executor struct {
@@ -63,7 +68,7 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 		},
 		{
 			Name:        toolScheduleCreate,
-			Description: "Create a new scheduled task. The task will fire at the specified cron pattern and execute the command as a bot prompt. Returns the created schedule with its ID.",
+			Description: "Create a new scheduled task that sends the command to the bot as a prompt. It fires on a cron pattern, once (run_at or run_in, for reminders like 'in 20 minutes' or 'tomorrow at 9') or at a fixed interval (interval_minutes, optionally from start_at). One-shot tasks are archived after they run. Returns the created schedule with its ID.",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -77,7 +82,32 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 					},
 					"pattern": map[string]any{
 						"type":        "string",
-						"description": "Cron expression: 'minute hour day-of-month month day-of-week' (e.g. '30 9 * * *' for daily at 9:30, '0 */2 * * *' for every 2 hours). Supports optional seconds prefix.",
+						"description": "Cron expression: 'minute hour day-of-month month day-of-week' (e.g. '30 9 * * *' for daily at 9:30, '0 */2 * * *' for every 2 hours). Supports optional seconds prefix. Required unless run_at, run_in or interval_minutes is given.",
+					},
+					"kind": map[string]any{
+						"type":        "string",
+						"enum":        []string{sched.KindCron, sched.KindOnce, sched.KindInterval},
+						"description": "Schedule kind. Inferred from the other fields when omitted.",
+					},
+					"run_at": map[string]any{
+						"type":        "string",
+						"description": "When a one-shot task fires: RFC 3339 (e.g. '2025-06-01T09:00:00+08:00') or 'YYYY-MM-DD HH:MM' in the schedule's timezone.",
+					},
+					"run_in": map[string]any{
+						"type":        "string",
+						"description": "Fire once after this delay from now, e.g. '20m', '2h30m'. Alternative to run_at.",
+					},
+					"interval_minutes": map[string]any{
+						"type":        "integer",
+						"description": "Fire every N minutes (at least 1), e.g. 90 for every 90 minutes.",
+					},
+					"start_at": map[string]any{
+						"type":        "string",
+						"description": "First fire time of an interval task, same format as run_at. Defaults to now.",
+					},
+					"timezone": map[string]any{
+						"type":        "string",
+						"description": "IANA timezone such as 'Asia/Shanghai' used for the cron pattern and for run_at/start_at without an offset. Defaults to the server timezone.",
 					},
 					"command": map[string]any{
 						"type":        "string",
@@ -91,8 +121,21 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 						"type":        "boolean",
 						"description": "Whether the schedule is enabled. Defaults to true if omitted.",
 					},
+					"misfire_policy": map[string]any{
+						"type":        "string",
+						"enum":        []string{sched.MisfireSkip, sched.MisfireRunOnce, sched.MisfireRunAll},
+						"description": "What to do with fire times missed while the server was down: 'skip' (default), 'run_once' (run once on startup) or 'run_all' (replay every missed time).",
+					},
+					"retry_max_attempts": map[string]any{
+						"type":        "integer",
+						"description": "Total attempts when a run fails, including the first (1-10, default 1 = no retry). The owner is notified after the last failed attempt.",
+					},
+					"retry_backoff_seconds": map[string]any{
+						"type":        "integer",
+						"description": "Seconds before the first retry; doubles with each further retry (default 60).",
+					},
 				},
-				"required": []string{"name", "description", "pattern", "command"},
+				"required": []string{"name", "description", "command"},
 			},
 		},
 		{
@@ -117,6 +160,31 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 						"type":        "string",
 						"description": "New cron expression",
 					},
+					"kind": map[string]any{
+						"type":        "string",
+						"enum":        []string{sched.KindCron, sched.KindOnce, sched.KindInterval},
+						"description": "Change the schedule kind; give the matching pattern, run_at/run_in or interval_minutes with it.",
+					},
+					"run_at": map[string]any{
+						"type":        "string",
+						"description": "New one-shot fire time: RFC 3339 or 'YYYY-MM-DD HH:MM' in the schedule's timezone.",
+					},
+					"run_in": map[string]any{
+						"type":        "string",
+						"description": "New one-shot delay from now, e.g. '20m'. Alternative to run_at.",
+					},
+					"interval_minutes": map[string]any{
+						"type":        "integer",
+						"description": "New interval in minutes",
+					},
+					"start_at": map[string]any{
+						"type":        "string",
+						"description": "New first fire time of an interval task",
+					},
+					"timezone": map[string]any{
+						"type":        "string",
+						"description": "New IANA timezone, or an empty string for the server timezone",
+					},
 					"max_calls": map[string]any{
 						"type":        "integer",
 						"description": "New max calls limit, or null for unlimited",
@@ -129,6 +197,19 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 						"type":        "string",
 						"description": "New prompt/instruction text",
 					},
+					"misfire_policy": map[string]any{
+						"type":        "string",
+						"enum":        []string{sched.MisfireSkip, sched.MisfireRunOnce, sched.MisfireRunAll},
+						"description": "What to do with fire times missed while the server was down: 'skip' (default), 'run_once' (run once on startup) or 'run_all' (replay every missed time).",
+					},
+					"retry_max_attempts": map[string]any{
+						"type":        "integer",
+						"description": "Total attempts when a run fails, including the first (1-10, default 1 = no retry). The owner is notified after the last failed attempt.",
+					},
+					"retry_backoff_seconds": map[string]any{
+						"type":        "integer",
+						"description": "Seconds before the first retry; doubles with each further retry (default 60).",
+					},
 				},
 				"required": []string{"id"},
 			},
@@ -144,6 +225,23 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				"required": []string{"id"},
 			},
 		},
+		{
+			Name:        toolScheduleRuns,
+			Description: "List past runs of scheduled tasks, newest first. Each run has its status (running, success, error), error message, start and finish time, token usage and a preview of the reply. Use it to find out why a scheduled task did not deliver.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"schedule_id": map[string]any{
+						"type":        "string",
+						"description": "Only list runs of this schedule. Omit to list runs of all schedules.",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of runs to return (default 20)",
+					},
+				},
+			},
+		},
 	}, nil
 }
 
@@ -193,15 +291,18 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 			slog.String("platform", session.CurrentPlatform),
 			slog.String("reply_target", session.ReplyTarget),
 		)
-		if name == "" || description == "" || pattern == "" || command == "" {
+		if name == "" || description == "" || command == "" {
 			p.logger.Warn("schedule create missing required fields",
 				slog.String("bot_id", botID),
 				slog.Bool("has_name", name != ""),
 				slog.Bool("has_description", description != ""),
-				slog.Bool("has_pattern", pattern != ""),
 				slog.Bool("has_command", command != ""),
 			)
-			return mcpgw.BuildToolErrorResult("name, description, pattern, command are required"), nil
+			return mcpgw.BuildToolErrorResult("name, description, command are required"), nil
+		}
+		timing, err := parseTimingArgs(arguments, time.Now())
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
 		}
 
 		req := sched.CreateRequest{
@@ -211,6 +312,12 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 			Command:     command,
 			Platform:    strings.TrimSpace(session.CurrentPlatform),
 			ReplyTarget: strings.TrimSpace(session.ReplyTarget),
+
+			Kind:            timing.kind,
+			RunAt:           timing.runAt,
+			IntervalSeconds: timing.intervalSeconds,
+			StartAt:         timing.startAt,
+			Timezone:        timing.timezone,
 		}
 		maxCalls, err := parseNullableIntArg(arguments, "max_calls")
 		if err != nil {
@@ -223,6 +330,13 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 		} else if ok {
 			req.Enabled = &enabled
 		}
+		req.MisfirePolicy = mcpgw.StringArg(arguments, "misfire_policy")
+		if req.RetryMaxAttempts, err = optionalIntArg(arguments, "retry_max_attempts"); err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		if req.RetryBackoffSeconds, err = optionalIntArg(arguments, "retry_backoff_seconds"); err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
 		item, err := p.service.Create(ctx, botID, req)
 		if err != nil {
 			p.logger.Error("schedule create failed",
@@ -270,11 +384,37 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 		if value := mcpgw.StringArg(arguments, "command"); value != "" {
 			req.Command = &value
 		}
+		timing, err := parseTimingArgs(arguments, time.Now())
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		if timing.kind != "" {
+			req.Kind = &timing.kind
+		}
+		if timing.runAt != "" {
+			req.RunAt = &timing.runAt
+		}
+		if timing.startAt != "" {
+			req.StartAt = &timing.startAt
+		}
+		req.IntervalSeconds = timing.intervalSeconds
+		if _, ok := arguments["timezone"]; ok {
+			req.Timezone = &timing.timezone
+		}
 		if enabled, ok, err := mcpgw.BoolArg(arguments, "enabled"); err != nil {
 			return mcpgw.BuildToolErrorResult(err.Error()), nil
 		} else if ok {
 			req.Enabled = &enabled
 		}
+		if value := mcpgw.StringArg(arguments, "misfire_policy"); value != "" {
+			req.MisfirePolicy = &value
+		}
+		if req.RetryMaxAttempts, err = optionalIntArg(arguments, "retry_max_attempts"); err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		if req.RetryBackoffSeconds, err = optionalIntArg(arguments, "retry_backoff_seconds"); err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
 		item, err := p.service.Update(ctx, id, req)
 		if err != nil {
 			return mcpgw.BuildToolErrorResult(err.Error()), nil
@@ -296,6 +436,33 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 			return mcpgw.BuildToolErrorResult(err.Error()), nil
 		}
 		return mcpgw.BuildToolSuccessResult(map[string]any{"success": true}), nil
+	case toolScheduleRuns:
+		limit, _, err := mcpgw.IntArg(arguments, "limit")
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		var items []sched.Run
+		if scheduleID := mcpgw.StringArg(arguments, "schedule_id"); scheduleID != "" {
+			item, err := p.service.Get(ctx, scheduleID)
+			if err != nil {
+				return mcpgw.BuildToolErrorResult(err.Error()), nil
+			}
+			if item.BotID != botID {
+				return mcpgw.BuildToolErrorResult("bot mismatch"), nil
+			}
+			items, err = p.service.ListRuns(ctx, scheduleID, limit)
+			if err != nil {
+				return mcpgw.BuildToolErrorResult(err.Error()), nil
+			}
+		} else {
+			items, err = p.service.ListRunsByBot(ctx, botID, limit)
+			if err != nil {
+				return mcpgw.BuildToolErrorResult(err.Error()), nil
+			}
+		}
+		return mcpgw.BuildToolSuccessResult(map[string]any{
+			"items": items,
+		}), nil
 	default:
 		return nil, mcpgw.ErrToolNotFound
 	}
@@ -323,9 +490,58 @@ func parseNullableIntArg(arguments map[string]any, key string) (sched.NullableIn
 	return req, nil
 }
 
+func optionalIntArg(arguments map[string]any, key string) (*int, error) {
+	value, ok, err := mcpgw.IntArg(arguments, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &value, nil
+}
+
 func emptyObjectSchema() map[string]any {
 	return map[string]any{
 		"type":       "object",
 		"properties": map[string]any{},
 	}
 }
+
+// timingArgs holds the tool arguments that decide when a schedule fires,
+// translated into the fields of the service requests.
+type timingArgs struct {
+	kind            string
+	runAt           string
+	startAt         string
+	timezone        string
+	intervalSeconds *int
+}
+
+// parseTimingArgs reads kind, run_at, run_in, interval_minutes, start_at and
+// timezone. run_in is resolved against now so that the model does not have
+// to compute absolute times for relative reminders.
+func parseTimingArgs(arguments map[string]any, now time.Time) (timingArgs, error) {
+	args := timingArgs{
+		kind:     mcpgw.StringArg(arguments, "kind"),
+		runAt:    mcpgw.StringArg(arguments, "run_at"),
+		startAt:  mcpgw.StringArg(arguments, "start_at"),
+		timezone: mcpgw.StringArg(arguments, "timezone"),
+	}
+	if runIn := mcpgw.StringArg(arguments, "run_in"); runIn != "" {
+		if args.runAt != "" {
+			return timingArgs{}, fmt.Errorf("give either run_at or run_in, not both")
+		}
+		d, err := time.ParseDuration(runIn)
+		if err != nil || d <= 0 {
+			return timingArgs{}, fmt.Errorf("run_in must be a positive duration such as '20m' or '2h30m'")
+		}
+		args.runAt = now.Add(d).Format(time.RFC3339)
+	}
+	minutes, ok, err := mcpgw.IntArg(arguments, "interval_minutes")
+	if err != nil {
+		return timingArgs{}, err
+	}
+	if ok {
+		seconds := minutes * 60
+		args.intervalSeconds = &seconds
+	}
+	return args, nil
+}
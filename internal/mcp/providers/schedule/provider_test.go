@@ -16,9 +16,13 @@ type fakeScheduler struct {
 	getErr    error
 	create    sched.Schedule
 	createErr error
+	createReq sched.CreateRequest
 	update    sched.Schedule
 	updateErr error
 	deleteErr error
+	runs      []sched.Run
+	runsBotID string
+	runsID    string
 }
 
 func (f *fakeScheduler) List(ctx context.Context, botID string) ([]sched.Schedule, error) {
@@ -33,6 +37,7 @@ func (f *fakeScheduler) Get(ctx context.Context, id string) (sched.Schedule, err
 }
 
 func (f *fakeScheduler) Create(ctx context.Context, botID string, req sched.CreateRequest) (sched.Schedule, error) {
+	f.createReq = req
 	if f.createErr != nil {
 		return sched.Schedule{}, f.createErr
 	}
@@ -50,6 +55,16 @@ func (f *fakeScheduler) Delete(ctx context.Context, id string) error {
 	return f.deleteErr
 }
 
+func (f *fakeScheduler) ListRuns(ctx context.Context, scheduleID string, limit int) ([]sched.Run, error) {
+	f.runsID = scheduleID
+	return f.runs, nil
+}
+
+func (f *fakeScheduler) ListRunsByBot(ctx context.Context, botID string, limit int) ([]sched.Run, error) {
+	f.runsBotID = botID
+	return f.runs, nil
+}
+
 func TestExecutor_ListTools_NilService(t *testing.T) {
 	exec := NewExecutor(nil, nil)
 	tools, err := exec.ListTools(context.Background(), mcpgw.ToolSessionContext{})
@@ -68,7 +83,7 @@ func TestExecutor_ListTools(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	wantNames := []string{toolScheduleList, toolScheduleGet, toolScheduleCreate, toolScheduleUpdate, toolScheduleDelete}
+	wantNames := []string{toolScheduleList, toolScheduleGet, toolScheduleCreate, toolScheduleUpdate, toolScheduleDelete, toolScheduleRuns}
 	if len(tools) != len(wantNames) {
 		t.Fatalf("expected %d tools, got %d", len(wantNames), len(tools))
 	}
@@ -228,6 +243,52 @@ func TestExecutor_CallTool_Create_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_CallTool_Create_RunIn(t *testing.T) {
+	svc := &fakeScheduler{create: sched.Schedule{ID: "new1", BotID: "bot1", Kind: sched.KindOnce}}
+	exec := NewExecutor(nil, svc)
+	session := mcpgw.ToolSessionContext{BotID: "bot1"}
+	before := time.Now()
+	result, err := exec.CallTool(context.Background(), session, toolScheduleCreate, map[string]any{
+		"name": "tea", "description": "tea reminder", "command": "Remind me to take the tea out", "run_in": "20m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mcpgw.PayloadError(result); err != nil {
+		t.Fatal(err)
+	}
+	runAt, err := time.Parse(time.RFC3339, svc.createReq.RunAt)
+	if err != nil {
+		t.Fatalf("run_at = %q: %v", svc.createReq.RunAt, err)
+	}
+	if d := runAt.Sub(before); d < 19*time.Minute || d > 21*time.Minute {
+		t.Errorf("run_at %v is not 20 minutes from now", runAt)
+	}
+	if svc.createReq.Pattern != "" {
+		t.Errorf("pattern = %q, want empty", svc.createReq.Pattern)
+	}
+}
+
+func TestParseTimingArgs(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	args, err := parseTimingArgs(map[string]any{"interval_minutes": 90, "timezone": "Asia/Shanghai"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.intervalSeconds == nil || *args.intervalSeconds != 5400 {
+		t.Errorf("interval_seconds = %v", args.intervalSeconds)
+	}
+	if args.timezone != "Asia/Shanghai" {
+		t.Errorf("timezone = %q", args.timezone)
+	}
+	if _, err := parseTimingArgs(map[string]any{"run_in": "soon"}, now); err == nil {
+		t.Error("expected error for unparsable run_in")
+	}
+	if _, err := parseTimingArgs(map[string]any{"run_in": "1h", "run_at": "2026-03-01 10:00"}, now); err == nil {
+		t.Error("expected error for run_in together with run_at")
+	}
+}
+
 func TestExecutor_CallTool_Update_IdRequired(t *testing.T) {
 	svc := &fakeScheduler{}
 	exec := NewExecutor(nil, svc)
@@ -308,6 +369,47 @@ func TestExecutor_CallTool_Delete_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_CallTool_Runs_ByBot(t *testing.T) {
+	svc := &fakeScheduler{
+		runs: []sched.Run{{ID: "r1", ScheduleID: "s1", BotID: "bot1", Status: sched.RunStatusError, Error: "gateway timeout"}},
+	}
+	exec := NewExecutor(nil, svc)
+	session := mcpgw.ToolSessionContext{BotID: "bot1"}
+	result, err := exec.CallTool(context.Background(), session, toolScheduleRuns, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mcpgw.PayloadError(result); err != nil {
+		t.Fatal(err)
+	}
+	if svc.runsBotID != "bot1" || svc.runsID != "" {
+		t.Errorf("expected runs listed by bot, got bot=%q schedule=%q", svc.runsBotID, svc.runsID)
+	}
+	content, _ := result["structuredContent"].(map[string]any)
+	items, _ := content["items"].([]sched.Run)
+	if len(items) != 1 {
+		t.Errorf("items length = %d", len(items))
+	}
+}
+
+func TestExecutor_CallTool_Runs_BotMismatch(t *testing.T) {
+	svc := &fakeScheduler{
+		get: sched.Schedule{ID: "s1", BotID: "other-bot"},
+	}
+	exec := NewExecutor(nil, svc)
+	session := mcpgw.ToolSessionContext{BotID: "bot1"}
+	result, err := exec.CallTool(context.Background(), session, toolScheduleRuns, map[string]any{"schedule_id": "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Error("expected error when listing runs of another bot's schedule")
+	}
+	if svc.runsID != "" {
+		t.Error("runs must not be listed on bot mismatch")
+	}
+}
+
 func TestExecutor_CallTool_Get_ServiceError(t *testing.T) {
 	svc := &fakeScheduler{getErr: errors.New("not found")}
 	exec := NewExecutor(nil, svc)
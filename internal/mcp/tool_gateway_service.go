@@ -8,6 +8,10 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
 )
 
 const (
@@ -83,9 +87,19 @@ func (s *ToolGatewayService) CallTool(ctx context.Context, session ToolSessionCo
 	if toolName == "" {
 		return nil, fmt.Errorf("tool name is required")
 	}
+	ctx, span := tracing.Start(ctx, "mcp.tool_call",
+		attribute.String("tool.name", toolName),
+		attribute.String("bot.id", session.BotID),
+		attribute.String("chat.id", session.ChatID),
+	)
+	// Tool failures are returned as error results, so the span error is
+	// tracked separately.
+	var spanErr error
+	defer func() { tracing.End(span, spanErr) }()
 
 	registry, err := s.getRegistry(ctx, session, false)
 	if err != nil {
+		spanErr = err
 		return nil, err
 	}
 	executor, _, ok := registry.Lookup(toolName)
@@ -93,10 +107,12 @@ func (s *ToolGatewayService) CallTool(ctx context.Context, session ToolSessionCo
 		// Refresh once for dynamic executors/sources.
 		registry, err = s.getRegistry(ctx, session, true)
 		if err != nil {
+			spanErr = err
 			return nil, err
 		}
 		executor, _, ok = registry.Lookup(toolName)
 		if !ok {
+			spanErr = ErrToolNotFound
 			return BuildToolErrorResult("tool not found: " + toolName), nil
 		}
 	}
@@ -107,6 +123,7 @@ func (s *ToolGatewayService) CallTool(ctx context.Context, session ToolSessionCo
 	}
 	result, err := executor.CallTool(ctx, session, toolName, arguments)
 	if err != nil {
+		spanErr = err
 		if errors.Is(err, ErrToolNotFound) {
 			return BuildToolErrorResult("tool not found: " + toolName), nil
 		}
@@ -15,6 +15,7 @@ type ToolSessionContext struct {
 	ChannelIdentityID string
 	SessionToken      string
 	CurrentPlatform   string
+	ConversationType  string
 	ReplyTarget       string
 	IncludeTools      []string // extended tools to include in tools/list
 }
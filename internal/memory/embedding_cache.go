@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/embeddings"
 )
 
 const (
@@ -51,12 +53,15 @@ func (c *EmbeddingCache) Get(ctx context.Context, text string) ([]float32, bool)
 	)
 	var raw []byte
 	if err := row.Scan(&raw); err != nil {
+		embeddings.ObserveCacheLookup("postgres", false)
 		return nil, false
 	}
 	var vec []float32
 	if err := json.Unmarshal(raw, &vec); err != nil {
+		embeddings.ObserveCacheLookup("postgres", false)
 		return nil, false
 	}
+	embeddings.ObserveCacheLookup("postgres", true)
 	// Touch updated_at so LRU pruning keeps recently used entries longer.
 	_, _ = c.pool.Exec(ctx,
 		`UPDATE embedding_cache SET updated_at=$1 WHERE provider=$2 AND model=$3 AND hash=$4`,
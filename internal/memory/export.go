@@ -0,0 +1,345 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const exportBatchSize = 256
+
+// sharedImportNamespace is the namespace bots share their memories in.
+const sharedImportNamespace = "bot"
+
+// ExportRecord is one line of a memory export (JSONL). Filters carries the
+// scoping payload fields (namespace, scopeId, bot_id, source, ...); Vector is
+// only present when the export was requested with vectors.
+type ExportRecord struct {
+	ID          string         `json:"id"`
+	Memory      string         `json:"memory"`
+	Hash        string         `json:"hash"`
+	Lang        string         `json:"lang,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Filters     map[string]any `json:"filters,omitempty"`
+	CreatedAt   string         `json:"created_at,omitempty"`
+	UpdatedAt   string         `json:"updated_at,omitempty"`
+	Provenance  *Provenance    `json:"provenance,omitempty"`
+	VectorModel string         `json:"vector_model,omitempty"`
+	Vector      []float32      `json:"vector,omitempty"`
+}
+
+// ImportRequest describes a batch of export records to load into a bot.
+// Records keep their exported scoping fields (namespace, scopeId, ...);
+// Filters only fill in the fields a record lacks, unless OverrideScope is set,
+// in which case they replace the exported values. Records from the shared
+// bot namespace always take the namespace and scopeId from Filters. bot_id
+// is always the target bot.
+type ImportRequest struct {
+	BotID            string
+	Filters          map[string]any
+	OverrideScope    bool
+	Records          []ExportRecord
+	EmbeddingEnabled bool
+}
+
+// ImportResult summarises an import.
+type ImportResult struct {
+	Total      int             `json:"total"`
+	Imported   int             `json:"imported"`
+	Duplicates int             `json:"duplicates"`
+	Skipped    int             `json:"skipped"`
+	Reembedded int             `json:"reembedded"`
+	Scopes     []ImportedScope `json:"-"`
+}
+
+// ImportedScope groups the imported memories that share a namespace and
+// scopeId. Filters holds those two fields.
+type ImportedScope struct {
+	Filters map[string]any
+	Items   []MemoryItem
+}
+
+// payloadRecordKeys are payload fields that map to dedicated ExportRecord
+// fields; everything else is exported as a filter.
+var payloadRecordKeys = map[string]struct{}{
+	"data": {}, "hash": {}, "lang": {}, "metadata": {}, "created_at": {}, "updated_at": {}, "provenance": {},
+}
+
+// Export pages through every memory matching filters and passes each one to
+// fn as an ExportRecord. With withVectors set, the dense vector of the bot's
+// active text model is included.
+func (s *Service) Export(ctx context.Context, botID string, filters map[string]any, withVectors bool, fn func(ExportRecord) error) error {
+	if s.store == nil {
+		return fmt.Errorf("vector store not configured")
+	}
+	filters = cloneFilters(filters)
+	if botID = strings.TrimSpace(botID); botID != "" {
+		filters["bot_id"] = botID
+	}
+	if len(filters) == 0 {
+		return fmt.Errorf("bot_id or filters are required")
+	}
+	vectorName := s.textVectorName(botID)
+	offset := ""
+	for {
+		var (
+			points []VectorPoint
+			next   string
+			err    error
+		)
+		if withVectors {
+			points, next, err = s.store.ScrollWithVectors(ctx, exportBatchSize, filters, offset, vectorName)
+		} else {
+			points, next, err = s.store.Scroll(ctx, exportBatchSize, filters, offset)
+		}
+		if err != nil {
+			return err
+		}
+		for _, point := range points {
+			if err := fn(pointToExportRecord(point)); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		offset = next
+	}
+}
+
+func pointToExportRecord(point VectorPoint) ExportRecord {
+	item := payloadToMemoryItem(point.ID, point.Payload)
+	record := ExportRecord{
+		ID:         point.ID,
+		Memory:     item.Memory,
+		Hash:       item.Hash,
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+		Provenance: item.Provenance,
+		Filters:    map[string]any{},
+	}
+	if meta, ok := point.Payload["metadata"].(map[string]any); ok && len(meta) > 0 {
+		record.Metadata = meta
+	}
+	if lang, ok := point.Payload["lang"].(string); ok {
+		record.Lang = lang
+	}
+	for key, value := range point.Payload {
+		if _, reserved := payloadRecordKeys[key]; !reserved {
+			record.Filters[key] = value
+		}
+	}
+	if len(point.Vector) > 0 {
+		record.Vector = point.Vector
+		record.VectorModel = point.VectorName
+	}
+	return record
+}
+
+// Import writes export records into the bot's memory. Records whose text hash
+// already exists for the bot are skipped, timestamps and scoping fields are
+// preserved (see ImportRequest), and exported vectors are reused only when they
+// were produced by the bot's active text model; otherwise the text is
+// re-embedded.
+func (s *Service) Import(ctx context.Context, req ImportRequest) (ImportResult, error) {
+	botID := strings.TrimSpace(req.BotID)
+	if botID == "" {
+		return ImportResult{}, fmt.Errorf("bot_id is required")
+	}
+	if s.store == nil {
+		return ImportResult{}, fmt.Errorf("vector store not configured")
+	}
+	if s.bm25 == nil {
+		return ImportResult{}, fmt.Errorf("bm25 indexer not configured")
+	}
+	embeddingEnabled := req.EmbeddingEnabled && s.embedder != nil
+
+	existing, err := s.existingHashes(ctx, map[string]any{"bot_id": botID})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	vectorName := s.textVectorName(botID)
+	usedIDs := map[string]struct{}{}
+	scopeIndex := map[string]int{}
+	result := ImportResult{Total: len(req.Records)}
+	batch := make([]VectorPoint, 0, exportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.store.Upsert(ctx, batch); err != nil {
+			return err
+		}
+		for _, point := range batch {
+			s.recordHistory(ctx, point.ID, point.Payload, "ADD", HistorySourceImport, "", fmt.Sprint(point.Payload["data"]), provenanceFromPayload(point.Payload["provenance"]))
+			scope := map[string]any{}
+			for _, key := range []string{"namespace", "scopeId"} {
+				if value, ok := point.Payload[key]; ok {
+					scope[key] = value
+				}
+			}
+			key := fmt.Sprint(scope["namespace"]) + "\x00" + fmt.Sprint(scope["scopeId"])
+			idx, ok := scopeIndex[key]
+			if !ok {
+				idx = len(result.Scopes)
+				scopeIndex[key] = idx
+				result.Scopes = append(result.Scopes, ImportedScope{Filters: scope})
+			}
+			result.Scopes[idx].Items = append(result.Scopes[idx].Items, payloadToMemoryItem(point.ID, point.Payload))
+		}
+		result.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for _, record := range req.Records {
+		text := strings.TrimSpace(record.Memory)
+		if text == "" {
+			result.Skipped++
+			continue
+		}
+		hash := hashMemory(text)
+		if _, dup := existing[hash]; dup {
+			result.Duplicates++
+			continue
+		}
+		existing[hash] = struct{}{}
+
+		filters := importFilters(record.Filters, req.Filters, req.OverrideScope, botID)
+		payload := buildPayload(text, filters, record.Metadata, normalizeImportTime(record.CreatedAt))
+		if updatedAt := normalizeImportTime(record.UpdatedAt); updatedAt != "" {
+			payload["updated_at"] = updatedAt
+		}
+		if record.Provenance != nil {
+			payload["provenance"] = provenanceToPayload(record.Provenance)
+		}
+		lang := strings.TrimSpace(record.Lang)
+		if lang == "" {
+			if lang, err = s.detectLanguage(ctx, text); err != nil {
+				return result, err
+			}
+		}
+		payload["lang"] = lang
+		termFreq, docLen, err := s.bm25.TermFrequencies(lang, text)
+		if err != nil {
+			return result, err
+		}
+		sparseIndices, sparseValues := s.bm25.AddDocument(lang, termFreq, docLen)
+
+		point := VectorPoint{
+			ID:               s.importPointID(ctx, record.ID, usedIDs),
+			SparseIndices:    sparseIndices,
+			SparseValues:     sparseValues,
+			SparseVectorName: s.store.SparseVectorName(),
+			Payload:          payload,
+		}
+		if embeddingEnabled {
+			if len(record.Vector) > 0 && record.VectorModel == vectorName {
+				point.Vector = record.Vector
+				point.VectorName = vectorName
+			} else {
+				vector, name, err := s.embedTextForBot(ctx, botID, text)
+				if err != nil {
+					return result, fmt.Errorf("embed record %s: %w", record.ID, err)
+				}
+				point.Vector = vector
+				point.VectorName = name
+				result.Reembedded++
+			}
+		}
+		batch = append(batch, point)
+		if len(batch) >= exportBatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	s.logger.Info("memory.Import: completed",
+		slog.String("bot_id", botID),
+		slog.Int("total", result.Total),
+		slog.Int("imported", result.Imported),
+		slog.Int("duplicates", result.Duplicates),
+		slog.Int("reembedded", result.Reembedded),
+	)
+	return result, nil
+}
+
+// importFilters returns the scoping fields of an imported record: the
+// exported ones, with requested filling in missing fields or, with override,
+// replacing them. Records from the shared bot namespace always move into the
+// requested namespace and scope, since the source bot's scopeId would never
+// match a recall in the target bot; only their non-scoping fields are kept.
+// bot_id is always the target bot.
+func importFilters(exported, requested map[string]any, override bool, botID string) map[string]any {
+	filters := cloneFilters(exported)
+	shared := false
+	if namespace, _ := exported["namespace"].(string); namespace == "" || namespace == sharedImportNamespace {
+		shared = true
+	}
+	for key, value := range requested {
+		if _, ok := filters[key]; ok && !override {
+			if !shared || (key != "namespace" && key != "scopeId") {
+				continue
+			}
+		}
+		filters[key] = value
+	}
+	filters["bot_id"] = botID
+	return filters
+}
+
+func (s *Service) existingHashes(ctx context.Context, filters map[string]any) (map[string]struct{}, error) {
+	hashes := map[string]struct{}{}
+	offset := ""
+	for {
+		points, next, err := s.store.Scroll(ctx, exportBatchSize, filters, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range points {
+			if hash, ok := point.Payload["hash"].(string); ok && hash != "" {
+				hashes[hash] = struct{}{}
+			} else if text, ok := point.Payload["data"].(string); ok {
+				hashes[hashMemory(text)] = struct{}{}
+			}
+		}
+		if next == "" {
+			return hashes, nil
+		}
+		offset = next
+	}
+}
+
+// importPointID keeps the exported ID when it is a free UUID, so that files
+// written by MemoryFS keep matching. Importing into the deployment the export
+// came from (e.g. cloning a bot) would collide, so a fresh ID is used then.
+func (s *Service) importPointID(ctx context.Context, id string, used map[string]struct{}) string {
+	id = strings.TrimSpace(id)
+	_, seen := used[id]
+	if _, err := uuid.Parse(id); err != nil || seen {
+		id = uuid.NewString()
+	} else if existing, err := s.store.Get(ctx, id); err != nil || existing != nil {
+		id = uuid.NewString()
+	}
+	used[id] = struct{}{}
+	return id
+}
+
+func normalizeImportTime(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return parsed.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,84 @@
+package memory
+
+import "testing"
+
+func TestPointToExportRecord(t *testing.T) {
+	t.Parallel()
+
+	record := pointToExportRecord(VectorPoint{
+		ID:         "7f1b8c2e-0000-4000-8000-000000000001",
+		Vector:     []float32{0.1, 0.2},
+		VectorName: "bge-m3",
+		Payload: map[string]any{
+			"data":       "User likes Go",
+			"hash":       "abc",
+			"lang":       "en",
+			"created_at": "2025-01-02T03:04:05Z",
+			"metadata":   map[string]any{"source": "chat"},
+			"bot_id":     "bot-1",
+			"namespace":  "bot",
+			"scopeId":    "bot-1",
+		},
+	})
+	if record.Memory != "User likes Go" || record.Hash != "abc" || record.Lang != "en" {
+		t.Fatalf("unexpected record fields: %+v", record)
+	}
+	if record.CreatedAt != "2025-01-02T03:04:05Z" {
+		t.Fatalf("created_at = %q", record.CreatedAt)
+	}
+	if len(record.Filters) != 3 || record.Filters["namespace"] != "bot" {
+		t.Fatalf("filters = %v", record.Filters)
+	}
+	if _, ok := record.Filters["data"]; ok {
+		t.Fatalf("data leaked into filters: %v", record.Filters)
+	}
+	if record.VectorModel != "bge-m3" || len(record.Vector) != 2 {
+		t.Fatalf("vector = %q %v", record.VectorModel, record.Vector)
+	}
+}
+
+func TestNormalizeImportTime(t *testing.T) {
+	t.Parallel()
+
+	if got := normalizeImportTime("2025-01-02T11:04:05+08:00"); got != "2025-01-02T03:04:05Z" {
+		t.Fatalf("normalizeImportTime = %q", got)
+	}
+	if got := normalizeImportTime("yesterday"); got != "" {
+		t.Fatalf("normalizeImportTime(invalid) = %q", got)
+	}
+}
+
+func TestImportFilters_RewritesSharedScope(t *testing.T) {
+	t.Parallel()
+
+	exported := map[string]any{"namespace": "bot", "scopeId": "bot-old", "bot_id": "bot-old", "source": "chat"}
+	requested := map[string]any{"namespace": "bot", "scopeId": "bot-new", "agent_id": "agent-1"}
+
+	got := importFilters(exported, requested, false, "bot-new")
+	if got["scopeId"] != "bot-new" || got["namespace"] != "bot" {
+		t.Fatalf("shared record not moved to the target scope: %v", got)
+	}
+	if got["source"] != "chat" {
+		t.Fatalf("non-scoping field not preserved: %v", got)
+	}
+	if got["agent_id"] != "agent-1" {
+		t.Fatalf("missing field not filled from request: %v", got)
+	}
+	if got["bot_id"] != "bot-new" {
+		t.Fatalf("bot_id = %v, want target bot", got["bot_id"])
+	}
+	if exported["bot_id"] != "bot-old" || exported["scopeId"] != "bot-old" {
+		t.Fatalf("exported filters were mutated: %v", exported)
+	}
+
+	other := map[string]any{"namespace": "user", "scopeId": "user-1", "source": "chat"}
+	got = importFilters(other, requested, false, "bot-new")
+	if got["namespace"] != "user" || got["scopeId"] != "user-1" {
+		t.Fatalf("non-shared scope not preserved: %v", got)
+	}
+
+	got = importFilters(other, requested, true, "bot-new")
+	if got["namespace"] != "bot" || got["scopeId"] != "bot-new" || got["source"] != "chat" {
+		t.Fatalf("override did not replace scope: %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// History sources: edits decided from a conversation round, edits made
+// directly through the API, and memories written by compaction or import.
+const (
+	HistorySourceConversation = "conversation"
+	HistorySourceManual       = "manual"
+	HistorySourceCompaction   = "compaction"
+	HistorySourceImport       = "import"
+)
+
+// HistoryEntry is one edit of a memory: the decision event, the text before
+// and after it, and the provenance of the round that caused it.
+type HistoryEntry struct {
+	ID         string     `json:"id"`
+	MemoryID   string     `json:"memory_id"`
+	BotID      string     `json:"bot_id,omitempty"`
+	Event      string     `json:"event"`
+	Source     string     `json:"source"`
+	OldMemory  string     `json:"old_memory,omitempty"`
+	NewMemory  string     `json:"new_memory,omitempty"`
+	Provenance Provenance `json:"provenance"`
+	CreatedAt  string     `json:"created_at"`
+}
+
+// HistoryStore keeps the append-only edit log of memories in PostgreSQL. The
+// vector store only holds the current text, so this is the only place where
+// earlier versions and the conversations that changed them are kept.
+type HistoryStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewHistoryStore creates a history store backed by the memory_history table.
+func NewHistoryStore(pool *pgxpool.Pool, log *slog.Logger) *HistoryStore {
+	return &HistoryStore{
+		pool:   pool,
+		logger: log.With(slog.String("component", "history_store")),
+	}
+}
+
+// Record appends one entry to the log.
+func (r *HistoryStore) Record(ctx context.Context, entry HistoryEntry) error {
+	if strings.TrimSpace(entry.MemoryID) == "" {
+		return fmt.Errorf("memory_id is required")
+	}
+	messageIDs := entry.Provenance.MessageIDs
+	if messageIDs == nil {
+		messageIDs = []string{}
+	}
+	encodedIDs, err := json.Marshal(messageIDs)
+	if err != nil {
+		return fmt.Errorf("encode message ids: %w", err)
+	}
+	if _, err := r.pool.Exec(ctx,
+		`INSERT INTO memory_history (memory_id, bot_id, event, source, old_memory, new_memory,
+		   message_ids, external_message_id, chat_id, trace_id, channel, extractor_model)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		entry.MemoryID, entry.BotID, strings.ToUpper(entry.Event), entry.Source, entry.OldMemory, entry.NewMemory,
+		encodedIDs, entry.Provenance.ExternalMessageID, entry.Provenance.ChatID, entry.Provenance.TraceID,
+		entry.Provenance.Channel, entry.Provenance.ExtractorModel,
+	); err != nil {
+		return fmt.Errorf("record memory history: %w", err)
+	}
+	return nil
+}
+
+// ListByMemory returns the edits of one memory of a bot, oldest first.
+func (r *HistoryStore) ListByMemory(ctx context.Context, botID, memoryID string, limit int) ([]HistoryEntry, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT id::text, memory_id, bot_id, event, source, old_memory, new_memory,
+		        message_ids, external_message_id, chat_id, trace_id, channel, extractor_model, created_at
+		 FROM memory_history
+		 WHERE bot_id = $1 AND memory_id = $2
+		 ORDER BY created_at, id
+		 LIMIT $3`,
+		strings.TrimSpace(botID), strings.TrimSpace(memoryID), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list memory history: %w", err)
+	}
+	defer rows.Close()
+	entries := make([]HistoryEntry, 0)
+	for rows.Next() {
+		var (
+			entry      HistoryEntry
+			messageIDs []byte
+			createdAt  time.Time
+		)
+		if err := rows.Scan(&entry.ID, &entry.MemoryID, &entry.BotID, &entry.Event, &entry.Source,
+			&entry.OldMemory, &entry.NewMemory, &messageIDs, &entry.Provenance.ExternalMessageID,
+			&entry.Provenance.ChatID, &entry.Provenance.TraceID, &entry.Provenance.Channel,
+			&entry.Provenance.ExtractorModel, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan memory history: %w", err)
+		}
+		if len(messageIDs) > 0 {
+			if err := json.Unmarshal(messageIDs, &entry.Provenance.MessageIDs); err != nil {
+				r.logger.Warn("decode history message ids failed", slog.String("id", entry.ID), slog.Any("error", err))
+			}
+		}
+		entry.Provenance.Event = entry.Event
+		entry.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,368 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	FusionRRF      = "rrf"
+	FusionConvex   = "convex"
+	FusionAbsolute = "absolute"
+
+	defaultDenseWeight  = 0.5
+	defaultSparseWeight = 0.5
+
+	// bm25HalfScore is the BM25 score that absolute fusion maps to 0.5:
+	// roughly one rare query term matched once.
+	bm25HalfScore = 6.0
+)
+
+// hybridBranch is the ranked output of one retrieval branch (dense or sparse).
+type hybridBranch struct {
+	items  []MemoryItem
+	sparse map[string]VectorPoint
+	// vectors holds the dense vectors of the dense branch's hits when MMR
+	// reranking needs them.
+	vectors map[string][]float32
+	err     error
+}
+
+// embeddingEnabled resolves a request's EmbeddingEnabled flag. Unset means
+// dense vectors are used whenever an embedder is configured, so memories the
+// agent writes and recalls get the same treatment as explicit API calls.
+func (s *Service) embeddingEnabled(flag *bool) bool {
+	if flag != nil {
+		return *flag
+	}
+	return s.embedder != nil
+}
+
+// useHybrid reports whether a text search fuses dense and sparse recall. An
+// explicit Hybrid flag needs embeddings enabled; requests that leave
+// EmbeddingEnabled unset, like the agent's context recall and memory tools,
+// get hybrid whenever both an embedder and a sparse index exist, fused by
+// absolute score so their relevance thresholds keep working.
+func (s *Service) useHybrid(req SearchRequest, embeddingEnabled bool) bool {
+	if !embeddingEnabled {
+		return false
+	}
+	if req.Hybrid {
+		return true
+	}
+	return req.EmbeddingEnabled == nil && s.embedder != nil && s.bm25 != nil && s.store.SparseVectorName() != ""
+}
+
+// hybridSearch runs dense vector search and BM25 sparse search in parallel
+// and fuses the two ranked lists. When one branch fails the other is returned
+// on its own so that a flaky embedder does not take down keyword recall.
+func (s *Service) hybridSearch(ctx context.Context, req SearchRequest, filters map[string]any) (SearchResponse, error) {
+	if s.embedder == nil {
+		return SearchResponse{}, fmt.Errorf("embedder not configured")
+	}
+	if s.bm25 == nil {
+		return SearchResponse{}, fmt.Errorf("bm25 indexer not configured")
+	}
+	method, err := normalizeFusionMethod(req.FusionMethod)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	denseWeight, sparseWeight := normalizeFusionWeights(req.DenseWeight, req.SparseWeight)
+
+	overfetch := req.OverfetchRatio
+	if overfetch <= 0 {
+		overfetch = defaultOverfetchRatio
+	}
+	fetchLimit := req.Limit
+	if fetchLimit > 0 {
+		fetchLimit = req.Limit * overfetch
+	}
+	wantStats := !req.NoStats
+
+	var dense, sparse hybridBranch
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dense = s.searchDenseBranch(ctx, req, filters, fetchLimit, req.UseMMR)
+	}()
+	go func() {
+		defer wg.Done()
+		sparse = s.searchSparseBranch(ctx, req, filters, fetchLimit, wantStats)
+	}()
+	wg.Wait()
+
+	if dense.err != nil && sparse.err != nil {
+		return SearchResponse{}, dense.err
+	}
+	if dense.err != nil {
+		s.logger.Warn("memory.Search: hybrid dense branch failed, using sparse only", slog.Any("error", dense.err))
+	}
+	if sparse.err != nil {
+		s.logger.Warn("memory.Search: hybrid sparse branch failed, using dense only", slog.Any("error", sparse.err))
+	}
+
+	var results []MemoryItem
+	switch method {
+	case FusionConvex:
+		results = fuseByConvexCombination(dense.items, sparse.items, denseWeight, sparseWeight)
+	case FusionAbsolute:
+		results = fuseByAbsoluteScore(dense.items, sparse.items)
+	default:
+		results = fuseByWeightedRRF(dense.items, sparse.items, denseWeight, sparseWeight)
+	}
+	if req.UseMMR {
+		results = mmrRerankFused(results, dense.vectors, req.MMRLambda, req.Limit, method == FusionRRF)
+	} else if req.Limit > 0 && len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+	if wantStats {
+		for i := range results {
+			if p, ok := sparse.sparse[results[i].ID]; ok {
+				results[i].TopKBuckets, results[i].CDFCurve = computeSparseVectorStats(p.SparseIndices, p.SparseValues)
+			}
+		}
+	}
+	s.logger.Debug("memory.Search: hybrid fused",
+		slog.String("method", method),
+		slog.Int("dense_hits", len(dense.items)),
+		slog.Int("sparse_hits", len(sparse.items)),
+		slog.Int("results", len(results)),
+	)
+	return SearchResponse{Results: results}, nil
+}
+
+func (s *Service) searchDenseBranch(ctx context.Context, req SearchRequest, filters map[string]any, limit int, withVectors bool) hybridBranch {
+	vector, vectorName, err := s.embedTextForBot(ctx, req.BotID, req.Query)
+	if err != nil {
+		return hybridBranch{err: err}
+	}
+	if len(req.Sources) == 0 {
+		points, scores, err := s.store.SearchWithVectors(ctx, vector, limit, filters, vectorName, withVectors)
+		if err != nil {
+			return hybridBranch{err: err}
+		}
+		branch := hybridBranch{items: pointsToScoredItems(points, scores)}
+		if withVectors {
+			branch.vectors = make(map[string][]float32, len(points))
+			for _, p := range points {
+				branch.vectors[p.ID] = p.Vector
+			}
+		}
+		return branch
+	}
+	pointsBySource, scoresBySource, err := s.store.SearchBySources(ctx, vector, limit, filters, req.Sources, vectorName)
+	if err != nil {
+		return hybridBranch{err: err}
+	}
+	return hybridBranch{items: fuseByRankFusion(pointsBySource, scoresBySource)}
+}
+
+func (s *Service) searchSparseBranch(ctx context.Context, req SearchRequest, filters map[string]any, limit int, wantStats bool) hybridBranch {
+	lang, err := s.detectLanguage(ctx, req.Query)
+	if err != nil {
+		return hybridBranch{err: err}
+	}
+	termFreq, _, err := s.bm25.TermFrequencies(lang, req.Query)
+	if err != nil {
+		return hybridBranch{err: err}
+	}
+	indices, values := s.bm25.BuildQueryVector(lang, termFreq)
+
+	var branch hybridBranch
+	if wantStats {
+		branch.sparse = make(map[string]VectorPoint)
+	}
+	if len(req.Sources) == 0 {
+		points, scores, err := s.store.SearchSparse(ctx, indices, values, limit, filters, wantStats)
+		if err != nil {
+			return hybridBranch{err: err}
+		}
+		branch.items = pointsToScoredItems(points, scores)
+		if wantStats {
+			for _, p := range points {
+				branch.sparse[p.ID] = p
+			}
+		}
+		return branch
+	}
+	pointsBySource, scoresBySource, err := s.store.SearchSparseBySources(ctx, indices, values, limit, filters, req.Sources, wantStats)
+	if err != nil {
+		return hybridBranch{err: err}
+	}
+	branch.items = fuseByRankFusion(pointsBySource, scoresBySource)
+	if wantStats {
+		for _, pts := range pointsBySource {
+			for _, p := range pts {
+				if len(p.SparseIndices) > 0 {
+					branch.sparse[p.ID] = p
+				}
+			}
+		}
+	}
+	return branch
+}
+
+// mmrRerankFused applies MMR to fused results. With rescale, fused scores
+// (RRF's are tiny) are rescaled to [0, 1] first so they weigh against cosine
+// similarity the way dense scores do. Items only the sparse branch returned
+// have no vector and never count as similar to anything.
+func mmrRerankFused(items []MemoryItem, vectors map[string][]float32, lambda float64, limit int, rescale bool) []MemoryItem {
+	if len(items) == 0 {
+		return items
+	}
+	top := items[0].Score
+	candidates := make([]mmrCandidate, len(items))
+	for i, item := range items {
+		if rescale && top > 0 {
+			item.Score /= top
+		}
+		candidates[i] = mmrCandidate{item: item, vector: vectors[item.ID]}
+	}
+	return mmrRerank(candidates, lambda, limit)
+}
+
+func pointsToScoredItems(points []VectorPoint, scores []float64) []MemoryItem {
+	items := make([]MemoryItem, 0, len(points))
+	for idx, point := range points {
+		item := payloadToMemoryItem(point.ID, point.Payload)
+		if idx < len(scores) {
+			item.Score = scores[idx]
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func normalizeFusionMethod(method string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(method)) {
+	case "", FusionRRF:
+		return FusionRRF, nil
+	case FusionConvex:
+		return FusionConvex, nil
+	case FusionAbsolute:
+		return FusionAbsolute, nil
+	default:
+		return "", fmt.Errorf("unsupported fusion method: %s", method)
+	}
+}
+
+// normalizeFusionWeights fills in defaults for unset weights and scales the
+// pair so that it sums to 1.
+func normalizeFusionWeights(dense, sparse float64) (float64, float64) {
+	if dense < 0 {
+		dense = 0
+	}
+	if sparse < 0 {
+		sparse = 0
+	}
+	if dense == 0 && sparse == 0 {
+		return defaultDenseWeight, defaultSparseWeight
+	}
+	total := dense + sparse
+	return dense / total, sparse / total
+}
+
+// fuseByWeightedRRF merges two ranked lists with Reciprocal Rank Fusion where
+// each list's contribution is scaled by its weight: w / (k + rank).
+func fuseByWeightedRRF(dense, sparse []MemoryItem, denseWeight, sparseWeight float64) []MemoryItem {
+	byID := map[string]MemoryItem{}
+	scores := map[string]float64{}
+	accumulate := func(items []MemoryItem, weight float64) {
+		for idx, item := range items {
+			if _, ok := byID[item.ID]; !ok {
+				byID[item.ID] = item
+			}
+			scores[item.ID] += weight / (rrfK + float64(idx+1))
+		}
+	}
+	accumulate(dense, denseWeight)
+	accumulate(sparse, sparseWeight)
+	return collectFused(byID, scores)
+}
+
+// fuseByConvexCombination min-max normalises each list's raw scores to [0, 1]
+// and combines them linearly. Items missing from a list contribute 0 for it.
+func fuseByConvexCombination(dense, sparse []MemoryItem, denseWeight, sparseWeight float64) []MemoryItem {
+	byID := map[string]MemoryItem{}
+	scores := map[string]float64{}
+	accumulate := func(items []MemoryItem, weight float64) {
+		if len(items) == 0 {
+			return
+		}
+		lo, hi := items[0].Score, items[0].Score
+		for _, item := range items[1:] {
+			if item.Score < lo {
+				lo = item.Score
+			}
+			if item.Score > hi {
+				hi = item.Score
+			}
+		}
+		for _, item := range items {
+			if _, ok := byID[item.ID]; !ok {
+				byID[item.ID] = item
+			}
+			norm := 1.0
+			if hi > lo {
+				norm = (item.Score - lo) / (hi - lo)
+			}
+			scores[item.ID] += weight * norm
+		}
+	}
+	accumulate(dense, denseWeight)
+	accumulate(sparse, sparseWeight)
+	return collectFused(byID, scores)
+}
+
+// fuseByAbsoluteScore combines the branches' own scores instead of their
+// ranks or per-query min-max, so a query that matches nothing well keeps low
+// scores. Cosine similarity is used as is and BM25 is squashed into [0, 1) by
+// x / (x + bm25HalfScore); the two are joined as independent evidence,
+// 1 - (1-dense)(1-sparse). An item without keyword overlap keeps its cosine
+// score, so relevance thresholds tuned for dense search still hold. Weights
+// do not apply.
+func fuseByAbsoluteScore(dense, sparse []MemoryItem) []MemoryItem {
+	byID := map[string]MemoryItem{}
+	denseScores := map[string]float64{}
+	sparseScores := map[string]float64{}
+	for _, item := range dense {
+		if _, ok := byID[item.ID]; !ok {
+			byID[item.ID] = item
+		}
+		denseScores[item.ID] = math.Min(math.Max(item.Score, 0), 1)
+	}
+	for _, item := range sparse {
+		if _, ok := byID[item.ID]; !ok {
+			byID[item.ID] = item
+		}
+		if item.Score > 0 {
+			sparseScores[item.ID] = item.Score / (item.Score + bm25HalfScore)
+		}
+	}
+	scores := make(map[string]float64, len(byID))
+	for id := range byID {
+		scores[id] = 1 - (1-denseScores[id])*(1-sparseScores[id])
+	}
+	return collectFused(byID, scores)
+}
+
+func collectFused(byID map[string]MemoryItem, scores map[string]float64) []MemoryItem {
+	items := make([]MemoryItem, 0, len(byID))
+	for id, item := range byID {
+		item.Score = scores[id]
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score == items[j].Score {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].Score > items[j].Score
+	})
+	return items
+}
@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"testing"
+)
+
+// sparseVectorStore satisfies VectorStore for tests that only need the
+// sparse index name; any other call panics on the nil embedded interface.
+type sparseVectorStore struct {
+	VectorStore
+	sparseName string
+}
+
+func (s sparseVectorStore) SparseVectorName() string { return s.sparseName }
+
+type stubEmbedder struct{}
+
+func (stubEmbedder) Embed(context.Context, string) ([]float32, error) { return []float32{1, 0}, nil }
+func (stubEmbedder) Dimensions() int                                  { return 2 }
+
+func TestFuseByWeightedRRF(t *testing.T) {
+	t.Parallel()
+
+	dense := []MemoryItem{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.8}}
+	sparse := []MemoryItem{{ID: "c", Score: 12.0}, {ID: "a", Score: 3.0}}
+
+	results := fuseByWeightedRRF(dense, sparse, 0.5, 0.5)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].ID != "a" {
+		t.Fatalf("expected item present in both lists to rank first, got %s", results[0].ID)
+	}
+
+	sparseHeavy := fuseByWeightedRRF(dense, sparse, 0.1, 0.9)
+	if sparseHeavy[1].ID != "c" {
+		t.Fatalf("expected sparse-only hit to outrank dense-only hit, got %s", sparseHeavy[1].ID)
+	}
+}
+
+func TestFuseByConvexCombination(t *testing.T) {
+	t.Parallel()
+
+	dense := []MemoryItem{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.5}}
+	sparse := []MemoryItem{{ID: "b", Score: 20.0}, {ID: "c", Score: 10.0}}
+
+	results := fuseByConvexCombination(dense, sparse, 0.5, 0.5)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	// a: 0.5*1 + 0 = 0.5; b: 0.5*0 + 0.5*1 = 0.5; c: 0 + 0.5*0 = 0.
+	if results[2].ID != "c" || results[2].Score != 0 {
+		t.Fatalf("expected c last with score 0, got %s=%f", results[2].ID, results[2].Score)
+	}
+}
+
+func TestNormalizeFusionWeights(t *testing.T) {
+	t.Parallel()
+
+	d, s := normalizeFusionWeights(0, 0)
+	if d != defaultDenseWeight || s != defaultSparseWeight {
+		t.Fatalf("expected defaults, got %f/%f", d, s)
+	}
+	d, s = normalizeFusionWeights(3, 1)
+	if d != 0.75 || s != 0.25 {
+		t.Fatalf("expected 0.75/0.25, got %f/%f", d, s)
+	}
+	if _, err := normalizeFusionMethod("bogus"); err == nil {
+		t.Fatalf("expected error for unknown fusion method")
+	}
+}
+
+func TestUseHybridDefaultsOnWithSparseIndex(t *testing.T) {
+	t.Parallel()
+
+	on, off := true, false
+	s := &Service{embedder: stubEmbedder{}, bm25: NewBM25Indexer(nil), store: sparseVectorStore{sparseName: "bm25"}}
+	if !s.embeddingEnabled(nil) {
+		t.Fatal("unset EmbeddingEnabled should follow the configured embedder")
+	}
+	if !s.useHybrid(SearchRequest{}, s.embeddingEnabled(nil)) {
+		t.Fatal("agent searches should default to hybrid when a sparse index exists")
+	}
+	if s.useHybrid(SearchRequest{EmbeddingEnabled: &on}, true) {
+		t.Fatal("explicit dense search without Hybrid should stay dense-only")
+	}
+	if s.useHybrid(SearchRequest{EmbeddingEnabled: &off, Hybrid: true}, false) {
+		t.Fatal("hybrid needs embeddings enabled")
+	}
+
+	noSparse := &Service{embedder: stubEmbedder{}, bm25: NewBM25Indexer(nil), store: sparseVectorStore{}}
+	if noSparse.useHybrid(SearchRequest{}, true) {
+		t.Fatal("no sparse index, no default hybrid")
+	}
+	noEmbedder := &Service{bm25: NewBM25Indexer(nil), store: sparseVectorStore{sparseName: "bm25"}}
+	if noEmbedder.embeddingEnabled(nil) || noEmbedder.useHybrid(SearchRequest{}, false) {
+		t.Fatal("without an embedder searches stay sparse-only")
+	}
+}
+
+func TestMMRRerankFused(t *testing.T) {
+	t.Parallel()
+
+	// a and b are near-duplicates; c is less relevant but different.
+	fused := []MemoryItem{{ID: "a", Score: 0.030}, {ID: "b", Score: 0.029}, {ID: "c", Score: 0.020}}
+	vectors := map[string][]float32{
+		"a": {1, 0},
+		"b": {0.99, 0.01},
+		"c": {0, 1},
+	}
+	results := mmrRerankFused(fused, vectors, 0.5, 2, true)
+	if len(results) != 2 || results[0].ID != "a" || results[1].ID != "c" {
+		t.Fatalf("expected MMR to pick a then the diverse c, got %+v", results)
+	}
+	// Without dense vectors MMR keeps the fused order.
+	plain := mmrRerankFused(fused, nil, 0.5, 2, true)
+	if plain[0].ID != "a" || plain[1].ID != "b" {
+		t.Fatalf("expected fused order without vectors, got %+v", plain)
+	}
+}
+
+func TestFuseByAbsoluteScore(t *testing.T) {
+	t.Parallel()
+
+	dense := []MemoryItem{{ID: "a", Score: 0.55}, {ID: "b", Score: 0.40}}
+	sparse := []MemoryItem{{ID: "a", Score: 6}, {ID: "c", Score: 6}}
+
+	results := fuseByAbsoluteScore(dense, sparse)
+	if len(results) != 3 || results[0].ID != "a" {
+		t.Fatalf("expected a first of three, got %+v", results)
+	}
+	// a: 1 - 0.45*0.5; c: keyword hit only; b: cosine unchanged.
+	want := map[string]float64{"a": 0.775, "b": 0.40, "c": 0.5}
+	for _, item := range results {
+		if math.Abs(item.Score-want[item.ID]) > 1e-9 {
+			t.Errorf("%s score = %f, want %f", item.ID, item.Score, want[item.ID])
+		}
+	}
+}
+
+// lowScoreStore answers an unrelated query: weak cosine matches and no
+// keyword overlap.
+type lowScoreStore struct {
+	sparseVectorStore
+}
+
+func (lowScoreStore) UsesNamedVectors() bool { return false }
+
+func (lowScoreStore) SearchWithVectors(context.Context, []float32, int, map[string]any, string, bool) ([]VectorPoint, []float64, error) {
+	return []VectorPoint{
+		{ID: "a", Payload: map[string]any{"data": "likes green tea"}},
+		{ID: "b", Payload: map[string]any{"data": "works night shifts"}},
+	}, []float64{0.08, 0.05}, nil
+}
+
+func (lowScoreStore) SearchSparse(context.Context, []uint32, []float32, int, map[string]any, bool) ([]VectorPoint, []float64, error) {
+	return nil, nil, nil
+}
+
+func TestDefaultHybridKeepsIrrelevantScoresLow(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{
+		logger:   slog.New(slog.DiscardHandler),
+		embedder: stubEmbedder{},
+		bm25:     NewBM25Indexer(nil),
+		store:    lowScoreStore{sparseVectorStore{sparseName: "bm25"}},
+	}
+	resp, err := s.search(context.Background(), SearchRequest{Query: "quarterly tax deadline", BotID: "bot-1", Limit: 4, NoStats: true})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected both weak hits, got %+v", resp.Results)
+	}
+	// Agent recall drops memories below 0.1; min-max fusion would have
+	// scored the top hit 1.
+	for _, item := range resp.Results {
+		if item.Score >= 0.1 {
+			t.Errorf("%s score = %f, want below the recall threshold", item.ID, item.Score)
+		}
+	}
+}
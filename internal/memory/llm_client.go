@@ -74,6 +74,7 @@ func (c *LLMClient) Extract(ctx context.Context, req ExtractRequest) (ExtractRes
 		return ExtractResponse{}, err
 	}
 	c.logger.Info("memory.llm.Extract: completed", slog.Int("facts", len(parsed.Facts)))
+	parsed.Model = c.model
 	return parsed, nil
 }
 
@@ -177,6 +178,39 @@ func (c *LLMClient) Compact(ctx context.Context, req CompactRequest) (CompactRes
 	return parsed, nil
 }
 
+func (c *LLMClient) ExtractRelations(ctx context.Context, req ExtractRelationsRequest) (ExtractRelationsResponse, error) {
+	if len(req.Messages) == 0 {
+		return ExtractRelationsResponse{}, fmt.Errorf("messages is required")
+	}
+	c.logger.Debug("memory.llm.ExtractRelations: starting", slog.Int("message_count", len(req.Messages)))
+	systemPrompt, userPrompt := getRelationExtractionMessages(strings.Join(formatMessages(req.Messages), "\n"))
+	content, err := c.callChat(ctx, []chatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	})
+	if err != nil {
+		c.logger.Warn("memory.llm.ExtractRelations: call failed", slog.Any("error", err))
+		return ExtractRelationsResponse{}, err
+	}
+	var parsed ExtractRelationsResponse
+	if err := json.Unmarshal([]byte(removeCodeBlocks(content)), &parsed); err != nil {
+		c.logger.Warn("memory.llm.ExtractRelations: parse failed", slog.Any("error", err), slog.String("content_prefix", truncateStr(content, 200)))
+		return ExtractRelationsResponse{}, err
+	}
+	triples := make([]RelationTriple, 0, len(parsed.Relations))
+	for _, t := range parsed.Relations {
+		t.Subject = strings.TrimSpace(t.Subject)
+		t.Predicate = normalizePredicate(t.Predicate)
+		t.Object = strings.TrimSpace(t.Object)
+		if t.Subject == "" || t.Predicate == "" || t.Object == "" {
+			continue
+		}
+		triples = append(triples, t)
+	}
+	c.logger.Info("memory.llm.ExtractRelations: completed", slog.Int("relations", len(triples)))
+	return ExtractRelationsResponse{Relations: triples}, nil
+}
+
 func (c *LLMClient) DetectLanguage(ctx context.Context, text string) (string, error) {
 	if strings.TrimSpace(text) == "" {
 		return "", fmt.Errorf("text is required")
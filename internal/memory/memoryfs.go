@@ -20,6 +20,8 @@ const (
 )
 
 // MemoryFS persists memory entries as files inside the bot container via ExecRunner.
+// The bot can read those files from any conversation, so private and PII
+// memories are never written there; they live only in the vector store.
 type MemoryFS struct {
 	execRunner container.ExecRunner
 	workDir    string // e.g. "/data"
@@ -36,10 +38,12 @@ type Manifest struct {
 
 // ManifestEntry records metadata for a single memory entry.
 type ManifestEntry struct {
-	Hash      string         `json:"hash"`
-	CreatedAt string         `json:"created_at"`
-	Lang      string         `json:"lang,omitempty"`
-	Filters   map[string]any `json:"filters,omitempty"`
+	Hash          string         `json:"hash"`
+	CreatedAt     string         `json:"created_at"`
+	Lang          string         `json:"lang,omitempty"`
+	Filters       map[string]any `json:"filters,omitempty"`
+	Sensitivity   string         `json:"sensitivity,omitempty"`
+	AudienceGroup string         `json:"audience_group,omitempty"`
 }
 
 // NewMemoryFS creates a MemoryFS that writes through the given ExecRunner.
@@ -60,7 +64,8 @@ func NewMemoryFS(log *slog.Logger, runner container.ExecRunner, workDir string)
 // ----- write operations -----
 
 // PersistMemories writes .md files for new items and incrementally updates the manifest.
-// Used after Add — does NOT delete existing files.
+// Used after Add — does NOT delete existing files, except those of items that
+// became private or gained PII.
 func (fs *MemoryFS) PersistMemories(ctx context.Context, botID string, items []MemoryItem, filters map[string]any) error {
 	if len(items) == 0 {
 		return nil
@@ -81,17 +86,20 @@ func (fs *MemoryFS) PersistMemories(ctx context.Context, botID string, items []M
 		if strings.TrimSpace(item.ID) == "" || strings.TrimSpace(item.Memory) == "" {
 			continue
 		}
+		if !persistable(item) {
+			if _, ok := manifest.Entries[item.ID]; ok {
+				fs.execDeleteFile(ctx, botID, fmt.Sprintf("%s/%s.md", memoryDirPath, item.ID))
+				delete(manifest.Entries, item.ID)
+			}
+			continue
+		}
 		// Write individual .md file.
 		if err := fs.writeMemoryFile(ctx, botID, item); err != nil {
 			fs.logger.Warn("write memory file failed", slog.String("id", item.ID), slog.Any("error", err))
 			continue
 		}
 		// Update manifest entry.
-		manifest.Entries[item.ID] = ManifestEntry{
-			Hash:      item.Hash,
-			CreatedAt: item.CreatedAt,
-			Filters:   filters,
-		}
+		manifest.Entries[item.ID] = newManifestEntry(item, filters)
 	}
 
 	manifest.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
@@ -114,18 +122,14 @@ func (fs *MemoryFS) RebuildFiles(ctx context.Context, botID string, items []Memo
 	}
 
 	for _, item := range items {
-		if strings.TrimSpace(item.ID) == "" || strings.TrimSpace(item.Memory) == "" {
+		if strings.TrimSpace(item.ID) == "" || strings.TrimSpace(item.Memory) == "" || !persistable(item) {
 			continue
 		}
 		if err := fs.writeMemoryFile(ctx, botID, item); err != nil {
 			fs.logger.Warn("rebuild write memory file failed", slog.String("id", item.ID), slog.Any("error", err))
 			continue
 		}
-		manifest.Entries[item.ID] = ManifestEntry{
-			Hash:      item.Hash,
-			CreatedAt: item.CreatedAt,
-			Filters:   filters,
-		}
+		manifest.Entries[item.ID] = newManifestEntry(item, filters)
 	}
 
 	return fs.writeManifest(ctx, botID, manifest)
@@ -222,6 +226,22 @@ func (fs *MemoryFS) ReadAllMemoryFiles(ctx context.Context, botID string) ([]Mem
 
 // ----- internal helpers -----
 
+// persistable reports whether item may be written into the container. Private
+// and PII memories stay out, since the bot reads these files in group chats too.
+func persistable(item MemoryItem) bool {
+	return item.Sensitivity != SensitivityPrivate && len(item.PII) == 0
+}
+
+func newManifestEntry(item MemoryItem, filters map[string]any) ManifestEntry {
+	return ManifestEntry{
+		Hash:          item.Hash,
+		CreatedAt:     item.CreatedAt,
+		Filters:       filters,
+		Sensitivity:   item.Sensitivity,
+		AudienceGroup: item.AudienceGroup,
+	}
+}
+
 func (fs *MemoryFS) writeMemoryFile(ctx context.Context, botID string, item MemoryItem) error {
 	content := formatMemoryMD(item)
 	filePath := fmt.Sprintf("%s/%s.md", memoryDirPath, item.ID)
@@ -278,6 +298,12 @@ func formatMemoryMD(item MemoryItem) string {
 	if item.UpdatedAt != "" {
 		b.WriteString(fmt.Sprintf("updated_at: %s\n", item.UpdatedAt))
 	}
+	if item.Sensitivity != "" {
+		b.WriteString(fmt.Sprintf("sensitivity: %s\n", item.Sensitivity))
+	}
+	if item.AudienceGroup != "" {
+		b.WriteString(fmt.Sprintf("audience_group: %s\n", item.AudienceGroup))
+	}
 	b.WriteString("---\n")
 	b.WriteString(item.Memory)
 	b.WriteString("\n")
@@ -318,6 +344,10 @@ func parseMemoryMD(content string) (MemoryItem, error) {
 			item.CreatedAt = value
 		case "updated_at":
 			item.UpdatedAt = value
+		case "sensitivity":
+			item.Sensitivity = value
+		case "audience_group":
+			item.AudienceGroup = value
 		}
 	}
 	if item.ID == "" {
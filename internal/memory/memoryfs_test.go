@@ -0,0 +1,46 @@
+package memory
+
+import "testing"
+
+func TestMemoryMDRoundTripKeepsLabels(t *testing.T) {
+	t.Parallel()
+
+	item := MemoryItem{
+		ID:            "m1",
+		Memory:        "The team standup moved to 10am.",
+		Hash:          "abc",
+		CreatedAt:     "2026-01-02T03:04:05Z",
+		Sensitivity:   SensitivityGroup,
+		AudienceGroup: "telegram:-100123",
+	}
+	got, err := parseMemoryMD(formatMemoryMD(item))
+	if err != nil {
+		t.Fatalf("parseMemoryMD: %v", err)
+	}
+	if got.ID != item.ID || got.Memory != item.Memory || got.Hash != item.Hash {
+		t.Fatalf("round trip = %+v", got)
+	}
+	if got.Sensitivity != SensitivityGroup || got.AudienceGroup != "telegram:-100123" {
+		t.Fatalf("labels lost: sensitivity=%q audience_group=%q", got.Sensitivity, got.AudienceGroup)
+	}
+}
+
+func TestPersistableExcludesPrivateAndPII(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		item MemoryItem
+		want bool
+	}{
+		{MemoryItem{}, true},
+		{MemoryItem{Sensitivity: SensitivityPublic}, true},
+		{MemoryItem{Sensitivity: SensitivityGroup, AudienceGroup: "g"}, true},
+		{MemoryItem{Sensitivity: SensitivityPrivate, AudienceIdentity: "u"}, false},
+		{MemoryItem{Sensitivity: SensitivityGroup, PII: []string{"email"}}, false},
+	}
+	for _, tc := range cases {
+		if got := persistable(tc.item); got != tc.want {
+			t.Errorf("persistable(%+v) = %v, want %v", tc.item, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,628 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgVectorStore keeps memory points in Postgres. Payloads and BM25 sparse
+// vectors live in plain tables (memory_points, memory_sparse_terms); dense
+// vectors live in memory_point_vectors, which requires the pgvector extension.
+// Collections are a column rather than separate tables, so siblings are cheap;
+// points are keyed by (collection, id), so siblings may share point IDs.
+type PgVectorStore struct {
+	pool             *pgxpool.Pool
+	collection       string
+	sparseVectorName string
+	logger           *slog.Logger
+	// indexes records the dimensions whose HNSW index is known to exist. It
+	// is shared between siblings because the index covers all collections.
+	indexes *sync.Map
+	// iterativeScan is set when pgvector supports hnsw.iterative_scan
+	// (0.8.0+), which keeps filtered ANN searches from returning short lists.
+	// Ping refreshes it while searches read it, and siblings share it.
+	iterativeScan *atomic.Bool
+}
+
+// NewPgVectorStore creates a pgvector-backed store and verifies that the
+// migrated tables are present.
+func NewPgVectorStore(ctx context.Context, log *slog.Logger, pool *pgxpool.Pool, collection string) (*PgVectorStore, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("pgvector: postgres pool is required")
+	}
+	if strings.TrimSpace(collection) == "" {
+		collection = "memory"
+	}
+	store := &PgVectorStore{
+		pool:             pool,
+		collection:       collection,
+		sparseVectorName: sparseHashVectorName,
+		logger:           log.With(slog.String("store", "pgvector")),
+		indexes:          &sync.Map{},
+		iterativeScan:    &atomic.Bool{},
+	}
+	if err := store.Ping(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *PgVectorStore) NewSibling(collection string, _ int) (VectorStore, error) {
+	return &PgVectorStore{
+		pool:             s.pool,
+		collection:       collection,
+		sparseVectorName: s.sparseVectorName,
+		logger:           s.logger,
+		indexes:          s.indexes,
+		iterativeScan:    s.iterativeScan,
+	}, nil
+}
+
+func (s *PgVectorStore) Backend() string {
+	return VectorBackendPgVector
+}
+
+// UsesNamedVectors is always true: dense vectors are keyed by vector name
+// (the embedding model ID), so several models can coexist in one collection.
+func (s *PgVectorStore) UsesNamedVectors() bool {
+	return true
+}
+
+func (s *PgVectorStore) SparseVectorName() string {
+	return s.sparseVectorName
+}
+
+// Ping checks that the pgvector extension is installed and that the memory
+// tables have been created by the migrations.
+func (s *PgVectorStore) Ping(ctx context.Context) error {
+	var version string
+	err := s.pool.QueryRow(ctx, `SELECT extversion FROM pg_extension WHERE extname = 'vector'`).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("pgvector extension is not installed")
+	}
+	if err != nil {
+		return fmt.Errorf("check pgvector extension: %w", err)
+	}
+	var exists bool
+	if err := s.pool.QueryRow(ctx, `SELECT to_regclass('memory_point_vectors') IS NOT NULL`).Scan(&exists); err != nil {
+		return fmt.Errorf("check memory tables: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("memory_point_vectors table does not exist; run database migrations")
+	}
+	s.iterativeScan.Store(pgVectorAtLeast(version, 0, 8))
+	return nil
+}
+
+func (s *PgVectorStore) Upsert(ctx context.Context, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	s.ensureVectorIndexes(ctx, points)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, point := range points {
+		if len(point.Vector) == 0 && len(point.SparseIndices) == 0 {
+			return fmt.Errorf("no vector data provided for point %s", point.ID)
+		}
+		payload, err := json.Marshal(point.Payload)
+		if err != nil {
+			return err
+		}
+		indices := make([]int64, len(point.SparseIndices))
+		for i, idx := range point.SparseIndices {
+			indices[i] = int64(idx)
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO memory_points (id, collection, payload, sparse_indices, sparse_values)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (collection, id) DO UPDATE
+			   SET payload = $3, sparse_indices = $4, sparse_values = $5, updated_at = now()`,
+			point.ID, s.collection, payload, indices, point.SparseValues,
+		); err != nil {
+			return fmt.Errorf("upsert point: %w", err)
+		}
+		// Upsert replaces the whole point, matching Qdrant semantics.
+		if _, err := tx.Exec(ctx, `DELETE FROM memory_point_vectors WHERE collection = $1 AND point_id = $2`, s.collection, point.ID); err != nil {
+			return fmt.Errorf("clear point vectors: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM memory_sparse_terms WHERE collection = $1 AND point_id = $2`, s.collection, point.ID); err != nil {
+			return fmt.Errorf("clear sparse terms: %w", err)
+		}
+		if len(point.Vector) > 0 {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO memory_point_vectors (collection, point_id, vector_name, embedding) VALUES ($1, $2, $3, $4::vector)`,
+				s.collection, point.ID, point.VectorName, formatPgVector(point.Vector),
+			); err != nil {
+				return fmt.Errorf("insert point vector: %w", err)
+			}
+		}
+		if len(indices) > 0 && len(indices) == len(point.SparseValues) {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO memory_sparse_terms (collection, point_id, term, value)
+				 SELECT $1, $2, t.term, t.value FROM unnest($3::bigint[], $4::real[]) AS t(term, value)
+				 ON CONFLICT (collection, point_id, term) DO UPDATE SET value = EXCLUDED.value`,
+				s.collection, point.ID, indices, point.SparseValues,
+			); err != nil {
+				return fmt.Errorf("insert sparse terms: %w", err)
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *PgVectorStore) UpdateVectors(ctx context.Context, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	s.ensureVectorIndexes(ctx, points)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, point := range points {
+		if point.VectorName == "" || len(point.Vector) == 0 {
+			return fmt.Errorf("no named vector provided for point %s", point.ID)
+		}
+		tag, err := tx.Exec(ctx,
+			`INSERT INTO memory_point_vectors (collection, point_id, vector_name, embedding)
+			 SELECT collection, id, $3, $4::vector FROM memory_points WHERE collection = $1 AND id = $2
+			 ON CONFLICT (collection, point_id, vector_name) DO UPDATE SET embedding = EXCLUDED.embedding`,
+			s.collection, point.ID, point.VectorName, formatPgVector(point.Vector),
+		)
+		if err != nil {
+			return fmt.Errorf("update point vector: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("point %s not found", point.ID)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *PgVectorStore) Get(ctx context.Context, id string) (*VectorPoint, error) {
+	points, err := s.queryPoints(ctx,
+		`SELECT p.id::text, p.payload FROM memory_points p WHERE p.collection = $1 AND p.id = $2`,
+		false, s.collection, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+	return &points[0], nil
+}
+
+func (s *PgVectorStore) Delete(ctx context.Context, id string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM memory_points WHERE collection = $1 AND id = $2`, s.collection, id)
+	return err
+}
+
+func (s *PgVectorStore) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.pool.Exec(ctx, `DELETE FROM memory_points WHERE collection = $1 AND id::text = ANY($2::text[])`, s.collection, ids)
+	return err
+}
+
+func (s *PgVectorStore) DeleteAll(ctx context.Context, filters map[string]any) error {
+	where, args := buildPgPayloadFilter(filters, "p", []any{s.collection})
+	if where == "" {
+		return fmt.Errorf("delete all requires filters")
+	}
+	_, err := s.pool.Exec(ctx, `DELETE FROM memory_points p WHERE p.collection = $1`+where, args...)
+	return err
+}
+
+func (s *PgVectorStore) Count(ctx context.Context, filters map[string]any) (uint64, error) {
+	where, args := buildPgPayloadFilter(filters, "p", []any{s.collection})
+	var count int64
+	if err := s.pool.QueryRow(ctx, `SELECT count(*) FROM memory_points p WHERE p.collection = $1`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+func (s *PgVectorStore) List(ctx context.Context, limit int, filters map[string]any, withSparseVectors bool) ([]VectorPoint, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	where, args := buildPgPayloadFilter(filters, "p", []any{s.collection})
+	query := fmt.Sprintf(`SELECT p.id::text, p.payload%s FROM memory_points p
+		WHERE p.collection = $1%s ORDER BY p.created_at DESC LIMIT %d`,
+		sparseColumns(withSparseVectors), where, limit)
+	return s.queryPoints(ctx, query, withSparseVectors, args...)
+}
+
+func (s *PgVectorStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	where, args := buildPgPayloadFilter(filters, "p", []any{s.collection})
+	if offset != "" {
+		args = append(args, offset)
+		where += fmt.Sprintf(" AND p.id::text >= $%d", len(args))
+	}
+	// Fetch one extra row: its ID becomes the inclusive offset of the next page.
+	query := fmt.Sprintf(`SELECT p.id::text, p.payload FROM memory_points p
+		WHERE p.collection = $1%s ORDER BY p.id::text LIMIT %d`, where, limit+1)
+	points, err := s.queryPoints(ctx, query, false, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(points) > limit {
+		return points[:limit], points[limit].ID, nil
+	}
+	return points, "", nil
+}
+
+func (s *PgVectorStore) ScrollWithVectors(ctx context.Context, limit int, filters map[string]any, offset, vectorName string) ([]VectorPoint, string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	where, args := buildPgPayloadFilter(filters, "p", []any{s.collection, vectorName})
+	if offset != "" {
+		args = append(args, offset)
+		where += fmt.Sprintf(" AND p.id::text >= $%d", len(args))
+	}
+	query := fmt.Sprintf(`SELECT p.id::text, p.payload, v.embedding::text FROM memory_points p
+		LEFT JOIN memory_point_vectors v ON v.collection = p.collection AND v.point_id = p.id AND v.vector_name = $2
+		WHERE p.collection = $1%s ORDER BY p.id::text LIMIT %d`, where, limit+1)
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	points := make([]VectorPoint, 0, limit+1)
+	for rows.Next() {
+		var (
+			p       VectorPoint
+			vecText *string
+		)
+		if err := scanPgPoint(rows, &p, false, &vecText); err != nil {
+			return nil, "", err
+		}
+		if vecText != nil {
+			p.Vector = parsePgVector(*vecText)
+			p.VectorName = vectorName
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	if len(points) > limit {
+		return points[:limit], points[limit].ID, nil
+	}
+	return points, "", nil
+}
+
+func (s *PgVectorStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error) {
+	return s.SearchWithVectors(ctx, vector, limit, filters, vectorName, false)
+}
+
+func (s *PgVectorStore) SearchWithVectors(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string, withVectors bool) ([]VectorPoint, []float64, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(vector) == 0 {
+		return nil, nil, nil
+	}
+	args := []any{s.collection, formatPgVector(vector), vectorName}
+	where, args := buildPgPayloadFilter(filters, "p", args)
+	vectorColumn := ""
+	if withVectors {
+		vectorColumn = ", v.embedding::text"
+	}
+	// The distance is computed on embedding::vector(dim) under a
+	// vector_dims predicate so the planner can use the partial HNSW index
+	// for this dimension.
+	dim := len(vector)
+	query := fmt.Sprintf(`SELECT p.id::text, p.payload, 1 - (v.embedding::vector(%[1]d) <=> $2::vector(%[1]d)) AS score%[2]s
+		FROM memory_point_vectors v
+		JOIN memory_points p ON p.collection = v.collection AND p.id = v.point_id
+		WHERE v.collection = $1 AND v.vector_name = $3
+		  AND vector_dims(v.embedding) = %[1]d%[3]s
+		ORDER BY v.embedding::vector(%[1]d) <=> $2::vector(%[1]d)
+		LIMIT %[4]d`, dim, vectorColumn, where, limit)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if s.iterativeScan.Load() {
+		if _, err := tx.Exec(ctx, `SET LOCAL hnsw.iterative_scan = strict_order`); err != nil {
+			return nil, nil, fmt.Errorf("enable iterative scan: %w", err)
+		}
+	}
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	points := make([]VectorPoint, 0, limit)
+	scores := make([]float64, 0, limit)
+	for rows.Next() {
+		var (
+			p       VectorPoint
+			payload []byte
+			score   float64
+			vecText string
+		)
+		dest := []any{&p.ID, &payload, &score}
+		if withVectors {
+			dest = append(dest, &vecText)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(payload, &p.Payload); err != nil {
+			return nil, nil, fmt.Errorf("decode payload: %w", err)
+		}
+		if withVectors {
+			p.Vector = parsePgVector(vecText)
+		}
+		points = append(points, p)
+		scores = append(scores, score)
+	}
+	return points, scores, rows.Err()
+}
+
+// SearchSparse scores points by the dot product between the query's BM25
+// weights and each document's stored term weights, which is what Qdrant does
+// for sparse vectors without an IDF modifier.
+func (s *PgVectorStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, withSparseVectors bool) ([]VectorPoint, []float64, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(indices) == 0 || len(values) == 0 {
+		return nil, nil, nil
+	}
+	terms := make([]int64, len(indices))
+	for i, idx := range indices {
+		terms[i] = int64(idx)
+	}
+	args := []any{s.collection, terms, values}
+	where, args := buildPgPayloadFilter(filters, "p", args)
+	query := fmt.Sprintf(`SELECT p.id::text, p.payload%s, m.score
+		FROM (
+		  SELECT t.point_id, sum(t.value * q.value) AS score
+		  FROM memory_sparse_terms t
+		  JOIN unnest($2::bigint[], $3::real[]) AS q(term, value) ON q.term = t.term
+		  WHERE t.collection = $1
+		  GROUP BY t.point_id
+		) m
+		JOIN memory_points p ON p.collection = $1 AND p.id = m.point_id
+		WHERE p.collection = $1%s
+		ORDER BY m.score DESC
+		LIMIT %d`, sparseColumns(withSparseVectors), where, limit)
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	points := make([]VectorPoint, 0, limit)
+	scores := make([]float64, 0, limit)
+	for rows.Next() {
+		var (
+			score float64
+			p     VectorPoint
+		)
+		if err := scanPgPoint(rows, &p, withSparseVectors, &score); err != nil {
+			return nil, nil, err
+		}
+		points = append(points, p)
+		scores = append(scores, score)
+	}
+	return points, scores, rows.Err()
+}
+
+func (s *PgVectorStore) SearchBySources(ctx context.Context, vector []float32, limit int, filters map[string]any, sources []string, vectorName string) (map[string][]VectorPoint, map[string][]float64, error) {
+	return searchBySources(filters, sources, func(merged map[string]any) ([]VectorPoint, []float64, error) {
+		return s.Search(ctx, vector, limit, merged, vectorName)
+	})
+}
+
+func (s *PgVectorStore) SearchSparseBySources(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, sources []string, withSparseVectors bool) (map[string][]VectorPoint, map[string][]float64, error) {
+	return searchBySources(filters, sources, func(merged map[string]any) ([]VectorPoint, []float64, error) {
+		return s.SearchSparse(ctx, indices, values, limit, merged, withSparseVectors)
+	})
+}
+
+func (s *PgVectorStore) queryPoints(ctx context.Context, query string, withSparseVectors bool, args ...any) ([]VectorPoint, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	points := make([]VectorPoint, 0)
+	for rows.Next() {
+		var p VectorPoint
+		if err := scanPgPoint(rows, &p, withSparseVectors); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// scanPgPoint scans id, payload, optional sparse columns and any extra
+// trailing destinations (e.g. a score) into p.
+func scanPgPoint(rows pgx.Rows, p *VectorPoint, withSparseVectors bool, extra ...any) error {
+	var (
+		payload []byte
+		indices []int64
+		values  []float32
+	)
+	dest := []any{&p.ID, &payload}
+	if withSparseVectors {
+		dest = append(dest, &indices, &values)
+	}
+	dest = append(dest, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, &p.Payload); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	if withSparseVectors && len(indices) > 0 {
+		p.SparseIndices = make([]uint32, len(indices))
+		for i, idx := range indices {
+			p.SparseIndices[i] = uint32(idx)
+		}
+		p.SparseValues = values
+	}
+	return nil
+}
+
+// maxHNSWDimensions is the largest vector pgvector can index with HNSW.
+const maxHNSWDimensions = 2000
+
+// ensureVectorIndexes creates the partial HNSW index for each dense vector
+// dimension in points the first time this process sees it. Failures are
+// logged rather than returned: search still works, only without the index.
+func (s *PgVectorStore) ensureVectorIndexes(ctx context.Context, points []VectorPoint) {
+	for _, point := range points {
+		dim := len(point.Vector)
+		if dim == 0 || dim > maxHNSWDimensions {
+			continue
+		}
+		if _, done := s.indexes.Load(dim); done {
+			continue
+		}
+		query := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_memory_point_vectors_hnsw_%[1]d ON memory_point_vectors
+			USING hnsw ((embedding::vector(%[1]d)) vector_cosine_ops) WHERE vector_dims(embedding) = %[1]d`, dim)
+		if _, err := s.pool.Exec(ctx, query); err != nil {
+			s.logger.Warn("create hnsw index failed", slog.Int("dimensions", dim), slog.Any("error", err))
+			continue
+		}
+		s.indexes.Store(dim, struct{}{})
+	}
+}
+
+// pgVectorAtLeast reports whether an extversion string such as "0.8.0" is at
+// least major.minor.
+func pgVectorAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	gotMajor, err1 := strconv.Atoi(parts[0])
+	gotMinor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+func sparseColumns(withSparseVectors bool) string {
+	if withSparseVectors {
+		return ", p.sparse_indices, p.sparse_values"
+	}
+	return ""
+}
+
+// buildPgPayloadFilter translates the memory filter map (the same shape used
+// by buildQdrantFilter) into JSONB conditions on the payload column. args are
+// the already-bound parameters; the returned clause starts with " AND".
+func buildPgPayloadFilter(filters map[string]any, alias string, args []any) (string, []any) {
+	if len(filters) == 0 {
+		return "", args
+	}
+	var b strings.Builder
+	field := func(key string) string {
+		args = append(args, key)
+		return fmt.Sprintf("%s.payload ->> $%d", alias, len(args))
+	}
+	bind := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	for key, value := range filters {
+		switch typed := value.(type) {
+		case string:
+			col := field(key)
+			fmt.Fprintf(&b, " AND %s = %s", col, bind(typed))
+		case bool:
+			col := field(key)
+			fmt.Fprintf(&b, " AND %s = %s", col, bind(strconv.FormatBool(typed)))
+		case int, int64, float32, float64:
+			num, _ := toFloat(typed)
+			col := field(key)
+			fmt.Fprintf(&b, " AND (%s)::double precision = %s", col, bind(num))
+		case map[string]any:
+			ops := map[string]string{"gte": ">=", "gt": ">", "lte": "<=", "lt": "<"}
+			matched := false
+			for _, op := range []string{"gte", "gt", "lte", "lt"} {
+				raw, ok := typed[op]
+				if !ok {
+					continue
+				}
+				num, ok := toFloat(raw)
+				if !ok {
+					continue
+				}
+				col := field(key)
+				fmt.Fprintf(&b, " AND (%s)::double precision %s %s", col, ops[op], bind(num))
+				matched = true
+			}
+			if !matched {
+				col := field(key)
+				fmt.Fprintf(&b, " AND %s = %s", col, bind(fmt.Sprint(value)))
+			}
+		default:
+			col := field(key)
+			fmt.Fprintf(&b, " AND %s = %s", col, bind(fmt.Sprint(value)))
+		}
+	}
+	return b.String(), args
+}
+
+// formatPgVector renders a vector in pgvector's text input format: [1,2,3].
+func formatPgVector(vector []float32) string {
+	var b strings.Builder
+	b.Grow(len(vector) * 8)
+	b.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func parsePgVector(text string) []float32 {
+	text = strings.Trim(strings.TrimSpace(text), "[]")
+	if text == "" {
+		return nil
+	}
+	parts := strings.Split(text, ",")
+	vector := make([]float32, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil
+		}
+		vector = append(vector, float32(v))
+	}
+	return vector
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPgVectorTextRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	in := []float32{0.25, -1, 3.5}
+	text := formatPgVector(in)
+	if text != "[0.25,-1,3.5]" {
+		t.Fatalf("formatPgVector = %q", text)
+	}
+	if got := parsePgVector(text); !reflect.DeepEqual(got, in) {
+		t.Fatalf("parsePgVector = %v, want %v", got, in)
+	}
+	if got := parsePgVector("[]"); got != nil {
+		t.Fatalf("parsePgVector(empty) = %v, want nil", got)
+	}
+}
+
+func TestBuildPgPayloadFilter(t *testing.T) {
+	t.Parallel()
+
+	where, args := buildPgPayloadFilter(map[string]any{"bot_id": "bot-1"}, "p", []any{"memory"})
+	if where != " AND p.payload ->> $2 = $3" {
+		t.Fatalf("where = %q", where)
+	}
+	if !reflect.DeepEqual(args, []any{"memory", "bot_id", "bot-1"}) {
+		t.Fatalf("args = %v", args)
+	}
+
+	where, args = buildPgPayloadFilter(map[string]any{"ts": map[string]any{"gte": 10, "lt": 20}}, "p", nil)
+	if !strings.Contains(where, "::double precision >= $2") || !strings.Contains(where, "::double precision < $4") {
+		t.Fatalf("range where = %q", where)
+	}
+	if len(args) != 4 {
+		t.Fatalf("range args = %v", args)
+	}
+
+	if where, _ := buildPgPayloadFilter(nil, "p", nil); where != "" {
+		t.Fatalf("empty filter where = %q", where)
+	}
+}
+
+func TestPgVectorAtLeast(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"0.8.0":  true,
+		"0.10.1": true,
+		"1.0":    true,
+		"0.7.4":  false,
+		"0":      false,
+		"dev":    false,
+	}
+	for version, want := range cases {
+		if got := pgVectorAtLeast(version, 0, 8); got != want {
+			t.Errorf("pgVectorAtLeast(%q, 0, 8) = %v, want %v", version, got, want)
+		}
+	}
+}
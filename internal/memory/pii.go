@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PII kinds reported by DetectPII.
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICreditCard = "credit_card"
+	PIINationalID = "national_id"
+	PIIIBAN       = "iban"
+)
+
+var (
+	piiEmailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)
+	// Phone numbers: an optional +country code followed by 7-15 digits that
+	// may be grouped with spaces, dots, dashes or parentheses.
+	piiPhonePattern = regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?)?(?:\(\d{1,4}\)[\s.\-]?)?\d{2,4}(?:[\s.\-]?\d{2,4}){1,4}`)
+	piiCardPattern  = regexp.MustCompile(`\b(?:\d[ \-]?){13,19}\b`)
+	// US SSN and PRC resident identity card numbers.
+	piiSSNPattern   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	piiCNIDPattern  = regexp.MustCompile(`\b\d{17}[\dXx]\b`)
+	piiIBANPattern  = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?:\s?[A-Z0-9]{4}){2,7}(?:\s?[A-Z0-9]{1,4})?\b`)
+	piiDateLikeExpr = regexp.MustCompile(`^\d{4}[\-./]\d{1,2}[\-./]\d{1,2}$`)
+)
+
+// DetectPII returns the kinds of personal data found in text, in a stable
+// order. It is a cheap pattern pass meant to err on the side of flagging;
+// a hit only makes the memory private, it never drops it.
+func DetectPII(text string) []string {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	var kinds []string
+	if piiEmailPattern.MatchString(text) {
+		kinds = append(kinds, PIIEmail)
+	}
+	// Card and ID numbers are checked first so their digits are not also
+	// reported as phone numbers.
+	rest := text
+	for _, match := range piiCardPattern.FindAllString(text, -1) {
+		if luhnValid(digitsOnly(match)) {
+			kinds = append(kinds, PIICreditCard)
+			rest = strings.ReplaceAll(rest, match, " ")
+			break
+		}
+	}
+	if piiSSNPattern.MatchString(rest) || piiCNIDPattern.MatchString(rest) {
+		kinds = append(kinds, PIINationalID)
+		rest = piiSSNPattern.ReplaceAllString(rest, " ")
+		rest = piiCNIDPattern.ReplaceAllString(rest, " ")
+	}
+	if piiIBANPattern.MatchString(rest) {
+		kinds = append(kinds, PIIIBAN)
+		rest = piiIBANPattern.ReplaceAllString(rest, " ")
+	}
+	for _, match := range piiPhonePattern.FindAllString(rest, -1) {
+		if looksLikePhone(match) {
+			kinds = append(kinds, PIIPhone)
+			break
+		}
+	}
+	return kinds
+}
+
+func looksLikePhone(match string) bool {
+	match = strings.TrimSpace(match)
+	if piiDateLikeExpr.MatchString(match) {
+		return false
+	}
+	digits := digitsOnly(match)
+	if len(digits) < 7 || len(digits) > 15 {
+		return false
+	}
+	// Plain runs of digits without a country code or separators are more
+	// often amounts, years or IDs than phone numbers; require 10+ for them.
+	if !strings.ContainsAny(match, "+ -.()") && len(digits) < 10 {
+		return false
+	}
+	return true
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func luhnValid(digits string) bool {
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectPII(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "plain fact", text: "User prefers Go over Rust and has 2 cats", want: nil},
+		{name: "year and date", text: "User moved to Berlin in 2019, on 2019-04-01", want: nil},
+		{name: "email", text: "User's email is alice@example.com", want: []string{PIIEmail}},
+		{name: "phone", text: "Call me at +1 415-555-0132", want: []string{PIIPhone}},
+		{name: "cn mobile", text: "我的手机号是13812345678", want: []string{PIIPhone}},
+		{name: "credit card", text: "Card 4111 1111 1111 1111 expires soon", want: []string{PIICreditCard}},
+		{name: "card failing luhn", text: "Order 4111 1111 1111 1112 shipped", want: nil},
+		{name: "ssn", text: "SSN 123-45-6789", want: []string{PIINationalID}},
+		{name: "cn id", text: "身份证 11010519491231002X", want: []string{PIINationalID}},
+		{name: "iban", text: "IBAN DE89 3704 0044 0532 0130 00", want: []string{PIIIBAN}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectPII(tc.text); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("DetectPII(%q) = %v, want %v", tc.text, got, tc.want)
+			}
+		})
+	}
+}
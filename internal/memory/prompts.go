@@ -198,6 +198,38 @@ Rules:
 	return systemPrompt, userPrompt
 }
 
+func getRelationExtractionMessages(parsedMessages string) (string, string) {
+	systemPrompt := fmt.Sprintf(`You are a Knowledge Graph Builder. Your job is to extract relationships between named entities (people, organizations, projects, places, products) from a conversation.
+
+Output format: Return a JSON object with a "relations" key containing an array of objects, each with "subject", "predicate" and "object" string fields.
+
+Guidelines:
+1. Subjects and objects must be concrete entities mentioned in the conversation. Use the most complete name given (e.g. "Alice Chen" rather than "she").
+2. When the user talks about themselves, use "user" as the entity name.
+3. Predicates are short lowercase verb phrases in snake_case, e.g. "works_at", "manager_of", "member_of", "located_in", "owns", "reports_to".
+4. Prefer a single canonical direction: "Bob manager_of Alice" rather than also emitting "Alice reports_to Bob".
+5. Do not extract opinions, preferences, or relations that are only hypothetical.
+6. Keep entity names in the same language as the conversation. Do not translate.
+7. If there are no relations, return an empty array.
+
+Examples:
+
+Input: user: My manager Bob Li approved the Atlas launch. Alice is on the Atlas team too.
+Output: {"relations": [{"subject": "Bob Li", "predicate": "manager_of", "object": "user"}, {"subject": "Bob Li", "predicate": "approved", "object": "Atlas launch"}, {"subject": "Alice", "predicate": "member_of", "object": "Atlas team"}]}
+
+Input: user: Hi, how are you?
+Output: {"relations": []}
+
+Rules:
+- Today's date is %s.
+- Return ONLY valid JSON. No extra text or codeblocks.
+- DO NOT ADD "%s" OR "%s" IN THE JSON FIELDS.
+`, time.Now().UTC().Format("2006-01-02"), "```json", "```")
+
+	userPrompt := fmt.Sprintf("Extract entity relations from the following conversation:\n\nInput:\n%s", parsedMessages)
+	return systemPrompt, userPrompt
+}
+
 func removeCodeBlocks(text string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(text, "```json", ""), "```", "")
 }
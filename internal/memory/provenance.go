@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SetHistoryStore attaches an optional history store. When set, every edit
+// made by Add, Update, Delete, Compact and Import is appended to the memory's
+// edit log.
+func (s *Service) SetHistoryStore(h *HistoryStore) {
+	s.history = h
+}
+
+// History returns the edit log of one memory of a bot, oldest first.
+func (s *Service) History(ctx context.Context, botID, memoryID string, limit int) ([]HistoryEntry, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("memory history not configured")
+	}
+	if strings.TrimSpace(botID) == "" {
+		return nil, fmt.Errorf("bot_id is required")
+	}
+	if strings.TrimSpace(memoryID) == "" {
+		return nil, fmt.Errorf("memory_id is required")
+	}
+	return s.history.ListByMemory(ctx, botID, memoryID, limit)
+}
+
+// recordHistory appends an edit to the log. Failures are logged and never
+// fail the write itself: losing a history row is better than losing a memory.
+func (s *Service) recordHistory(ctx context.Context, memoryID string, payload map[string]any, event, source, oldText, newText string, prov *Provenance) {
+	if s.history == nil {
+		return
+	}
+	entry := HistoryEntry{
+		MemoryID:  memoryID,
+		BotID:     botIDFromPayload(payload),
+		Event:     event,
+		Source:    source,
+		OldMemory: oldText,
+		NewMemory: newText,
+	}
+	if prov != nil {
+		entry.Provenance = *prov
+	}
+	if err := s.history.Record(ctx, entry); err != nil {
+		s.logger.Warn("memory history record failed",
+			slog.String("memory_id", memoryID),
+			slog.String("event", event),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// historySource is the source of an edit written by Add: a conversation round
+// when it carries provenance, otherwise a direct API call.
+func historySource(prov *Provenance) string {
+	if prov != nil {
+		return HistorySourceConversation
+	}
+	return HistorySourceManual
+}
+
+// provenanceForEvent returns a copy of base stamped with the decision event
+// and the extractor model. It returns nil when the request carried no
+// provenance, so memories written outside a conversation stay unstamped.
+func provenanceForEvent(base *Provenance, extractorModel, event string) *Provenance {
+	if base == nil {
+		return nil
+	}
+	prov := *base
+	prov.MessageIDs = append([]string(nil), base.MessageIDs...)
+	if model := strings.TrimSpace(extractorModel); model != "" {
+		prov.ExtractorModel = model
+	}
+	prov.Event = strings.ToUpper(event)
+	return &prov
+}
+
+func provenanceToPayload(prov *Provenance) map[string]any {
+	out := map[string]any{}
+	if len(prov.MessageIDs) > 0 {
+		ids := make([]any, 0, len(prov.MessageIDs))
+		for _, id := range prov.MessageIDs {
+			ids = append(ids, id)
+		}
+		out["message_ids"] = ids
+	}
+	for key, value := range map[string]string{
+		"external_message_id": prov.ExternalMessageID,
+		"chat_id":             prov.ChatID,
+		"trace_id":            prov.TraceID,
+		"channel":             prov.Channel,
+		"extractor_model":     prov.ExtractorModel,
+		"event":               prov.Event,
+	} {
+		if value != "" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// provenanceFromPayload reads the provenance stored on a point. Payload lists
+// come back from the vector store as []any.
+func provenanceFromPayload(raw any) *Provenance {
+	values, ok := raw.(map[string]any)
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	str := func(key string) string {
+		v, _ := values[key].(string)
+		return v
+	}
+	prov := &Provenance{
+		ExternalMessageID: str("external_message_id"),
+		ChatID:            str("chat_id"),
+		TraceID:           str("trace_id"),
+		Channel:           str("channel"),
+		ExtractorModel:    str("extractor_model"),
+		Event:             str("event"),
+	}
+	switch ids := values["message_ids"].(type) {
+	case []string:
+		prov.MessageIDs = append(prov.MessageIDs, ids...)
+	case []any:
+		for _, id := range ids {
+			if s, ok := id.(string); ok && s != "" {
+				prov.MessageIDs = append(prov.MessageIDs, s)
+			}
+		}
+	}
+	return prov
+}
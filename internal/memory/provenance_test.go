@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProvenanceForEvent(t *testing.T) {
+	t.Parallel()
+
+	if got := provenanceForEvent(nil, "gpt-4o-mini", "ADD"); got != nil {
+		t.Fatalf("expected nil provenance without a base, got %+v", got)
+	}
+	base := &Provenance{MessageIDs: []string{"m1"}, ChatID: "chat-1", ExtractorModel: "fallback"}
+	got := provenanceForEvent(base, "gpt-4o-mini", "update")
+	if got.Event != "UPDATE" || got.ExtractorModel != "gpt-4o-mini" || got.ChatID != "chat-1" {
+		t.Fatalf("unexpected provenance: %+v", got)
+	}
+	got.MessageIDs[0] = "changed"
+	if base.MessageIDs[0] != "m1" {
+		t.Fatal("provenanceForEvent must not share the message id slice with its base")
+	}
+	if kept := provenanceForEvent(base, "", "ADD"); kept.ExtractorModel != "fallback" {
+		t.Fatalf("empty extractor model should keep the base one, got %q", kept.ExtractorModel)
+	}
+}
+
+func TestProvenancePayloadRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	prov := &Provenance{
+		MessageIDs:        []string{"m1", "m2"},
+		ExternalMessageID: "tg-42",
+		ChatID:            "chat-1",
+		TraceID:           "trace-1",
+		Channel:           "telegram",
+		ExtractorModel:    "gpt-4o-mini",
+		Event:             "ADD",
+	}
+	got := provenanceFromPayload(provenanceToPayload(prov))
+	if !reflect.DeepEqual(got, prov) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, prov)
+	}
+	if provenanceFromPayload(nil) != nil || provenanceFromPayload("x") != nil {
+		t.Fatal("expected nil for missing or malformed provenance")
+	}
+}
+
+func TestHistorySource(t *testing.T) {
+	t.Parallel()
+
+	if got := historySource(nil); got != HistorySourceManual {
+		t.Fatalf("historySource(nil) = %q, want %q", got, HistorySourceManual)
+	}
+	if got := historySource(&Provenance{ChatID: "chat-1"}); got != HistorySourceConversation {
+		t.Fatalf("historySource(prov) = %q, want %q", got, HistorySourceConversation)
+	}
+}
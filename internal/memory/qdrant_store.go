@@ -33,16 +33,6 @@ type QdrantStore struct {
 	usesSparseVectors bool
 }
 
-type qdrantPoint struct {
-	ID               string         `json:"id"`
-	Vector           []float32      `json:"vector"`
-	VectorName       string         `json:"vector_name,omitempty"`
-	SparseIndices    []uint32       `json:"sparse_indices,omitempty"`
-	SparseValues     []float32      `json:"sparse_values,omitempty"`
-	SparseVectorName string         `json:"sparse_vector_name,omitempty"`
-	Payload          map[string]any `json:"payload,omitempty"`
-}
-
 func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimension int, sparseVectorName string, timeout time.Duration) (*QdrantStore, error) {
 	host, port, useTLS, err := parseQdrantEndpoint(baseURL)
 	if err != nil {
@@ -89,10 +79,37 @@ func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimens
 	return store, nil
 }
 
-func (s *QdrantStore) NewSibling(collection string, dimension int) (*QdrantStore, error) {
+func (s *QdrantStore) NewSibling(collection string, dimension int) (VectorStore, error) {
 	return NewQdrantStore(s.logger, s.baseURL, s.apiKey, collection, dimension, s.sparseVectorName, s.timeout)
 }
 
+func (s *QdrantStore) Backend() string {
+	return VectorBackendQdrant
+}
+
+func (s *QdrantStore) UsesNamedVectors() bool {
+	return s.usesNamedVectors
+}
+
+func (s *QdrantStore) SparseVectorName() string {
+	return s.sparseVectorName
+}
+
+// Ping checks that the Qdrant server is reachable and the collection exists.
+func (s *QdrantStore) Ping(ctx context.Context) error {
+	if _, err := s.client.HealthCheck(ctx); err != nil {
+		return err
+	}
+	exists, err := s.client.CollectionExists(ctx, s.collection)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("collection %q does not exist", s.collection)
+	}
+	return nil
+}
+
 func NewQdrantStoreWithVectors(log *slog.Logger, baseURL, apiKey, collection string, vectors map[string]int, sparseVectorName string, timeout time.Duration) (*QdrantStore, error) {
 	host, port, useTLS, err := parseQdrantEndpoint(baseURL)
 	if err != nil {
@@ -140,7 +157,7 @@ func NewQdrantStoreWithVectors(log *slog.Logger, baseURL, apiKey, collection str
 	return store, nil
 }
 
-func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
+func (s *QdrantStore) Upsert(ctx context.Context, points []VectorPoint) error {
 	if len(points) == 0 {
 		return nil
 	}
@@ -191,13 +208,13 @@ func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
 	return err
 }
 
-func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]qdrantPoint, []float64, error) {
+func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error) {
 	return s.SearchWithVectors(ctx, vector, limit, filters, vectorName, false)
 }
 
 // SearchWithVectors is like Search but optionally returns the stored dense vectors
 // so callers can compute pairwise cosine similarity for MMR reranking.
-func (s *QdrantStore) SearchWithVectors(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string, withVectors bool) ([]qdrantPoint, []float64, error) {
+func (s *QdrantStore) SearchWithVectors(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string, withVectors bool) ([]VectorPoint, []float64, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -226,10 +243,10 @@ func (s *QdrantStore) SearchWithVectors(ctx context.Context, vector []float32, l
 		return nil, nil, err
 	}
 
-	points := make([]qdrantPoint, 0, len(results))
+	points := make([]VectorPoint, 0, len(results))
 	scores := make([]float64, 0, len(results))
 	for _, scored := range results {
-		p := qdrantPoint{
+		p := VectorPoint{
 			ID:      pointIDToString(scored.GetId()),
 			Payload: valueMapToInterface(scored.GetPayload()),
 		}
@@ -242,7 +259,7 @@ func (s *QdrantStore) SearchWithVectors(ctx context.Context, vector []float32, l
 	return points, scores, nil
 }
 
-func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, withSparseVectors bool) ([]qdrantPoint, []float64, error) {
+func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, withSparseVectors bool) ([]VectorPoint, []float64, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -269,10 +286,10 @@ func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values
 	if err != nil {
 		return nil, nil, err
 	}
-	points := make([]qdrantPoint, 0, len(results))
+	points := make([]VectorPoint, 0, len(results))
 	scores := make([]float64, 0, len(results))
 	for _, scored := range results {
-		p := qdrantPoint{
+		p := VectorPoint{
 			ID:      pointIDToString(scored.GetId()),
 			Payload: valueMapToInterface(scored.GetPayload()),
 		}
@@ -285,77 +302,90 @@ func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values
 	return points, scores, nil
 }
 
-func (s *QdrantStore) SearchBySources(ctx context.Context, vector []float32, limit int, filters map[string]any, sources []string, vectorName string) (map[string][]qdrantPoint, map[string][]float64, error) {
-	pointsBySource := make(map[string][]qdrantPoint, len(sources))
-	scoresBySource := make(map[string][]float64, len(sources))
-	if len(sources) == 0 {
-		return pointsBySource, scoresBySource, nil
-	}
+func (s *QdrantStore) SearchBySources(ctx context.Context, vector []float32, limit int, filters map[string]any, sources []string, vectorName string) (map[string][]VectorPoint, map[string][]float64, error) {
+	return searchBySources(filters, sources, func(merged map[string]any) ([]VectorPoint, []float64, error) {
+		return s.Search(ctx, vector, limit, merged, vectorName)
+	})
+}
+
+func (s *QdrantStore) SearchSparseBySources(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, sources []string, withSparseVectors bool) (map[string][]VectorPoint, map[string][]float64, error) {
+	return searchBySources(filters, sources, func(merged map[string]any) ([]VectorPoint, []float64, error) {
+		return s.SearchSparse(ctx, indices, values, limit, merged, withSparseVectors)
+	})
+}
 
-	type result struct {
-		source string
-		points []qdrantPoint
-		scores []float64
-		err    error
+func (s *QdrantStore) UpdateVectors(ctx context.Context, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
 	}
-	ch := make(chan result, len(sources))
-	for _, src := range sources {
-		go func(source string) {
-			merged := cloneFilters(filters)
-			if source != "" {
-				merged["source"] = source
-			}
-			pts, scs, err := s.Search(ctx, vector, limit, merged, vectorName)
-			ch <- result{source: source, points: pts, scores: scs, err: err}
-		}(src)
+	if !s.usesNamedVectors {
+		return fmt.Errorf("qdrant: collection %q does not use named vectors", s.collection)
 	}
-	for range sources {
-		r := <-ch
-		if r.err != nil {
-			return nil, nil, r.err
-		}
-		pointsBySource[r.source] = r.points
-		scoresBySource[r.source] = r.scores
+	updates := make([]*qdrant.PointVectors, 0, len(points))
+	for _, point := range points {
+		if point.VectorName == "" || len(point.Vector) == 0 {
+			return fmt.Errorf("no named vector provided for point %s", point.ID)
+		}
+		if dim, ok := s.vectorNames[point.VectorName]; !ok {
+			return fmt.Errorf("qdrant: collection %q has no named vector %q", s.collection, point.VectorName)
+		} else if dim != len(point.Vector) {
+			return fmt.Errorf("qdrant: vector %q expects %d dimensions, got %d", point.VectorName, dim, len(point.Vector))
+		}
+		updates = append(updates, &qdrant.PointVectors{
+			Id: qdrant.NewIDUUID(point.ID),
+			Vectors: qdrant.NewVectorsMap(map[string]*qdrant.Vector{
+				point.VectorName: qdrant.NewVectorDense(point.Vector),
+			}),
+		})
 	}
-	return pointsBySource, scoresBySource, nil
+	_, err := s.client.UpdateVectors(ctx, &qdrant.UpdatePointVectors{
+		CollectionName: s.collection,
+		Wait:           qdrant.PtrOf(true),
+		Points:         updates,
+	})
+	return err
 }
 
-func (s *QdrantStore) SearchSparseBySources(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, sources []string, withSparseVectors bool) (map[string][]qdrantPoint, map[string][]float64, error) {
-	pointsBySource := make(map[string][]qdrantPoint, len(sources))
-	scoresBySource := make(map[string][]float64, len(sources))
-	if len(sources) == 0 {
-		return pointsBySource, scoresBySource, nil
+// CheckNamedVector reads the collection schema and fails when it does not
+// declare name with dims dimensions. Qdrant cannot add a named vector to a
+// collection that already holds points, so such a migration has to go
+// through a new collection instead.
+func (s *QdrantStore) CheckNamedVector(ctx context.Context, name string, dims int) error {
+	if !s.usesNamedVectors {
+		return fmt.Errorf("qdrant: collection %q does not use named vectors", s.collection)
+	}
+	info, err := s.client.GetCollectionInfo(ctx, s.collection)
+	if err != nil {
+		return err
+	}
+	declared := map[string]int{}
+	for vectorName, params := range info.GetConfig().GetParams().GetVectorsConfig().GetParamsMap().GetMap() {
+		if params != nil {
+			declared[vectorName] = int(params.GetSize())
+		}
 	}
+	return checkDeclaredVector(s.collection, declared, info.GetPointsCount(), name, dims)
+}
 
-	type result struct {
-		source string
-		points []qdrantPoint
-		scores []float64
-		err    error
+// checkDeclaredVector is the schema check behind CheckNamedVector.
+func checkDeclaredVector(collection string, declared map[string]int, points uint64, name string, dims int) error {
+	existing, ok := declared[name]
+	if ok && (dims <= 0 || existing == dims) {
+		return nil
 	}
-	ch := make(chan result, len(sources))
-	for _, src := range sources {
-		go func(source string) {
-			merged := cloneFilters(filters)
-			if source != "" {
-				merged["source"] = source
-			}
-			pts, scs, err := s.SearchSparse(ctx, indices, values, limit, merged, withSparseVectors)
-			ch <- result{source: source, points: pts, scores: scs, err: err}
-		}(src)
+	if ok {
+		return fmt.Errorf("%w: qdrant collection %q declares %q with %d dimensions, the model returns %d",
+			ErrVectorNotDeclared, collection, name, existing, dims)
 	}
-	for range sources {
-		r := <-ch
-		if r.err != nil {
-			return nil, nil, r.err
-		}
-		pointsBySource[r.source] = r.points
-		scoresBySource[r.source] = r.scores
+	if points > 0 {
+		return fmt.Errorf("%w: qdrant collection %q holds %d points and was created without %q; "+
+			"qdrant cannot add named vectors to a populated collection, migrate it into a new collection first",
+			ErrVectorNotDeclared, collection, points, name)
 	}
-	return pointsBySource, scoresBySource, nil
+	return fmt.Errorf("%w: qdrant collection %q was created without %q", ErrVectorNotDeclared, collection, name)
 }
 
-func (s *QdrantStore) Get(ctx context.Context, id string) (*qdrantPoint, error) {
+func (s *QdrantStore) Get(ctx context.Context, id string) (*VectorPoint, error) {
 	result, err := s.client.Get(ctx, &qdrant.GetPoints{
 		CollectionName: s.collection,
 		Ids:            []*qdrant.PointId{qdrant.NewIDUUID(id)},
@@ -368,7 +398,7 @@ func (s *QdrantStore) Get(ctx context.Context, id string) (*qdrantPoint, error)
 		return nil, nil
 	}
 	point := result[0]
-	return &qdrantPoint{
+	return &VectorPoint{
 		ID:      pointIDToString(point.GetId()),
 		Payload: valueMapToInterface(point.GetPayload()),
 	}, nil
@@ -399,7 +429,7 @@ func (s *QdrantStore) DeleteBatch(ctx context.Context, ids []string) error {
 	return err
 }
 
-func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]any, withSparseVectors bool) ([]qdrantPoint, error) {
+func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]any, withSparseVectors bool) ([]VectorPoint, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -418,9 +448,9 @@ func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]an
 		return nil, err
 	}
 
-	result := make([]qdrantPoint, 0, len(points))
+	result := make([]VectorPoint, 0, len(points))
 	for _, point := range points {
-		p := qdrantPoint{
+		p := VectorPoint{
 			ID:      pointIDToString(point.GetId()),
 			Payload: valueMapToInterface(point.GetPayload()),
 		}
@@ -432,29 +462,56 @@ func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]an
 	return result, nil
 }
 
-func (s *QdrantStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset *qdrant.PointId) ([]qdrantPoint, *qdrant.PointId, error) {
+func (s *QdrantStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error) {
+	return s.scroll(ctx, limit, filters, offset, "", false)
+}
+
+func (s *QdrantStore) ScrollWithVectors(ctx context.Context, limit int, filters map[string]any, offset, vectorName string) ([]VectorPoint, string, error) {
+	return s.scroll(ctx, limit, filters, offset, vectorName, true)
+}
+
+func (s *QdrantStore) scroll(ctx context.Context, limit int, filters map[string]any, offset, vectorName string, withVectors bool) ([]VectorPoint, string, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 	filter := buildQdrantFilter(filters)
-	points, nextOffset, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+	var offsetID *qdrant.PointId
+	if offset != "" {
+		offsetID = qdrant.NewIDUUID(offset)
+	}
+	req := &qdrant.ScrollPoints{
 		CollectionName: s.collection,
 		Limit:          qdrant.PtrOf(uint32(limit)),
 		Filter:         filter,
-		Offset:         offset,
+		Offset:         offsetID,
 		WithPayload:    qdrant.NewWithPayload(true),
-	})
+	}
+	if withVectors {
+		if vectorName != "" && s.usesNamedVectors {
+			req.WithVectors = qdrant.NewWithVectorsInclude(vectorName)
+		} else {
+			req.WithVectors = qdrant.NewWithVectorsEnable(true)
+		}
+	}
+	points, nextOffset, err := s.client.ScrollAndOffset(ctx, req)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
-	result := make([]qdrantPoint, 0, len(points))
+	result := make([]VectorPoint, 0, len(points))
 	for _, point := range points {
-		result = append(result, qdrantPoint{
+		p := VectorPoint{
 			ID:      pointIDToString(point.GetId()),
 			Payload: valueMapToInterface(point.GetPayload()),
-		})
+		}
+		if withVectors {
+			p.Vector = extractDenseVector(point.GetVectors(), vectorName, s.usesNamedVectors)
+			if len(p.Vector) > 0 {
+				p.VectorName = vectorName
+			}
+		}
+		result = append(result, p)
 	}
-	return result, nextOffset, nil
+	return result, pointIDToString(nextOffset), nil
 }
 
 // extractSparseVector extracts sparse indices and values from a VectorsOutput.
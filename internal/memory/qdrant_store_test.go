@@ -1,6 +1,9 @@
 package memory
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestBuildQdrantFilter(t *testing.T) {
 	t.Parallel()
@@ -16,3 +19,24 @@ func TestBuildQdrantFilter(t *testing.T) {
 		t.Fatalf("expected two conditions, got %d", len(filter.Must))
 	}
 }
+
+func TestCheckDeclaredVector_PopulatedCollection(t *testing.T) {
+	t.Parallel()
+
+	declared := map[string]int{"bge-m3": 1024}
+	if err := checkDeclaredVector("memory", declared, 42, "bge-m3", 1024); err != nil {
+		t.Fatalf("declared vector rejected: %v", err)
+	}
+	if err := checkDeclaredVector("memory", declared, 42, "bge-m3", 0); err != nil {
+		t.Fatalf("declared vector with unknown dimensions rejected: %v", err)
+	}
+	if err := checkDeclaredVector("memory", declared, 42, "text-embedding-3-small", 1536); !errors.Is(err, ErrVectorNotDeclared) {
+		t.Fatalf("missing vector on populated collection: err = %v, want ErrVectorNotDeclared", err)
+	}
+	if err := checkDeclaredVector("memory", declared, 42, "bge-m3", 512); !errors.Is(err, ErrVectorNotDeclared) {
+		t.Fatalf("dimension mismatch: err = %v, want ErrVectorNotDeclared", err)
+	}
+	if err := checkDeclaredVector("memory", declared, 0, "bge-m3#512", 512); !errors.Is(err, ErrVectorNotDeclared) {
+		t.Fatalf("missing vector on empty collection: err = %v, want ErrVectorNotDeclared", err)
+	}
+}
@@ -0,0 +1,469 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/embeddings"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/processlog"
+)
+
+const reembedBatchSize = 64
+
+// SetReembedStore attaches the store used by embedding model migrations and
+// per-bot vector bindings.
+func (s *Service) SetReembedStore(r *ReembedStore) {
+	s.reembed = r
+}
+
+// SetProcessLog attaches the process log used to report migration progress.
+func (s *Service) SetProcessLog(p *processlog.Service) {
+	s.processLog = p
+}
+
+// LoadReembedState loads the active vector binding of every bot and resumes
+// jobs that were pending or running when the process last stopped.
+func (s *Service) LoadReembedState(ctx context.Context) error {
+	if s.reembed == nil {
+		return nil
+	}
+	bindings, err := s.reembed.Bindings(ctx)
+	if err != nil {
+		return err
+	}
+	s.reembedMu.Lock()
+	s.activeVectors = bindings
+	s.reembedMu.Unlock()
+
+	jobs, err := s.reembed.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		s.logger.Info("memory.reembed: resuming job",
+			slog.String("job_id", job.ID), slog.String("bot_id", job.BotID), slog.String("to_model", job.ToModel))
+		s.launchReembed(job)
+	}
+	return nil
+}
+
+// StartReembed starts a background job that re-embeds every text memory of a
+// bot with modelID into that model's named vector. Search keeps using the
+// current vector until the job completes, then switches atomically. Starting
+// a job for the bot's current model migrates it only when the model's
+// dimensions no longer match the stored vectors.
+func (s *Service) StartReembed(ctx context.Context, botID, modelID string) (ReembedJob, error) {
+	botID = strings.TrimSpace(botID)
+	modelID = strings.TrimSpace(modelID)
+	if botID == "" {
+		return ReembedJob{}, fmt.Errorf("bot_id is required")
+	}
+	if modelID == "" {
+		return ReembedJob{}, fmt.Errorf("model_id is required")
+	}
+	if s.reembed == nil {
+		return ReembedJob{}, fmt.Errorf("reembed store not configured")
+	}
+	if s.store == nil {
+		return ReembedJob{}, fmt.Errorf("vector store not configured")
+	}
+	if !s.store.UsesNamedVectors() {
+		return ReembedJob{}, fmt.Errorf("vector store does not use named vectors; embedding migration is unavailable")
+	}
+	if s.resolver == nil {
+		return ReembedJob{}, fmt.Errorf("embeddings resolver not configured")
+	}
+	from := s.textVectorName(botID)
+	storedDims := 0
+	if vectorModelID(from) == modelID {
+		storedDims = s.storedVectorDimensions(ctx, botID, from)
+		if storedDims == 0 {
+			return ReembedJob{}, ErrReembedNotNeeded
+		}
+	}
+	// Fail fast on unknown or non-text models before creating the job.
+	probe, err := s.resolver.Embed(ctx, embeddings.Request{
+		Type:  embeddings.TypeText,
+		Model: modelID,
+		Input: embeddings.Input{Text: "ping"},
+	})
+	if err != nil {
+		return ReembedJob{}, fmt.Errorf("embedding model %s: %w", modelID, err)
+	}
+	to, err := reembedTarget(from, modelID, storedDims, len(probe.Embedding))
+	if err != nil {
+		return ReembedJob{}, err
+	}
+	if checker, ok := s.store.(NamedVectorChecker); ok {
+		if err := checker.CheckNamedVector(ctx, to, len(probe.Embedding)); err != nil {
+			return ReembedJob{}, err
+		}
+	}
+	job, err := s.reembed.Create(ctx, botID, from, to)
+	if err != nil {
+		return ReembedJob{}, err
+	}
+	s.launchReembed(job)
+	return job, nil
+}
+
+// GetReembedJob returns a single migration job.
+func (s *Service) GetReembedJob(ctx context.Context, jobID string) (ReembedJob, error) {
+	if s.reembed == nil {
+		return ReembedJob{}, fmt.Errorf("reembed store not configured")
+	}
+	return s.reembed.Get(ctx, jobID)
+}
+
+// ListReembedJobs returns the most recent migration jobs of a bot.
+func (s *Service) ListReembedJobs(ctx context.Context, botID string, limit int) ([]ReembedJob, error) {
+	if s.reembed == nil {
+		return nil, fmt.Errorf("reembed store not configured")
+	}
+	return s.reembed.ListByBot(ctx, strings.TrimSpace(botID), limit)
+}
+
+// CancelReembed stops a running job. The bot keeps its current vector; vectors
+// already written for the target model are left in place and reused if the
+// migration is started again.
+func (s *Service) CancelReembed(ctx context.Context, jobID string) (ReembedJob, error) {
+	job, err := s.GetReembedJob(ctx, jobID)
+	if err != nil {
+		return ReembedJob{}, err
+	}
+	if job.Status != ReembedStatusPending && job.Status != ReembedStatusRunning {
+		return job, nil
+	}
+	s.reembedMu.Lock()
+	cancel := s.reembedCancels[job.ID]
+	s.reembedMu.Unlock()
+	if cancel != nil {
+		cancel()
+	} else if err := s.reembed.Finish(ctx, job.ID, ReembedStatusCancelled, ""); err != nil {
+		return ReembedJob{}, err
+	}
+	job.Status = ReembedStatusCancelled
+	return job, nil
+}
+
+func (s *Service) launchReembed(job ReembedJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.reembedMu.Lock()
+	if s.reembedCancels == nil {
+		s.reembedCancels = map[string]context.CancelFunc{}
+	}
+	if s.reembedTargets == nil {
+		s.reembedTargets = map[string]string{}
+	}
+	s.reembedCancels[job.ID] = cancel
+	s.reembedTargets[job.BotID] = job.ToModel
+	s.reembedMu.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			s.reembedMu.Lock()
+			delete(s.reembedCancels, job.ID)
+			if s.reembedTargets[job.BotID] == job.ToModel {
+				delete(s.reembedTargets, job.BotID)
+			}
+			s.reembedMu.Unlock()
+		}()
+		s.runReembed(ctx, job)
+	}()
+}
+
+func (s *Service) runReembed(ctx context.Context, job ReembedJob) {
+	start := time.Now()
+	filters := map[string]any{"bot_id": job.BotID}
+	// Resumed jobs were checked when they started, but the collection may
+	// have been recreated since.
+	if checker, ok := s.store.(NamedVectorChecker); ok {
+		if err := checker.CheckNamedVector(ctx, job.ToModel, 0); err != nil {
+			s.failReembed(job, err)
+			return
+		}
+	}
+	total, err := s.store.Count(ctx, filters)
+	if err != nil {
+		s.failReembed(job, fmt.Errorf("count memories: %w", err))
+		return
+	}
+	job.Total = int(total)
+	if err := s.reembed.MarkRunning(ctx, job.ID, job.Total); err != nil {
+		s.failReembed(job, err)
+		return
+	}
+	s.logReembed(job, processlog.StepMemoryReembedStarted, processlog.LevelInfo, "Embedding migration started", 0)
+
+	offset := job.cursor
+	for {
+		points, next, err := s.store.Scroll(ctx, reembedBatchSize, filters, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				s.cancelledReembed(job)
+				return
+			}
+			s.failReembed(job, fmt.Errorf("scroll memories: %w", err))
+			return
+		}
+		processed, failed, err := s.reembedBatch(ctx, job.ToModel, points)
+		if err != nil {
+			if ctx.Err() != nil {
+				s.cancelledReembed(job)
+				return
+			}
+			s.failReembed(job, err)
+			return
+		}
+		job.Processed += processed
+		job.Failed += failed
+		if err := s.reembed.SaveProgress(ctx, job.ID, job.Processed, job.Failed, next); err != nil {
+			s.logger.Warn("memory.reembed: save progress failed", slog.String("job_id", job.ID), slog.Any("error", err))
+		}
+		if next == "" {
+			break
+		}
+		offset = next
+	}
+
+	if job.Failed > 0 {
+		s.failReembed(job, fmt.Errorf("%d memories could not be re-embedded", job.Failed))
+		return
+	}
+	if err := s.reembed.Complete(context.Background(), job); err != nil {
+		s.failReembed(job, err)
+		return
+	}
+	s.reembedMu.Lock()
+	if s.activeVectors == nil {
+		s.activeVectors = map[string]string{}
+	}
+	s.activeVectors[job.BotID] = job.ToModel
+	s.reembedMu.Unlock()
+	job.Status = ReembedStatusCompleted
+	s.logger.Info("memory.reembed: completed",
+		slog.String("job_id", job.ID), slog.String("bot_id", job.BotID),
+		slog.String("to_model", job.ToModel), slog.Int("processed", job.Processed))
+	s.logReembed(job, processlog.StepMemoryReembedCompleted, processlog.LevelInfo, "Embedding migration completed", int(time.Since(start).Milliseconds()))
+}
+
+// reembedBatch writes the target vector for each text point. A failed batch
+// write is retried point by point so one bad record does not sink the rest;
+// points deleted since the scroll are skipped rather than counted as failures.
+func (s *Service) reembedBatch(ctx context.Context, modelID string, points []VectorPoint) (int, int, error) {
+	updates := make([]VectorPoint, 0, len(points))
+	processed, failed := 0, 0
+	for _, point := range points {
+		text := strings.TrimSpace(fmt.Sprint(point.Payload["data"]))
+		modality, _ := point.Payload["modality"].(string)
+		if text == "" || strings.EqualFold(modality, embeddings.TypeMultimodal) {
+			processed++
+			continue
+		}
+		vector, err := s.embedWithModel(ctx, modelID, text)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, 0, ctx.Err()
+			}
+			s.logger.Warn("memory.reembed: embed failed", slog.String("id", point.ID), slog.Any("error", err))
+			failed++
+			continue
+		}
+		updates = append(updates, VectorPoint{ID: point.ID, Vector: vector, VectorName: modelID})
+	}
+	if len(updates) == 0 {
+		return processed, failed, nil
+	}
+	if err := s.store.UpdateVectors(ctx, updates); err == nil {
+		return processed + len(updates), failed, nil
+	} else if ctx.Err() != nil {
+		return 0, 0, ctx.Err()
+	}
+	for _, update := range updates {
+		if err := s.store.UpdateVectors(ctx, []VectorPoint{update}); err != nil {
+			if existing, getErr := s.store.Get(ctx, update.ID); getErr == nil && existing == nil {
+				processed++
+				continue
+			}
+			s.logger.Warn("memory.reembed: update vector failed", slog.String("id", update.ID), slog.Any("error", err))
+			failed++
+			continue
+		}
+		processed++
+	}
+	return processed, failed, nil
+}
+
+func (s *Service) failReembed(job ReembedJob, err error) {
+	s.logger.Warn("memory.reembed: failed", slog.String("job_id", job.ID), slog.String("bot_id", job.BotID), slog.Any("error", err))
+	if finishErr := s.reembed.Finish(context.Background(), job.ID, ReembedStatusFailed, err.Error()); finishErr != nil {
+		s.logger.Warn("memory.reembed: mark failed", slog.String("job_id", job.ID), slog.Any("error", finishErr))
+	}
+	job.Status = ReembedStatusFailed
+	job.Error = err.Error()
+	s.logReembed(job, processlog.StepMemoryReembedFailed, processlog.LevelError, "Embedding migration failed", 0)
+}
+
+func (s *Service) cancelledReembed(job ReembedJob) {
+	if err := s.reembed.Finish(context.Background(), job.ID, ReembedStatusCancelled, ""); err != nil {
+		s.logger.Warn("memory.reembed: mark cancelled", slog.String("job_id", job.ID), slog.Any("error", err))
+	}
+	job.Status = ReembedStatusCancelled
+	s.logReembed(job, processlog.StepMemoryReembedFailed, processlog.LevelWarn, "Embedding migration cancelled", 0)
+}
+
+// logReembed writes a process log entry for the job. Migrations are not tied
+// to a conversation, so the bot ID doubles as chat ID and the job ID as trace.
+func (s *Service) logReembed(job ReembedJob, step processlog.ProcessLogStep, level processlog.ProcessLogLevel, message string, durationMs int) {
+	if s.processLog == nil {
+		return
+	}
+	data := map[string]any{
+		"job_id":     job.ID,
+		"status":     job.Status,
+		"from_model": job.FromModel,
+		"to_model":   job.ToModel,
+		"total":      job.Total,
+		"processed":  job.Processed,
+		"failed":     job.Failed,
+	}
+	if job.Error != "" {
+		data["error"] = job.Error
+	}
+	if _, err := s.processLog.Create(context.Background(), processlog.CreateProcessLogParams{
+		BotID:      job.BotID,
+		ChatID:     job.BotID,
+		TraceID:    job.ID,
+		Step:       step,
+		Level:      level,
+		Message:    message,
+		Data:       data,
+		DurationMs: durationMs,
+	}); err != nil {
+		s.logger.Debug("memory.reembed: process log failed", slog.Any("error", err))
+	}
+}
+
+// reembedDimensionSeparator joins a model ID and a dimension count in the
+// vector name written when a model's dimensions change: the old vectors keep
+// the bare model ID, so the new ones need a name of their own.
+const reembedDimensionSeparator = "#"
+
+// reembedTarget returns the named vector a migration from the vector from to
+// modelID writes into. Switching models targets the model's own vector; for
+// the current model a migration is only needed when storedDims, the size of
+// the bot's stored vectors, differs from dims, the size the model produces now.
+func reembedTarget(from, modelID string, storedDims, dims int) (string, error) {
+	if vectorModelID(from) != modelID {
+		return modelID, nil
+	}
+	if storedDims == 0 || dims == 0 || storedDims == dims {
+		return "", ErrReembedNotNeeded
+	}
+	return modelID + reembedDimensionSeparator + strconv.Itoa(dims), nil
+}
+
+// vectorModelID returns the embedding model that fills a named vector,
+// stripping the dimension suffix added by reembedTarget.
+func vectorModelID(name string) string {
+	if i := strings.LastIndex(name, reembedDimensionSeparator); i > 0 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			return name[:i]
+		}
+	}
+	return name
+}
+
+// storedVectorDimensions returns the size of the bot's vectors stored under
+// vectorName, or 0 when none are found.
+func (s *Service) storedVectorDimensions(ctx context.Context, botID, vectorName string) int {
+	points, _, err := s.store.ScrollWithVectors(ctx, reembedBatchSize, map[string]any{"bot_id": botID}, "", vectorName)
+	if err != nil {
+		s.logger.Warn("memory.reembed: read stored vectors failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return 0
+	}
+	for _, point := range points {
+		if len(point.Vector) > 0 {
+			return len(point.Vector)
+		}
+	}
+	return 0
+}
+
+// textVectorName returns the named vector holding a bot's text memories: the
+// model bound by its last completed migration, or the default text model.
+func (s *Service) textVectorName(botID string) string {
+	if s.store == nil || !s.store.UsesNamedVectors() {
+		return ""
+	}
+	s.reembedMu.RLock()
+	name := s.activeVectors[strings.TrimSpace(botID)]
+	s.reembedMu.RUnlock()
+	if name != "" {
+		return name
+	}
+	return s.vectorNameForText()
+}
+
+// embedTextForBot embeds text with the bot's active text model and returns
+// the vector together with the named vector it belongs to.
+func (s *Service) embedTextForBot(ctx context.Context, botID, text string) ([]float32, string, error) {
+	name := s.textVectorName(botID)
+	if name == "" || name == s.vectorNameForText() {
+		vector, err := s.embedText(ctx, text)
+		return vector, name, err
+	}
+	vector, err := s.embedWithModel(ctx, name, text)
+	return vector, name, err
+}
+
+// embedWithModel embeds text for the named vector vectorName.
+func (s *Service) embedWithModel(ctx context.Context, vectorName, text string) ([]float32, error) {
+	if vectorName == s.vectorNameForText() && s.embedder != nil {
+		return s.embedText(ctx, text)
+	}
+	if s.resolver == nil {
+		return nil, errors.New("embeddings resolver not configured")
+	}
+	result, err := s.resolver.Embed(ctx, embeddings.Request{
+		Type:  embeddings.TypeText,
+		Model: vectorModelID(vectorName),
+		Input: embeddings.Input{Text: text},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Embedding, nil
+}
+
+// mirrorReembedVector keeps a running migration consistent with concurrent
+// writes: a point written after the job scrolled past it also gets its target
+// vector. Failures are logged; the job's completion is not blocked on them.
+func (s *Service) mirrorReembedVector(ctx context.Context, botID, id, text string) {
+	s.reembedMu.RLock()
+	target := s.reembedTargets[strings.TrimSpace(botID)]
+	s.reembedMu.RUnlock()
+	if target == "" || target == s.textVectorName(botID) {
+		return
+	}
+	vector, err := s.embedWithModel(ctx, target, text)
+	if err == nil {
+		err = s.store.UpdateVectors(ctx, []VectorPoint{{ID: id, Vector: vector, VectorName: target}})
+	}
+	if err != nil {
+		s.logger.Warn("memory.reembed: mirror write failed", slog.String("bot_id", botID), slog.String("id", id), slog.Any("error", err))
+	}
+}
+
+func botIDFromPayload(payload map[string]any) string {
+	if v, ok := payload["bot_id"].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
@@ -0,0 +1,233 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ReembedStatusPending   = "pending"
+	ReembedStatusRunning   = "running"
+	ReembedStatusCompleted = "completed"
+	ReembedStatusFailed    = "failed"
+	ReembedStatusCancelled = "cancelled"
+)
+
+var (
+	ErrReembedInProgress = errors.New("an embedding migration is already in progress for this bot")
+	ErrReembedNotFound   = errors.New("embedding migration not found")
+	ErrReembedNotNeeded  = errors.New("bot memories already use this embedding model")
+	// ErrVectorNotDeclared is returned when the vector store cannot hold the
+	// migration's target vector, e.g. a populated Qdrant collection that was
+	// created without it.
+	ErrVectorNotDeclared = errors.New("vector store has no room for the target named vector")
+)
+
+// ReembedJob tracks a background migration of a bot's memories from one
+// embedding model (named vector) to another. ToModel is the model ID, suffixed
+// with "#<dimensions>" when the job moves a model to new dimensions.
+type ReembedJob struct {
+	ID         string `json:"id"`
+	BotID      string `json:"bot_id"`
+	FromModel  string `json:"from_model"`
+	ToModel    string `json:"to_model"`
+	Status     string `json:"status"`
+	Total      int    `json:"total"`
+	Processed  int    `json:"processed"`
+	Failed     int    `json:"failed"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+
+	// cursor is the scroll offset of the next batch; persisted so that an
+	// interrupted job resumes where it stopped.
+	cursor string
+}
+
+// ReembedStore persists embedding migration jobs and the active vector name
+// for each bot in PostgreSQL.
+type ReembedStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewReembedStore creates a store backed by memory_reembed_jobs and
+// memory_vector_bindings.
+func NewReembedStore(pool *pgxpool.Pool, log *slog.Logger) *ReembedStore {
+	return &ReembedStore{
+		pool:   pool,
+		logger: log.With(slog.String("component", "reembed_store")),
+	}
+}
+
+const reembedJobColumns = `id::text, bot_id, from_model, to_model, status, total, processed, failed,
+	cursor, error, started_at, finished_at, created_at, updated_at`
+
+// Create inserts a pending job. Only one pending or running job may exist per
+// bot; a second one returns ErrReembedInProgress.
+func (r *ReembedStore) Create(ctx context.Context, botID, fromModel, toModel string) (ReembedJob, error) {
+	row := r.pool.QueryRow(ctx,
+		`INSERT INTO memory_reembed_jobs (bot_id, from_model, to_model, status)
+		 VALUES ($1, $2, $3, 'pending')
+		 RETURNING `+reembedJobColumns,
+		botID, fromModel, toModel,
+	)
+	job, err := scanReembedJob(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ReembedJob{}, ErrReembedInProgress
+		}
+		return ReembedJob{}, fmt.Errorf("create reembed job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *ReembedStore) Get(ctx context.Context, id string) (ReembedJob, error) {
+	job, err := scanReembedJob(r.pool.QueryRow(ctx,
+		`SELECT `+reembedJobColumns+` FROM memory_reembed_jobs WHERE id::text = $1`, strings.TrimSpace(id)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ReembedJob{}, ErrReembedNotFound
+	}
+	if err != nil {
+		return ReembedJob{}, fmt.Errorf("get reembed job: %w", err)
+	}
+	return job, nil
+}
+
+// ListByBot returns the most recent jobs for a bot, newest first.
+func (r *ReembedStore) ListByBot(ctx context.Context, botID string, limit int) ([]ReembedJob, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	return r.list(ctx,
+		`SELECT `+reembedJobColumns+` FROM memory_reembed_jobs WHERE bot_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		botID, limit)
+}
+
+// ListActive returns every pending or running job, used to resume work after
+// a restart.
+func (r *ReembedStore) ListActive(ctx context.Context) ([]ReembedJob, error) {
+	return r.list(ctx,
+		`SELECT `+reembedJobColumns+` FROM memory_reembed_jobs WHERE status IN ('pending', 'running') ORDER BY created_at`)
+}
+
+func (r *ReembedStore) list(ctx context.Context, query string, args ...any) ([]ReembedJob, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list reembed jobs: %w", err)
+	}
+	defer rows.Close()
+	jobs := make([]ReembedJob, 0)
+	for rows.Next() {
+		job, err := scanReembedJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan reembed job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// MarkRunning records the total point count when a job (re)starts.
+func (r *ReembedStore) MarkRunning(ctx context.Context, id string, total int) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE memory_reembed_jobs
+		 SET status = 'running', total = $2, started_at = COALESCE(started_at, now()), updated_at = now()
+		 WHERE id::text = $1`,
+		id, total)
+	return err
+}
+
+// SaveProgress checkpoints the counters and the scroll cursor of the next batch.
+func (r *ReembedStore) SaveProgress(ctx context.Context, id string, processed, failed int, cursor string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE memory_reembed_jobs SET processed = $2, failed = $3, cursor = $4, updated_at = now() WHERE id::text = $1`,
+		id, processed, failed, cursor)
+	return err
+}
+
+// Finish moves a job to a terminal status without touching the active vector.
+func (r *ReembedStore) Finish(ctx context.Context, id, status, errMsg string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE memory_reembed_jobs SET status = $2, error = $3, finished_at = now(), updated_at = now() WHERE id::text = $1`,
+		id, status, errMsg)
+	return err
+}
+
+// Complete marks the job completed and binds the bot to the job's target
+// vector in one transaction, so readers never see a half-switched state.
+func (r *ReembedStore) Complete(ctx context.Context, job ReembedJob) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin reembed tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx,
+		`UPDATE memory_reembed_jobs
+		 SET status = 'completed', processed = $2, failed = $3, cursor = '', finished_at = now(), updated_at = now()
+		 WHERE id::text = $1`,
+		job.ID, job.Processed, job.Failed,
+	); err != nil {
+		return fmt.Errorf("complete reembed job: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memory_vector_bindings (bot_id, vector_name, updated_at)
+		 VALUES ($1, $2, now())
+		 ON CONFLICT (bot_id) DO UPDATE SET vector_name = EXCLUDED.vector_name, updated_at = now()`,
+		job.BotID, job.ToModel,
+	); err != nil {
+		return fmt.Errorf("bind vector: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// Bindings returns the active text vector name of every migrated bot.
+func (r *ReembedStore) Bindings(ctx context.Context) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT bot_id, vector_name FROM memory_vector_bindings`)
+	if err != nil {
+		return nil, fmt.Errorf("list vector bindings: %w", err)
+	}
+	defer rows.Close()
+	bindings := map[string]string{}
+	for rows.Next() {
+		var botID, vectorName string
+		if err := rows.Scan(&botID, &vectorName); err != nil {
+			return nil, fmt.Errorf("scan vector binding: %w", err)
+		}
+		bindings[botID] = vectorName
+	}
+	return bindings, rows.Err()
+}
+
+func scanReembedJob(row pgx.Row) (ReembedJob, error) {
+	var (
+		job                   ReembedJob
+		startedAt, finishedAt *time.Time
+		createdAt, updatedAt  time.Time
+	)
+	if err := row.Scan(&job.ID, &job.BotID, &job.FromModel, &job.ToModel, &job.Status,
+		&job.Total, &job.Processed, &job.Failed, &job.cursor, &job.Error,
+		&startedAt, &finishedAt, &createdAt, &updatedAt); err != nil {
+		return ReembedJob{}, err
+	}
+	if startedAt != nil {
+		job.StartedAt = startedAt.UTC().Format(time.RFC3339)
+	}
+	if finishedAt != nil {
+		job.FinishedAt = finishedAt.UTC().Format(time.RFC3339)
+	}
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	job.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return job, nil
+}
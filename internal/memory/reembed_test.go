@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+// namedVectorStore satisfies VectorStore for tests that only need the
+// named-vector flag; any other call panics on the nil embedded interface.
+type namedVectorStore struct {
+	VectorStore
+}
+
+func (namedVectorStore) UsesNamedVectors() bool { return true }
+
+func TestTextVectorName_UsesBindingOverDefault(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{
+		store:              namedVectorStore{},
+		defaultTextModelID: "text-embedding-3-small",
+		activeVectors:      map[string]string{"bot-a": "bge-m3"},
+	}
+	if got := s.textVectorName("bot-a"); got != "bge-m3" {
+		t.Fatalf("bound bot vector = %q, want bge-m3", got)
+	}
+	if got := s.textVectorName("bot-b"); got != "text-embedding-3-small" {
+		t.Fatalf("unbound bot vector = %q, want default model", got)
+	}
+}
+
+func TestBotIDFromPayload(t *testing.T) {
+	t.Parallel()
+
+	if got := botIDFromPayload(map[string]any{"bot_id": " bot-1 "}); got != "bot-1" {
+		t.Fatalf("botIDFromPayload = %q", got)
+	}
+	if got := botIDFromPayload(map[string]any{}); got != "" {
+		t.Fatalf("botIDFromPayload(empty) = %q", got)
+	}
+}
+
+// scrollVectorStore returns fixed points from ScrollWithVectors.
+type scrollVectorStore struct {
+	namedVectorStore
+	points []VectorPoint
+}
+
+func (s scrollVectorStore) ScrollWithVectors(context.Context, int, map[string]any, string, string) ([]VectorPoint, string, error) {
+	return s.points, "", nil
+}
+
+func TestReembedTarget_SameModelDimensionChange(t *testing.T) {
+	t.Parallel()
+
+	if got, err := reembedTarget("bge-m3", "text-embedding-3-small", 0, 1536); err != nil || got != "text-embedding-3-small" {
+		t.Fatalf("model switch = %q, %v", got, err)
+	}
+	if _, err := reembedTarget("bge-m3", "bge-m3", 1024, 1024); !errors.Is(err, ErrReembedNotNeeded) {
+		t.Fatalf("unchanged dimensions: err = %v, want ErrReembedNotNeeded", err)
+	}
+	got, err := reembedTarget("bge-m3", "bge-m3", 1024, 512)
+	if err != nil || got != "bge-m3#512" {
+		t.Fatalf("dimension change = %q, %v, want bge-m3#512", got, err)
+	}
+	if got, err := reembedTarget(got, "bge-m3", 512, 768); err != nil || got != "bge-m3#768" {
+		t.Fatalf("second dimension change = %q, %v, want bge-m3#768", got, err)
+	}
+	if got := vectorModelID("bge-m3#512"); got != "bge-m3" {
+		t.Fatalf("vectorModelID = %q, want bge-m3", got)
+	}
+	if got := vectorModelID("org/model#v2"); got != "org/model#v2" {
+		t.Fatalf("vectorModelID without dimension suffix = %q", got)
+	}
+}
+
+func TestStoredVectorDimensions(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{
+		logger: slog.New(slog.DiscardHandler),
+		store: scrollVectorStore{points: []VectorPoint{
+			{ID: "image"},
+			{ID: "text", Vector: make([]float32, 1024)},
+		}},
+	}
+	if got := s.storedVectorDimensions(context.Background(), "bot-a", "bge-m3"); got != 1024 {
+		t.Fatalf("storedVectorDimensions = %d, want 1024", got)
+	}
+	s.store = scrollVectorStore{}
+	if got := s.storedVectorDimensions(context.Background(), "bot-a", "bge-m3"); got != 0 {
+		t.Fatalf("storedVectorDimensions(empty) = %d, want 0", got)
+	}
+}
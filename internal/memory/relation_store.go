@@ -0,0 +1,289 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultRelationLimit = 20
+	maxRelationLimit     = 100
+	// minEntityMatchLen skips single-character entity names (e.g. "a") when
+	// matching entities against free text. Longer names such as "QA" are
+	// kept; word-boundary matching stops them hitting inside other words.
+	minEntityMatchLen = 2
+	// entityMatchCandidates bounds the substring candidates ForText checks
+	// for word boundaries; maxEntityMatches bounds the entities it keeps.
+	entityMatchCandidates = 200
+	maxEntityMatches      = 50
+)
+
+// RelationStore persists entity relation triples in PostgreSQL. Entities are
+// deduplicated per bot by their normalised name, so "Alice" and "alice " map
+// to the same node and repeated triples only bump the mention counter. Each
+// relation carries the sensitivity of the conversation it came from, so the
+// same triple learned in two audiences is stored once per audience.
+type RelationStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewRelationStore creates a relation store backed by the memory_entities and
+// memory_relations tables.
+func NewRelationStore(pool *pgxpool.Pool, log *slog.Logger) *RelationStore {
+	return &RelationStore{
+		pool:   pool,
+		logger: log.With(slog.String("component", "relation_store")),
+	}
+}
+
+// Upsert stores the triples for a bot with the sensitivity and audience fields
+// of labels and returns the number written.
+func (r *RelationStore) Upsert(ctx context.Context, botID string, triples []RelationTriple, labels Relation) (int, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return 0, fmt.Errorf("bot_id is required")
+	}
+	if len(triples) == 0 {
+		return 0, nil
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin relation tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	written := 0
+	for _, t := range triples {
+		subject := strings.TrimSpace(t.Subject)
+		object := strings.TrimSpace(t.Object)
+		predicate := normalizePredicate(t.Predicate)
+		if subject == "" || object == "" || predicate == "" {
+			continue
+		}
+		subjectID, err := upsertEntity(ctx, tx, botID, subject)
+		if err != nil {
+			return 0, err
+		}
+		objectID, err := upsertEntity(ctx, tx, botID, object)
+		if err != nil {
+			return 0, err
+		}
+		if subjectID == objectID {
+			continue
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO memory_relations (bot_id, subject_id, predicate, object_id, sensitivity, audience_group, audience_identity)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)
+			 ON CONFLICT (bot_id, subject_id, predicate, object_id, sensitivity, audience_group, audience_identity) DO UPDATE
+			   SET mentions = memory_relations.mentions + 1, updated_at = now()`,
+			botID, subjectID, predicate, objectID, labels.Sensitivity, labels.AudienceGroup, labels.AudienceIdentity,
+		); err != nil {
+			return 0, fmt.Errorf("upsert relation: %w", err)
+		}
+		written++
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit relation tx: %w", err)
+	}
+	return written, nil
+}
+
+func upsertEntity(ctx context.Context, tx pgx.Tx, botID, name string) (string, error) {
+	var id string
+	err := tx.QueryRow(ctx,
+		`INSERT INTO memory_entities (bot_id, name, normalized_name)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (bot_id, normalized_name) DO UPDATE SET updated_at = now()
+		 RETURNING id::text`,
+		botID, name, normalizeEntityName(name),
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("upsert entity: %w", err)
+	}
+	return id, nil
+}
+
+// ForText returns relations touching any entity whose name appears in one of
+// the given texts and visible to audience. Used to attach graph context to
+// search hits.
+func (r *RelationStore) ForText(ctx context.Context, botID string, texts []string, limit int, audience *Audience) ([]Relation, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" || len(texts) == 0 {
+		return nil, nil
+	}
+	haystack := normalizeEntityName(strings.Join(texts, "\n"))
+	if haystack == "" {
+		return nil, nil
+	}
+	// strpos narrows the candidates in the database; the word-boundary check
+	// runs here so "al" does not match inside "also".
+	rows, err := r.pool.Query(ctx,
+		`SELECT id::text, normalized_name FROM memory_entities
+		 WHERE bot_id = $1 AND length(normalized_name) >= $2 AND strpos($3, normalized_name) > 0
+		 ORDER BY length(normalized_name) DESC
+		 LIMIT $4`,
+		botID, minEntityMatchLen, haystack, entityMatchCandidates,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("match entities: %w", err)
+	}
+	var entityIDs []string
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan entities: %w", err)
+		}
+		if len(entityIDs) < maxEntityMatches && containsEntity(haystack, name) {
+			entityIDs = append(entityIDs, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan entities: %w", err)
+	}
+	if len(entityIDs) == 0 {
+		return nil, nil
+	}
+	return r.queryRelations(ctx,
+		`WHERE r.bot_id = $1 AND (r.subject_id::text = ANY($2::text[]) OR r.object_id::text = ANY($2::text[]))`,
+		clampRelationLimit(limit), audience, botID, entityIDs,
+	)
+}
+
+// Neighbors returns relations visible to audience where the named entity is
+// the subject or the object, optionally filtered by a predicate substring.
+func (r *RelationStore) Neighbors(ctx context.Context, botID, entity, predicate string, limit int, audience *Audience) ([]Relation, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return nil, fmt.Errorf("bot_id is required")
+	}
+	name := normalizeEntityName(entity)
+	if name == "" {
+		return nil, fmt.Errorf("entity is required")
+	}
+	predicate = normalizePredicate(predicate)
+	return r.queryRelations(ctx,
+		`WHERE r.bot_id = $1
+		   AND (s.normalized_name = $2 OR o.normalized_name = $2)
+		   AND ($3 = '' OR strpos(r.predicate, $3) > 0)`,
+		clampRelationLimit(limit), audience, botID, name, predicate,
+	)
+}
+
+// DeleteAll removes every entity and relation for a bot.
+func (r *RelationStore) DeleteAll(ctx context.Context, botID string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM memory_entities WHERE bot_id = $1`, strings.TrimSpace(botID)); err != nil {
+		return fmt.Errorf("delete relations: %w", err)
+	}
+	return nil
+}
+
+// queryRelations runs a relation query and filters it to audience. Filtered
+// queries overfetch so that hidden relations do not starve the result.
+func (r *RelationStore) queryRelations(ctx context.Context, where string, limit int, audience *Audience, args ...any) ([]Relation, error) {
+	fetch := limit
+	if audience != nil {
+		fetch = limit * audienceOverfetchRatio
+	}
+	query := `SELECT r.id::text, r.bot_id, s.name, r.predicate, o.name, r.mentions, r.created_at, r.updated_at,
+		       r.sensitivity, r.audience_group, r.audience_identity
+		FROM memory_relations r
+		JOIN memory_entities s ON s.id = r.subject_id
+		JOIN memory_entities o ON o.id = r.object_id
+		` + where + fmt.Sprintf(`
+		ORDER BY r.mentions DESC, r.updated_at DESC
+		LIMIT %d`, fetch)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query relations: %w", err)
+	}
+	defer rows.Close()
+	relations := make([]Relation, 0)
+	for rows.Next() {
+		var (
+			rel                  Relation
+			createdAt, updatedAt time.Time
+		)
+		if err := rows.Scan(&rel.ID, &rel.BotID, &rel.Subject, &rel.Predicate, &rel.Object, &rel.Mentions, &createdAt, &updatedAt,
+			&rel.Sensitivity, &rel.AudienceGroup, &rel.AudienceIdentity); err != nil {
+			return nil, fmt.Errorf("scan relation: %w", err)
+		}
+		rel.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		rel.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+		relations = append(relations, rel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return filterRelationsByAudience(relations, audience, limit), nil
+}
+
+func clampRelationLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRelationLimit
+	}
+	if limit > maxRelationLimit {
+		return maxRelationLimit
+	}
+	return limit
+}
+
+// normalizeEntityName lowercases and collapses whitespace so that entity
+// names differing only in case or spacing deduplicate to the same node.
+// containsEntity reports whether name occurs in text as a whole word. Scripts
+// written without spaces (Han, kana, Hangul) have no word boundaries, so a
+// name in or next to them matches as a plain substring.
+func containsEntity(text, name string) bool {
+	if name == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(name)
+	last, _ := utf8.DecodeLastRuneInString(name)
+	for offset := 0; offset < len(text); {
+		idx := strings.Index(text[offset:], name)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(name)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isSpacedWordRune(before) || !isSpacedWordRune(first)) &&
+			(end == len(text) || !isSpacedWordRune(after) || !isSpacedWordRune(last)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// isSpacedWordRune reports whether r is a word character of a script that
+// separates words with spaces.
+func isSpacedWordRune(r rune) bool {
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+		return false
+	}
+	return !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func normalizeEntityName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// normalizePredicate converts a predicate to lowercase snake_case.
+func normalizePredicate(predicate string) string {
+	fields := strings.FieldsFunc(strings.ToLower(predicate), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "_")
+}
@@ -0,0 +1,50 @@
+package memory
+
+import "testing"
+
+func TestNormalizeEntityName(t *testing.T) {
+	t.Parallel()
+
+	if got := normalizeEntityName("  Alice   Chen "); got != "alice chen" {
+		t.Fatalf("normalizeEntityName = %q", got)
+	}
+}
+
+func TestContainsEntity(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		text, name string
+		want       bool
+	}{
+		{"alice chen joined qa", "qa", true},
+		{"we also met", "al", false},
+		{"qa, then al.", "al", true},
+		{"aqa qab", "qa", false},
+		{"talk to bob's team", "bob", true},
+		{"我和张三去上海", "张三", true},
+		{"meet 张三tomorrow", "张三", true},
+		{"c++ and go", "c++", true},
+	}
+	for _, tc := range cases {
+		if got := containsEntity(tc.text, tc.name); got != tc.want {
+			t.Errorf("containsEntity(%q, %q) = %v, want %v", tc.text, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizePredicate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Manager of":  "manager_of",
+		"works-at":    "works_at",
+		"reports_to ": "reports_to",
+		"  ":          "",
+	}
+	for in, want := range cases {
+		if got := normalizePredicate(in); got != want {
+			t.Errorf("normalizePredicate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package memory
+
+import "strings"
+
+// Sensitivity labels. Memories without a label (written before labels
+// existed, or through the API without one) are treated as public.
+const (
+	SensitivityPublic  = "public"
+	SensitivityGroup   = "group"
+	SensitivityPrivate = "private"
+)
+
+// audienceOverfetchRatio widens searches that are filtered by audience so
+// that hidden hits do not starve the result list.
+const audienceOverfetchRatio = 3
+
+// Audience describes the conversation a memory is written from or recalled
+// into. GroupID is the platform conversation (platform + reply target) and
+// ChannelIdentityID the person speaking to the bot.
+type Audience struct {
+	ConversationType  string `json:"conversation_type,omitempty"`
+	GroupID           string `json:"group_id,omitempty"`
+	ChannelIdentityID string `json:"channel_identity_id,omitempty"`
+}
+
+// NewAudience builds an audience from the routing fields of a conversation.
+func NewAudience(conversationType, platform, replyTarget, channelIdentityID string) *Audience {
+	a := &Audience{
+		ConversationType:  strings.ToLower(strings.TrimSpace(conversationType)),
+		ChannelIdentityID: strings.TrimSpace(channelIdentityID),
+	}
+	if target := strings.TrimSpace(replyTarget); target != "" {
+		a.GroupID = strings.TrimSpace(platform) + ":" + target
+	}
+	return a
+}
+
+// IsGroup reports whether the conversation has more than one human in it.
+func (a *Audience) IsGroup() bool {
+	if a == nil {
+		return false
+	}
+	switch a.ConversationType {
+	case "", "p2p", "private", "direct":
+		return false
+	}
+	return true
+}
+
+// NormalizeSensitivity validates a label; empty stays empty.
+func NormalizeSensitivity(label string) (string, bool) {
+	switch label = strings.ToLower(strings.TrimSpace(label)); label {
+	case "", SensitivityPublic, SensitivityGroup, SensitivityPrivate:
+		return label, true
+	}
+	return "", false
+}
+
+// labelMemory decides the sensitivity of a memory and writes it to payload.
+// An explicit label wins; otherwise text containing PII is private to the
+// speaker, and everything else follows the conversation it was learned in:
+// group chats produce group-scoped memories, direct messages private ones.
+// Conversations without a type (the web UI, the API) produce public memories.
+// Labels that lack the identity or group they need fall back to the next
+// wider one that can be satisfied.
+func labelMemory(payload map[string]any, text, explicit string, audience *Audience) {
+	pii := DetectPII(text)
+	if len(pii) > 0 {
+		kinds := make([]any, 0, len(pii))
+		for _, kind := range pii {
+			kinds = append(kinds, kind)
+		}
+		payload["pii"] = kinds
+	} else {
+		delete(payload, "pii")
+	}
+
+	label, _ := NormalizeSensitivity(explicit)
+	if label == "" {
+		switch {
+		case len(pii) > 0:
+			label = SensitivityPrivate
+		case audience.IsGroup():
+			label = SensitivityGroup
+		case audience != nil && audience.ConversationType != "":
+			label = SensitivityPrivate
+		}
+	}
+	if existing, ok := payload["sensitivity"].(string); ok && explicit == "" && sensitivityRank(existing) >= sensitivityRank(label) {
+		// Never widen an existing memory implicitly: an update learned in a
+		// group must not expose what was told in private.
+		return
+	}
+
+	identity, group := "", ""
+	if audience != nil {
+		identity, group = audience.ChannelIdentityID, audience.GroupID
+	}
+	if label == SensitivityPrivate && identity == "" {
+		label = SensitivityGroup
+	}
+	if label == SensitivityGroup && group == "" {
+		if identity != "" && audience.IsGroup() {
+			label = SensitivityPrivate
+		} else {
+			label = SensitivityPublic
+		}
+	}
+
+	delete(payload, "audience_identity")
+	delete(payload, "audience_group")
+	switch label {
+	case SensitivityPrivate:
+		payload["audience_identity"] = identity
+	case SensitivityGroup:
+		payload["audience_group"] = group
+	case "":
+		delete(payload, "sensitivity")
+		return
+	}
+	payload["sensitivity"] = label
+}
+
+func sensitivityRank(label string) int {
+	switch label {
+	case SensitivityPrivate:
+		return 2
+	case SensitivityGroup:
+		return 1
+	}
+	return 0
+}
+
+// VisibleTo reports whether item may be recalled into the audience's
+// conversation. A nil audience means an unrestricted (owner/admin) read.
+func (item MemoryItem) VisibleTo(audience *Audience) bool {
+	return visibleTo(item.Sensitivity, item.AudienceGroup, item.AudienceIdentity, audience)
+}
+
+// VisibleTo reports whether rel may be recalled into the audience's
+// conversation, by the same rule as MemoryItem.VisibleTo.
+func (rel Relation) VisibleTo(audience *Audience) bool {
+	return visibleTo(rel.Sensitivity, rel.AudienceGroup, rel.AudienceIdentity, audience)
+}
+
+func visibleTo(sensitivity, group, identity string, audience *Audience) bool {
+	if audience == nil {
+		return true
+	}
+	switch sensitivity {
+	case SensitivityPrivate:
+		return !audience.IsGroup() && identity != "" && identity == audience.ChannelIdentityID
+	case SensitivityGroup:
+		return group != "" && group == audience.GroupID
+	}
+	return true
+}
+
+// relationLabels returns the sensitivity and audience fields for relations
+// extracted from text, decided by labelMemory exactly as for a memory.
+func relationLabels(text, explicit string, audience *Audience) Relation {
+	payload := map[string]any{}
+	labelMemory(payload, text, explicit, audience)
+	rel := Relation{}
+	rel.Sensitivity, _ = payload["sensitivity"].(string)
+	rel.AudienceGroup, _ = payload["audience_group"].(string)
+	rel.AudienceIdentity, _ = payload["audience_identity"].(string)
+	return rel
+}
+
+// filterRelationsByAudience drops relations the audience may not see, keeps
+// one relation per triple (the same fact may be stored once per audience)
+// and trims to limit.
+func filterRelationsByAudience(relations []Relation, audience *Audience, limit int) []Relation {
+	visible := make([]Relation, 0, len(relations))
+	seen := make(map[[3]string]struct{}, len(relations))
+	for _, rel := range relations {
+		if !rel.VisibleTo(audience) {
+			continue
+		}
+		key := [3]string{rel.Subject, rel.Predicate, rel.Object}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		visible = append(visible, rel)
+		if limit > 0 && len(visible) >= limit {
+			break
+		}
+	}
+	return visible
+}
+
+// filterByAudience drops items the audience may not see and trims to limit.
+func filterByAudience(items []MemoryItem, audience *Audience, limit int) []MemoryItem {
+	if audience == nil {
+		return items
+	}
+	visible := make([]MemoryItem, 0, len(items))
+	for _, item := range items {
+		if !item.VisibleTo(audience) {
+			continue
+		}
+		visible = append(visible, item)
+		if limit > 0 && len(visible) >= limit {
+			break
+		}
+	}
+	return visible
+}
@@ -0,0 +1,132 @@
+package memory
+
+import "testing"
+
+func TestLabelMemory(t *testing.T) {
+	t.Parallel()
+
+	group := NewAudience("group", "telegram", "-100123", "ident-alice")
+	dm := NewAudience("private", "telegram", "42", "ident-alice")
+
+	cases := []struct {
+		name         string
+		text         string
+		explicit     string
+		audience     *Audience
+		wantLabel    string
+		wantIdentity string
+		wantGroup    string
+	}{
+		{name: "api without audience", text: "User likes tea", wantLabel: ""},
+		{name: "web chat", text: "User likes tea", audience: NewAudience("", "", "", "ident-alice"), wantLabel: ""},
+		{name: "group chat", text: "Team ships on Fridays", audience: group, wantLabel: SensitivityGroup, wantGroup: "telegram:-100123"},
+		{name: "direct message", text: "User likes tea", audience: dm, wantLabel: SensitivityPrivate, wantIdentity: "ident-alice"},
+		{name: "pii in group", text: "Alice's email is alice@example.com", audience: group, wantLabel: SensitivityPrivate, wantIdentity: "ident-alice"},
+		{name: "explicit public wins over pii", text: "Support line is +1 415-555-0132", explicit: "public", audience: dm, wantLabel: SensitivityPublic},
+		{name: "private without identity falls back to the chat", text: "User likes tea", audience: NewAudience("private", "telegram", "42", ""), wantLabel: SensitivityGroup, wantGroup: "telegram:42"},
+		{name: "nothing to bind falls back to public", text: "User likes tea", audience: NewAudience("private", "", "", ""), wantLabel: SensitivityPublic},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := map[string]any{}
+			labelMemory(payload, tc.text, tc.explicit, tc.audience)
+			item := payloadToMemoryItem("m1", payload)
+			if item.Sensitivity != tc.wantLabel || item.AudienceIdentity != tc.wantIdentity || item.AudienceGroup != tc.wantGroup {
+				t.Fatalf("got label=%q identity=%q group=%q", item.Sensitivity, item.AudienceIdentity, item.AudienceGroup)
+			}
+		})
+	}
+}
+
+func TestLabelMemoryNeverWidensImplicitly(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{}
+	labelMemory(payload, "User likes tea", "", NewAudience("private", "telegram", "42", "ident-alice"))
+	labelMemory(payload, "User likes green tea", "", NewAudience("group", "telegram", "-100123", "ident-bob"))
+	item := payloadToMemoryItem("m1", payload)
+	if item.Sensitivity != SensitivityPrivate || item.AudienceIdentity != "ident-alice" {
+		t.Fatalf("update from a group widened a private memory: %+v", item)
+	}
+}
+
+func TestMemoryItemVisibleTo(t *testing.T) {
+	t.Parallel()
+
+	private := MemoryItem{Sensitivity: SensitivityPrivate, AudienceIdentity: "ident-alice"}
+	group := MemoryItem{Sensitivity: SensitivityGroup, AudienceGroup: "telegram:-100123"}
+	public := MemoryItem{}
+
+	aliceDM := NewAudience("private", "telegram", "42", "ident-alice")
+	bobDM := NewAudience("private", "telegram", "43", "ident-bob")
+	sameGroup := NewAudience("group", "telegram", "-100123", "ident-alice")
+	otherGroup := NewAudience("supergroup", "telegram", "-100999", "ident-alice")
+
+	checks := []struct {
+		name     string
+		item     MemoryItem
+		audience *Audience
+		want     bool
+	}{
+		{"owner read", private, nil, true},
+		{"private in own dm", private, aliceDM, true},
+		{"private in other dm", private, bobDM, false},
+		{"private in group", private, sameGroup, false},
+		{"group in same group", group, sameGroup, true},
+		{"group in other group", group, otherGroup, false},
+		{"group in dm", group, aliceDM, false},
+		{"public anywhere", public, otherGroup, true},
+	}
+	for _, c := range checks {
+		if got := c.item.VisibleTo(c.audience); got != c.want {
+			t.Errorf("%s: VisibleTo = %v, want %v", c.name, got, c.want)
+		}
+	}
+	if got := filterByAudience([]MemoryItem{private, group, public}, sameGroup, 1); len(got) != 1 || got[0].Sensitivity != SensitivityGroup {
+		t.Fatalf("filterByAudience = %+v", got)
+	}
+}
+
+func TestDMRelationHiddenFromGroup(t *testing.T) {
+	t.Parallel()
+
+	dm := NewAudience("private", "telegram", "42", "ident-alice")
+	group := NewAudience("group", "telegram", "-100123", "ident-alice")
+
+	dmLabels := relationLabels("Alice is interviewing at Acme", "", dm)
+	if dmLabels.Sensitivity != SensitivityPrivate || dmLabels.AudienceIdentity != "ident-alice" {
+		t.Fatalf("DM relation labels = %+v", dmLabels)
+	}
+	groupLabels := relationLabels("Bob manages Alice", "", group)
+
+	fromDM := dmLabels
+	fromDM.Subject, fromDM.Predicate, fromDM.Object = "Alice", "interviewing_at", "Acme"
+	fromGroup := groupLabels
+	fromGroup.Subject, fromGroup.Predicate, fromGroup.Object = "Bob", "manager_of", "Alice"
+	relations := []Relation{fromDM, fromGroup}
+
+	got := filterRelationsByAudience(relations, group, 10)
+	if len(got) != 1 || got[0].Predicate != "manager_of" {
+		t.Fatalf("group session relations = %+v, want only the group relation", got)
+	}
+	if got := filterRelationsByAudience(relations, dm, 10); len(got) != 1 || got[0].Predicate != "interviewing_at" {
+		t.Fatalf("DM session relations = %+v, want only the DM relation", got)
+	}
+	if got := filterRelationsByAudience(relations, nil, 10); len(got) != 2 {
+		t.Fatalf("unrestricted relations = %+v, want both", got)
+	}
+}
+
+func TestFilterRelationsByAudienceDedupesTriples(t *testing.T) {
+	t.Parallel()
+
+	relations := []Relation{
+		{Subject: "Bob", Predicate: "manager_of", Object: "Alice", Mentions: 3},
+		{Subject: "Bob", Predicate: "manager_of", Object: "Alice", Mentions: 1, Sensitivity: SensitivityGroup, AudienceGroup: "telegram:-100123"},
+		{Subject: "Bob", Predicate: "works_at", Object: "Acme"},
+	}
+	got := filterRelationsByAudience(relations, NewAudience("group", "telegram", "-100123", ""), 0)
+	if len(got) != 2 || got[0].Mentions != 3 {
+		t.Fatalf("deduped relations = %+v", got)
+	}
+}
@@ -9,27 +9,49 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/qdrant/go-client/qdrant"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/embeddings"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/processlog"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
+)
+
+var (
+	searchDuration = metrics.NewHistogram("memoh_memory_search_duration_seconds",
+		"Memory search latency by outcome (hit, miss, error).", nil, "outcome")
+	searchResults = metrics.NewCounter("memoh_memory_search_results_total",
+		"Memories returned by searches.")
 )
 
 type Service struct {
 	llm                      LLM
 	embedder                 embeddings.Embedder
-	store                    *QdrantStore
+	store                    VectorStore
 	resolver                 *embeddings.Resolver
 	bm25                     *BM25Indexer
 	logger                   *slog.Logger
 	defaultTextModelID       string
 	defaultMultimodalModelID string
 	embeddingCache           *EmbeddingCache
+	relations                *RelationStore
+	reembed                  *ReembedStore
+	history                  *HistoryStore
+	processLog               *processlog.Service
+	events                   event.Publisher
+
+	reembedMu      sync.RWMutex
+	activeVectors  map[string]string // bot ID -> text vector name bound by a completed migration
+	reembedTargets map[string]string // bot ID -> target vector of its running migration
+	reembedCancels map[string]context.CancelFunc
 }
 
-func NewService(log *slog.Logger, llm LLM, embedder embeddings.Embedder, store *QdrantStore, resolver *embeddings.Resolver, bm25 *BM25Indexer, defaultTextModelID, defaultMultimodalModelID string) *Service {
+func NewService(log *slog.Logger, llm LLM, embedder embeddings.Embedder, store VectorStore, resolver *embeddings.Resolver, bm25 *BM25Indexer, defaultTextModelID, defaultMultimodalModelID string) *Service {
 	return &Service{
 		llm:                      llm,
 		embedder:                 embedder,
@@ -48,6 +70,18 @@ func (s *Service) SetEmbeddingCache(c *EmbeddingCache) {
 	s.embeddingCache = c
 }
 
+// SetRelationStore attaches an optional relation store. When set, Add extracts
+// entity relation triples from each round and Search returns the relations
+// touching entities mentioned in the hits.
+func (s *Service) SetRelationStore(r *RelationStore) {
+	s.relations = r
+}
+
+// SetEventPublisher attaches the hub that receives memory_added events.
+func (s *Service) SetEventPublisher(p event.Publisher) {
+	s.events = p
+}
+
 // embedText wraps embedder.Embed with an optional cache lookup/store.
 func (s *Service) embedText(ctx context.Context, text string) ([]float32, error) {
 	if s.embeddingCache != nil {
@@ -67,7 +101,14 @@ func (s *Service) embedText(ctx context.Context, text string) ([]float32, error)
 	return vec, nil
 }
 
-func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, error) {
+func (s *Service) Add(ctx context.Context, req AddRequest) (resp SearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "memory.extract", attribute.String("bot.id", req.BotID))
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			s.publishAdded(req.BotID, resp.Results)
+		}
+	}()
 	if req.Message == "" && len(req.Messages) == 0 {
 		return SearchResponse{}, fmt.Errorf("message or messages is required")
 	}
@@ -84,9 +125,13 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 		slog.Int("message_count", len(messages)),
 	)
 
-	embeddingEnabled := req.EmbeddingEnabled != nil && *req.EmbeddingEnabled
+	embeddingEnabled := s.embeddingEnabled(req.EmbeddingEnabled)
+	sensitivity, ok := NormalizeSensitivity(req.Sensitivity)
+	if !ok {
+		return SearchResponse{}, fmt.Errorf("invalid sensitivity: %s", req.Sensitivity)
+	}
 	if req.Infer != nil && !*req.Infer {
-		return s.addRawMessages(ctx, messages, filters, req.Metadata, embeddingEnabled)
+		return s.addRawMessages(ctx, messages, filters, req.Metadata, embeddingEnabled, req.Provenance, req.Audience, sensitivity)
 	}
 
 	area := ""
@@ -103,6 +148,9 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 		s.logger.Warn("memory.Add: extract failed", slog.String("bot_id", req.BotID), slog.Any("error", err))
 		return SearchResponse{}, err
 	}
+	if area == "" {
+		s.storeRelations(ctx, req.BotID, messages, sensitivity, req.Audience)
+	}
 	if len(extractResp.Facts) == 0 {
 		s.logger.Info("memory.Add: no facts extracted", slog.String("bot_id", req.BotID))
 		return SearchResponse{Results: []MemoryItem{}}, nil
@@ -140,7 +188,8 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	for _, action := range actions {
 		switch strings.ToUpper(action.Event) {
 		case "ADD":
-			item, err := s.applyAdd(ctx, action.Text, filters, req.Metadata, embeddingEnabled)
+			prov := provenanceForEvent(req.Provenance, extractResp.Model, "ADD")
+			item, err := s.applyAdd(ctx, action.Text, filters, req.Metadata, embeddingEnabled, prov, req.Audience, sensitivity, historySource(prov))
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -149,7 +198,8 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 			})
 			results = append(results, item)
 		case "UPDATE":
-			item, err := s.applyUpdate(ctx, action.ID, action.Text, filters, req.Metadata, embeddingEnabled)
+			prov := provenanceForEvent(req.Provenance, extractResp.Model, "UPDATE")
+			item, err := s.applyUpdate(ctx, action.ID, action.Text, filters, req.Metadata, embeddingEnabled, prov, req.Audience, sensitivity)
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -159,7 +209,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 			})
 			results = append(results, item)
 		case "DELETE":
-			item, err := s.applyDelete(ctx, action.ID)
+			item, err := s.applyDelete(ctx, action.ID, provenanceForEvent(req.Provenance, extractResp.Model, "DELETE"))
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -180,12 +230,80 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	return SearchResponse{Results: results}, nil
 }
 
-func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse, error) {
+// publishAdded reports the memories an Add call created. Updates and deletes
+// are not published. Webhook receivers sit outside the audience checks, so
+// the text is only included for public memories without PII; the others are
+// reported by ID and sensitivity alone.
+func (s *Service) publishAdded(botID string, items []MemoryItem) {
+	if s.events == nil || botID == "" {
+		return
+	}
+	added := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		if ev, _ := item.Metadata["event"].(string); ev != "ADD" {
+			continue
+		}
+		sensitivity := item.Sensitivity
+		if sensitivity == "" {
+			sensitivity = SensitivityPublic
+		}
+		entry := map[string]string{"id": item.ID, "sensitivity": sensitivity}
+		if sensitivity == SensitivityPublic && len(item.PII) == 0 {
+			entry["memory"] = item.Memory
+		}
+		added = append(added, entry)
+	}
+	if len(added) == 0 {
+		return
+	}
+	s.events.Publish(event.NewEvent(event.EventTypeMemoryAdded, botID, map[string]any{
+		"memories": added,
+	}))
+}
+
+func (s *Service) Search(ctx context.Context, req SearchRequest) (_ SearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "memory.search", attribute.String("bot.id", req.BotID))
+	defer func() { tracing.End(span, err) }()
+	start := time.Now()
+	inner := req
+	if req.Audience != nil && req.Limit > 0 {
+		inner.Limit = req.Limit * audienceOverfetchRatio
+	}
+	resp, err := s.search(ctx, inner)
+	if err != nil {
+		searchDuration.ObserveSince(start, "error")
+		return resp, err
+	}
+	resp.Results = filterByAudience(resp.Results, req.Audience, req.Limit)
+	outcome := "hit"
+	if len(resp.Results) == 0 {
+		outcome = "miss"
+	}
+	searchDuration.ObserveSince(start, outcome)
+	searchResults.Add(float64(len(resp.Results)))
+	span.SetAttributes(attribute.Int("memory.results", len(resp.Results)))
+	if s.relations != nil && strings.TrimSpace(req.BotID) != "" && len(resp.Results) > 0 {
+		texts := make([]string, 0, len(resp.Results)+1)
+		texts = append(texts, req.Query)
+		for _, item := range resp.Results {
+			texts = append(texts, item.Memory)
+		}
+		relations, relErr := s.relations.ForText(ctx, req.BotID, texts, defaultRelationLimit, req.Audience)
+		if relErr != nil {
+			s.logger.Warn("memory.Search: relation lookup failed", slog.String("bot_id", req.BotID), slog.Any("error", relErr))
+		} else {
+			resp.Relations = relations
+		}
+	}
+	return resp, nil
+}
+
+func (s *Service) search(ctx context.Context, req SearchRequest) (SearchResponse, error) {
 	if strings.TrimSpace(req.Query) == "" {
 		return SearchResponse{}, fmt.Errorf("query is required")
 	}
 	if s.store == nil {
-		return SearchResponse{}, fmt.Errorf("qdrant store not configured")
+		return SearchResponse{}, fmt.Errorf("vector store not configured")
 	}
 	filters := buildSearchFilters(req)
 	s.logger.Debug("memory.Search", slog.String("query_prefix", truncateStr(req.Query, 80)), slog.String("bot_id", req.BotID))
@@ -193,7 +311,7 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 	if raw, ok := filters["modality"].(string); ok {
 		modality = strings.ToLower(strings.TrimSpace(raw))
 	}
-	embeddingEnabled := req.EmbeddingEnabled != nil && *req.EmbeddingEnabled
+	embeddingEnabled := s.embeddingEnabled(req.EmbeddingEnabled)
 	if modality == embeddings.TypeMultimodal {
 		if !embeddingEnabled {
 			return SearchResponse{}, fmt.Errorf("embedding is disabled")
@@ -234,15 +352,24 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 		return SearchResponse{Results: results}, nil
 	}
 
+	if s.useHybrid(req, embeddingEnabled) {
+		if req.EmbeddingEnabled == nil && req.FusionMethod == "" {
+			// Min-max and rank fusion give every query a top hit near the
+			// maximum; absolute fusion keeps callers' relevance thresholds
+			// meaningful.
+			req.FusionMethod = FusionAbsolute
+		}
+		return s.hybridSearch(ctx, req, filters)
+	}
+
 	if embeddingEnabled {
 		if s.embedder == nil {
 			return SearchResponse{}, fmt.Errorf("embedder not configured")
 		}
-		vector, err := s.embedText(ctx, req.Query)
+		vector, vectorName, err := s.embedTextForBot(ctx, req.BotID, req.Query)
 		if err != nil {
 			return SearchResponse{}, err
 		}
-		vectorName := s.vectorNameForText()
 
 		useMMR := req.UseMMR
 		overfetch := req.OverfetchRatio
@@ -328,9 +455,9 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 		return SearchResponse{}, err
 	}
 	// Build sparse vector lookup before fusion (fusion discards raw points).
-	var sparseByID map[string]qdrantPoint
+	var sparseByID map[string]VectorPoint
 	if wantStats {
-		sparseByID = make(map[string]qdrantPoint)
+		sparseByID = make(map[string]VectorPoint)
 		for _, pts := range pointsBySource {
 			for _, p := range pts {
 				if len(p.SparseIndices) > 0 {
@@ -379,11 +506,11 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	}
 
 	if s.store == nil {
-		return EmbedUpsertResponse{}, fmt.Errorf("qdrant store not configured")
+		return EmbedUpsertResponse{}, fmt.Errorf("vector store not configured")
 	}
 
 	vectorName := ""
-	if s.store.usesNamedVectors {
+	if s.store.UsesNamedVectors() {
 		vectorName = result.Model
 	}
 
@@ -393,7 +520,7 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	if metadata, ok := payload["metadata"].(map[string]any); ok && result.Model != "" {
 		metadata["model_id"] = result.Model
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{{
+	if err := s.store.Upsert(ctx, []VectorPoint{{
 		ID:         id,
 		Vector:     result.Embedding,
 		VectorName: vectorName,
@@ -419,7 +546,7 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 		return MemoryItem{}, fmt.Errorf("memory is required")
 	}
 	if s.store == nil {
-		return MemoryItem{}, fmt.Errorf("qdrant store not configured")
+		return MemoryItem{}, fmt.Errorf("vector store not configured")
 	}
 	if s.bm25 == nil {
 		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
@@ -466,29 +593,35 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 	payload["hash"] = hashMemory(req.Memory)
 	payload["updated_at"] = time.Now().UTC().Format(time.RFC3339)
 	payload["lang"] = newLang
+	// Re-run the PII pass; the label itself can only get stricter here.
+	labelMemory(payload, req.Memory, "", nil)
 
-	embeddingEnabled := req.EmbeddingEnabled != nil && *req.EmbeddingEnabled
-	point := qdrantPoint{
+	embeddingEnabled := s.embeddingEnabled(req.EmbeddingEnabled)
+	point := VectorPoint{
 		ID:               req.MemoryID,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
 	if embeddingEnabled {
 		if s.embedder == nil {
 			return MemoryItem{}, fmt.Errorf("embedder not configured")
 		}
-		vector, err := s.embedText(ctx, req.Memory)
+		vector, vectorName, err := s.embedTextForBot(ctx, botIDFromPayload(payload), req.Memory)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
+	if err := s.store.Upsert(ctx, []VectorPoint{point}); err != nil {
 		return MemoryItem{}, err
 	}
+	if embeddingEnabled {
+		s.mirrorReembedVector(ctx, botIDFromPayload(payload), req.MemoryID, req.Memory)
+	}
+	s.recordHistory(ctx, req.MemoryID, payload, "UPDATE", HistorySourceManual, oldText, req.Memory, nil)
 	return payloadToMemoryItem(req.MemoryID, payload), nil
 }
 
@@ -546,9 +679,17 @@ func (s *Service) Delete(ctx context.Context, memoryID string) (DeleteResponse,
 	if strings.TrimSpace(memoryID) == "" {
 		return DeleteResponse{}, fmt.Errorf("memory_id is required")
 	}
+	var existing *VectorPoint
+	if s.history != nil {
+		// Keep the last text in the log; a failed lookup only loses that.
+		existing, _ = s.store.Get(ctx, memoryID)
+	}
 	if err := s.store.Delete(ctx, memoryID); err != nil {
 		return DeleteResponse{}, err
 	}
+	if existing != nil {
+		s.recordHistory(ctx, memoryID, existing.Payload, "DELETE", HistorySourceManual, fmt.Sprint(existing.Payload["data"]), "", nil)
+	}
 	return DeleteResponse{Message: "Memory deleted successfully!"}, nil
 }
 
@@ -600,7 +741,7 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 		return CompactResult{}, fmt.Errorf("llm not configured")
 	}
 	if s.store == nil {
-		return CompactResult{}, fmt.Errorf("qdrant store not configured")
+		return CompactResult{}, fmt.Errorf("vector store not configured")
 	}
 	if ratio <= 0 || ratio > 1 {
 		ratio = 0.5
@@ -626,8 +767,42 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 		}, nil
 	}
 
+	// Compact each sensitivity partition on its own so that a merged fact
+	// never mixes private, group-scoped and public memories.
+	results := make([]MemoryItem, 0, beforeCount)
+	for _, part := range partitionBySensitivity(points) {
+		if len(part.points) <= 1 {
+			for _, p := range part.points {
+				results = append(results, payloadToMemoryItem(p.ID, p.Payload))
+			}
+			continue
+		}
+		partFilters := cloneFilters(filters)
+		for key, value := range part.labels {
+			partFilters[key] = value
+		}
+		items, err := s.compactPoints(ctx, partFilters, part.points, ratio, decayDays)
+		if err != nil {
+			return CompactResult{}, err
+		}
+		results = append(results, items...)
+	}
+
+	afterCount := len(results)
+	actualRatio := float64(afterCount) / float64(beforeCount)
+	return CompactResult{
+		BeforeCount: beforeCount,
+		AfterCount:  afterCount,
+		Ratio:       math.Round(actualRatio*100) / 100,
+		Results:     results,
+	}, nil
+}
+
+// compactPoints asks the LLM to consolidate points into about ratio×len
+// facts, writes them with filters and deletes the originals.
+func (s *Service) compactPoints(ctx context.Context, filters map[string]any, points []VectorPoint, ratio float64, decayDays int) ([]MemoryItem, error) {
 	// Build candidate list and compute target.
-	candidates := make([]CandidateMemory, 0, beforeCount)
+	candidates := make([]CandidateMemory, 0, len(points))
 	for _, p := range points {
 		candidates = append(candidates, CandidateMemory{
 			ID:        p.ID,
@@ -635,7 +810,7 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 			CreatedAt: fmt.Sprint(p.Payload["created_at"]),
 		})
 	}
-	targetCount := int(math.Round(float64(beforeCount) * ratio))
+	targetCount := int(math.Round(float64(len(points)) * ratio))
 	if targetCount < 1 {
 		targetCount = 1
 	}
@@ -647,10 +822,10 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 		DecayDays:   decayDays,
 	})
 	if err != nil {
-		return CompactResult{}, fmt.Errorf("compact llm call failed: %w", err)
+		return nil, fmt.Errorf("compact llm call failed: %w", err)
 	}
 	if len(compactResp.Facts) == 0 {
-		return CompactResult{}, fmt.Errorf("compact returned no facts")
+		return nil, fmt.Errorf("compact returned no facts")
 	}
 
 	// Add compacted facts first (before deleting old ones) to prevent data loss.
@@ -659,7 +834,7 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 		if strings.TrimSpace(fact) == "" {
 			continue
 		}
-		item, err := s.applyAdd(ctx, fact, filters, nil, false)
+		item, err := s.applyAdd(ctx, fact, filters, nil, false, nil, nil, "", HistorySourceCompaction)
 		if err != nil {
 			// Adding new facts failed — roll back by deleting any already-added new facts.
 			for _, added := range results {
@@ -667,7 +842,7 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 					s.logger.Warn("compact rollback: failed to delete new fact", slog.String("id", added.ID), slog.Any("error", delErr))
 				}
 			}
-			return CompactResult{}, fmt.Errorf("compact add failed: %w", err)
+			return nil, fmt.Errorf("compact add failed: %w", err)
 		}
 		results = append(results, item)
 	}
@@ -679,6 +854,10 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 	}
 	if err := s.store.DeleteBatch(ctx, oldIDs); err != nil {
 		s.logger.Warn("compact delete old failed, new facts already persisted", slog.Any("error", err))
+	} else {
+		for _, p := range points {
+			s.recordHistory(ctx, p.ID, p.Payload, "DELETE", HistorySourceCompaction, fmt.Sprint(p.Payload["data"]), "", nil)
+		}
 	}
 
 	// Reset BM25 stats for deleted documents.
@@ -697,14 +876,36 @@ func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio flo
 		}
 	}
 
-	afterCount := len(results)
-	actualRatio := float64(afterCount) / float64(beforeCount)
-	return CompactResult{
-		BeforeCount: beforeCount,
-		AfterCount:  afterCount,
-		Ratio:       math.Round(actualRatio*100) / 100,
-		Results:     results,
-	}, nil
+	return results, nil
+}
+
+type sensitivityPartition struct {
+	labels map[string]any
+	points []VectorPoint
+}
+
+// partitionBySensitivity groups points by their sensitivity label and the
+// group or identity it is bound to, in first-seen order.
+func partitionBySensitivity(points []VectorPoint) []sensitivityPartition {
+	index := map[string]int{}
+	partitions := make([]sensitivityPartition, 0, 1)
+	for _, p := range points {
+		labels := map[string]any{}
+		for _, key := range []string{"sensitivity", "audience_group", "audience_identity"} {
+			if v, ok := p.Payload[key].(string); ok && v != "" {
+				labels[key] = v
+			}
+		}
+		key := fmt.Sprint(labels["sensitivity"], "|", labels["audience_group"], "|", labels["audience_identity"])
+		i, ok := index[key]
+		if !ok {
+			i = len(partitions)
+			index[key] = i
+			partitions = append(partitions, sensitivityPartition{labels: labels})
+		}
+		partitions[i].points = append(partitions[i].points, p)
+	}
+	return partitions
 }
 
 const (
@@ -716,7 +917,7 @@ const (
 
 func (s *Service) Usage(ctx context.Context, filters map[string]any) (UsageResponse, error) {
 	if s.store == nil {
-		return UsageResponse{}, fmt.Errorf("qdrant store not configured")
+		return UsageResponse{}, fmt.Errorf("vector store not configured")
 	}
 	points, err := s.store.List(ctx, 0, filters, false)
 	if err != nil {
@@ -778,7 +979,7 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 		return nil
 	}
 	s.logger.Info("bm25 warmup starting")
-	var offset *qdrant.PointId
+	offset := ""
 	for {
 		points, next, err := s.store.Scroll(ctx, batchSize, nil, offset)
 		if err != nil {
@@ -803,7 +1004,7 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 			}
 			s.bm25.AddDocument(lang, termFreq, docLen)
 		}
-		if next == nil {
+		if next == "" {
 			break
 		}
 		offset = next
@@ -812,10 +1013,58 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 	return nil
 }
 
-func (s *Service) addRawMessages(ctx context.Context, messages []Message, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (SearchResponse, error) {
+// Relations returns the stored relations where entity is the subject or the
+// object, optionally narrowed to predicates containing predicate. Relations
+// the audience may not see are left out; a nil audience sees everything.
+func (s *Service) Relations(ctx context.Context, botID, entity, predicate string, limit int, audience *Audience) ([]Relation, error) {
+	if s.relations == nil {
+		return nil, fmt.Errorf("relation store not configured")
+	}
+	return s.relations.Neighbors(ctx, botID, entity, predicate, limit, audience)
+}
+
+// DeleteRelations removes every stored entity relation for a bot.
+func (s *Service) DeleteRelations(ctx context.Context, botID string) error {
+	if s.relations == nil {
+		return nil
+	}
+	return s.relations.DeleteAll(ctx, botID)
+}
+
+// storeRelations extracts entity triples from a conversation round and stores
+// them, labelled like a memory learned from the same round so that they are
+// only recalled where that memory would be. Failures are logged and never
+// fail the surrounding Add.
+func (s *Service) storeRelations(ctx context.Context, botID string, messages []Message, sensitivity string, audience *Audience) {
+	if s.relations == nil || strings.TrimSpace(botID) == "" || len(messages) == 0 {
+		return
+	}
+	resp, err := s.llm.ExtractRelations(ctx, ExtractRelationsRequest{Messages: messages})
+	if err != nil {
+		s.logger.Warn("memory.Add: relation extract failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return
+	}
+	if len(resp.Relations) == 0 {
+		return
+	}
+	texts := make([]string, 0, len(messages))
+	for _, message := range messages {
+		texts = append(texts, message.Content)
+	}
+	labels := relationLabels(strings.Join(texts, "\n"), sensitivity, audience)
+	written, err := s.relations.Upsert(ctx, botID, resp.Relations, labels)
+	if err != nil {
+		s.logger.Warn("memory.Add: relation store failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return
+	}
+	s.logger.Info("memory.Add: relations stored", slog.String("bot_id", botID), slog.Int("relations", written))
+}
+
+func (s *Service) addRawMessages(ctx context.Context, messages []Message, filters map[string]any, metadata map[string]any, embeddingEnabled bool, prov *Provenance, audience *Audience, sensitivity string) (SearchResponse, error) {
 	results := make([]MemoryItem, 0, len(messages))
 	for _, message := range messages {
-		item, err := s.applyAdd(ctx, message.Content, filters, metadata, embeddingEnabled)
+		addProv := provenanceForEvent(prov, "", "ADD")
+		item, err := s.applyAdd(ctx, message.Content, filters, metadata, embeddingEnabled, addProv, audience, sensitivity, historySource(addProv))
 		if err != nil {
 			return SearchResponse{}, err
 		}
@@ -842,6 +1091,12 @@ func (s *Service) collectCandidates(ctx context.Context, facts []string, filters
 			return nil, err
 		}
 		indices, values := s.bm25.BuildQueryVector(lang, termFreq)
+		if len(indices) == 0 {
+			continue
+		}
+		if s.store == nil {
+			return nil, fmt.Errorf("vector store not configured")
+		}
 		points, _, err := s.store.SearchSparse(ctx, indices, values, 5, filters, false)
 		if err != nil {
 			return nil, err
@@ -863,10 +1118,11 @@ func (s *Service) collectCandidates(ctx context.Context, facts []string, filters
 	return candidates, nil
 }
 
-func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (MemoryItem, error) {
+// applyAdd writes a new memory and records its ADD history under source.
+func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool, prov *Provenance, audience *Audience, sensitivity, source string) (MemoryItem, error) {
 	s.logger.Debug("memory.applyAdd", slog.String("text_prefix", truncateStr(text, 80)))
 	if s.store == nil {
-		return MemoryItem{}, fmt.Errorf("qdrant store not configured")
+		return MemoryItem{}, fmt.Errorf("vector store not configured")
 	}
 	if s.bm25 == nil {
 		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
@@ -885,24 +1141,28 @@ func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]
 	id := uuid.NewString()
 	payload := buildPayload(text, filters, metadata, "")
 	payload["lang"] = lang
-	point := qdrantPoint{
+	labelMemory(payload, text, sensitivity, audience)
+	if prov != nil {
+		payload["provenance"] = provenanceToPayload(prov)
+	}
+	point := VectorPoint{
 		ID:               id,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
 	if embeddingEnabled {
 		if s.embedder == nil {
 			return MemoryItem{}, fmt.Errorf("embedder not configured")
 		}
-		vector, err := s.embedText(ctx, text)
+		vector, vectorName, err := s.embedTextForBot(ctx, botIDFromPayload(payload), text)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		// Dedup guard: skip if a near-duplicate (cosine similarity ≥ 0.92) already exists.
 		if len(vector) > 0 {
-			existing, scores, searchErr := s.store.Search(ctx, vector, 1, filters, s.vectorNameForText())
+			existing, scores, searchErr := s.store.Search(ctx, vector, 1, filters, vectorName)
 			if searchErr == nil && len(existing) > 0 && len(scores) > 0 && scores[0] >= 0.92 {
 				s.logger.Debug("memory.applyAdd: skipping near-duplicate",
 					slog.String("existing_id", existing[0].ID),
@@ -913,20 +1173,26 @@ func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]
 			}
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
-		s.logger.Warn("memory.applyAdd: qdrant upsert failed", slog.Any("error", err))
+	if err := s.store.Upsert(ctx, []VectorPoint{point}); err != nil {
+		s.logger.Warn("memory.applyAdd: vector store upsert failed", slog.Any("error", err))
 		return MemoryItem{}, err
 	}
+	if embeddingEnabled {
+		s.mirrorReembedVector(ctx, botIDFromPayload(payload), id, text)
+	}
+	s.recordHistory(ctx, id, payload, "ADD", source, "", text, prov)
 	return payloadToMemoryItem(id, payload), nil
 }
 
 // RebuildAdd inserts a memory with a specific ID (from filesystem recovery).
 // Like applyAdd but preserves the given ID instead of generating a new UUID.
-func (s *Service) RebuildAdd(ctx context.Context, id, text string, filters map[string]any) (MemoryItem, error) {
+// sensitivity and audienceGroup are the labels recorded in the file, so a
+// group memory comes back bound to its group.
+func (s *Service) RebuildAdd(ctx context.Context, id, text string, filters map[string]any, sensitivity, audienceGroup string) (MemoryItem, error) {
 	if s.store == nil {
-		return MemoryItem{}, fmt.Errorf("qdrant store not configured")
+		return MemoryItem{}, fmt.Errorf("vector store not configured")
 	}
 	if s.bm25 == nil {
 		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
@@ -945,20 +1211,25 @@ func (s *Service) RebuildAdd(ctx context.Context, id, text string, filters map[s
 	sparseIndices, sparseValues := s.bm25.AddDocument(lang, termFreq, docLen)
 	payload := buildPayload(text, filters, nil, "")
 	payload["lang"] = lang
-	point := qdrantPoint{
+	audience := &Audience{GroupID: strings.TrimSpace(audienceGroup)}
+	if audience.GroupID != "" {
+		audience.ConversationType = "group"
+	}
+	labelMemory(payload, text, sensitivity, audience)
+	point := VectorPoint{
 		ID:               id,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
+	if err := s.store.Upsert(ctx, []VectorPoint{point}); err != nil {
 		return MemoryItem{}, err
 	}
 	return payloadToMemoryItem(id, payload), nil
 }
 
-func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (MemoryItem, error) {
+func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool, prov *Provenance, audience *Audience, sensitivity string) (MemoryItem, error) {
 	if strings.TrimSpace(id) == "" {
 		return MemoryItem{}, fmt.Errorf("update action missing id")
 	}
@@ -1007,31 +1278,39 @@ func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[
 	if filters != nil {
 		applyFiltersToPayload(payload, filters)
 	}
-	point := qdrantPoint{
+	labelMemory(payload, text, sensitivity, audience)
+	if prov != nil {
+		payload["provenance"] = provenanceToPayload(prov)
+	}
+	point := VectorPoint{
 		ID:               id,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
 	if embeddingEnabled {
 		if s.embedder == nil {
 			return MemoryItem{}, fmt.Errorf("embedder not configured")
 		}
-		vector, err := s.embedText(ctx, text)
+		vector, vectorName, err := s.embedTextForBot(ctx, botIDFromPayload(payload), text)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
+	if err := s.store.Upsert(ctx, []VectorPoint{point}); err != nil {
 		return MemoryItem{}, err
 	}
+	if embeddingEnabled {
+		s.mirrorReembedVector(ctx, botIDFromPayload(payload), id, text)
+	}
+	s.recordHistory(ctx, id, payload, "UPDATE", historySource(prov), oldText, text, prov)
 	return payloadToMemoryItem(id, payload), nil
 }
 
-func (s *Service) applyDelete(ctx context.Context, id string) (MemoryItem, error) {
+func (s *Service) applyDelete(ctx context.Context, id string, prov *Provenance) (MemoryItem, error) {
 	if strings.TrimSpace(id) == "" {
 		return MemoryItem{}, fmt.Errorf("delete action missing id")
 	}
@@ -1065,6 +1344,7 @@ func (s *Service) applyDelete(ctx context.Context, id string) (MemoryItem, error
 	if err := s.store.Delete(ctx, id); err != nil {
 		return MemoryItem{}, err
 	}
+	s.recordHistory(ctx, id, existing.Payload, "DELETE", historySource(prov), item.Memory, "", prov)
 	return item, nil
 }
 
@@ -1221,14 +1501,14 @@ func buildEmbeddingPayload(req EmbedUpsertRequest, filters map[string]any) map[s
 }
 
 func (s *Service) vectorNameForText() string {
-	if s.store == nil || !s.store.usesNamedVectors {
+	if s.store == nil || !s.store.UsesNamedVectors() {
 		return ""
 	}
 	return strings.TrimSpace(s.defaultTextModelID)
 }
 
 func (s *Service) vectorNameForMultimodal() string {
-	if s.store == nil || !s.store.usesNamedVectors {
+	if s.store == nil || !s.store.UsesNamedVectors() {
 		return ""
 	}
 	return strings.TrimSpace(s.defaultMultimodalModelID)
@@ -1279,6 +1559,23 @@ func payloadToMemoryItem(id string, payload map[string]any) MemoryItem {
 	if v, ok := payload["run_id"].(string); ok {
 		item.RunID = v
 	}
+	item.Provenance = provenanceFromPayload(payload["provenance"])
+	if v, ok := payload["sensitivity"].(string); ok {
+		item.Sensitivity = v
+	}
+	if v, ok := payload["audience_group"].(string); ok {
+		item.AudienceGroup = v
+	}
+	if v, ok := payload["audience_identity"].(string); ok {
+		item.AudienceIdentity = v
+	}
+	if kinds, ok := payload["pii"].([]any); ok {
+		for _, kind := range kinds {
+			if v, ok := kind.(string); ok {
+				item.PII = append(item.PII, v)
+			}
+		}
+	}
 	if meta, ok := payload["metadata"].(map[string]any); ok {
 		item.Metadata = meta
 	} else if payload["metadata"] == nil {
@@ -1374,7 +1671,7 @@ const (
 	rrfK = 60.0
 )
 
-func fuseByRankFusion(pointsBySource map[string][]qdrantPoint, _ map[string][]float64) []MemoryItem {
+func fuseByRankFusion(pointsBySource map[string][]VectorPoint, _ map[string][]float64) []MemoryItem {
 	candidates := map[string]*rerankCandidate{}
 	rrfScores := map[string]float64{}
 
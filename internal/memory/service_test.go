@@ -2,9 +2,12 @@ package memory
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"testing"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 )
 
 // MockLLM mocks LLM for tests.
@@ -12,6 +15,7 @@ type MockLLM struct {
 	ExtractFunc        func(ctx context.Context, req ExtractRequest) (ExtractResponse, error)
 	DecideFunc         func(ctx context.Context, req DecideRequest) (DecideResponse, error)
 	CompactFunc        func(ctx context.Context, req CompactRequest) (CompactResponse, error)
+	RelationsFunc      func(ctx context.Context, req ExtractRelationsRequest) (ExtractRelationsResponse, error)
 	DetectLanguageFunc func(ctx context.Context, text string) (string, error)
 }
 
@@ -27,6 +31,12 @@ func (m *MockLLM) Compact(ctx context.Context, req CompactRequest) (CompactRespo
 	}
 	return CompactResponse{}, fmt.Errorf("compact not mocked")
 }
+func (m *MockLLM) ExtractRelations(ctx context.Context, req ExtractRelationsRequest) (ExtractRelationsResponse, error) {
+	if m.RelationsFunc != nil {
+		return m.RelationsFunc(ctx, req)
+	}
+	return ExtractRelationsResponse{}, nil
+}
 func (m *MockLLM) DetectLanguage(ctx context.Context, text string) (string, error) {
 	return m.DetectLanguageFunc(ctx, text)
 }
@@ -98,11 +108,11 @@ func reflectContains(s, substr string) bool {
 }
 
 func TestRankFusion_Logic(t *testing.T) {
-	p1 := qdrantPoint{ID: "1", Payload: map[string]any{"data": "result 1"}}
-	p2 := qdrantPoint{ID: "2", Payload: map[string]any{"data": "result 2"}}
+	p1 := VectorPoint{ID: "1", Payload: map[string]any{"data": "result 1"}}
+	p2 := VectorPoint{ID: "2", Payload: map[string]any{"data": "result 2"}}
 
 	// Source A: 1 first, 2 second; Source B: 2 first, 1 second.
-	pointsBySource := map[string][]qdrantPoint{
+	pointsBySource := map[string][]VectorPoint{
 		"source_a": {p1, p2},
 		"source_b": {p2, p1},
 	}
@@ -121,3 +131,40 @@ func TestRankFusion_Logic(t *testing.T) {
 		// Symmetric case: both get same RRF score (e.g. 1/(k+1)+1/(k+2) for k=60).
 	}
 }
+
+type recordingPublisher struct {
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(evt event.Event) { p.events = append(p.events, evt) }
+
+func TestPublishAdded_OmitsNonPublicText(t *testing.T) {
+	pub := &recordingPublisher{}
+	s := &Service{events: pub}
+	added := map[string]any{"event": "ADD"}
+	s.publishAdded("bot-1", []MemoryItem{
+		{ID: "pub", Memory: "likes tea", Metadata: added},
+		{ID: "grp", Memory: "team offsite in May", Sensitivity: SensitivityGroup, Metadata: added},
+		{ID: "priv", Memory: "salary is 100k", Sensitivity: SensitivityPrivate, Metadata: added},
+		{ID: "pii", Memory: "mail me at a@b.co", PII: []string{"email"}, Metadata: added},
+		{ID: "upd", Memory: "updated", Metadata: map[string]any{"event": "UPDATE"}},
+	})
+	if len(pub.events) != 1 {
+		t.Fatalf("published %d events, want 1", len(pub.events))
+	}
+	var data struct {
+		Memories []map[string]string `json:"memories"`
+	}
+	if err := json.Unmarshal(pub.events[0].Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Memories) != 4 {
+		t.Fatalf("memories = %v, want the four added ones", data.Memories)
+	}
+	for _, m := range data.Memories {
+		_, hasText := m["memory"]
+		if hasText != (m["id"] == "pub") {
+			t.Errorf("memory %s: text included = %v", m["id"], hasText)
+		}
+	}
+}
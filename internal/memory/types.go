@@ -24,6 +24,7 @@ type LLM interface {
 	Extract(ctx context.Context, req ExtractRequest) (ExtractResponse, error)
 	Decide(ctx context.Context, req DecideRequest) (DecideResponse, error)
 	Compact(ctx context.Context, req CompactRequest) (CompactResponse, error)
+	ExtractRelations(ctx context.Context, req ExtractRelationsRequest) (ExtractRelationsResponse, error)
 	DetectLanguage(ctx context.Context, text string) (string, error)
 }
 
@@ -42,6 +43,26 @@ type AddRequest struct {
 	Filters          map[string]any `json:"filters,omitempty"`
 	Infer            *bool          `json:"infer,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
+	// Provenance identifies the conversation round the messages came from.
+	// It is stamped on every memory the round adds, updates or deletes.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// Audience is the conversation the messages came from; it decides the
+	// default sensitivity label. Sensitivity, when set, overrides it.
+	Audience    *Audience `json:"audience,omitempty"`
+	Sensitivity string    `json:"sensitivity,omitempty"`
+}
+
+// Provenance records where a memory came from: the persisted chat messages
+// the extractor read, the trace of that round, the model that extracted the
+// fact and the decision event that wrote it.
+type Provenance struct {
+	MessageIDs        []string `json:"message_ids,omitempty"`
+	ExternalMessageID string   `json:"external_message_id,omitempty"`
+	ChatID            string   `json:"chat_id,omitempty"`
+	TraceID           string   `json:"trace_id,omitempty"`
+	Channel           string   `json:"channel,omitempty"`
+	ExtractorModel    string   `json:"extractor_model,omitempty"`
+	Event             string   `json:"event,omitempty"`
 }
 
 type SearchRequest struct {
@@ -62,6 +83,18 @@ type SearchRequest struct {
 	// Temporal decay: reduce scores for older memories exponentially.
 	UseTemporalDecay  bool    `json:"use_temporal_decay,omitempty"`
 	DecayHalfLifeDays float64 `json:"decay_half_life_days,omitempty"` // default 30
+	// Hybrid retrieval: run dense and BM25 sparse search in parallel and fuse
+	// the two ranked lists. Requires EmbeddingEnabled. When EmbeddingEnabled
+	// is unset it is on by default wherever an embedder and a sparse index
+	// exist, fused by absolute score unless FusionMethod says otherwise.
+	// UseMMR reranks the fused list.
+	Hybrid       bool    `json:"hybrid,omitempty"`
+	FusionMethod string  `json:"fusion_method,omitempty"` // "rrf" (default), "convex" or "absolute"
+	DenseWeight  float64 `json:"dense_weight,omitempty"`  // default 0.5
+	SparseWeight float64 `json:"sparse_weight,omitempty"` // default 0.5
+	// Audience restricts results to memories visible in that conversation;
+	// nil returns everything (owner/admin reads).
+	Audience *Audience `json:"audience,omitempty"`
 }
 
 type UpdateRequest struct {
@@ -113,18 +146,25 @@ type EmbedUpsertResponse struct {
 }
 
 type MemoryItem struct {
-	ID          string         `json:"id"`
-	Memory      string         `json:"memory"`
-	Hash        string         `json:"hash,omitempty"`
-	CreatedAt   string         `json:"created_at,omitempty"`
-	UpdatedAt   string         `json:"updated_at,omitempty"`
-	Score       float64        `json:"score,omitempty"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
-	BotID       string         `json:"bot_id,omitempty"`
-	AgentID     string         `json:"agent_id,omitempty"`
-	RunID       string         `json:"run_id,omitempty"`
-	TopKBuckets []TopKBucket   `json:"top_k_buckets,omitempty"`
-	CDFCurve    []CDFPoint     `json:"cdf_curve,omitempty"`
+	ID         string         `json:"id"`
+	Memory     string         `json:"memory"`
+	Hash       string         `json:"hash,omitempty"`
+	CreatedAt  string         `json:"created_at,omitempty"`
+	UpdatedAt  string         `json:"updated_at,omitempty"`
+	Score      float64        `json:"score,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	BotID      string         `json:"bot_id,omitempty"`
+	AgentID    string         `json:"agent_id,omitempty"`
+	RunID      string         `json:"run_id,omitempty"`
+	Provenance *Provenance    `json:"provenance,omitempty"`
+	// Sensitivity is public (or empty), group or private. Group memories are
+	// bound to AudienceGroup, private ones to AudienceIdentity.
+	Sensitivity      string       `json:"sensitivity,omitempty"`
+	AudienceGroup    string       `json:"audience_group,omitempty"`
+	AudienceIdentity string       `json:"audience_identity,omitempty"`
+	PII              []string     `json:"pii,omitempty"`
+	TopKBuckets      []TopKBucket `json:"top_k_buckets,omitempty"`
+	CDFCurve         []CDFPoint   `json:"cdf_curve,omitempty"`
 }
 
 // TopKBucket represents one bar in the Top-K sparse dimension bar chart.
@@ -141,7 +181,7 @@ type CDFPoint struct {
 
 type SearchResponse struct {
 	Results   []MemoryItem `json:"results"`
-	Relations []any        `json:"relations,omitempty"`
+	Relations []Relation   `json:"relations,omitempty"`
 }
 
 type DeleteResponse struct {
@@ -157,6 +197,40 @@ type ExtractRequest struct {
 
 type ExtractResponse struct {
 	Facts []string `json:"facts"`
+	// Model is the model that produced the facts; filled in by the client.
+	Model string `json:"-"`
+}
+
+type ExtractRelationsRequest struct {
+	Messages []Message `json:"messages"`
+}
+
+type ExtractRelationsResponse struct {
+	Relations []RelationTriple `json:"relations"`
+}
+
+// RelationTriple is a (subject, predicate, object) edge as produced by the LLM.
+type RelationTriple struct {
+	Subject   string `json:"subject"`
+	Predicate string `json:"predicate"`
+	Object    string `json:"object"`
+}
+
+// Relation is a stored entity relation scoped to a bot.
+type Relation struct {
+	ID        string `json:"id"`
+	BotID     string `json:"bot_id"`
+	Subject   string `json:"subject"`
+	Predicate string `json:"predicate"`
+	Object    string `json:"object"`
+	Mentions  int    `json:"mentions"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// Sensitivity and the audience fields are inherited from the conversation
+	// the relation was extracted from, with the same meaning as on MemoryItem.
+	Sensitivity      string `json:"sensitivity,omitempty"`
+	AudienceGroup    string `json:"audience_group,omitempty"`
+	AudienceIdentity string `json:"audience_identity,omitempty"`
 }
 
 type CandidateMemory struct {
@@ -0,0 +1,103 @@
+package memory
+
+import "context"
+
+const (
+	VectorBackendQdrant   = "qdrant"
+	VectorBackendPgVector = "pgvector"
+)
+
+// VectorPoint is one stored memory: its payload plus an optional dense vector
+// (named by VectorName when the store uses named vectors) and an optional
+// sparse vector.
+type VectorPoint struct {
+	ID               string         `json:"id"`
+	Vector           []float32      `json:"vector"`
+	VectorName       string         `json:"vector_name,omitempty"`
+	SparseIndices    []uint32       `json:"sparse_indices,omitempty"`
+	SparseValues     []float32      `json:"sparse_values,omitempty"`
+	SparseVectorName string         `json:"sparse_vector_name,omitempty"`
+	Payload          map[string]any `json:"payload,omitempty"`
+}
+
+// VectorStore is the storage backend for memory points: a payload plus an
+// optional dense vector (named per embedding model) and an optional BM25
+// sparse vector. Service only talks to this interface, so deployments can
+// choose between Qdrant and Postgres/pgvector in config.toml.
+type VectorStore interface {
+	Backend() string
+	Ping(ctx context.Context) error
+	UsesNamedVectors() bool
+	SparseVectorName() string
+
+	Upsert(ctx context.Context, points []VectorPoint) error
+	// UpdateVectors sets the named dense vector (VectorName/Vector) on points
+	// that already exist, leaving their payload and other vectors untouched.
+	UpdateVectors(ctx context.Context, points []VectorPoint) error
+	Get(ctx context.Context, id string) (*VectorPoint, error)
+	Delete(ctx context.Context, id string) error
+	DeleteBatch(ctx context.Context, ids []string) error
+	DeleteAll(ctx context.Context, filters map[string]any) error
+	Count(ctx context.Context, filters map[string]any) (uint64, error)
+	List(ctx context.Context, limit int, filters map[string]any, withSparseVectors bool) ([]VectorPoint, error)
+	// Scroll pages through all points matching filters. Pass "" as offset to
+	// start; an empty next offset means there are no more pages.
+	Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error)
+	// ScrollWithVectors is Scroll that also returns each point's dense vector
+	// stored under vectorName, when present.
+	ScrollWithVectors(ctx context.Context, limit int, filters map[string]any, offset, vectorName string) ([]VectorPoint, string, error)
+
+	Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error)
+	SearchWithVectors(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string, withVectors bool) ([]VectorPoint, []float64, error)
+	SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, withSparseVectors bool) ([]VectorPoint, []float64, error)
+	SearchBySources(ctx context.Context, vector []float32, limit int, filters map[string]any, sources []string, vectorName string) (map[string][]VectorPoint, map[string][]float64, error)
+	SearchSparseBySources(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, sources []string, withSparseVectors bool) (map[string][]VectorPoint, map[string][]float64, error)
+
+	// NewSibling opens another collection on the same backend.
+	NewSibling(collection string, dimension int) (VectorStore, error)
+}
+
+// NamedVectorChecker is implemented by stores whose named vectors are fixed
+// by the collection schema. CheckNamedVector fails with ErrVectorNotDeclared
+// when vectors of dims dimensions cannot be written under name, so an
+// embedding migration fails before it starts rather than on its first write.
+type NamedVectorChecker interface {
+	CheckNamedVector(ctx context.Context, name string, dims int) error
+}
+
+// searchBySources runs search once per source in parallel, adding a "source"
+// filter to each call, and groups the results by source.
+func searchBySources(filters map[string]any, sources []string, search func(filters map[string]any) ([]VectorPoint, []float64, error)) (map[string][]VectorPoint, map[string][]float64, error) {
+	pointsBySource := make(map[string][]VectorPoint, len(sources))
+	scoresBySource := make(map[string][]float64, len(sources))
+	if len(sources) == 0 {
+		return pointsBySource, scoresBySource, nil
+	}
+
+	type result struct {
+		source string
+		points []VectorPoint
+		scores []float64
+		err    error
+	}
+	ch := make(chan result, len(sources))
+	for _, src := range sources {
+		go func(source string) {
+			merged := cloneFilters(filters)
+			if source != "" {
+				merged["source"] = source
+			}
+			pts, scs, err := search(merged)
+			ch <- result{source: source, points: pts, scores: scs, err: err}
+		}(src)
+	}
+	for range sources {
+		r := <-ch
+		if r.err != nil {
+			return nil, nil, r.err
+		}
+		pointsBySource[r.source] = r.points
+		scoresBySource[r.source] = r.scores
+	}
+	return pointsBySource, scoresBySource, nil
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
 )
 
 const (
@@ -21,8 +23,45 @@ const (
 	EventTypeMessageCreated EventType = "message_created"
 	// EventTypeScheduleCompleted is emitted after a scheduled task finishes execution.
 	EventTypeScheduleCompleted EventType = "schedule_completed"
+	// EventTypeScheduleRunFailed is emitted when a schedule run fails, once per attempt.
+	EventTypeScheduleRunFailed EventType = "schedule_run_failed"
+	// EventTypeMemoryAdded is emitted after memories are added to a bot.
+	EventTypeMemoryAdded EventType = "memory_added"
+	// EventTypeHeartbeatFired is emitted after a heartbeat runs.
+	EventTypeHeartbeatFired EventType = "heartbeat_fired"
+	// EventTypeEvolutionCompleted is emitted when an evolution run is recorded as
+	// completed, skipped or failed.
+	EventTypeEvolutionCompleted EventType = "evolution_completed"
+	// EventTypeContainerStateChanged is emitted when a bot container starts,
+	// stops, fails or is removed.
+	EventTypeContainerStateChanged EventType = "container_state_changed"
+	// EventTypeChannelConnectionLost is emitted when a channel connection drops
+	// or cannot be established.
+	EventTypeChannelConnectionLost EventType = "channel_connection_lost"
 )
 
+// AllEventTypes lists every event type the hub publishes.
+var AllEventTypes = []EventType{
+	EventTypeMessageCreated,
+	EventTypeScheduleCompleted,
+	EventTypeScheduleRunFailed,
+	EventTypeMemoryAdded,
+	EventTypeHeartbeatFired,
+	EventTypeEvolutionCompleted,
+	EventTypeContainerStateChanged,
+	EventTypeChannelConnectionLost,
+}
+
+// IsValidEventType reports whether t is a known event type.
+func IsValidEventType(t EventType) bool {
+	for _, known := range AllEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 // Event is the normalized payload emitted by the in-process message event hub.
 type Event struct {
 	Type  EventType       `json:"type"`
@@ -30,6 +69,21 @@ type Event struct {
 	Data  json.RawMessage `json:"data,omitempty"`
 }
 
+// NewEvent builds an event with data encoded as JSON. Data that cannot be
+// encoded is left out.
+func NewEvent(eventType EventType, botID string, data any) Event {
+	evt := Event{Type: eventType, BotID: botID}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			evt.Data = raw
+		}
+	}
+	return evt
+}
+
+var droppedEvents = metrics.NewCounter("memoh_event_hub_dropped_events_total",
+	"Events dropped because a subscriber's buffer was full, by subscription (bot, all).", "subscription")
+
 // Publisher publishes events to subscribers.
 type Publisher interface {
 	Publish(event Event)
@@ -44,12 +98,15 @@ type Subscriber interface {
 type Hub struct {
 	mu      sync.RWMutex
 	streams map[string]map[string]chan Event
+	// all holds subscribers that receive the events of every bot.
+	all map[string]chan Event
 }
 
 // NewHub creates an empty message event hub.
 func NewHub() *Hub {
 	return &Hub{
 		streams: map[string]map[string]chan Event{},
+		all:     map[string]chan Event{},
 	}
 }
 
@@ -70,6 +127,14 @@ func (h *Hub) Publish(event Event) {
 		case ch <- event:
 		default:
 			// Drop if receiver is slow to avoid blocking persistence path.
+			droppedEvents.Inc("bot")
+		}
+	}
+	for _, ch := range h.all {
+		select {
+		case ch <- event:
+		default:
+			droppedEvents.Inc("all")
 		}
 	}
 }
@@ -124,3 +189,38 @@ func (h *Hub) Subscribe(botID string, buffer int) (string, <-chan Event, func())
 
 	return streamID, ch, cancel
 }
+
+// SubscribeAll registers one subscriber for the events of all bots. Like
+// Subscribe, events are dropped while the subscriber's buffer is full; drops
+// are counted in memoh_event_hub_dropped_events_total.
+func (h *Hub) SubscribeAll(buffer int) (string, <-chan Event, func()) {
+	if h == nil {
+		ch := make(chan Event)
+		close(ch)
+		return "", ch, func() {}
+	}
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+
+	streamID := uuid.NewString()
+	ch := make(chan Event, buffer)
+
+	h.mu.Lock()
+	h.all[streamID] = ch
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			if current, ok := h.all[streamID]; ok {
+				delete(h.all, streamID)
+				close(current)
+			}
+			h.mu.Unlock()
+		})
+	}
+
+	return streamID, ch, cancel
+}
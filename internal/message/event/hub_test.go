@@ -57,3 +57,27 @@ func TestHubSlowSubscriberDoesNotBlockPublish(t *testing.T) {
 		t.Fatalf("expected at least one event in buffer")
 	}
 }
+
+func TestHubSubscribeAllReceivesEveryBot(t *testing.T) {
+	hub := NewHub()
+	_, stream, cancel := hub.SubscribeAll(8)
+
+	hub.Publish(Event{Type: EventTypeMessageCreated, BotID: "bot-a"})
+	hub.Publish(Event{Type: EventTypeMemoryAdded, BotID: "bot-b"})
+
+	for _, want := range []string{"bot-a", "bot-b"} {
+		select {
+		case evt := <-stream:
+			if evt.BotID != want {
+				t.Fatalf("expected event for %s, got %s", want, evt.BotID)
+			}
+		case <-time.After(200 * time.Millisecond):
+			t.Fatalf("expected event for %s", want)
+		}
+	}
+
+	cancel()
+	if _, ok := <-stream; ok {
+		t.Fatalf("expected stream to be closed after cancel")
+	}
+}
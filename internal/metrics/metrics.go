@@ -0,0 +1,198 @@
+// Package metrics wraps the Prometheus client library with the small API the
+// server uses: label-partitioned counters, gauges and histograms registered
+// in one registry, plus hooks that refresh gauges right before a scrape.
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 2 minutes, so
+// that both fast HTTP routes and slow LLM gateway calls resolve.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Default is the process-wide registry served on /metrics. Besides the
+// server's own metrics it reports the Go runtime and process collectors.
+var Default = newDefaultRegistry()
+
+// Registry holds metrics and serves them on scrape.
+type Registry struct {
+	reg   *prometheus.Registry
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{reg: prometheus.NewRegistry()}
+}
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return r
+}
+
+// OnScrape registers fn to run before every scrape. It is meant for gauges
+// that mirror external state, such as container task states.
+func (r *Registry) OnScrape(fn func(ctx context.Context)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+func (r *Registry) runHooks(ctx context.Context) {
+	r.mu.Lock()
+	hooks := append([]func(context.Context){}, r.hooks...)
+	r.mu.Unlock()
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
+// Handler runs the scrape hooks and serves the registry in the exposition
+// format the scraper negotiates.
+func (r *Registry) Handler() http.Handler {
+	inner := promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.runHooks(req.Context())
+		inner.ServeHTTP(w, req)
+	})
+}
+
+// Write runs the scrape hooks and renders all metrics in the Prometheus text
+// exposition format, sorted by name.
+func (r *Registry) Write(ctx context.Context, w io.Writer) error {
+	r.runHooks(ctx)
+	families, err := r.reg.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels.
+type CounterVec struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter creates a counter in the Default registry.
+func NewCounter(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter creates a counter in r. It panics if the name is taken.
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	r.reg.MustRegister(vec)
+	return &CounterVec{vec: vec}
+}
+
+// Inc adds one to the series with the given label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.vec.WithLabelValues(labelValues...).Add(v)
+}
+
+// Value returns the current value of a series.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	var m dto.Metric
+	if err := c.vec.WithLabelValues(labelValues...).Write(&m); err != nil {
+		return 0
+	}
+	return m.GetCounter().GetValue()
+}
+
+// GaugeVec is a value that can go up and down, partitioned by labels.
+type GaugeVec struct {
+	vec *prometheus.GaugeVec
+}
+
+// NewGauge creates a gauge in the Default registry.
+func NewGauge(name, help string, labels ...string) *GaugeVec {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// NewGauge creates a gauge in r. It panics if the name is taken.
+func (r *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	r.reg.MustRegister(vec)
+	return &GaugeVec{vec: vec}
+}
+
+// Set sets the series to v.
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(v)
+}
+
+// Add adds v, which may be negative, to the series.
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Add(v)
+}
+
+// Reset drops all series, so label combinations that disappeared are no
+// longer reported.
+func (g *GaugeVec) Reset() {
+	g.vec.Reset()
+}
+
+// HistogramVec samples observations into cumulative buckets.
+type HistogramVec struct {
+	vec *prometheus.HistogramVec
+}
+
+// NewHistogram creates a histogram in the Default registry. Nil buckets use
+// DefaultBuckets.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewHistogram creates a histogram in r. Nil buckets use DefaultBuckets. It
+// panics if the name is taken.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	r.reg.MustRegister(vec)
+	return &HistogramVec{vec: vec}
+}
+
+// Observe records one observation.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(v)
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("test_requests_total", "Requests served.", "route", "code")
+	inflight := r.NewGauge("test_inflight", "In-flight requests.")
+	latency := r.NewHistogram("test_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+
+	requests.Inc("/a", "200")
+	requests.Add(2, "/a", "200")
+	requests.Inc(`/b"x`, "500")
+	inflight.Set(3)
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	r.OnScrape(func(context.Context) { inflight.Set(4) })
+
+	var buf bytes.Buffer
+	if err := r.Write(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+
+	want := []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{code="200",route="/a"} 3`,
+		`test_requests_total{code="500",route="/b\"x"} 1`,
+		"# TYPE test_inflight gauge",
+		"test_inflight 4",
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{route="/a",le="0.1"} 1`,
+		`test_latency_seconds_bucket{route="/a",le="1"} 2`,
+		`test_latency_seconds_bucket{route="/a",le="+Inf"} 2`,
+		`test_latency_seconds_sum{route="/a"} 0.55`,
+		`test_latency_seconds_count{route="/a"} 2`,
+	}
+	for _, line := range want {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, got)
+		}
+	}
+	if strings.Index(got, "test_inflight") > strings.Index(got, "test_latency_seconds") {
+		t.Error("metrics are not sorted by name")
+	}
+	if requests.Value("/a", "200") != 3 {
+		t.Errorf("unexpected counter value %v", requests.Value("/a", "200"))
+	}
+}
+
+func TestGaugeReset(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("test_tasks", "Tasks.", "status")
+	g.Set(1, "running")
+	g.Reset()
+	g.Set(2, "stopped")
+
+	var buf bytes.Buffer
+	if err := r.Write(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "running") {
+		t.Fatalf("reset series still reported:\n%s", buf.String())
+	}
+}
+
+func TestDuplicateMetricPanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_dup", "Dup.")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate registration")
+		}
+	}()
+	r.NewGauge("test_dup", "Dup.")
+}
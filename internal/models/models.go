@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
 )
 
 // Service provides CRUD operations for models
@@ -52,6 +53,10 @@ func (s *Service) Create(ctx context.Context, req AddRequest) (AddResponse, erro
 		ContextWindow: contextWindow,
 		Reasoning:     model.Reasoning,
 		MaxTokens:     int32(model.MaxTokens),
+
+		InputPrice:       model.InputPrice,
+		OutputPrice:      model.OutputPrice,
+		CachedInputPrice: model.CachedInputPrice,
 	}
 
 	// Handle optional name field
@@ -134,7 +139,7 @@ func (s *Service) List(ctx context.Context) ([]GetResponse, error) {
 
 // ListByType returns models filtered by type (chat or embedding)
 func (s *Service) ListByType(ctx context.Context, modelType ModelType) ([]GetResponse, error) {
-	if modelType != ModelTypeChat && modelType != ModelTypeEmbedding {
+	if !modelType.IsValid() {
 		return nil, fmt.Errorf("invalid model type: %s", modelType)
 	}
 
@@ -178,7 +183,7 @@ func (s *Service) ListByProviderID(ctx context.Context, providerID string) ([]Ge
 
 // ListByProviderIDAndType returns models filtered by provider ID and type.
 func (s *Service) ListByProviderIDAndType(ctx context.Context, providerID string, modelType ModelType) ([]GetResponse, error) {
-	if modelType != ModelTypeChat && modelType != ModelTypeEmbedding {
+	if !modelType.IsValid() {
 		return nil, fmt.Errorf("invalid model type: %s", modelType)
 	}
 	if strings.TrimSpace(providerID) == "" {
@@ -222,6 +227,10 @@ func (s *Service) UpdateByID(ctx context.Context, id string, req UpdateRequest)
 		ContextWindow: contextWindow,
 		Reasoning:     model.Reasoning,
 		MaxTokens:     int32(model.MaxTokens),
+
+		InputPrice:       model.InputPrice,
+		OutputPrice:      model.OutputPrice,
+		CachedInputPrice: model.CachedInputPrice,
 	}
 
 	llmProviderID, err := db.ParseUUID(model.LlmProviderID)
@@ -278,6 +287,10 @@ func (s *Service) UpdateByModelID(ctx context.Context, modelID string, req Updat
 		ContextWindow: contextWindow,
 		Reasoning:     model.Reasoning,
 		MaxTokens:     int32(model.MaxTokens),
+
+		InputPrice:       model.InputPrice,
+		OutputPrice:      model.OutputPrice,
+		CachedInputPrice: model.CachedInputPrice,
 	}
 
 	llmProviderID, err := db.ParseUUID(model.LlmProviderID)
@@ -348,7 +361,7 @@ func (s *Service) Count(ctx context.Context) (int64, error) {
 
 // CountByType returns the number of models of a specific type
 func (s *Service) CountByType(ctx context.Context, modelType ModelType) (int64, error) {
-	if modelType != ModelTypeChat && modelType != ModelTypeEmbedding {
+	if !modelType.IsValid() {
 		return 0, fmt.Errorf("invalid model type: %s", modelType)
 	}
 
@@ -372,6 +385,10 @@ func (s *Service) convertToGetResponse(ctx context.Context, dbModel sqlc.Model)
 			ContextWindow: int(dbModel.ContextWindow),
 			Reasoning:     dbModel.Reasoning,
 			MaxTokens:     int(dbModel.MaxTokens),
+
+			InputPrice:       dbModel.InputPrice,
+			OutputPrice:      dbModel.OutputPrice,
+			CachedInputPrice: dbModel.CachedInputPrice,
 		},
 	}
 
@@ -530,5 +547,12 @@ func FetchProviderByID(ctx context.Context, queries *sqlc.Queries, providerID st
 	if err != nil {
 		return sqlc.LlmProvider{}, err
 	}
-	return queries.GetLlmProviderByID(ctx, parsed)
+	provider, err := queries.GetLlmProviderByID(ctx, parsed)
+	if err != nil {
+		return sqlc.LlmProvider{}, err
+	}
+	if provider.ApiKey, err = secrets.Open(provider.ApiKey); err != nil {
+		return sqlc.LlmProvider{}, fmt.Errorf("decrypt provider api key: %w", err)
+	}
+	return provider, nil
 }
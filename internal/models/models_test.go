@@ -169,6 +169,16 @@ func TestModel_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative price",
+			model: models.Model{
+				ModelID:       "gpt-4",
+				LlmProviderID: "11111111-1111-1111-1111-111111111111",
+				Type:          models.ModelTypeChat,
+				OutputPrice:   -1,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -183,6 +193,23 @@ func TestModel_Validate(t *testing.T) {
 	}
 }
 
+func TestModel_Cost(t *testing.T) {
+	model := models.Model{InputPrice: 2.5, OutputPrice: 10, CachedInputPrice: 1.25}
+
+	assert.InDelta(t, 0.0, model.Cost(0, 0, 0), 1e-12)
+	// 1M prompt tokens + 100k completion tokens.
+	assert.InDelta(t, 3.5, model.Cost(1_000_000, 100_000, 0), 1e-9)
+	// Half of the prompt served from cache at the discounted price.
+	assert.InDelta(t, 1.875, model.Cost(1_000_000, 0, 500_000), 1e-9)
+	// Cached tokens never exceed the prompt.
+	assert.InDelta(t, 1.25, model.Cost(1_000_000, 0, 2_000_000), 1e-9)
+
+	t.Run("no cached price bills cache hits at the input price", func(t *testing.T) {
+		noCache := models.Model{InputPrice: 2.5}
+		assert.InDelta(t, 2.5, noCache.Cost(1_000_000, 0, 400_000), 1e-9)
+	})
+}
+
 func TestModelTypes(t *testing.T) {
 	t.Run("ModelType constants", func(t *testing.T) {
 		assert.Equal(t, models.ModelType("chat"), models.ModelTypeChat)
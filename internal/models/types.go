@@ -11,8 +11,20 @@ type ModelType string
 const (
 	ModelTypeChat      ModelType = "chat"
 	ModelTypeEmbedding ModelType = "embedding"
+	// ModelTypeTranscription is a speech-to-text model served through an
+	// OpenAI-compatible /audio/transcriptions endpoint.
+	ModelTypeTranscription ModelType = "transcription"
 )
 
+// IsValid reports whether t is a known model type.
+func (t ModelType) IsValid() bool {
+	switch t {
+	case ModelTypeChat, ModelTypeEmbedding, ModelTypeTranscription:
+		return true
+	}
+	return false
+}
+
 const (
 	ModelInputText  = "text"
 	ModelInputImage = "image"
@@ -69,6 +81,27 @@ type Model struct {
 	FallbackModelID string    `json:"fallback_model_id,omitempty"`
 	Reasoning       bool      `json:"reasoning"`
 	MaxTokens       int       `json:"max_tokens"`
+	// Prices are in USD per 1M tokens. CachedInputPrice applies to prompt
+	// tokens served from the provider's cache; 0 bills them at InputPrice.
+	InputPrice       float64 `json:"input_price"`
+	OutputPrice      float64 `json:"output_price"`
+	CachedInputPrice float64 `json:"cached_input_price"`
+}
+
+// Cost returns the USD cost of one call. cachedTokens are the part of
+// promptTokens read from the provider's prompt cache.
+func (m Model) Cost(promptTokens, completionTokens, cachedTokens int) float64 {
+	if cachedTokens > promptTokens {
+		cachedTokens = promptTokens
+	}
+	cachedPrice := m.CachedInputPrice
+	if cachedPrice <= 0 {
+		cachedPrice = m.InputPrice
+	}
+	cost := float64(promptTokens-cachedTokens)*m.InputPrice +
+		float64(cachedTokens)*cachedPrice +
+		float64(completionTokens)*m.OutputPrice
+	return cost / 1e6
 }
 
 func (m *Model) Validate() error {
@@ -81,12 +114,15 @@ func (m *Model) Validate() error {
 	if _, err := uuid.Parse(m.LlmProviderID); err != nil {
 		return errors.New("llm provider ID must be a valid UUID")
 	}
-	if m.Type != ModelTypeChat && m.Type != ModelTypeEmbedding {
+	if !m.Type.IsValid() {
 		return errors.New("invalid model type")
 	}
 	if m.Type == ModelTypeEmbedding && m.Dimensions <= 0 {
 		return errors.New("dimensions must be greater than 0")
 	}
+	if m.InputPrice < 0 || m.OutputPrice < 0 || m.CachedInputPrice < 0 {
+		return errors.New("prices must not be negative")
+	}
 
 	return nil
 }
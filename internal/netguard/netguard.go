@@ -0,0 +1,116 @@
+// Package netguard keeps requests to user-supplied URLs, such as webhook
+// endpoints, away from the server's own network. The check runs when the
+// connection is dialed, on the resolved address, so DNS names that point at
+// internal addresses are caught as well.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when a connection to a loopback, private,
+// link-local or otherwise non-public address is refused.
+var ErrBlockedAddress = errors.New("destination address is not allowed")
+
+// dialTimeout bounds connection setup; callers bound the whole request.
+const dialTimeout = 10 * time.Second
+
+// Guard refuses connections to non-public addresses unless they fall into
+// one of the allowed networks.
+type Guard struct {
+	allowed []*net.IPNet
+}
+
+// New creates a guard. allowedCIDRs lists internal networks an admin has
+// allowed, as CIDRs or single addresses.
+func New(allowedCIDRs []string) (*Guard, error) {
+	g := &Guard{}
+	for _, raw := range allowedCIDRs {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "/") {
+			ip := net.ParseIP(raw)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid allowed network %q", raw)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			g.allowed = append(g.allowed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network %q", raw)
+		}
+		g.allowed = append(g.allowed, network)
+	}
+	return g, nil
+}
+
+// Allowed reports whether a connection to ip may be made.
+func (g *Guard) Allowed(ip net.IP) bool {
+	if g != nil {
+		for _, network := range g.allowed {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return isPublic(ip)
+}
+
+// Control is a net.Dialer Control function that refuses blocked addresses.
+func (g *Guard) Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !g.Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// Transport returns an HTTP transport that dials through the guard.
+// Environment proxies are ignored, since the guard would otherwise only see
+// the proxy's address.
+func (g *Guard) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   g.Control,
+	}).DialContext
+	return transport
+}
+
+// isPublic reports whether ip is a globally routable unicast address.
+func isPublic(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		// Carrier-grade NAT space (100.64.0.0/10) is not reachable from the
+		// internet either.
+		if ip[0] == 100 && ip[1]&0xc0 == 64 {
+			return false
+		}
+		if ip[0] == 0 {
+			return false
+		}
+	}
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
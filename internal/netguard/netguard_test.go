@@ -0,0 +1,65 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	g, err := New([]string{"10.20.0.0/16", "192.168.1.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.0.0.1":         false,
+		"10.20.3.4":        true,
+		"172.16.0.1":       false,
+		"192.168.1.5":      true,
+		"192.168.1.6":      false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"224.0.0.1":        false,
+		"::ffff:127.0.0.1": false,
+	}
+	for addr, want := range cases {
+		if got := g.Allowed(net.ParseIP(addr)); got != want {
+			t.Errorf("Allowed(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if _, err := New([]string{"not-a-network"}); err == nil {
+		t.Fatal("expected invalid allowlist entry to be rejected")
+	}
+}
+
+func TestTransportRefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	blocked, _ := New(nil)
+	client := &http.Client{Transport: blocked.Transport()}
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected ErrBlockedAddress, got %v", err)
+	}
+
+	allowed, _ := New([]string{"127.0.0.0/8"})
+	client = &http.Client{Transport: allowed.Transport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("allowlisted request: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,216 @@
+package openaicompat
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
+)
+
+// SplitMessages turns the OpenAI message list into the query the bot answers
+// (the last message, which must come from the user) and the messages before
+// it. System and developer messages are kept as system messages; tool
+// messages are dropped because the bot runs its own tools.
+func SplitMessages(messages []Message) (string, []conversation.ModelMessage, error) {
+	if len(messages) == 0 {
+		return "", nil, errors.New("messages must not be empty")
+	}
+	last := messages[len(messages)-1]
+	query := strings.TrimSpace(MessageText(last.Content))
+	if last.Role != "user" || query == "" {
+		return "", nil, errors.New("the last message must be a user message with text content")
+	}
+	history := make([]conversation.ModelMessage, 0, len(messages)-1)
+	for _, m := range messages[:len(messages)-1] {
+		role := m.Role
+		switch role {
+		case "system", "developer":
+			role = "system"
+		case "user", "assistant":
+		default:
+			continue
+		}
+		text := strings.TrimSpace(MessageText(m.Content))
+		if text == "" {
+			continue
+		}
+		history = append(history, conversation.ModelMessage{
+			Role:    role,
+			Content: conversation.NewTextContent(text),
+		})
+	}
+	return query, history, nil
+}
+
+// HasConversation reports whether the history carries earlier turns, in
+// which case the client manages the context and the bot's stored history
+// should not be added on top.
+func HasConversation(history []conversation.ModelMessage) bool {
+	for _, m := range history {
+		if m.Role != "system" {
+			return true
+		}
+	}
+	return false
+}
+
+// MessageText returns the text of an OpenAI message content, which is either
+// a string or an array of parts.
+func MessageText(content json.RawMessage) string {
+	if len(content) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(content, &s); err == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ReplyText joins the text of the assistant messages of a bot response, the
+// same text a stream delivers as deltas.
+func ReplyText(messages []conversation.ModelMessage) string {
+	texts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		if m.Role != "assistant" {
+			continue
+		}
+		if text := strings.TrimSpace(m.TextContent()); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// NewCompletion builds a non-streaming response.
+func NewCompletion(id, model string, created int64, resp conversation.ChatResponse) ChatCompletion {
+	stop := FinishReasonStop
+	completion := ChatCompletion{
+		ID:      id,
+		Object:  ObjectCompletion,
+		Created: created,
+		Model:   model,
+		Choices: []Choice{{
+			Message:      &ResponseMessage{Role: "assistant", Content: ReplyText(resp.Messages)},
+			FinishReason: &stop,
+		}},
+	}
+	if resp.Usage != nil {
+		completion.Usage = &Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+	}
+	return completion
+}
+
+// StreamTranslator turns bot stream chunks into chat.completion.chunk
+// objects.
+type StreamTranslator struct {
+	id           string
+	model        string
+	created      int64
+	includeUsage bool
+	sentRole     bool
+	usage        *Usage
+}
+
+func NewStreamTranslator(id, model string, created int64, includeUsage bool) *StreamTranslator {
+	return &StreamTranslator{id: id, model: model, created: created, includeUsage: includeUsage}
+}
+
+type streamEnvelope struct {
+	Type    string `json:"type"`
+	Delta   string `json:"delta"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Usage   *struct {
+		PromptTokens     int `json:"promptTokens"`
+		CompletionTokens int `json:"completionTokens"`
+		TotalTokens      int `json:"totalTokens"`
+	} `json:"usage,omitempty"`
+}
+
+// Translate maps one bot chunk. It returns the chunks to send, or a non-empty
+// error message when the bot reported a failure.
+func (t *StreamTranslator) Translate(chunk conversation.StreamChunk) ([]ChatCompletion, string) {
+	var env streamEnvelope
+	if err := json.Unmarshal(chunk, &env); err != nil {
+		return nil, ""
+	}
+	if env.Usage != nil {
+		t.usage = &Usage{
+			PromptTokens:     env.Usage.PromptTokens,
+			CompletionTokens: env.Usage.CompletionTokens,
+			TotalTokens:      env.Usage.TotalTokens,
+		}
+	}
+	switch env.Type {
+	case "text_delta":
+		if env.Delta == "" {
+			return nil, ""
+		}
+		return []ChatCompletion{t.delta(env.Delta, nil)}, ""
+	case "error":
+		msg := strings.TrimSpace(env.Error)
+		if msg == "" {
+			msg = strings.TrimSpace(env.Message)
+		}
+		if msg == "" {
+			msg = "stream error"
+		}
+		return nil, msg
+	}
+	return nil, ""
+}
+
+// Finish returns the closing chunk and, when requested, the usage chunk.
+func (t *StreamTranslator) Finish() []ChatCompletion {
+	stop := FinishReasonStop
+	out := []ChatCompletion{t.delta("", &stop)}
+	if t.includeUsage {
+		usage := t.usage
+		if usage == nil {
+			usage = &Usage{}
+		}
+		out = append(out, ChatCompletion{
+			ID:      t.id,
+			Object:  ObjectChunk,
+			Created: t.created,
+			Model:   t.model,
+			Choices: []Choice{},
+			Usage:   usage,
+		})
+	}
+	return out
+}
+
+func (t *StreamTranslator) delta(content string, finishReason *string) ChatCompletion {
+	delta := &ResponseMessage{Content: content}
+	if !t.sentRole {
+		delta.Role = "assistant"
+		t.sentRole = true
+	}
+	return ChatCompletion{
+		ID:      t.id,
+		Object:  ObjectChunk,
+		Created: t.created,
+		Model:   t.model,
+		Choices: []Choice{{Delta: delta, FinishReason: finishReason}},
+	}
+}
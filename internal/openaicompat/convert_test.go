@@ -0,0 +1,104 @@
+package openaicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/conversation"
+)
+
+func textMessage(role, text string) Message {
+	raw, _ := json.Marshal(text)
+	return Message{Role: role, Content: raw}
+}
+
+func TestSplitMessages(t *testing.T) {
+	query, history, err := SplitMessages([]Message{
+		textMessage("system", "be brief"),
+		textMessage("user", "hi"),
+		textMessage("assistant", "hello"),
+		{Role: "tool", Content: json.RawMessage(`"ignored"`)},
+		{Role: "user", Content: json.RawMessage(`[{"type":"text","text":"what"},{"type":"image_url"},{"type":"text","text":"now?"}]`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "what\nnow?" {
+		t.Errorf("query = %q", query)
+	}
+	if len(history) != 3 || history[0].Role != "system" || history[2].Role != "assistant" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	if !HasConversation(history) {
+		t.Error("history with earlier turns should count as a conversation")
+	}
+
+	_, history, err = SplitMessages([]Message{textMessage("developer", "rules"), textMessage("user", "hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HasConversation(history) {
+		t.Error("system prompt alone should not count as a conversation")
+	}
+
+	if _, _, err := SplitMessages(nil); err == nil {
+		t.Error("expected error for empty messages")
+	}
+	if _, _, err := SplitMessages([]Message{textMessage("user", "hi"), textMessage("assistant", "hello")}); err == nil {
+		t.Error("expected error when the last message is not from the user")
+	}
+}
+
+func TestNewCompletion(t *testing.T) {
+	resp := conversation.ChatResponse{
+		Messages: []conversation.ModelMessage{
+			{Role: "assistant", Content: conversation.NewTextContent("first")},
+			{Role: "tool", Content: conversation.NewTextContent("tool output")},
+			{Role: "assistant", Content: conversation.NewTextContent("second")},
+		},
+		Usage: &conversation.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+	}
+	got := NewCompletion("chatcmpl-1", "bot", 10, resp)
+	if got.Object != ObjectCompletion || len(got.Choices) != 1 {
+		t.Fatalf("unexpected completion %+v", got)
+	}
+	if content := got.Choices[0].Message.Content; content != "first\n\nsecond" {
+		t.Errorf("content = %q", content)
+	}
+	if got.Usage == nil || got.Usage.TotalTokens != 5 {
+		t.Errorf("usage = %+v", got.Usage)
+	}
+}
+
+func TestStreamTranslator(t *testing.T) {
+	tr := NewStreamTranslator("chatcmpl-1", "bot", 10, true)
+
+	out, errMsg := tr.Translate(conversation.StreamChunk(`{"type":"reasoning_delta","delta":"thinking"}`))
+	if len(out) != 0 || errMsg != "" {
+		t.Fatalf("reasoning should be skipped, got %+v %q", out, errMsg)
+	}
+	out, _ = tr.Translate(conversation.StreamChunk(`{"type":"text_delta","delta":"Hel"}`))
+	if len(out) != 1 || out[0].Choices[0].Delta.Role != "assistant" || out[0].Choices[0].Delta.Content != "Hel" {
+		t.Fatalf("first delta should carry the role, got %+v", out)
+	}
+	out, _ = tr.Translate(conversation.StreamChunk(`{"type":"text_delta","delta":"lo"}`))
+	if len(out) != 1 || out[0].Choices[0].Delta.Role != "" {
+		t.Fatalf("later deltas should not repeat the role, got %+v", out)
+	}
+	tr.Translate(conversation.StreamChunk(`{"type":"agent_end","usage":{"promptTokens":3,"completionTokens":2,"totalTokens":5}}`))
+
+	final := tr.Finish()
+	if len(final) != 2 {
+		t.Fatalf("expected stop and usage chunks, got %d", len(final))
+	}
+	if fr := final[0].Choices[0].FinishReason; fr == nil || *fr != FinishReasonStop {
+		t.Errorf("finish reason = %v", fr)
+	}
+	if len(final[1].Choices) != 0 || final[1].Usage == nil || final[1].Usage.TotalTokens != 5 {
+		t.Errorf("usage chunk = %+v", final[1])
+	}
+
+	if _, errMsg := tr.Translate(conversation.StreamChunk(`{"type":"error","error":"boom"}`)); errMsg != "boom" {
+		t.Errorf("error message = %q", errMsg)
+	}
+}
@@ -0,0 +1,93 @@
+// Package openaicompat translates between the OpenAI chat completions API and
+// bot conversations, so that OpenAI clients can talk to bots directly.
+package openaicompat
+
+import "encoding/json"
+
+const (
+	ObjectList       = "list"
+	ObjectModel      = "model"
+	ObjectCompletion = "chat.completion"
+	ObjectChunk      = "chat.completion.chunk"
+
+	FinishReasonStop = "stop"
+)
+
+// ChatCompletionRequest is the subset of the OpenAI request the bots honour.
+// Sampling parameters such as temperature are accepted but ignored: the bot's
+// own model settings apply.
+type ChatCompletionRequest struct {
+	Model         string         `json:"model"`
+	Messages      []Message      `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	User          string         `json:"user,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// Message is an incoming message. Content is a string or an array of
+// content parts; only text parts are used.
+type Message struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+	Name    string          `json:"name,omitempty"`
+}
+
+type ResponseMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+type Choice struct {
+	Index        int              `json:"index"`
+	Message      *ResponseMessage `json:"message,omitempty"`
+	Delta        *ResponseMessage `json:"delta,omitempty"`
+	FinishReason *string          `json:"finish_reason"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatCompletion is both the non-streaming response (object
+// chat.completion) and a streamed chunk (object chat.completion.chunk).
+type ChatCompletion struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   *Usage   `json:"usage,omitempty"`
+}
+
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type ModelList struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
+
+// ErrorResponse is the OpenAI error envelope; clients read error.message.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
+
+func NewError(errType, code, message string) ErrorResponse {
+	return ErrorResponse{Error: ErrorBody{Message: message, Type: errType, Code: code}}
+}
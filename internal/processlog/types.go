@@ -52,6 +52,10 @@ const (
 	StepTriggerCompleted  ProcessLogStep = "trigger_completed"
 	StepQuotaChecked      ProcessLogStep = "quota_checked"
 	StepResolveCompleted  ProcessLogStep = "resolve_completed"
+
+	StepMemoryReembedStarted   ProcessLogStep = "memory_reembed_started"
+	StepMemoryReembedCompleted ProcessLogStep = "memory_reembed_completed"
+	StepMemoryReembedFailed    ProcessLogStep = "memory_reembed_failed"
 )
 
 // ProcessLogLevel represents the log level
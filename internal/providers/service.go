@@ -9,6 +9,7 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
 )
 
 // Service handles provider operations
@@ -38,12 +39,17 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (GetResponse, e
 		return GetResponse{}, fmt.Errorf("marshal metadata: %w", err)
 	}
 
+	apiKey, err := secrets.Seal(req.APIKey)
+	if err != nil {
+		return GetResponse{}, fmt.Errorf("encrypt api key: %w", err)
+	}
+
 	// Create provider
 	provider, err := s.queries.CreateLlmProvider(ctx, sqlc.CreateLlmProviderParams{
 		Name:       req.Name,
 		ClientType: string(req.ClientType),
 		BaseUrl:    req.BaseURL,
-		ApiKey:     req.APIKey,
+		ApiKey:     apiKey,
 		Metadata:   metadataJSON,
 	})
 	if err != nil {
@@ -142,7 +148,14 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Get
 		baseURL = *req.BaseURL
 	}
 
-	apiKey := resolveUpdatedAPIKey(existing.ApiKey, req.APIKey)
+	existingAPIKey, err := secrets.Open(existing.ApiKey)
+	if err != nil {
+		return GetResponse{}, fmt.Errorf("decrypt api key: %w", err)
+	}
+	apiKey, err := secrets.Seal(resolveUpdatedAPIKey(existingAPIKey, req.APIKey))
+	if err != nil {
+		return GetResponse{}, fmt.Errorf("encrypt api key: %w", err)
+	}
 
 	metadata := existing.Metadata
 	if req.Metadata != nil {
@@ -214,7 +227,12 @@ func (s *Service) toGetResponse(provider sqlc.LlmProvider) GetResponse {
 	}
 
 	// Mask API key (show only first 8 characters)
-	maskedAPIKey := maskAPIKey(provider.ApiKey)
+	apiKey, err := secrets.Open(provider.ApiKey)
+	if err != nil {
+		slog.Warn("provider api key decrypt failed", slog.String("id", provider.ID.String()), slog.Any("error", err))
+		apiKey = ""
+	}
+	maskedAPIKey := maskAPIKey(apiKey)
 
 	return GetResponse{
 		ID:         provider.ID.String(),
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/boot"
@@ -17,13 +20,34 @@ import (
 	msgEvent "github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
 )
 
+// OwnerNotifier delivers schedule failure notices to a bot's owner.
+type OwnerNotifier interface {
+	NotifyOwner(ctx context.Context, botID, ownerUserID, text string) error
+}
+
+const (
+	// maxRetryBackoff caps the exponential delay between retries.
+	maxRetryBackoff = time.Hour
+	// fireTimeLookback bounds how late after its fire time a cron job may
+	// start and still be matched to that fire time.
+	fireTimeLookback = time.Minute
+	// runInterruptedReason is the error stored on runs still open at
+	// startup. Such runs count as not run when catching up.
+	runInterruptedReason = "interrupted by server restart"
+)
+
 type Service struct {
 	queries   *sqlc.Queries
 	pool      *automation.CronPool
 	triggerer Triggerer
 	hub       *msgEvent.Hub
+	notifier  OwnerNotifier
 	jwtSecret string
 	logger    *slog.Logger
+
+	// stop is closed on shutdown to abandon pending retries and catch-ups.
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func NewService(log *slog.Logger, queries *sqlc.Queries, triggerer Triggerer, pool *automation.CronPool, hub *msgEvent.Hub, runtimeConfig *boot.RuntimeConfig) *Service {
@@ -34,18 +58,36 @@ func NewService(log *slog.Logger, queries *sqlc.Queries, triggerer Triggerer, po
 		hub:       hub,
 		jwtSecret: runtimeConfig.JwtSecret,
 		logger:    log.With(slog.String("service", "schedule")),
+		stop:      make(chan struct{}),
 	}
 }
 
+// SetOwnerNotifier sets how the owner hears about runs that failed for good.
+func (s *Service) SetOwnerNotifier(n OwnerNotifier) {
+	s.notifier = n
+}
+
+// Stop abandons pending retries and catch-up runs.
+func (s *Service) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
 func (s *Service) Bootstrap(ctx context.Context) error {
 	if s.queries == nil {
 		return fmt.Errorf("schedule queries not configured")
 	}
+	if n, err := s.queries.FailRunningScheduleRuns(ctx, runInterruptedReason); err != nil {
+		s.logger.Warn("failed to close interrupted schedule runs", slog.Any("error", err))
+	} else if n > 0 {
+		s.logger.Info("closed interrupted schedule runs", slog.Int64("count", n))
+	}
 	items, err := s.queries.ListEnabledSchedules(ctx)
 	if err != nil {
 		return err
 	}
-	var failed int
+	var failed, archived int
+	now := time.Now()
+	catchUps := map[string][]time.Time{}
 	for _, item := range items {
 		if err := s.scheduleJob(item); err != nil {
 			failed++
@@ -54,25 +96,129 @@ func (s *Service) Bootstrap(ctx context.Context) error {
 				slog.String("pattern", item.Pattern),
 				slog.Any("error", err),
 			)
+			continue
+		}
+		if missed := s.missedFireTimes(ctx, item, now); len(missed) > 0 {
+			catchUps[item.ID.String()] = missed
+			continue
+		}
+		// One-shot schedules whose time passed while the server was down
+		// and that are not caught up will never fire again.
+		if t := timingFromRow(item); t.kind == KindOnce && !t.runAt.After(now) {
+			s.archive(ctx, item.ID.String())
+			archived++
 		}
 	}
 	s.logger.Info("schedule bootstrap complete",
 		slog.Int("total", len(items)),
 		slog.Int("registered", len(items)-failed),
 		slog.Int("failed", failed),
+		slog.Int("catching_up", len(catchUps)),
+		slog.Int("archived", archived),
 	)
+	for id, missed := range catchUps {
+		go s.catchUp(id, missed)
+	}
+	return nil
+}
+
+// missedFireTimes returns the fire times the schedule's misfire policy wants
+// replayed: those between its last scheduled run (or its creation) and now.
+func (s *Service) missedFireTimes(ctx context.Context, row sqlc.Schedule, now time.Time) []time.Time {
+	if row.MisfirePolicy != MisfireRunOnce && row.MisfirePolicy != MisfireRunAll {
+		return nil
+	}
+	t := timingFromRow(row)
+	since := row.CreatedAt.Time
+	last, err := s.queries.GetLastScheduledRun(ctx, row.ID)
+	switch {
+	case err == nil:
+		since = catchUpSince(t, row.CreatedAt.Time, last)
+	case !errors.Is(err, pgx.ErrNoRows):
+		s.logger.Warn("failed to load last schedule run, skipping catch-up",
+			slog.String("schedule_id", row.ID.String()),
+			slog.Any("error", err),
+		)
+		return nil
+	}
+	sched, err := t.schedule(s.pool)
+	if err != nil {
+		return nil
+	}
+	missed, dropped := s.pool.FireTimesBetween(sched, since, now, MaxCatchUpRuns)
+	if dropped > 0 && row.MisfirePolicy == MisfireRunAll {
+		s.logger.Warn("too many missed schedule runs, replaying only the most recent",
+			slog.String("schedule_id", row.ID.String()),
+			slog.Int("replayed", len(missed)),
+			slog.Int("dropped", dropped),
+		)
+	}
+	return selectCatchUps(row.MisfirePolicy, missed)
+}
+
+// catchUpSince returns the time after which fire times count as missed,
+// given the last scheduled run. A run cut short by a restart did not really
+// run, so its own fire time is replayed.
+func catchUpSince(t timing, createdAt time.Time, last sqlc.ScheduleRun) time.Time {
+	interrupted := last.Status == RunStatusError && last.Error == runInterruptedReason
+	switch {
+	case interrupted && last.ScheduledFor.Valid:
+		return last.ScheduledFor.Time.Add(-time.Nanosecond)
+	case interrupted && t.kind == KindOnce:
+		// Runs recorded before scheduled_for was set on cron runs only have
+		// started_at, which is already past the one-shot's run_at.
+		return createdAt
+	case last.ScheduledFor.Valid:
+		return last.ScheduledFor.Time
+	}
+	return last.StartedAt.Time
+}
+
+// selectCatchUps applies a misfire policy to the missed fire times.
+func selectCatchUps(policy string, missed []time.Time) []time.Time {
+	if len(missed) == 0 {
+		return nil
+	}
+	switch policy {
+	case MisfireRunOnce:
+		return missed[len(missed)-1:]
+	case MisfireRunAll:
+		return missed
+	}
 	return nil
 }
 
+// catchUp replays missed fire times one after another. It stops when the
+// schedule is disabled or deleted in the meantime, e.g. by max_calls.
+// One-shot schedules are archived once their missed run is done.
+func (s *Service) catchUp(scheduleID string, missed []time.Time) {
+	ctx := context.Background()
+	var schedule Schedule
+	for _, firedAt := range missed {
+		var err error
+		schedule, err = s.Get(ctx, scheduleID)
+		if err != nil || !schedule.Enabled {
+			return
+		}
+		s.logger.Info("running missed schedule",
+			slog.String("schedule_id", scheduleID),
+			slog.Time("scheduled_for", firedAt),
+		)
+		if err := s.runWithRetry(ctx, schedule, RunTriggerCatchUp, firedAt); err != nil && s.stopping() {
+			return
+		}
+	}
+	if schedule.Kind == KindOnce {
+		s.archive(ctx, scheduleID)
+	}
+}
+
 func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (Schedule, error) {
 	if s.queries == nil {
 		return Schedule{}, fmt.Errorf("schedule queries not configured")
 	}
-	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.Description) == "" || strings.TrimSpace(req.Pattern) == "" || strings.TrimSpace(req.Command) == "" {
-		return Schedule{}, fmt.Errorf("name, description, pattern, command are required")
-	}
-	if err := s.pool.ValidatePattern(req.Pattern); err != nil {
-		return Schedule{}, fmt.Errorf("invalid cron pattern: %w", err)
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.Description) == "" || strings.TrimSpace(req.Command) == "" {
+		return Schedule{}, fmt.Errorf("name, description, command are required")
 	}
 	pgBotID, err := db.ParseUUID(botID)
 	if err != nil {
@@ -86,16 +232,54 @@ func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (
 	if req.Enabled != nil {
 		enabled = *req.Enabled
 	}
+	now := time.Now()
+	t, err := s.createTiming(req, now)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if err := t.validate(s.pool, enabled, now); err != nil {
+		return Schedule{}, err
+	}
+	// A reminder missed during downtime should still go out late rather
+	// than never, so one-shots catch up by default.
+	misfirePolicy := MisfireSkip
+	if t.kind == KindOnce {
+		misfirePolicy = MisfireRunOnce
+	}
+	if strings.TrimSpace(req.MisfirePolicy) != "" {
+		misfirePolicy = strings.TrimSpace(req.MisfirePolicy)
+	}
+	retryMaxAttempts := 1
+	if req.RetryMaxAttempts != nil {
+		retryMaxAttempts = *req.RetryMaxAttempts
+	}
+	retryBackoff := DefaultRetryBackoffSeconds
+	if req.RetryBackoffSeconds != nil {
+		retryBackoff = *req.RetryBackoffSeconds
+	}
+	if err := validatePolicies(misfirePolicy, retryMaxAttempts, retryBackoff); err != nil {
+		return Schedule{}, err
+	}
 	row, err := s.queries.CreateSchedule(ctx, sqlc.CreateScheduleParams{
 		Name:        req.Name,
 		Description: req.Description,
-		Pattern:     req.Pattern,
+		Pattern:     t.pattern,
 		MaxCalls:    maxCalls,
 		Enabled:     enabled,
 		Command:     req.Command,
 		BotID:       pgBotID,
 		Platform:    req.Platform,
 		ReplyTarget: req.ReplyTarget,
+
+		MisfirePolicy:       misfirePolicy,
+		RetryMaxAttempts:    int32(retryMaxAttempts),
+		RetryBackoffSeconds: int32(retryBackoff),
+
+		Kind:            t.kind,
+		RunAt:           t.runAtParam(),
+		IntervalSeconds: t.intervalParam(),
+		StartAt:         t.startAtParam(),
+		Timezone:        t.timezone,
 	})
 	if err != nil {
 		return Schedule{}, err
@@ -108,6 +292,73 @@ func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (
 	return toSchedule(row), nil
 }
 
+// createTiming reads the timing fields of a create request. run_at and
+// start_at without an offset are read in the schedule's timezone.
+func (s *Service) createTiming(req CreateRequest, now time.Time) (timing, error) {
+	t := timing{
+		kind:     strings.TrimSpace(req.Kind),
+		pattern:  strings.TrimSpace(req.Pattern),
+		timezone: strings.TrimSpace(req.Timezone),
+	}
+	if req.IntervalSeconds != nil {
+		t.intervalSeconds = *req.IntervalSeconds
+	}
+	loc := location(s.pool, t.timezone)
+	if strings.TrimSpace(req.RunAt) != "" {
+		runAt, err := parseTime(req.RunAt, loc)
+		if err != nil {
+			return timing{}, fmt.Errorf("run_at: %w", err)
+		}
+		t.runAt = runAt
+	}
+	if strings.TrimSpace(req.StartAt) != "" {
+		startAt, err := parseTime(req.StartAt, loc)
+		if err != nil {
+			return timing{}, fmt.Errorf("start_at: %w", err)
+		}
+		t.startAt = startAt
+	}
+	t.inferKind()
+	t.normalize(now)
+	return t, nil
+}
+
+// updateTiming applies the timing fields of an update request on top of the
+// stored ones.
+func (s *Service) updateTiming(existing sqlc.Schedule, req UpdateRequest, now time.Time) (timing, error) {
+	t := timingFromRow(existing)
+	if req.Kind != nil {
+		t.kind = strings.TrimSpace(*req.Kind)
+	}
+	if req.Pattern != nil {
+		t.pattern = strings.TrimSpace(*req.Pattern)
+	}
+	if req.Timezone != nil {
+		t.timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.IntervalSeconds != nil {
+		t.intervalSeconds = *req.IntervalSeconds
+	}
+	loc := location(s.pool, t.timezone)
+	if req.RunAt != nil {
+		runAt, err := parseTime(*req.RunAt, loc)
+		if err != nil {
+			return timing{}, fmt.Errorf("run_at: %w", err)
+		}
+		t.runAt = runAt
+	}
+	if req.StartAt != nil {
+		startAt, err := parseTime(*req.StartAt, loc)
+		if err != nil {
+			return timing{}, fmt.Errorf("start_at: %w", err)
+		}
+		t.startAt = startAt
+	}
+	t.inferKind()
+	t.normalize(now)
+	return t, nil
+}
+
 func (s *Service) Get(ctx context.Context, id string) (Schedule, error) {
 	pgID, err := db.ParseUUID(id)
 	if err != nil {
@@ -132,11 +383,21 @@ func (s *Service) List(ctx context.Context, botID string) ([]Schedule, error) {
 	if err != nil {
 		return nil, err
 	}
-	items := make([]Schedule, 0, len(rows))
-	for _, row := range rows {
-		items = append(items, toSchedule(row))
+	return toSchedules(rows), nil
+}
+
+// ListArchived returns the bot's one-shot schedules that have already run,
+// most recently archived first.
+func (s *Service) ListArchived(ctx context.Context, botID string) ([]Schedule, error) {
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListArchivedSchedulesByBot(ctx, pgBotID)
+	if err != nil {
+		return nil, err
 	}
-	return items, nil
+	return toSchedules(rows), nil
 }
 
 func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Schedule, error) {
@@ -156,13 +417,6 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Sch
 	if req.Description != nil {
 		description = *req.Description
 	}
-	pattern := existing.Pattern
-	if req.Pattern != nil {
-		if err := s.pool.ValidatePattern(*req.Pattern); err != nil {
-			return Schedule{}, fmt.Errorf("invalid cron pattern: %w", err)
-		}
-		pattern = *req.Pattern
-	}
 	command := existing.Command
 	if req.Command != nil {
 		command = *req.Command
@@ -179,14 +433,48 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Sch
 	if req.Enabled != nil {
 		enabled = *req.Enabled
 	}
+	now := time.Now()
+	t, err := s.updateTiming(existing, req, now)
+	if err != nil {
+		return Schedule{}, err
+	}
+	// Only insist on a future run_at when the update touches it; renaming a
+	// one-shot that is due right now must still work.
+	timingChanged := req.Kind != nil || req.RunAt != nil || req.Enabled != nil
+	if err := t.validate(s.pool, enabled && timingChanged, now); err != nil {
+		return Schedule{}, err
+	}
+	misfirePolicy := existing.MisfirePolicy
+	if req.MisfirePolicy != nil {
+		misfirePolicy = strings.TrimSpace(*req.MisfirePolicy)
+	}
+	retryMaxAttempts := int(existing.RetryMaxAttempts)
+	if req.RetryMaxAttempts != nil {
+		retryMaxAttempts = *req.RetryMaxAttempts
+	}
+	retryBackoff := int(existing.RetryBackoffSeconds)
+	if req.RetryBackoffSeconds != nil {
+		retryBackoff = *req.RetryBackoffSeconds
+	}
+	if err := validatePolicies(misfirePolicy, retryMaxAttempts, retryBackoff); err != nil {
+		return Schedule{}, err
+	}
 	updated, err := s.queries.UpdateSchedule(ctx, sqlc.UpdateScheduleParams{
-		ID:          pgID,
-		Name:        name,
-		Description: description,
-		Pattern:     pattern,
-		MaxCalls:    maxCalls,
-		Enabled:     enabled,
-		Command:     command,
+		ID:                  pgID,
+		Name:                name,
+		Description:         description,
+		Pattern:             t.pattern,
+		MaxCalls:            maxCalls,
+		Enabled:             enabled,
+		Command:             command,
+		MisfirePolicy:       misfirePolicy,
+		RetryMaxAttempts:    int32(retryMaxAttempts),
+		RetryBackoffSeconds: int32(retryBackoff),
+		Kind:                t.kind,
+		RunAt:               t.runAtParam(),
+		IntervalSeconds:     t.intervalParam(),
+		StartAt:             t.startAtParam(),
+		Timezone:            t.timezone,
 	})
 	if err != nil {
 		return Schedule{}, err
@@ -220,22 +508,118 @@ func (s *Service) Trigger(ctx context.Context, scheduleID string) error {
 	if !schedule.Enabled {
 		return fmt.Errorf("schedule is disabled")
 	}
-	return s.runSchedule(ctx, schedule)
+	return s.runSchedule(ctx, schedule, runOptions{triggerType: RunTriggerManual, attempt: 1})
 }
 
-func (s *Service) runSchedule(ctx context.Context, schedule Schedule) error {
-	if s.triggerer == nil {
-		return fmt.Errorf("schedule triggerer not configured")
+// runOptions describes one attempt of a schedule run.
+type runOptions struct {
+	triggerType  string
+	attempt      int
+	scheduledFor time.Time
+}
+
+// runWithRetry runs a schedule under its retry policy, waiting with
+// exponential backoff between attempts. When the last attempt fails the
+// owner is notified.
+func (s *Service) runWithRetry(ctx context.Context, schedule Schedule, triggerType string, scheduledFor time.Time) error {
+	attempts := max(schedule.RetryMaxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := retryDelay(time.Duration(schedule.RetryBackoffSeconds)*time.Second, attempt-1)
+			s.logger.Info("retrying failed schedule run",
+				slog.String("schedule_id", schedule.ID),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+			)
+			if !s.wait(ctx, delay) {
+				return err
+			}
+			// The schedule may have been edited, disabled or deleted while
+			// waiting; retry the current version or give up quietly.
+			current, getErr := s.Get(ctx, schedule.ID)
+			if getErr != nil || !current.Enabled {
+				return err
+			}
+			schedule = current
+		}
+		err = s.runSchedule(ctx, schedule, runOptions{
+			triggerType:  triggerType,
+			attempt:      attempt,
+			scheduledFor: scheduledFor,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	s.notifyFailure(ctx, schedule, attempts, err)
+	return err
+}
+
+// retryDelay returns base doubled for every retry after the first, capped at
+// maxRetryBackoff.
+func retryDelay(base time.Duration, retry int) time.Duration {
+	if base <= 0 {
+		base = DefaultRetryBackoffSeconds * time.Second
+	}
+	delay := base
+	for i := 1; i < retry && delay < maxRetryBackoff; i++ {
+		delay *= 2
 	}
+	return min(delay, maxRetryBackoff)
+}
+
+// wait sleeps for d and reports false if the context or service stopped first.
+func (s *Service) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.stop:
+		return false
+	}
+}
+
+func (s *Service) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
 
+// notifyFailure tells the bot owner a run failed after its last attempt, so a
+// missing report does not go unnoticed.
+func (s *Service) notifyFailure(ctx context.Context, schedule Schedule, attempts int, runErr error) {
+	if s.notifier == nil || runErr == nil {
+		return
+	}
 	ownerUserID, err := automation.ResolveBotOwner(ctx, s.queries, schedule.BotID)
 	if err != nil {
-		return fmt.Errorf("resolve bot owner: %w", err)
+		s.logger.Warn("cannot notify owner about failed schedule run", slog.String("schedule_id", schedule.ID), slog.Any("error", err))
+		return
+	}
+	text := fmt.Sprintf("Scheduled task %q failed after %d attempt(s) and will not be retried.\nLast error: %s",
+		schedule.Name, attempts, truncateError(runErr.Error(), 500))
+	if err := s.notifier.NotifyOwner(ctx, schedule.BotID, ownerUserID, text); err != nil {
+		s.logger.Warn("failed to notify owner about failed schedule run", slog.String("schedule_id", schedule.ID), slog.Any("error", err))
 	}
+}
 
-	token, err := automation.GenerateTriggerToken(ownerUserID, s.jwtSecret, automation.DefaultTriggerTokenTTL)
-	if err != nil {
-		return fmt.Errorf("generate trigger token: %w", err)
+func truncateError(msg string, limit int) string {
+	if len(msg) <= limit {
+		return msg
+	}
+	return msg[:limit] + "..."
+}
+
+func (s *Service) runSchedule(ctx context.Context, schedule Schedule, opts runOptions) error {
+	if s.triggerer == nil {
+		return fmt.Errorf("schedule triggerer not configured")
 	}
 
 	platform := schedule.Platform
@@ -256,9 +640,29 @@ func (s *Service) runSchedule(ctx context.Context, schedule Schedule) error {
 		}
 	}
 
+	runID := s.startRun(ctx, schedule, opts, platform, replyTarget)
+
+	ownerUserID, err := automation.ResolveBotOwner(ctx, s.queries, schedule.BotID)
+	if err != nil {
+		err = fmt.Errorf("resolve bot owner: %w", err)
+		s.finishRun(ctx, runID, TriggerResult{}, err)
+		s.publishRunFailed(schedule, opts, err)
+		return err
+	}
+
+	token, err := automation.GenerateTriggerToken(ownerUserID, s.jwtSecret, automation.DefaultTriggerTokenTTL)
+	if err != nil {
+		err = fmt.Errorf("generate trigger token: %w", err)
+		s.finishRun(ctx, runID, TriggerResult{}, err)
+		s.publishRunFailed(schedule, opts, err)
+		return err
+	}
+
 	s.logger.Info("runSchedule: triggering",
 		slog.String("schedule_id", schedule.ID),
 		slog.String("bot_id", schedule.BotID),
+		slog.String("trigger_type", opts.triggerType),
+		slog.Int("attempt", opts.attempt),
 		slog.String("platform", platform),
 		slog.String("reply_target", replyTarget),
 		slog.String("command", schedule.Command),
@@ -266,22 +670,24 @@ func (s *Service) runSchedule(ctx context.Context, schedule Schedule) error {
 		slog.String("schedule_reply_target", schedule.ReplyTarget),
 	)
 
-	err = s.triggerer.TriggerSchedule(ctx, schedule.BotID, TriggerPayload{
+	result, err := s.triggerer.TriggerSchedule(ctx, schedule.BotID, TriggerPayload{
 		ID:          schedule.ID,
 		Name:        schedule.Name,
 		Description: schedule.Description,
-		Pattern:     schedule.Pattern,
+		Pattern:     timingOf(schedule).describe(),
 		MaxCalls:    schedule.MaxCalls,
 		Command:     schedule.Command,
 		OwnerUserID: ownerUserID,
 		Platform:    platform,
 		ReplyTarget: replyTarget,
 	}, token)
+	s.finishRun(ctx, runID, result, err)
 	if err != nil {
 		s.logger.Warn("runSchedule: trigger failed",
 			slog.String("schedule_id", schedule.ID),
 			slog.Any("error", err),
 		)
+		s.publishRunFailed(schedule, opts, err)
 		return err
 	}
 
@@ -299,25 +705,188 @@ func (s *Service) runSchedule(ctx context.Context, schedule Schedule) error {
 
 	// Publish schedule_completed event so heartbeats with this trigger can fire.
 	if s.hub != nil {
-		s.hub.Publish(msgEvent.Event{
-			Type:  msgEvent.EventTypeScheduleCompleted,
-			BotID: schedule.BotID,
-		})
+		s.hub.Publish(msgEvent.NewEvent(msgEvent.EventTypeScheduleCompleted, schedule.BotID, map[string]any{
+			"schedule_id": schedule.ID,
+			"name":        schedule.Name,
+		}))
 	}
 	return nil
 }
 
+// publishRunFailed reports a failed run attempt. Retries publish again, so
+// subscribers see every attempt.
+func (s *Service) publishRunFailed(schedule Schedule, opts runOptions, runErr error) {
+	if s.hub == nil {
+		return
+	}
+	s.hub.Publish(msgEvent.NewEvent(msgEvent.EventTypeScheduleRunFailed, schedule.BotID, map[string]any{
+		"schedule_id":  schedule.ID,
+		"name":         schedule.Name,
+		"trigger_type": opts.triggerType,
+		"attempt":      opts.attempt,
+		"error":        runErr.Error(),
+	}))
+}
+
+// ListRuns returns the most recent runs of a schedule, newest first.
+func (s *Service) ListRuns(ctx context.Context, scheduleID string, limit int) ([]Run, error) {
+	pgID, err := db.ParseUUID(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListScheduleRunsBySchedule(ctx, sqlc.ListScheduleRunsByScheduleParams{
+		ScheduleID: pgID,
+		Limit:      normalizeRunLimit(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toRuns(rows), nil
+}
+
+// ListRunsByBot returns the most recent runs across all schedules of a bot.
+func (s *Service) ListRunsByBot(ctx context.Context, botID string, limit int) ([]Run, error) {
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListScheduleRunsByBot(ctx, sqlc.ListScheduleRunsByBotParams{
+		BotID: pgBotID,
+		Limit: normalizeRunLimit(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toRuns(rows), nil
+}
+
+func (s *Service) GetRun(ctx context.Context, id string) (Run, error) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return Run{}, err
+	}
+	row, err := s.queries.GetScheduleRunByID(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Run{}, fmt.Errorf("schedule run not found")
+		}
+		return Run{}, err
+	}
+	return toRun(row), nil
+}
+
+// startRun records a new run in the running state. Failures are logged and
+// never block the schedule itself; an invalid ID is returned instead.
+func (s *Service) startRun(ctx context.Context, schedule Schedule, opts runOptions, platform, replyTarget string) pgtype.UUID {
+	if s.queries == nil {
+		return pgtype.UUID{}
+	}
+	scheduledFor := pgtype.Timestamptz{}
+	if !opts.scheduledFor.IsZero() {
+		scheduledFor = pgtype.Timestamptz{Time: opts.scheduledFor, Valid: true}
+	}
+	row, err := s.queries.CreateScheduleRun(ctx, sqlc.CreateScheduleRunParams{
+		ScheduleID:   toUUID(schedule.ID),
+		BotID:        toUUID(schedule.BotID),
+		TriggerType:  opts.triggerType,
+		Platform:     platform,
+		ReplyTarget:  replyTarget,
+		Attempt:      int32(max(opts.attempt, 1)),
+		ScheduledFor: scheduledFor,
+	})
+	if err != nil {
+		s.logger.Warn("failed to record schedule run",
+			slog.String("schedule_id", schedule.ID),
+			slog.Any("error", err),
+		)
+		return pgtype.UUID{}
+	}
+	return row.ID
+}
+
+// finishRun stores the outcome of a run started with startRun.
+func (s *Service) finishRun(ctx context.Context, runID pgtype.UUID, result TriggerResult, runErr error) {
+	if s.queries == nil || !runID.Valid {
+		return
+	}
+	status := RunStatusSuccess
+	errText := ""
+	if runErr != nil {
+		status = RunStatusError
+		errText = runErr.Error()
+	}
+	// The trigger context may already be cancelled when the run fails on a
+	// timeout; the outcome should still be stored.
+	ctx = context.WithoutCancel(ctx)
+	if _, err := s.queries.CompleteScheduleRun(ctx, sqlc.CompleteScheduleRunParams{
+		ID:               runID,
+		Status:           status,
+		Error:            errText,
+		TraceID:          result.TraceID,
+		Model:            result.Model,
+		PromptTokens:     int32(result.PromptTokens),
+		CompletionTokens: int32(result.CompletionTokens),
+		TotalTokens:      int32(result.TotalTokens),
+		ReplyPreview:     result.ReplyPreview,
+	}); err != nil {
+		s.logger.Warn("failed to complete schedule run",
+			slog.String("run_id", runID.String()),
+			slog.Any("error", err),
+		)
+	}
+}
+
 func (s *Service) scheduleJob(schedule sqlc.Schedule) error {
 	id := schedule.ID.String()
 	if id == "" {
 		return fmt.Errorf("schedule id missing")
 	}
-	job := func() {
-		if err := s.runSchedule(context.Background(), toSchedule(schedule)); err != nil {
+	t := timingFromRow(schedule)
+	sched, err := t.schedule(s.pool)
+	if err != nil {
+		return err
+	}
+	job := func() error {
+		firedAt := t.runAt
+		if t.kind != KindOnce {
+			firedAt = lastFireTime(s.pool, sched, time.Now())
+		}
+		err := s.runWithRetry(context.Background(), toSchedule(schedule), RunTriggerCron, firedAt)
+		if err != nil {
 			s.logger.Error("scheduled job failed", slog.String("schedule_id", schedule.ID.String()), slog.Any("error", err))
 		}
+		// A one-shot interrupted by shutdown stays active so that the next
+		// start can catch it up.
+		if t.kind == KindOnce && !s.stopping() {
+			s.archive(context.Background(), id)
+		}
+		return err
+	}
+	s.pool.AddSchedule(id, sched, job)
+	return nil
+}
+
+// lastFireTime returns the most recent fire time of sched at or before now,
+// or the zero time if it did not fire within fireTimeLookback. Cron jobs run
+// right after their fire time, so this is the time the running job is for.
+func lastFireTime(pool *automation.CronPool, sched cron.Schedule, now time.Time) time.Time {
+	times, _ := pool.FireTimesBetween(sched, now.Add(-fireTimeLookback), now, 1)
+	if len(times) == 0 {
+		return time.Time{}
+	}
+	return times[0]
+}
+
+// archive retires a one-shot schedule after its run. Archived schedules are
+// disabled and hidden from List but keep their run history.
+func (s *Service) archive(ctx context.Context, id string) {
+	s.pool.Remove(id)
+	if _, err := s.queries.ArchiveSchedule(ctx, toUUID(id)); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Warn("failed to archive one-shot schedule",
+			slog.String("schedule_id", id),
+			slog.Any("error", err),
+		)
 	}
-	return s.pool.Add(id, schedule.Pattern, job)
 }
 
 func (s *Service) rescheduleJob(schedule sqlc.Schedule) error {
@@ -344,6 +913,29 @@ func toSchedule(row sqlc.Schedule) Schedule {
 		BotID:        row.BotID.String(),
 		Platform:     row.Platform,
 		ReplyTarget:  row.ReplyTarget,
+
+		MisfirePolicy:       row.MisfirePolicy,
+		RetryMaxAttempts:    int(row.RetryMaxAttempts),
+		RetryBackoffSeconds: int(row.RetryBackoffSeconds),
+
+		Kind:     row.Kind,
+		Timezone: row.Timezone,
+	}
+	if row.RunAt.Valid {
+		runAt := row.RunAt.Time
+		item.RunAt = &runAt
+	}
+	if row.IntervalSeconds.Valid {
+		interval := int(row.IntervalSeconds.Int32)
+		item.IntervalSeconds = &interval
+	}
+	if row.StartAt.Valid {
+		startAt := row.StartAt.Time
+		item.StartAt = &startAt
+	}
+	if row.ArchivedAt.Valid {
+		archivedAt := row.ArchivedAt.Time
+		item.ArchivedAt = &archivedAt
 	}
 	if row.MaxCalls.Valid {
 		max := int(row.MaxCalls.Int32)
@@ -358,6 +950,83 @@ func toSchedule(row sqlc.Schedule) Schedule {
 	return item
 }
 
+func toSchedules(rows []sqlc.Schedule) []Schedule {
+	items := make([]Schedule, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toSchedule(row))
+	}
+	return items
+}
+
+func toRuns(rows []sqlc.ScheduleRun) []Run {
+	items := make([]Run, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toRun(row))
+	}
+	return items
+}
+
+func toRun(row sqlc.ScheduleRun) Run {
+	item := Run{
+		ID:               row.ID.String(),
+		ScheduleID:       row.ScheduleID.String(),
+		BotID:            row.BotID.String(),
+		TriggerType:      row.TriggerType,
+		Status:           row.Status,
+		Error:            row.Error,
+		TraceID:          row.TraceID,
+		Model:            row.Model,
+		PromptTokens:     int(row.PromptTokens),
+		CompletionTokens: int(row.CompletionTokens),
+		TotalTokens:      int(row.TotalTokens),
+		ReplyPreview:     row.ReplyPreview,
+		Platform:         row.Platform,
+		ReplyTarget:      row.ReplyTarget,
+		Attempt:          int(row.Attempt),
+	}
+	if row.StartedAt.Valid {
+		item.StartedAt = row.StartedAt.Time
+	}
+	if row.FinishedAt.Valid {
+		finished := row.FinishedAt.Time
+		item.FinishedAt = &finished
+	}
+	if row.DurationMs.Valid {
+		ms := int(row.DurationMs.Int32)
+		item.DurationMs = &ms
+	}
+	if row.ScheduledFor.Valid {
+		scheduledFor := row.ScheduledFor.Time
+		item.ScheduledFor = &scheduledFor
+	}
+	return item
+}
+
+func validatePolicies(misfirePolicy string, retryMaxAttempts, retryBackoffSeconds int) error {
+	switch misfirePolicy {
+	case MisfireSkip, MisfireRunOnce, MisfireRunAll:
+	default:
+		return fmt.Errorf("invalid misfire_policy %q: must be skip, run_once or run_all", misfirePolicy)
+	}
+	if retryMaxAttempts < 1 || retryMaxAttempts > MaxRetryAttempts {
+		return fmt.Errorf("retry_max_attempts must be between 1 and %d", MaxRetryAttempts)
+	}
+	if retryBackoffSeconds < 1 {
+		return fmt.Errorf("retry_backoff_seconds must be positive")
+	}
+	return nil
+}
+
+func normalizeRunLimit(limit int) int32 {
+	if limit <= 0 {
+		return DefaultRunLimit
+	}
+	if limit > MaxRunLimit {
+		return MaxRunLimit
+	}
+	return int32(limit)
+}
+
 // resolveDefaultRoute looks up the bot's channel routes and returns the
 // platform and reply_target of the first (oldest) route. This provides a
 // fallback when a schedule has no platform stored (e.g. created before the
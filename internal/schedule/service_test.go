@@ -2,13 +2,16 @@ package schedule
 
 import (
 	"context"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
 )
 
 type mockTriggerer struct {
@@ -18,12 +21,12 @@ type mockTriggerer struct {
 	token   string
 }
 
-func (m *mockTriggerer) TriggerSchedule(_ context.Context, botID string, payload TriggerPayload, token string) error {
+func (m *mockTriggerer) TriggerSchedule(_ context.Context, botID string, payload TriggerPayload, token string) (TriggerResult, error) {
 	m.called = true
 	m.botID = botID
 	m.payload = payload
 	m.token = token
-	return nil
+	return TriggerResult{}, nil
 }
 
 func TestGenerateTriggerToken(t *testing.T) {
@@ -78,3 +81,146 @@ func TestGenerateTriggerToken_EmptyUserID(t *testing.T) {
 		t.Fatal("expected error for empty user ID")
 	}
 }
+
+func TestToRun(t *testing.T) {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	running := toRun(sqlc.ScheduleRun{
+		Status:    RunStatusRunning,
+		StartedAt: pgtype.Timestamptz{Time: started, Valid: true},
+	})
+	if running.FinishedAt != nil || running.DurationMs != nil {
+		t.Fatalf("running run should have no finish time or duration, got %+v", running)
+	}
+
+	done := toRun(sqlc.ScheduleRun{
+		Status:      RunStatusError,
+		Error:       "gateway timeout",
+		TotalTokens: 42,
+		StartedAt:   pgtype.Timestamptz{Time: started, Valid: true},
+		FinishedAt:  pgtype.Timestamptz{Time: started.Add(1500 * time.Millisecond), Valid: true},
+		DurationMs:  pgtype.Int4{Int32: 1500, Valid: true},
+	})
+	if done.FinishedAt == nil || !done.FinishedAt.Equal(started.Add(1500*time.Millisecond)) {
+		t.Errorf("unexpected finished_at: %v", done.FinishedAt)
+	}
+	if done.DurationMs == nil || *done.DurationMs != 1500 {
+		t.Errorf("unexpected duration_ms: %v", done.DurationMs)
+	}
+	if done.Error != "gateway timeout" || done.TotalTokens != 42 {
+		t.Errorf("unexpected run: %+v", done)
+	}
+}
+
+func TestNormalizeRunLimit(t *testing.T) {
+	cases := map[int]int32{0: DefaultRunLimit, -1: DefaultRunLimit, 5: 5, MaxRunLimit + 1: MaxRunLimit}
+	for in, want := range cases {
+		if got := normalizeRunLimit(in); got != want {
+			t.Errorf("normalizeRunLimit(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestSelectCatchUps(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	missed := []time.Time{base, base.Add(24 * time.Hour), base.Add(48 * time.Hour)}
+
+	if got := selectCatchUps(MisfireSkip, missed); len(got) != 0 {
+		t.Errorf("skip should not catch up, got %v", got)
+	}
+	if got := selectCatchUps(MisfireRunOnce, missed); len(got) != 1 || !got[0].Equal(missed[2]) {
+		t.Errorf("run_once should run the latest missed time, got %v", got)
+	}
+	if got := selectCatchUps(MisfireRunAll, missed); len(got) != 3 {
+		t.Errorf("run_all should run every missed time, got %v", got)
+	}
+	if got := selectCatchUps(MisfireRunAll, nil); got != nil {
+		t.Errorf("nothing missed should run nothing, got %v", got)
+	}
+}
+
+func TestCatchUpSinceReplaysInterruptedOneShot(t *testing.T) {
+	pool := automation.NewCronPool(slog.Default(), time.UTC)
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	runAt := createdAt.Add(time.Hour)
+	once := timing{kind: KindOnce, runAt: runAt}
+	sched, err := once.schedule(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := runAt.Add(2 * time.Hour)
+	missedSince := func(last sqlc.ScheduleRun) []time.Time {
+		times, _ := pool.FireTimesBetween(sched, catchUpSince(once, createdAt, last), now, MaxCatchUpRuns)
+		return times
+	}
+	startedAt := pgtype.Timestamptz{Time: runAt.Add(time.Second), Valid: true}
+	scheduledFor := pgtype.Timestamptz{Time: runAt, Valid: true}
+
+	interrupted := sqlc.ScheduleRun{Status: RunStatusError, Error: runInterruptedReason, StartedAt: startedAt, ScheduledFor: scheduledFor}
+	if got := missedSince(interrupted); len(got) != 1 || !got[0].Equal(runAt) {
+		t.Errorf("interrupted one-shot should be replayed, got %v", got)
+	}
+	interrupted.ScheduledFor = pgtype.Timestamptz{}
+	if got := missedSince(interrupted); len(got) != 1 || !got[0].Equal(runAt) {
+		t.Errorf("interrupted one-shot without scheduled_for should be replayed, got %v", got)
+	}
+	done := sqlc.ScheduleRun{Status: RunStatusSuccess, StartedAt: startedAt, ScheduledFor: scheduledFor}
+	if got := missedSince(done); len(got) != 0 {
+		t.Errorf("completed one-shot should not be replayed, got %v", got)
+	}
+	failed := sqlc.ScheduleRun{Status: RunStatusError, Error: "boom", StartedAt: startedAt, ScheduledFor: scheduledFor}
+	if got := missedSince(failed); len(got) != 0 {
+		t.Errorf("failed one-shot should not be replayed, got %v", got)
+	}
+}
+
+func TestLastFireTime(t *testing.T) {
+	pool := automation.NewCronPool(slog.Default(), time.UTC)
+	sched, err := pool.Parse("0 9 * * *", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fired := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if got := lastFireTime(pool, sched, fired.Add(20*time.Millisecond)); !got.Equal(fired) {
+		t.Errorf("lastFireTime = %v, want %v", got, fired)
+	}
+	if got := lastFireTime(pool, sched, fired.Add(time.Hour)); !got.IsZero() {
+		t.Errorf("lastFireTime long after the fire time = %v, want zero", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := []struct {
+		base  time.Duration
+		retry int
+		want  time.Duration
+	}{
+		{time.Minute, 1, time.Minute},
+		{time.Minute, 2, 2 * time.Minute},
+		{time.Minute, 4, 8 * time.Minute},
+		{time.Minute, 20, maxRetryBackoff},
+		{0, 1, DefaultRetryBackoffSeconds * time.Second},
+	}
+	for _, tc := range cases {
+		if got := retryDelay(tc.base, tc.retry); got != tc.want {
+			t.Errorf("retryDelay(%v, %d) = %v, want %v", tc.base, tc.retry, got, tc.want)
+		}
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	if err := validatePolicies(MisfireRunAll, 3, 30); err != nil {
+		t.Errorf("valid policies rejected: %v", err)
+	}
+	if err := validatePolicies("sometimes", 1, 60); err == nil {
+		t.Error("expected error for unknown misfire policy")
+	}
+	if err := validatePolicies(MisfireSkip, 0, 60); err == nil {
+		t.Error("expected error for zero attempts")
+	}
+	if err := validatePolicies(MisfireSkip, MaxRetryAttempts+1, 60); err == nil {
+		t.Error("expected error for too many attempts")
+	}
+	if err := validatePolicies(MisfireSkip, 1, 0); err == nil {
+		t.Error("expected error for non-positive backoff")
+	}
+}
@@ -0,0 +1,212 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+)
+
+// timing is when a schedule fires: a cron pattern, a single instant or a
+// fixed interval from a start time.
+type timing struct {
+	kind            string
+	pattern         string
+	timezone        string
+	runAt           time.Time
+	startAt         time.Time
+	intervalSeconds int
+}
+
+func timingFromRow(row sqlc.Schedule) timing {
+	t := timing{
+		kind:     row.Kind,
+		pattern:  row.Pattern,
+		timezone: row.Timezone,
+	}
+	if t.kind == "" {
+		t.kind = KindCron
+	}
+	if row.RunAt.Valid {
+		t.runAt = row.RunAt.Time
+	}
+	if row.StartAt.Valid {
+		t.startAt = row.StartAt.Time
+	}
+	if row.IntervalSeconds.Valid {
+		t.intervalSeconds = int(row.IntervalSeconds.Int32)
+	}
+	return t
+}
+
+// timingOf is timingFromRow for the API shape.
+func timingOf(s Schedule) timing {
+	t := timing{kind: s.Kind, pattern: s.Pattern, timezone: s.Timezone}
+	if t.kind == "" {
+		t.kind = KindCron
+	}
+	if s.RunAt != nil {
+		t.runAt = *s.RunAt
+	}
+	if s.StartAt != nil {
+		t.startAt = *s.StartAt
+	}
+	if s.IntervalSeconds != nil {
+		t.intervalSeconds = *s.IntervalSeconds
+	}
+	return t
+}
+
+// inferKind picks the kind from the fields that are set when none was given.
+func (t *timing) inferKind() {
+	if t.kind != "" {
+		return
+	}
+	switch {
+	case !t.runAt.IsZero():
+		t.kind = KindOnce
+	case t.intervalSeconds > 0:
+		t.kind = KindInterval
+	default:
+		t.kind = KindCron
+	}
+}
+
+// normalize clears the fields the kind does not use and defaults the
+// interval start to now.
+func (t *timing) normalize(now time.Time) {
+	switch t.kind {
+	case KindCron:
+		t.runAt, t.startAt, t.intervalSeconds = time.Time{}, time.Time{}, 0
+	case KindOnce:
+		t.pattern, t.startAt, t.intervalSeconds = "", time.Time{}, 0
+	case KindInterval:
+		t.pattern, t.runAt = "", time.Time{}
+		if t.startAt.IsZero() {
+			t.startAt = now.Truncate(time.Second)
+		}
+	}
+}
+
+// validate checks the fields required by the kind. enabled one-shot
+// schedules must lie in the future.
+func (t timing) validate(pool *automation.CronPool, enabled bool, now time.Time) error {
+	if tz := strings.TrimSpace(t.timezone); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid timezone %q", tz)
+		}
+	}
+	switch t.kind {
+	case KindCron:
+		if strings.TrimSpace(t.pattern) == "" {
+			return fmt.Errorf("pattern is required for cron schedules")
+		}
+		if _, err := pool.Parse(t.pattern, t.timezone); err != nil {
+			return fmt.Errorf("invalid cron pattern: %w", err)
+		}
+	case KindOnce:
+		if t.runAt.IsZero() {
+			return fmt.Errorf("run_at is required for one-shot schedules")
+		}
+		if enabled && !t.runAt.After(now) {
+			return fmt.Errorf("run_at must be in the future")
+		}
+	case KindInterval:
+		if t.intervalSeconds < MinIntervalSeconds {
+			return fmt.Errorf("interval_seconds must be at least %d", MinIntervalSeconds)
+		}
+	default:
+		return fmt.Errorf("invalid kind %q: must be cron, once or interval", t.kind)
+	}
+	return nil
+}
+
+// schedule builds what the cron pool runs.
+func (t timing) schedule(pool *automation.CronPool) (cron.Schedule, error) {
+	switch t.kind {
+	case KindOnce:
+		return automation.OnceSchedule{At: t.runAt}, nil
+	case KindInterval:
+		if t.intervalSeconds <= 0 {
+			return nil, fmt.Errorf("interval_seconds must be positive")
+		}
+		return automation.IntervalSchedule{Start: t.startAt, Every: time.Duration(t.intervalSeconds) * time.Second}, nil
+	default:
+		return pool.Parse(t.pattern, t.timezone)
+	}
+}
+
+// describe renders the timing for the agent, which only knows cron
+// patterns.
+func (t timing) describe() string {
+	switch t.kind {
+	case KindOnce:
+		return "once at " + t.runAt.Format(time.RFC3339)
+	case KindInterval:
+		return fmt.Sprintf("every %s from %s", time.Duration(t.intervalSeconds)*time.Second, t.startAt.Format(time.RFC3339))
+	}
+	if t.timezone != "" {
+		return t.pattern + " (" + t.timezone + ")"
+	}
+	return t.pattern
+}
+
+func (t timing) runAtParam() pgtype.Timestamptz {
+	return optionalTimestamptz(t.runAt)
+}
+
+func (t timing) startAtParam() pgtype.Timestamptz {
+	return optionalTimestamptz(t.startAt)
+}
+
+func (t timing) intervalParam() pgtype.Int4 {
+	if t.intervalSeconds <= 0 {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(t.intervalSeconds), Valid: true}
+}
+
+func optionalTimestamptz(v time.Time) pgtype.Timestamptz {
+	if v.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: v, Valid: true}
+}
+
+// localTimeLayouts are accepted for run_at and start_at besides RFC 3339.
+// They carry no offset and are read in the schedule's timezone.
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseTime reads an RFC 3339 timestamp, or a local date and time in loc.
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD HH:MM", value)
+}
+
+// location returns the schedule's timezone, or the pool's when it has none.
+func location(pool *automation.CronPool, timezone string) *time.Location {
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return pool.Location()
+}
@@ -0,0 +1,110 @@
+package schedule
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/automation"
+)
+
+func TestParseTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	want := time.Date(2026, 3, 1, 9, 30, 0, 0, shanghai)
+
+	for _, value := range []string{"2026-03-01T09:30:00+08:00", "2026-03-01 09:30", "2026-03-01T09:30:00"} {
+		got, err := parseTime(value, shanghai)
+		if err != nil {
+			t.Errorf("parseTime(%q): %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseTime(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if _, err := parseTime("tomorrow at nine", shanghai); err == nil {
+		t.Error("expected error for free text")
+	}
+}
+
+func TestTimingInferAndNormalize(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	once := timing{pattern: "* * * * *", runAt: now.Add(time.Hour)}
+	once.inferKind()
+	once.normalize(now)
+	if once.kind != KindOnce || once.pattern != "" {
+		t.Errorf("run_at should make a one-shot without pattern, got %+v", once)
+	}
+
+	interval := timing{intervalSeconds: 5400}
+	interval.inferKind()
+	interval.normalize(now)
+	if interval.kind != KindInterval || !interval.startAt.Equal(now) {
+		t.Errorf("interval should start now by default, got %+v", interval)
+	}
+
+	cron := timing{kind: KindCron, pattern: "0 9 * * *", runAt: now, intervalSeconds: 60}
+	cron.normalize(now)
+	if !cron.runAt.IsZero() || cron.intervalSeconds != 0 {
+		t.Errorf("cron should drop one-shot and interval fields, got %+v", cron)
+	}
+}
+
+func TestTimingValidate(t *testing.T) {
+	pool := automation.NewCronPool(slog.Default(), time.UTC)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		timing  timing
+		enabled bool
+		wantErr bool
+	}{
+		{"cron", timing{kind: KindCron, pattern: "0 9 * * *"}, true, false},
+		{"cron with timezone", timing{kind: KindCron, pattern: "0 9 * * *", timezone: "Europe/Berlin"}, true, false},
+		{"cron without pattern", timing{kind: KindCron}, true, true},
+		{"bad pattern", timing{kind: KindCron, pattern: "every day"}, true, true},
+		{"bad timezone", timing{kind: KindCron, pattern: "0 9 * * *", timezone: "Mars/Olympus"}, true, true},
+		{"once in future", timing{kind: KindOnce, runAt: now.Add(time.Minute)}, true, false},
+		{"once in past", timing{kind: KindOnce, runAt: now.Add(-time.Minute)}, true, true},
+		{"disabled once in past", timing{kind: KindOnce, runAt: now.Add(-time.Minute)}, false, false},
+		{"once without run_at", timing{kind: KindOnce}, true, true},
+		{"interval", timing{kind: KindInterval, intervalSeconds: 5400}, true, false},
+		{"interval too short", timing{kind: KindInterval, intervalSeconds: 30}, true, true},
+		{"unknown kind", timing{kind: "sometimes"}, true, true},
+	}
+	for _, tc := range cases {
+		err := tc.timing.validate(pool, tc.enabled, now)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestTimingSchedule(t *testing.T) {
+	pool := automation.NewCronPool(slog.Default(), time.UTC)
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	interval, err := timing{kind: KindInterval, intervalSeconds: 5400, startAt: start}.schedule(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := interval.Next(start); !got.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("interval next = %v", got)
+	}
+
+	once, err := timing{kind: KindOnce, runAt: start}.schedule(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := once.Next(start.Add(-time.Hour)); !got.Equal(start) {
+		t.Errorf("once next = %v", got)
+	}
+	if got := once.Next(start); !got.IsZero() {
+		t.Errorf("once should not fire again, got %v", got)
+	}
+}
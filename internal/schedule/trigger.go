@@ -15,7 +15,17 @@ type TriggerPayload struct {
 	ReplyTarget string
 }
 
+// TriggerResult describes the outcome of a triggered schedule run.
+type TriggerResult struct {
+	TraceID          string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	ReplyPreview     string
+}
+
 // Triggerer triggers schedule execution for chat-related jobs.
 type Triggerer interface {
-	TriggerSchedule(ctx context.Context, botID string, payload TriggerPayload, token string) error
+	TriggerSchedule(ctx context.Context, botID string, payload TriggerPayload, token string) (TriggerResult, error)
 }
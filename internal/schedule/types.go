@@ -19,6 +19,25 @@ type Schedule struct {
 	BotID        string    `json:"bot_id"`
 	Platform     string    `json:"platform,omitempty"`
 	ReplyTarget  string    `json:"reply_target,omitempty"`
+	// MisfirePolicy decides what happens to fire times missed while the
+	// server was down: skip, run_once or run_all.
+	MisfirePolicy string `json:"misfire_policy"`
+	// RetryMaxAttempts is the total number of attempts for a failed run,
+	// including the first one.
+	RetryMaxAttempts int `json:"retry_max_attempts"`
+	// RetryBackoffSeconds is the delay before the first retry; it doubles
+	// with every further attempt.
+	RetryBackoffSeconds int `json:"retry_backoff_seconds"`
+	// Kind is cron (Pattern), once (RunAt) or interval (IntervalSeconds
+	// from StartAt).
+	Kind            string     `json:"kind"`
+	RunAt           *time.Time `json:"run_at,omitempty"`
+	IntervalSeconds *int       `json:"interval_seconds,omitempty"`
+	StartAt         *time.Time `json:"start_at,omitempty"`
+	// Timezone is the IANA zone cron patterns are read in; empty means the
+	// server timezone.
+	Timezone   string     `json:"timezone,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 type NullableInt struct {
@@ -54,12 +73,25 @@ func (n *NullableInt) UnmarshalJSON(data []byte) error {
 type CreateRequest struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
-	Pattern     string      `json:"pattern"`
+	Pattern     string      `json:"pattern,omitempty"`
 	MaxCalls    NullableInt `json:"max_calls,omitempty"`
 	Command     string      `json:"command"`
 	Enabled     *bool       `json:"enabled,omitempty"`
 	Platform    string      `json:"platform,omitempty"`
 	ReplyTarget string      `json:"reply_target,omitempty"`
+	// MisfirePolicy defaults to skip.
+	MisfirePolicy       string `json:"misfire_policy,omitempty"`
+	RetryMaxAttempts    *int   `json:"retry_max_attempts,omitempty"`
+	RetryBackoffSeconds *int   `json:"retry_backoff_seconds,omitempty"`
+
+	// Kind defaults to once when RunAt is set, interval when
+	// IntervalSeconds is set and cron otherwise. RunAt and StartAt take
+	// RFC 3339 or a local "YYYY-MM-DD HH:MM" read in Timezone.
+	Kind            string `json:"kind,omitempty"`
+	RunAt           string `json:"run_at,omitempty"`
+	IntervalSeconds *int   `json:"interval_seconds,omitempty"`
+	StartAt         string `json:"start_at,omitempty"`
+	Timezone        string `json:"timezone,omitempty"`
 }
 
 type UpdateRequest struct {
@@ -69,8 +101,75 @@ type UpdateRequest struct {
 	MaxCalls    NullableInt `json:"max_calls,omitempty"`
 	Command     *string     `json:"command,omitempty"`
 	Enabled     *bool       `json:"enabled,omitempty"`
+
+	MisfirePolicy       *string `json:"misfire_policy,omitempty"`
+	RetryMaxAttempts    *int    `json:"retry_max_attempts,omitempty"`
+	RetryBackoffSeconds *int    `json:"retry_backoff_seconds,omitempty"`
+
+	Kind            *string `json:"kind,omitempty"`
+	RunAt           *string `json:"run_at,omitempty"`
+	IntervalSeconds *int    `json:"interval_seconds,omitempty"`
+	StartAt         *string `json:"start_at,omitempty"`
+	Timezone        *string `json:"timezone,omitempty"`
 }
 
 type ListResponse struct {
 	Items []Schedule `json:"items"`
 }
+
+const (
+	KindCron     = "cron"
+	KindOnce     = "once"
+	KindInterval = "interval"
+
+	MinIntervalSeconds = 60
+
+	RunTriggerCron    = "cron"
+	RunTriggerManual  = "manual"
+	RunTriggerCatchUp = "catchup"
+
+	RunStatusRunning = "running"
+	RunStatusSuccess = "success"
+	RunStatusError   = "error"
+
+	DefaultRunLimit = 20
+	MaxRunLimit     = 200
+
+	MisfireSkip    = "skip"
+	MisfireRunOnce = "run_once"
+	MisfireRunAll  = "run_all"
+
+	DefaultRetryBackoffSeconds = 60
+	MaxRetryAttempts           = 10
+	// MaxCatchUpRuns caps how many missed fire times run_all replays after
+	// a long outage; older ones are dropped.
+	MaxCatchUpRuns = 24
+)
+
+// Run is one execution of a schedule and its outcome.
+type Run struct {
+	ID               string     `json:"id"`
+	ScheduleID       string     `json:"schedule_id"`
+	BotID            string     `json:"bot_id"`
+	TriggerType      string     `json:"trigger_type"`
+	Status           string     `json:"status"`
+	Error            string     `json:"error,omitempty"`
+	TraceID          string     `json:"trace_id,omitempty"`
+	Model            string     `json:"model,omitempty"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	TotalTokens      int        `json:"total_tokens"`
+	ReplyPreview     string     `json:"reply_preview,omitempty"`
+	Platform         string     `json:"platform,omitempty"`
+	ReplyTarget      string     `json:"reply_target,omitempty"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	DurationMs       *int       `json:"duration_ms,omitempty"`
+	Attempt          int        `json:"attempt"`
+	// ScheduledFor is the missed fire time a catch-up run stands in for.
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+}
+
+type ListRunsResponse struct {
+	Items []Run `json:"items"`
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
 	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
 )
 
 type Service struct {
@@ -100,6 +101,10 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (GetResponse, e
 	if err != nil {
 		return GetResponse{}, fmt.Errorf("marshal config: %w", err)
 	}
+	configJSON, err = secrets.SealJSON(configJSON)
+	if err != nil {
+		return GetResponse{}, fmt.Errorf("encrypt config: %w", err)
+	}
 	row, err := s.queries.CreateSearchProvider(ctx, sqlc.CreateSearchProviderParams{
 		Name:     strings.TrimSpace(req.Name),
 		Provider: string(req.Provider),
@@ -128,7 +133,14 @@ func (s *Service) GetRawByID(ctx context.Context, id string) (sqlc.SearchProvide
 	if err != nil {
 		return sqlc.SearchProvider{}, err
 	}
-	return s.queries.GetSearchProviderByID(ctx, pgID)
+	row, err := s.queries.GetSearchProviderByID(ctx, pgID)
+	if err != nil {
+		return sqlc.SearchProvider{}, err
+	}
+	if row.Config, err = secrets.OpenJSON(row.Config); err != nil {
+		return sqlc.SearchProvider{}, fmt.Errorf("decrypt config: %w", err)
+	}
+	return row, nil
 }
 
 func (s *Service) List(ctx context.Context, provider string) ([]GetResponse, error) {
@@ -178,7 +190,10 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Get
 		if marshalErr != nil {
 			return GetResponse{}, fmt.Errorf("marshal config: %w", marshalErr)
 		}
-		config = configJSON
+		config, err = secrets.SealJSON(configJSON)
+		if err != nil {
+			return GetResponse{}, fmt.Errorf("encrypt config: %w", err)
+		}
 	}
 	updated, err := s.queries.UpdateSearchProvider(ctx, sqlc.UpdateSearchProviderParams{
 		ID:       pgID,
@@ -202,8 +217,13 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 
 func (s *Service) toGetResponse(row sqlc.SearchProvider) GetResponse {
 	var cfg map[string]any
-	if len(row.Config) > 0 {
-		if err := json.Unmarshal(row.Config, &cfg); err != nil {
+	raw, err := secrets.OpenJSON(row.Config)
+	if err != nil {
+		s.logger.Warn("search provider config decrypt failed", slog.String("id", row.ID.String()), slog.Any("error", err))
+		raw = nil
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &cfg); err != nil {
 			s.logger.Warn("search provider config unmarshal failed", slog.String("id", row.ID.String()), slog.Any("error", err))
 		}
 	}
@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
+)
+
+// LoadKeyring builds a keyring from config. It returns nil, nil when no
+// master key is configured.
+func LoadKeyring(cfg config.SecretsConfig) (*Keyring, error) {
+	primary, err := resolveKey(cfg.MasterKey, cfg.MasterKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("master key: %w", err)
+	}
+	var previous [][]byte
+	for _, raw := range cfg.PreviousKeys {
+		key, err := decodeKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("previous key: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	for _, path := range cfg.PreviousKeyFiles {
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("previous key file: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	if primary == nil {
+		if len(previous) > 0 {
+			return nil, fmt.Errorf("previous keys are set but master key is missing")
+		}
+		return nil, nil
+	}
+	return NewKeyring(primary, previous...)
+}
+
+func resolveKey(inline, path string) ([]byte, error) {
+	inline = strings.TrimSpace(inline)
+	path = strings.TrimSpace(path)
+	switch {
+	case inline != "" && path != "":
+		return nil, fmt.Errorf("set either master_key or master_key_file, not both")
+	case inline != "":
+		return decodeKey(inline)
+	case path != "":
+		return readKeyFile(path)
+	}
+	return nil, nil
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey(string(data))
+}
+
+func decodeKey(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(raw); err == nil {
+			if len(key) != KeySize {
+				return nil, fmt.Errorf("key must decode to %d bytes, got %d", KeySize, len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key is not valid base64")
+}
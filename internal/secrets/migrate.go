@@ -0,0 +1,200 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Kind is how a column stores its secret.
+type Kind int
+
+const (
+	// KindText is a TEXT column holding a single sealed string.
+	KindText Kind = iota
+	// KindJSON is a JSONB column sealed as a whole with SealJSON.
+	KindJSON
+	// KindJSONFields is a JSONB object whose listed fields are sealed with
+	// SealFields.
+	KindJSONFields
+)
+
+// Column is a database column that holds credentials.
+type Column struct {
+	Table  string
+	Column string
+	Kind   Kind
+	Fields []string
+}
+
+// Mode selects which values Reencrypt touches.
+type Mode int
+
+const (
+	// ModeEncrypt only encrypts plaintext values.
+	ModeEncrypt Mode = iota
+	// ModeRotate also rewraps values sealed with a previous master key.
+	ModeRotate
+)
+
+// Result counts the rows Reencrypt looked at and changed in one column.
+type Result struct {
+	Table   string
+	Column  string
+	Scanned int
+	Updated int
+}
+
+// Reencrypt brings the given columns under the keyring's primary key. Rows
+// are updated one by one and only if they did not change in the meantime,
+// so it is safe to run against a live server. Rows that fail to decrypt are
+// logged and skipped.
+func Reencrypt(ctx context.Context, pool *pgxpool.Pool, k *Keyring, columns []Column, mode Mode, log *slog.Logger) ([]Result, error) {
+	if !k.Enabled() {
+		return nil, errors.New("no master key configured")
+	}
+	if log == nil {
+		log = slog.Default()
+	}
+	results := make([]Result, 0, len(columns))
+	for _, col := range columns {
+		res, err := reencryptColumn(ctx, pool, k, col, mode, log)
+		if err != nil {
+			return results, fmt.Errorf("%s.%s: %w", col.Table, col.Column, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func reencryptColumn(ctx context.Context, pool *pgxpool.Pool, k *Keyring, col Column, mode Mode, log *slog.Logger) (Result, error) {
+	res := Result{Table: col.Table, Column: col.Column}
+	rows, err := pool.Query(ctx, fmt.Sprintf(`SELECT id::text, %s::text FROM %s`, col.Column, col.Table))
+	if err != nil {
+		return res, err
+	}
+	type pending struct{ id, old, updated string }
+	var updates []pending
+	for rows.Next() {
+		var id, value string
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return res, err
+		}
+		res.Scanned++
+		updated, changed, err := reencryptValue(k, col, value, mode)
+		if err != nil {
+			log.Warn("skipping credential that cannot be re-encrypted",
+				slog.String("table", col.Table), slog.String("id", id), slog.Any("error", err))
+			continue
+		}
+		if changed {
+			updates = append(updates, pending{id: id, old: value, updated: updated})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	cast := ""
+	if col.Kind != KindText {
+		cast = "::jsonb"
+	}
+	stmt := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = $2%[3]s WHERE id = $1::uuid AND %[2]s = $3%[3]s`, col.Table, col.Column, cast)
+	for _, u := range updates {
+		tag, err := pool.Exec(ctx, stmt, u.id, u.updated, u.old)
+		if err != nil {
+			return res, err
+		}
+		res.Updated += int(tag.RowsAffected())
+	}
+	return res, nil
+}
+
+func reencryptValue(k *Keyring, col Column, value string, mode Mode) (string, bool, error) {
+	switch col.Kind {
+	case KindText:
+		if !needsUpdate(k, value, mode) {
+			return value, false, nil
+		}
+		updated, err := k.Rewrap(value)
+		return updated, err == nil, err
+	case KindJSON:
+		if sealed, ok := sealedJSONValue([]byte(value)); ok {
+			if !needsUpdate(k, sealed, mode) {
+				return value, false, nil
+			}
+			rewrapped, err := k.Rewrap(sealed)
+			if err != nil {
+				return "", false, err
+			}
+			out, err := json.Marshal(map[string]string{sealedField: rewrapped})
+			return string(out), err == nil, err
+		}
+		if isEmptyJSON(value) {
+			return value, false, nil
+		}
+		out, err := k.SealJSON([]byte(value))
+		return string(out), err == nil, err
+	case KindJSONFields:
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(value), &doc); err != nil || doc == nil {
+			return value, false, nil
+		}
+		changed := false
+		for _, field := range col.Fields {
+			v, ok := doc[field]
+			if !ok || v == nil {
+				continue
+			}
+			if s, isString := v.(string); isString && IsSealed(s) {
+				if !needsUpdate(k, s, mode) {
+					continue
+				}
+				rewrapped, err := k.Rewrap(s)
+				if err != nil {
+					return "", false, err
+				}
+				doc[field] = rewrapped
+				changed = true
+				continue
+			}
+			if err := k.SealFields(doc, field); err != nil {
+				return "", false, err
+			}
+			changed = true
+		}
+		if !changed {
+			return value, false, nil
+		}
+		out, err := json.Marshal(doc)
+		return string(out), err == nil, err
+	}
+	return "", false, fmt.Errorf("unknown column kind %d", col.Kind)
+}
+
+func needsUpdate(k *Keyring, value string, mode Mode) bool {
+	if mode == ModeRotate {
+		return k.NeedsUpdate(value)
+	}
+	return value != "" && !IsSealed(value)
+}
+
+func isEmptyJSON(value string) bool {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return true
+	}
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(t) == 0
+	}
+	return false
+}
@@ -0,0 +1,395 @@
+// Package secrets encrypts credentials stored in the database.
+//
+// Values use envelope encryption: each value is sealed with a fresh data key
+// under AES-256-GCM, and the data key is wrapped with the master key. Rotating
+// the master key only rewraps data keys. Sealed strings look like
+//
+//	enc:v1:<key id>:<wrapped data key>:<ciphertext>
+//
+// Values without the prefix are treated as legacy plaintext, so rows written
+// before encryption was enabled keep working until they are migrated.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	prefix = "enc:v1:"
+	// KeySize is the length of master keys in bytes.
+	KeySize = 32
+	// sealedField is the JSON key that holds a sealed JSON document.
+	sealedField = "$sealed"
+)
+
+var (
+	// ErrNoKey is returned when a sealed value is read without a master key.
+	ErrNoKey = errors.New("secrets: value is encrypted but no master key is configured")
+	// ErrUnknownKey is returned when a value was sealed with a key that is
+	// neither the master key nor one of the previous keys.
+	ErrUnknownKey = errors.New("secrets: value was encrypted with an unknown master key")
+	// ErrMalformed is returned for values with the prefix but a broken body.
+	ErrMalformed = errors.New("secrets: malformed encrypted value")
+)
+
+type masterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring holds the master key that seals new values and the previous keys
+// that can still open old ones. A nil Keyring stores values in plaintext.
+type Keyring struct {
+	primary *masterKey
+	keys    map[string]*masterKey
+}
+
+// NewKeyring creates a keyring from raw 32-byte keys.
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	k := &Keyring{keys: map[string]*masterKey{}}
+	pk, err := newMasterKey(primary)
+	if err != nil {
+		return nil, err
+	}
+	k.primary = pk
+	k.keys[pk.id] = pk
+	for _, raw := range previous {
+		mk, err := newMasterKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("previous key: %w", err)
+		}
+		if _, ok := k.keys[mk.id]; !ok {
+			k.keys[mk.id] = mk
+		}
+	}
+	return k, nil
+}
+
+func newMasterKey(raw []byte) (*masterKey, error) {
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(raw))
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return &masterKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// GenerateKey returns a new random master key, base64 encoded.
+func GenerateKey() (string, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// Enabled reports whether new values are encrypted.
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.primary != nil
+}
+
+// PrimaryID returns the ID of the key that seals new values.
+func (k *Keyring) PrimaryID() string {
+	if !k.Enabled() {
+		return ""
+	}
+	return k.primary.id
+}
+
+// IsSealed reports whether a string is an encrypted value.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Seal encrypts a value. Empty and already sealed values are returned as-is,
+// and so is everything when no master key is configured.
+func (k *Keyring) Seal(plaintext string) (string, error) {
+	if !k.Enabled() || plaintext == "" || IsSealed(plaintext) {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.primary.aead, dataKey, []byte(k.primary.id))
+	if err != nil {
+		return "", err
+	}
+	return prefix + k.primary.id + ":" + encode(wrapped) + ":" + encode(ciphertext), nil
+}
+
+// Open decrypts a sealed value. Plaintext values are returned unchanged.
+func (k *Keyring) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	env, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAEAD, env.ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsUpdate reports whether a value is plaintext or sealed with a key other
+// than the primary one.
+func (k *Keyring) NeedsUpdate(value string) bool {
+	if !k.Enabled() || value == "" {
+		return false
+	}
+	if !IsSealed(value) {
+		return true
+	}
+	env, err := parse(value)
+	return err == nil && env.keyID != k.primary.id
+}
+
+// Rewrap brings a value under the primary key. Plaintext is sealed; values
+// sealed with a previous key get their data key rewrapped while the
+// ciphertext stays the same.
+func (k *Keyring) Rewrap(value string) (string, error) {
+	if !k.Enabled() {
+		return value, nil
+	}
+	if !IsSealed(value) {
+		return k.Seal(value)
+	}
+	env, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	if env.keyID == k.primary.id {
+		return value, nil
+	}
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.primary.aead, dataKey, []byte(k.primary.id))
+	if err != nil {
+		return "", err
+	}
+	return prefix + k.primary.id + ":" + encode(wrapped) + ":" + encode(env.ciphertext), nil
+}
+
+func (k *Keyring) unwrap(env envelope) ([]byte, error) {
+	if k == nil || len(k.keys) == 0 {
+		return nil, ErrNoKey
+	}
+	mk, ok := k.keys[env.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, env.keyID)
+	}
+	dataKey, err := open(mk.aead, env.wrappedKey, []byte(mk.id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return dataKey, nil
+}
+
+// SealJSON encrypts a JSON document and returns it wrapped as
+// {"$sealed": "enc:v1:..."} so it still fits a JSONB column.
+func (k *Keyring) SealJSON(raw []byte) ([]byte, error) {
+	if !k.Enabled() || len(raw) == 0 || isSealedJSON(raw) {
+		return raw, nil
+	}
+	sealed, err := k.Seal(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]string{sealedField: sealed})
+}
+
+// OpenJSON reverses SealJSON. Documents that are not sealed are returned
+// unchanged.
+func (k *Keyring) OpenJSON(raw []byte) ([]byte, error) {
+	sealed, ok := sealedJSONValue(raw)
+	if !ok {
+		return raw, nil
+	}
+	plaintext, err := k.Open(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(plaintext), nil
+}
+
+// SealFields encrypts the listed top-level fields of a map in place. Each
+// field's value is marshalled to JSON and replaced by a sealed string.
+func (k *Keyring) SealFields(m map[string]any, fields ...string) error {
+	if !k.Enabled() {
+		return nil
+	}
+	for _, field := range fields {
+		v, ok := m[field]
+		if !ok || v == nil {
+			continue
+		}
+		if s, isString := v.(string); isString && IsSealed(s) {
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", field, err)
+		}
+		sealed, err := k.Seal(string(raw))
+		if err != nil {
+			return err
+		}
+		m[field] = sealed
+	}
+	return nil
+}
+
+// OpenFields decrypts fields sealed by SealFields in place.
+func (k *Keyring) OpenFields(m map[string]any, fields ...string) error {
+	for _, field := range fields {
+		s, ok := m[field].(string)
+		if !ok || !IsSealed(s) {
+			continue
+		}
+		plaintext, err := k.Open(s)
+		if err != nil {
+			return fmt.Errorf("decrypt %s: %w", field, err)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(plaintext), &v); err != nil {
+			return fmt.Errorf("decode %s: %w", field, err)
+		}
+		m[field] = v
+	}
+	return nil
+}
+
+type envelope struct {
+	keyID      string
+	wrappedKey []byte
+	ciphertext []byte
+}
+
+func parse(value string) (envelope, error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return envelope{}, ErrMalformed
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return envelope{}, ErrMalformed
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return envelope{}, ErrMalformed
+	}
+	return envelope{keyID: parts[0], wrappedKey: wrapped, ciphertext: ciphertext}, nil
+}
+
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func sealedJSONValue(raw []byte) (string, bool) {
+	if !strings.Contains(string(raw), sealedField) {
+		return "", false
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil || len(doc) != 1 {
+		return "", false
+	}
+	var sealed string
+	if err := json.Unmarshal(doc[sealedField], &sealed); err != nil || !IsSealed(sealed) {
+		return "", false
+	}
+	return sealed, true
+}
+
+func isSealedJSON(raw []byte) bool {
+	_, ok := sealedJSONValue(raw)
+	return ok
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefault installs the keyring used by the package-level helpers. It is
+// called once at startup before any credential is read.
+func SetDefault(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// Default returns the keyring installed with SetDefault, or nil.
+func Default() *Keyring {
+	return defaultKeyring.Load()
+}
+
+// Seal encrypts a value with the default keyring.
+func Seal(plaintext string) (string, error) { return Default().Seal(plaintext) }
+
+// Open decrypts a value with the default keyring.
+func Open(value string) (string, error) { return Default().Open(value) }
+
+// SealJSON encrypts a JSON document with the default keyring.
+func SealJSON(raw []byte) ([]byte, error) { return Default().SealJSON(raw) }
+
+// OpenJSON decrypts a JSON document with the default keyring.
+func OpenJSON(raw []byte) ([]byte, error) { return Default().OpenJSON(raw) }
+
+// SealFields encrypts map fields with the default keyring.
+func SealFields(m map[string]any, fields ...string) error { return Default().SealFields(m, fields...) }
+
+// OpenFields decrypts map fields with the default keyring.
+func OpenFields(m map[string]any, fields ...string) error { return Default().OpenFields(m, fields...) }
@@ -0,0 +1,195 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	k, err := NewKeyring(testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := k.Seal("sk-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "sk-secret") {
+		t.Fatalf("value not sealed: %q", sealed)
+	}
+	again, _ := k.Seal("sk-secret")
+	if again == sealed {
+		t.Fatal("sealing twice should use fresh data keys and nonces")
+	}
+	if resealed, _ := k.Seal(sealed); resealed != sealed {
+		t.Fatal("sealed values should not be sealed twice")
+	}
+	plain, err := k.Open(sealed)
+	if err != nil || plain != "sk-secret" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if plain, err := k.Open("legacy-plaintext"); err != nil || plain != "legacy-plaintext" {
+		t.Fatalf("plaintext should pass through, got %q, %v", plain, err)
+	}
+	if empty, _ := k.Seal(""); empty != "" {
+		t.Fatalf("empty values should stay empty, got %q", empty)
+	}
+}
+
+func TestNilKeyring(t *testing.T) {
+	var k *Keyring
+	if k.Enabled() {
+		t.Fatal("nil keyring should be disabled")
+	}
+	if v, err := k.Seal("plain"); err != nil || v != "plain" {
+		t.Fatalf("Seal without key = %q, %v", v, err)
+	}
+	sealed, _ := mustKeyring(t, testKey(1)).Seal("x")
+	if _, err := k.Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("expected ErrNoKey, got %v", err)
+	}
+}
+
+func TestTamperedValueFails(t *testing.T) {
+	k := mustKeyring(t, testKey(1))
+	sealed, _ := k.Seal("sk-secret")
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	if _, err := k.Open(tampered); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey := mustKeyring(t, testKey(1))
+	sealed, _ := oldKey.Seal("bot-token")
+
+	newOnly := mustKeyring(t, testKey(2))
+	if _, err := newOnly.Open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+
+	rotating, err := NewKeyring(testKey(2), testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rotating.NeedsUpdate(sealed) {
+		t.Fatal("value sealed with the previous key should need an update")
+	}
+	rewrapped, err := rotating.Rewrap(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotating.NeedsUpdate(rewrapped) {
+		t.Fatal("rewrapped value should be under the primary key")
+	}
+	if plain, err := newOnly.Open(rewrapped); err != nil || plain != "bot-token" {
+		t.Fatalf("new key should open rewrapped value, got %q, %v", plain, err)
+	}
+	oldCiphertext := sealed[strings.LastIndex(sealed, ":"):]
+	if !strings.HasSuffix(rewrapped, oldCiphertext) {
+		t.Fatal("rewrap should keep the ciphertext and only rewrap the data key")
+	}
+}
+
+func TestSealJSON(t *testing.T) {
+	k := mustKeyring(t, testKey(1))
+	raw := []byte(`{"botToken":"123:abc"}`)
+	sealed, err := k.SealJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("123:abc")) || !json.Valid(sealed) {
+		t.Fatalf("unexpected sealed document: %s", sealed)
+	}
+	opened, err := k.OpenJSON(sealed)
+	if err != nil || string(opened) != string(raw) {
+		t.Fatalf("OpenJSON = %s, %v", opened, err)
+	}
+	if opened, _ := k.OpenJSON(raw); string(opened) != string(raw) {
+		t.Fatalf("plain documents should pass through, got %s", opened)
+	}
+}
+
+func TestSealFields(t *testing.T) {
+	k := mustKeyring(t, testKey(1))
+	m := map[string]any{
+		"url":     "https://mcp.example.com",
+		"headers": map[string]any{"Authorization": "Bearer xyz"},
+	}
+	if err := k.SealFields(m, "headers", "env"); err != nil {
+		t.Fatal(err)
+	}
+	if m["url"] != "https://mcp.example.com" {
+		t.Fatal("unlisted fields must stay in plaintext")
+	}
+	if s, ok := m["headers"].(string); !ok || !IsSealed(s) {
+		t.Fatalf("headers not sealed: %#v", m["headers"])
+	}
+	if _, ok := m["env"]; ok {
+		t.Fatal("missing fields must not be added")
+	}
+	if err := k.OpenFields(m, "headers", "env"); err != nil {
+		t.Fatal(err)
+	}
+	headers, ok := m["headers"].(map[string]any)
+	if !ok || headers["Authorization"] != "Bearer xyz" {
+		t.Fatalf("headers not restored: %#v", m["headers"])
+	}
+}
+
+func TestReencryptValue(t *testing.T) {
+	oldKey := mustKeyring(t, testKey(1))
+	sealedOld, _ := oldKey.Seal("sk-old")
+	k, err := NewKeyring(testKey(2), testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := Column{Kind: KindText}
+
+	if _, changed, _ := reencryptValue(k, text, sealedOld, ModeEncrypt); changed {
+		t.Fatal("encrypt mode must leave sealed values alone")
+	}
+	updated, changed, err := reencryptValue(k, text, sealedOld, ModeRotate)
+	if err != nil || !changed || k.NeedsUpdate(updated) {
+		t.Fatalf("rotate mode should rewrap, got changed=%v err=%v", changed, err)
+	}
+	if _, changed, _ := reencryptValue(k, Column{Kind: KindJSON}, `{}`, ModeEncrypt); changed {
+		t.Fatal("empty documents should not be sealed")
+	}
+	doc, changed, err := reencryptValue(k, Column{Kind: KindJSONFields, Fields: []string{"env"}}, `{"command":"npx","env":{"TOKEN":"t"}}`, ModeEncrypt)
+	if err != nil || !changed || strings.Contains(doc, `"TOKEN"`) || !strings.Contains(doc, `"npx"`) {
+		t.Fatalf("unexpected field encryption: %s, %v", doc, err)
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeKey(key + "\n"); err != nil {
+		t.Fatalf("generated key should decode: %v", err)
+	}
+	if _, err := decodeKey("c2hvcnQ="); err == nil {
+		t.Fatal("short keys should be rejected")
+	}
+}
+
+func mustKeyring(t *testing.T, key []byte) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/metrics"
+)
+
+var (
+	httpRequestDuration = metrics.NewHistogram("memoh_http_request_duration_seconds",
+		"HTTP request latency by route template.", nil, "method", "route", "code")
+	httpRequestsInFlight = metrics.NewGauge("memoh_http_requests_in_flight",
+		"HTTP requests currently being served.")
+)
+
+// metricsMiddleware records request latency per route template. Using the
+// template (e.g. /bots/:bot_id) rather than the URL keeps label cardinality
+// bounded.
+func metricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			httpRequestsInFlight.Add(1)
+			defer httpRequestsInFlight.Add(-1)
+
+			err := next(c)
+			code := c.Response().Status
+			if err != nil {
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					code = he.Code
+				} else if !c.Response().Committed {
+					code = http.StatusInternalServerError
+				}
+			}
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			httpRequestDuration.ObserveSince(start, c.Request().Method, route, strconv.Itoa(code))
+			return err
+		}
+	}
+}
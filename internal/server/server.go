@@ -21,7 +21,7 @@ type Handler interface {
 	Register(e *echo.Echo)
 }
 
-func NewServer(log *slog.Logger, addr string, jwtSecret string,
+func NewServer(log *slog.Logger, addr string, jwtSecret string, tokens auth.TokenAuthenticator,
 	handlers ...Handler,
 ) *Server {
 	if addr == "" {
@@ -31,6 +31,8 @@ func NewServer(log *slog.Logger, addr string, jwtSecret string,
 	e := echo.New()
 	e.HideBanner = true
 	e.Use(middleware.Recover())
+	e.Use(metricsMiddleware())
+	e.Use(tracingMiddleware())
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogStatus: true,
 		LogURI:    true,
@@ -46,18 +48,35 @@ func NewServer(log *slog.Logger, addr string, jwtSecret string,
 			return nil
 		},
 	}))
+	// Personal access tokens and bot API keys are resolved before JWTs. The
+	// OpenAI-compatible API checks its bot keys itself.
+	e.Use(auth.APITokenMiddleware(tokens, func(c echo.Context) bool {
+		return strings.HasPrefix(c.Request().URL.Path, "/v1/")
+	}))
 	e.Use(auth.JWTMiddleware(jwtSecret, func(c echo.Context) bool {
+		if auth.IsAPITokenRequest(c) {
+			return true
+		}
 		path := c.Request().URL.Path
-		if path == "/ping" || path == "/health" || path == "/api/swagger.json" || path == "/auth/login" {
+		if path == "/ping" || path == "/health" || path == "/api/swagger.json" || path == "/auth/login" || path == "/metrics" {
 			return true
 		}
 		if strings.HasPrefix(path, "/api/docs") {
 			return true
 		}
+		// Login method discovery and the SSO redirect flow run before the
+		// user has a token.
+		if path == "/auth/config" || strings.HasPrefix(path, "/auth/oidc/") {
+			return true
+		}
 		// Webhook endpoints use their own API key authentication, skip JWT.
 		if strings.HasPrefix(path, "/channels/") && strings.Contains(path, "/webhook/") {
 			return true
 		}
+		// The OpenAI-compatible API authenticates with bot API keys.
+		if strings.HasPrefix(path, "/v1/") {
+			return true
+		}
 		return false
 	}))
 
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/tracing"
+)
+
+// tracingMiddleware starts a server span per request, continuing any W3C
+// trace context sent by the caller. Tool calls made by the agent gateway
+// thereby join the trace of the conversation that triggered them.
+func tracingMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			ctx := tracing.Extract(req.Context(), req.Header)
+			ctx, span := tracing.Start(ctx, "HTTP "+req.Method+" "+route,
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", route),
+			)
+			c.SetRequest(req.WithContext(ctx))
+			err := next(c)
+			span.SetAttributes(attribute.Int("http.response.status_code", c.Response().Status))
+			tracing.End(span, err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Egress modes. Bots without a stored policy are unrestricted.
+const (
+	EgressModeUnrestricted = "unrestricted"
+	EgressModeAllowlist    = "allowlist"
+	EgressModeNone         = "none"
+)
+
+const maxEgressEntries = 256
+
+var egressDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// EgressPolicy controls where a bot's container may connect to.
+type EgressPolicy struct {
+	Mode           string     `json:"mode"`
+	AllowedDomains []string   `json:"allowed_domains"`
+	AllowedCIDRs   []string   `json:"allowed_cidrs"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultEgressPolicy is the policy of bots that have none stored.
+func DefaultEgressPolicy() EgressPolicy {
+	return EgressPolicy{Mode: EgressModeUnrestricted, AllowedDomains: []string{}, AllowedCIDRs: []string{}}
+}
+
+// Normalize trims and lowercases entries and drops empty ones.
+func (p EgressPolicy) Normalize() EgressPolicy {
+	p.Mode = strings.ToLower(strings.TrimSpace(p.Mode))
+	if p.Mode == "" {
+		p.Mode = EgressModeUnrestricted
+	}
+	p.AllowedDomains = normalizeEgressEntries(p.AllowedDomains, true)
+	p.AllowedCIDRs = normalizeEgressEntries(p.AllowedCIDRs, false)
+	return p
+}
+
+// Validate checks the mode and allowlist entries. CIDRs may be IPv4 or
+// IPv6; a bare address stands for a single host.
+func (p EgressPolicy) Validate() error {
+	switch p.Mode {
+	case EgressModeUnrestricted, EgressModeNone:
+	case EgressModeAllowlist:
+		if len(p.AllowedDomains) == 0 && len(p.AllowedCIDRs) == 0 {
+			return errors.New("allowlist mode needs at least one allowed domain or CIDR")
+		}
+	default:
+		return fmt.Errorf("invalid egress mode %q", p.Mode)
+	}
+	if len(p.AllowedDomains)+len(p.AllowedCIDRs) > maxEgressEntries {
+		return fmt.Errorf("at most %d allowlist entries are supported", maxEgressEntries)
+	}
+	for _, domain := range p.AllowedDomains {
+		if !egressDomainPattern.MatchString(domain) {
+			return fmt.Errorf("invalid domain %q", domain)
+		}
+	}
+	for _, raw := range p.AllowedCIDRs {
+		if !strings.Contains(raw, "/") {
+			if net.ParseIP(raw) == nil {
+				return fmt.Errorf("invalid CIDR %q", raw)
+			}
+			continue
+		}
+		if _, _, err := net.ParseCIDR(raw); err != nil {
+			return fmt.Errorf("invalid CIDR %q", raw)
+		}
+	}
+	return nil
+}
+
+func normalizeEgressEntries(entries []string, lower bool) []string {
+	out := make([]string, 0, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if lower {
+			entry = strings.TrimSuffix(strings.ToLower(entry), ".")
+		}
+		if entry == "" {
+			continue
+		}
+		if _, ok := seen[entry]; ok {
+			continue
+		}
+		seen[entry] = struct{}{}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// EgressPolicyStore persists per-bot egress policies.
+type EgressPolicyStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewEgressPolicyStore creates a store backed by the bot_egress_policies table.
+func NewEgressPolicyStore(log *slog.Logger, pool *pgxpool.Pool) *EgressPolicyStore {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &EgressPolicyStore{
+		pool:   pool,
+		logger: log.With(slog.String("service", "egress_policy")),
+	}
+}
+
+// Get returns the policy of a bot, or the default policy when none is stored.
+func (s *EgressPolicyStore) Get(ctx context.Context, botID string) (EgressPolicy, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return EgressPolicy{}, errors.New("bot_id is required")
+	}
+	var (
+		policy         EgressPolicy
+		domains, cidrs []byte
+		updatedAt      time.Time
+	)
+	err := s.pool.QueryRow(ctx, `
+		SELECT mode, allowed_domains, allowed_cidrs, updated_at
+		FROM bot_egress_policies WHERE bot_id = $1`, botID,
+	).Scan(&policy.Mode, &domains, &cidrs, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return DefaultEgressPolicy(), nil
+		}
+		return EgressPolicy{}, fmt.Errorf("query egress policy: %w", err)
+	}
+	if err := json.Unmarshal(domains, &policy.AllowedDomains); err != nil {
+		return EgressPolicy{}, fmt.Errorf("decode allowed domains: %w", err)
+	}
+	if err := json.Unmarshal(cidrs, &policy.AllowedCIDRs); err != nil {
+		return EgressPolicy{}, fmt.Errorf("decode allowed cidrs: %w", err)
+	}
+	policy = policy.Normalize()
+	policy.UpdatedAt = &updatedAt
+	return policy, nil
+}
+
+// Upsert validates and stores the policy of a bot.
+func (s *EgressPolicyStore) Upsert(ctx context.Context, botID string, policy EgressPolicy) (EgressPolicy, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return EgressPolicy{}, errors.New("bot_id is required")
+	}
+	policy = policy.Normalize()
+	if err := policy.Validate(); err != nil {
+		return EgressPolicy{}, err
+	}
+	domains, err := json.Marshal(policy.AllowedDomains)
+	if err != nil {
+		return EgressPolicy{}, err
+	}
+	cidrs, err := json.Marshal(policy.AllowedCIDRs)
+	if err != nil {
+		return EgressPolicy{}, err
+	}
+	var updatedAt time.Time
+	err = s.pool.QueryRow(ctx, `
+		INSERT INTO bot_egress_policies (bot_id, mode, allowed_domains, allowed_cidrs, updated_at)
+		VALUES ($1, $2, $3, $4, now())
+		ON CONFLICT (bot_id) DO UPDATE SET
+			mode = EXCLUDED.mode,
+			allowed_domains = EXCLUDED.allowed_domains,
+			allowed_cidrs = EXCLUDED.allowed_cidrs,
+			updated_at = now()
+		RETURNING updated_at`,
+		botID, policy.Mode, domains, cidrs,
+	).Scan(&updatedAt)
+	if err != nil {
+		return EgressPolicy{}, fmt.Errorf("upsert egress policy: %w", err)
+	}
+	policy.UpdatedAt = &updatedAt
+	return policy, nil
+}
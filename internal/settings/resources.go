@@ -0,0 +1,119 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Bounds for resource limits. They reject obvious typos (e.g. memory given in
+// bytes) rather than describe what a host can actually provide.
+const (
+	minMemoryMB      = 64
+	maxCPUShares     = 262144
+	minCPUMillicores = 10
+	minPidsLimit     = 16
+)
+
+// ResourceLimits is the per-bot container resource profile. A zero field
+// leaves that resource unlimited.
+type ResourceLimits struct {
+	// CPUShares is the relative CPU weight against other bots (default 1024).
+	CPUShares int `json:"cpu_shares"`
+	// CPUMillicores caps CPU time; 1000 is one full core.
+	CPUMillicores int `json:"cpu_millicores"`
+	MemoryMB      int        `json:"memory_mb"`
+	PidsLimit     int        `json:"pids_limit"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks that the limits are within sane bounds.
+func (l ResourceLimits) Validate() error {
+	switch {
+	case l.CPUShares < 0 || l.CPUMillicores < 0 || l.MemoryMB < 0 || l.PidsLimit < 0:
+		return errors.New("resource limits must not be negative")
+	case l.CPUShares > maxCPUShares:
+		return fmt.Errorf("cpu_shares must be at most %d", maxCPUShares)
+	case l.CPUMillicores > 0 && l.CPUMillicores < minCPUMillicores:
+		return fmt.Errorf("cpu_millicores must be at least %d", minCPUMillicores)
+	case l.MemoryMB > 0 && l.MemoryMB < minMemoryMB:
+		return fmt.Errorf("memory_mb must be at least %d", minMemoryMB)
+	case l.PidsLimit > 0 && l.PidsLimit < minPidsLimit:
+		return fmt.Errorf("pids_limit must be at least %d", minPidsLimit)
+	}
+	return nil
+}
+
+// ResourceLimitsStore persists per-bot resource limits.
+type ResourceLimitsStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewResourceLimitsStore creates a store backed by the bot_resource_limits table.
+func NewResourceLimitsStore(log *slog.Logger, pool *pgxpool.Pool) *ResourceLimitsStore {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &ResourceLimitsStore{
+		pool:   pool,
+		logger: log.With(slog.String("service", "resource_limits")),
+	}
+}
+
+// Get returns the limits of a bot, or zero limits when none are stored.
+func (s *ResourceLimitsStore) Get(ctx context.Context, botID string) (ResourceLimits, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return ResourceLimits{}, errors.New("bot_id is required")
+	}
+	var limits ResourceLimits
+	var updatedAt time.Time
+	err := s.pool.QueryRow(ctx, `
+		SELECT cpu_shares, cpu_millicores, memory_mb, pids_limit, updated_at
+		FROM bot_resource_limits WHERE bot_id = $1`, botID,
+	).Scan(&limits.CPUShares, &limits.CPUMillicores, &limits.MemoryMB, &limits.PidsLimit, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ResourceLimits{}, nil
+		}
+		return ResourceLimits{}, fmt.Errorf("query resource limits: %w", err)
+	}
+	limits.UpdatedAt = &updatedAt
+	return limits, nil
+}
+
+// Upsert stores the limits of a bot.
+func (s *ResourceLimitsStore) Upsert(ctx context.Context, botID string, limits ResourceLimits) (ResourceLimits, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return ResourceLimits{}, errors.New("bot_id is required")
+	}
+	if err := limits.Validate(); err != nil {
+		return ResourceLimits{}, err
+	}
+	var updatedAt time.Time
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO bot_resource_limits (bot_id, cpu_shares, cpu_millicores, memory_mb, pids_limit, updated_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		ON CONFLICT (bot_id) DO UPDATE SET
+			cpu_shares = EXCLUDED.cpu_shares,
+			cpu_millicores = EXCLUDED.cpu_millicores,
+			memory_mb = EXCLUDED.memory_mb,
+			pids_limit = EXCLUDED.pids_limit,
+			updated_at = now()
+		RETURNING updated_at`,
+		botID, limits.CPUShares, limits.CPUMillicores, limits.MemoryMB, limits.PidsLimit,
+	).Scan(&updatedAt)
+	if err != nil {
+		return ResourceLimits{}, fmt.Errorf("upsert resource limits: %w", err)
+	}
+	limits.UpdatedAt = &updatedAt
+	return limits, nil
+}
@@ -0,0 +1,261 @@
+// Package sso implements single sign-on with an OpenID Connect provider
+// using the authorization code flow with PKCE.
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/accounts"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
+)
+
+const (
+	defaultName              = "SSO"
+	defaultPostLoginRedirect = "/login"
+	httpTimeout              = 15 * time.Second
+)
+
+var defaultScopes = []string{oidc.ScopeOpenID, "profile", "email"}
+
+// ErrDisabled is returned when single sign-on is not configured.
+var ErrDisabled = errors.New("single sign-on is not enabled")
+
+// Provider signs users in with the configured identity provider. The
+// provider's discovery document is fetched on first use, so the server
+// starts even while the provider is unreachable.
+type Provider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewProvider creates a Provider from the [auth.oidc] configuration.
+func NewProvider(log *slog.Logger, cfg config.OIDCConfig) *Provider {
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: httpTimeout},
+		logger: log.With(slog.String("service", "sso")),
+	}
+}
+
+// Enabled reports whether single sign-on is configured.
+func (p *Provider) Enabled() bool {
+	return p != nil && p.cfg.Enabled
+}
+
+// Name is the label of the login button.
+func (p *Provider) Name() string {
+	if name := strings.TrimSpace(p.cfg.Name); name != "" {
+		return name
+	}
+	return defaultName
+}
+
+// PasswordLoginEnabled reports whether local username/password login is
+// allowed. It can only be turned off while single sign-on is enabled.
+func (p *Provider) PasswordLoginEnabled() bool {
+	return !p.Enabled() || !p.cfg.DisablePasswordLogin
+}
+
+// PostLoginRedirect is where the browser is sent after the callback.
+func (p *Provider) PostLoginRedirect() string {
+	if target := strings.TrimSpace(p.cfg.PostLoginRedirect); target != "" {
+		return target
+	}
+	return defaultPostLoginRedirect
+}
+
+// SecureCookies reports whether the callback is served over HTTPS, in which
+// case the state cookie is marked secure.
+func (p *Provider) SecureCookies() bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(p.cfg.RedirectURL)), "https://")
+}
+
+// AuthURL returns the provider URL the browser is sent to.
+func (p *Provider) AuthURL(ctx context.Context, st LoginState) (string, error) {
+	oauthCfg, _, err := p.oauth(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauthCfg.AuthCodeURL(st.State,
+		oauth2.S256ChallengeOption(st.Verifier),
+		oidc.Nonce(st.Nonce),
+	), nil
+}
+
+// Exchange redeems the authorization code and verifies the ID token. It
+// returns the login to provision or sign in.
+func (p *Provider) Exchange(ctx context.Context, code string, st LoginState) (accounts.ExternalLogin, error) {
+	oauthCfg, provider, err := p.oauth(ctx)
+	if err != nil {
+		return accounts.ExternalLogin{}, err
+	}
+	ctx = oidc.ClientContext(ctx, p.client)
+	token, err := oauthCfg.Exchange(ctx, code, oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		return accounts.ExternalLogin{}, fmt.Errorf("exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return accounts.ExternalLogin{}, fmt.Errorf("token response has no id_token")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return accounts.ExternalLogin{}, fmt.Errorf("verify id_token: %w", err)
+	}
+	if idToken.Nonce != st.Nonce {
+		return accounts.ExternalLogin{}, fmt.Errorf("id_token nonce mismatch")
+	}
+	claims := map[string]any{}
+	if err := idToken.Claims(&claims); err != nil {
+		return accounts.ExternalLogin{}, fmt.Errorf("decode id_token claims: %w", err)
+	}
+	// Some providers only put groups and profile fields in userinfo.
+	if p.cfg.RoleClaim != "" && lookupClaim(claims, p.cfg.RoleClaim) == nil && provider.UserInfoEndpoint() != "" {
+		info, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			p.logger.Warn("fetch userinfo failed", slog.Any("error", err))
+		} else {
+			extra := map[string]any{}
+			if err := info.Claims(&extra); err == nil {
+				for k, v := range extra {
+					if _, ok := claims[k]; !ok {
+						claims[k] = v
+					}
+				}
+			}
+		}
+	}
+	return p.login(idToken.Issuer, idToken.Subject, claims), nil
+}
+
+// login maps verified claims onto an account login.
+func (p *Provider) login(issuer, subject string, claims map[string]any) accounts.ExternalLogin {
+	usernameClaim := strings.TrimSpace(p.cfg.UsernameClaim)
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	role, syncRole := p.role(claims)
+	return accounts.ExternalLogin{
+		Issuer:        issuer,
+		Subject:       subject,
+		Email:         claimString(claims, "email"),
+		EmailVerified: claimBool(claims, "email_verified"),
+		Username:      claimString(claims, usernameClaim),
+		DisplayName:   claimString(claims, "name"),
+		AvatarURL:     claimString(claims, "picture"),
+		Role:          role,
+		SyncRole:      syncRole,
+		LinkByEmail:   p.cfg.LinkByEmail,
+	}
+}
+
+// role maps the role claim: admin when it contains one of the admin values,
+// the default role otherwise. The second result reports whether a role claim
+// is configured, in which case the provider decides the role on every login.
+func (p *Provider) role(claims map[string]any) (string, bool) {
+	claim := strings.TrimSpace(p.cfg.RoleClaim)
+	if claim == "" {
+		return p.cfg.DefaultRole, false
+	}
+	for _, value := range claimValues(lookupClaim(claims, claim)) {
+		for _, admin := range p.cfg.AdminValues {
+			if strings.EqualFold(value, strings.TrimSpace(admin)) {
+				return "admin", true
+			}
+		}
+	}
+	return p.cfg.DefaultRole, true
+}
+
+// oauth discovers the provider on first use.
+func (p *Provider) oauth(ctx context.Context) (oauth2.Config, *oidc.Provider, error) {
+	if !p.Enabled() {
+		return oauth2.Config{}, nil, ErrDisabled
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider == nil {
+		provider, err := oidc.NewProvider(oidc.ClientContext(ctx, p.client), strings.TrimSpace(p.cfg.Issuer))
+		if err != nil {
+			return oauth2.Config{}, nil, fmt.Errorf("discover oidc provider: %w", err)
+		}
+		p.provider = provider
+	}
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	return oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     p.provider.Endpoint(),
+		Scopes:       scopes,
+	}, p.provider, nil
+}
+
+// lookupClaim reads a claim, following dots into nested objects such as
+// realm_access.roles.
+func lookupClaim(claims map[string]any, name string) any {
+	if v, ok := claims[name]; ok {
+		return v
+	}
+	var current any = claims
+	for _, part := range strings.Split(name, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// claimValues returns a string or list claim as strings.
+func claimValues(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return val
+	}
+	return nil
+}
+
+func claimString(claims map[string]any, name string) string {
+	s, _ := lookupClaim(claims, name).(string)
+	return strings.TrimSpace(s)
+}
+
+// claimBool accepts booleans and the "true" strings some providers send.
+func claimBool(claims map[string]any, name string) bool {
+	switch v := lookupClaim(claims, name).(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
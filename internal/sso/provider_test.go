@@ -0,0 +1,331 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/config"
+)
+
+const (
+	testClientID = "memoh"
+	testKeyID    = "test-key"
+)
+
+// mockIdP is a minimal OpenID Connect provider: discovery, JWKS and a token
+// endpoint that enforces PKCE.
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	idp := &mockIdP{t: t, key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	base := idp.server.URL
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                base,
+		"authorization_endpoint":                base + "/authorize",
+		"token_endpoint":                        base + "/token",
+		"jwks_uri":                              base + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := idp.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	if r.PostForm.Get("code") != "good-code" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant", "error_description": "pkce verification failed"})
+		return
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "user-123",
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": idp.nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		idp.t.Errorf("sign id_token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+// authorize plays the browser and the provider's login page: it records the
+// PKCE challenge and nonce of the authorization URL.
+func (idp *mockIdP) authorize(t *testing.T, authURL string) url.Values {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	if !strings.HasPrefix(authURL, idp.server.URL+"/authorize?") {
+		t.Fatalf("auth url = %q, want the provider authorization endpoint", authURL)
+	}
+	q := u.Query()
+	idp.mu.Lock()
+	idp.challenge = q.Get("code_challenge")
+	idp.nonce = q.Get("nonce")
+	idp.mu.Unlock()
+	return q
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func testProvider(idp *mockIdP, mutate func(*config.OIDCConfig)) *Provider {
+	cfg := config.OIDCConfig{
+		Enabled:      true,
+		Issuer:       idp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://memoh.test/api/auth/oidc/callback",
+		RoleClaim:    "groups",
+		AdminValues:  []string{"memoh-admins"},
+		DefaultRole:  "member",
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+	return NewProvider(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})), cfg)
+}
+
+func TestProviderLoginFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.claims = jwt.MapClaims{
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"groups":             []string{"staff", "memoh-admins"},
+	}
+	p := testProvider(idp, nil)
+	ctx := context.Background()
+
+	st, err := NewLoginState()
+	if err != nil {
+		t.Fatalf("NewLoginState: %v", err)
+	}
+	authURL, err := p.AuthURL(ctx, st)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	q := idp.authorize(t, authURL)
+	if q.Get("state") != st.State || q.Get("client_id") != testClientID || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected auth url query: %v", q)
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge") == st.Verifier {
+		t.Fatalf("code_challenge must be derived from the verifier, got %q", q.Get("code_challenge"))
+	}
+
+	login, err := p.Exchange(ctx, "good-code", st)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if login.Issuer != idp.server.URL || login.Subject != "user-123" {
+		t.Fatalf("identity = %s/%s", login.Issuer, login.Subject)
+	}
+	if login.Email != "alice@example.com" || !login.EmailVerified || login.Username != "alice" || login.DisplayName != "Alice" {
+		t.Fatalf("unexpected profile: %+v", login)
+	}
+	if login.Role != "admin" || !login.SyncRole {
+		t.Fatalf("role = %q sync = %v, want admin synced", login.Role, login.SyncRole)
+	}
+}
+
+func TestProviderExchangeRejectsWrongVerifierAndNonce(t *testing.T) {
+	idp := newMockIdP(t)
+	p := testProvider(idp, nil)
+	ctx := context.Background()
+
+	st, err := NewLoginState()
+	if err != nil {
+		t.Fatalf("NewLoginState: %v", err)
+	}
+	authURL, err := p.AuthURL(ctx, st)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	idp.authorize(t, authURL)
+
+	wrongVerifier := st
+	wrongVerifier.Verifier = "not-the-verifier-not-the-verifier-not-the-verifier"
+	if _, err := p.Exchange(ctx, "good-code", wrongVerifier); err == nil {
+		t.Fatal("expected PKCE failure")
+	}
+	wrongNonce := st
+	wrongNonce.Nonce = "other"
+	if _, err := p.Exchange(ctx, "good-code", wrongNonce); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("expected nonce mismatch, got %v", err)
+	}
+	if _, err := p.Exchange(ctx, "bad-code", st); err == nil {
+		t.Fatal("expected invalid code to fail")
+	}
+}
+
+func TestProviderRoleMapping(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      config.OIDCConfig
+		claims   map[string]any
+		wantRole string
+		wantSync bool
+	}{
+		{
+			name:     "no role claim uses default",
+			cfg:      config.OIDCConfig{DefaultRole: "member"},
+			claims:   map[string]any{"groups": []any{"memoh-admins"}},
+			wantRole: "member",
+		},
+		{
+			name:     "list claim",
+			cfg:      config.OIDCConfig{RoleClaim: "groups", AdminValues: []string{"Memoh-Admins"}},
+			claims:   map[string]any{"groups": []any{"staff", "memoh-admins"}},
+			wantRole: "admin",
+			wantSync: true,
+		},
+		{
+			name:     "string claim without admin value",
+			cfg:      config.OIDCConfig{RoleClaim: "role", AdminValues: []string{"admin"}, DefaultRole: "member"},
+			claims:   map[string]any{"role": "viewer"},
+			wantRole: "member",
+			wantSync: true,
+		},
+		{
+			name:     "nested claim",
+			cfg:      config.OIDCConfig{RoleClaim: "realm_access.roles", AdminValues: []string{"memoh-admin"}},
+			claims:   map[string]any{"realm_access": map[string]any{"roles": []any{"memoh-admin"}}},
+			wantRole: "admin",
+			wantSync: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Provider{cfg: tc.cfg}
+			role, syncRole := p.role(tc.claims)
+			if role != tc.wantRole || syncRole != tc.wantSync {
+				t.Fatalf("role() = %q, %v; want %q, %v", role, syncRole, tc.wantRole, tc.wantSync)
+			}
+		})
+	}
+}
+
+func TestProviderPasswordLogin(t *testing.T) {
+	if !(&Provider{cfg: config.OIDCConfig{DisablePasswordLogin: true}}).PasswordLoginEnabled() {
+		t.Fatal("password login can only be disabled while SSO is enabled")
+	}
+	if (&Provider{cfg: config.OIDCConfig{Enabled: true, DisablePasswordLogin: true}}).PasswordLoginEnabled() {
+		t.Fatal("password login should be disabled")
+	}
+	var p *Provider
+	if p.Enabled() || !p.PasswordLoginEnabled() {
+		t.Fatal("nil provider should leave password login on")
+	}
+}
+
+func TestLoginStateRoundTrip(t *testing.T) {
+	st, err := NewLoginState()
+	if err != nil {
+		t.Fatalf("NewLoginState: %v", err)
+	}
+	now := time.Now()
+	value, err := EncodeState(st, "secret", now)
+	if err != nil {
+		t.Fatalf("EncodeState: %v", err)
+	}
+	got, err := DecodeState(value, "secret")
+	if err != nil {
+		t.Fatalf("DecodeState: %v", err)
+	}
+	if got != st {
+		t.Fatalf("DecodeState = %+v, want %+v", got, st)
+	}
+	if _, err := DecodeState(value, "other-secret"); err == nil {
+		t.Fatal("expected signature check to fail")
+	}
+	expired, err := EncodeState(st, "secret", now.Add(-2*StateTTL))
+	if err != nil {
+		t.Fatalf("EncodeState: %v", err)
+	}
+	if _, err := DecodeState(expired, "secret"); err == nil {
+		t.Fatal("expected expired state to fail")
+	}
+}
@@ -0,0 +1,88 @@
+package sso
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// StateCookie holds the signed login state between the redirect to the
+	// provider and the callback.
+	StateCookie = "memoh_sso_state"
+	// StateTTL is how long a login may take at the provider.
+	StateTTL = 10 * time.Minute
+
+	stateTokenType = "sso_state"
+)
+
+// LoginState ties a callback to the browser that started the login: the
+// state parameter, the ID token nonce and the PKCE code verifier.
+type LoginState struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// NewLoginState generates fresh random values.
+func NewLoginState() (LoginState, error) {
+	state, err := randomString()
+	if err != nil {
+		return LoginState{}, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return LoginState{}, err
+	}
+	return LoginState{State: state, Nonce: nonce, Verifier: oauth2.GenerateVerifier()}, nil
+}
+
+// EncodeState signs the state for the cookie with the JWT secret, so no
+// server-side session is needed.
+func EncodeState(st LoginState, secret string, now time.Time) (string, error) {
+	if secret == "" {
+		return "", fmt.Errorf("jwt secret is required")
+	}
+	claims := jwt.MapClaims{
+		"typ":      stateTokenType,
+		"state":    st.State,
+		"nonce":    st.Nonce,
+		"verifier": st.Verifier,
+		"exp":      now.Add(StateTTL).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// DecodeState verifies and reads a state cookie.
+func DecodeState(value, secret string) (LoginState, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(value, claims, func(*jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return LoginState{}, fmt.Errorf("invalid login state: %w", err)
+	}
+	if typ, _ := claims["typ"].(string); typ != stateTokenType {
+		return LoginState{}, fmt.Errorf("invalid login state")
+	}
+	st := LoginState{}
+	st.State, _ = claims["state"].(string)
+	st.Nonce, _ = claims["nonce"].(string)
+	st.Verifier, _ = claims["verifier"].(string)
+	if st.State == "" || st.Nonce == "" || st.Verifier == "" {
+		return LoginState{}, fmt.Errorf("invalid login state")
+	}
+	return st, nil
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
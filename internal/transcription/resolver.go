@@ -0,0 +1,175 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/bots"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/models"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
+)
+
+// MaxAudioBytes is the upload limit of the OpenAI transcription API; larger
+// clips are rejected before they are sent.
+const MaxAudioBytes = 25 << 20
+
+// botModelMetadataKey names the bot metadata entry that pins a transcription
+// model (by model_id) for one bot. Without it the first transcription model
+// is used.
+const botModelMetadataKey = "transcription_model"
+
+// ErrNoModel is returned when no transcription model is configured.
+var ErrNoModel = errors.New("no transcription models available")
+
+// Audio is one clip to transcribe. Data is used when present; otherwise the
+// clip is downloaded from URL.
+type Audio struct {
+	Data []byte
+	URL  string
+	Name string
+	Mime string
+}
+
+type Resolver struct {
+	modelsService *models.Service
+	queries       *sqlc.Queries
+	bots          *bots.Service
+	timeout       time.Duration
+	logger        *slog.Logger
+	http          *http.Client
+}
+
+func NewResolver(log *slog.Logger, modelsService *models.Service, queries *sqlc.Queries, botsService *bots.Service, timeout time.Duration) *Resolver {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &Resolver{
+		modelsService: modelsService,
+		queries:       queries,
+		bots:          botsService,
+		timeout:       timeout,
+		logger:        log.With(slog.String("service", "transcription")),
+		http:          &http.Client{Timeout: timeout},
+	}
+}
+
+// Transcribe converts audio to text with the bot's transcription model.
+func (r *Resolver) Transcribe(ctx context.Context, botID string, audio Audio) (Result, error) {
+	selected, err := r.selectModel(ctx, botID)
+	if err != nil {
+		return Result{}, err
+	}
+	provider, err := r.fetchProvider(ctx, selected.LlmProviderID)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(audio.Data) == 0 {
+		data, err := r.download(ctx, audio.URL)
+		if err != nil {
+			return Result{}, err
+		}
+		audio.Data = data
+	}
+	transcriber, err := NewOpenAITranscriber(r.logger, provider.ApiKey, provider.BaseUrl, selected.ModelID, r.timeout)
+	if err != nil {
+		return Result{}, err
+	}
+	return transcriber.Transcribe(ctx, audio)
+}
+
+func (r *Resolver) selectModel(ctx context.Context, botID string) (models.GetResponse, error) {
+	if r.modelsService == nil {
+		return models.GetResponse{}, errors.New("models service not configured")
+	}
+	if pinned := r.botModelID(ctx, botID); pinned != "" {
+		model, err := r.modelsService.GetByModelID(ctx, pinned)
+		if err != nil {
+			return models.GetResponse{}, err
+		}
+		if model.Type != models.ModelTypeTranscription {
+			return models.GetResponse{}, fmt.Errorf("model %s is not a transcription model", pinned)
+		}
+		return model, nil
+	}
+	candidates, err := r.modelsService.ListByType(ctx, models.ModelTypeTranscription)
+	if err != nil {
+		return models.GetResponse{}, err
+	}
+	if len(candidates) == 0 {
+		return models.GetResponse{}, ErrNoModel
+	}
+	return candidates[0], nil
+}
+
+func (r *Resolver) botModelID(ctx context.Context, botID string) string {
+	botID = strings.TrimSpace(botID)
+	if r.bots == nil || botID == "" {
+		return ""
+	}
+	bot, err := r.bots.Get(ctx, botID)
+	if err != nil || bot.Metadata == nil {
+		return ""
+	}
+	value, _ := bot.Metadata[botModelMetadataKey].(string)
+	return strings.TrimSpace(value)
+}
+
+func (r *Resolver) download(ctx context.Context, rawURL string) ([]byte, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, errors.New("audio has neither data nor url")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download audio: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("download audio: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxAudioBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download audio: %w", err)
+	}
+	if len(data) > MaxAudioBytes {
+		return nil, fmt.Errorf("audio exceeds %d bytes", MaxAudioBytes)
+	}
+	return data, nil
+}
+
+func (r *Resolver) fetchProvider(ctx context.Context, providerID string) (sqlc.LlmProvider, error) {
+	if r.queries == nil {
+		return sqlc.LlmProvider{}, errors.New("llm provider queries not configured")
+	}
+	if strings.TrimSpace(providerID) == "" {
+		return sqlc.LlmProvider{}, errors.New("llm provider id missing")
+	}
+	parsed, err := uuid.Parse(providerID)
+	if err != nil {
+		return sqlc.LlmProvider{}, err
+	}
+	pgID := pgtype.UUID{Valid: true}
+	copy(pgID.Bytes[:], parsed[:])
+	provider, err := r.queries.GetLlmProviderByID(ctx, pgID)
+	if err != nil {
+		return sqlc.LlmProvider{}, err
+	}
+	if provider.ApiKey, err = secrets.Open(provider.ApiKey); err != nil {
+		return sqlc.LlmProvider{}, fmt.Errorf("decrypt provider api key: %w", err)
+	}
+	return provider, nil
+}
@@ -0,0 +1,146 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Result is the transcript of one audio clip.
+type Result struct {
+	Text       string
+	Language   string
+	DurationMs int64
+}
+
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio Audio) (Result, error)
+}
+
+// OpenAITranscriber calls an OpenAI-compatible /audio/transcriptions endpoint.
+// The provider base URL is expected to include the API version, as in the
+// provider catalog (e.g. https://api.openai.com/v1).
+type OpenAITranscriber struct {
+	apiKey  string
+	baseURL string
+	model   string
+	logger  *slog.Logger
+	http    *http.Client
+}
+
+type openAITranscriptionResponse struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"`
+}
+
+func NewOpenAITranscriber(log *slog.Logger, apiKey, baseURL, model string, timeout time.Duration) (*OpenAITranscriber, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		return nil, fmt.Errorf("openai transcriber: base url is required")
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, fmt.Errorf("openai transcriber: model is required")
+	}
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &OpenAITranscriber{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		logger:  log.With(slog.String("transcriber", "openai")),
+		http: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, audio Audio) (Result, error) {
+	if len(audio.Data) == 0 {
+		return Result{}, fmt.Errorf("openai transcriber: audio data is required")
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("model", t.model); err != nil {
+		return Result{}, err
+	}
+	// verbose_json is the only format that reports the detected language.
+	if err := writer.WriteField("response_format", "verbose_json"); err != nil {
+		return Result{}, err
+	}
+	part, err := writer.CreateFormFile("file", audioFileName(audio))
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := part.Write(audio.Data); err != nil {
+		return Result{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return Result{}, fmt.Errorf("openai transcription error: %s", strings.TrimSpace(string(payload)))
+	}
+
+	var parsed openAITranscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Text:       strings.TrimSpace(parsed.Text),
+		Language:   strings.TrimSpace(parsed.Language),
+		DurationMs: int64(parsed.Duration * 1000),
+	}, nil
+}
+
+// audioFileName returns a file name for the multipart upload. Providers infer
+// the container format from the extension, so one is derived from the mime
+// type when the attachment has no name.
+func audioFileName(audio Audio) string {
+	if name := strings.TrimSpace(audio.Name); name != "" && strings.Contains(name, ".") {
+		return name
+	}
+	mimeType := strings.ToLower(strings.TrimSpace(audio.Mime))
+	if base, _, err := mime.ParseMediaType(mimeType); err == nil {
+		mimeType = base
+	}
+	switch mimeType {
+	case "audio/ogg", "audio/opus":
+		return "voice.ogg"
+	case "audio/mpeg", "audio/mp3":
+		return "voice.mp3"
+	case "audio/mp4", "audio/m4a", "audio/x-m4a", "audio/aac":
+		return "voice.m4a"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return "voice.wav"
+	case "audio/webm":
+		return "voice.webm"
+	case "audio/flac", "audio/x-flac":
+		return "voice.flac"
+	}
+	return "voice.ogg"
+}
@@ -0,0 +1,95 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenAITranscriberTranscribe(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("unexpected authorization: %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse form: %v", err)
+			return
+		}
+		if got := r.FormValue("model"); got != "whisper-1" {
+			t.Errorf("unexpected model: %q", got)
+		}
+		if got := r.FormValue("response_format"); got != "verbose_json" {
+			t.Errorf("unexpected response format: %q", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("form file: %v", err)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if header.Filename != "voice.ogg" || string(data) != "OggS" {
+			t.Errorf("unexpected file %q: %q", header.Filename, data)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":" hello there ","language":"english","duration":1.5}`))
+	}))
+	defer server.Close()
+
+	transcriber, err := NewOpenAITranscriber(slog.Default(), "sk-test", server.URL+"/v1/", "whisper-1", time.Second)
+	if err != nil {
+		t.Fatalf("new transcriber: %v", err)
+	}
+	result, err := transcriber.Transcribe(context.Background(), Audio{Data: []byte("OggS"), Mime: "audio/ogg; codecs=opus"})
+	if err != nil {
+		t.Fatalf("transcribe: %v", err)
+	}
+	if result.Text != "hello there" || result.Language != "english" || result.DurationMs != 1500 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestOpenAITranscriberError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	transcriber, err := NewOpenAITranscriber(slog.Default(), "", server.URL, "whisper-1", time.Second)
+	if err != nil {
+		t.Fatalf("new transcriber: %v", err)
+	}
+	if _, err := transcriber.Transcribe(context.Background(), Audio{Data: []byte("x"), Name: "clip.flac"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAudioFileName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		audio Audio
+		want  string
+	}{
+		{Audio{Name: "memo.m4a"}, "memo.m4a"},
+		{Audio{Mime: "audio/mpeg"}, "voice.mp3"},
+		{Audio{Name: "voice", Mime: "audio/wav"}, "voice.wav"},
+		{Audio{}, "voice.ogg"},
+	}
+	for _, tc := range cases {
+		if got := audioFileName(tc.audio); got != tc.want {
+			t.Errorf("audioFileName(%+v) = %q, want %q", tc.audio, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/netguard"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
+)
+
+const (
+	// MaxAttempts is how often a delivery is tried before it is marked failed.
+	MaxAttempts = 8
+	// retryBaseDelay doubles after every failed attempt up to retryMaxDelay:
+	// 30s, 1m, 2m, ... so the last retry happens about an hour after the event.
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+
+	requestTimeout = 10 * time.Second
+	pollInterval   = 5 * time.Second
+	claimBatchSize = 20
+
+	// eventBuffer absorbs bursts from the hub, which drops events for
+	// subscribers that fall behind. Deliveries are only persisted once an
+	// event is enqueued, so a dropped event is never delivered; the hub
+	// counts drops in memoh_event_hub_dropped_events_total.
+	eventBuffer = 1024
+
+	deliveryRetention = 30 * 24 * time.Hour
+	cleanupInterval   = time.Hour
+
+	// maxErrorBody caps how much of a failed response is kept in the log.
+	maxErrorBody = 512
+	userAgent    = "Memoh-Webhook/1.0"
+)
+
+// EventSource is the event hub the dispatcher listens to.
+type EventSource interface {
+	SubscribeAll(buffer int) (string, <-chan event.Event, func())
+}
+
+// Start subscribes to the hub and runs the delivery worker until ctx is
+// cancelled.
+func (s *Service) Start(ctx context.Context, source EventSource) {
+	if s.queries == nil {
+		return
+	}
+	if source != nil {
+		_, ch, cancel := source.SubscribeAll(eventBuffer)
+		go func() {
+			defer cancel()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case evt, ok := <-ch:
+					if !ok {
+						return
+					}
+					if err := s.Enqueue(ctx, evt); err != nil {
+						s.logger.Warn("queue webhook deliveries failed",
+							slog.String("bot_id", evt.BotID),
+							slog.String("event", string(evt.Type)),
+							slog.Any("error", err))
+					}
+				}
+			}
+		}()
+	}
+	go s.run(ctx)
+}
+
+// Enqueue stores a delivery of evt for every enabled webhook of the bot whose
+// filter matches.
+func (s *Service) Enqueue(ctx context.Context, evt event.Event) error {
+	if s.queries == nil {
+		return fmt.Errorf("webhook queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(evt.BotID)
+	if err != nil {
+		return err
+	}
+	hooks, err := s.queries.ListEnabledBotWebhooksByBot(ctx, pgBotID)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	queued := 0
+	for _, hook := range hooks {
+		if !matchesEvent(hook.Events, string(evt.Type)) {
+			continue
+		}
+		if body == nil {
+			if body, err = newPayload(evt, time.Now()); err != nil {
+				return err
+			}
+		}
+		if _, err := s.queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			WebhookID: hook.ID,
+			BotID:     hook.BotID,
+			EventType: string(evt.Type),
+			Payload:   body,
+		}); err != nil {
+			return err
+		}
+		queued++
+	}
+	if queued > 0 {
+		s.wake()
+	}
+	return nil
+}
+
+func (s *Service) wake() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Time{}
+	for {
+		s.processDue(ctx)
+		if time.Since(lastCleanup) > cleanupInterval {
+			s.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+	}
+}
+
+// processDue sends due deliveries until none are left.
+func (s *Service) processDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		rows, err := s.queries.ClaimDueWebhookDeliveries(ctx, claimBatchSize)
+		if err != nil {
+			s.logger.Warn("claim webhook deliveries failed", slog.Any("error", err))
+			return
+		}
+		var wg sync.WaitGroup
+		for _, row := range rows {
+			wg.Add(1)
+			go func(row sqlc.WebhookDelivery) {
+				defer wg.Done()
+				s.attempt(ctx, row)
+			}(row)
+		}
+		wg.Wait()
+		if len(rows) < claimBatchSize {
+			return
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome.
+func (s *Service) attempt(ctx context.Context, row sqlc.WebhookDelivery) {
+	attempts := int(row.Attempts) + 1
+	statusCode := 0
+	// final stops retrying failures that a retry cannot fix.
+	final := false
+	hook, err := s.queries.GetBotWebhook(ctx, row.WebhookID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// Deleted meanwhile; its deliveries go with it.
+		return
+	case err != nil:
+		err = fmt.Errorf("load webhook: %w", err)
+	case !hook.Enabled && row.EventType != EventTypePing:
+		err = errors.New("webhook disabled")
+		final = true
+	default:
+		var secret string
+		if secret, err = secrets.Open(hook.Secret); err != nil {
+			err = fmt.Errorf("decrypt webhook secret: %w", err)
+			break
+		}
+		statusCode, err = s.send(ctx, hook.Url, secret, row)
+		// Retrying cannot make an internal address allowed.
+		final = errors.Is(err, netguard.ErrBlockedAddress)
+	}
+
+	params := outcome(row.ID, attempts, statusCode, err, final, time.Now())
+	if _, recErr := s.queries.RecordWebhookDeliveryAttempt(context.WithoutCancel(ctx), params); recErr != nil {
+		s.logger.Warn("record webhook delivery failed", slog.String("delivery_id", row.ID.String()), slog.Any("error", recErr))
+	}
+	if err != nil {
+		s.logger.Info("webhook delivery attempt failed",
+			slog.String("delivery_id", row.ID.String()),
+			slog.String("webhook_id", row.WebhookID.String()),
+			slog.Int("attempt", attempts),
+			slog.String("status", params.Status),
+			slog.Any("error", err))
+	}
+}
+
+// send POSTs the stored payload. Any 2xx response counts as delivered.
+func (s *Service) send(ctx context.Context, target, secret string, row sqlc.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, row.EventType)
+	req.Header.Set(HeaderDelivery, row.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, row.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(snippet))
+		if msg == "" {
+			return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg)
+	}
+	return resp.StatusCode, nil
+}
+
+// outcome turns the result of an attempt into the stored delivery state.
+func outcome(id pgtype.UUID, attempts, statusCode int, sendErr error, final bool, now time.Time) sqlc.RecordWebhookDeliveryAttemptParams {
+	params := sqlc.RecordWebhookDeliveryAttemptParams{
+		ID:             id,
+		Attempts:       int32(attempts),
+		LastStatusCode: int32(statusCode),
+		NextAttemptAt:  pgtype.Timestamptz{Time: now, Valid: true},
+	}
+	switch {
+	case sendErr == nil:
+		params.Status = StatusSucceeded
+		params.DeliveredAt = pgtype.Timestamptz{Time: now, Valid: true}
+	case final || attempts >= MaxAttempts:
+		params.Status = StatusFailed
+		params.LastError = truncate(sendErr.Error(), maxErrorBody)
+	default:
+		params.Status = StatusPending
+		params.LastError = truncate(sendErr.Error(), maxErrorBody)
+		params.NextAttemptAt = pgtype.Timestamptz{Time: now.Add(retryDelay(attempts)), Valid: true}
+	}
+	return params
+}
+
+// retryDelay is the wait after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	cutoff := pgtype.Timestamptz{Time: time.Now().Add(-deliveryRetention), Valid: true}
+	n, err := s.queries.DeleteWebhookDeliveriesBefore(ctx, cutoff)
+	if err != nil {
+		s.logger.Warn("prune webhook deliveries failed", slog.Any("error", err))
+		return
+	}
+	if n > 0 {
+		s.logger.Info("pruned webhook deliveries", slog.Int64("count", n))
+	}
+}
+
+// matchesEvent reports whether a webhook filter accepts an event type. An
+// empty filter accepts everything.
+func matchesEvent(filter []string, eventType string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func newPayload(evt event.Event, now time.Time) ([]byte, error) {
+	return json.Marshal(Payload{
+		ID:        uuid.NewString(),
+		Type:      string(evt.Type),
+		BotID:     evt.BotID,
+		CreatedAt: now.UTC(),
+		Data:      evt.Data,
+	})
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/netguard"
+)
+
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		8:  time.Hour,
+		20: time.Hour,
+	}
+	for attempts, want := range cases {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	id := pgtype.UUID{Valid: true}
+
+	ok := outcome(id, 1, 200, nil, false, now)
+	if ok.Status != StatusSucceeded || !ok.DeliveredAt.Valid || ok.LastError != "" {
+		t.Fatalf("success outcome = %+v", ok)
+	}
+
+	retry := outcome(id, 2, 500, errors.New("boom"), false, now)
+	if retry.Status != StatusPending || retry.LastError != "boom" || retry.LastStatusCode != 500 {
+		t.Fatalf("retry outcome = %+v", retry)
+	}
+	if !retry.NextAttemptAt.Time.Equal(now.Add(time.Minute)) {
+		t.Fatalf("next attempt = %v, want %v", retry.NextAttemptAt.Time, now.Add(time.Minute))
+	}
+
+	if got := outcome(id, MaxAttempts, 0, errors.New("timeout"), false, now); got.Status != StatusFailed {
+		t.Fatalf("last attempt status = %q, want failed", got.Status)
+	}
+	if got := outcome(id, 1, 0, errors.New("webhook disabled"), true, now); got.Status != StatusFailed || got.Attempts != 1 {
+		t.Fatalf("final outcome = %+v", got)
+	}
+}
+
+func TestMatchesEvent(t *testing.T) {
+	if !matchesEvent(nil, string(event.EventTypeMemoryAdded)) {
+		t.Fatal("empty filter should match everything")
+	}
+	filter := []string{string(event.EventTypeScheduleRunFailed)}
+	if !matchesEvent(filter, string(event.EventTypeScheduleRunFailed)) {
+		t.Fatal("filter should match listed event")
+	}
+	if matchesEvent(filter, string(event.EventTypeMemoryAdded)) {
+		t.Fatal("filter should reject unlisted event")
+	}
+}
+
+func TestNewPayload(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	evt := event.NewEvent(event.EventTypeMemoryAdded, "bot-1", map[string]any{"count": 2})
+	body, err := newPayload(evt, now)
+	if err != nil {
+		t.Fatalf("newPayload: %v", err)
+	}
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if p.ID == "" || p.Type != "memory_added" || p.BotID != "bot-1" || !p.CreatedAt.Equal(now) {
+		t.Fatalf("payload = %+v", p)
+	}
+	if string(p.Data) != `{"count":2}` {
+		t.Fatalf("data = %s", p.Data)
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	var gotErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotErr = Verify("hook-secret", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Minute, time.Now())
+		if r.Header.Get(HeaderEvent) != "ping" || r.Header.Get(HeaderDelivery) == "" {
+			gotErr = errors.New("missing event headers")
+		}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	guard, err := netguard.New([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, guard)
+	row := sqlc.WebhookDelivery{
+		ID:        pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		EventType: EventTypePing,
+		Payload:   []byte(`{"type":"ping"}`),
+	}
+
+	code, err := s.send(context.Background(), srv.URL, "hook-secret", row)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("send = %d, %v", code, err)
+	}
+	if gotErr != nil {
+		t.Fatalf("receiver: %v", gotErr)
+	}
+
+	code, err = s.send(context.Background(), srv.URL+"/fail", "hook-secret", row)
+	if err == nil || code != http.StatusBadGateway {
+		t.Fatalf("send to failing endpoint = %d, %v", code, err)
+	}
+
+	// Without the allowlist entry the loopback receiver is refused.
+	blocked := NewService(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil)
+	if _, err := blocked.send(context.Background(), srv.URL, "hook-secret", row); !errors.Is(err, netguard.ErrBlockedAddress) {
+		t.Fatalf("send to loopback = %v, want ErrBlockedAddress", err)
+	}
+}
@@ -0,0 +1,407 @@
+// Package webhooks delivers bot events to external HTTP endpoints. Events
+// from the message event hub are persisted as deliveries, POSTed with an
+// HMAC signature and retried with exponential backoff.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/db/sqlc"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/message/event"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/netguard"
+	"github.com/Kxiandaoyan/Memoh-v2/internal/secrets"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidRequest marks errors caused by the caller's input.
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+const (
+	// SecretPrefix starts every generated signing secret.
+	SecretPrefix = "mw-"
+	// minSecretLen is the shortest secret accepted from callers.
+	minSecretLen = 16
+
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+type Service struct {
+	queries *sqlc.Queries
+	client  *http.Client
+	logger  *slog.Logger
+	// kick wakes the delivery worker after new deliveries are queued.
+	kick chan struct{}
+}
+
+// NewService creates the webhook service. Deliveries dial through guard, so
+// endpoints on internal addresses are refused unless the guard allows them.
+func NewService(log *slog.Logger, queries *sqlc.Queries, guard *netguard.Guard) *Service {
+	return &Service{
+		queries: queries,
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: guard.Transport(),
+			// A redirect is reported as a failed attempt rather than followed,
+			// so the signed body is only ever sent to the configured URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: log.With(slog.String("service", "webhooks")),
+		kick:   make(chan struct{}, 1),
+	}
+}
+
+// Create adds a webhook to the bot. The returned secret cannot be read back
+// later; it can only be rotated.
+func (s *Service) Create(ctx context.Context, botID, createdByUserID string, req CreateRequest) (CreatedWebhook, error) {
+	if s.queries == nil {
+		return CreatedWebhook{}, fmt.Errorf("webhook queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	pgCreatedBy := pgtype.UUID{}
+	if strings.TrimSpace(createdByUserID) != "" {
+		pgCreatedBy, err = db.ParseUUID(createdByUserID)
+		if err != nil {
+			return CreatedWebhook{}, err
+		}
+	}
+	target, err := normalizeURL(req.URL)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return CreatedWebhook{}, err
+		}
+	} else if len(secret) < minSecretLen {
+		return CreatedWebhook{}, fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidRequest, minSecretLen)
+	}
+	sealed, err := secrets.Seal(secret)
+	if err != nil {
+		return CreatedWebhook{}, fmt.Errorf("encrypt webhook secret: %w", err)
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	row, err := s.queries.CreateBotWebhook(ctx, sqlc.CreateBotWebhookParams{
+		BotID:           pgBotID,
+		Name:            strings.TrimSpace(req.Name),
+		Url:             target,
+		Secret:          sealed,
+		Events:          events,
+		Enabled:         enabled,
+		CreatedByUserID: pgCreatedBy,
+	})
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	return CreatedWebhook{Webhook: toWebhook(row), Secret: secret}, nil
+}
+
+func (s *Service) List(ctx context.Context, botID string) ([]Webhook, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("webhook queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListBotWebhooksByBot(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Webhook, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toWebhook(row))
+	}
+	return items, nil
+}
+
+func (s *Service) Get(ctx context.Context, botID, id string) (Webhook, error) {
+	row, err := s.get(ctx, botID, id)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return toWebhook(row), nil
+}
+
+func (s *Service) Update(ctx context.Context, botID, id string, req UpdateRequest) (Webhook, error) {
+	row, err := s.get(ctx, botID, id)
+	if err != nil {
+		return Webhook{}, err
+	}
+	params := sqlc.UpdateBotWebhookParams{
+		ID:      row.ID,
+		Name:    row.Name,
+		Url:     row.Url,
+		Events:  row.Events,
+		Enabled: row.Enabled,
+	}
+	if req.Name != nil {
+		params.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		if params.Url, err = normalizeURL(*req.URL); err != nil {
+			return Webhook{}, err
+		}
+	}
+	if req.Events != nil {
+		if params.Events, err = normalizeEvents(*req.Events); err != nil {
+			return Webhook{}, err
+		}
+	}
+	if req.Enabled != nil {
+		params.Enabled = *req.Enabled
+	}
+	updated, err := s.queries.UpdateBotWebhook(ctx, params)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return toWebhook(updated), nil
+}
+
+// RotateSecret replaces the signing secret. Pending retries are signed with
+// the new secret.
+func (s *Service) RotateSecret(ctx context.Context, botID, id string) (CreatedWebhook, error) {
+	row, err := s.get(ctx, botID, id)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	sealed, err := secrets.Seal(secret)
+	if err != nil {
+		return CreatedWebhook{}, fmt.Errorf("encrypt webhook secret: %w", err)
+	}
+	updated, err := s.queries.UpdateBotWebhookSecret(ctx, sqlc.UpdateBotWebhookSecretParams{ID: row.ID, Secret: sealed})
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	return CreatedWebhook{Webhook: toWebhook(updated), Secret: secret}, nil
+}
+
+// Delete removes a webhook together with its delivery log.
+func (s *Service) Delete(ctx context.Context, botID, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("webhook queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return ErrWebhookNotFound
+	}
+	n, err := s.queries.DeleteBotWebhook(ctx, sqlc.DeleteBotWebhookParams{ID: pgID, BotID: pgBotID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries of a webhook, newest
+// first.
+func (s *Service) ListDeliveries(ctx context.Context, botID, id string, limit int) ([]Delivery, error) {
+	row, err := s.get(ctx, botID, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	limit = min(limit, maxDeliveryLimit)
+	rows, err := s.queries.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{WebhookID: row.ID, Limit: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Delivery, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, toDelivery(r))
+	}
+	return items, nil
+}
+
+// Redeliver queues a delivery again with a fresh attempt budget.
+func (s *Service) Redeliver(ctx context.Context, botID, id, deliveryID string) (Delivery, error) {
+	row, err := s.get(ctx, botID, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	pgDeliveryID, err := db.ParseUUID(deliveryID)
+	if err != nil {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	delivery, err := s.queries.RetryWebhookDelivery(ctx, sqlc.RetryWebhookDeliveryParams{ID: pgDeliveryID, WebhookID: row.ID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Delivery{}, ErrDeliveryNotFound
+		}
+		return Delivery{}, err
+	}
+	s.wake()
+	return toDelivery(delivery), nil
+}
+
+// Ping queues a ping event for one webhook, regardless of its filter and
+// whether it is enabled.
+func (s *Service) Ping(ctx context.Context, botID, id string) (Delivery, error) {
+	row, err := s.get(ctx, botID, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	evt := event.NewEvent(EventTypePing, botID, map[string]string{"webhook_id": row.ID.String()})
+	body, err := newPayload(evt, time.Now())
+	if err != nil {
+		return Delivery{}, err
+	}
+	delivery, err := s.queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		WebhookID: row.ID,
+		BotID:     row.BotID,
+		EventType: EventTypePing,
+		Payload:   body,
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+	s.wake()
+	return toDelivery(delivery), nil
+}
+
+func (s *Service) get(ctx context.Context, botID, id string) (sqlc.BotWebhook, error) {
+	if s.queries == nil {
+		return sqlc.BotWebhook{}, fmt.Errorf("webhook queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return sqlc.BotWebhook{}, ErrWebhookNotFound
+	}
+	row, err := s.queries.GetBotWebhook(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.BotWebhook{}, ErrWebhookNotFound
+		}
+		return sqlc.BotWebhook{}, err
+	}
+	if row.BotID.String() != strings.TrimSpace(botID) {
+		return sqlc.BotWebhook{}, ErrWebhookNotFound
+	}
+	return row, nil
+}
+
+func normalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidRequest)
+	}
+	return raw, nil
+}
+
+// normalizeEvents validates and deduplicates an event filter.
+func normalizeEvents(events []string) ([]string, error) {
+	out := make([]string, 0, len(events))
+	seen := map[string]bool{}
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		if !event.IsValidEventType(event.EventType(e)) {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidRequest, e)
+		}
+		seen[e] = true
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func toWebhook(row sqlc.BotWebhook) Webhook {
+	w := Webhook{
+		ID:      row.ID.String(),
+		BotID:   row.BotID.String(),
+		Name:    row.Name,
+		URL:     row.Url,
+		Events:  row.Events,
+		Enabled: row.Enabled,
+	}
+	if w.Events == nil {
+		w.Events = []string{}
+	}
+	if row.CreatedByUserID.Valid {
+		w.CreatedByUserID = row.CreatedByUserID.String()
+	}
+	if row.CreatedAt.Valid {
+		w.CreatedAt = row.CreatedAt.Time
+	}
+	if row.UpdatedAt.Valid {
+		w.UpdatedAt = row.UpdatedAt.Time
+	}
+	return w
+}
+
+func toDelivery(row sqlc.WebhookDelivery) Delivery {
+	d := Delivery{
+		ID:             row.ID.String(),
+		WebhookID:      row.WebhookID.String(),
+		BotID:          row.BotID.String(),
+		EventType:      row.EventType,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		Attempts:       int(row.Attempts),
+		LastStatusCode: int(row.LastStatusCode),
+		LastError:      row.LastError,
+	}
+	if row.Status == StatusPending && row.NextAttemptAt.Valid {
+		next := row.NextAttemptAt.Time
+		d.NextAttemptAt = &next
+	}
+	if row.DeliveredAt.Valid {
+		delivered := row.DeliveredAt.Time
+		d.DeliveredAt = &delivered
+	}
+	if row.CreatedAt.Valid {
+		d.CreatedAt = row.CreatedAt.Time
+	}
+	return d
+}
@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"type":"ping"}`)
+	sig := Sign("top-secret", now.Unix(), body)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	if err := Verify("top-secret", ts, sig, body, 5*time.Minute, now); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	cases := map[string]func() error{
+		"wrong secret": func() error { return Verify("other", ts, sig, body, 5*time.Minute, now) },
+		"tampered body": func() error {
+			return Verify("top-secret", ts, sig, []byte(`{"type":"pong"}`), 5*time.Minute, now)
+		},
+		"shifted timestamp": func() error {
+			return Verify("top-secret", strconv.FormatInt(now.Unix()+1, 10), sig, body, 5*time.Minute, now)
+		},
+		"stale": func() error {
+			return Verify("top-secret", ts, sig, body, 5*time.Minute, now.Add(10*time.Minute))
+		},
+		"bad timestamp": func() error { return Verify("top-secret", "yesterday", sig, body, 0, now) },
+	}
+	for name, check := range cases {
+		if err := check(); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+	if err := Verify("top-secret", ts, sig, body, 0, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("zero tolerance should skip the timestamp check: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// EventTypePing is sent by the test endpoint. It bypasses the event filter.
+const EventTypePing = "ping"
+
+// Webhook is an outgoing webhook subscription of a bot. The signing secret
+// is only returned on creation and rotation.
+type Webhook struct {
+	ID    string `json:"id"`
+	BotID string `json:"bot_id"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	// Events filters the delivered event types; empty means all.
+	Events          []string  `json:"events"`
+	Enabled         bool      `json:"enabled"`
+	CreatedByUserID string    `json:"created_by_user_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CreatedWebhook is a webhook together with its signing secret.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateRequest creates a webhook. Without a secret one is generated.
+type CreateRequest struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events,omitempty"`
+	Secret  string   `json:"secret,omitempty"`
+	Enabled *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateRequest changes the given fields of a webhook.
+type UpdateRequest struct {
+	Name    *string   `json:"name,omitempty"`
+	URL     *string   `json:"url,omitempty"`
+	Events  *[]string `json:"events,omitempty"`
+	Enabled *bool     `json:"enabled,omitempty"`
+}
+
+type ListResponse struct {
+	Items []Webhook `json:"items"`
+}
+
+// Delivery is one event sent, or to be sent, to a webhook.
+type Delivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	BotID          string          `json:"bot_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type DeliveryListResponse struct {
+	Items []Delivery `json:"items"`
+}
+
+// Payload is the JSON body POSTed to the webhook URL. ID identifies the
+// event and is the same for every webhook it is delivered to.
+type Payload struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	BotID     string          `json:"bot_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
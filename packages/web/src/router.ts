@@ -123,7 +123,7 @@ const router = createRouter({
 router.beforeEach((to) => {
   const token = localStorage.getItem('token')
 
-  if (to.fullPath !== '/login') {
+  if (to.path !== '/login') {
     return token ? true : { name: 'Login' }
   } else {
     return token ? { path: '/chat' } : true